@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"HelmyTask/accounts"
+	"HelmyTask/config"
+	"HelmyTask/core"
+	"HelmyTask/hooks"
+	"HelmyTask/logger"
+	"HelmyTask/repositories"
+	"HelmyTask/services"
+	"HelmyTask/utils/knownemails"
+	"HelmyTask/utils/revocation"
+	"HelmyTask/utils/session"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// accountsPassphraseEnv holds the bundle passphrase for the accounts command (kept off the
+// command line, where it would show up in ps and shell history).
+const accountsPassphraseEnv = "ACCOUNTS_PASSPHRASE"
+
+// runAccounts implements `server accounts export -ids 1,2,3 -out FILE` and
+// `server accounts import -in FILE [-overwrite]`, the CLI side of /admin/accounts/*, with the
+// passphrase in $ACCOUNTS_PASSPHRASE. It returns the process exit code (0 done, 1 failed,
+// 2 bad usage); an import that skipped accounts prints the report and exits 1.
+func runAccounts(args []string) int {
+	if len(args) == 0 || (args[0] != "export" && args[0] != "import") {
+		fmt.Fprintln(os.Stderr, "accounts: want export or import")
+		return 2
+	}
+	fs := flag.NewFlagSet("accounts "+args[0], flag.ContinueOnError)
+	ids := fs.String("ids", "", "export: comma-separated user IDs")
+	out := fs.String("out", "", "export: bundle file to write")
+	in := fs.String("in", "", "import: bundle file to read")
+	overwrite := fs.Bool("overwrite", false, "import: replace users whose email is already registered")
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+	passphrase := os.Getenv(accountsPassphraseEnv)
+	if len(passphrase) < accounts.MinPassphraseLen {
+		fmt.Fprintf(os.Stderr, "accounts: set %s (at least %d characters)\n", accountsPassphraseEnv, accounts.MinPassphraseLen)
+		return 2
+	}
+
+	var userIDs []core.UserID
+	if args[0] == "export" {
+		if *out == "" {
+			fmt.Fprintln(os.Stderr, "accounts export: -out is required")
+			return 2
+		}
+		var err error
+		if userIDs, err = parseIDList(*ids); err != nil {
+			fmt.Fprintln(os.Stderr, "accounts export:", err)
+			return 2
+		}
+	} else if *in == "" {
+		fmt.Fprintln(os.Stderr, "accounts import: -in is required")
+		return 2
+	}
+
+	logger.Init()
+	cfg := config.Load()
+	rdb := config.InitRedis(cfg)
+	defer rdb.Close()
+	db := config.InitDB(cfg)
+	svc := newAccountsService(cfg, db, rdb)
+
+	if args[0] == "export" {
+		return exportAccounts(svc, userIDs, *out, passphrase)
+	}
+	return importAccounts(svc, *in, passphrase, *overwrite)
+}
+
+// newAccountsService builds the user service as the server does, minus what only serving
+// needs: an overwritten user is still logged out everywhere and lifecycle hooks still run.
+func newAccountsService(cfg *config.Config, db *gorm.DB, rdb *redis.Client) services.UserAdminService {
+	jwtExp, _ := time.ParseDuration(cfg.JWTExpires)     // validated in config.Load
+	sessionTTL, _ := time.ParseDuration(cfg.SessionTTL) // validated in config.Load
+	var known *knownemails.Store
+	if ttl, _ := time.ParseDuration(cfg.KnownEmailsTTL); ttl > 0 {
+		known = knownemails.New(rdb, ttl)
+	}
+	return services.NewUserService(repositories.NewUserRepository(db), rdb, nil,
+		services.WithTwoFactor(cfg.TwoFactorKey, cfg.AppName),
+		services.WithLifecycleHooks(hooks.Registered()...),
+		services.WithCredentialRevocation(session.New(rdb, sessionTTL), revocation.New(rdb, jwtExp)),
+		services.WithKnownEmails(known))
+}
+
+func exportAccounts(svc services.UserAdminService, ids []core.UserID, out, passphrase string) int {
+	accts, missing, err := svc.ExportAccounts(context.Background(), ids)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "accounts export:", err)
+		return 1
+	}
+	if len(missing) > 0 {
+		fmt.Fprintf(os.Stderr, "accounts export: no such users: %v\n", missing)
+	}
+	if len(accts) == 0 {
+		return 1
+	}
+	bundle, err := accounts.Seal(accts, passphrase, time.Now())
+	if err == nil {
+		err = os.WriteFile(out, bundle, 0o600)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "accounts export:", err)
+		return 1
+	}
+	fmt.Printf("exported %d accounts to %s\n", len(accts), out)
+	return 0
+}
+
+func importAccounts(svc services.UserAdminService, in, passphrase string, overwrite bool) int {
+	data, err := os.ReadFile(in)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "accounts import:", err)
+		return 1
+	}
+	b, err := accounts.Open(data, passphrase)
+	if err == nil && len(b.Accounts) > accounts.MaxAccounts {
+		err = fmt.Errorf("too many accounts; the limit is %d per bundle", accounts.MaxAccounts)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "accounts import:", err)
+		return 1
+	}
+	res, err := svc.ImportAccounts(context.Background(), b.Accounts, overwrite)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "accounts import:", err)
+		return 1
+	}
+	j, _ := json.Marshal(res)
+	fmt.Println(string(j))
+	if len(res.Errors) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// parseIDList parses "1,2,3" (1..accounts.MaxAccounts positive IDs).
+func parseIDList(s string) ([]core.UserID, error) {
+	if s == "" {
+		return nil, fmt.Errorf("-ids is required")
+	}
+	parts := strings.Split(s, ",")
+	if len(parts) > accounts.MaxAccounts {
+		return nil, fmt.Errorf("too many ids; the limit is %d", accounts.MaxAccounts)
+	}
+	ids := make([]core.UserID, 0, len(parts))
+	for _, p := range parts {
+		n, err := strconv.ParseUint(strings.TrimSpace(p), 10, 64)
+		if err != nil || n == 0 {
+			return nil, fmt.Errorf("invalid id %q", p)
+		}
+		ids = append(ids, core.UserID(n))
+	}
+	return ids, nil
+}
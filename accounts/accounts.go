@@ -0,0 +1,172 @@
+// Package accounts moves users between environments (staging refreshes, tenant migrations) as
+// an encrypted bundle: each account keeps its bcrypt hash, so people log in with the same
+// password, and its 2FA seed, re-encrypted with the target's two_factor_key on import.
+//
+// A bundle is "HTACCT1\n" | salt (16) | nonce (12) | AES-256-GCM(JSON Bundle), with the key
+// derived from a passphrase by Argon2id. The passphrase travels out of band; without it the
+// file is opaque, and any change to it makes Open fail.
+package accounts
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"HelmyTask/models"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Format is the bundle layout version written by Seal.
+const Format = 1
+
+// MinPassphraseLen is the shortest passphrase Seal accepts.
+const MinPassphraseLen = 12
+
+// MaxAccounts caps one bundle (export and import), like the bulk user endpoints.
+const MaxAccounts = 500
+
+var magic = []byte("HTACCT1\n")
+
+// Argon2id parameters (RFC 9106's second recommended option).
+const (
+	kdfTime    = 3
+	kdfMemory  = 64 * 1024 // KiB
+	kdfThreads = 4
+	saltLen    = 16
+)
+
+// ErrBadBundle is returned by Open for a wrong passphrase, a damaged file or not a bundle at all.
+var ErrBadBundle = errors.New("cannot open bundle (wrong passphrase, or not an accounts bundle)")
+
+// Account is one user as exported. IDs are not carried over: the target assigns its own,
+// and accounts are matched by email.
+type Account struct {
+	Email        string    `json:"email"`
+	Name         string    `json:"name"`
+	PasswordHash string    `json:"password_hash"` // bcrypt
+	Role         string    `json:"role"`
+	Status       string    `json:"status"`
+	TOTPSeed     string    `json:"totp_seed,omitempty"` // plaintext; the bundle is the encryption
+	TOTPEnabled  bool      `json:"totp_enabled"`
+	Phone        string    `json:"phone,omitempty"`
+	Bio          string    `json:"bio,omitempty"`
+	DateOfBirth  string    `json:"date_of_birth,omitempty"`
+	Locale       string    `json:"locale,omitempty"`
+	Timezone     string    `json:"timezone,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// Bundle is the decrypted content of a bundle file.
+type Bundle struct {
+	Format     int       `json:"format"`
+	ExportedAt time.Time `json:"exported_at"`
+	Accounts   []Account `json:"accounts"`
+}
+
+// FromUser turns u into an Account; seed is u's decrypted TOTP seed ("" = none).
+func FromUser(u models.User, seed string) Account {
+	return Account{
+		Email: u.Email, Name: u.Name, PasswordHash: u.Password, Role: u.Role, Status: u.Status,
+		TOTPSeed: seed, TOTPEnabled: u.TOTPEnabled && seed != "",
+		Phone: u.Phone, Bio: u.Bio, DateOfBirth: u.DateOfBirth, Locale: u.Locale, Timezone: u.Timezone,
+		CreatedAt: u.CreatedAt,
+	}
+}
+
+// Apply copies a's fields onto u (all but ID, email, timestamps and the TOTP secret, which the
+// caller re-encrypts for this environment).
+func (a Account) Apply(u *models.User) {
+	u.Name, u.Password, u.Role, u.Status = a.Name, a.PasswordHash, a.Role, a.Status
+	if u.Status == "" {
+		u.Status = models.StatusActive
+	}
+	u.TOTPEnabled = a.TOTPEnabled && a.TOTPSeed != ""
+	u.Phone, u.Bio, u.DateOfBirth, u.Locale, u.Timezone = a.Phone, a.Bio, a.DateOfBirth, a.Locale, a.Timezone
+}
+
+// Validate checks what the target can't repair: a bcrypt hash and known role/status.
+func (a Account) Validate() error {
+	if !strings.HasPrefix(a.PasswordHash, "$2a$") && !strings.HasPrefix(a.PasswordHash, "$2b$") && !strings.HasPrefix(a.PasswordHash, "$2y$") {
+		return errors.New("password_hash is not a bcrypt hash")
+	}
+	switch a.Role {
+	case models.RoleUser, models.RoleSupport, models.RoleAdmin:
+	default:
+		return fmt.Errorf("unknown role %q", a.Role)
+	}
+	switch a.Status {
+	case "", models.StatusActive, models.StatusDisabled, models.StatusBanned:
+	default:
+		return fmt.Errorf("unknown status %q", a.Status)
+	}
+	return nil
+}
+
+// Seal encrypts accounts into a bundle file.
+func Seal(accounts []Account, passphrase string, now time.Time) ([]byte, error) {
+	if len(passphrase) < MinPassphraseLen {
+		return nil, fmt.Errorf("passphrase must be at least %d characters", MinPassphraseLen)
+	}
+	plain, err := json.Marshal(Bundle{Format: Format, ExportedAt: now.UTC(), Accounts: accounts})
+	if err != nil {
+		return nil, err
+	}
+	salt := make([]byte, saltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	out := append(append(append([]byte{}, magic...), salt...), nonce...)
+	return gcm.Seal(out, nonce, plain, magic), nil // the header is authenticated too
+}
+
+// Open decrypts a bundle file.
+func Open(data []byte, passphrase string) (*Bundle, error) {
+	if !bytes.HasPrefix(data, magic) {
+		return nil, ErrBadBundle
+	}
+	data = data[len(magic):]
+	if len(data) < saltLen {
+		return nil, ErrBadBundle
+	}
+	gcm, err := newGCM(passphrase, data[:saltLen])
+	if err != nil {
+		return nil, err
+	}
+	data = data[saltLen:]
+	if len(data) < gcm.NonceSize() {
+		return nil, ErrBadBundle
+	}
+	plain, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], magic)
+	if err != nil {
+		return nil, ErrBadBundle
+	}
+	var b Bundle
+	if err := json.Unmarshal(plain, &b); err != nil || b.Format != Format {
+		return nil, fmt.Errorf("unsupported bundle format %d", b.Format)
+	}
+	return &b, nil
+}
+
+func newGCM(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key := argon2.IDKey([]byte(passphrase), salt, kdfTime, kdfMemory, kdfThreads, 32)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
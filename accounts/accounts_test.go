@@ -0,0 +1,70 @@
+package accounts
+
+import (
+	"testing"
+	"time"
+
+	"HelmyTask/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const pass = "correct horse battery"
+
+func TestSealOpen_RoundTrip(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	in := []Account{{Email: "a@b.c", Name: "Sara", PasswordHash: "$2a$10$x", Role: models.RoleAdmin, TOTPSeed: "SEED", TOTPEnabled: true}}
+
+	data, err := Seal(in, pass, now)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "a@b.c", "contents are encrypted")
+
+	b, err := Open(data, pass)
+	require.NoError(t, err)
+	assert.Equal(t, Format, b.Format)
+	assert.True(t, now.Equal(b.ExportedAt))
+	assert.Equal(t, in[0].Email, b.Accounts[0].Email)
+	assert.Equal(t, "SEED", b.Accounts[0].TOTPSeed)
+}
+
+func TestOpen_Rejects(t *testing.T) {
+	data, err := Seal([]Account{{Email: "a@b.c"}}, pass, time.Now())
+	require.NoError(t, err)
+
+	_, err = Open(data, "wrong passphrase!")
+	assert.ErrorIs(t, err, ErrBadBundle)
+
+	tampered := append([]byte{}, data...)
+	tampered[len(tampered)-1] ^= 1
+	_, err = Open(tampered, pass)
+	assert.ErrorIs(t, err, ErrBadBundle)
+
+	_, err = Open([]byte("name,email\n"), pass)
+	assert.ErrorIs(t, err, ErrBadBundle)
+	_, err = Open(magic, pass)
+	assert.ErrorIs(t, err, ErrBadBundle, "truncated")
+
+	_, err = Seal(nil, "short", time.Now())
+	assert.Error(t, err)
+}
+
+func TestAccount_ValidateAndApply(t *testing.T) {
+	a := Account{Email: "a@b.c", Name: "Sara", PasswordHash: "$2b$10$x", Role: models.RoleSupport, Locale: "ar-EG"}
+	assert.NoError(t, a.Validate())
+
+	bad := a
+	bad.PasswordHash = "hunter2"
+	assert.Error(t, bad.Validate())
+	bad = a
+	bad.Role = "root"
+	assert.Error(t, bad.Validate())
+
+	u := models.User{ID: 7, Email: "keep@b.c", TOTPSecret: "enc"}
+	a.Apply(&u)
+	assert.Equal(t, uint(7), u.ID)
+	assert.Equal(t, "keep@b.c", u.Email, "matched by email, never changed")
+	assert.Equal(t, models.StatusActive, u.Status, "missing status means active")
+	assert.Equal(t, "ar-EG", u.Locale)
+	assert.False(t, u.TOTPEnabled, "no seed, no 2FA")
+}
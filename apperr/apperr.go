@@ -0,0 +1,85 @@
+// Package apperr is the catalog of error codes the API returns, served at GET /api/errors so
+// client teams can map codes to UX without reading Go source.
+//
+// Codes are defined next to the rules that produce them (core, settings, scripting, the
+// OpenAPI validator) and reach clients as violations: {"field", "code", "message"}. Each one
+// is listed here once with the HTTP status it comes with and a message template; {name}
+// placeholders stand for values filled in at runtime. A code added without an entry fails
+// apperr's tests, and a listed code never changes meaning: clients switch on it.
+package apperr
+
+import (
+	"sort"
+	"strings"
+)
+
+// Groups a code can belong to.
+const (
+	GroupValidation = "validation" // business rules on input, 400
+	GroupSettings   = "settings"   // PUT /api/v1/admin/settings, 400
+	GroupSchema     = "schema"     // request doesn't match docs/swagger.yaml (openapi_validation), 422
+)
+
+// Entry describes one code.
+type Entry struct {
+	Code    string   `json:"code"`
+	Status  int      `json:"status"` // HTTP status of the response carrying it
+	Group   string   `json:"group"`
+	Message string   `json:"message"`          // template of the violation's message
+	Params  []string `json:"params,omitempty"` // {placeholders} in Message
+	Fields  []string `json:"fields,omitempty"` // violation fields it is reported on, if fixed
+	Docs    string   `json:"docs,omitempty"`   // link to the code's documentation
+}
+
+// index is the catalog by code, built once.
+var index = func() map[string]Entry {
+	m := make(map[string]Entry, len(catalog))
+	for _, e := range catalog {
+		m[e.Code] = e
+	}
+	return m
+}()
+
+// Catalog returns every entry sorted by code. With a non-empty docsBase, Docs is docsBase
+// followed by the code (e.g. "https://docs.example.com/errors#" + "password_weak").
+func Catalog(docsBase string) []Entry {
+	out := make([]Entry, 0, len(catalog))
+	for _, e := range catalog {
+		out = append(out, withDocs(e, docsBase))
+	}
+	sort.Slice(out, func(i, k int) bool { return out[i].Code < out[k].Code })
+	return out
+}
+
+// Lookup returns the entry for code.
+func Lookup(code, docsBase string) (Entry, bool) {
+	e, ok := index[code]
+	if !ok {
+		return Entry{}, false
+	}
+	return withDocs(e, docsBase), true
+}
+
+func withDocs(e Entry, docsBase string) Entry {
+	if docsBase != "" {
+		e.Docs = docsBase + e.Code
+	}
+	return e
+}
+
+// params lists the {placeholders} in a message template, in order.
+func params(msg string) []string {
+	var out []string
+	for {
+		i := strings.IndexByte(msg, '{')
+		if i < 0 {
+			return out
+		}
+		j := strings.IndexByte(msg[i:], '}')
+		if j < 0 {
+			return out
+		}
+		out = append(out, msg[i+1:i+j])
+		msg = msg[i+j+1:]
+	}
+}
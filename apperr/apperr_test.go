@@ -0,0 +1,84 @@
+package apperr
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// responseOnly are codes that are logged, never sent to clients.
+var responseOnly = map[string]bool{"schema_status": true}
+
+// TestCatalog_CoversEveryCode fails when a Code* constant is added to a package that returns
+// violations without an entry here.
+func TestCatalog_CoversEveryCode(t *testing.T) {
+	for _, dir := range []string{"../core", "../settings", "../scripting", "../utils/openapi"} {
+		for _, code := range codeConstants(t, dir) {
+			if responseOnly[code] {
+				continue
+			}
+			_, ok := Lookup(code, "")
+			assert.True(t, ok, "%s: code %q is not in the catalog", dir, code)
+		}
+	}
+}
+
+func TestCatalog_Entries(t *testing.T) {
+	seen := map[string]bool{}
+	for _, e := range Catalog("") {
+		assert.False(t, seen[e.Code], "duplicate code %q", e.Code)
+		seen[e.Code] = true
+		assert.NotZero(t, e.Status, e.Code)
+		assert.NotEmpty(t, e.Message, e.Code)
+		assert.Empty(t, e.Docs)
+	}
+}
+
+func TestCatalog_DocsAndParams(t *testing.T) {
+	e, ok := Lookup("password_too_short", "https://docs.example.com/errors#")
+	require.True(t, ok)
+	assert.Equal(t, "https://docs.example.com/errors#password_too_short", e.Docs)
+	assert.Equal(t, 400, e.Status)
+	assert.Equal(t, []string{"min"}, e.Params)
+
+	e, _ = Lookup("schema_type", "")
+	assert.Equal(t, 422, e.Status)
+	_, ok = Lookup("nope", "")
+	assert.False(t, ok)
+}
+
+// codeConstants returns the values of string constants named Code* in the non-test files of dir.
+func codeConstants(t *testing.T, dir string) []string {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fi fs.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, 0)
+	require.NoError(t, err)
+	var out []string
+	for _, pkg := range pkgs {
+		ast.Inspect(pkg, func(n ast.Node) bool {
+			spec, ok := n.(*ast.ValueSpec)
+			if !ok {
+				return true
+			}
+			for i, name := range spec.Names {
+				if !strings.HasPrefix(name.Name, "Code") || i >= len(spec.Values) {
+					continue
+				}
+				if lit, ok := spec.Values[i].(*ast.BasicLit); ok && lit.Kind == token.STRING {
+					v, _ := strconv.Unquote(lit.Value)
+					out = append(out, v)
+				}
+			}
+			return true
+		})
+	}
+	return out
+}
@@ -0,0 +1,69 @@
+// The catalog itself: one entry per code, grouped by the package that defines it.
+
+package apperr
+
+import (
+	"net/http"
+
+	"HelmyTask/core"
+	"HelmyTask/scripting"
+	"HelmyTask/settings"
+	"HelmyTask/utils/openapi"
+)
+
+var catalog = withParams([]Entry{
+	// core: user input (register, profile, password change, list and export queries).
+	{Code: core.CodeEmailInvalid, Group: GroupValidation, Fields: []string{"email"}, Message: "must be a valid email address"},
+	{Code: core.CodeEmailTooLong, Group: GroupValidation, Fields: []string{"email"}, Message: "must be at most {max} characters"},
+	{Code: core.CodePasswordTooShort, Group: GroupValidation, Fields: []string{"password", "new_password"}, Message: "must be at least {min} characters"},
+	{Code: core.CodePasswordTooLong, Group: GroupValidation, Fields: []string{"password", "new_password"}, Message: "must be at most {max} bytes"},
+	{Code: core.CodePasswordBlank, Group: GroupValidation, Fields: []string{"password", "new_password"}, Message: "must not be blank"},
+	{Code: core.CodePasswordWeak, Group: GroupValidation, Fields: []string{"password", "new_password"}, Message: "is too easy to guess{warning}"},
+	{Code: core.CodePasswordReused, Group: GroupValidation, Fields: []string{"new_password"}, Message: "must differ from the current password"},
+	{Code: core.CodePasswordMissingClass, Group: GroupValidation, Fields: []string{"password", "new_password"}, Message: "must contain {classes}"},
+	{Code: core.CodePasswordBanned, Group: GroupValidation, Fields: []string{"password", "new_password"}, Message: "is too common"},
+	{Code: core.CodeNameLength, Group: GroupValidation, Fields: []string{"name"}, Message: "must be between {min} and {max} characters"},
+	{Code: core.CodeNameCharset, Group: GroupValidation, Fields: []string{"name"}, Message: "may only contain letters, spaces, apostrophes, hyphens and periods"},
+	{Code: core.CodeNameReserved, Group: GroupValidation, Fields: []string{"name"}, Message: "is reserved"},
+	{Code: core.CodeDateOfBirthInvalid, Group: GroupValidation, Fields: []string{"date_of_birth"}, Message: "must be a past date as YYYY-MM-DD after 1900"},
+	{Code: core.CodeSortInvalid, Group: GroupValidation, Fields: []string{"sort", "order"}, Message: "unknown sort column or order"},
+	{Code: core.CodeCursorInvalid, Group: GroupValidation, Fields: []string{"cursor"}, Message: "use the next_cursor of a previous response"},
+	{Code: core.CodeFormatInvalid, Group: GroupValidation, Fields: []string{"format", "tz"}, Message: "unsupported export format or time zone"},
+	{Code: core.CodeColumnInvalid, Group: GroupValidation, Fields: []string{"columns"}, Message: "unknown column {name}"},
+
+	// scripting: registration rules configured per environment.
+	{Code: scripting.CodeRegistrationRule, Group: GroupValidation, Fields: []string{"registration"}, Message: "{rule_message}"},
+
+	// settings: runtime overrides.
+	{Code: settings.CodeLogLevelInvalid, Group: GroupSettings, Fields: []string{"log_level"}, Message: "must be one of {levels}"},
+	{Code: settings.CodeLogMaxLenInvalid, Group: GroupSettings, Fields: []string{"log_max_len"}, Message: "must be between 1 and {max}"},
+	{Code: settings.CodeLogRetentionInvalid, Group: GroupSettings, Fields: []string{"log_retention"}, Message: "must be a duration between 0s (no age limit) and 720h"},
+	{Code: settings.CodeRateInvalid, Group: GroupSettings, Message: "requests_per_minute and burst must be >= 0"},
+	{Code: settings.CodeTTLInvalid, Group: GroupSettings, Fields: []string{"cache_ttl"}, Message: "must be a duration between 1s and 24h"},
+	{Code: settings.CodeFlagInvalid, Group: GroupSettings, Fields: []string{"feature_flags"}, Message: "invalid flag name {name}"},
+
+	// openapi: requests checked against docs/swagger.yaml.
+	{Code: openapi.CodeRequired, Group: GroupSchema, Message: "is required"},
+	{Code: openapi.CodeType, Group: GroupSchema, Message: "must be {type}"},
+	{Code: openapi.CodeEnum, Group: GroupSchema, Message: "must be one of {values}"},
+	{Code: openapi.CodeFormat, Group: GroupSchema, Message: "must be a valid {format}"},
+	{Code: openapi.CodeLength, Group: GroupSchema, Message: "length out of the documented bounds"},
+	{Code: openapi.CodeRange, Group: GroupSchema, Message: "value out of the documented range"},
+	{Code: openapi.CodeBody, Group: GroupSchema, Fields: []string{"body"}, Message: "is not valid JSON"},
+})
+
+// groupStatus is the HTTP status every code of a group comes with.
+var groupStatus = map[string]int{
+	GroupValidation: http.StatusBadRequest,
+	GroupSettings:   http.StatusBadRequest,
+	GroupSchema:     http.StatusUnprocessableEntity,
+}
+
+// withParams fills in Status and Params so entries don't repeat them.
+func withParams(entries []Entry) []Entry {
+	for i := range entries {
+		entries[i].Status = groupStatus[entries[i].Group]
+		entries[i].Params = params(entries[i].Message)
+	}
+	return entries
+}
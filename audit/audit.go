@@ -0,0 +1,157 @@
+// Package audit records who changed what, and when, for every mutating operation.
+// Entries are append-only rows in audit_logs; the diff holds old/new values per changed field,
+// with secrets (passwords, TOTP seeds) recorded only as "changed".
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"HelmyTask/models"
+	"HelmyTask/repositories"
+	"HelmyTask/utils/redislog"
+)
+
+// Actions recorded so far.
+const (
+	ActionUserCreate = "user.create"
+	ActionUserUpdate = "user.update"
+	ActionUserDelete = "user.delete"
+	ActionUserBan    = "user.ban"
+	ActionUserUnban  = "user.unban"
+	ActionUserExport = "user.export" // included in an accounts bundle (credentials left the environment)
+	ActionUserFlush  = "user.flush"  // cache, logins and pending state cleared by support
+	ActionUserErase  = "user.erase"  // approved deletion carried out; the diff holds what was erased, never the data
+
+	ActionDeletionRequest = "deletion.request" // user asked for their account to be erased
+	ActionDeletionApprove = "deletion.approve"
+	ActionDeletionReject  = "deletion.reject"
+	ActionDeletionExpire  = "deletion.expire" // review window passed without a decision
+
+	ActionSettingsUpdate = "settings.update" // PUT /admin/settings or /admin/logs/retention
+	ActionLogsArchive    = "logs.archive"    // old Redis log entries moved to an archive file
+)
+
+// ignoredFields are bookkeeping columns that change on every write.
+var ignoredFields = map[string]bool{"updated_at": true}
+
+// Event describes one change to record.
+type Event struct {
+	ActorID    uint   // uid from the request context (0 = anonymous/system)
+	Action     string // one of the Action* constants
+	TargetType string // "user", ...
+	TargetID   uint
+	Before     interface{} // nil on create
+	After      interface{} // nil on delete
+	Secrets    []string    // secret fields that changed; recorded without values
+	IP         string
+	Reason     string // optional note from the actor (e.g. why a user was banned)
+}
+
+// Change is the old/new value of one field.
+type Change struct {
+	From    interface{} `json:"from,omitempty"`
+	To      interface{} `json:"to,omitempty"`
+	Changed bool        `json:"changed,omitempty"` // secrets: value withheld
+}
+
+// Recorder persists audit events. A nil *Recorder records nothing.
+type Recorder struct {
+	repo repositories.AuditRepository
+	log  *redislog.Logger
+}
+
+// New builds a recorder.
+func New(repo repositories.AuditRepository, rlog *redislog.Logger) *Recorder {
+	return &Recorder{repo: repo, log: rlog}
+}
+
+// Record stores e. Failures are logged rather than returned: the change already happened,
+// and failing the request now would only hide that from the caller.
+func (r *Recorder) Record(e Event) {
+	if r == nil {
+		return
+	}
+	entry := Entry(e)
+	if err := r.repo.Create(&entry); err != nil && r.log != nil {
+		r.log.Error("audit write error", map[string]string{"action": e.Action, "target_id": fmt.Sprint(e.TargetID), "err": err.Error()})
+	}
+}
+
+// Entry is the audit_logs row for e: what Record writes, and what a service writes itself when
+// the entry must commit in the same transaction as the change (see WithPending).
+func Entry(e Event) models.AuditLog {
+	diff := Diff(e.Before, e.After)
+	for _, f := range e.Secrets {
+		diff[f] = Change{Changed: true}
+	}
+	b, _ := json.Marshal(diff)
+	return models.AuditLog{ActorID: e.ActorID, Action: e.Action, TargetType: e.TargetType, TargetID: e.TargetID, Diff: string(b), IP: e.IP, Reason: e.Reason}
+}
+
+type pendingKey struct{}
+
+// WithPending hands e (who, what, from where; not yet Before/After) to the service making the
+// change: it fills in the states it read and wrote, and inserts the entry in the same
+// transaction, so a change is never committed without its audit entry (or the reverse).
+func WithPending(ctx context.Context, e Event) context.Context {
+	return context.WithValue(ctx, pendingKey{}, e)
+}
+
+// Pending is the event WithPending attached to ctx.
+func Pending(ctx context.Context) (Event, bool) {
+	e, ok := ctx.Value(pendingKey{}).(Event)
+	return e, ok
+}
+
+// Query pages through the audit trail, newest first.
+func (r *Recorder) Query(f models.AuditFilter, page, limit int) (*models.PagedAuditLogs, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	items, total, err := r.repo.List(f, (page-1)*limit, limit)
+	if err != nil {
+		return nil, err
+	}
+	return &models.PagedAuditLogs{Items: items, Total: total, Page: page, Limit: limit}, nil
+}
+
+// Diff compares the JSON forms of before and after field by field. Either side may be nil
+// (create/delete), in which case every field of the other side is reported.
+func Diff(before, after interface{}) map[string]Change {
+	b, a := fields(before), fields(after)
+	out := map[string]Change{}
+	for k, av := range a {
+		if ignoredFields[k] {
+			continue
+		}
+		if bv, ok := b[k]; !ok || !reflect.DeepEqual(bv, av) {
+			out[k] = Change{From: b[k], To: av}
+		}
+	}
+	for k, bv := range b {
+		if _, ok := a[k]; !ok && !ignoredFields[k] {
+			out[k] = Change{From: bv}
+		}
+	}
+	return out
+}
+
+// fields flattens v's JSON object into a map (json:"-" fields are naturally excluded).
+func fields(v interface{}) map[string]interface{} {
+	m := map[string]interface{}{}
+	if v == nil || reflect.ValueOf(v).Kind() == reflect.Ptr && reflect.ValueOf(v).IsNil() {
+		return m
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return m
+	}
+	_ = json.Unmarshal(b, &m)
+	return m
+}
@@ -0,0 +1,28 @@
+package audit
+
+import (
+	"testing"
+
+	"HelmyTask/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	before := &models.User{ID: 1, Name: "Ahmed", Email: "a@b.c", Role: "user", Password: "hash1"}
+	after := &models.User{ID: 1, Name: "Ahmed", Email: "a@b.c", Role: "admin", Password: "hash2"}
+
+	d := Diff(before, after)
+	assert.Equal(t, map[string]Change{"role": {From: "user", To: "admin"}}, d, "password is json:\"-\" so never leaks into a diff")
+
+	// create: everything is new; delete: everything goes away
+	assert.Equal(t, "Ahmed", Diff(nil, after)["name"].To)
+	assert.Equal(t, "Ahmed", Diff(before, (*models.User)(nil))["name"].From)
+}
+
+func TestEntry_SecretsWithheld(t *testing.T) {
+	e := Entry(Event{ActorID: 1, Action: ActionUserUpdate, TargetType: "user", TargetID: 2,
+		Before: &models.User{Name: "Ahmed"}, After: &models.User{Name: "Ahmed H"}, Secrets: []string{"password"}})
+	assert.JSONEq(t, `{"name":{"from":"Ahmed","to":"Ahmed H"},"password":{"changed":true}}`, e.Diff)
+	assert.Equal(t, uint(2), e.TargetID)
+}
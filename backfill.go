@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"HelmyTask/backfill"
+	"HelmyTask/config"
+	"HelmyTask/logger"
+)
+
+// runBackfill implements `server backfill [-batch 500] [-rate 0] [-status|-reset] <job>` and
+// `server backfill -list`: it runs a data backfill to completion, resuming from its checkpoint,
+// and returns the process exit code (0 done, 1 failed or interrupted, 2 bad usage). SIGTERM or
+// Ctrl-C stops it after the current batch; running the same command again resumes.
+func runBackfill(args []string) int {
+	fs := flag.NewFlagSet("backfill", flag.ContinueOnError)
+	batch := fs.Int("batch", 500, "rows per batch")
+	rate := fs.Float64("rate", 0, "at most this many rows per second (0 = unthrottled)")
+	status := fs.Bool("status", false, "print the job's checkpoint and exit")
+	reset := fs.Bool("reset", false, "forget the job's checkpoint so the next run starts over")
+	list := fs.Bool("list", false, "list the available jobs")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *list {
+		listBackfills(os.Stdout)
+		return 0
+	}
+	job, ok := backfill.Jobs[fs.Arg(0)]
+	if fs.NArg() != 1 || !ok {
+		fmt.Fprintf(os.Stderr, "backfill: want exactly one job name\n")
+		listBackfills(os.Stderr)
+		return 2
+	}
+
+	logger.Init()
+	cfg := config.Load()
+	rdb := config.InitRedis(cfg)
+	defer rdb.Close()
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
+
+	if *status || *reset {
+		runner := backfill.NewRunner(nil, rdb, backfill.Options{}) // checkpoints only; no DB needed
+		if *reset {
+			if err := runner.Reset(ctx, job.Name); err != nil {
+				fmt.Fprintln(os.Stderr, "backfill:", err)
+				return 1
+			}
+		}
+		return printProgress(runner.Progress(ctx, job.Name))
+	}
+
+	db := config.InitDB(cfg) // schema migrations first, so the job sees the current columns
+	p, err := backfill.NewRunner(db, rdb, backfill.Options{BatchSize: *batch, RowsPerSecond: *rate}).Run(ctx, job)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "backfill %s: %v (run it again to resume)\n", job.Name, err)
+	}
+	if code := printProgress(p, nil); err != nil || code != 0 {
+		return 1
+	}
+	return 0
+}
+
+// listBackfills prints "name  description" for every job.
+func listBackfills(w io.Writer) {
+	for _, name := range backfill.Names() {
+		fmt.Fprintf(w, "%-20s %s\n", name, backfill.Jobs[name].Description)
+	}
+}
+
+// printProgress writes p as JSON to stdout.
+func printProgress(p backfill.Progress, err error) int {
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "backfill:", err)
+		return 1
+	}
+	b, _ := json.Marshal(p)
+	fmt.Println(string(b))
+	return 0
+}
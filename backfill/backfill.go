@@ -0,0 +1,197 @@
+// Package backfill runs long data backfills (rewriting existing rows, as opposed to schema
+// migrations, which config.InitDB applies at boot) as CLI jobs: `server backfill <job>`.
+//
+// A job walks a table in primary-key order, one batch at a time. After every batch the runner
+// records the last ID in a Redis checkpoint ("backfill:<job>"), so an interrupted run resumes
+// where it stopped; a lease ("backfill:<job>:lock") keeps two runs of one job from overlapping.
+// Batches can be throttled to a number of rows per second to spare the primary.
+package backfill
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"HelmyTask/utils/leader"
+	"HelmyTask/utils/redisscript"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// lockTTL is how long a crashed run blocks the next one; the lease is renewed every batch.
+const lockTTL = 5 * time.Minute
+
+// ErrLocked is returned by Run when another run of the job holds the lease.
+var ErrLocked = errors.New("backfill: job is already running")
+
+// renewScript extends the lease only if this run still holds it.
+var renewScript = redisscript.Register("backfill.renew", `
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+
+// releaseScript deletes the lease only if this run still holds it.
+var releaseScript = redisscript.Register("backfill.release", `
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+// Result is what one batch did.
+type Result struct {
+	LastID  uint // highest ID looked at; 0 = no rows left
+	Scanned int  // rows looked at
+	Changed int  // rows rewritten
+	Skipped int  // rows left alone on purpose (e.g. a conflict), worth a look afterwards
+}
+
+// Job is one backfill. Batch must be idempotent: a crash between a batch's commit and its
+// checkpoint repeats that batch on resume.
+type Job struct {
+	Name        string
+	Description string
+	// Batch processes up to limit rows with ID > after, in ID order.
+	Batch func(ctx context.Context, db *gorm.DB, after uint, limit int) (Result, error)
+}
+
+// Progress is a job's checkpoint.
+type Progress struct {
+	Job       string    `json:"job"`
+	LastID    uint      `json:"last_id"`
+	Scanned   int64     `json:"scanned"`
+	Changed   int64     `json:"changed"`
+	Skipped   int64     `json:"skipped"`
+	Done      bool      `json:"done"`
+	UpdatedAt time.Time `json:"updated_at"` // zero = never run
+}
+
+// Options tunes a run.
+type Options struct {
+	BatchSize     int     // rows per batch; 0 = 500
+	RowsPerSecond float64 // throttle; 0 = as fast as the DB goes
+}
+
+// Runner runs jobs against one database, keeping checkpoints in Redis.
+type Runner struct {
+	db   *gorm.DB
+	rdb  *redis.Client
+	opts Options
+	id   string // lease holder, e.g. "pod-7f9c-1a2b"
+	now  func() time.Time
+}
+
+// NewRunner builds a runner.
+func NewRunner(db *gorm.DB, rdb *redis.Client, opts Options) *Runner {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 500
+	}
+	return &Runner{db: db, rdb: rdb, opts: opts, id: leader.InstanceID(), now: time.Now}
+}
+
+func checkpointKey(job string) string { return "backfill:" + job }
+func lockKey(job string) string       { return "backfill:" + job + ":lock" }
+
+// Run processes the job from its checkpoint until no rows are left or ctx is done; either
+// way the checkpoint reflects every batch that completed. A finished job returns at once
+// (Reset starts it over).
+func (r *Runner) Run(ctx context.Context, job Job) (Progress, error) {
+	ok, err := r.rdb.SetNX(ctx, lockKey(job.Name), r.id, lockTTL).Result()
+	if err != nil {
+		return Progress{}, err
+	}
+	if !ok {
+		return Progress{}, ErrLocked
+	}
+	defer releaseScript.Run(context.Background(), r.rdb, []string{lockKey(job.Name)}, r.id) // even when ctx is cancelled
+
+	p, err := r.Progress(ctx, job.Name)
+	if err != nil {
+		return p, err
+	}
+	for !p.Done {
+		if err := ctx.Err(); err != nil {
+			return p, err
+		}
+		start := r.now()
+		res, err := job.Batch(ctx, r.db, p.LastID, r.opts.BatchSize)
+		if err != nil {
+			return p, fmt.Errorf("batch after id %d: %w", p.LastID, err)
+		}
+		if res.LastID == 0 {
+			p.Done = true
+		} else {
+			p.LastID = res.LastID
+		}
+		p.Scanned += int64(res.Scanned)
+		p.Changed += int64(res.Changed)
+		p.Skipped += int64(res.Skipped)
+		p.UpdatedAt = r.now().UTC()
+		if err := r.save(context.Background(), p); err != nil { // the batch is committed; record it even if ctx just ended
+			return p, err
+		}
+		renewed, err := renewScript.Run(ctx, r.rdb, []string{lockKey(job.Name)}, r.id, lockTTL.Milliseconds()).Int()
+		if err == nil && renewed == 0 {
+			return p, ErrLocked // the lease expired and someone else took over
+		}
+		slog.Info("backfill batch", "job", job.Name, "last_id", p.LastID, "scanned", p.Scanned, "changed", p.Changed, "skipped", p.Skipped)
+		if d := pause(res.Scanned, r.opts.RowsPerSecond, r.now().Sub(start)); d > 0 && !p.Done {
+			select {
+			case <-ctx.Done():
+			case <-time.After(d):
+			}
+		}
+	}
+	return p, nil
+}
+
+// pause is how long to wait after a batch of n rows that took elapsed, to stay under rate.
+func pause(n int, rate float64, elapsed time.Duration) time.Duration {
+	if rate <= 0 {
+		return 0
+	}
+	return time.Duration(float64(n)/rate*float64(time.Second)) - elapsed
+}
+
+// Progress reads a job's checkpoint (the zero Progress if it never ran).
+func (r *Runner) Progress(ctx context.Context, job string) (Progress, error) {
+	h, err := r.rdb.HGetAll(ctx, checkpointKey(job)).Result()
+	if err != nil {
+		return Progress{}, err
+	}
+	p := Progress{Job: job}
+	last, _ := strconv.ParseUint(h["last_id"], 10, 0)
+	p.LastID = uint(last)
+	p.Scanned, _ = strconv.ParseInt(h["scanned"], 10, 64)
+	p.Changed, _ = strconv.ParseInt(h["changed"], 10, 64)
+	p.Skipped, _ = strconv.ParseInt(h["skipped"], 10, 64)
+	p.Done = h["done"] == "1"
+	if ts, err := strconv.ParseInt(h["updated_at"], 10, 64); err == nil {
+		p.UpdatedAt = time.Unix(ts, 0).UTC()
+	}
+	return p, nil
+}
+
+// Reset deletes a job's checkpoint, so the next run starts from the first row.
+func (r *Runner) Reset(ctx context.Context, job string) error {
+	return r.rdb.Del(ctx, checkpointKey(job)).Err()
+}
+
+// save writes the checkpoint. It never expires: a half-done backfill may wait days for a resume.
+func (r *Runner) save(ctx context.Context, p Progress) error {
+	done := "0"
+	if p.Done {
+		done = "1"
+	}
+	return r.rdb.HSet(ctx, checkpointKey(p.Job),
+		"last_id", strconv.FormatUint(uint64(p.LastID), 10),
+		"scanned", strconv.FormatInt(p.Scanned, 10),
+		"changed", strconv.FormatInt(p.Changed, 10),
+		"skipped", strconv.FormatInt(p.Skipped, 10),
+		"done", done,
+		"updated_at", strconv.FormatInt(p.UpdatedAt.Unix(), 10)).Err()
+}
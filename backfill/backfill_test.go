@@ -0,0 +1,62 @@
+package backfill
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestRunner_ResumesAndCheckpointsEveryBatch(t *testing.T) {
+	rdb, m := redismock.NewClientMock()
+	r := NewRunner(nil, rdb, Options{BatchSize: 10})
+	r.id = "test-1"
+	clock := time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC)
+	r.now = func() time.Time { return clock }
+	ts := "1792143000"
+
+	var afters []uint
+	job := Job{Name: "demo", Batch: func(_ context.Context, _ *gorm.DB, after uint, limit int) (Result, error) {
+		assert.Equal(t, 10, limit)
+		afters = append(afters, after)
+		if after >= 40 {
+			return Result{}, nil // nothing left
+		}
+		return Result{LastID: after + 10, Scanned: 10, Changed: 3, Skipped: 1}, nil
+	}}
+
+	renew := func() {
+		m.ExpectEvalSha(renewScript.SHA(), []string{"backfill:demo:lock"}, "test-1", lockTTL.Milliseconds()).SetVal(int64(1))
+	}
+	m.ExpectSetNX("backfill:demo:lock", "test-1", lockTTL).SetVal(true)
+	m.ExpectHGetAll("backfill:demo").SetVal(map[string]string{"last_id": "20", "scanned": "20", "changed": "6", "skipped": "2", "done": "0"})
+	m.ExpectHSet("backfill:demo", "last_id", "30", "scanned", "30", "changed", "9", "skipped", "3", "done", "0", "updated_at", ts).SetVal(0)
+	renew()
+	m.ExpectHSet("backfill:demo", "last_id", "40", "scanned", "40", "changed", "12", "skipped", "4", "done", "0", "updated_at", ts).SetVal(0)
+	renew()
+	m.ExpectHSet("backfill:demo", "last_id", "40", "scanned", "40", "changed", "12", "skipped", "4", "done", "1", "updated_at", ts).SetVal(0)
+	renew()
+	m.ExpectEvalSha(releaseScript.SHA(), []string{"backfill:demo:lock"}, "test-1").SetVal(int64(1))
+
+	p, err := r.Run(context.Background(), job)
+	require.NoError(t, err)
+	assert.Equal(t, []uint{20, 30, 40}, afters, "resumed after the checkpointed id")
+	assert.Equal(t, Progress{Job: "demo", LastID: 40, Scanned: 40, Changed: 12, Skipped: 4, Done: true, UpdatedAt: clock}, p)
+	assert.NoError(t, m.ExpectationsWereMet())
+
+	// a second run while the lease is held is refused before anything else happens
+	m.ExpectSetNX("backfill:demo:lock", "test-1", lockTTL).SetVal(false)
+	_, err = r.Run(context.Background(), job)
+	assert.ErrorIs(t, err, ErrLocked)
+	assert.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestPause(t *testing.T) {
+	assert.Zero(t, pause(500, 0, 0), "unthrottled")
+	assert.Equal(t, 4*time.Second, pause(500, 100, time.Second)) // 500 rows at 100/s = 5s, 1s already spent
+	assert.Negative(t, pause(10, 100, time.Second), "slow batch: no wait")
+}
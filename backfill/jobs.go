@@ -0,0 +1,96 @@
+package backfill
+
+import (
+	"context"
+	"sort"
+
+	"HelmyTask/core"
+	"HelmyTask/models"
+
+	"gorm.io/gorm"
+)
+
+// Jobs are the built-in backfills, by name.
+var Jobs = map[string]Job{
+	"normalize-emails": {
+		Name: "normalize-emails",
+		Description: "Trim user emails and lowercase their domains, as registration does today. " +
+			"Rows whose canonical form belongs to another user, or that aren't valid addresses, are skipped. " +
+			"Cached copies of the user (cache_ttl) show the old address until they expire.",
+		Batch: normalizeEmails,
+	},
+	"search-columns": {
+		Name:        "search-columns",
+		Description: "Recompute users.name_search and users.name_skeleton for rows written before they existed or before the folding rules changed.",
+		Batch:       searchColumns,
+	},
+}
+
+// Names lists the built-in jobs, sorted.
+func Names() []string {
+	names := make([]string, 0, len(Jobs))
+	for name := range Jobs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// userBatch loads up to limit users with ID > after, in ID order, reading only cols.
+func userBatch(ctx context.Context, db *gorm.DB, after uint, limit int, cols ...string) ([]models.User, error) {
+	var users []models.User
+	err := db.WithContext(ctx).Select(cols).Where("id > ?", after).Order("id").Limit(limit).Find(&users).Error
+	return users, err
+}
+
+func normalizeEmails(ctx context.Context, db *gorm.DB, after uint, limit int) (Result, error) {
+	users, err := userBatch(ctx, db, after, limit, "id", "email")
+	if err != nil || len(users) == 0 {
+		return Result{}, err
+	}
+	res := Result{LastID: users[len(users)-1].ID, Scanned: len(users)}
+	for _, u := range users {
+		email, err := core.ParseEmail(u.Email)
+		if err != nil {
+			res.Skipped++ // not an address we'd accept today; needs a human
+			continue
+		}
+		if email.String() == u.Email {
+			continue
+		}
+		var taken int64
+		if err := db.WithContext(ctx).Model(&models.User{}).Where("email = ? AND id <> ?", email.String(), u.ID).Count(&taken).Error; err != nil {
+			return res, err
+		}
+		if taken > 0 {
+			res.Skipped++ // two rows for one mailbox; merging accounts is not a backfill's call
+			continue
+		}
+		// UpdateColumn: no hooks and updated_at stays, the user didn't change anything
+		if err := db.WithContext(ctx).Model(&models.User{}).Where("id = ?", u.ID).UpdateColumn("email", email.String()).Error; err != nil {
+			return res, err
+		}
+		res.Changed++
+	}
+	return res, nil
+}
+
+func searchColumns(ctx context.Context, db *gorm.DB, after uint, limit int) (Result, error) {
+	users, err := userBatch(ctx, db, after, limit, "id", "name", "name_search", "name_skeleton")
+	if err != nil || len(users) == 0 {
+		return Result{}, err
+	}
+	res := Result{LastID: users[len(users)-1].ID, Scanned: len(users)}
+	for _, u := range users {
+		search, skeleton := core.FoldSearch(u.Name), core.SearchSkeleton(u.Name)
+		if search == u.NameSearch && skeleton == u.NameSkeleton {
+			continue
+		}
+		if err := db.WithContext(ctx).Model(&models.User{}).Where("id = ?", u.ID).
+			UpdateColumns(map[string]interface{}{"name_search": search, "name_skeleton": skeleton}).Error; err != nil {
+			return res, err
+		}
+		res.Changed++
+	}
+	return res, nil
+}
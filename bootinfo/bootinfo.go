@@ -0,0 +1,132 @@
+// Package bootinfo is the startup diagnostics report: what a replica started with (database
+// driver and migrations, Redis mode and latency, enabled features and flags, route count).
+// It is logged once at boot and kept in the Redis hash "boot:reports" (instance -> JSON) for
+// GET /admin/boot-info, so a deployment can be checked without digging through logs.
+package bootinfo
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// reportsKey holds the latest Report per instance.
+const reportsKey = "boot:reports"
+
+// keepFor is how long a report stays after its replica booted; Save drops older ones, so
+// replicas long gone don't pile up.
+const keepFor = 7 * 24 * time.Hour
+
+// pings is how many round trips RedisInfo times (the fastest counts: the first may dial).
+const pings = 3
+
+// Report describes one replica's boot.
+type Report struct {
+	Instance     string            `json:"instance"`
+	App          string            `json:"app"`
+	Version      string            `json:"version"`
+	GoVersion    string            `json:"go_version"`
+	Env          string            `json:"env"`
+	StartedAt    time.Time         `json:"started_at"`
+	BootMS       int64             `json:"boot_ms"` // process start to ready to serve
+	DB           DB                `json:"db"`
+	Redis        Redis             `json:"redis"`
+	Features     map[string]string `json:"features"`      // see config.Config.Features
+	FeatureFlags map[string]bool   `json:"feature_flags"` // as in effect at boot (runtime settings)
+	Routes       int               `json:"routes"`
+}
+
+// DB is the database side of the report.
+type DB struct {
+	Driver        string   `json:"driver"`
+	Migrations    int      `json:"migrations"`               // models auto-migrated
+	CreatedTables []string `json:"created_tables,omitempty"` // by this boot's migration
+	Schema        string   `json:"schema"`                   // checksum of the schema the models expect
+	Compatible    bool     `json:"compatible"`               // the schema matched when the report was made
+}
+
+// Redis is the Redis side of the report.
+type Redis struct {
+	Mode      string  `json:"mode"`    // standalone|cluster|sentinel, from INFO server ("" if unknown)
+	Version   string  `json:"version"` // server version
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"` // INFO or PING failed
+}
+
+// RedisInfo asks the server for its mode and version and times a PING.
+func RedisInfo(ctx context.Context, rdb *redis.Client) Redis {
+	var out Redis
+	info, err := rdb.Info(ctx, "server").Result()
+	if err != nil {
+		out.Error = err.Error()
+		return out
+	}
+	for _, line := range strings.Split(info, "\n") {
+		k, v, ok := strings.Cut(strings.TrimSpace(line), ":")
+		switch {
+		case ok && k == "redis_mode":
+			out.Mode = v
+		case ok && k == "redis_version":
+			out.Version = v
+		}
+	}
+	best := time.Duration(-1)
+	for i := 0; i < pings; i++ {
+		start := time.Now()
+		if err := rdb.Ping(ctx).Err(); err != nil {
+			out.Error = err.Error()
+			return out
+		}
+		if d := time.Since(start); best < 0 || d < best {
+			best = d
+		}
+	}
+	out.LatencyMS = float64(best.Microseconds()) / 1000
+	return out
+}
+
+// Save stores r as its instance's latest report and drops reports older than keepFor.
+func Save(ctx context.Context, rdb *redis.Client, r Report) error {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	if err := rdb.HSet(ctx, reportsKey, r.Instance, b).Err(); err != nil {
+		return err
+	}
+	all, err := List(ctx, rdb)
+	if err != nil {
+		return err
+	}
+	var stale []string
+	for _, x := range all {
+		if r.StartedAt.Sub(x.StartedAt) > keepFor {
+			stale = append(stale, x.Instance)
+		}
+	}
+	if len(stale) == 0 {
+		return nil
+	}
+	return rdb.HDel(ctx, reportsKey, stale...).Err()
+}
+
+// List returns the stored reports, latest boot first.
+func List(ctx context.Context, rdb *redis.Client) ([]Report, error) {
+	raw, err := rdb.HGetAll(ctx, reportsKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Report, 0, len(raw))
+	for _, v := range raw {
+		var r Report
+		if json.Unmarshal([]byte(v), &r) == nil {
+			out = append(out, r)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].StartedAt.After(out[j].StartedAt) })
+	return out, nil
+}
@@ -0,0 +1,55 @@
+package bootinfo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"HelmyTask/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisInfo_ModeVersionLatency(t *testing.T) {
+	rdb, m := mocks.NewRedisMock()
+	m.ExpectInfo("server").SetVal("# Server\r\nredis_version:7.2.4\r\nredis_mode:standalone\r\nos:Linux\r\n")
+	for i := 0; i < pings; i++ {
+		m.ExpectPing().SetVal("PONG")
+	}
+	r := RedisInfo(context.Background(), rdb)
+	assert.Equal(t, "standalone", r.Mode)
+	assert.Equal(t, "7.2.4", r.Version)
+	assert.Empty(t, r.Error)
+	assert.GreaterOrEqual(t, r.LatencyMS, 0.0)
+	assert.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestSave_DropsStaleReports(t *testing.T) {
+	rdb, m := mocks.NewRedisMock()
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	r := Report{Instance: "web-2", StartedAt: now, Routes: 120}
+	anyArgs := func(expected, actual []interface{}) error { return nil }
+	m.CustomMatch(anyArgs).ExpectHSet(reportsKey, "web-2", "").SetVal(1)
+	m.ExpectHGetAll(reportsKey).SetVal(map[string]string{
+		"web-2": `{"instance":"web-2","started_at":"2026-10-16T09:00:00Z"}`,
+		"web-1": `{"instance":"web-1","started_at":"2026-10-15T09:00:00Z"}`,
+		"old-1": `{"instance":"old-1","started_at":"2026-09-01T09:00:00Z"}`,
+	})
+	m.ExpectHDel(reportsKey, "old-1").SetVal(1)
+	require.NoError(t, Save(context.Background(), rdb, r))
+	assert.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestList_LatestBootFirst(t *testing.T) {
+	rdb, m := mocks.NewRedisMock()
+	m.ExpectHGetAll(reportsKey).SetVal(map[string]string{
+		"web-1": `{"instance":"web-1","started_at":"2026-10-15T09:00:00Z"}`,
+		"web-2": `{"instance":"web-2","started_at":"2026-10-16T09:00:00Z"}`,
+		"bad":   `not json`,
+	})
+	rs, err := List(context.Background(), rdb)
+	require.NoError(t, err)
+	require.Len(t, rs, 2)
+	assert.Equal(t, "web-2", rs[0].Instance)
+}
@@ -0,0 +1,172 @@
+// Package changelog is the machine-readable record of API changes and deprecations
+// (docs/changelog.yaml), served at GET /api/changelog.
+//
+// Entries with a deprecated date also drive response headers on the endpoint they name
+// (middlewares.Deprecation): Deprecation (RFC 9745), Sunset (RFC 8594) and Link, so clients
+// learn about a deprecation from the traffic they already send.
+package changelog
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Kinds of entry.
+const (
+	KindAdded      = "added"
+	KindChanged    = "changed"
+	KindDeprecated = "deprecated"
+	KindRemoved    = "removed"
+)
+
+const dateLayout = "2006-01-02"
+
+var methods = map[string]bool{"GET": true, "POST": true, "PUT": true, "PATCH": true, "DELETE": true}
+
+// pathParam matches "{id}" segments of OpenAPI-style paths.
+var pathParam = regexp.MustCompile(`\{([^/{}]+)\}`)
+
+// Entry is one change. Method/Path are optional for API-wide changes.
+type Entry struct {
+	Date       string `yaml:"date" json:"date"` // YYYY-MM-DD the change shipped
+	Kind       string `yaml:"kind" json:"kind"`
+	Method     string `yaml:"method" json:"method,omitempty"`
+	Path       string `yaml:"path" json:"path,omitempty"` // as in the OpenAPI spec, e.g. /api/v1/users/{id}
+	Summary    string `yaml:"summary" json:"summary"`
+	Deprecated string `yaml:"deprecated" json:"deprecated,omitempty"` // YYYY-MM-DD; responses carry Deprecation from then on
+	Sunset     string `yaml:"sunset" json:"sunset,omitempty"`         // YYYY-MM-DD the endpoint goes away
+	Link       string `yaml:"link" json:"link,omitempty"`             // migration notes
+	Successor  string `yaml:"successor" json:"successor,omitempty"`   // replacement endpoint, e.g. /api/v2/users
+
+	deprecatedAt time.Time
+	sunsetAt     time.Time
+}
+
+// DeprecatedAt is the parsed Deprecated date (zero if not deprecated).
+func (e Entry) DeprecatedAt() time.Time { return e.deprecatedAt }
+
+// SunsetAt is the parsed Sunset date (zero if none).
+func (e Entry) SunsetAt() time.Time { return e.sunsetAt }
+
+// Registry holds the entries, newest first, and an index of deprecated routes.
+type Registry struct {
+	entries    []Entry
+	deprecated map[string]*Entry // "GET /api/v1/users/:id" (Gin route template) → entry
+}
+
+// Load reads and validates a changelog file.
+func Load(path string) (*Registry, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(b)
+}
+
+// Parse validates a changelog document ({entries: [...]}).
+func Parse(b []byte) (*Registry, error) {
+	var doc struct {
+		Entries []Entry `yaml:"entries"`
+	}
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return nil, fmt.Errorf("changelog: %w", err)
+	}
+	reg := &Registry{deprecated: map[string]*Entry{}}
+	for i := range doc.Entries {
+		e := &doc.Entries[i]
+		if err := e.validate(); err != nil {
+			return nil, fmt.Errorf("changelog: entry %d (%s %s): %w", i+1, e.Method, e.Path, err)
+		}
+	}
+	reg.entries = doc.Entries
+	sort.SliceStable(reg.entries, func(i, j int) bool { return reg.entries[i].Date > reg.entries[j].Date })
+	for i := range reg.entries {
+		if e := &reg.entries[i]; !e.deprecatedAt.IsZero() {
+			key := e.Method + " " + GinPath(e.Path)
+			if _, dup := reg.deprecated[key]; dup {
+				return nil, fmt.Errorf("changelog: %s is deprecated twice", key)
+			}
+			reg.deprecated[key] = e
+		}
+	}
+	return reg, nil
+}
+
+func (e *Entry) validate() error {
+	if _, err := time.Parse(dateLayout, e.Date); err != nil {
+		return fmt.Errorf("date %q is not YYYY-MM-DD", e.Date)
+	}
+	switch e.Kind {
+	case KindAdded, KindChanged, KindDeprecated, KindRemoved:
+	default:
+		return fmt.Errorf("kind %q (want added|changed|deprecated|removed)", e.Kind)
+	}
+	if strings.TrimSpace(e.Summary) == "" {
+		return fmt.Errorf("summary is required")
+	}
+	if (e.Method == "") != (e.Path == "") {
+		return fmt.Errorf("method and path go together")
+	}
+	if e.Method != "" && (!methods[e.Method] || !strings.HasPrefix(e.Path, "/")) {
+		return fmt.Errorf("want an upper-case method and an absolute path")
+	}
+	var err error
+	if e.Deprecated != "" {
+		if e.Method == "" {
+			return fmt.Errorf("deprecated needs a method and path")
+		}
+		if e.deprecatedAt, err = time.Parse(dateLayout, e.Deprecated); err != nil {
+			return fmt.Errorf("deprecated %q is not YYYY-MM-DD", e.Deprecated)
+		}
+	}
+	if e.Sunset != "" {
+		if e.deprecatedAt.IsZero() {
+			return fmt.Errorf("sunset needs a deprecated date")
+		}
+		if e.sunsetAt, err = time.Parse(dateLayout, e.Sunset); err != nil || !e.sunsetAt.After(e.deprecatedAt) {
+			return fmt.Errorf("sunset %q must be a YYYY-MM-DD date after deprecated", e.Sunset)
+		}
+	}
+	if e.Link != "" {
+		if u, err := url.Parse(e.Link); err != nil || u.Scheme != "https" && u.Scheme != "http" {
+			return fmt.Errorf("link %q is not an absolute URL", e.Link)
+		}
+	}
+	return nil
+}
+
+// Entries returns entries shipped on or after since (zero = all), newest first.
+func (r *Registry) Entries(since time.Time) []Entry {
+	out := []Entry{}
+	for _, e := range r.entries {
+		if d, _ := time.Parse(dateLayout, e.Date); !d.Before(since) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// Deprecation returns the deprecation entry for a Gin route (method + c.FullPath()) that is in
+// effect at now, or nil. Announced-but-future deprecations don't emit headers yet.
+func (r *Registry) Deprecation(method, route string, now time.Time) *Entry {
+	if r == nil {
+		return nil
+	}
+	e := r.deprecated[method+" "+route]
+	if e == nil || now.Before(e.deprecatedAt) {
+		return nil
+	}
+	return e
+}
+
+// GinPath converts an OpenAPI path template to Gin's syntax: /users/{id} → /users/:id.
+func GinPath(p string) string {
+	return pathParam.ReplaceAllString(p, ":$1")
+}
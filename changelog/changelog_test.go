@@ -0,0 +1,87 @@
+package changelog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const doc = `
+entries:
+  - date: "2026-01-10"
+    kind: added
+    method: GET
+    path: /api/v2/users/{id}
+    summary: v2 user resource.
+  - date: "2026-03-01"
+    kind: deprecated
+    method: GET
+    path: /api/v1/users/{id}
+    summary: Superseded by v2.
+    deprecated: "2026-03-01"
+    sunset: "2026-09-01"
+    successor: /api/v2/users/{id}
+  - date: "2025-12-01"
+    kind: changed
+    summary: API-wide change.
+`
+
+func TestParse_SortsNewestFirstAndFiltersSince(t *testing.T) {
+	reg, err := Parse([]byte(doc))
+	require.NoError(t, err)
+
+	all := reg.Entries(time.Time{})
+	require.Len(t, all, 3)
+	assert.Equal(t, []string{"2026-03-01", "2026-01-10", "2025-12-01"}, []string{all[0].Date, all[1].Date, all[2].Date})
+
+	recent := reg.Entries(time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC))
+	assert.Len(t, recent, 2, "since is inclusive")
+}
+
+func TestDeprecation_OnlyOnceInEffect(t *testing.T) {
+	reg, err := Parse([]byte(doc))
+	require.NoError(t, err)
+
+	assert.Nil(t, reg.Deprecation("GET", "/api/v1/users/:id", time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)), "announced, not yet deprecated")
+	e := reg.Deprecation("GET", "/api/v1/users/:id", time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC))
+	require.NotNil(t, e)
+	assert.Equal(t, time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC), e.SunsetAt())
+	assert.Nil(t, reg.Deprecation("DELETE", "/api/v1/users/:id", time.Now()))
+
+	var none *Registry
+	assert.Nil(t, none.Deprecation("GET", "/", time.Now()))
+}
+
+func TestParse_Rejects(t *testing.T) {
+	cases := map[string]string{
+		"bad date":            `{entries: [{date: "1/2/2026", kind: added, summary: x}]}`,
+		"bad kind":            `{entries: [{date: "2026-01-01", kind: fixed, summary: x}]}`,
+		"no summary":          `{entries: [{date: "2026-01-01", kind: added}]}`,
+		"path without method": `{entries: [{date: "2026-01-01", kind: added, path: /x, summary: x}]}`,
+		"lower-case method":   `{entries: [{date: "2026-01-01", kind: added, method: get, path: /x, summary: x}]}`,
+		"deprecated API-wide": `{entries: [{date: "2026-01-01", kind: deprecated, deprecated: "2026-01-01", summary: x}]}`,
+		"sunset before":       `{entries: [{date: "2026-01-01", kind: deprecated, method: GET, path: /x, deprecated: "2026-02-01", sunset: "2026-01-01", summary: x}]}`,
+		"relative link":       `{entries: [{date: "2026-01-01", kind: deprecated, method: GET, path: /x, deprecated: "2026-01-01", link: /docs, summary: x}]}`,
+		"deprecated twice": `{entries: [
+			{date: "2026-01-01", kind: deprecated, method: GET, path: "/x/{id}", deprecated: "2026-01-01", summary: x},
+			{date: "2026-02-01", kind: deprecated, method: GET, path: "/x/{id}", deprecated: "2026-02-01", summary: y}]}`,
+	}
+	for name, src := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := Parse([]byte(src))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestGinPath(t *testing.T) {
+	assert.Equal(t, "/api/v1/users/:id/sessions/:sid", GinPath("/api/v1/users/{id}/sessions/{sid}"))
+	assert.Equal(t, "/status", GinPath("/status"))
+}
+
+func TestLoad_ShippedChangelogIsValid(t *testing.T) {
+	_, err := Load("../docs/changelog.yaml")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// exitCode is returned by a command that has already reported its outcome and only needs the
+// process to exit with this status.
+type exitCode int
+
+func (c exitCode) Error() string { return fmt.Sprintf("exit status %d", int(c)) }
+
+// execute runs the command line and returns the process exit code: 0 done, 1 failed, 2 bad
+// usage (the convention of the flag-based commands below).
+func execute(args []string) int {
+	root := newRootCmd()
+	root.SetArgs(args)
+	cmd, err := root.ExecuteC()
+	var code exitCode
+	switch {
+	case err == nil:
+		return 0
+	case errors.As(err, &code):
+		return int(code)
+	case isUsageError(err):
+		fmt.Fprintf(os.Stderr, "%s: %v\n", cmd.CommandPath(), err)
+		fmt.Fprintln(os.Stderr, cmd.UsageString())
+		return 2
+	default:
+		fmt.Fprintf(os.Stderr, "%s: %v\n", cmd.CommandPath(), err)
+		return 1
+	}
+}
+
+// usageError marks a bad command line (as opposed to a failure while running).
+type usageError struct{ error }
+
+func isUsageError(err error) bool {
+	var u usageError
+	return errors.As(err, &u)
+}
+
+// usage makes positional-argument errors usage errors.
+func usage(check cobra.PositionalArgs) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		if err := check(cmd, args); err != nil {
+			return usageError{err}
+		}
+		return nil
+	}
+}
+
+// newRootCmd is `server`. With no subcommand it serves, so images whose entrypoint is the bare
+// binary keep working.
+func newRootCmd() *cobra.Command {
+	root := &cobra.Command{
+		Use:           "server",
+		Short:         "HelmyTask API server and maintenance commands",
+		Args:          usage(cobra.NoArgs),
+		SilenceUsage:  true, // execute prints usage for usage errors only
+		SilenceErrors: true,
+		RunE:          runServe,
+	}
+	root.SetFlagErrorFunc(func(_ *cobra.Command, err error) error { return usageError{err} })
+	root.AddCommand(
+		&cobra.Command{Use: "serve", Short: "Run the API (the default)", Args: usage(cobra.NoArgs), RunE: runServe},
+		newMigrateCmd(),
+		newSeedCmd(),
+		newCreateAdminCmd(),
+		flagCommand("healthcheck", "Probe /readyz (container health checks); see healthcheck.go", runHealthcheck),
+		flagCommand("backfill", "Run a data backfill job; see backfill.go", runBackfill),
+		flagCommand("accounts", "Export or import encrypted account bundles; see accounts.go", runAccounts),
+	)
+	return root
+}
+
+func runServe(*cobra.Command, []string) error {
+	runServer()
+	return nil
+}
+
+// flagCommand wraps a command that parses its own (Go flag) arguments and returns an exit code.
+func flagCommand(use, short string, run func(args []string) int) *cobra.Command {
+	return &cobra.Command{
+		Use:                use,
+		Short:              short,
+		DisableFlagParsing: true, // -h included: the command's FlagSet prints its own usage
+		RunE: func(_ *cobra.Command, args []string) error {
+			if code := run(args); code != 0 {
+				return exitCode(code)
+			}
+			return nil
+		},
+	}
+}
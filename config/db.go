@@ -3,12 +3,16 @@
 package config
 
 import (
-	"log"
+	"fmt"
+	"time"
 
+	"HelmyTask/core"   // Search-key folding for the backfill.
+	"HelmyTask/global" // App version, recorded with the schema checksum.
+	applog "HelmyTask/logger" // Structured process log (gorm has its own "logger").
 	"HelmyTask/models" // Import our model(s) so we can auto-migrate schema.
+	"HelmyTask/schemacheck" // Records which schema the migration produced.
 
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
 
 	// GORM drivers (we open one depending on cfg.DBDriver).
 	"gorm.io/driver/mysql"
@@ -17,57 +21,159 @@ import (
 	"gorm.io/driver/sqlserver"
 )
 
+// migratedModels are the tables Migrate keeps in step with the models.
+var migratedModels = []any{&models.User{}, &models.APIKey{}, &models.EmailDelivery{}, &models.Webhook{}, &models.WebhookDelivery{}, &models.AuditLog{}, &models.ProbeHeartbeat{}, &models.Incident{}, &models.DeletionRequest{}, &models.Notification{}, &models.SchemaVersion{}}
+
+// Migration summarizes Migrate's auto-migration.
+type Migration struct {
+	Models   int      `json:"models"`            // models migrated
+	Created  []string `json:"created,omitempty"` // tables that didn't exist yet
+	Checksum string   `json:"checksum"`          // of the schema the models expect (see schemacheck)
+}
+
+// MigratedModels are the models InitDB migrates, for schemacheck.New.
+func MigratedModels() []any { return migratedModels }
+
+// LastMigration is what the latest Migrate did (for the boot report).
+var LastMigration Migration
+
 // InitDB opens a database connection using the driver specified in config,
 // configures GORM, and applies auto-migrations for our models.
 func InitDB(cfg *Config) *gorm.DB {
+	db := OpenDB(cfg)
+	if err := Migrate(db); err != nil {
+		applog.Fatal("db: migration failed", "err", err)
+	}
+	return db
+}
+
+// OpenDB opens and configures the connection, without migrating (`server migrate` does that
+// separately; InitDB does both).
+func OpenDB(cfg *Config) *gorm.DB {
 	var (
 		db  *gorm.DB //will hold the db connection
 		err error    //error handler for opening connections
 	)
 
 	// Configure GORM’s logger to Warn to keep output readable (Info is very verbose).
- 
+	// It writes through slog (JSON); placeholders only, so bound values (emails, hashes) stay out.
+	// Queries slower than db_slow_query_threshold are logged too, and copied to the Redis log (see gormlog.go).
+	slow, _ := time.ParseDuration(cfg.DBSlowQueryThreshold) // validated in Load
 	gormCfg := &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Warn),
+		Logger:  newGormLogger(slow),
+		NowFunc: func() time.Time { return time.Now().UTC() }, // created_at/updated_at stored in UTC whatever the server's zone
 	}
 
+	var dialector func() gorm.Dialector // a fresh one per attempt: a failed Open leaves nothing to reuse
 	switch cfg.DBDriver {
 	case "mysql":
 		if cfg.MySQLDSN == "" { // Ensure DSN is provided when driver is mysql.
-			log.Fatal("[db] mysql selected but mysql_dsn empty")
+			applog.Fatal("db: mysql selected but mysql_dsn empty")
 		}
-		db, err = gorm.Open(mysql.Open(cfg.MySQLDSN), gormCfg) //open connection
+		dialector = func() gorm.Dialector { return mysql.Open(cfg.MySQLDSN) } //open connection
 	case "postgres":
 		if cfg.PostgresDSN == "" { //ensuree dsn is provided for postgres
-			log.Fatal("[db] postgres selected but postgres_dsn empty")
+			applog.Fatal("db: postgres selected but postgres_dsn empty")
 		}
-		db, err = gorm.Open(postgres.Open(cfg.PostgresDSN), gormCfg)
+		dialector = func() gorm.Dialector { return postgres.Open(cfg.PostgresDSN) }
 	case "sqlite":
 		// SQLite only needs a file path; GORM will create file if missing.
-		db, err = gorm.Open(sqlite.Open(cfg.SQLitePath), gormCfg)
+		dialector = func() gorm.Dialector { return sqlite.Open(cfg.SQLitePath) }
 	case "sqlserver":
 		if cfg.SQLServerDSN == "" { // Ensure DSN is provided for SQL Server.
-			log.Fatal("[db] sqlserver selected but sqlserver_dsn empty")
+			applog.Fatal("db: sqlserver selected but sqlserver_dsn empty")
 		}
-		db, err = gorm.Open(sqlserver.Open(cfg.SQLServerDSN), gormCfg)
+		dialector = func() gorm.Dialector { return sqlserver.Open(cfg.SQLServerDSN) }
 	default:
-		log.Fatalf("[db] unknown DBDriver: %s", cfg.DBDriver) // Fail fast if driver is unsupported.
+		applog.Fatal("db: unknown db_driver", "value", cfg.DBDriver) // Fail fast if driver is unsupported.
 
 	}
 
-	// If gorm.Open returned an error, abort.
+	// gorm.Open pings the server: retried (startup_retry_*) while it isn't accepting connections yet.
+	err = cfg.startupRetry().do("db", func() error {
+		db, err = gorm.Open(dialector(), gormCfg)
+		return err
+	})
+	// If gorm.Open still returned an error, abort.
 	if err != nil {
-		log.Fatalf("[db] connection error: %v", err)
+		applog.Fatal("db: connection error", "driver", cfg.DBDriver, "err", err)
+	}
+	if cfg.DBExplainSlowQueries != ExplainOff && slow > 0 { // never in prod (Load refuses it there)
+		if err := db.Use(newExplainPlugin(cfg.DBExplainSlowQueries, slow)); err != nil {
+			applog.Fatal("db: explain plugin", "err", err)
+		}
 	}
 
-	
+	return db // Return the connected *gorm.DB to be injected into repositories.
+
+}
+
+// Migrate brings the tables in step with the models (AutoMigrate only adds: tables, columns,
+// indexes), records the resulting schema checksum and fills columns added since rows were saved.
+// LastMigration describes the outcome.
+func Migrate(db *gorm.DB) error {
 	// AutoMigrate creates or updates DB tables based on our struct definitions.
 	// Safe for demos/starters; for real projects you may use migrations.
 	// Migrate models (safe baseline)
-	if err := db.AutoMigrate(&models.User{}); err != nil {
-		log.Fatalf("[db] automigrate error: %v", err)
+	LastMigration = Migration{Models: len(migratedModels)}
+	for _, m := range migratedModels { // tables about to be created, for the boot report
+		if stmt := (&gorm.Statement{DB: db}); !db.Migrator().HasTable(m) && stmt.Parse(m) == nil {
+			LastMigration.Created = append(LastMigration.Created, stmt.Schema.Table)
+		}
+	}
+	if err := db.AutoMigrate(migratedModels...); err != nil {
+		return fmt.Errorf("automigrate: %w", err)
+	}
+	schema, err := schemacheck.New(db, migratedModels...)
+	if err != nil {
+		return fmt.Errorf("schema check: %w", err)
 	}
+	if err := schema.Record(global.AppVersion); err != nil { // the schema is now this build's
+		return fmt.Errorf("record schema version: %w", err)
+	}
+	LastMigration.Checksum = schema.Checksum()
+	if err := backfillUserSearch(db); err != nil { // rows saved before the search columns existed
+		return fmt.Errorf("search column backfill: %w", err)
+	}
+	return nil
+}
 
-	return db // Return the connected *gorm.DB to be injected into repositories.
+// MigrateDown drops the migrated tables, data included, in reverse order (schema_versions
+// first). There is no finer-grained down: AutoMigrate keeps no history to step back through.
+func MigrateDown(db *gorm.DB) ([]string, error) {
+	var dropped []string
+	for i := len(migratedModels) - 1; i >= 0; i-- {
+		m := migratedModels[i]
+		if !db.Migrator().HasTable(m) {
+			continue
+		}
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(m); err != nil {
+			return dropped, err
+		}
+		if err := db.Migrator().DropTable(m); err != nil {
+			return dropped, fmt.Errorf("drop %s: %w", stmt.Schema.Table, err)
+		}
+		dropped = append(dropped, stmt.Schema.Table)
+	}
+	return dropped, nil
+}
 
+// backfillUserSearch fills name_search/name_skeleton (kept current by models.User.BeforeSave)
+// for users saved before those columns existed. Cheap once done: the query finds nothing.
+func backfillUserSearch(db *gorm.DB) error {
+	var batch []models.User
+	return db.Select("id", "name").Where("name_search = '' OR name_search IS NULL").
+		FindInBatches(&batch, 500, func(*gorm.DB, int) error {
+			for _, u := range batch {
+				err := db.Model(&models.User{}).Where("id = ?", u.ID).UpdateColumns(map[string]any{
+					"name_search":   core.FoldSearch(u.Name),
+					"name_skeleton": core.SearchSkeleton(u.Name),
+				}).Error
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
 }
@@ -0,0 +1,146 @@
+package config
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Explain modes (db_explain_slow_queries).
+const (
+	ExplainOff     = "off"
+	ExplainPlan    = "explain" // the planner's estimate; the query isn't run again
+	ExplainAnalyze = "analyze" // runs the query a second time and reports actual rows and timings
+)
+
+// explainStartKey is where the query's start time waits between the before and after callbacks.
+const explainStartKey = "explain:start"
+
+// planLimit caps the plan copied into a log entry.
+const planLimit = 4000
+
+type planKey struct{}
+
+// planFrom returns the plan the explain plugin attached to a slow query's context ("" = none).
+func planFrom(ctx context.Context) string {
+	plan, _ := ctx.Value(planKey{}).(string)
+	return plan
+}
+
+// explainPlugin runs EXPLAIN for SELECTs slower than slow and hands the plan to the GORM logger,
+// which adds it to the slow query entry. Only the query processor (First/Find/Count/...) is
+// hooked: its rows are closed by then, so the EXPLAIN can reuse the connection (or transaction)
+// the query ran on. Raw/Row statements and writes are never explained.
+type explainPlugin struct {
+	analyze bool
+	slow    time.Duration
+}
+
+func newExplainPlugin(mode string, slow time.Duration) *explainPlugin {
+	return &explainPlugin{analyze: mode == ExplainAnalyze, slow: slow}
+}
+
+func (p *explainPlugin) Name() string { return "explain" }
+
+// Initialize registers the timing callbacks around gorm:query.
+func (p *explainPlugin) Initialize(db *gorm.DB) error {
+	q := db.Callback().Query()
+	if err := q.Before("gorm:query").Register("explain:before_query", func(db *gorm.DB) {
+		db.InstanceSet(explainStartKey, time.Now())
+	}); err != nil {
+		return err
+	}
+	return q.After("gorm:query").Register("explain:after_query", p.after)
+}
+
+func (p *explainPlugin) after(db *gorm.DB) {
+	v, ok := db.InstanceGet(explainStartKey)
+	if !ok || db.Error != nil || time.Since(v.(time.Time)) < p.slow {
+		return
+	}
+	prefix, ok := explainPrefix(db.Dialector.Name(), p.analyze)
+	if !ok {
+		return
+	}
+	query := db.Statement.SQL.String()
+	if !strings.HasPrefix(strings.ToUpper(strings.TrimSpace(query)), "SELECT") {
+		return
+	}
+	plan, err := explain(db.Statement.Context, db.Statement.ConnPool, prefix+query, db.Statement.Vars)
+	if err != nil {
+		plan = "explain failed: " + err.Error()
+	}
+	db.Statement.Context = context.WithValue(db.Statement.Context, planKey{}, plan)
+}
+
+// explainPrefix is the dialect's EXPLAIN statement. SQLite has no ANALYZE variant; SQL Server
+// only shows plans through session SET options, so it isn't supported.
+func explainPrefix(dialect string, analyze bool) (string, bool) {
+	switch dialect {
+	case "mysql":
+		if analyze {
+			return "EXPLAIN ANALYZE ", true // MySQL 8.0.18+
+		}
+		return "EXPLAIN ", true
+	case "postgres":
+		if analyze {
+			return "EXPLAIN (ANALYZE, BUFFERS) ", true
+		}
+		return "EXPLAIN ", true
+	case "sqlite":
+		return "EXPLAIN QUERY PLAN ", true
+	}
+	return "", false
+}
+
+// explain runs stmt with the query's own bound values (so the plan matches what was run) and
+// renders the result one line per row: the text itself for single-column plans (Postgres, MySQL
+// ANALYZE), "column=value ..." otherwise (MySQL's table, SQLite's query plan).
+func explain(ctx context.Context, pool gorm.ConnPool, stmt string, vars []interface{}) (string, error) {
+	rows, err := pool.QueryContext(ctx, stmt, vars...)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+	cols, err := rows.Columns()
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	vals := make([]sql.NullString, len(cols))
+	dest := make([]interface{}, len(cols))
+	for i := range vals {
+		dest[i] = &vals[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return "", err
+		}
+		if b.Len() > 0 {
+			b.WriteByte('\n')
+		}
+		if len(cols) == 1 {
+			b.WriteString(vals[0].String)
+			continue
+		}
+		sep := ""
+		for i, v := range vals {
+			if v.Valid && v.String != "" {
+				fmt.Fprintf(&b, "%s%s=%s", sep, cols[i], v.String)
+				sep = " "
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return "", err
+	}
+	plan := b.String()
+	if len(plan) > planLimit {
+		plan = plan[:planLimit] + " …(truncated)"
+	}
+	return plan, nil
+}
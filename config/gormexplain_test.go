@@ -0,0 +1,83 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"regexp"
+	"testing"
+	"time"
+
+	applog "HelmyTask/logger"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+type explained struct {
+	ID    uint
+	Email string
+}
+
+func TestExplainPlugin_AttachesPlanToSlowQueryLog(t *testing.T) {
+	var out bytes.Buffer
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(applog.New(&out))
+
+	sqlDB, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+	require.NoError(t, err)
+	defer sqlDB.Close()
+	db, err := gorm.Open(mysql.New(mysql.Config{Conn: sqlDB, SkipInitializeWithVersion: true}), &gorm.Config{Logger: newGormLogger(time.Nanosecond)})
+	require.NoError(t, err)
+	require.NoError(t, db.Use(newExplainPlugin(ExplainPlan, time.Nanosecond)))
+
+	query := regexp.QuoteMeta("SELECT * FROM `explaineds` WHERE email = ?")
+	mock.ExpectQuery(query).WithArgs("a@b.c").WillReturnRows(sqlmock.NewRows([]string{"id", "email"}).AddRow(1, "a@b.c"))
+	mock.ExpectQuery("^EXPLAIN " + query).WithArgs("a@b.c").WillReturnRows(
+		sqlmock.NewRows([]string{"id", "select_type", "table", "type", "key", "rows", "Extra"}).
+			AddRow(1, "SIMPLE", "explaineds", "ALL", nil, 1000, "Using where"))
+
+	var rows []explained
+	require.NoError(t, db.Where("email = ?", "a@b.c").Find(&rows).Error)
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	var line map[string]any
+	require.NoError(t, json.Unmarshal(out.Bytes(), &line))
+	assert.Equal(t, "db: slow query", line["msg"])
+	assert.Equal(t, "id=1 select_type=SIMPLE table=explaineds type=ALL rows=1000 Extra=Using where", line["plan"])
+}
+
+func TestExplainPlugin_FailureIsLoggedNotReturned(t *testing.T) {
+	var out bytes.Buffer
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(applog.New(&out))
+
+	sqlDB, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+	require.NoError(t, err)
+	defer sqlDB.Close()
+	db, err := gorm.Open(mysql.New(mysql.Config{Conn: sqlDB, SkipInitializeWithVersion: true}), &gorm.Config{Logger: newGormLogger(time.Nanosecond)})
+	require.NoError(t, err)
+	require.NoError(t, db.Use(newExplainPlugin(ExplainAnalyze, time.Nanosecond)))
+
+	mock.ExpectQuery("^SELECT").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectQuery("^EXPLAIN ANALYZE SELECT").WillReturnError(errors.New("syntax error"))
+
+	var rows []explained
+	require.NoError(t, db.Find(&rows).Error) // the query's own result stands
+	require.NoError(t, mock.ExpectationsWereMet())
+	assert.Contains(t, out.String(), `"plan":"explain failed: syntax error"`)
+}
+
+func TestExplainPrefix(t *testing.T) {
+	p, ok := explainPrefix("postgres", true)
+	assert.True(t, ok)
+	assert.Equal(t, "EXPLAIN (ANALYZE, BUFFERS) ", p)
+	p, _ = explainPrefix("sqlite", true)
+	assert.Equal(t, "EXPLAIN QUERY PLAN ", p)
+	_, ok = explainPrefix("sqlserver", false)
+	assert.False(t, ok)
+}
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	applog "HelmyTask/logger"
+	"HelmyTask/utils/redislog"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// slowQueryLog is the Redis log slow queries are also written to (nil = stdout only); InitDB
+// runs before Redis is up, so main attaches it later with SetSlowQueryLog.
+var slowQueryLog atomic.Pointer[redislog.Logger]
+
+// SetSlowQueryLog starts (or, with nil, stops) copying slow queries to a Redis log.
+func SetSlowQueryLog(l *redislog.Logger) { slowQueryLog.Store(l) }
+
+// gormLogger is GORM's logger: errors and warnings go to slog as before, and queries slower
+// than slow are logged at warn with their SQL (placeholders only), duration and rows affected,
+// plus the query plan when the explain plugin captured one (see gormexplain.go).
+type gormLogger struct {
+	logger.Interface                 // everything but slow queries
+	level            logger.LogLevel // Silent (e.g. a session's LogMode) mutes slow queries too
+	slow             time.Duration   // 0 = off
+}
+
+func newGormLogger(slow time.Duration) *gormLogger {
+	return &gormLogger{
+		Interface: logger.NewSlogLogger(slog.Default(), logger.Config{IgnoreRecordNotFoundError: true, ParameterizedQueries: true, LogLevel: logger.Warn}),
+		level:     logger.Warn,
+		slow:      slow,
+	}
+}
+
+func (l *gormLogger) LogMode(level logger.LogLevel) logger.Interface {
+	c := *l
+	c.Interface, c.level = l.Interface.LogMode(level), level
+	return &c
+}
+
+// ParamsFilter drops bound values (emails, hashes) from the SQL handed to Trace.
+func (l *gormLogger) ParamsFilter(_ context.Context, sql string, _ ...interface{}) (string, []interface{}) {
+	return sql, nil
+}
+
+func (l *gormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	elapsed := time.Since(begin)
+	failed := err != nil && !errors.Is(err, gorm.ErrRecordNotFound)
+	if l.slow <= 0 || elapsed < l.slow || l.level < logger.Warn || failed {
+		l.Interface.Trace(ctx, begin, fc, err) // failed queries are logged as errors, slow or not
+		return
+	}
+	sql, rows := fc()
+	ms := float64(elapsed.Microseconds()) / 1000
+	attrs := []any{"sql", sql, "duration_ms", ms, "rows", rows, "threshold", l.slow.String()}
+	plan := planFrom(ctx)
+	if plan != "" {
+		attrs = append(attrs, "plan", plan)
+	}
+	slog.WarnContext(ctx, "db: slow query", attrs...)
+	if rl := slowQueryLog.Load(); rl != nil && !applog.Copies(slog.LevelWarn) { // else the sink already copied it
+		meta := map[string]string{
+			"sql":       sql,
+			"rows":      strconv.FormatInt(rows, 10),
+			"threshold": l.slow.String(),
+		}
+		if plan != "" {
+			meta["plan"] = plan
+		}
+		rl.Log("warn", "slow query", redislog.Fields{LatencyMS: ms}, meta)
+	}
+}
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	applog "HelmyTask/logger"
+	"HelmyTask/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm/logger"
+)
+
+func TestGormLogger_SlowQueries(t *testing.T) {
+	var out bytes.Buffer
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(applog.New(&out))
+	rlog, _, m := mocks.NewRedisLoggerWithMock()
+	defer SetSlowQueryLog(nil)
+	SetSlowQueryLog(rlog)
+
+	l := newGormLogger(100 * time.Millisecond)
+	query := func() (string, int64) { return "SELECT * FROM `users` WHERE email = ?", 3 }
+
+	l.Trace(context.Background(), time.Now(), query, nil) // fast: nothing
+	assert.Zero(t, out.Len())
+
+	var pushed []byte
+	m.CustomMatch(func(_, actual []interface{}) error { pushed = actual[2].([]byte); return nil }).ExpectLPush("logs:app", "").SetVal(1)
+	m.ExpectLTrim("logs:app", 0, 99).SetVal("OK")
+	m.ExpectExpire("logs:app", 24*time.Hour).SetVal(true)
+	l.Trace(context.Background(), time.Now().Add(-250*time.Millisecond), query, nil)
+	require.NoError(t, m.ExpectationsWereMet())
+
+	var line map[string]any
+	require.NoError(t, json.Unmarshal(out.Bytes(), &line))
+	assert.Equal(t, "db: slow query", line["msg"])
+	assert.Equal(t, "WARN", line["level"])
+	assert.Equal(t, "SELECT * FROM `users` WHERE email = ?", line["sql"])
+	assert.EqualValues(t, 3, line["rows"])
+	assert.GreaterOrEqual(t, line["duration_ms"], 250.0)
+
+	var entry struct {
+		Level, Msg string
+		LatencyMS  float64 `json:"latency_ms"`
+		Meta       map[string]string
+	}
+	require.NoError(t, json.Unmarshal(pushed, &entry))
+	assert.Equal(t, "slow query", entry.Msg)
+	assert.GreaterOrEqual(t, entry.LatencyMS, 250.0)
+	assert.Equal(t, map[string]string{"sql": "SELECT * FROM `users` WHERE email = ?", "rows": "3", "threshold": "100ms"}, entry.Meta)
+
+	// muted sessions and a zero threshold log nothing
+	out.Reset()
+	l.LogMode(logger.Silent).Trace(context.Background(), time.Now().Add(-time.Second), query, nil)
+	newGormLogger(0).Trace(context.Background(), time.Now().Add(-time.Second), query, nil)
+	assert.Zero(t, out.Len())
+
+	// failed queries stay errors, however long they took
+	newGormLogger(100*time.Millisecond).Trace(context.Background(), time.Now().Add(-time.Second), query, errors.New("deadlock"))
+	assert.Contains(t, out.String(), `"level":"ERROR"`)
+	assert.NotContains(t, out.String(), "slow query")
+	assert.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestGormLogger_DropsBoundValues(t *testing.T) {
+	sql, vars := newGormLogger(time.Second).ParamsFilter(context.Background(), "SELECT 1 WHERE email = ?", "a@b.c")
+	assert.Equal(t, "SELECT 1 WHERE email = ?", sql)
+	assert.Nil(t, vars)
+}
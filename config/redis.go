@@ -2,14 +2,17 @@ package config
 
 import (
 	"context"
-	"log"
+	"log/slog"
 	"time"
 
+	"HelmyTask/logger"
+
 	"github.com/redis/go-redis/v9"
 )
 
 // InitRedis creates a single Redis client and verifies connectivity with Ping.
-// It also configures sane timeouts so the app fails fast if Redis is unreachable.
+// It also configures sane timeouts so the app fails fast if Redis is unreachable
+// (after the startup_retry_* window, for a Redis that is still starting).
 func InitRedis(cfg *Config) *redis.Client {
 	opts := &redis.Options{
 		Addr:        cfg.RedisAddr,
@@ -20,13 +23,26 @@ func InitRedis(cfg *Config) *redis.Client {
 		WriteTimeout: 2 * time.Second,
 		PoolSize:     10,
 		MinIdleConns: 2,
+		MaxRetries:   cfg.RedisMaxRetries, // network errors and transient server states (LOADING, READONLY, ...)
+	}
+	if opts.MaxRetries == 0 {
+		opts.MaxRetries = -1 // go-redis reads 0 as "default" (3)
 	}
+	opts.MinRetryBackoff, opts.MaxRetryBackoff = cfg.redisRetryBackoff()
 	rdb := redis.NewClient(opts)
 
-	// verify connectivity (hard fail if Redis is down)
-	if err := rdb.Ping(context.Background()).Err(); err != nil {
-		log.Fatalf("[redis] ping failed: %v (addr=%s db=%d)", err, cfg.RedisAddr, cfg.RedisDB)
+	// verify connectivity (hard fail if Redis is still down once the retries are spent)
+	err := cfg.startupRetry().do("redis", func() error { return rdb.Ping(context.Background()).Err() })
+	if err != nil {
+		logger.Fatal("redis: ping failed", "addr", cfg.RedisAddr, "db", cfg.RedisDB, "err", err)
 	}
-	log.Printf("[redis] connected: addr=%s db=%d", cfg.RedisAddr, cfg.RedisDB)
+	slog.Info("redis: connected", "addr", cfg.RedisAddr, "db", cfg.RedisDB)
 	return rdb
 }
+
+// redisRetryBackoff is the configured backoff range (validated in Load).
+func (c *Config) redisRetryBackoff() (min, max time.Duration) {
+	min, _ = time.ParseDuration(c.RedisMinRetryBackoff)
+	max, _ = time.ParseDuration(c.RedisMaxRetryBackoff)
+	return min, max
+}
@@ -0,0 +1,55 @@
+// Startup retry: in docker-compose the database and Redis containers start alongside the app,
+// and are often not accepting connections yet when it first dials them. InitDB and InitRedis
+// retry that first connection with exponential backoff (startup_retry_* settings) instead of
+// exiting on the first refusal.
+
+package config
+
+import (
+	"log/slog"
+	"time"
+)
+
+// startupRetry is the backoff schedule for the first connection to a dependency.
+type startupRetry struct {
+	initial, max time.Duration // first pause, doubled after each failure up to max
+	wait         time.Duration // give up once this long has passed (0 = a single attempt)
+	sleep        func(time.Duration)
+	now          func() time.Time
+}
+
+// startupRetry is the configured schedule (validated in Load).
+func (c *Config) startupRetry() startupRetry {
+	r := startupRetry{sleep: time.Sleep, now: time.Now}
+	r.initial, _ = time.ParseDuration(c.StartupRetryInitialBackoff)
+	r.max, _ = time.ParseDuration(c.StartupRetryMaxBackoff)
+	r.wait, _ = time.ParseDuration(c.StartupRetryMaxWait)
+	return r
+}
+
+// do calls connect until it succeeds or the wait is over, and returns its last error.
+func (r startupRetry) do(what string, connect func() error) error {
+	deadline := r.now().Add(r.wait)
+	pause := r.initial
+	for attempt := 1; ; attempt++ {
+		err := connect()
+		if err == nil {
+			if attempt > 1 {
+				slog.Info(what+": connected after retrying", "attempts", attempt)
+			}
+			return nil
+		}
+		left := deadline.Sub(r.now())
+		if left <= 0 {
+			return err
+		}
+		if pause > left {
+			pause = left
+		}
+		slog.Warn(what+": not reachable yet, retrying", "attempt", attempt, "in", pause.String(), "err", err)
+		r.sleep(pause)
+		if pause *= 2; pause > r.max {
+			pause = r.max
+		}
+	}
+}
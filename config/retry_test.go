@@ -0,0 +1,49 @@
+package config
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClock advances only when slept on.
+type fakeClock struct {
+	t      time.Time
+	pauses []time.Duration
+}
+
+func (c *fakeClock) now() time.Time { return c.t }
+func (c *fakeClock) sleep(d time.Duration) {
+	c.pauses = append(c.pauses, d)
+	c.t = c.t.Add(d)
+}
+
+func TestStartupRetry_BacksOffUntilConnected(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(0, 0)}
+	r := startupRetry{initial: time.Second, max: 3 * time.Second, wait: time.Minute, sleep: clock.sleep, now: clock.now}
+	calls := 0
+	err := r.do("db", func() error {
+		if calls++; calls < 5 {
+			return errors.New("connection refused")
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second, 3 * time.Second, 3 * time.Second}, clock.pauses)
+}
+
+func TestStartupRetry_GivesUpAfterMaxWait(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(0, 0)}
+	r := startupRetry{initial: 2 * time.Second, max: 10 * time.Second, wait: 5 * time.Second, sleep: clock.sleep, now: clock.now}
+	refused := errors.New("connection refused")
+	err := r.do("redis", func() error { return refused })
+	assert.ErrorIs(t, err, refused)
+	assert.Equal(t, []time.Duration{2 * time.Second, 3 * time.Second}, clock.pauses) // the last pause is cut to the deadline
+
+	r.wait = 0 // retries off: one attempt
+	clock.pauses = nil
+	assert.ErrorIs(t, r.do("redis", func() error { return refused }), refused)
+	assert.Empty(t, clock.pauses)
+}
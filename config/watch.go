@@ -0,0 +1,103 @@
+// Hot reload: Load watches the config file, and edits to the runtime-tunable keys take effect
+// without a restart. Everything else (DB/Redis connections, ports, secrets...) is fixed at
+// boot; edits to it are logged as needing a restart and otherwise ignored.
+
+package config
+
+import (
+	"log/slog"
+	"reflect"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"HelmyTask/settings"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+)
+
+// Reloadable are the keys a config file edit changes at runtime: the base of settings.Store.
+var Reloadable = map[string]bool{
+	"log_level": true, "log_max_len": true, "log_retention": true, "rate_limits": true, "cache_ttl": true,
+	"maintenance_mode": true, "maintenance_message": true, "feature_flags": true,
+}
+
+var (
+	current   atomic.Pointer[Config]
+	reloadMu  sync.Mutex // serializes reloads + listeners
+	listeners []func(*Config)
+)
+
+// Current is the configuration in effect: what Load returned, with the Reloadable keys as of
+// the latest valid edit of the config file. Safe to call from any goroutine; nil before Load.
+func Current() *Config { return current.Load() }
+
+// OnReload registers fn to be called (from the watcher's goroutine) with the new configuration
+// after each valid edit that changes a Reloadable key.
+func OnReload(fn func(*Config)) {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+	listeners = append(listeners, fn)
+}
+
+// watch reloads the config file whenever it changes.
+func watch(v *viper.Viper) {
+	v.OnConfigChange(func(e fsnotify.Event) { reload(v, e.Name) })
+	v.WatchConfig()
+}
+
+// reload publishes the Reloadable keys of the edited file. An edit that doesn't parse or fails
+// the settings validation is refused as a whole, and the running configuration stays.
+func reload(v *viper.Viper, file string) {
+	var edited Config
+	if err := v.Unmarshal(&edited); err != nil {
+		slog.Warn("config: reload refused", "file", file, "err", err)
+		return
+	}
+	if _, err := settings.New(nil, edited.Settings()); err != nil {
+		slog.Warn("config: reload refused", "file", file, "err", err)
+		return
+	}
+	if edited.TwoFactorKey == "" { // as Load does
+		edited.TwoFactorKey = edited.JWTSecret
+	}
+
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+	old := current.Load()
+	next, changed, restart := merge(old, &edited)
+	if len(restart) > 0 {
+		slog.Warn("config: changes need a restart to apply", "file", file, "keys", restart)
+	}
+	if len(changed) == 0 {
+		return
+	}
+	current.Store(next)
+	slog.Info("config: reloaded", "file", file, "keys", changed)
+	for _, fn := range listeners {
+		fn(next)
+	}
+}
+
+// merge copies the Reloadable fields of edited onto a copy of old, and lists the keys that
+// changed and those that differ but only apply at boot.
+func merge(old, edited *Config) (next *Config, changed, restart []string) {
+	n := *old
+	nv, ev := reflect.ValueOf(&n).Elem(), reflect.ValueOf(edited).Elem()
+	for i := 0; i < nv.NumField(); i++ {
+		key := nv.Type().Field(i).Tag.Get("mapstructure")
+		if reflect.DeepEqual(nv.Field(i).Interface(), ev.Field(i).Interface()) {
+			continue
+		}
+		if Reloadable[key] {
+			nv.Field(i).Set(ev.Field(i))
+			changed = append(changed, key)
+		} else {
+			restart = append(restart, key)
+		}
+	}
+	sort.Strings(changed)
+	sort.Strings(restart)
+	return &n, changed, restart
+}
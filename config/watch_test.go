@@ -0,0 +1,20 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMerge_OnlyReloadableKeysApply(t *testing.T) {
+	old := &Config{LogLevel: "info", CacheTTL: "10m", RedisAddr: "redis:6379", RateLimits: map[string]RateLimitRule{"auth": {RequestsPerMinute: 10}}}
+	edited := &Config{LogLevel: "debug", CacheTTL: "10m", RedisAddr: "other:6379", RateLimits: map[string]RateLimitRule{"auth": {RequestsPerMinute: 20}}}
+
+	next, changed, restart := merge(old, edited)
+	assert.Equal(t, []string{"log_level", "rate_limits"}, changed)
+	assert.Equal(t, []string{"redis_addr"}, restart)
+	assert.Equal(t, "debug", next.LogLevel)
+	assert.Equal(t, 20, next.RateLimits["auth"].RequestsPerMinute)
+	assert.Equal(t, "redis:6379", next.RedisAddr, "connections are fixed at boot")
+	assert.Equal(t, "info", old.LogLevel, "the running config is not modified")
+}
@@ -2,16 +2,34 @@
 // Place for pure domain logic
 package core
 
-import "strings"
+import (
+	"strings"
+
+	"golang.org/x/text/cases"    // Locale-aware Unicode case mapping.
+	"golang.org/x/text/language" // BCP 47 tags for optional locale rules.
+	"golang.org/x/text/unicode/norm"
+)
 
 // Small, framework-agnostic logic demo.
 // NormalizeName is a tiny example of "pure" core logic that doesn't depend on HTTP/DB frameworks.
 // Keeping domain rules here makes it highly testable and reusable.
 func NormalizeName(s string) string {
-	s = strings.TrimSpace(s) // Remove leading/trailing whitespace (clean user input).
-	if s == "" { //if empty after triming , return as 
+	return NormalizeNameLocale(s, language.Und) // Locale-neutral rules.
+}
+
+// NormalizeNameLocale cleans a display name with the casing rules of the given locale:
+//   - NFC composition, so "e"+U+0301 and "é" are stored identically
+//   - trims and collapses internal whitespace runs to a single space
+//   - uppercases the first letter of every word (rune-aware, never splits multi-byte chars)
+//
+// Letters after the first are kept as typed, so "McDonald" and "DeShawn" survive.
+// Locale matters for e.g. Turkish (i → İ) and Dutch (ij → IJ).
+func NormalizeNameLocale(s string, tag language.Tag) string {
+	s = norm.NFC.String(s)                   // Canonical composition first, so casing sees whole characters.
+	s = strings.Join(strings.Fields(s), " ") // Trim + collapse any Unicode whitespace.
+	if s == "" {                             //if empty after triming , return as
 		return s
 	}
-	//upercase first leyyer to standrize display 
-	return strings.ToUpper(s[:1]) + s[1:]
+	// Title-case each word; NoLower keeps the remaining letters untouched.
+	return cases.Title(tag, cases.NoLower).String(s)
 }
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/language"
 )
 
 func TestNormalizeName_Table(t *testing.T) {
@@ -20,6 +21,15 @@ func TestNormalizeName_Table(t *testing.T) {
 		{"single", "a", "A"},
 		{"caps-ok", "Ahmed", "Ahmed"},
 		{"mixed+spaces", "  aHMED  ", "AHMED"},
+		{"multi-word", "ahmed helmy", "Ahmed Helmy"},
+		{"collapse-inner-spaces", "  ahmed \t  helmy ", "Ahmed Helmy"},
+		{"keeps-inner-caps", "mcDonald", "McDonald"},
+		{"multibyte-first", "élodie", "Élodie"},
+		{"multibyte-words", "ömer faruk", "Ömer Faruk"},
+		{"decomposed-to-nfc", "e\u0301lise", "\u00c9lise"},
+		{"greek", "σοφία", "Σοφία"},
+		{"arabic-unchanged", "أحمد حلمي", "أحمد حلمي"},
+		{"nbsp-collapsed", "anna\u00a0maria", "Anna Maria"},
 	}
 
 	// WHEN/THEN: loop & assert
@@ -31,3 +41,23 @@ func TestNormalizeName_Table(t *testing.T) {
 		})
 	}
 }
+
+func TestNormalizeNameLocale_Table(t *testing.T) {
+	tests := []struct {
+		name string
+		tag  language.Tag
+		in   string
+		out  string
+	}{
+		{"turkish-dotted-i", language.Turkish, "istanbul", "İstanbul"},
+		{"neutral-i", language.Und, "istanbul", "Istanbul"},
+		{"dutch-ij", language.Dutch, "ijsselmeer", "IJsselmeer"},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.out, NormalizeNameLocale(tc.in, tc.tag))
+		})
+	}
+}
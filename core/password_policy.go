@@ -0,0 +1,105 @@
+// Configurable password composition policy. The same PasswordPolicy value backs the Gin
+// "password" binding tag and the service-layer checks, so HTTP and non-HTTP entry points agree.
+
+package core
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Extra violation codes for composition rules.
+const (
+	CodePasswordMissingClass = "password_missing_class"
+	CodePasswordBanned       = "password_banned"
+)
+
+// PasswordPolicy describes what a password must look like. The zero value only enforces
+// the baseline (not blank, at most MaxPasswordBytes); use DefaultPasswordPolicy for MinPasswordLen.
+type PasswordPolicy struct {
+	MinLength     int      // runes
+	RequireUpper  bool     // at least one upper-case letter
+	RequireLower  bool     // at least one lower-case letter
+	RequireDigit  bool     // at least one digit
+	RequireSymbol bool     // at least one non-letter, non-digit
+	BanCommon     bool     // reject the built-in list of most common passwords
+	Banned        []string // extra banned passwords (case-insensitive), e.g. the company name
+}
+
+// DefaultPasswordPolicy is the historical rule set: 6+ characters, nothing else.
+func DefaultPasswordPolicy() PasswordPolicy {
+	return PasswordPolicy{MinLength: MinPasswordLen}
+}
+
+// Validate returns every rule pw breaks.
+func (p PasswordPolicy) Validate(pw string) Violations {
+	switch {
+	case strings.TrimSpace(pw) == "":
+		return Violations{{"password", CodePasswordBlank, "must not be blank"}}
+	case len(pw) > MaxPasswordBytes:
+		return Violations{{"password", CodePasswordTooLong, "must be at most 72 bytes"}}
+	}
+
+	var out Violations
+	if utf8.RuneCountInString(pw) < p.MinLength {
+		out = append(out, Violation{"password", CodePasswordTooShort, fmt.Sprintf("must be at least %d characters", p.MinLength)})
+	}
+	if missing := p.missingClasses(pw); len(missing) > 0 {
+		out = append(out, Violation{"password", CodePasswordMissingClass, "must contain " + strings.Join(missing, ", ")})
+	}
+	if p.banned(pw) {
+		out = append(out, Violation{"password", CodePasswordBanned, "is too common"})
+	}
+	return out
+}
+
+// missingClasses lists the required character classes pw lacks.
+func (p PasswordPolicy) missingClasses(pw string) []string {
+	var upper, lower, digit, symbol bool
+	for _, r := range pw {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case !unicode.IsLetter(r):
+			symbol = true
+		}
+	}
+	var missing []string
+	if p.RequireUpper && !upper {
+		missing = append(missing, "an upper-case letter")
+	}
+	if p.RequireLower && !lower {
+		missing = append(missing, "a lower-case letter")
+	}
+	if p.RequireDigit && !digit {
+		missing = append(missing, "a digit")
+	}
+	if p.RequireSymbol && !symbol {
+		missing = append(missing, "a symbol")
+	}
+	return missing
+}
+
+// banned matches the lower-cased password against the configured and built-in lists.
+func (p PasswordPolicy) banned(pw string) bool {
+	lower := strings.ToLower(pw)
+	for _, b := range p.Banned {
+		if strings.ToLower(b) == lower {
+			return true
+		}
+	}
+	if p.BanCommon {
+		for _, c := range commonPasswords {
+			if c == lower {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,38 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPasswordPolicy_Validate(t *testing.T) {
+	strict := PasswordPolicy{MinLength: 10, RequireUpper: true, RequireLower: true, RequireDigit: true, RequireSymbol: true,
+		BanCommon: true, Banned: []string{"HelmyTask2024!"}}
+
+	tests := []struct {
+		name     string
+		p        PasswordPolicy
+		pw       string
+		wantCode []string
+	}{
+		{"default-ok", DefaultPasswordPolicy(), "123456", nil},
+		{"default-short", DefaultPasswordPolicy(), "12345", []string{CodePasswordTooShort}},
+		{"strict-ok", strict, "Blue-Horse-42", nil},
+		{"strict-missing-classes", strict, "bluehorsebattery", []string{CodePasswordMissingClass}},
+		{"strict-short-and-classes", strict, "abc", []string{CodePasswordTooShort, CodePasswordMissingClass}},
+		{"banned-custom-case-insensitive", strict, "helmytask2024!", []string{CodePasswordMissingClass, CodePasswordBanned}},
+		{"banned-common", PasswordPolicy{MinLength: 6, BanCommon: true}, "Password", []string{CodePasswordBanned}},
+		{"blank-short-circuits", strict, "   ", []string{CodePasswordBlank}},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			var codes []string
+			for _, v := range tc.p.Validate(tc.pw) {
+				codes = append(codes, v.Code)
+			}
+			assert.Equal(t, tc.wantCode, codes)
+		})
+	}
+}
@@ -0,0 +1,262 @@
+// zxcvbn-style password strength estimation (pure Go, no external data files).
+// The password is split into the cheapest-to-guess patterns we recognise (common passwords,
+// the user's own name/email, sequences, repeats, keyboard walks); whatever is left is
+// charged at brute-force cost. The guess count maps to a 0..4 score like zxcvbn's.
+
+package core
+
+import (
+	"math"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// StrengthResult is the outcome of EstimatePasswordStrength.
+type StrengthResult struct {
+	Score        int      `json:"score"`         // 0 (too guessable) .. 4 (very unguessable)
+	GuessesLog10 float64  `json:"guesses_log10"` // estimated guesses, as a power of ten
+	Warning      string   `json:"warning,omitempty"`
+	Suggestions  []string `json:"suggestions,omitempty"`
+}
+
+// commonPasswords is a short, ranked list of the most common passwords/words (rank = index+1).
+var commonPasswords = []string{
+	"password", "123456", "12345678", "qwerty", "123456789", "12345", "1234", "111111", "1234567",
+	"dragon", "123123", "baseball", "abc123", "football", "monkey", "letmein", "696969", "shadow",
+	"master", "666666", "qwertyuiop", "123321", "mustang", "1234567890", "michael", "654321",
+	"superman", "1qaz2wsx", "7777777", "121212", "000000", "qazwsx", "123qwe", "killer", "trustno1",
+	"jordan", "jennifer", "zxcvbnm", "asdfgh", "hunter", "buster", "soccer", "harley", "batman",
+	"andrew", "tigger", "sunshine", "iloveyou", "whatever", "2000", "charlie", "robert", "thomas",
+	"hockey", "ranger", "daniel", "starwars", "klaster", "112233", "george", "computer", "michelle",
+	"jessica", "pepper", "1111", "zxcvbn", "555555", "11111111", "131313", "freedom", "777777",
+	"pass", "maggie", "159753", "aaaaaa", "ginger", "princess", "joshua", "cheese", "amanda",
+	"summer", "love", "ashley", "nicole", "chelsea", "biteme", "matthew", "access", "yankees",
+	"987654321", "dallas", "austin", "thunder", "taylor", "matrix", "admin", "welcome", "login",
+	"passw0rd", "secret", "hello", "changeme", "ahmed", "egypt", "cairo", "default", "test",
+}
+
+// keyboardRows are the rows used to detect keyboard walks like "qwerty" or "asdf".
+var keyboardRows = []string{"1234567890", "qwertyuiop", "asdfghjkl", "zxcvbnm"}
+
+// leet maps common l33t substitutions back to letters before dictionary lookups.
+var leet = strings.NewReplacer("0", "o", "1", "l", "3", "e", "4", "a", "5", "s", "7", "t", "@", "a", "$", "s", "!", "i")
+
+// match is one recognised pattern covering runes [i, j).
+type match struct {
+	i, j    int
+	kind    string
+	guesses float64
+}
+
+// EstimatePasswordStrength scores pw; userInputs (name, email...) count as known words.
+func EstimatePasswordStrength(pw string, userInputs ...string) StrengthResult {
+	runes := []rune(pw)
+	if len(runes) == 0 {
+		return StrengthResult{Score: 0, Warning: "Password is empty."}
+	}
+	lower := []rune(strings.ToLower(pw))
+	words := dictionary(userInputs)
+
+	// Greedy left-to-right: take the longest pattern at each position, else one brute-force char.
+	var (
+		log10 float64
+		kinds = map[string]bool{}
+	)
+	for i := 0; i < len(runes); {
+		m := bestMatch(runes, lower, i, words)
+		if m == nil {
+			log10 += math.Log10(charCardinality(runes[i]))
+			i++
+			continue
+		}
+		log10 += math.Log10(m.guesses)
+		kinds[m.kind] = true
+		i = m.j
+	}
+
+	res := StrengthResult{GuessesLog10: math.Round(log10*100) / 100}
+	switch {
+	case log10 < 3:
+		res.Score = 0
+	case log10 < 6:
+		res.Score = 1
+	case log10 < 8:
+		res.Score = 2
+	case log10 < 10:
+		res.Score = 3
+	default:
+		res.Score = 4
+	}
+	res.Warning, res.Suggestions = feedback(res.Score, kinds, len(runes))
+	return res
+}
+
+// dictionary merges common passwords with tokens from the user's own inputs.
+func dictionary(userInputs []string) map[string]float64 {
+	words := make(map[string]float64, len(commonPasswords)+8)
+	for rank, w := range commonPasswords {
+		words[w] = float64(rank + 1)
+	}
+	for _, in := range userInputs {
+		// split "ahmed.helmy@example.com" into "ahmed", "helmy", "example"...
+		for _, tok := range strings.FieldsFunc(strings.ToLower(in), func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) {
+			if utf8.RuneCountInString(tok) >= 3 {
+				words[tok] = 1 // the attacker knows these first
+			}
+		}
+	}
+	return words
+}
+
+// bestMatch returns the longest pattern starting at i, or nil.
+func bestMatch(runes, lower []rune, i int, words map[string]float64) *match {
+	var best *match
+	consider := func(m *match) {
+		if m != nil && (best == nil || m.j-m.i > best.j-best.i) {
+			best = m
+		}
+	}
+	consider(dictMatch(runes, lower, i, words))
+	consider(repeatMatch(runes, i))
+	consider(sequenceMatch(lower, i))
+	consider(keyboardMatch(lower, i))
+	return best
+}
+
+// dictMatch finds the longest dictionary word (also after undoing l33t) starting at i.
+func dictMatch(runes, lower []rune, i int, words map[string]float64) *match {
+	var best *match
+	for j := len(lower); j >= i+3; j-- {
+		plain := string(lower[i:j])
+		unleet := leet.Replace(plain)
+		rank, ok := words[plain]
+		l33t := false
+		if !ok {
+			rank, ok = words[unleet]
+			l33t = ok
+		}
+		if !ok {
+			continue
+		}
+		g := rank
+		if hasUpper(runes[i:j]) {
+			g *= 2 // "Password" is barely harder than "password"
+		}
+		if l33t {
+			g *= 2
+		}
+		best = &match{i: i, j: j, kind: "dictionary", guesses: math.Max(g, 10)}
+		break // longest first
+	}
+	return best
+}
+
+// repeatMatch finds runs like "aaaa" (3+ identical runes).
+func repeatMatch(runes []rune, i int) *match {
+	j := i + 1
+	for j < len(runes) && runes[j] == runes[i] {
+		j++
+	}
+	if j-i < 3 {
+		return nil
+	}
+	return &match{i: i, j: j, kind: "repeat", guesses: charCardinality(runes[i]) * float64(j-i)}
+}
+
+// sequenceMatch finds runs like "abcd" or "9876" (3+ runes stepping by ±1).
+func sequenceMatch(lower []rune, i int) *match {
+	if i+2 >= len(lower) {
+		return nil
+	}
+	step := lower[i+1] - lower[i]
+	if step != 1 && step != -1 {
+		return nil
+	}
+	j := i + 1
+	for j < len(lower) && lower[j]-lower[j-1] == step {
+		j++
+	}
+	if j-i < 3 {
+		return nil
+	}
+	base := 26.0
+	if unicode.IsDigit(lower[i]) {
+		base = 10
+	}
+	return &match{i: i, j: j, kind: "sequence", guesses: base * float64(j-i)}
+}
+
+// keyboardMatch finds walks of 4+ adjacent keys on one keyboard row ("asdf", "poiu").
+func keyboardMatch(lower []rune, i int) *match {
+	best := 0
+	for _, row := range keyboardRows {
+		r := []rune(row)
+		for k := range r {
+			if r[k] != lower[i] {
+				continue
+			}
+			for _, dir := range []int{1, -1} {
+				n := 1
+				for i+n < len(lower) && k+dir*n >= 0 && k+dir*n < len(r) && lower[i+n] == r[k+dir*n] {
+					n++
+				}
+				if n > best {
+					best = n
+				}
+			}
+		}
+	}
+	if best < 4 {
+		return nil
+	}
+	return &match{i: i, j: i + best, kind: "keyboard", guesses: 50 * float64(best)}
+}
+
+// charCardinality is the brute-force alphabet size for a rune's class.
+func charCardinality(r rune) float64 {
+	switch {
+	case unicode.IsDigit(r):
+		return 10
+	case unicode.IsLower(r):
+		return 26
+	case unicode.IsUpper(r):
+		return 26
+	case r < utf8.RuneSelf:
+		return 33 // ASCII symbols and space
+	default:
+		return 100 // any other script: attackers rarely enumerate these
+	}
+}
+
+func hasUpper(rs []rune) bool {
+	for _, r := range rs {
+		if unicode.IsUpper(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// feedback mirrors zxcvbn's hints for the weakest patterns found.
+func feedback(score int, kinds map[string]bool, length int) (string, []string) {
+	if score >= 3 {
+		return "", nil
+	}
+	var warning string
+	switch {
+	case kinds["dictionary"]:
+		warning = "This is similar to a commonly used password or your personal details."
+	case kinds["keyboard"]:
+		warning = "Straight rows of keys are easy to guess."
+	case kinds["sequence"]:
+		warning = "Sequences like abc or 6543 are easy to guess."
+	case kinds["repeat"]:
+		warning = "Repeats like \"aaa\" are easy to guess."
+	}
+	suggestions := []string{"Add another word or two. Uncommon words are better."}
+	if length < 12 {
+		suggestions = append(suggestions, "Use a longer password; length beats complexity.")
+	}
+	return warning, suggestions
+}
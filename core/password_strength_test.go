@@ -0,0 +1,42 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEstimatePasswordStrength_Table(t *testing.T) {
+	tests := []struct {
+		name     string
+		pw       string
+		inputs   []string
+		maxScore int // weak passwords must not score above this
+		minScore int // strong passwords must reach at least this
+	}{
+		{"empty", "", nil, 0, 0},
+		{"common", "password", nil, 0, 0},
+		{"common-capitalized-leet", "P@ssw0rd", nil, 1, 0},
+		{"digits-sequence", "123456789", nil, 0, 0},
+		{"keyboard-walk", "qwertyuiop", nil, 0, 0},
+		{"repeat", "aaaaaaaaaa", nil, 0, 0},
+		{"own-name", "ahmedhelmy", []string{"Ahmed Helmy", "ahmed.helmy@example.com"}, 1, 0},
+		{"passphrase", "correct horse battery staple", nil, 4, 4},
+		{"random-mixed", "xK9#mP2$vL7q", nil, 4, 4},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got := EstimatePasswordStrength(tc.pw, tc.inputs...)
+			assert.LessOrEqual(t, got.Score, tc.maxScore, "guesses_log10=%v", got.GuessesLog10)
+			assert.GreaterOrEqual(t, got.Score, tc.minScore, "guesses_log10=%v", got.GuessesLog10)
+		})
+	}
+}
+
+func TestEstimatePasswordStrength_FeedbackForWeak(t *testing.T) {
+	got := EstimatePasswordStrength("password")
+	assert.NotEmpty(t, got.Warning)
+	assert.NotEmpty(t, got.Suggestions)
+}
@@ -0,0 +1,27 @@
+package core
+
+import "time"
+
+// CodeDateOfBirthInvalid: unparsable, future, or implausibly old birth date.
+const CodeDateOfBirthInvalid = "date_of_birth_invalid"
+
+const (
+	DateLayout   = "2006-01-02" // date_of_birth format
+	minBirthYear = 1900
+)
+
+// ValidateDateOfBirth checks a YYYY-MM-DD birth date is real and not in the future.
+// The empty string (clearing the field) is valid.
+func ValidateDateOfBirth(s string, now time.Time) Violations {
+	if s == "" {
+		return nil
+	}
+	d, err := time.Parse(DateLayout, s)
+	if err != nil {
+		return Violations{{"date_of_birth", CodeDateOfBirthInvalid, "must be a date as YYYY-MM-DD"}}
+	}
+	if d.After(now) || d.Year() < minBirthYear {
+		return Violations{{"date_of_birth", CodeDateOfBirthInvalid, "must be a past date after 1900"}}
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+// Search keys for user names: accent folding plus Arabic → Latin transliteration, so a search
+// for "ahmed" finds "Ahméd" and "أحمد".
+package core
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// transliterations maps letters that don't decompose to a base Latin letter. Arabic follows a
+// simplified Egyptian-friendly romanization; hamza seats (أ إ ؤ ئ) reach here already
+// stripped by NFD, so they map through their carrier letter.
+var transliterations = map[rune]string{
+	// Latin letters without a canonical decomposition.
+	'ß': "ss", 'æ': "ae", 'œ': "oe", 'ø': "o", 'ł': "l", 'đ': "d", 'ð': "d", 'þ': "th", 'ı': "i",
+
+	// Arabic.
+	'ا': "a", 'ٱ': "a", 'ى': "a", 'ة': "a", 'ب': "b", 'ت': "t", 'ث': "th", 'ج': "g", 'ح': "h",
+	'خ': "kh", 'د': "d", 'ذ': "z", 'ر': "r", 'ز': "z", 'س': "s", 'ش': "sh", 'ص': "s", 'ض': "d",
+	'ط': "t", 'ظ': "z", 'ع': "a", 'غ': "gh", 'ف': "f", 'ق': "k", 'ك': "k", 'ل': "l", 'م': "m",
+	'ن': "n", 'ه': "h", 'و': "w", 'ي': "y", 'ء': "", 'ـ': "", // tatweel is decoration
+
+	// Persian/Urdu letters common in names.
+	'پ': "p", 'چ': "ch", 'ژ': "zh", 'گ': "g", 'ک': "k", 'ی': "y",
+}
+
+// FoldSearch lower-cases s, strips accents and Arabic vowel marks, transliterates Arabic to
+// Latin and collapses whitespace: "  Ahméd " → "ahmed", "أحمد" → "ahmd". Other scripts pass
+// through lower-cased.
+func FoldSearch(s string) string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(strings.ToLower(s)) {
+		switch {
+		case unicode.Is(unicode.Mn, r): // combining accents, harakat, hamza above/below
+			continue
+		case r >= '٠' && r <= '٩': // Arabic-Indic digits
+			b.WriteRune('0' + r - '٠')
+			continue
+		case r >= '۰' && r <= '۹': // Persian digits
+			b.WriteRune('0' + r - '۰')
+			continue
+		}
+		if t, ok := transliterations[r]; ok {
+			b.WriteString(t)
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return strings.Join(strings.Fields(norm.NFC.String(b.String())), " ")
+}
+
+// SearchSkeleton reduces each word of FoldSearch(s) to its consonants, since Arabic script
+// mostly omits short vowels and romanizations disagree on them: "Mohamed", "Muhammad" and
+// "محمد" all become "mhmd". A leading vowel is kept as "a" (so "ahmed" and "أحمد" are "ahmd"),
+// a, e, i, o, u, w and y are dropped elsewhere, and doubled letters count once.
+func SearchSkeleton(s string) string {
+	words := strings.Fields(FoldSearch(s))
+	out := words[:0]
+	for _, w := range words {
+		if k := skeletonWord(w); k != "" {
+			out = append(out, k)
+		}
+	}
+	return strings.Join(out, " ")
+}
+
+func skeletonWord(w string) string {
+	var (
+		b    strings.Builder
+		prev rune
+	)
+	for _, r := range w {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			continue
+		}
+		if b.Len() == 0 && strings.ContainsRune("aeiou", r) {
+			r = 'a' // "omar"/"عمر", "eman"/"إيمان"
+		} else if b.Len() > 0 && strings.ContainsRune("aeiouwy", r) {
+			prev = r
+			continue
+		}
+		if r != prev {
+			b.WriteRune(r)
+		}
+		prev = r
+	}
+	return b.String()
+}
@@ -0,0 +1,42 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFoldSearch(t *testing.T) {
+	tests := []struct{ in, out string }{
+		{"  Ahméd  Helmy ", "ahmed helmy"},
+		{"Élise", "elise"}, // decomposed accent
+		{"Straße", "strasse"},
+		{"Łukasz Søren", "lukasz soren"},
+		{"أحمد", "ahmd"},
+		{"مُحَمَّد", "mhmd"}, // harakat and shadda dropped
+		{"فاطمة", "fatma"},
+		{"خالد ٣", "khald 3"},
+		{"Борис", "борис"}, // other scripts: lower-cased only
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.out, FoldSearch(tt.in), tt.in)
+	}
+}
+
+func TestSearchSkeleton_RomanizationsMeet(t *testing.T) {
+	groups := [][]string{
+		{"Ahmed", "Ahmad", "Ahméd", "أحمد"},
+		{"Mohamed", "Muhammad", "Mohammed", "محمد"},
+		{"Youssef", "Yousef", "يوسف"},
+		{"Omar", "عمر"},
+		{"Ibrahim", "إبراهيم"},
+		{"Mahmoud", "محمود"},
+	}
+	for _, g := range groups {
+		for _, name := range g[1:] {
+			assert.Equal(t, SearchSkeleton(g[0]), SearchSkeleton(name), "%s vs %s", g[0], name)
+		}
+	}
+	assert.Equal(t, "ahmd hlm", SearchSkeleton("Ahmed Helmy"))
+	assert.Empty(t, SearchSkeleton(" - "))
+}
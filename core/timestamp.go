@@ -0,0 +1,88 @@
+// One timestamp format on the wire: RFC 3339 in UTC, whole seconds ("2026-10-16T09:30:00Z"),
+// whatever zone or precision the database driver handed back. Inputs are more forgiving.
+
+package core
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"time"
+)
+
+// ErrInvalidTimestamp is returned by ParseTimestamp for input in none of the accepted formats.
+var ErrInvalidTimestamp = errors.New("invalid timestamp (want RFC 3339, e.g. 2026-10-16T09:30:00Z)")
+
+// inputLayouts are tried in order; layouts without a zone are read as UTC.
+var inputLayouts = []string{
+	time.RFC3339Nano, // 2026-10-16T09:30:00.123+02:00 (covers plain RFC 3339 too)
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05",
+	DateLayout, // midnight UTC
+}
+
+// ParseTimestamp reads RFC 3339 (any precision, any offset), the same without a zone or with a
+// space instead of "T", a bare date, or Unix seconds. The result is in UTC.
+func ParseTimestamp(s string) (time.Time, error) {
+	for _, layout := range inputLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil && n >= 0 {
+		return time.Unix(n, 0).UTC(), nil
+	}
+	return time.Time{}, ErrInvalidTimestamp
+}
+
+// FormatTimestamp renders t as RFC 3339 in UTC, truncated to the second.
+func FormatTimestamp(t time.Time) string { return t.UTC().Format(time.RFC3339) }
+
+// Timestamp is a time.Time with the API's JSON form: FormatTimestamp out, ParseTimestamp in
+// (a JSON number is read as Unix seconds). Models keep time.Time fields for GORM and use it in
+// their MarshalJSON.
+type Timestamp time.Time
+
+// TimestampPtr converts an optional time (nil stays nil, so omitempty still works).
+func TimestampPtr(t *time.Time) *Timestamp {
+	if t == nil {
+		return nil
+	}
+	ts := Timestamp(*t)
+	return &ts
+}
+
+// Time returns the underlying time.
+func (t Timestamp) Time() time.Time { return time.Time(t) }
+
+// TimePtr is the inverse of TimestampPtr (nil stays nil).
+func (t *Timestamp) TimePtr() *time.Time {
+	if t == nil {
+		return nil
+	}
+	tt := time.Time(*t)
+	return &tt
+}
+
+// MarshalJSON implements json.Marshaler.
+func (t Timestamp) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + FormatTimestamp(time.Time(t)) + `"`), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler; null leaves t unchanged.
+func (t *Timestamp) UnmarshalJSON(b []byte) error {
+	if bytes.Equal(b, []byte("null")) {
+		return nil
+	}
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		s = string(b) // a number
+	}
+	parsed, err := ParseTimestamp(s)
+	if err != nil {
+		return err
+	}
+	*t = Timestamp(parsed)
+	return nil
+}
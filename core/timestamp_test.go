@@ -0,0 +1,57 @@
+package core
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTimestamp(t *testing.T) {
+	want := time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC)
+	for _, in := range []string{
+		"2026-10-16T09:30:00Z",
+		"2026-10-16T12:30:00+03:00",
+		"2026-10-16T09:30:00.000Z",
+		"2026-10-16T09:30:00",
+		"2026-10-16 09:30:00",
+		"2026-10-16 11:30:00+02:00",
+		"1792143000",
+	} {
+		got, err := ParseTimestamp(in)
+		require.NoError(t, err, in)
+		assert.True(t, want.Equal(got), in)
+		assert.Equal(t, time.UTC, got.Location(), in)
+	}
+
+	got, err := ParseTimestamp("2026-10-16")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC), got)
+
+	for _, in := range []string{"", "yesterday", "16/10/2026", "-5"} {
+		_, err := ParseTimestamp(in)
+		assert.ErrorIs(t, err, ErrInvalidTimestamp, in)
+	}
+}
+
+func TestTimestamp_JSON(t *testing.T) {
+	cairo := time.FixedZone("EET", 3*3600)
+	b, err := json.Marshal(Timestamp(time.Date(2026, 10, 16, 12, 30, 0, 123456789, cairo)))
+	require.NoError(t, err)
+	assert.Equal(t, `"2026-10-16T09:30:00Z"`, string(b))
+
+	var v struct {
+		A *Timestamp `json:"a"`
+		B Timestamp  `json:"b"`
+		C *Timestamp `json:"c"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(`{"a":"2026-10-16 09:30:00","b":1792143000,"c":null}`), &v))
+	want := time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC)
+	assert.Equal(t, want, v.A.Time())
+	assert.Equal(t, want, v.B.Time())
+	assert.Nil(t, v.C.TimePtr())
+
+	assert.Error(t, json.Unmarshal([]byte(`{"a":"soon"}`), &v))
+}
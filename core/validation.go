@@ -0,0 +1,135 @@
+// Business validation rules as pure functions.
+// Binding tags only cover the HTTP path; these run in the service layer so every
+// entry point (HTTP, imports, CLI, future gRPC) enforces the same rules.
+
+package core
+
+import (
+	"net/mail"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Violation codes are stable identifiers clients can switch on.
+const (
+	CodeEmailInvalid     = "email_invalid"
+	CodeEmailTooLong     = "email_too_long"
+	CodePasswordTooShort = "password_too_short"
+	CodePasswordTooLong  = "password_too_long"
+	CodePasswordBlank    = "password_blank"
+	CodePasswordWeak     = "password_weak"
+	CodePasswordReused   = "password_reused"
+	CodeNameLength       = "name_length"
+	CodeNameCharset      = "name_charset"
+	CodeNameReserved     = "name_reserved"
+	CodeSortInvalid      = "sort_invalid"   // list endpoints: unknown sort column or order
+	CodeCursorInvalid    = "cursor_invalid" // list endpoints: malformed pagination cursor
+	CodeFormatInvalid    = "format_invalid" // export endpoints: unsupported file format
+	CodeColumnInvalid    = "column_invalid" // export endpoints: unknown column
+)
+
+// Limits mirror the DB column sizes and bcrypt's input limit.
+const (
+	MaxEmailLen      = 180 // users.email size
+	MinNameLen       = 2   // runes, after normalization
+	MaxNameLen       = 120 // users.name size
+	MinPasswordLen   = 6
+	MaxPasswordBytes = 72 // bcrypt silently ignores anything longer
+)
+
+// reservedNames can't be used as display names (case-insensitive) to avoid impersonation.
+var reservedNames = map[string]struct{}{
+	"admin": {}, "administrator": {}, "root": {}, "system": {}, "support": {}, "null": {}, "undefined": {},
+}
+
+// Violation is one broken rule on one field.
+type Violation struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Violations is a list of broken rules; it implements error so services can return it directly.
+type Violations []Violation
+
+// Error joins the messages so plain-text clients still get something readable.
+func (v Violations) Error() string {
+	msgs := make([]string, 0, len(v))
+	for _, x := range v {
+		msgs = append(msgs, x.Field+": "+x.Message)
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Err returns nil for an empty list, so callers can `if err := v.Err(); err != nil`.
+func (v Violations) Err() error {
+	if len(v) == 0 {
+		return nil
+	}
+	return v
+}
+
+// ValidateEmail checks the address is a bare RFC 5322 addr-spec with a dotted domain.
+func ValidateEmail(email string) Violations {
+	if len(email) > MaxEmailLen {
+		return Violations{{"email", CodeEmailTooLong, "must be at most 180 characters"}}
+	}
+	addr, err := mail.ParseAddress(email)
+	// Reject display-name forms ("Bob <b@x.io>") and single-label domains ("a@localhost").
+	if err != nil || addr.Address != email || !strings.Contains(email[strings.LastIndex(email, "@")+1:], ".") {
+		return Violations{{"email", CodeEmailInvalid, "must be a valid email address"}}
+	}
+	return nil
+}
+
+// ValidatePassword applies the default password policy.
+func ValidatePassword(pw string) Violations {
+	return DefaultPasswordPolicy().Validate(pw)
+}
+
+// ValidatePasswordStrength rejects passwords whose estimated score is below minScore (0 disables).
+// userInputs (name, email) are treated as words an attacker would try first.
+func ValidatePasswordStrength(pw string, minScore int, userInputs ...string) Violations {
+	if minScore <= 0 {
+		return nil
+	}
+	res := EstimatePasswordStrength(pw, userInputs...)
+	if res.Score >= minScore {
+		return nil
+	}
+	msg := "is too easy to guess"
+	if res.Warning != "" {
+		msg += ": " + res.Warning
+	}
+	return Violations{{"password", CodePasswordWeak, msg}}
+}
+
+// ValidateName checks an already-normalized display name.
+func ValidateName(name string) Violations {
+	var out Violations
+	if n := utf8.RuneCountInString(name); n < MinNameLen || n > MaxNameLen {
+		out = append(out, Violation{"name", CodeNameLength, "must be between 2 and 120 characters"})
+	}
+	for _, r := range name {
+		// letters (any script), combining marks, and a few name punctuation characters
+		if !unicode.IsLetter(r) && !unicode.Is(unicode.M, r) && !strings.ContainsRune(" '-.", r) {
+			out = append(out, Violation{"name", CodeNameCharset, "may only contain letters, spaces, apostrophes, hyphens and periods"})
+			break
+		}
+	}
+	if _, ok := reservedNames[strings.ToLower(name)]; ok {
+		out = append(out, Violation{"name", CodeNameReserved, "is reserved"})
+	}
+	return out
+}
+
+// ValidateRegistration runs every rule for a new account, with the password checked against
+// policy, and returns all violations at once.
+func ValidateRegistration(name, email, password string, policy PasswordPolicy) Violations {
+	var out Violations
+	out = append(out, ValidateName(name)...)
+	out = append(out, ValidateEmail(email)...)
+	out = append(out, policy.Validate(password)...)
+	return out
+}
@@ -0,0 +1,72 @@
+package core
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateRegistration_Table(t *testing.T) {
+	tests := []struct {
+		name     string
+		in       [3]string // name, email, password
+		wantCode []string
+	}{
+		{"valid", [3]string{"Ahmed Helmy", "a@b.co", "123456"}, nil},
+		{"valid-unicode-name", [3]string{"Élodie O'Neil-Smith", "e@x.fr", "secret1"}, nil},
+		{"email-display-name", [3]string{"Ahmed", "Bob <b@x.io>", "123456"}, []string{CodeEmailInvalid}},
+		{"email-no-dot-domain", [3]string{"Ahmed", "a@localhost", "123456"}, []string{CodeEmailInvalid}},
+		{"email-too-long", [3]string{"Ahmed", strings.Repeat("a", 175) + "@x.com", "123456"}, []string{CodeEmailTooLong}},
+		{"password-short", [3]string{"Ahmed", "a@b.co", "12345"}, []string{CodePasswordTooShort}},
+		{"password-blank", [3]string{"Ahmed", "a@b.co", "       "}, []string{CodePasswordBlank}},
+		{"password-over-bcrypt", [3]string{"Ahmed", "a@b.co", strings.Repeat("x", 73)}, []string{CodePasswordTooLong}},
+		{"name-digits", [3]string{"R2D2", "a@b.co", "123456"}, []string{CodeNameCharset}},
+		{"name-reserved", [3]string{"Admin", "a@b.co", "123456"}, []string{CodeNameReserved}},
+		{"name-short-and-bad-email", [3]string{"A", "nope", "123456"}, []string{CodeNameLength, CodeEmailInvalid}},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got := ValidateRegistration(tc.in[0], tc.in[1], tc.in[2], DefaultPasswordPolicy())
+			var codes []string
+			for _, v := range got {
+				codes = append(codes, v.Code)
+			}
+			assert.Equal(t, tc.wantCode, codes)
+		})
+	}
+}
+
+func TestViolations_ErrNilWhenEmpty(t *testing.T) {
+	assert.NoError(t, Violations(nil).Err())
+
+	err := ValidateName("root").Err()
+	var v Violations
+	assert.True(t, errors.As(err, &v))
+	assert.Equal(t, "name: is reserved", err.Error())
+}
+
+func TestParseValues(t *testing.T) {
+	id, err := ParseUserID("42")
+	assert.NoError(t, err)
+	assert.Equal(t, UserID(42), id)
+	assert.Equal(t, "42", id.String())
+
+	_, err = ParseUserID("0")
+	assert.ErrorIs(t, err, ErrInvalidUserID)
+	_, err = ParseUserID("-1")
+	assert.ErrorIs(t, err, ErrInvalidUserID)
+
+	e, err := ParseEmail("  Ahmed@Example.COM ")
+	assert.NoError(t, err)
+	assert.Equal(t, Email("Ahmed@example.com"), e) // domain folded, local part kept
+
+	_, err = ParseEmail("nope")
+	assert.Error(t, err)
+
+	assert.Equal(t, Email("Ops@intranet"), NormalizeEmail(" Ops@INTRANET ")) // no validation
+	assert.Equal(t, Email("nope"), NormalizeEmail("nope"))
+}
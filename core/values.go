@@ -0,0 +1,52 @@
+// Value objects for identifiers that are easy to mix up as raw strings/uints.
+// Distinct types make the compiler catch swapped arguments (e.g. keyID vs userID).
+
+package core
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// UserID identifies a user. Zero is never a valid ID.
+type UserID uint
+
+// ErrInvalidUserID is returned by ParseUserID for non-numeric or zero input.
+var ErrInvalidUserID = errors.New("invalid user id")
+
+// ParseUserID parses a base-10 ID from a URL param or claim.
+func ParseUserID(s string) (UserID, error) {
+	n, err := strconv.ParseUint(s, 10, 0)
+	if err != nil || n == 0 {
+		return 0, ErrInvalidUserID
+	}
+	return UserID(n), nil
+}
+
+// String renders the ID for logs and cache keys.
+func (id UserID) String() string { return strconv.FormatUint(uint64(id), 10) }
+
+// Email is a validated address with a lowercased domain (local parts are case-sensitive per RFC 5321,
+// so they are kept as typed).
+type Email string
+
+// ParseEmail trims, validates (see ValidateEmail) and canonicalizes an address.
+func ParseEmail(s string) (Email, error) {
+	s = strings.TrimSpace(s)
+	if v := ValidateEmail(s); len(v) > 0 {
+		return "", v
+	}
+	return NormalizeEmail(s), nil
+}
+
+// NormalizeEmail trims and canonicalizes s like ParseEmail, without validating it: for lookups
+// such as login, where an address that was never valid simply matches no account.
+func NormalizeEmail(s string) Email {
+	s = strings.TrimSpace(s)
+	at := strings.LastIndex(s, "@")
+	return Email(s[:at+1] + strings.ToLower(s[at+1:]))
+}
+
+// String returns the address.
+func (e Email) String() string { return string(e) }
@@ -0,0 +1,225 @@
+// Package emailtmpl renders transactional emails (welcome, password reset, ...) in the
+// recipient's language.
+//
+// Each kind has one variant per locale under templates/<kind>/<locale>.tmpl, defining
+// "subject", "text" and "html". A recipient's locale (User.Locale, BCP 47) is resolved through a
+// fallback chain, e.g. "ar-EG" → "ar" → the default locale, and the first variant found wins.
+// HTML bodies are wrapped in templates/layout.html with lang/dir set from the chosen locale, so
+// right-to-left scripts (Arabic, Hebrew, ...) lay out correctly in mail clients.
+package emailtmpl
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"text/template"
+
+	"golang.org/x/text/language"
+)
+
+//go:embed templates
+var builtin embed.FS
+
+// ErrUnknownKind is returned for a kind with no templates at all.
+var ErrUnknownKind = errors.New("emailtmpl: unknown email kind")
+
+// rtlScripts are the scripts written right to left.
+var rtlScripts = map[string]bool{"Arab": true, "Hebr": true, "Thaa": true, "Syrc": true, "Nkoo": true, "Adlm": true}
+
+// Data is what templates see. Link is the call to action (reset/verify URL), if any.
+type Data struct {
+	AppName string
+	Name    string
+	Link    string
+	Expires string // human-readable validity of Link, e.g. "1 hour"
+}
+
+// Message is a rendered email.
+type Message struct {
+	Kind    string   `json:"kind"`
+	Locale  string   `json:"locale"` // variant used
+	Dir     string   `json:"dir"`    // ltr|rtl
+	Chain   []string `json:"chain"`  // locales tried, in order
+	Subject string   `json:"subject"`
+	Text    string   `json:"text"`
+	HTML    string   `json:"html"`
+}
+
+// variant is one parsed <kind>/<locale>.tmpl.
+type variant struct {
+	text *template.Template     // "subject" and "text"
+	html *htmltemplate.Template // "layout" wrapping "html"
+	dir  string
+}
+
+// Registry holds every parsed variant.
+type Registry struct {
+	defaultLocale string
+	kinds         map[string]map[string]*variant // kind → canonical locale → variant
+}
+
+// New parses the built-in templates; defaultLocale ends every fallback chain and must have a
+// variant for each kind.
+func New(defaultLocale string) (*Registry, error) {
+	sub, _ := fs.Sub(builtin, "templates")
+	return Load(sub, defaultLocale)
+}
+
+// Load parses templates from fsys (layout.html plus <kind>/<locale>.tmpl). Every variant is
+// rendered once with sample data so a broken template fails at boot, not at send time.
+func Load(fsys fs.FS, defaultLocale string) (*Registry, error) {
+	def, err := language.Parse(defaultLocale)
+	if err != nil {
+		return nil, fmt.Errorf("emailtmpl: default locale %q: %w", defaultLocale, err)
+	}
+	layout, err := htmltemplate.ParseFS(fsys, "layout.html")
+	if err != nil {
+		return nil, fmt.Errorf("emailtmpl: %w", err)
+	}
+	files, err := fs.Glob(fsys, "*/*.tmpl")
+	if err != nil {
+		return nil, err
+	}
+	reg := &Registry{defaultLocale: def.String(), kinds: map[string]map[string]*variant{}}
+	for _, file := range files {
+		kind, name := path.Split(file)
+		kind = strings.TrimSuffix(kind, "/")
+		tag, err := language.Parse(strings.TrimSuffix(name, ".tmpl"))
+		if err != nil {
+			return nil, fmt.Errorf("emailtmpl: %s: locale: %w", file, err)
+		}
+		src, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+		v, err := parseVariant(layout, file, string(src), direction(tag))
+		if err != nil {
+			return nil, err
+		}
+		if reg.kinds[kind] == nil {
+			reg.kinds[kind] = map[string]*variant{}
+		}
+		reg.kinds[kind][tag.String()] = v
+	}
+	for kind, variants := range reg.kinds {
+		if variants[reg.defaultLocale] == nil {
+			return nil, fmt.Errorf("emailtmpl: %s has no %s variant (the default locale)", kind, reg.defaultLocale)
+		}
+	}
+	return reg, nil
+}
+
+func parseVariant(layout *htmltemplate.Template, file, src, dir string) (*variant, error) {
+	text, err := template.New(file).Option("missingkey=error").Parse(src)
+	if err != nil {
+		return nil, fmt.Errorf("emailtmpl: %w", err)
+	}
+	html, err := layout.Clone()
+	if err == nil {
+		_, err = html.New(file).Parse(src)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("emailtmpl: %w", err)
+	}
+	for _, name := range []string{"subject", "text", "html"} {
+		if text.Lookup(name) == nil {
+			return nil, fmt.Errorf("emailtmpl: %s: missing {{define %q}}", file, name)
+		}
+	}
+	v := &variant{text: text, html: html, dir: dir}
+	if _, err := v.render(Data{AppName: "App", Name: "Sample", Link: "https://example.com", Expires: "1 hour"}, "und"); err != nil {
+		return nil, fmt.Errorf("emailtmpl: %s: %w", file, err)
+	}
+	return v, nil
+}
+
+// Chain is the fallback order for a recipient locale: the locale itself, its parents, then
+// the default. Unparsable or empty locales go straight to the default.
+func (r *Registry) Chain(locale string) []string {
+	var chain []string
+	add := func(l string) {
+		for _, seen := range chain {
+			if seen == l {
+				return
+			}
+		}
+		chain = append(chain, l)
+	}
+	if tag, err := language.Parse(locale); err == nil && locale != "" {
+		for t := tag; t != language.Und; t = t.Parent() {
+			add(t.String())
+		}
+	}
+	add(r.defaultLocale)
+	return chain
+}
+
+// Render picks the variant for kind along Chain(locale) and executes it.
+func (r *Registry) Render(kind, locale string, data Data) (Message, error) {
+	variants, ok := r.kinds[kind]
+	if !ok {
+		return Message{}, ErrUnknownKind
+	}
+	chain := r.Chain(locale)
+	for _, l := range chain {
+		if v := variants[l]; v != nil {
+			msg, err := v.render(data, l)
+			msg.Kind, msg.Chain = kind, chain
+			return msg, err
+		}
+	}
+	return Message{}, ErrUnknownKind // unreachable: Load requires a default variant
+}
+
+// DefaultLocale ends every fallback chain.
+func (r *Registry) DefaultLocale() string { return r.defaultLocale }
+
+// Kinds lists every kind with the locales it has variants for, sorted.
+func (r *Registry) Kinds() map[string][]string {
+	out := make(map[string][]string, len(r.kinds))
+	for kind, variants := range r.kinds {
+		for l := range variants {
+			out[kind] = append(out[kind], l)
+		}
+		sort.Strings(out[kind])
+	}
+	return out
+}
+
+func (v *variant) render(data Data, locale string) (Message, error) {
+	msg := Message{Locale: locale, Dir: v.dir}
+	var b bytes.Buffer
+	if err := v.text.ExecuteTemplate(&b, "subject", data); err != nil {
+		return msg, err
+	}
+	msg.Subject = strings.TrimSpace(b.String())
+	b.Reset()
+	if err := v.text.ExecuteTemplate(&b, "text", data); err != nil {
+		return msg, err
+	}
+	msg.Text = strings.TrimSpace(b.String()) + "\n"
+	b.Reset()
+	page := struct {
+		Lang, Dir, Subject string
+		Data               Data
+	}{locale, v.dir, msg.Subject, data}
+	if err := v.html.ExecuteTemplate(&b, "layout", page); err != nil {
+		return msg, err
+	}
+	msg.HTML = b.String()
+	return msg, nil
+}
+
+// direction is "rtl" for locales written in a right-to-left script.
+func direction(tag language.Tag) string {
+	if script, _ := tag.Script(); rtlScripts[script.String()] {
+		return "rtl"
+	}
+	return "ltr"
+}
@@ -0,0 +1,71 @@
+package emailtmpl
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var sample = Data{AppName: "HelmyTask", Name: "Ahmed", Link: "https://example.com/r?t=1&x=<y>", Expires: "1 hour"}
+
+func TestBuiltin_FallbackChainAndDirection(t *testing.T) {
+	reg, err := New("en")
+	require.NoError(t, err)
+
+	tests := []struct{ kind, locale, want, dir string }{
+		{"password_reset", "ar-EG", "ar-EG", "rtl"}, // exact variant
+		{"password_reset", "ar-SA", "ar", "rtl"},    // parent language
+		{"welcome", "ar-EG", "ar", "rtl"},
+		{"welcome", "fr-CA", "en", "ltr"}, // no French: default
+		{"welcome", "", "en", "ltr"},
+		{"welcome", "not a locale!", "en", "ltr"},
+	}
+	for _, tt := range tests {
+		msg, err := reg.Render(tt.kind, tt.locale, sample)
+		require.NoError(t, err, tt.locale)
+		assert.Equal(t, tt.want, msg.Locale, tt.locale)
+		assert.Equal(t, tt.dir, msg.Dir, tt.locale)
+		assert.Contains(t, msg.HTML, `dir="`+tt.dir+`"`)
+		assert.Contains(t, msg.HTML, `lang="`+tt.want+`"`)
+	}
+	assert.Equal(t, []string{"ar-EG", "ar", "en"}, reg.Chain("ar-EG"))
+	assert.Equal(t, []string{"ar", "en"}, reg.Chain("ar"))
+}
+
+func TestRender_EscapesHTMLOnly(t *testing.T) {
+	reg, err := New("en")
+	require.NoError(t, err)
+
+	msg, err := reg.Render("password_reset", "en", sample)
+	require.NoError(t, err)
+	assert.Equal(t, "Reset your HelmyTask password", msg.Subject)
+	assert.Contains(t, msg.Text, "https://example.com/r?t=1&x=<y>") // plain text untouched
+	assert.Contains(t, msg.HTML, `href="https://example.com/r?t=1&amp;x=%3cy%3e"`)
+	assert.NotContains(t, msg.HTML, "<y>")
+
+	_, err = reg.Render("nope", "en", sample)
+	assert.ErrorIs(t, err, ErrUnknownKind)
+}
+
+func TestLoad_RejectsIncompleteTemplates(t *testing.T) {
+	layout := &fstest.MapFile{Data: []byte(`{{define "layout"}}{{template "html" .Data}}{{end}}`)}
+	full := []byte(`{{define "subject"}}s{{end}}{{define "text"}}t{{end}}{{define "html"}}h{{end}}`)
+
+	_, err := Load(fstest.MapFS{"layout.html": layout, "welcome/ar.tmpl": {Data: full}}, "en")
+	assert.ErrorContains(t, err, "no en variant")
+
+	_, err = Load(fstest.MapFS{"layout.html": layout, "welcome/en.tmpl": {Data: []byte(`{{define "subject"}}s{{end}}`)}}, "en")
+	assert.ErrorContains(t, err, `missing {{define "text"}}`)
+
+	_, err = Load(fstest.MapFS{"layout.html": layout, "welcome/en.tmpl": {Data: []byte(`{{define "subject"}}{{.Nope}}{{end}}{{define "text"}}t{{end}}{{define "html"}}h{{end}}`)}}, "en")
+	assert.Error(t, err, "unknown field fails at load")
+
+	reg, err := Load(fstest.MapFS{"layout.html": layout, "welcome/en.tmpl": {Data: full}, "welcome/he.tmpl": {Data: full}}, "en")
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{"welcome": {"en", "he"}}, reg.Kinds())
+	msg, err := reg.Render("welcome", "he-IL", sample)
+	require.NoError(t, err)
+	assert.Equal(t, "rtl", msg.Dir)
+}
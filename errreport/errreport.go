@@ -0,0 +1,67 @@
+// Package errreport forwards recovered panics and serious errors to an error tracker (Sentry,
+// see sentry.go). Like the logger's Redis sink it is process-wide: main installs a reporter
+// with Set, and middlewares.Recovery and the Redis log (through redislog.WithReporter and
+// ReportEntry) call Report, which does nothing until then.
+package errreport
+
+import (
+	"strconv"
+	"sync/atomic"
+
+	"HelmyTask/utils/redislog"
+)
+
+// Event is one error to report.
+type Event struct {
+	Level     string // "error", "warn", or "fatal" for a recovered panic
+	Message   string // what failed, e.g. "register db create error"; the grouping key with Route
+	Error     string // err.Error() or the panic value
+	RequestID string
+	UID       uint   // acting user; 0 = unknown
+	Route     string // route pattern, e.g. "GET /users/:id"
+	Stack     string // goroutine stack, when known
+	Extra     map[string]string
+}
+
+// Reporter sends events somewhere; Report must not block the caller for long.
+type Reporter interface {
+	Report(Event)
+}
+
+// current is the installed reporter (nil = none).
+var current atomic.Pointer[Reporter]
+
+// Set installs r as the process-wide reporter (nil stops reporting).
+func Set(r Reporter) {
+	if r == nil {
+		current.Store(nil)
+		return
+	}
+	current.Store(&r)
+}
+
+// Report sends e to the installed reporter, if any.
+func Report(e Event) {
+	if r := current.Load(); r != nil {
+		(*r).Report(e)
+	}
+}
+
+// ReportEntry reports a Redis log entry; pass it to redislog.WithReporter. Service log calls
+// name the user "user_id" in meta, so that is used when the typed uid is unset.
+func ReportEntry(en redislog.Entry) {
+	e := Event{Level: en.Level, Message: en.Msg, Error: en.Error, RequestID: en.RequestID, UID: en.UID, Route: en.Route, Stack: en.Stack}
+	if len(en.Meta) > 0 {
+		e.Extra = make(map[string]string, len(en.Meta))
+		for k, v := range en.Meta {
+			e.Extra[k] = v
+		}
+	}
+	if e.UID == 0 {
+		if id, err := strconv.ParseUint(en.Meta["user_id"], 10, 0); err == nil {
+			e.UID = uint(id)
+			delete(e.Extra, "user_id")
+		}
+	}
+	Report(e)
+}
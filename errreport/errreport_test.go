@@ -0,0 +1,61 @@
+package errreport
+
+import (
+	"testing"
+
+	"HelmyTask/utils/redislog"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recorder struct{ events []Event }
+
+func (r *recorder) Report(e Event) { r.events = append(r.events, e) }
+
+func TestReportEntry(t *testing.T) {
+	Report(Event{Message: "nobody listening"}) // no reporter: no-op
+
+	rec := &recorder{}
+	Set(rec)
+	defer Set(nil)
+
+	ReportEntry(redislog.Entry{Level: "error", Msg: "api key create error",
+		Fields: redislog.Fields{Error: "db down", Route: "POST /api-keys"},
+		Meta:   map[string]string{"user_id": "7", "key_prefix": "hk_1"}})
+	require.Len(t, rec.events, 1)
+	assert.Equal(t, Event{Level: "error", Message: "api key create error", Error: "db down", UID: 7, Route: "POST /api-keys",
+		Extra: map[string]string{"key_prefix": "hk_1"}}, rec.events[0])
+
+	Set(nil)
+	Report(Event{Message: "after Set(nil)"})
+	assert.Len(t, rec.events, 1)
+}
+
+func TestSentry_Report(t *testing.T) {
+	transport := &sentry.MockTransport{}
+	s, err := newSentry(SentryOptions{DSN: "https://key@o0.ingest.sentry.io/1", Environment: "test", Release: "1.0.0"}, transport)
+	require.NoError(t, err)
+
+	s.Report(Event{Level: "fatal", Message: "panic recovered", Error: "kaboom", RequestID: "req-1", UID: 3,
+		Route: "GET /boom", Stack: "goroutine 1", Extra: map[string]string{"email": "a@b.c", "attempt": "2"}})
+	require.Len(t, transport.Events(), 1)
+	ev := transport.Events()[0]
+	assert.Equal(t, sentry.LevelFatal, ev.Level)
+	assert.Equal(t, "test", ev.Environment)
+	assert.Equal(t, "1.0.0", ev.Release)
+	assert.Equal(t, "GET /boom", ev.Tags["route"])
+	assert.Equal(t, "req-1", ev.Tags["request_id"])
+	assert.Equal(t, "3", ev.User.ID)
+	assert.Equal(t, []string{"panic recovered", "GET /boom"}, ev.Fingerprint)
+	if assert.Len(t, ev.Exception, 1) {
+		assert.Equal(t, "kaboom", ev.Exception[0].Value)
+	}
+	assert.Equal(t, "2", ev.Extra["attempt"])
+	assert.Equal(t, "goroutine 1", ev.Extra["stack"])
+	assert.NotContains(t, ev.Extra, "email", "PII stays out of Sentry")
+
+	_, err = NewSentry(SentryOptions{DSN: "not a dsn"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,88 @@
+package errreport
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// SentryOptions configures the Sentry client.
+type SentryOptions struct {
+	DSN         string  // project DSN, e.g. https://<key>@o0.ingest.sentry.io/<project>
+	Environment string  // dev|staging|prod
+	Release     string  // app version
+	SampleRate  float64 // share of events sent (0..1; 0 = all)
+}
+
+// piiKeys are Extra keys never sent to Sentry (log meta often carries the address a failure
+// was about); the user is identified by UID only.
+var piiKeys = map[string]bool{"email": true, "to": true, "password": true, "token": true, "client_ip": true}
+
+// Sentry reports events to Sentry. Events are queued and sent in the background, so Report
+// doesn't wait on the network; call Flush before exiting.
+type Sentry struct {
+	client *sentry.Client
+}
+
+// NewSentry builds the client; an invalid DSN is an error.
+func NewSentry(opts SentryOptions) (*Sentry, error) {
+	return newSentry(opts, nil)
+}
+
+// newSentry lets tests swap the transport (nil = HTTP).
+func newSentry(opts SentryOptions, transport sentry.Transport) (*Sentry, error) {
+	client, err := sentry.NewClient(sentry.ClientOptions{
+		Dsn:         opts.DSN,
+		Environment: opts.Environment,
+		Release:     opts.Release,
+		SampleRate:  opts.SampleRate,
+		Transport:   transport,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Sentry{client: client}, nil
+}
+
+// Report implements Reporter. Events group by message and route rather than by error text,
+// which often carries IDs.
+func (s *Sentry) Report(e Event) {
+	ev := sentry.NewEvent()
+	ev.Level = sentryLevel(e.Level)
+	ev.Message = e.Message
+	ev.Transaction = e.Route
+	ev.Fingerprint = []string{e.Message, e.Route}
+	ev.Exception = []sentry.Exception{{Type: e.Message, Value: e.Error}}
+	if e.Route != "" {
+		ev.Tags["route"] = e.Route
+	}
+	if e.RequestID != "" {
+		ev.Tags["request_id"] = e.RequestID
+	}
+	if e.UID != 0 {
+		ev.User.ID = strconv.FormatUint(uint64(e.UID), 10)
+	}
+	for k, v := range e.Extra {
+		if !piiKeys[k] {
+			ev.Extra[k] = v
+		}
+	}
+	if e.Stack != "" {
+		ev.Extra["stack"] = e.Stack
+	}
+	s.client.CaptureEvent(ev, nil, nil)
+}
+
+// Flush waits up to timeout for queued events to be sent; false means some may be lost.
+func (s *Sentry) Flush(timeout time.Duration) bool { return s.client.Flush(timeout) }
+
+func sentryLevel(level string) sentry.Level {
+	switch level {
+	case "fatal":
+		return sentry.LevelFatal
+	case "warn":
+		return sentry.LevelWarning
+	}
+	return sentry.LevelError
+}
@@ -0,0 +1,169 @@
+// Package events fans user change events out to live subscribers (the GET /ws WebSocket).
+//
+// The Hub is a lifecycle hook (see the hooks package): the user service calls it after a user is
+// created, updated or deleted, and it publishes the event on a Redis channel. Every replica
+// subscribes to that channel and hands events to its own connected clients, so an admin UI sees
+// changes made through any replica. Delivery is best effort: a client that can't keep up loses
+// events (and should reload its list on reconnect).
+//
+// The same events also go to a message broker (NATS or Kafka) for other services when a
+// Publisher is configured; see publisher.go.
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"time"
+
+	"HelmyTask/hooks"
+	"HelmyTask/models"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Event types.
+const (
+	UserCreated = "user.created"
+	UserUpdated = "user.updated"
+	UserDeleted = "user.deleted"
+)
+
+// Event is one change, as sent to clients.
+type Event struct {
+	ID     string       `json:"id,omitempty"` // broker messages only (Message.ID)
+	Type   string       `json:"type"`
+	UserID uint         `json:"user_id"`
+	User   *models.User `json:"user,omitempty"` // new state; absent on delete
+	At     time.Time    `json:"at"`
+}
+
+// subscriberBuffer is how many events a slow client may fall behind before losing some.
+const subscriberBuffer = 64
+
+// Hub publishes events and delivers them to local subscribers.
+type Hub struct {
+	hooks.Base // only the created/updated/deleted events are published
+
+	rdb     *redis.Client // nil = this replica only
+	channel string
+	now     func() time.Time
+
+	mu     sync.Mutex
+	subs   map[chan Event]struct{}
+	closed bool
+}
+
+// NewHub publishes on the Redis channel (e.g. "events:users"); rdb nil keeps events local.
+func NewHub(rdb *redis.Client, channel string) *Hub {
+	return &Hub{rdb: rdb, channel: channel, now: time.Now, subs: map[chan Event]struct{}{}}
+}
+
+// OnRegistered publishes user.created.
+func (h *Hub) OnRegistered(ctx context.Context, u models.User) {
+	h.Publish(ctx, Event{Type: UserCreated, UserID: u.ID, User: &u})
+}
+
+// OnUpdated publishes user.updated.
+func (h *Hub) OnUpdated(ctx context.Context, u models.User) {
+	h.Publish(ctx, Event{Type: UserUpdated, UserID: u.ID, User: &u})
+}
+
+// OnDeleted publishes user.deleted.
+func (h *Hub) OnDeleted(ctx context.Context, id uint) {
+	h.Publish(ctx, Event{Type: UserDeleted, UserID: id})
+}
+
+// Publish sends e to every replica's subscribers (or just this one's without Redis). Errors are
+// logged: a lost notification must not fail the change that caused it.
+func (h *Hub) Publish(ctx context.Context, e Event) {
+	if e.At.IsZero() {
+		e.At = h.now().UTC()
+	}
+	if h.rdb == nil {
+		h.deliver(e)
+		return
+	}
+	b, err := json.Marshal(e)
+	if err == nil {
+		err = h.rdb.Publish(ctx, h.channel, b).Err()
+	}
+	if err != nil {
+		slog.Warn("events: publish failed", "type", e.Type, "user_id", e.UserID, "err", err)
+	}
+}
+
+// Run relays the Redis channel to local subscribers until ctx is done, then closes every
+// subscription (connected clients are disconnected). Without Redis it just waits.
+func (h *Hub) Run(ctx context.Context) {
+	defer h.close()
+	if h.rdb == nil {
+		<-ctx.Done()
+		return
+	}
+	ps := h.rdb.Subscribe(ctx, h.channel) // go-redis resubscribes after reconnects
+	defer ps.Close()
+	ch := ps.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-ch:
+			if !ok {
+				return
+			}
+			var e Event
+			if err := json.Unmarshal([]byte(msg.Payload), &e); err != nil {
+				slog.Warn("events: bad message", "channel", h.channel, "err", err)
+				continue
+			}
+			h.deliver(e)
+		}
+	}
+}
+
+// Subscribe returns a channel of events and the function that ends the subscription. The
+// channel is closed when the hub stops.
+func (h *Hub) Subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBuffer)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		close(ch)
+		return ch, func() {}
+	}
+	h.subs[ch] = struct{}{}
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if _, ok := h.subs[ch]; ok {
+			delete(h.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// deliver hands e to every subscriber without blocking; full buffers drop it.
+func (h *Hub) deliver(e Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subs {
+		select {
+		case ch <- e:
+		default:
+			slog.Warn("events: subscriber too slow, event dropped", "type", e.Type, "user_id", e.UserID)
+		}
+	}
+}
+
+// close ends every subscription.
+func (h *Hub) close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for ch := range h.subs {
+		delete(h.subs, ch)
+		close(ch)
+	}
+}
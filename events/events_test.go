@@ -0,0 +1,59 @@
+package events
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"HelmyTask/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHub_LocalFanOut(t *testing.T) {
+	h := NewHub(nil, "events:users")
+	a, cancelA := h.Subscribe()
+	b, cancelB := h.Subscribe()
+	defer cancelB()
+
+	h.OnRegistered(context.Background(), models.User{ID: 5, Name: "Sara"})
+	for _, sub := range []<-chan Event{a, b} {
+		e := <-sub
+		assert.Equal(t, UserCreated, e.Type)
+		assert.Equal(t, uint(5), e.UserID)
+		assert.Equal(t, "Sara", e.User.Name)
+		assert.False(t, e.At.IsZero())
+	}
+
+	cancelA()
+	_, open := <-a
+	assert.False(t, open) // unsubscribed
+	h.OnDeleted(context.Background(), 5)
+	assert.Equal(t, Event{Type: UserDeleted, UserID: 5}, withoutTime(<-b))
+}
+
+func TestHub_RunClosesSubscriptionsOnShutdown(t *testing.T) {
+	h := NewHub(nil, "events:users")
+	sub, cancel := h.Subscribe()
+	defer cancel()
+	ctx, stop := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() { h.Run(ctx); close(done) }()
+
+	stop()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return")
+	}
+	_, open := <-sub
+	assert.False(t, open)
+	late, _ := h.Subscribe()
+	_, open = <-late
+	assert.False(t, open) // no new subscribers after shutdown
+}
+
+func withoutTime(e Event) Event {
+	e.At = time.Time{}
+	return e
+}
@@ -0,0 +1,41 @@
+// Kafka implementation of Publisher.
+
+package events
+
+import (
+	"context"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// KafkaPublisher writes every event to one topic, keyed by user ID so a user's events land on
+// one partition in order; the event type is in the "type" header (and the payload).
+type KafkaPublisher struct {
+	w *kafka.Writer
+}
+
+// NewKafka writes to topic on the given brokers ("host:9092"). The topic must exist: it is
+// not created on the fly, so a typo fails loudly instead of filling a stray topic.
+func NewKafka(brokers []string, topic string) *KafkaPublisher {
+	return &KafkaPublisher{w: &kafka.Writer{
+		Addr:         kafka.TCP(brokers...),
+		Topic:        topic,
+		Balancer:     &kafka.Hash{},    // same key, same partition
+		RequiredAcks: kafka.RequireAll, // in-sync replicas have it before Publish returns
+		MaxAttempts:  1,                // the job queue retries, with backoff
+	}}
+}
+
+// Publish writes m and waits for the acks.
+func (p *KafkaPublisher) Publish(ctx context.Context, m Message) error {
+	return p.w.WriteMessages(ctx, kafka.Message{
+		Key:     []byte(m.Key),
+		Value:   m.Data,
+		Headers: []kafka.Header{{Key: "type", Value: []byte(m.Type)}, {Key: "id", Value: []byte(m.ID)}},
+	})
+}
+
+// Close flushes pending writes and closes the connections.
+func (p *KafkaPublisher) Close() error {
+	return p.w.Close()
+}
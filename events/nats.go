@@ -0,0 +1,44 @@
+// NATS implementation of Publisher.
+
+package events
+
+import (
+	"context"
+
+	"github.com/nats-io/nats.go"
+)
+
+// NATSPublisher publishes each message on subject prefix+type (e.g. "helmytask.user.created").
+type NATSPublisher struct {
+	nc     *nats.Conn
+	prefix string
+}
+
+// NewNATS connects to url (e.g. "nats://nats:4222"; comma-separated for a cluster). The client
+// reconnects on its own for as long as the process runs; messages published while it is away
+// are buffered by the client, and fail the flush (and so get retried) if it stays away.
+func NewNATS(url, prefix string) (*NATSPublisher, error) {
+	nc, err := nats.Connect(url, nats.Name("helmytask"), nats.MaxReconnects(-1))
+	if err != nil {
+		return nil, err
+	}
+	return &NATSPublisher{nc: nc, prefix: prefix}, nil
+}
+
+// Publish sends m and waits for the server to acknowledge the connection's writes (core NATS
+// has no per-message ack). The Nats-Msg-Id header lets a JetStream stream on the subject drop
+// duplicates from retries.
+func (p *NATSPublisher) Publish(ctx context.Context, m Message) error {
+	msg := nats.NewMsg(p.prefix + m.Type)
+	msg.Data = m.Data
+	msg.Header.Set(nats.MsgIdHdr, m.ID)
+	if err := p.nc.PublishMsg(msg); err != nil {
+		return err
+	}
+	return p.nc.FlushWithContext(ctx)
+}
+
+// Close sends what is buffered and closes the connection.
+func (p *NATSPublisher) Close() error {
+	return p.nc.Drain()
+}
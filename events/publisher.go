@@ -0,0 +1,22 @@
+// Publishing to a message broker, so other services in the architecture can react to user
+// changes. Unlike the Hub (best effort, this app's WebSocket clients only), messages go through
+// the job queue and are retried until the broker has them.
+
+package events
+
+import "context"
+
+// Message is one event as handed to a broker.
+type Message struct {
+	ID   string // unique per event, kept across retries: consumers (and JetStream) dedupe on it
+	Type string // event type, e.g. UserCreated; the NATS subject suffix or a Kafka header
+	Key  string // ordering key (the user ID): messages with the same key stay in order on Kafka
+	Data []byte // JSON-encoded Event
+}
+
+// Publisher sends messages to a broker. Publish returns once the broker has the message (or
+// fails), so callers can retry; it must be safe for concurrent use.
+type Publisher interface {
+	Publish(ctx context.Context, m Message) error
+	Close() error // flushes anything buffered; called on shutdown
+}
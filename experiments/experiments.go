@@ -0,0 +1,149 @@
+// Package experiments assigns users to experiment variants (A/B tests) and records when a user
+// was exposed to an assignment, for the analytics that evaluate the experiment.
+//
+// Assignment is deterministic and stateless: a user's bucket is a hash of the experiment's salt
+// and their ID, so every replica agrees without storing anything. Changing the salt reshuffles
+// everyone (use it to restart an experiment); changing weights moves only the users in the
+// shifted bucket ranges.
+package experiments
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// name matches experiment and variant names; they end up in headers and analytics keys.
+var name = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+// Variant is one arm of an experiment; users land in it in proportion to Weight.
+type Variant struct {
+	Name   string
+	Weight int // relative share, > 0
+}
+
+// Experiment is a named test with its variants.
+type Experiment struct {
+	Name     string
+	Salt     string // hashed with the user ID; change it to reassign everyone
+	Variants []Variant
+
+	total int // sum of weights
+}
+
+// Assignment is the variant a user sees in one experiment.
+type Assignment struct {
+	Experiment string `json:"experiment"`
+	Variant    string `json:"variant"`
+}
+
+// Exposure is an assignment delivered to a user, as recorded by the Sink.
+type Exposure struct {
+	UserID     uint
+	Experiment string
+	Variant    string
+	At         time.Time
+}
+
+// Sink receives exposure events (see RedisSink).
+type Sink interface {
+	Record(ctx context.Context, events []Exposure) error
+}
+
+// Registry holds the configured experiments, sorted by name.
+type Registry struct {
+	experiments []Experiment
+	sink        Sink // nil = exposures not recorded
+	now         func() time.Time
+}
+
+// New validates the experiments; sink may be nil.
+func New(exps []Experiment, sink Sink) (*Registry, error) {
+	r := &Registry{sink: sink, now: time.Now}
+	seen := map[string]bool{}
+	for _, e := range exps {
+		if !name.MatchString(e.Name) {
+			return nil, fmt.Errorf("experiment %q: name must be lowercase letters, digits, '_' or '-'", e.Name)
+		}
+		if seen[e.Name] {
+			return nil, fmt.Errorf("experiment %q: declared twice", e.Name)
+		}
+		seen[e.Name] = true
+		if len(e.Variants) < 2 {
+			return nil, fmt.Errorf("experiment %q: needs at least two variants", e.Name)
+		}
+		variants := map[string]bool{}
+		e.total = 0
+		for _, v := range e.Variants {
+			if !name.MatchString(v.Name) || variants[v.Name] {
+				return nil, fmt.Errorf("experiment %q: invalid or duplicate variant %q", e.Name, v.Name)
+			}
+			if v.Weight <= 0 {
+				return nil, fmt.Errorf("experiment %q: variant %q needs a weight > 0", e.Name, v.Name)
+			}
+			variants[v.Name] = true
+			e.total += v.Weight
+		}
+		if e.Salt == "" {
+			e.Salt = e.Name
+		}
+		e.Variants = append([]Variant(nil), e.Variants...) // order matters for bucketing; don't share the caller's slice
+		r.experiments = append(r.experiments, e)
+	}
+	sort.Slice(r.experiments, func(i, j int) bool { return r.experiments[i].Name < r.experiments[j].Name })
+	return r, nil
+}
+
+// Assign returns the user's variant in every experiment, sorted by experiment name.
+func (r *Registry) Assign(uid uint) []Assignment {
+	out := make([]Assignment, 0, len(r.experiments))
+	for _, e := range r.experiments {
+		out = append(out, Assignment{Experiment: e.Name, Variant: e.variant(uid)})
+	}
+	return out
+}
+
+// Expose records that the user was served these assignments. Sink errors are logged, not
+// returned: losing analytics must not fail the request.
+func (r *Registry) Expose(ctx context.Context, uid uint, as []Assignment) {
+	if r.sink == nil || len(as) == 0 {
+		return
+	}
+	at := r.now().UTC()
+	events := make([]Exposure, len(as))
+	for i, a := range as {
+		events[i] = Exposure{UserID: uid, Experiment: a.Experiment, Variant: a.Variant, At: at}
+	}
+	if err := r.sink.Record(ctx, events); err != nil {
+		slog.Warn("experiments: exposures not recorded", "user_id", uid, "err", err)
+	}
+}
+
+// Header renders assignments for the X-Experiments response header: "name=variant, ...".
+func Header(as []Assignment) string {
+	parts := make([]string, len(as))
+	for i, a := range as {
+		parts[i] = a.Experiment + "=" + a.Variant
+	}
+	return strings.Join(parts, ", ")
+}
+
+// variant maps the user's bucket (0..total-1) onto the cumulative weights.
+func (e Experiment) variant(uid uint) string {
+	sum := sha256.Sum256([]byte(e.Salt + ":" + strconv.FormatUint(uint64(uid), 10)))
+	bucket := int(binary.BigEndian.Uint64(sum[:8]) % uint64(e.total))
+	for _, v := range e.Variants {
+		if bucket < v.Weight {
+			return v.Name
+		}
+		bucket -= v.Weight
+	}
+	return e.Variants[len(e.Variants)-1].Name // unreachable: bucket < total
+}
@@ -0,0 +1,60 @@
+package experiments
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"HelmyTask/mocks"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssign_DeterministicAndWeighted(t *testing.T) {
+	r, err := New([]Experiment{
+		{Name: "checkout", Salt: "v1", Variants: []Variant{{"control", 75}, {"green", 25}}},
+		{Name: "banner", Variants: []Variant{{"a", 1}, {"b", 1}}},
+	}, nil)
+	require.NoError(t, err)
+
+	first := r.Assign(42)
+	assert.Equal(t, first, r.Assign(42))           // same user, same variants
+	assert.Equal(t, "banner", first[0].Experiment) // sorted by name
+
+	green := 0
+	for uid := uint(1); uid <= 10000; uid++ {
+		if r.Assign(uid)[1].Variant == "green" {
+			green++
+		}
+	}
+	assert.InDelta(t, 2500, green, 250) // ~25%
+}
+
+func TestNew_Rejects(t *testing.T) {
+	for _, exps := range [][]Experiment{
+		{{Name: "Bad Name", Variants: []Variant{{"a", 1}, {"b", 1}}}},
+		{{Name: "one", Variants: []Variant{{"a", 1}}}},
+		{{Name: "zero", Variants: []Variant{{"a", 1}, {"b", 0}}}},
+		{{Name: "dup", Variants: []Variant{{"a", 1}, {"b", 1}}}, {Name: "dup", Variants: []Variant{{"a", 1}, {"b", 1}}}},
+	} {
+		_, err := New(exps, nil)
+		assert.Error(t, err, exps[0].Name)
+	}
+}
+
+func TestExpose_WritesStream(t *testing.T) {
+	rdb, m := mocks.NewRedisMock()
+	r, err := New([]Experiment{{Name: "checkout", Variants: []Variant{{"control", 1}, {"green", 1}}}}, NewRedisSink(rdb, "analytics:exposures", 1000))
+	require.NoError(t, err)
+	r.now = func() time.Time { return time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC) }
+	as := r.Assign(7)
+
+	m.ExpectXAdd(&redis.XAddArgs{Stream: "analytics:exposures", MaxLen: 1000, Approx: true, Values: []interface{}{
+		"user_id", "7", "experiment", "checkout", "variant", as[0].Variant, "at", "2026-10-16T09:30:00Z",
+	}}).SetVal("1-0")
+	r.Expose(context.Background(), 7, as)
+	assert.NoError(t, m.ExpectationsWereMet())
+	assert.Equal(t, "checkout="+as[0].Variant, Header(as))
+}
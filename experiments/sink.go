@@ -0,0 +1,39 @@
+package experiments
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisSink appends exposures to a capped Redis stream, the analytics feed: a consumer group
+// (e.g. the warehouse loader) reads it reliably. One entry per exposure, fields user_id,
+// experiment, variant and at (RFC 3339).
+type RedisSink struct {
+	rdb *redis.Client
+	key string // e.g. "analytics:exposures"
+	max int64  // approximate cap on entries
+}
+
+// NewRedisSink writes to the stream at key, keeping about max entries.
+func NewRedisSink(rdb *redis.Client, key string, max int64) *RedisSink {
+	return &RedisSink{rdb: rdb, key: key, max: max}
+}
+
+// Record writes the events in one round trip.
+func (s *RedisSink) Record(ctx context.Context, events []Exposure) error {
+	_, err := s.rdb.Pipelined(ctx, func(p redis.Pipeliner) error {
+		for _, e := range events {
+			p.XAdd(ctx, &redis.XAddArgs{Stream: s.key, MaxLen: s.max, Approx: true, Values: []interface{}{
+				"user_id", strconv.FormatUint(uint64(e.UserID), 10),
+				"experiment", e.Experiment,
+				"variant", e.Variant,
+				"at", e.At.Format(time.RFC3339),
+			}})
+		}
+		return nil
+	})
+	return err
+}
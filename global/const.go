@@ -8,4 +8,10 @@ const (
 	// Using a string constant reduces risk of typos and collisions.
 	
 	CtxUserIDKey = "uid"
+
+	// Gin context key for storing the authenticated user's role (read from the "rol" claim).
+	CtxUserRoleKey = "role"
+
+	// Gin context key for the permissions an API key is limited to ([]string); unset = no limit.
+	CtxScopesKey = "scopes"
 )
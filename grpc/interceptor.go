@@ -0,0 +1,170 @@
+package grpc
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"strings"
+	"time"
+
+	"HelmyTask/core"
+	"HelmyTask/middlewares"
+	"HelmyTask/policy"
+	"HelmyTask/utils/jwtkeys"
+	"HelmyTask/utils/ratelimit"
+
+	gogrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// MethodPolicy is what a gRPC method requires, the counterpart of routes.RoutePolicy.
+type MethodPolicy struct {
+	Auth       bool              // bearer JWT required
+	Permission policy.Permission // required role permission ("" = any authenticated user)
+	RateLimit  bool              // counted in the "auth" rate limit group with the REST /auth routes
+	Write      bool              // changes data: refused while in maintenance or on an incompatible schema
+}
+
+// Methods maps full method names to their policy. Unlisted methods are refused, so a new RPC
+// can't go out unauthenticated by accident.
+var Methods = map[string]MethodPolicy{
+	"/helmytask.user.v1.UserService/Register": {RateLimit: true, Write: true},
+	"/helmytask.user.v1.UserService/Login":    {RateLimit: true},
+
+	"/helmytask.user.v1.UserService/GetMe": {Auth: true},
+
+	"/helmytask.user.v1.UserService/GetUser":    {Auth: true, Permission: policy.UsersRead},
+	"/helmytask.user.v1.UserService/ListUsers":  {Auth: true, Permission: policy.UsersRead},
+	"/helmytask.user.v1.UserService/CreateUser": {Auth: true, Permission: policy.UsersCreate, Write: true},
+	"/helmytask.user.v1.UserService/UpdateUser": {Auth: true, Permission: policy.UsersUpdate, Write: true},
+	"/helmytask.user.v1.UserService/DeleteUser": {Auth: true, Permission: policy.UsersDelete, Write: true},
+}
+
+// ctxKey keys the caller identity in a request context.
+type ctxKey int
+
+const ctxUserID ctxKey = iota // core.UserID from the token's "sub"
+
+// CurrentUserID reads the authenticated user ID the interceptor stored in the context.
+func CurrentUserID(ctx context.Context) (core.UserID, bool) {
+	id, ok := ctx.Value(ctxUserID).(core.UserID)
+	return id, ok
+}
+
+// Interceptor enforces Methods: writes are refused during maintenance or on an incompatible
+// schema (as middlewares.Maintenance and SchemaGuard refuse them over REST), then the "auth" rate
+// limit for public calls, then the bearer JWT (as middlewares.AuthWithKeys checks it) and the role
+// permission.
+type Interceptor struct {
+	keys        *jwtkeys.KeySet
+	revocations middlewares.TokenRevocations // nil = no revocation check
+	limiter     middlewares.RateLimiter      // nil = no rate limiting
+	rule        func() ratelimit.Rule
+	maintenance func() (on bool, message string) // nil = never in maintenance
+	compatible  func() bool                      // nil = always compatible
+}
+
+// InterceptorOption customizes an Interceptor.
+type InterceptorOption func(*Interceptor)
+
+// WithRevocation rejects tokens issued before the user's last revocation (password change, ban).
+func WithRevocation(r middlewares.TokenRevocations) InterceptorOption {
+	return func(i *Interceptor) { i.revocations = r }
+}
+
+// WithRateLimit limits the public methods per client IP, sharing the REST "auth" buckets.
+func WithRateLimit(l middlewares.RateLimiter, rule func() ratelimit.Rule) InterceptorOption {
+	return func(i *Interceptor) { i.limiter, i.rule = l, rule }
+}
+
+// WithMaintenance refuses write methods while state reports maintenance on.
+func WithMaintenance(state func() (on bool, message string)) InterceptorOption {
+	return func(i *Interceptor) { i.maintenance = state }
+}
+
+// WithSchemaGuard refuses write methods while compatible reports the database schema doesn't
+// match this build.
+func WithSchemaGuard(compatible func() bool) InterceptorOption {
+	return func(i *Interceptor) { i.compatible = compatible }
+}
+
+// NewInterceptor verifies tokens against keys (HS256 secret or RS256 key set).
+func NewInterceptor(keys *jwtkeys.KeySet, opts ...InterceptorOption) *Interceptor {
+	i := &Interceptor{keys: keys}
+	for _, opt := range opts {
+		opt(i)
+	}
+	return i
+}
+
+// Unary is the grpc.UnaryServerInterceptor.
+func (i *Interceptor) Unary(ctx context.Context, req interface{}, info *gogrpc.UnaryServerInfo, handler gogrpc.UnaryHandler) (interface{}, error) {
+	p, ok := Methods[info.FullMethod]
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "unknown method")
+	}
+	if p.Write {
+		if err := i.writable(); err != nil {
+			return nil, err
+		}
+	}
+	if p.RateLimit && i.limiter != nil {
+		allowed, wait, err := i.limiter.Allow(ctx, middlewares.RateLimitKey("auth", peerIP(ctx)), i.rule())
+		if err != nil { // fail open, like the REST limiter
+			slog.Warn("grpc: limiter unavailable, allowing request", "method", info.FullMethod, "err", err)
+		} else if !allowed {
+			return nil, status.Errorf(codes.ResourceExhausted, "rate limit exceeded; retry in %s", wait.Round(time.Second))
+		}
+	}
+	if !p.Auth {
+		return handler(ctx, req)
+	}
+
+	auth := metadata.ValueFromIncomingContext(ctx, "authorization")
+	if len(auth) == 0 || !strings.HasPrefix(auth[0], "Bearer ") || len(auth[0]) < 8 {
+		return nil, status.Error(codes.Unauthenticated, "missing bearer token")
+	}
+	uid, role, err := middlewares.VerifyToken(ctx, i.keys, i.revocations, auth[0][7:])
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	if p.Permission != "" && !policy.Allowed(role, p.Permission) {
+		return nil, status.Error(codes.PermissionDenied, "forbidden")
+	}
+	if uid != nil {
+		ctx = context.WithValue(ctx, ctxUserID, core.UserID(*uid))
+	}
+	return handler(ctx, req)
+}
+
+// writable is the Unavailable error that refuses a write, or nil.
+func (i *Interceptor) writable() error {
+	if i.maintenance != nil {
+		if on, msg := i.maintenance(); on {
+			if msg == "" {
+				msg = "down for maintenance"
+			}
+			return status.Error(codes.Unavailable, msg)
+		}
+	}
+	if i.compatible != nil && !i.compatible() {
+		return status.Error(codes.Unavailable, "database schema is incompatible with this release; writes are disabled")
+	}
+	return nil
+}
+
+// peerIP is the client address without the port ("" when unknown).
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
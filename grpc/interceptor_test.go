@@ -0,0 +1,91 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"HelmyTask/core"
+	"HelmyTask/utils/jwtkeys"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	gogrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const testSecret = "test-secret"
+
+func bearer(t *testing.T, claims jwt.MapClaims) context.Context {
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testSecret))
+	assert.NoError(t, err)
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+signed))
+}
+
+func call(ctx context.Context, method string) (core.UserID, error) {
+	var seen core.UserID
+	_, err := NewInterceptor(jwtkeys.NewHMAC(testSecret)).Unary(ctx, nil, &gogrpc.UnaryServerInfo{FullMethod: method},
+		func(ctx context.Context, _ interface{}) (interface{}, error) {
+			seen, _ = CurrentUserID(ctx)
+			return nil, nil
+		})
+	return seen, err
+}
+
+func TestUnary_Permissions(t *testing.T) {
+	exp := time.Now().Add(time.Minute).Unix()
+	admin := bearer(t, jwt.MapClaims{"sub": 1, "rol": "admin", "exp": exp})
+	user := bearer(t, jwt.MapClaims{"sub": 2, "exp": exp}) // no role claim: plain user
+
+	uid, err := call(admin, "/helmytask.user.v1.UserService/DeleteUser")
+	assert.NoError(t, err)
+	assert.Equal(t, core.UserID(1), uid)
+
+	uid, err = call(user, "/helmytask.user.v1.UserService/GetMe")
+	assert.NoError(t, err)
+	assert.Equal(t, core.UserID(2), uid)
+
+	_, err = call(user, "/helmytask.user.v1.UserService/ListUsers")
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestUnary_Rejects(t *testing.T) {
+	_, err := call(context.Background(), "/helmytask.user.v1.UserService/GetMe")
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	expired := bearer(t, jwt.MapClaims{"sub": 1, "rol": "admin", "exp": time.Now().Add(-time.Minute).Unix()})
+	_, err = call(expired, "/helmytask.user.v1.UserService/GetUser")
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = call(context.Background(), "/helmytask.user.v1.UserService/Unlisted")
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+
+	_, err = call(context.Background(), "/helmytask.user.v1.UserService/Login") // public
+	assert.NoError(t, err)
+}
+
+func TestUnary_WritesRefusedInMaintenanceOrIncompatibleSchema(t *testing.T) {
+	admin := bearer(t, jwt.MapClaims{"sub": 1, "rol": "admin", "exp": time.Now().Add(time.Minute).Unix()})
+	maintenance, compatible := true, true
+	i := NewInterceptor(jwtkeys.NewHMAC(testSecret),
+		WithMaintenance(func() (bool, string) { return maintenance, "" }),
+		WithSchemaGuard(func() bool { return compatible }))
+	ok := func(context.Context, interface{}) (interface{}, error) { return nil, nil }
+	unary := func(method string) error {
+		_, err := i.Unary(admin, nil, &gogrpc.UnaryServerInfo{FullMethod: "/helmytask.user.v1.UserService/" + method}, ok)
+		return err
+	}
+
+	assert.Equal(t, codes.Unavailable, status.Code(unary("UpdateUser")))
+	assert.Equal(t, codes.Unavailable, status.Code(unary("Register")))
+	assert.NoError(t, unary("GetUser"), "reads keep working")
+
+	maintenance, compatible = false, false
+	assert.Equal(t, codes.Unavailable, status.Code(unary("DeleteUser")))
+	assert.NoError(t, unary("ListUsers"))
+
+	compatible = true
+	assert.NoError(t, unary("CreateUser"))
+}
@@ -0,0 +1,247 @@
+// Package grpc serves the user API over gRPC (see userpb/user.proto) next to the REST API.
+// It is a thin transport: the same services, JWTs, permissions and audit trail as /api/v1.
+package grpc
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"HelmyTask/audit"
+	"HelmyTask/core"
+	"HelmyTask/grpc/userpb"
+	_ "HelmyTask/handlers" // Registers the "password" binding tag the DTOs use.
+	"HelmyTask/models"
+	"HelmyTask/services"
+
+	"github.com/gin-gonic/gin/binding"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Server implements userpb.UserServiceServer on top of the service layer.
+type Server struct {
+	userpb.UnimplementedUserServiceServer
+
+	auth       services.AuthService
+	users      services.UserAdminService
+	jwtSecret  string          // HS256 secret passed to Login (ignored when the service has RS256 keys)
+	jwtExpires time.Duration   // Token lifetime
+	audit      *audit.Recorder // Records create/update/delete (nil = not audited).
+}
+
+// ServerOption customizes a Server.
+type ServerOption func(*Server)
+
+// WithAudit records user changes made over gRPC in the audit trail, like the REST handlers.
+func WithAudit(rec *audit.Recorder) ServerOption {
+	return func(s *Server) { s.audit = rec }
+}
+
+// NewServer constructs the gRPC user API; jwtSecret and jwtExpires are what Login signs with.
+func NewServer(auth services.AuthService, users services.UserAdminService, jwtSecret string, jwtExpires time.Duration, opts ...ServerOption) *Server {
+	s := &Server{auth: auth, users: users, jwtSecret: jwtSecret, jwtExpires: jwtExpires}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Register creates an account (public).
+func (s *Server) Register(ctx context.Context, in *userpb.RegisterRequest) (*userpb.User, error) {
+	req := registerRequest(in)
+	if err := binding.Validator.ValidateStruct(&req); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	u, err := s.auth.Register(ctx, req)
+	if err != nil {
+		return nil, invalidArgument(err)
+	}
+	return toUser(u), nil
+}
+
+// Login answers a JWT for valid credentials (public).
+func (s *Server) Login(ctx context.Context, in *userpb.LoginRequest) (*userpb.LoginResponse, error) {
+	req := models.LoginRequest{Email: in.GetEmail(), Password: in.GetPassword(), Code: in.GetCode()}
+	if err := binding.Validator.ValidateStruct(&req); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	tok, err := s.auth.Login(ctx, req, s.jwtSecret, s.jwtExpires)
+	switch {
+	case errors.Is(err, services.ErrAccountInactive): // Right password, but disabled/banned.
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, services.ErrLoginLocked): // Too many failed logins for this email.
+		return nil, status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, services.ErrTwoFactorUnavailable): // Server-side failure, details logged.
+		return nil, status.Error(codes.Internal, err.Error())
+	case err != nil: // Includes ErrTwoFactorRequired: resend with code.
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	return &userpb.LoginResponse{Token: tok}, nil
+}
+
+// GetMe returns the caller's own account.
+func (s *Server) GetMe(ctx context.Context, _ *userpb.GetMeRequest) (*userpb.User, error) {
+	uid, ok := CurrentUserID(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "unauthenticated")
+	}
+	u, err := s.users.GetUser(ctx, uid)
+	if err != nil { // Account deleted while the token is still valid.
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+	return toUser(u), nil
+}
+
+// GetUser returns one account (users:read).
+func (s *Server) GetUser(ctx context.Context, in *userpb.GetUserRequest) (*userpb.User, error) {
+	id, err := userID(in.GetId())
+	if err != nil {
+		return nil, err
+	}
+	u, err := s.users.GetUser(ctx, id)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+	return toUser(u), nil
+}
+
+// ListUsers pages through accounts with the filters of GET /users (users:read).
+func (s *Server) ListUsers(ctx context.Context, in *userpb.ListUsersRequest) (*userpb.ListUsersResponse, error) {
+	q := models.ListUserQuery{
+		Page: int(in.GetPage()), Limit: int(in.GetLimit()),
+		Q: in.GetQ(), Email: in.GetEmail(), Sort: in.GetSort(), Order: in.GetOrder(),
+		Cursor: in.Cursor,
+	}
+	if in.CreatedAfter != nil {
+		t := in.CreatedAfter.AsTime()
+		q.CreatedAfter = &t
+	}
+	if in.CreatedBefore != nil {
+		t := in.CreatedBefore.AsTime()
+		q.CreatedBefore = &t
+	}
+	if err := binding.Validator.ValidateStruct(&q); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	res, err := s.users.ListUsers(ctx, q)
+	if err != nil { // Unknown sort column, bad cursor, ...
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	out := &userpb.ListUsersResponse{Total: res.Total, Page: int32(res.Page), Limit: int32(res.Limit), NextCursor: res.NextCursor}
+	for i := range res.Items {
+		out.Items = append(out.Items, toUser(&res.Items[i]))
+	}
+	return out, nil
+}
+
+// CreateUser is the admin create (users:create).
+func (s *Server) CreateUser(ctx context.Context, in *userpb.RegisterRequest) (*userpb.User, error) {
+	req := registerRequest(in)
+	if err := binding.Validator.ValidateStruct(&req); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	u, err := s.users.CreateUser(ctx, req)
+	if err != nil {
+		return nil, invalidArgument(err)
+	}
+	s.record(ctx, audit.ActionUserCreate, core.UserID(u.ID), nil, u, "password")
+	return toUser(u), nil
+}
+
+// UpdateUser is a partial update of any account (users:update).
+func (s *Server) UpdateUser(ctx context.Context, in *userpb.UpdateUserRequest) (*userpb.User, error) {
+	id, err := userID(in.GetId())
+	if err != nil {
+		return nil, err
+	}
+	req := models.UpdateUserRequest{
+		Name: in.Name, Email: in.Email, Password: in.Password, Role: in.Role, Status: in.Status,
+		Phone: in.Phone, Bio: in.Bio, DateOfBirth: in.DateOfBirth, Locale: in.Locale, Timezone: in.Timezone,
+	}
+	if err := binding.Validator.ValidateStruct(&req); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	before := s.snapshot(ctx, id)
+	u, err := s.users.UpdateUser(ctx, id, req)
+	if err != nil {
+		return nil, invalidArgument(err)
+	}
+	var secrets []string
+	if req.Password != nil { // hashes never go in the diff, only the fact it changed
+		secrets = append(secrets, "password")
+	}
+	s.record(ctx, audit.ActionUserUpdate, id, before, u, secrets...)
+	return toUser(u), nil
+}
+
+// DeleteUser removes an account (users:delete).
+func (s *Server) DeleteUser(ctx context.Context, in *userpb.DeleteUserRequest) (*userpb.DeleteUserResponse, error) {
+	id, err := userID(in.GetId())
+	if err != nil {
+		return nil, err
+	}
+	before := s.snapshot(ctx, id)
+	if err := s.users.DeleteUser(ctx, id); err != nil { // Simplified mapping, like DELETE /users/:id.
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+	s.record(ctx, audit.ActionUserDelete, id, before, nil)
+	return &userpb.DeleteUserResponse{}, nil
+}
+
+// record writes an audit entry for a user change made by the authenticated caller.
+func (s *Server) record(ctx context.Context, action string, target core.UserID, before, after *models.User, secrets ...string) {
+	if s.audit == nil {
+		return
+	}
+	actor, _ := CurrentUserID(ctx)
+	s.audit.Record(audit.Event{
+		ActorID: uint(actor), Action: action, TargetType: "user", TargetID: uint(target),
+		Before: before, After: after, Secrets: secrets, IP: peerIP(ctx),
+	})
+}
+
+// snapshot is the pre-change state for the audit diff (only fetched when auditing).
+func (s *Server) snapshot(ctx context.Context, id core.UserID) *models.User {
+	if s.audit == nil {
+		return nil
+	}
+	u, err := s.users.GetUser(ctx, id)
+	if err != nil {
+		return nil
+	}
+	return u
+}
+
+// registerRequest converts the shared Register/CreateUser message.
+func registerRequest(in *userpb.RegisterRequest) models.RegisterRequest {
+	return models.RegisterRequest{Name: in.GetName(), Email: in.GetEmail(), Password: in.GetPassword(), IdempotencyKey: in.GetIdempotencyKey()}
+}
+
+// userID rejects the zero ID (the proto default when the field is missing).
+func userID(id uint64) (core.UserID, error) {
+	if id == 0 {
+		return 0, status.Error(codes.InvalidArgument, "invalid id")
+	}
+	return core.UserID(id), nil
+}
+
+// invalidArgument maps business errors like badRequest does for REST (violations included in the message).
+func invalidArgument(err error) error {
+	var v core.Violations
+	if errors.As(err, &v) {
+		return status.Error(codes.InvalidArgument, "validation failed: "+v.Error())
+	}
+	return status.Error(codes.InvalidArgument, err.Error())
+}
+
+// toUser converts the model; the password hash and TOTP seed have no field to leak into.
+func toUser(u *models.User) *userpb.User {
+	return &userpb.User{
+		Id: uint64(u.ID), Name: u.Name, Email: u.Email, Role: u.Role, Status: u.Status,
+		TwoFactorEnabled: u.TOTPEnabled, EmailUndeliverable: u.EmailUndeliverable,
+		Phone: u.Phone, Bio: u.Bio, DateOfBirth: u.DateOfBirth, Locale: u.Locale, Timezone: u.Timezone,
+		CreatedAt: timestamppb.New(u.CreatedAt), UpdatedAt: timestamppb.New(u.UpdatedAt),
+	}
+}
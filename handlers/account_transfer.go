@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"HelmyTask/accounts"
+	"HelmyTask/audit"
+	"HelmyTask/core"
+	"HelmyTask/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ExportAccounts handles POST /admin/accounts/export: the given users, with their password
+// hashes and 2FA seeds, as a bundle encrypted with the passphrase. IDs that don't exist are
+// listed in X-Export-Missing; every exported user is audited.
+func (h *UserHandler) ExportAccounts(c *gin.Context) {
+	var req models.ExportAccountsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(req.Passphrase) < accounts.MinPassphraseLen {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("passphrase must be at least %d characters", accounts.MinPassphraseLen)})
+		return
+	}
+	ids := make([]core.UserID, len(req.IDs))
+	for i, id := range req.IDs {
+		ids[i] = core.UserID(id)
+	}
+	accts, missing, err := h.svc.ExportAccounts(c.Request.Context(), ids)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if len(accts) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "none of the users exist", "missing": missing})
+		return
+	}
+	now := time.Now()
+	bundle, err := accounts.Seal(accts, req.Passphrase, now)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	skip := make(map[uint]bool, len(ids)) // missing, or already recorded
+	for _, id := range missing {
+		skip[id] = true
+	}
+	for _, id := range ids {
+		if !skip[uint(id)] {
+			skip[uint(id)] = true
+			h.record(c, audit.ActionUserExport, id, nil, nil)
+		}
+	}
+
+	if len(missing) > 0 {
+		c.Header("X-Export-Missing", joinIDs(missing))
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="accounts-%s.bundle"`, now.UTC().Format("20060102T150405Z")))
+	c.Data(http.StatusOK, "application/octet-stream", bundle)
+}
+
+// ImportAccounts handles POST /admin/accounts/import: multipart field "bundle" (a file from
+// ExportAccounts), "passphrase", and optionally overwrite=true to replace users whose email is
+// already registered. The response reports every account that was skipped.
+func (h *UserHandler) ImportAccounts(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportBytes)
+	fh, err := c.FormFile("bundle")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "multipart field \"bundle\" is required (max 5 MB)"})
+		return
+	}
+	overwrite := false
+	if v := c.PostForm("overwrite"); v != "" {
+		if overwrite, err = strconv.ParseBool(v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "overwrite must be true or false"})
+			return
+		}
+	}
+	f, err := fh.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	b, err := accounts.Open(data, c.PostForm("passphrase"))
+	if err == nil && len(b.Accounts) > accounts.MaxAccounts {
+		err = fmt.Errorf("too many accounts; the limit is %d per bundle", accounts.MaxAccounts)
+	}
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, accounts.ErrBadBundle) {
+			status = http.StatusUnprocessableEntity
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	res, err := h.svc.ImportAccounts(c.Request.Context(), b.Accounts, overwrite)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	for i := range res.Users {
+		u := res.Users[i]
+		h.record(c, audit.ActionUserCreate, core.UserID(u.ID), nil, &u, "password")
+	}
+	for i := range res.Replaced {
+		u := res.Replaced[i]
+		h.record(c, audit.ActionUserUpdate, core.UserID(u.ID), nil, &u, "password")
+	}
+	c.JSON(http.StatusOK, res) // 200 even with skipped accounts; the report says which.
+}
+
+// joinIDs renders ids as "3,7,9".
+func joinIDs(ids []uint) string {
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = strconv.FormatUint(uint64(id), 10)
+	}
+	return strings.Join(parts, ",")
+}
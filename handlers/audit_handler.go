@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"HelmyTask/audit"
+	"HelmyTask/core"
+	"HelmyTask/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuditHandler serves the audit trail.
+type AuditHandler struct{ rec *audit.Recorder }
+
+// NewAuditHandler wires the recorder.
+func NewAuditHandler(rec *audit.Recorder) *AuditHandler { return &AuditHandler{rec: rec} }
+
+// List handles GET /admin/audit?actor_id=&action=&from=&to=&page=&limit=
+// (from/to are RFC 3339 timestamps).
+func (h *AuditHandler) List(c *gin.Context) {
+	var f models.AuditFilter
+	f.Action = c.Query("action")
+	if v := c.Query("actor_id"); v != "" {
+		id, err := parseUint(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid actor_id"})
+			return
+		}
+		f.ActorID = id
+	}
+	for key, dst := range map[string]**time.Time{"from": &f.From, "to": &f.To} {
+		if v := c.Query(key); v != "" {
+			t, err := core.ParseTimestamp(v) // RFC 3339 preferred; dates and Unix seconds work too
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + key + " (want RFC 3339)"})
+				return
+			}
+			*dst = &t
+		}
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	paged, err := h.rec.Query(f, page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, paged)
+}
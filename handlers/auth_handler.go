@@ -0,0 +1,178 @@
+package handlers // Sign-up, login and credential endpoints.
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"HelmyTask/models"
+	"HelmyTask/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuthHandler serves the credential endpoints: register, JWT login, password strength and
+// changes, and 2FA enrollment (session-mode login is SessionHandler's).
+type AuthHandler struct {
+	svc        services.AuthService // Injected business logic.
+	jwtSecret  string               // JWT signing secret configured in main.
+	jwtExpires time.Duration        // JWT validity duration.
+}
+
+// NewAuthHandler constructs the handler.
+func NewAuthHandler(svc services.AuthService, jwtSecret string, jwtExp time.Duration) *AuthHandler {
+	return &AuthHandler{svc: svc, jwtSecret: jwtSecret, jwtExpires: jwtExp}
+}
+
+// Register handles POST /auth/register (public).
+func (h *AuthHandler) Register(c *gin.Context) {
+	var req models.RegisterRequest                 // Allocate request payload struct.
+	if err := c.ShouldBindJSON(&req); err != nil { // Bind and validate JSON input.
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()}) // 400 if validation fails.
+		return                                                     // Stop handler here.
+	}
+	req.IdempotencyKey = c.GetHeader("Idempotency-Key") // Optional; lets client retries replay safely.
+	u, err := h.svc.Register(c.Request.Context(), req)                       // Delegate to service (hash + save + optional cache warm).
+	if err != nil {                                     // Typically "email already exists" or domain rule violations.
+		badRequest(c, err) // Report error to client.
+		return
+	}
+	c.JSON(http.StatusCreated, u) // 201 Created with user JSON.
+}
+
+// Login handles POST /auth/login (public).
+func (h *AuthHandler) Login(c *gin.Context) {
+	var req models.LoginRequest                    // Allocate request payload struct.
+	if err := c.ShouldBindJSON(&req); err != nil { // Bind/validate JSON.
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()}) // 400 on invalid input.
+		return
+	}
+	tok, err := h.svc.Login(c.Request.Context(), req, h.jwtSecret, h.jwtExpires) // Delegate to service (validates + signs JWT).
+	if errors.Is(err, services.ErrTwoFactorRequired) {      // Password ok; client must resend with "code".
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error(), "two_factor_required": true})
+		return
+	}
+	if errors.Is(err, services.ErrAccountInactive) { // Right password, but disabled/banned.
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, services.ErrLoginLocked) { // Too many failures for this email → 429 + Retry-After.
+		loginLocked(c, h.svc, req.Email, err)
+		return
+	}
+	if errors.Is(err, services.ErrTwoFactorUnavailable) { // Our fault (details logged), not wrong credentials.
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil { // Wrong credentials → 401 Unauthorized.
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, models.AuthResponse{Token: tok}) // Return {"token": "..."}.
+}
+
+// LockoutStatus handles GET /auth/lockout-status?email= (public, rate limited): whether the
+// email is locked out of login and for how many more minutes, so login forms can say "try
+// again in N minutes". Every email is tracked, registered or not, so the answer reveals
+// nothing about accounts; minutes are rounded up to keep it coarse.
+func (h *AuthHandler) LockoutStatus(c *gin.Context) {
+	email := c.Query("email")
+	if email == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "email is required"})
+		return
+	}
+	wait := h.svc.LoginLockout(c.Request.Context(), email)
+	c.JSON(http.StatusOK, gin.H{"locked": wait > 0, "retry_after_minutes": ceilMinutes(wait)})
+}
+
+// loginLocked answers a login refused by the lockout: 429 with Retry-After.
+func loginLocked(c *gin.Context, svc services.AuthService, email string, err error) {
+	minutes := ceilMinutes(svc.LoginLockout(c.Request.Context(), email))
+	if minutes == 0 {
+		minutes = 1 // the lock ended in between; still ask for a pause
+	}
+	c.Header("Retry-After", strconv.Itoa(minutes*60))
+	c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error(), "retry_after_minutes": minutes})
+}
+
+// ceilMinutes rounds d up to whole minutes.
+func ceilMinutes(d time.Duration) int {
+	if d <= 0 {
+		return 0
+	}
+	return int((d + time.Minute - 1) / time.Minute)
+}
+
+// PasswordStrength handles POST /auth/password-strength (public, rate limited).
+func (h *AuthHandler) PasswordStrength(c *gin.Context) {
+	var req models.PasswordStrengthRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, h.svc.PasswordStrength(req)) // Score + feedback + whether register would accept it.
+}
+
+// ChangePassword handles POST /me/password (protected). On success every existing token/session
+// is revoked, so the client must log in again.
+func (h *AuthHandler) ChangePassword(c *gin.Context) {
+	uid, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthenticated"})
+		return
+	}
+	var req models.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	err := h.svc.ChangePassword(c.Request.Context(), uid, req)
+	switch {
+	case errors.Is(err, services.ErrWrongPassword):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case err != nil:
+		badRequest(c, err)
+	default:
+		c.JSON(http.StatusOK, gin.H{"status": "password changed; please log in again"})
+	}
+}
+
+// EnableTwoFactor handles POST /me/2fa/enable (protected): starts TOTP enrollment.
+func (h *AuthHandler) EnableTwoFactor(c *gin.Context) {
+	uid, ok := currentUserID(c) // Set by Auth middleware.
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthenticated"})
+		return
+	}
+	setup, err := h.svc.EnableTwoFactor(c.Request.Context(), uid) // Secret is shown once; only the encrypted copy is stored.
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, setup)
+}
+
+// ConfirmTwoFactor handles POST /me/2fa/confirm (protected): activates 2FA with a first valid code.
+func (h *AuthHandler) ConfirmTwoFactor(c *gin.Context) {
+	uid, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthenticated"})
+		return
+	}
+	var req models.TwoFactorConfirmRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	err := h.svc.ConfirmTwoFactor(c.Request.Context(), uid, req.Code)
+	if errors.Is(err, services.ErrTwoFactorUnavailable) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"two_factor_enabled": true})
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"HelmyTask/core"
+	"HelmyTask/mocks"
+	"HelmyTask/models"
+	"HelmyTask/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func setupAuth(r *gin.Engine, svc *mocks.AuthServiceMock) {
+	h := NewAuthHandler(svc, "test-secret", time.Minute)
+	r.POST("/auth/register", h.Register)
+	r.POST("/auth/login", h.Login)
+	r.GET("/auth/lockout-status", h.LockoutStatus)
+}
+
+func TestRegister_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	svc := new(mocks.AuthServiceMock)
+	setupAuth(r, svc)
+
+	req := models.RegisterRequest{Name: "ahmed", Email: "a@b.c", Password: "123456"}
+	resp := &models.User{ID: 1, Name: "Ahmed", Email: "a@b.c"}
+	svc.On("Register", req).Return(resp, nil)
+
+	b, _ := json.Marshal(req)
+	w := httptest.NewRecorder()
+	httpReq := httptest.NewRequest(http.MethodPost, "/auth/register", bytes.NewReader(b))
+	httpReq.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"id":1`)
+}
+
+func TestLogin_Unauthorized(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	svc := new(mocks.AuthServiceMock)
+	setupAuth(r, svc)
+
+	body := models.LoginRequest{Email: "x@y.z", Password: "oops"}
+	svc.On("Login", body, "test-secret", time.Minute).Return("", assert.AnError)
+
+	b, _ := json.Marshal(body)
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/auth/login", bytes.NewReader(b))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestLogin_LockedOut(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	svc := new(mocks.AuthServiceMock)
+	setupAuth(r, svc)
+
+	body := models.LoginRequest{Email: "x@y.z", Password: "oops"}
+	svc.On("Login", body, "test-secret", time.Minute).Return("", services.ErrLoginLocked)
+	svc.On("LoginLockout", "x@y.z").Return(4*time.Minute + time.Second)
+
+	b, _ := json.Marshal(body)
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/auth/login", bytes.NewReader(b))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "300", w.Header().Get("Retry-After"))
+	assert.Contains(t, w.Body.String(), `"retry_after_minutes":5`)
+}
+
+func TestLockoutStatus(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	svc := new(mocks.AuthServiceMock)
+	setupAuth(r, svc)
+	svc.On("LoginLockout", "locked@y.z").Return(30 * time.Second)
+	svc.On("LoginLockout", "free@y.z").Return(time.Duration(0))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/auth/lockout-status?email=locked@y.z", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"locked":true,"retry_after_minutes":1}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/auth/lockout-status?email=free@y.z", nil))
+	assert.JSONEq(t, `{"locked":false,"retry_after_minutes":0}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/auth/lockout-status", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestRegister_ViolationsListed(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	svc := new(mocks.AuthServiceMock)
+	setupAuth(r, svc)
+
+	req := models.RegisterRequest{Name: "root", Email: "a@b.c", Password: "123456"}
+	svc.On("Register", req).Return(nil, core.ValidateName("root").Err())
+
+	b, _ := json.Marshal(req)
+	w := httptest.NewRecorder()
+	httpReq := httptest.NewRequest(http.MethodPost, "/auth/register", bytes.NewReader(b))
+	httpReq.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"name_reserved"`)
+}
+
+func TestRegister_PasswordPolicyEnforcedAtBind(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	SetPasswordPolicy(core.PasswordPolicy{MinLength: 8, RequireDigit: true})
+	t.Cleanup(func() { SetPasswordPolicy(core.DefaultPasswordPolicy()) })
+
+	r := gin.New()
+	svc := new(mocks.AuthServiceMock)
+	setupAuth(r, svc)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/auth/register", bytes.NewReader([]byte(`{"name":"Ahmed","email":"a@b.c","password":"no-digits-here"}`)))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	svc.AssertNotCalled(t, "Register", mock.Anything)
+}
@@ -0,0 +1,32 @@
+package handlers // Startup diagnostics reports.
+
+import (
+	"net/http"
+
+	"HelmyTask/bootinfo"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// BootInfoHandler serves the boot reports replicas stored at startup.
+type BootInfoHandler struct {
+	rdb      *redis.Client
+	instance string
+}
+
+// NewBootInfoHandler wires the Redis client holding the reports and this replica's instance ID.
+func NewBootInfoHandler(rdb *redis.Client, instance string) *BootInfoHandler {
+	return &BootInfoHandler{rdb: rdb, instance: instance}
+}
+
+// Get handles GET /admin/boot-info: every replica's latest boot report (last week), latest
+// boot first; "instance" says which replica answered.
+func (h *BootInfoHandler) Get(c *gin.Context) {
+	reports, err := bootinfo.List(c.Request.Context(), h.rdb)
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"instance": h.instance, "reports": reports})
+}
@@ -0,0 +1,28 @@
+package handlers // API changelog and deprecation registry (public).
+
+import (
+	"net/http"
+	"time"
+
+	"HelmyTask/changelog"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Changelog handles GET /api/changelog?since=YYYY-MM-DD: every recorded API change, newest
+// first. Public and cacheable, so client teams can poll it from CI.
+func Changelog(reg *changelog.Registry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var since time.Time
+		if v := c.Query("since"); v != "" {
+			t, err := time.Parse("2006-01-02", v)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid since (want YYYY-MM-DD)"})
+				return
+			}
+			since = t
+		}
+		c.Header("Cache-Control", "public, max-age=300")
+		c.JSON(http.StatusOK, gin.H{"entries": reg.Entries(since)})
+	}
+}
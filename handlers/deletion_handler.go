@@ -0,0 +1,147 @@
+package handlers // Account deletion requests: filed by users, reviewed by admins.
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"HelmyTask/models"
+	"HelmyTask/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DeletionHandler serves the deletion request workflow.
+type DeletionHandler struct{ svc services.DeletionService }
+
+// NewDeletionHandler wires the deletion service.
+func NewDeletionHandler(svc services.DeletionService) *DeletionHandler {
+	return &DeletionHandler{svc: svc}
+}
+
+// Request handles POST /me/deletion-request: files a request for the caller's account
+// (409 with the open request if there already is one).
+func (h *DeletionHandler) Request(c *gin.Context) {
+	uid, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthenticated"})
+		return
+	}
+	var req models.CreateDeletionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	d, err := h.svc.Request(c.Request.Context(), uid, req, c.ClientIP())
+	if errors.Is(err, services.ErrDeletionOpen) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "request": d})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, d)
+}
+
+// Mine handles GET /me/deletion-request: the caller's latest request and its status.
+func (h *DeletionHandler) Mine(c *gin.Context) {
+	uid, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthenticated"})
+		return
+	}
+	d, err := h.svc.Mine(uid)
+	if errors.Is(err, services.ErrDeletionNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, d)
+}
+
+// deletionStatuses are the values GET /admin/deletion-requests?status= accepts.
+var deletionStatuses = []string{models.DeletionPending, models.DeletionApproved, models.DeletionRejected, models.DeletionExpired, models.DeletionCompleted}
+
+// List handles GET /admin/deletion-requests?status=&page=&limit= (oldest first).
+func (h *DeletionHandler) List(c *gin.Context) {
+	status := c.Query("status")
+	if status != "" && !oneOf(deletionStatuses, status) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid status (want " + strings.Join(deletionStatuses, "|") + ")"})
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	paged, err := h.svc.List(status, page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, paged)
+}
+
+// Approve handles POST /admin/deletion-requests/:id/approve (optional {"note": "..."}); the
+// account is then erased in the background.
+func (h *DeletionHandler) Approve(c *gin.Context) {
+	h.review(c, false)
+}
+
+// Reject handles POST /admin/deletion-requests/:id/reject ({"note": "..."}, required).
+func (h *DeletionHandler) Reject(c *gin.Context) {
+	h.review(c, true)
+}
+
+func (h *DeletionHandler) review(c *gin.Context, reject bool) {
+	uid, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthenticated"})
+		return
+	}
+	id, err := parseUint(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	var req models.ReviewDeletionRequest
+	if c.Request.ContentLength != 0 { // approving needs no body
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	req.Note = strings.TrimSpace(req.Note)
+	decide := h.svc.Approve
+	if reject {
+		if req.Note == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "note is required to reject (it is shown to the user)"})
+			return
+		}
+		decide = h.svc.Reject
+	}
+	d, err := decide(c.Request.Context(), id, uid, req.Note, c.ClientIP())
+	switch {
+	case errors.Is(err, services.ErrDeletionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrDeletionDecided), errors.Is(err, services.ErrDeletionOverdue):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "request": d})
+	case errors.Is(err, services.ErrDeletionSelfReview):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusOK, d)
+	}
+}
+
+func oneOf(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,48 @@
+package handlers // Operator diagnostics for one replica.
+
+import (
+	"net/http"
+	"runtime"
+	"time"
+
+	"HelmyTask/schemacheck"
+	"HelmyTask/utils/leader"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DiagnosticsHandler reports process-level state that differs between replicas.
+type DiagnosticsHandler struct {
+	started  time.Time
+	schema   *schemacheck.Checker // nil = not reported
+	electors []*leader.Elector
+}
+
+// NewDiagnosticsHandler wires the schema check and the leader elections to report.
+func NewDiagnosticsHandler(schema *schemacheck.Checker, electors ...*leader.Elector) *DiagnosticsHandler {
+	return &DiagnosticsHandler{started: time.Now(), schema: schema, electors: electors}
+}
+
+// Get handles GET /admin/diagnostics. Behind a load balancer each call lands on some replica;
+// "instance" says which one answered.
+func (h *DiagnosticsHandler) Get(c *gin.Context) {
+	leadership := make([]leader.Status, 0, len(h.electors))
+	for _, e := range h.electors {
+		leadership = append(leadership, e.Status())
+	}
+	instance := ""
+	if len(leadership) > 0 {
+		instance = leadership[0].Instance
+	}
+	out := gin.H{
+		"instance":   instance,
+		"uptime":     time.Since(h.started).Round(time.Second).String(),
+		"go_version": runtime.Version(),
+		"goroutines": runtime.NumGoroutine(),
+		"leadership": leadership,
+	}
+	if h.schema != nil {
+		out["schema"] = h.schema.Last() // refreshed by /readyz; mismatches explain refused writes
+	}
+	c.JSON(http.StatusOK, out)
+}
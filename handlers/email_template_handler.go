@@ -0,0 +1,51 @@
+package handlers // Email template previews (admin).
+
+import (
+	"errors"
+	"net/http"
+
+	"HelmyTask/emailtmpl"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EmailTemplateHandler serves /admin/email-templates.
+type EmailTemplateHandler struct {
+	templates *emailtmpl.Registry
+	appName   string
+}
+
+// NewEmailTemplateHandler wires the template registry; appName fills the previews.
+func NewEmailTemplateHandler(templates *emailtmpl.Registry, appName string) *EmailTemplateHandler {
+	return &EmailTemplateHandler{templates: templates, appName: appName}
+}
+
+// List handles GET /admin/email-templates: every kind with the locales it has variants for.
+func (h *EmailTemplateHandler) List(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"default_locale": h.templates.DefaultLocale(), "kinds": h.templates.Kinds()})
+}
+
+// Preview handles GET /admin/email-templates/:kind/preview?locale=ar-EG with sample data.
+// The response shows which variant the fallback chain picked; format=html returns the HTML
+// part alone so it can be opened in a browser.
+func (h *EmailTemplateHandler) Preview(c *gin.Context) {
+	msg, err := h.templates.Render(c.Param("kind"), c.Query("locale"), emailtmpl.Data{
+		AppName: h.appName,
+		Name:    "Sample User",
+		Link:    "https://example.com/preview?token=sample",
+		Expires: "1 hour",
+	})
+	if errors.Is(err, emailtmpl.ErrUnknownKind) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "unknown email kind"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if c.Query("format") == "html" {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(msg.HTML))
+		return
+	}
+	c.JSON(http.StatusOK, msg)
+}
@@ -0,0 +1,42 @@
+package handlers // Provider callbacks for email delivery events.
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"HelmyTask/models"
+	"HelmyTask/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EmailWebhookHandler receives bounce/complaint/delivered callbacks from the mail provider.
+type EmailWebhookHandler struct {
+	svc    services.EmailDeliveryService
+	secret string // shared secret the provider sends in X-Webhook-Secret
+}
+
+// NewEmailWebhookHandler constructs the handler.
+func NewEmailWebhookHandler(svc services.EmailDeliveryService, secret string) *EmailWebhookHandler {
+	return &EmailWebhookHandler{svc: svc, secret: secret}
+}
+
+// Receive handles POST /webhooks/email (public, shared-secret protected).
+func (h *EmailWebhookHandler) Receive(c *gin.Context) {
+	got := c.GetHeader("X-Webhook-Secret")
+	if subtle.ConstantTimeCompare([]byte(got), []byte(h.secret)) != 1 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid webhook secret"})
+		return
+	}
+	var ev models.EmailEvent
+	if err := c.ShouldBindJSON(&ev); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.svc.HandleEvent(ev); err != nil {
+		// Acknowledge anyway: providers retry non-2xx for days, and an unknown ID won't become known.
+		c.JSON(http.StatusAccepted, gin.H{"status": "ignored"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "processed"})
+}
@@ -0,0 +1,42 @@
+package handlers // Catalog of the API's error codes (public).
+
+import (
+	"net/http"
+
+	"HelmyTask/apperr"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrorCatalog handles GET /api/errors?group=: every error code clients can receive, with
+// its HTTP status, message template and docs link (docsBase + code; none if docsBase is "").
+// Public and cacheable, like the changelog.
+func ErrorCatalog(docsBase string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		entries := apperr.Catalog(docsBase)
+		if g := c.Query("group"); g != "" {
+			filtered := entries[:0]
+			for _, e := range entries {
+				if e.Group == g {
+					filtered = append(filtered, e)
+				}
+			}
+			entries = filtered
+		}
+		c.Header("Cache-Control", "public, max-age=300")
+		c.JSON(http.StatusOK, gin.H{"errors": entries})
+	}
+}
+
+// ErrorCode handles GET /api/errors/:code (404 for a code the API never returns).
+func ErrorCode(docsBase string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		e, ok := apperr.Lookup(c.Param("code"), docsBase)
+		if !ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": "unknown error code"})
+			return
+		}
+		c.Header("Cache-Control", "public, max-age=300")
+		c.JSON(http.StatusOK, e)
+	}
+}
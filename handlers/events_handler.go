@@ -0,0 +1,166 @@
+package handlers // Live user change events over WebSocket.
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+
+	"HelmyTask/core"
+	"HelmyTask/events"
+	"HelmyTask/middlewares"
+	"HelmyTask/policy"
+	"HelmyTask/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+// Keepalive: a ping every wsPingInterval; a client silent (no pong) for wsPongWait is dropped.
+// Every wsRecheckInterval the caller's access is checked again (see WithStreamAccessCheck).
+const (
+	wsPingInterval    = 30 * time.Second
+	wsPongWait        = 60 * time.Second
+	wsWriteWait       = 10 * time.Second
+	wsRecheckInterval = time.Minute
+	wsReadLimit       = 512 // bytes per client message; they are ignored, so only control frames matter
+)
+
+// errStreamRevoked ends a stream whose caller lost access after connecting.
+var errStreamRevoked = errors.New("access revoked")
+
+// EventsHandler streams events.Hub to WebSocket clients.
+type EventsHandler struct {
+	hub      *events.Hub
+	upgrader websocket.Upgrader
+	access   streamAccess
+}
+
+// streamAccess re-checks the caller of a long-lived stream (the /ws events, the log tail).
+type streamAccess struct {
+	revocations middlewares.TokenRevocations // nil = revocations not re-checked
+	users       services.UserAdminService    // nil = account status and role not re-checked
+}
+
+// allowed re-checks the access of uid, streaming since since: no logins revoked after that,
+// and the account still active and granted perm.
+func (a streamAccess) allowed(ctx context.Context, uid core.UserID, since time.Time, perm policy.Permission) error {
+	if a.revocations != nil {
+		if revoked, err := a.revocations.Revoked(ctx, uint(uid), since); err == nil && revoked { // errors keep the stream, like VerifyToken
+			return errStreamRevoked
+		}
+	}
+	if a.users != nil {
+		u, err := a.users.GetUser(ctx, uid)
+		if err != nil || !u.IsActive() || !policy.Allowed(u.Role, perm) {
+			return errStreamRevoked
+		}
+	}
+	return nil
+}
+
+// EventsHandlerOption customizes an EventsHandler.
+type EventsHandlerOption func(*EventsHandler)
+
+// WithStreamAccessCheck re-checks open streams periodically: a stream is closed once the
+// caller's logins were revoked after it connected (password change, ban, role change), or the
+// account is gone, inactive or no longer allowed to read users. The handshake's credentials are
+// only checked once, and a stream can stay open for days.
+func WithStreamAccessCheck(revocations middlewares.TokenRevocations, users services.UserAdminService) EventsHandlerOption {
+	return func(h *EventsHandler) { h.access = streamAccess{revocations: revocations, users: users} }
+}
+
+// NewEventsHandler accepts browser connections from the same origin or a trusted one (origins
+// may be nil); clients without an Origin header (not browsers) are always accepted.
+func NewEventsHandler(hub *events.Hub, origins middlewares.OriginChecker, opts ...EventsHandlerOption) *EventsHandler {
+	h := &EventsHandler{hub: hub, upgrader: websocket.Upgrader{
+		Subprotocols: []string{"bearer"}, // echoed when the token came that way (see middlewares.WebSocketBearer)
+		CheckOrigin: func(r *http.Request) bool { // the session cookie rides along cross-site: no hijacking from untrusted pages
+			origin := r.Header.Get("Origin")
+			if origin == "" {
+				return true
+			}
+			if u, err := url.Parse(origin); err == nil && u.Host == r.Host {
+				return true
+			}
+			return origins != nil && origins.Allowed(r.Context(), origin)
+		},
+	}}
+	for _, o := range opts {
+		o(h)
+	}
+	return h
+}
+
+// allowed re-checks the access of uid, streaming since since.
+func (h *EventsHandler) allowed(ctx context.Context, uid core.UserID, since time.Time) error {
+	return h.access.allowed(ctx, uid, since, policy.UsersRead)
+}
+
+// Stream handles GET /ws (protected): upgrades the connection and sends every user change as a
+// JSON text message, {"type": "user.created|user.updated|user.deleted", "user_id", "user", "at"}.
+// Messages from the client are ignored. With WithStreamAccessCheck, a caller who loses access is
+// disconnected (close code 1008, policy violation) within wsRecheckInterval.
+func (h *EventsHandler) Stream(c *gin.Context) {
+	uid, identified := currentUserID(c)
+	ctx := c.Request.Context()
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil) // answers the failed handshake itself
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	since := time.Now()
+	sub, cancel := h.hub.Subscribe()
+	defer cancel()
+
+	// Reader: handles pongs and notices the client going away.
+	gone := make(chan struct{})
+	go func() {
+		defer close(gone)
+		conn.SetReadLimit(wsReadLimit) // larger messages close the connection
+		_ = conn.SetReadDeadline(time.Now().Add(wsPongWait))
+		conn.SetPongHandler(func(string) error { return conn.SetReadDeadline(time.Now().Add(wsPongWait)) })
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ping := time.NewTicker(wsPingInterval)
+	defer ping.Stop()
+	recheck := time.NewTicker(wsRecheckInterval)
+	defer recheck.Stop()
+	for {
+		select {
+		case <-gone:
+			return
+		case <-recheck.C:
+			if !identified {
+				continue
+			}
+			if err := h.allowed(ctx, uid, since); err != nil {
+				_ = conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+				_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.ClosePolicyViolation, err.Error()))
+				return
+			}
+		case e, ok := <-sub:
+			_ = conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if !ok { // server shutting down
+				_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down"))
+				return
+			}
+			if err := conn.WriteJSON(e); err != nil {
+				slog.Debug("ws: write failed", "err", err)
+				return
+			}
+		case <-ping.C:
+			_ = conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
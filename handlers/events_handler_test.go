@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"HelmyTask/core"
+	"HelmyTask/mocks"
+	"HelmyTask/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// revokedSince is a TokenRevocations that revoked every login of its users at one moment.
+type revokedSince map[uint]time.Time
+
+func (r revokedSince) Revoked(_ context.Context, uid uint, issuedAt time.Time) (bool, error) {
+	at, ok := r[uid]
+	return ok && issuedAt.Before(at), nil
+}
+
+func TestEventsHandler_AccessRecheck(t *testing.T) {
+	connected := time.Now()
+	users := new(mocks.UserAdminServiceMock)
+	users.On("GetUser", core.UserID(1)).Return(&models.User{ID: 1, Role: models.RoleAdmin, Status: models.StatusActive}, nil)
+	users.On("GetUser", core.UserID(2)).Return(&models.User{ID: 2, Role: models.RoleAdmin, Status: models.StatusBanned}, nil)
+	users.On("GetUser", core.UserID(3)).Return(&models.User{ID: 3, Role: models.RoleUser, Status: models.StatusActive}, nil) // demoted
+	users.On("GetUser", core.UserID(4)).Return(nil, errors.New("record not found"))
+	users.On("GetUser", core.UserID(5)).Return(&models.User{ID: 5, Role: models.RoleAdmin, Status: models.StatusActive}, nil)
+	h := NewEventsHandler(nil, nil, WithStreamAccessCheck(revokedSince{5: connected.Add(time.Second)}, users))
+
+	ctx := context.Background()
+	assert.NoError(t, h.allowed(ctx, 1, connected))
+	for _, uid := range []core.UserID{2, 3, 4, 5} {
+		assert.ErrorIs(t, h.allowed(ctx, uid, connected), errStreamRevoked, "user %d", uid)
+	}
+	assert.NoError(t, NewEventsHandler(nil, nil).allowed(ctx, 2, connected), "nothing to check against")
+}
@@ -0,0 +1,30 @@
+package handlers // Experiment assignments of the current user.
+
+import (
+	"net/http"
+
+	"HelmyTask/experiments"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ExperimentHandler serves the caller's experiment variants.
+type ExperimentHandler struct {
+	reg *experiments.Registry
+}
+
+// NewExperimentHandler wires the registry.
+func NewExperimentHandler(reg *experiments.Registry) *ExperimentHandler {
+	return &ExperimentHandler{reg: reg}
+}
+
+// Mine handles GET /me/experiments (protected): {"experiments": [{"experiment", "variant"}, ...]}.
+// The exposure is recorded by middlewares.Experiments, like on any other authenticated route.
+func (h *ExperimentHandler) Mine(c *gin.Context) {
+	uid, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthenticated"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"experiments": h.reg.Assign(uint(uid))})
+}
@@ -0,0 +1,18 @@
+package handlers // Answers for requests no route takes.
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NotFound answers paths no route matches, in the same JSON shape as every other error.
+func NotFound(c *gin.Context) {
+	c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+}
+
+// MethodNotAllowed answers a known path requested with a method it doesn't serve; the router
+// has already listed the methods it does serve in the Allow header.
+func MethodNotAllowed(c *gin.Context) {
+	c.JSON(http.StatusMethodNotAllowed, gin.H{"error": "method not allowed", "allowed": c.Writer.Header().Get("Allow")})
+}
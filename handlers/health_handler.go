@@ -0,0 +1,178 @@
+package handlers // Liveness/readiness probes for orchestrators and load balancers.
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"HelmyTask/global"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HealthCheck probes one dependency (DB ping, Redis ping...); nil means healthy.
+type HealthCheck func(ctx context.Context) error
+
+// readyTimeout bounds a check without its own timeout, so a hung dependency reads as "not
+// ready", not a hung probe.
+const readyTimeout = 2 * time.Second
+
+// CheckPolicy says how much a check's failure matters to /readyz.
+type CheckPolicy struct {
+	Optional bool          // failing only degrades: /readyz still answers 200, so pods aren't pulled for it
+	Timeout  time.Duration // 0 = readyTimeout
+}
+
+// HealthOption customizes a HealthHandler.
+type HealthOption func(*HealthHandler)
+
+// WithCheckPolicy sets the policy of the named check (checks are critical by default).
+func WithCheckPolicy(name string, p CheckPolicy) HealthOption {
+	return func(h *HealthHandler) { h.policies[name] = p }
+}
+
+// WithCheckCache reuses check results for ttl, so frequent probes from the orchestrator, load
+// balancers and the status page don't each ping every dependency.
+func WithCheckCache(ttl time.Duration) HealthOption {
+	return func(h *HealthHandler) { h.cacheTTL = ttl }
+}
+
+// HealthHandler serves /healthz, /readyz and /ping.
+type HealthHandler struct {
+	checks   map[string]HealthCheck
+	policies map[string]CheckPolicy
+	draining atomic.Bool // set on SIGTERM; /readyz then fails so the load balancer stops routing here
+	started  time.Time   // carries a monotonic reading, so uptime ignores wall clock jumps
+
+	cacheTTL time.Duration
+	runMu    sync.Mutex // one run at a time; callers waiting on it get its results
+	cached   map[string]string
+	cachedAt time.Time
+}
+
+// NewHealthHandler wires the readiness checks, keyed by name ("db", "redis"...).
+func NewHealthHandler(checks map[string]HealthCheck, opts ...HealthOption) *HealthHandler {
+	h := &HealthHandler{checks: checks, policies: map[string]CheckPolicy{}, started: time.Now()}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// Optional reports whether the named check only degrades readiness.
+func (h *HealthHandler) Optional(name string) bool { return h.policies[name].Optional }
+
+// Drain makes /readyz report not-ready from now on (shutdown has begun). /healthz is unaffected,
+// so the orchestrator doesn't kill the pod while in-flight requests finish.
+func (h *HealthHandler) Drain() { h.draining.Store(true) }
+
+// Live handles GET /healthz: the process is up and serving HTTP. Dependencies are not checked,
+// so an outage doesn't get every replica restarted.
+func (h *HealthHandler) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Ping handles GET /ping (public, tightly rate limited): version and uptime for external uptime
+// monitors. Nothing is checked, so polling it costs no DB or Redis round trips beyond the
+// rate limiter's; point monitors here rather than at /readyz.
+func (h *HealthHandler) Ping(c *gin.Context) {
+	uptime := time.Since(h.started)
+	c.JSON(http.StatusOK, gin.H{
+		"status":         "ok",
+		"version":        global.AppVersion,
+		"uptime":         uptime.Round(time.Second).String(),
+		"uptime_seconds": int64(uptime.Seconds()),
+	})
+}
+
+// Ready handles GET /readyz: 200 when every critical check passes ("degraded" if an optional
+// one fails), else 503 with the failing checks. The endpoint is public, so a failing check reads
+// "unavailable"; the error itself only goes to the log.
+func (h *HealthHandler) Ready(c *gin.Context) {
+	if h.draining.Load() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "draining"})
+		return
+	}
+	results := h.Check(c.Request.Context())
+	ready, degraded := true, []string{}
+	for name, status := range results {
+		if status == "ok" {
+			continue
+		}
+		results[name] = "unavailable"
+		if h.Optional(name) {
+			degraded = append(degraded, name)
+		} else {
+			ready = false
+		}
+	}
+
+	if !ready {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "not ready", "checks": results})
+		return
+	}
+	if len(degraded) > 0 {
+		sort.Strings(degraded)
+		c.JSON(http.StatusOK, gin.H{"status": "degraded", "checks": results, "degraded": degraded})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ready", "checks": results})
+}
+
+// Check returns "ok" or the error text per check. Also used by the public status page. With
+// WithCheckCache, results younger than the TTL are reused.
+func (h *HealthHandler) Check(ctx context.Context) map[string]string {
+	h.runMu.Lock()
+	defer h.runMu.Unlock()
+	if h.cacheTTL > 0 && h.cached != nil && time.Since(h.cachedAt) < h.cacheTTL {
+		return copyResults(h.cached)
+	}
+	results := h.run(context.WithoutCancel(ctx)) // a client hanging up mustn't cache "context canceled"
+	if h.cacheTTL > 0 {
+		h.cached, h.cachedAt = results, time.Now()
+	}
+	return copyResults(results)
+}
+
+// run runs every check concurrently, each bounded by its timeout.
+func (h *HealthHandler) run(ctx context.Context) map[string]string {
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		results = make(map[string]string, len(h.checks))
+	)
+	for name, check := range h.checks { // run concurrently; the slowest check sets the latency
+		wg.Add(1)
+		go func(name string, check HealthCheck) {
+			defer wg.Done()
+			timeout := h.policies[name].Timeout
+			if timeout <= 0 {
+				timeout = readyTimeout
+			}
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			status := "ok"
+			if err := check(ctx); err != nil {
+				status = err.Error()
+				slog.Warn("health: check failed", "check", name, "err", err)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			results[name] = status
+		}(name, check)
+	}
+	wg.Wait()
+	return results
+}
+
+func copyResults(m map[string]string) map[string]string {
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"HelmyTask/global"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthHandler_Ready(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	redisUp := true
+	h := NewHealthHandler(map[string]HealthCheck{
+		"db": func(context.Context) error { return nil },
+		"redis": func(context.Context) error {
+			if !redisUp {
+				return errors.New("connection refused")
+			}
+			return nil
+		},
+	})
+	r := gin.New()
+	r.GET("/healthz", h.Live)
+	r.GET("/readyz", h.Ready)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	redisUp = false
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), `"redis":"unavailable"`)
+	assert.NotContains(t, w.Body.String(), "connection refused", "error detail stays in the log")
+
+	// liveness ignores dependencies
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestHealthHandler_OptionalCheckOnlyDegrades(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := NewHealthHandler(map[string]HealthCheck{
+		"db":    func(context.Context) error { return nil },
+		"redis": func(ctx context.Context) error { <-ctx.Done(); return ctx.Err() }, // hangs
+	}, WithCheckPolicy("redis", CheckPolicy{Optional: true, Timeout: 10 * time.Millisecond}))
+	r := gin.New()
+	r.GET("/readyz", h.Ready)
+
+	start := time.Now()
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"degraded"`)
+	assert.Contains(t, w.Body.String(), `"degraded":["redis"]`)
+	assert.Less(t, time.Since(start), readyTimeout, "the check's own timeout applies")
+}
+
+func TestHealthHandler_CheckCache(t *testing.T) {
+	calls := 0
+	h := NewHealthHandler(map[string]HealthCheck{"db": func(context.Context) error { calls++; return nil }}, WithCheckCache(time.Minute))
+
+	h.Check(context.Background())
+	got := h.Check(context.Background())
+	assert.Equal(t, 1, calls)
+	got["db"] = "tampered"
+	assert.Equal(t, "ok", h.Check(context.Background())["db"], "callers get copies")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	h = NewHealthHandler(map[string]HealthCheck{"db": func(ctx context.Context) error { return ctx.Err() }}, WithCheckCache(time.Minute))
+	assert.Equal(t, "ok", h.Check(ctx)["db"], "a caller hanging up doesn't fail (or cache) the check")
+}
+
+func TestHealthHandler_DrainFailsReadinessOnly(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := NewHealthHandler(nil)
+	r := gin.New()
+	r.GET("/healthz", h.Live)
+	r.GET("/readyz", h.Ready)
+
+	h.Drain()
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), "draining")
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestHealthHandler_PingRunsNoChecks(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	called := false
+	h := NewHealthHandler(map[string]HealthCheck{"db": func(context.Context) error { called = true; return nil }})
+	r := gin.New()
+	r.GET("/ping", h.Ping)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ping", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"version":"`+global.AppVersion+`"`)
+	assert.Contains(t, w.Body.String(), `"uptime_seconds":0`)
+	assert.False(t, called)
+}
@@ -0,0 +1,57 @@
+package handlers // Background job queue status and dead letters.
+
+import (
+	"net/http"
+
+	"HelmyTask/jobs"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JobsHandler exposes the job queue to admins.
+type JobsHandler struct {
+	q *jobs.Queue
+}
+
+// NewJobsHandler wires the queue.
+func NewJobsHandler(q *jobs.Queue) *JobsHandler {
+	return &JobsHandler{q: q}
+}
+
+// requeueDeadRequest selects dead jobs by ID; none = all of them.
+type requeueDeadRequest struct {
+	IDs []string `json:"ids"`
+}
+
+// Get handles GET /admin/jobs: counts per state and the 50 newest dead jobs with their last error.
+func (h *JobsHandler) Get(c *gin.Context) {
+	stats, err := h.q.Stats(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+	dead, err := h.q.Dead(c.Request.Context(), 50)
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"stats": stats, "dead": dead})
+}
+
+// RequeueDead handles POST /admin/jobs/dead/requeue with {"ids":[...]} (or {} for every dead
+// job): the jobs run again with a fresh set of attempts.
+func (h *JobsHandler) RequeueDead(c *gin.Context) {
+	var req requeueDeadRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	n, err := h.q.RequeueDead(c.Request.Context(), req.IDs)
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error(), "requeued": n})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"requeued": n})
+}
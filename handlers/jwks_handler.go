@@ -0,0 +1,18 @@
+package handlers // Public key discovery for token verifiers.
+
+import (
+	"net/http"
+
+	"HelmyTask/utils/jwtkeys"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JWKS handles GET /.well-known/jwks.json: the active RS256 public keys, so other services can
+// verify our tokens (matching on "kid") without sharing a secret.
+func JWKS(keys *jwtkeys.KeySet) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Cache-Control", "public, max-age=300") // verifiers refetch on an unknown kid anyway
+		c.JSON(http.StatusOK, keys.JWKS())
+	}
+}
@@ -0,0 +1,146 @@
+package handlers // Redis log size controls: usage, runtime limits and archival.
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"HelmyTask/audit"
+	"HelmyTask/settings"
+	"HelmyTask/utils/redislog"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LogAdminHandler serves /admin/logs/storage, /admin/logs/retention and /admin/logs/archive.
+type LogAdminHandler struct {
+	log        *redislog.Logger
+	store      *settings.Store // limits are settings overrides, so every replica applies them
+	audit      *audit.Recorder // nil = not audited
+	archiveDir string          // "" = archival off
+}
+
+// NewLogAdminHandler wires the Redis log, the settings store holding its limits, the audit
+// trail and the directory archives go to.
+func NewLogAdminHandler(l *redislog.Logger, store *settings.Store, rec *audit.Recorder, archiveDir string) *LogAdminHandler {
+	return &LogAdminHandler{log: l, store: store, audit: rec, archiveDir: archiveDir}
+}
+
+// Storage handles GET /admin/logs/storage: entries, memory and oldest entry of the log key,
+// with the limits in effect on this replica.
+func (h *LogAdminHandler) Storage(c *gin.Context) {
+	u, err := h.log.Usage(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, u)
+}
+
+// logRetentionRequest is the body of PUT /admin/logs/retention; absent fields are unchanged.
+type logRetentionRequest struct {
+	MaxLen    *int64  `json:"max_len"`
+	Retention *string `json:"retention"` // Go duration; "0s" = no age limit
+}
+
+// Retention handles PUT /admin/logs/retention: stores new limits as settings overrides (the
+// rest of the override set is kept), so every replica applies them within a few seconds.
+// Lowering max_len drops the excess with the next write; see Archive to keep it.
+func (h *LogAdminHandler) Retention(c *gin.Context) {
+	var req logRetentionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.MaxLen == nil && req.Retention == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "set max_len, retention or both"})
+		return
+	}
+	before := h.store.Overrides()
+	o := before
+	if req.MaxLen != nil {
+		o.LogMaxLen = req.MaxLen
+	}
+	if req.Retention != nil {
+		o.LogRetention = req.Retention
+	}
+	if v := o.Validate(); len(v) > 0 {
+		badRequest(c, v)
+		return
+	}
+	eff, err := h.store.Set(c.Request.Context(), o)
+	if err != nil { // Redis down/absent.
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+	actor, _ := currentUserID(c)
+	h.audit.Record(audit.Event{
+		ActorID: uint(actor), Action: audit.ActionSettingsUpdate, TargetType: "settings",
+		Before: before, After: o, IP: c.ClientIP(),
+	})
+	c.JSON(http.StatusOK, gin.H{"max_len": eff.LogMaxLen, "retention": eff.LogRetention})
+}
+
+// logArchiveRequest is the optional body of POST /admin/logs/archive.
+type logArchiveRequest struct {
+	OlderThan string `json:"older_than"` // Go duration, default "1h"; "0s" archives everything
+}
+
+// Archive handles POST /admin/logs/archive: moves entries older than older_than out of Redis
+// into a gzipped JSON-lines file in log_archive_dir on the replica that answers, and returns
+// its path.
+func (h *LogAdminHandler) Archive(c *gin.Context) {
+	if h.archiveDir == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "log archival is off (log_archive_dir is empty)"})
+		return
+	}
+	req := logArchiveRequest{OlderThan: "1h"}
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	olderThan, err := time.ParseDuration(req.OlderThan)
+	if err != nil || olderThan < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "older_than must be a duration >= 0, e.g. \"1h\""})
+		return
+	}
+
+	path, n, err := h.archive(c, olderThan)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error(), "archived": n})
+		return
+	}
+	actor, _ := currentUserID(c)
+	h.audit.Record(audit.Event{
+		ActorID: uint(actor), Action: audit.ActionLogsArchive, TargetType: "logs",
+		After: map[string]any{"archived": n, "file": path, "older_than": req.OlderThan}, IP: c.ClientIP(),
+	})
+	c.JSON(http.StatusOK, gin.H{"archived": n, "file": path})
+}
+
+// archive writes the file and trims Redis; an archive with nothing in it is not kept.
+func (h *LogAdminHandler) archive(c *gin.Context, olderThan time.Duration) (string, int64, error) {
+	if err := os.MkdirAll(h.archiveDir, 0o750); err != nil {
+		return "", 0, err
+	}
+	name := fmt.Sprintf("%s-%s.jsonl.gz", strings.ReplaceAll(h.log.Key(), ":", "_"), time.Now().UTC().Format("20060102T150405Z"))
+	path := filepath.Join(h.archiveDir, name)
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o640)
+	if err != nil {
+		return "", 0, err
+	}
+	zw := gzip.NewWriter(f)
+	n, err := h.log.Archive(c.Request.Context(), zw, olderThan)
+	err = errors.Join(err, zw.Close(), f.Close())
+	if n == 0 && err == nil {
+		return "", 0, os.Remove(path)
+	}
+	return path, n, err
+}
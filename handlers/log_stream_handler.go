@@ -0,0 +1,161 @@
+package handlers // Live tail of the application log (Server-Sent Events).
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"HelmyTask/middlewares"
+	"HelmyTask/policy"
+	"HelmyTask/services"
+	"HelmyTask/utils/redislog"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	logStreamPoll      = 2 * time.Second  // one Redis read per poll per client
+	logStreamHeartbeat = 15 * time.Second // keeps proxies from closing an idle stream
+	logStreamRecheck   = time.Minute      // how soon a viewer who lost access is cut off
+	logStreamMaxBack   = 500
+)
+
+// LogStreamHandler tails the Redis log for admins.
+type LogStreamHandler struct {
+	log     *redislog.Logger
+	access  streamAccess
+	recheck time.Duration
+}
+
+// LogStreamOption customizes a LogStreamHandler.
+type LogStreamOption func(*LogStreamHandler)
+
+// WithLogStreamAccessCheck re-checks open tails periodically, like WithStreamAccessCheck does
+// for /ws: a viewer whose logins were revoked, whose account is gone or inactive, or who may no
+// longer read logs stops receiving them.
+func WithLogStreamAccessCheck(revocations middlewares.TokenRevocations, users services.UserAdminService) LogStreamOption {
+	return func(h *LogStreamHandler) { h.access = streamAccess{revocations: revocations, users: users} }
+}
+
+// NewLogStreamHandler wires the Redis log (list or stream mode).
+func NewLogStreamHandler(l *redislog.Logger, opts ...LogStreamOption) *LogStreamHandler {
+	h := &LogStreamHandler{log: l, recheck: logStreamRecheck}
+	for _, o := range opts {
+		o(h)
+	}
+	return h
+}
+
+// Stream handles GET /admin/logs/stream?level=warn&levels=&backlog=50 as Server-Sent Events:
+// the last backlog entries (default 50, at most 500), then every new entry from any replica, each
+// as "event: log" with the entry JSON as data (and its stream ID as id, in stream mode).
+// level keeps entries at or above it; levels (comma-separated, e.g. "error,debug") keeps
+// exactly those instead. A comment line is sent every 15s so idle proxies keep the connection.
+// With WithLogStreamAccessCheck, a viewer who loses access gets "event: error" and the stream ends.
+func (h *LogStreamHandler) Stream(c *gin.Context) {
+	uid, identified := currentUserID(c)
+	since := time.Now()
+	keep, err := logLevelFilter(c.Query("level"), c.Query("levels"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	backlog := int64(50)
+	if v := c.Query("backlog"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 0 || n > logStreamMaxBack {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("backlog must be 0..%d", logStreamMaxBack)})
+			return
+		}
+		backlog = n
+	}
+
+	ctx, cancel := context.WithCancel(c.Request.Context()) // ends the tail when the client goes away
+	defer cancel()
+	entries := make(chan redislog.TailEntry, 64)
+	tailErr := make(chan error, 1)
+	go func() {
+		defer close(entries)
+		tailErr <- h.log.Tail(ctx, backlog, logStreamPoll, func(e redislog.TailEntry) error {
+			if !keep(e.Entry.Level) {
+				return nil
+			}
+			select {
+			case entries <- e:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-store")
+	c.Header("X-Accel-Buffering", "no") // nginx: don't buffer the stream
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(logStreamHeartbeat)
+	defer heartbeat.Stop()
+	recheck := time.NewTicker(h.recheck)
+	defer recheck.Stop()
+	for {
+		select {
+		case <-recheck.C:
+			if !identified {
+				continue
+			}
+			if err := h.access.allowed(ctx, uid, since, policy.LogsRead); err != nil {
+				fmt.Fprintf(c.Writer, "event: error\ndata: %s\n\n", strconv.Quote(err.Error()))
+				c.Writer.Flush()
+				return
+			}
+		case e, ok := <-entries:
+			if !ok {
+				if err := <-tailErr; err != nil && ctx.Err() == nil { // Redis failed; tell the viewer before closing
+					fmt.Fprintf(c.Writer, "event: error\ndata: %s\n\n", strconv.Quote(err.Error()))
+					c.Writer.Flush()
+				}
+				return
+			}
+			b, _ := json.Marshal(e.Entry)
+			if e.ID != "" {
+				fmt.Fprintf(c.Writer, "id: %s\n", e.ID)
+			}
+			fmt.Fprintf(c.Writer, "event: log\ndata: %s\n\n", b)
+			c.Writer.Flush()
+		case <-heartbeat.C:
+			fmt.Fprint(c.Writer, ": ping\n\n")
+			c.Writer.Flush()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// logLevelFilter builds the level predicate from ?level= (minimum) or ?levels= (exact list).
+func logLevelFilter(min, exact string) (func(string) bool, error) {
+	valid := map[string]bool{"debug": true, "info": true, "warn": true, "error": true}
+	if exact != "" {
+		set := map[string]bool{}
+		for _, l := range strings.Split(exact, ",") {
+			l = strings.TrimSpace(l)
+			if !valid[l] {
+				return nil, fmt.Errorf("invalid level %q (want debug|info|warn|error)", l)
+			}
+			set[l] = true
+		}
+		return func(level string) bool { return set[level] }, nil
+	}
+	if min == "" {
+		return func(string) bool { return true }, nil
+	}
+	if !valid[min] {
+		return nil, fmt.Errorf("invalid level %q (want debug|info|warn|error)", min)
+	}
+	return func(level string) bool { return redislog.LevelAtLeast(level, min) }, nil
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"HelmyTask/core"
+	"HelmyTask/global"
+	"HelmyTask/mocks"
+	"HelmyTask/models"
+	"HelmyTask/utils/redislog"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogStream_EndsWhenViewerLosesAccess(t *testing.T) {
+	rdb, rmock := mocks.NewRedisMock()
+	rmock.ExpectLRange("logs", 0, 999).SetVal([]string{}) // the backlog read; polls come later than the test lasts
+	users := new(mocks.UserAdminServiceMock)
+	users.On("GetUser", core.UserID(7)).Return(&models.User{ID: 7, Role: models.RoleSupport, Status: models.StatusActive}, nil) // demoted
+	h := NewLogStreamHandler(redislog.New(rdb, "logs", 0, 0), WithLogStreamAccessCheck(nil, users))
+	h.recheck = 10 * time.Millisecond
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) { c.Set(global.CtxUserIDKey, uint(7)); c.Next() })
+	r.GET("/admin/logs/stream", h.Stream)
+	w := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/logs/stream?backlog=0", nil))
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("stream still open after the viewer lost access")
+	}
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "event: error\ndata: \"access revoked\"")
+}
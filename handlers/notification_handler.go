@@ -0,0 +1,81 @@
+package handlers // The current user's in-app notifications.
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"HelmyTask/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NotificationHandler serves /me/notifications.
+type NotificationHandler struct{ svc services.NotificationService }
+
+// NewNotificationHandler wires the notification service.
+func NewNotificationHandler(svc services.NotificationService) *NotificationHandler {
+	return &NotificationHandler{svc: svc}
+}
+
+// List handles GET /me/notifications?unread=true&page=&limit= (newest first, with the unread
+// count for the badge).
+func (h *NotificationHandler) List(c *gin.Context) {
+	uid, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthenticated"})
+		return
+	}
+	unread, err := strconv.ParseBool(c.DefaultQuery("unread", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid unread (want true|false)"})
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	paged, err := h.svc.List(uid, unread, page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, paged)
+}
+
+// MarkRead handles POST /me/notifications/:id/read (404 for someone else's notification).
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	uid, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthenticated"})
+		return
+	}
+	id, err := parseUint(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	err = h.svc.MarkRead(uid, id)
+	if errors.Is(err, services.ErrNotificationNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// MarkAllRead handles POST /me/notifications/read-all: {"marked": n}.
+func (h *NotificationHandler) MarkAllRead(c *gin.Context) {
+	uid, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthenticated"})
+		return
+	}
+	n, err := h.svc.MarkAllRead(uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"marked": n})
+}
@@ -0,0 +1,71 @@
+package handlers // Admin endpoints for trusted browser origins (CORS/CSRF).
+
+import (
+	"errors"
+	"net/http"
+
+	"HelmyTask/utils/origins"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OriginHandler exposes /admin/origins.
+type OriginHandler struct {
+	reg *origins.Registry
+}
+
+// NewOriginHandler constructs the handler.
+func NewOriginHandler(reg *origins.Registry) *OriginHandler {
+	return &OriginHandler{reg: reg}
+}
+
+type originRequest struct {
+	Origin string `json:"origin" binding:"required"` // "https://app.example.com" or "https://*.example.com"
+}
+
+// List handles GET /admin/origins: config entries (read-only here) and runtime ones.
+func (h *OriginHandler) List(c *gin.Context) {
+	configured, runtime, err := h.reg.List(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"configured": configured, "runtime": runtime})
+}
+
+// Add handles POST /admin/origins {"origin": "..."}; effective on every replica within seconds.
+func (h *OriginHandler) Add(c *gin.Context) {
+	var req originRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	o, err := h.reg.Add(c.Request.Context(), req.Origin)
+	if errors.Is(err, origins.ErrInvalidOrigin) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"origin": o})
+}
+
+// Remove handles DELETE /admin/origins?origin=...; config entries can't be removed at runtime.
+func (h *OriginHandler) Remove(c *gin.Context) {
+	removed, err := h.reg.Remove(c.Request.Context(), c.Query("origin"))
+	if errors.Is(err, origins.ErrInvalidOrigin) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !removed {
+		c.JSON(http.StatusNotFound, gin.H{"error": "not a runtime origin"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,36 @@
+package handlers // Go runtime profiles for admins.
+
+import (
+	"net/http/pprof"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Pprof serves net/http/pprof under /debug/pprof/*profile (the route must be mounted exactly
+// there: the index links and named profiles are resolved from that prefix):
+//
+//	/debug/pprof/                     index
+//	/debug/pprof/profile?seconds=30   CPU profile
+//	/debug/pprof/heap, goroutine, allocs, block, mutex, threadcreate
+//	/debug/pprof/trace?seconds=5      execution trace
+//	/debug/pprof/cmdline, symbol
+//
+// e.g. `curl -H "Authorization: Bearer $TOKEN" https://api.example.com/debug/pprof/heap > heap.pb.gz`,
+// then `go tool pprof -http=: heap.pb.gz`.
+func Pprof() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch strings.TrimPrefix(c.Param("profile"), "/") {
+		case "cmdline":
+			pprof.Cmdline(c.Writer, c.Request)
+		case "profile":
+			pprof.Profile(c.Writer, c.Request)
+		case "symbol":
+			pprof.Symbol(c.Writer, c.Request)
+		case "trace":
+			pprof.Trace(c.Writer, c.Request)
+		default:
+			pprof.Index(c.Writer, c.Request) // the index, or a named profile (404 when unknown)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package handlers // Synthetic probe results.
+
+import (
+	"net/http"
+
+	"HelmyTask/prober"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// ProbeHandler serves the latest synthetic probe results (written by whichever replica leads).
+type ProbeHandler struct {
+	rdb *redis.Client
+}
+
+// NewProbeHandler wires the Redis client holding the results.
+func NewProbeHandler(rdb *redis.Client) *ProbeHandler {
+	return &ProbeHandler{rdb: rdb}
+}
+
+// List handles GET /admin/probes; "healthy" is false while any probe is alerting.
+func (h *ProbeHandler) List(c *gin.Context) {
+	results, err := prober.Results(c.Request.Context(), h.rdb)
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+	healthy := true
+	for _, r := range results {
+		healthy = healthy && !r.Alerting
+	}
+	c.JSON(http.StatusOK, gin.H{"healthy": healthy, "probes": results})
+}
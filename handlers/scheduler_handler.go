@@ -0,0 +1,48 @@
+package handlers // Scheduled maintenance jobs and the stats they keep.
+
+import (
+	"net/http"
+
+	"HelmyTask/scheduler"
+	"HelmyTask/stats"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// SchedulerHandler serves the scheduler's per-job status and the stats snapshot it refreshes.
+type SchedulerHandler struct {
+	sched *scheduler.Scheduler
+	rdb   *redis.Client
+}
+
+// NewSchedulerHandler wires the scheduler (for its configured jobs) and the Redis client
+// holding their results.
+func NewSchedulerHandler(sched *scheduler.Scheduler, rdb *redis.Client) *SchedulerHandler {
+	return &SchedulerHandler{sched: sched, rdb: rdb}
+}
+
+// List handles GET /admin/scheduler: each configured job's last run, outcome and next run.
+func (h *SchedulerHandler) List(c *gin.Context) {
+	jobs, err := h.sched.Status(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"jobs": jobs})
+}
+
+// Stats handles GET /admin/stats: the latest snapshot from the refresh_stats job (503 until
+// it has run once).
+func (h *SchedulerHandler) Stats(c *gin.Context) {
+	users, err := stats.GetUsers(c.Request.Context(), h.rdb)
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+	if users == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "stats not computed yet (see the refresh_stats job at /admin/scheduler)"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"users": users})
+}
@@ -0,0 +1,37 @@
+package handlers // Fake users for development databases.
+
+import (
+	"errors"
+	"net/http"
+
+	"HelmyTask/seed"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// SeedHandler creates generated users (package seed). Only registered when env is dev.
+type SeedHandler struct{ db *gorm.DB }
+
+// NewSeedHandler wires the database the users go into.
+func NewSeedHandler(db *gorm.DB) *SeedHandler { return &SeedHandler{db: db} }
+
+// Seed handles POST /admin/seed {count, seed, password, role}: 201 with what was created. The
+// same seed gives the same users, so repeating a call only adds what is missing.
+func (h *SeedHandler) Seed(c *gin.Context) {
+	var o seed.Options
+	if err := c.ShouldBindJSON(&o); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	res, err := seed.Run(c.Request.Context(), h.db, o)
+	if errors.Is(err, seed.ErrCount) || errors.Is(err, seed.ErrRole) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error(), "created": res.Created})
+		return
+	}
+	c.JSON(http.StatusCreated, res)
+}
@@ -0,0 +1,76 @@
+package handlers // Session-mode login/logout (auth_mode: session).
+
+import (
+	"errors"
+	"net/http"
+
+	"HelmyTask/middlewares"
+	"HelmyTask/models"
+	"HelmyTask/services"
+	"HelmyTask/utils/session"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SessionHandler issues opaque session cookies instead of JWTs.
+type SessionHandler struct {
+	svc          services.AuthService
+	store        *session.Store
+	secureCookie bool // false only for local http development
+}
+
+// NewSessionHandler constructs the handler.
+func NewSessionHandler(svc services.AuthService, store *session.Store, secureCookie bool) *SessionHandler {
+	return &SessionHandler{svc: svc, store: store, secureCookie: secureCookie}
+}
+
+// Login handles POST /auth/login in session mode: same credential (and 2FA) checks as JWT mode.
+func (h *SessionHandler) Login(c *gin.Context) {
+	var req models.LoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	u, err := h.svc.Authenticate(c.Request.Context(), req)
+	if errors.Is(err, services.ErrTwoFactorRequired) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error(), "two_factor_required": true})
+		return
+	}
+	if errors.Is(err, services.ErrAccountInactive) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, services.ErrLoginLocked) {
+		loginLocked(c, h.svc, req.Email, err)
+		return
+	}
+	if errors.Is(err, services.ErrTwoFactorUnavailable) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	role := u.Role
+	if role == "" {
+		role = models.RoleUser // rows created before roles existed
+	}
+	sid, err := h.store.Create(c.Request.Context(), u.ID, role)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not create session"})
+		return
+	}
+	c.SetSameSite(http.SameSiteLaxMode) // Blocks cross-site POSTs carrying the cookie.
+	c.SetCookie(middlewares.SessionCookieName, sid, int(h.store.TTL().Seconds()), "/", "", h.secureCookie, true)
+	c.JSON(http.StatusOK, u)
+}
+
+// Logout handles POST /auth/logout: deletes the server-side session and clears the cookie.
+func (h *SessionHandler) Logout(c *gin.Context) {
+	if sid, err := c.Cookie(middlewares.SessionCookieName); err == nil && sid != "" {
+		_ = h.store.Delete(c.Request.Context(), sid) // Already gone is fine.
+	}
+	c.SetCookie(middlewares.SessionCookieName, "", -1, "/", "", h.secureCookie, true)
+	c.Status(http.StatusNoContent)
+}
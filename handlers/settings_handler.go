@@ -0,0 +1,52 @@
+package handlers // Runtime settings overrides (admin).
+
+import (
+	"net/http"
+
+	"HelmyTask/audit"
+	"HelmyTask/settings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SettingsHandler serves /admin/settings.
+type SettingsHandler struct {
+	store *settings.Store
+	audit *audit.Recorder // nil = not audited
+}
+
+// NewSettingsHandler wires the store and the audit trail.
+func NewSettingsHandler(store *settings.Store, rec *audit.Recorder) *SettingsHandler {
+	return &SettingsHandler{store: store, audit: rec}
+}
+
+// Get handles GET /admin/settings: the file/env base, the stored overrides and the merge.
+func (h *SettingsHandler) Get(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"base": h.store.Base(), "overrides": h.store.Overrides(), "effective": h.store.Current()})
+}
+
+// Put handles PUT /admin/settings: the body replaces the whole override set ({} clears it).
+// Other replicas pick the change up within a few seconds.
+func (h *SettingsHandler) Put(c *gin.Context) {
+	var o settings.Overrides
+	if err := c.ShouldBindJSON(&o); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if v := o.Validate(); len(v) > 0 { // Same violation list as other admin writes.
+		badRequest(c, v)
+		return
+	}
+	before := h.store.Overrides()
+	eff, err := h.store.Set(c.Request.Context(), o)
+	if err != nil { // Redis down/absent.
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+	actor, _ := currentUserID(c)
+	h.audit.Record(audit.Event{
+		ActorID: uint(actor), Action: audit.ActionSettingsUpdate, TargetType: "settings",
+		Before: before, After: o, IP: c.ClientIP(),
+	})
+	c.JSON(http.StatusOK, gin.H{"overrides": o, "effective": eff})
+}
@@ -0,0 +1,103 @@
+package handlers // First-run bootstrap (public until the first account exists).
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+
+	"HelmyTask/audit"
+	"HelmyTask/models"
+	"HelmyTask/services"
+	"HelmyTask/settings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupHandler serves /setup.
+type SetupHandler struct {
+	svc   services.SetupService
+	store *settings.Store // nil = no runtime settings to write
+	token string          // required in X-Setup-Token when set
+	audit *audit.Recorder // nil = not audited
+}
+
+// NewSetupHandler wires the setup service, the settings store and the optional setup token.
+func NewSetupHandler(svc services.SetupService, store *settings.Store, token string, rec *audit.Recorder) *SetupHandler {
+	return &SetupHandler{svc: svc, store: store, token: token, audit: rec}
+}
+
+// setupRequest is the first admin plus, optionally, the initial runtime settings.
+type setupRequest struct {
+	models.RegisterRequest
+	Settings *settings.Overrides `json:"settings"` // same body as PUT /admin/settings
+}
+
+// Status handles GET /setup: whether setup is still open, so a frontend can show the wizard.
+func (h *SetupHandler) Status(c *gin.Context) {
+	open, err := h.svc.SetupRequired(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"setup_required": open, "token_required": h.token != ""})
+}
+
+// Run handles POST /setup: creates the first admin and writes the initial settings, then
+// stays locked (403) for good.
+func (h *SetupHandler) Run(c *gin.Context) {
+	if h.token != "" && subtle.ConstantTimeCompare([]byte(c.GetHeader("X-Setup-Token")), []byte(h.token)) != 1 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing or wrong X-Setup-Token"})
+		return
+	}
+	var req setupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Settings != nil { // Checked first, so a bad value doesn't leave setup half done.
+		if h.store == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "runtime settings are not available on this deployment"})
+			return
+		}
+		if v := req.Settings.Validate(); len(v) > 0 {
+			badRequest(c, v)
+			return
+		}
+	}
+
+	u, err := h.svc.Setup(c.Request.Context(), req.RegisterRequest)
+	switch {
+	case errors.Is(err, services.ErrSetupDone):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, services.ErrSetupInProgress):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, services.ErrSetupUnavailable):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		badRequest(c, err)
+		return
+	}
+	h.record(c, audit.Event{ActorID: u.ID, Action: audit.ActionUserCreate, TargetType: "user", TargetID: u.ID, After: u, Secrets: []string{"password"}})
+
+	res := gin.H{"user": u}
+	if req.Settings != nil {
+		before := h.store.Overrides()
+		eff, err := h.store.Set(c.Request.Context(), *req.Settings)
+		if err != nil { // The admin exists; they can retry with PUT /admin/settings.
+			res["settings_error"] = err.Error()
+		} else {
+			res["settings"] = eff
+			h.record(c, audit.Event{ActorID: u.ID, Action: audit.ActionSettingsUpdate, TargetType: "settings", Before: before, After: *req.Settings})
+		}
+	}
+	c.JSON(http.StatusCreated, res)
+}
+
+// record audits e as coming from the client's IP (the recorder is nil-safe).
+func (h *SetupHandler) record(c *gin.Context, e audit.Event) {
+	e.IP = c.ClientIP()
+	h.audit.Record(e)
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"HelmyTask/mocks"
+	"HelmyTask/models"
+	"HelmyTask/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetupHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	svc := new(mocks.SetupServiceMock)
+	h := NewSetupHandler(svc, nil, "tok", nil)
+	r.GET("/setup", h.Status)
+	r.POST("/setup", h.Run)
+	post := func(token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/setup", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("X-Setup-Token", token)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	const admin = `{"name":"Helmy","email":"a@b.c","password":"S3cure-pass!"`
+
+	svc.On("SetupRequired").Return(true, nil).Once()
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/setup", nil))
+	assert.JSONEq(t, `{"setup_required":true,"token_required":true}`, w.Body.String())
+
+	assert.Equal(t, http.StatusUnauthorized, post("", admin+`}`).Code)
+	assert.Equal(t, http.StatusUnauthorized, post("nope", admin+`}`).Code)
+	assert.Equal(t, http.StatusBadRequest, post("tok", admin+`,"settings":{"cache_ttl":"1m"}}`).Code, "no settings store")
+
+	req := models.RegisterRequest{Name: "Helmy", Email: "a@b.c", Password: "S3cure-pass!"}
+	svc.On("Setup", req).Return(&models.User{ID: 1, Role: models.RoleAdmin}, nil).Once()
+	w = post("tok", admin+`}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"role":"admin"`)
+
+	svc.On("Setup", req).Return(nil, services.ErrSetupDone).Once()
+	assert.Equal(t, http.StatusForbidden, post("tok", admin+`}`).Code)
+	svc.AssertExpectations(t)
+}
@@ -0,0 +1,30 @@
+package handlers // Service level objective report.
+
+import (
+	"net/http"
+
+	"HelmyTask/slo"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SLOHandler serves the rolling SLO report.
+type SLOHandler struct {
+	tracker *slo.Tracker
+}
+
+// NewSLOHandler wires the tracker.
+func NewSLOHandler(t *slo.Tracker) *SLOHandler {
+	return &SLOHandler{tracker: t}
+}
+
+// Get handles GET /admin/slo: availability and latency compliance over the configured window,
+// across all replicas.
+func (h *SLOHandler) Get(c *gin.Context) {
+	r, err := h.tracker.Report(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, r)
+}
@@ -0,0 +1,254 @@
+package handlers // Public status page (GET /status) and incident management (admin).
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"HelmyTask/core"
+	"HelmyTask/models"
+	"HelmyTask/services"
+	"HelmyTask/slo"
+
+	"github.com/gin-gonic/gin"
+)
+
+// statusCacheTTL is how long a computed status page is reused (and how long clients and CDNs
+// may cache it). It keeps an unauthenticated endpoint from fanning out to the DB and Redis.
+const statusCacheTTL = 15 * time.Second
+
+// statusBuildTimeout bounds one refresh of the page (the component checks have their own, shorter
+// timeouts; this covers the incident query and the uptime report as well).
+const statusBuildTimeout = 10 * time.Second
+
+// Overall and component statuses, worst last.
+const (
+	StatusOperational   = "operational"
+	StatusMaintenance   = "maintenance"
+	StatusDegraded      = "degraded"
+	StatusPartialOutage = "partial_outage"
+	StatusMajorOutage   = "major_outage"
+)
+
+var statusRank = map[string]int{StatusOperational: 0, StatusMaintenance: 1, StatusDegraded: 2, StatusPartialOutage: 3, StatusMajorOutage: 4}
+
+// impactStatus is the overall status an open incident implies.
+var impactStatus = map[string]string{models.ImpactMinor: StatusDegraded, models.ImpactMajor: StatusPartialOutage, models.ImpactCritical: StatusMajorOutage}
+
+// StatusComponent is one dependency's public state (error details stay on /readyz).
+type StatusComponent struct {
+	Name   string `json:"name"`
+	Status string `json:"status"` // operational|major_outage
+}
+
+// StatusUptime is the rolling availability from the SLO tracker.
+type StatusUptime struct {
+	From         core.Timestamp `json:"from"`
+	To           core.Timestamp `json:"to"`
+	Availability float64        `json:"availability"` // share of requests not answered with 5xx
+	Target       float64        `json:"target"`
+}
+
+// StatusPage is the GET /status body.
+type StatusPage struct {
+	Status      string            `json:"status"`
+	Maintenance string            `json:"maintenance_message,omitempty"`
+	Components  []StatusComponent `json:"components"`
+	Incidents   []models.Incident `json:"incidents"` // open and resolved in the last 7 days, newest first
+	Uptime      *StatusUptime     `json:"uptime,omitempty"`
+	UpdatedAt   core.Timestamp    `json:"updated_at"`
+}
+
+// StatusHandler serves GET /status and /admin/incidents.
+type StatusHandler struct {
+	incidents   services.IncidentService
+	health      *HealthHandler        // component checks (nil = none)
+	slo         *slo.Tracker          // uptime (nil = omitted)
+	maintenance func() (bool, string) // runtime maintenance mode (nil = never)
+
+	mu       sync.Mutex
+	cached   *StatusPage
+	cachedAt time.Time
+	refresh  *statusRefresh // the build in flight, if any
+}
+
+// statusRefresh is one build of the page, shared by every request that arrives while it runs.
+type statusRefresh struct {
+	done chan struct{} // closed when page/err are set
+	page *StatusPage
+	err  error
+}
+
+// NewStatusHandler wires the status page sources; health, tracker and maintenance may be nil.
+func NewStatusHandler(incidents services.IncidentService, health *HealthHandler, tracker *slo.Tracker, maintenance func() (bool, string)) *StatusHandler {
+	return &StatusHandler{incidents: incidents, health: health, slo: tracker, maintenance: maintenance}
+}
+
+// Status handles GET /status (no auth): overall status, components, incidents and uptime.
+func (h *StatusHandler) Status(c *gin.Context) {
+	page, err := h.page(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "status unavailable"})
+		return
+	}
+	c.Header("Cache-Control", "public, max-age=15")
+	c.JSON(http.StatusOK, page)
+}
+
+// page returns the cached page, or waits for a fresh one. Only one build runs at a time, and
+// not under the lock, so readers of a fresh page never queue behind a slow refresh. The build
+// doesn't use the caller's cancellation: the page is shared, and a client hanging up mustn't
+// make it show every component as down.
+func (h *StatusHandler) page(ctx context.Context) (*StatusPage, error) {
+	h.mu.Lock()
+	if h.cached != nil && time.Since(h.cachedAt) < statusCacheTTL {
+		page := h.cached
+		h.mu.Unlock()
+		return page, nil
+	}
+	r := h.refresh
+	if r == nil {
+		r = &statusRefresh{done: make(chan struct{})}
+		h.refresh = r
+		go h.rebuild(context.WithoutCancel(ctx), r)
+	}
+	h.mu.Unlock()
+	select {
+	case <-r.done:
+		return r.page, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// rebuild runs one build and caches its page, unless forget dropped it in the meantime.
+func (h *StatusHandler) rebuild(ctx context.Context, r *statusRefresh) {
+	ctx, cancel := context.WithTimeout(ctx, statusBuildTimeout)
+	defer cancel()
+	r.page, r.err = h.build(ctx)
+	h.mu.Lock()
+	if h.refresh == r {
+		h.refresh = nil
+		if r.err == nil {
+			h.cached, h.cachedAt = r.page, time.Now()
+		}
+	}
+	h.mu.Unlock()
+	close(r.done)
+}
+
+// build computes the page from its sources.
+func (h *StatusHandler) build(ctx context.Context) (*StatusPage, error) {
+	incidents, err := h.incidents.Recent()
+	if err != nil {
+		return nil, err
+	}
+	page := &StatusPage{Status: StatusOperational, Components: []StatusComponent{}, Incidents: incidents, UpdatedAt: core.Timestamp(time.Now())}
+	worsen := func(s string) {
+		if statusRank[s] > statusRank[page.Status] {
+			page.Status = s
+		}
+	}
+	if h.health != nil {
+		for name, result := range h.health.Check(ctx) {
+			comp := StatusComponent{Name: name, Status: StatusOperational}
+			switch {
+			case result == "ok":
+			case h.health.Optional(name): // the API keeps serving without it
+				comp.Status = StatusDegraded
+			default:
+				comp.Status = StatusMajorOutage
+			}
+			worsen(comp.Status)
+			page.Components = append(page.Components, comp)
+		}
+		sort.Slice(page.Components, func(i, j int) bool { return page.Components[i].Name < page.Components[j].Name })
+	}
+	for _, i := range incidents {
+		if i.ResolvedAt == nil {
+			worsen(impactStatus[i.Impact])
+		}
+	}
+	if h.slo != nil {
+		if r, err := h.slo.Report(ctx); err == nil { // Redis down shows up as a component outage already
+			page.Uptime = &StatusUptime{From: core.Timestamp(r.From), To: core.Timestamp(r.To), Availability: r.Availability.Actual, Target: r.Availability.Target}
+			if !r.Availability.Met {
+				worsen(StatusDegraded)
+			}
+		}
+	}
+	if h.maintenance != nil {
+		if on, msg := h.maintenance(); on {
+			worsen(StatusMaintenance)
+			page.Maintenance = msg
+		}
+	}
+	return page, nil
+}
+
+// forget drops the cached page so admin changes show up at once on this replica. A build
+// already running may predate the change, so its page isn't cached either.
+func (h *StatusHandler) forget() {
+	h.mu.Lock()
+	h.cached, h.refresh = nil, nil
+	h.mu.Unlock()
+}
+
+// ListIncidents handles GET /admin/incidents.
+func (h *StatusHandler) ListIncidents(c *gin.Context) {
+	items, err := h.incidents.Recent()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": items})
+}
+
+// CreateIncident handles POST /admin/incidents.
+func (h *StatusHandler) CreateIncident(c *gin.Context) {
+	uid, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthenticated"})
+		return
+	}
+	var req models.CreateIncidentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	i, err := h.incidents.Create(uid, req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	h.forget()
+	c.JSON(http.StatusCreated, i)
+}
+
+// UpdateIncident handles PATCH /admin/incidents/:id (status, impact, title, message).
+func (h *StatusHandler) UpdateIncident(c *gin.Context) {
+	id, err := parseUint(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	var req models.UpdateIncidentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	i, err := h.incidents.Update(id, req)
+	if errors.Is(err, services.ErrIncidentNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "incident not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	h.forget()
+	c.JSON(http.StatusOK, i)
+}
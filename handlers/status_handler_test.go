@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"HelmyTask/core"
+	"HelmyTask/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeIncidents is an in-memory services.IncidentService.
+type fakeIncidents struct {
+	items []models.Incident
+	calls int
+}
+
+func (f *fakeIncidents) Create(core.UserID, models.CreateIncidentRequest) (*models.Incident, error) {
+	return nil, errors.New("not used")
+}
+
+func (f *fakeIncidents) Update(uint, models.UpdateIncidentRequest) (*models.Incident, error) {
+	return nil, errors.New("not used")
+}
+
+func (f *fakeIncidents) Recent() ([]models.Incident, error) {
+	f.calls++
+	return f.items, nil
+}
+
+func getStatus(t *testing.T, h *StatusHandler) (*httptest.ResponseRecorder, StatusPage) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/status", h.Status)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/status", nil))
+	var page StatusPage
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	return w, page
+}
+
+func TestStatus_WorstSignalWinsAndIsCached(t *testing.T) {
+	resolved := time.Now()
+	incidents := &fakeIncidents{items: []models.Incident{
+		{ID: 2, Title: "Slow logins", Impact: models.ImpactMinor, Status: models.IncidentMonitoring},
+		{ID: 1, Title: "Outage", Impact: models.ImpactCritical, Status: models.IncidentResolved, ResolvedAt: &resolved}, // closed: ignored
+	}}
+	health := NewHealthHandler(map[string]HealthCheck{
+		"db":    func(context.Context) error { return nil },
+		"redis": func(context.Context) error { return nil },
+	})
+	h := NewStatusHandler(incidents, health, nil, nil)
+
+	w, page := getStatus(t, h)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "public, max-age=15", w.Header().Get("Cache-Control"))
+	assert.Equal(t, StatusDegraded, page.Status)
+	assert.Equal(t, []StatusComponent{{"db", StatusOperational}, {"redis", StatusOperational}}, page.Components)
+	assert.Len(t, page.Incidents, 2)
+	assert.Nil(t, page.Uptime)
+
+	_, _ = getStatus(t, h)
+	assert.Equal(t, 1, incidents.calls, "second request served from cache")
+}
+
+func TestStatus_CallerHangingUpDoesNotCacheOutage(t *testing.T) {
+	health := NewHealthHandler(map[string]HealthCheck{
+		"db": func(ctx context.Context) error { return ctx.Err() }, // fails only if the build sees a cancellation
+	})
+	h := NewStatusHandler(&fakeIncidents{}, health, nil, nil)
+
+	gone, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _ = h.page(gone) // the shared build goes on without this caller
+
+	page, err := h.page(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, StatusOperational, page.Status)
+	assert.Equal(t, []StatusComponent{{"db", StatusOperational}}, page.Components)
+}
+
+func TestStatus_ComponentOutageHidesErrorDetails(t *testing.T) {
+	health := NewHealthHandler(map[string]HealthCheck{
+		"db": func(context.Context) error { return errors.New("dial tcp 10.0.0.5:3306: connection refused") },
+	})
+	h := NewStatusHandler(&fakeIncidents{}, health, nil, func() (bool, string) { return true, "upgrading" })
+
+	w, page := getStatus(t, h)
+	assert.Equal(t, StatusMajorOutage, page.Status) // outranks maintenance
+	assert.Equal(t, "upgrading", page.Maintenance)
+	assert.NotContains(t, w.Body.String(), "10.0.0.5")
+}
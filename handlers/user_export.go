@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"HelmyTask/core"
+	"HelmyTask/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// exportColumn renders one CSV column of a user; timestamps are shown in loc.
+type exportColumn func(u models.User, loc *time.Location) string
+
+// exportColumns are the columns GET /users/export can emit (?columns=); password/TOTP never are.
+var exportColumns = map[string]exportColumn{
+	"id":                  func(u models.User, _ *time.Location) string { return strconv.FormatUint(uint64(u.ID), 10) },
+	"name":                func(u models.User, _ *time.Location) string { return u.Name },
+	"email":               func(u models.User, _ *time.Location) string { return u.Email },
+	"role":                func(u models.User, _ *time.Location) string { return u.Role },
+	"two_factor_enabled":  func(u models.User, _ *time.Location) string { return strconv.FormatBool(u.TOTPEnabled) },
+	"email_undeliverable": func(u models.User, _ *time.Location) string { return strconv.FormatBool(u.EmailUndeliverable) },
+	"phone":               func(u models.User, _ *time.Location) string { return u.Phone },
+	"date_of_birth":       func(u models.User, _ *time.Location) string { return u.DateOfBirth },
+	"locale":              func(u models.User, _ *time.Location) string { return u.Locale },
+	"timezone":            func(u models.User, _ *time.Location) string { return u.Timezone },
+	"created_at":          func(u models.User, loc *time.Location) string { return exportTime(u.CreatedAt, loc) },
+	"updated_at":          func(u models.User, loc *time.Location) string { return exportTime(u.UpdatedAt, loc) },
+}
+
+// exportTime is RFC 3339 in loc, whole seconds: "2026-10-16T12:30:00+03:00" (or "...Z" in UTC).
+func exportTime(t time.Time, loc *time.Location) string {
+	if loc == time.UTC {
+		return core.FormatTimestamp(t)
+	}
+	return t.In(loc).Format(time.RFC3339)
+}
+
+// exportTZUser is the ?tz= value that renders each row in that user's own timezone.
+const exportTZUser = "user"
+
+// exportLocations resolves ?tz=: "" or UTC (default), an IANA name for every row, or "user"
+// for each user's own timezone (UTC when unset or unknown). Zones are loaded once per export.
+type exportLocations struct {
+	fixed  *time.Location // nil in "user" mode
+	byName map[string]*time.Location
+}
+
+func newExportLocations(tz string) (*exportLocations, error) {
+	if tz == exportTZUser {
+		return &exportLocations{byName: map[string]*time.Location{}}, nil
+	}
+	loc, err := loadZone(tz)
+	if err != nil {
+		return nil, err
+	}
+	return &exportLocations{fixed: loc}, nil
+}
+
+// loadZone is time.LoadLocation ("" → UTC) minus "Local": exports never depend on the server's zone.
+func loadZone(name string) (*time.Location, error) {
+	if strings.EqualFold(name, "local") {
+		return nil, fmt.Errorf("unknown time zone %s", name)
+	}
+	return time.LoadLocation(name)
+}
+
+func (l *exportLocations) of(u models.User) *time.Location {
+	if l.fixed != nil {
+		return l.fixed
+	}
+	loc, ok := l.byName[u.Timezone]
+	if !ok {
+		var err error
+		if loc, err = loadZone(u.Timezone); err != nil {
+			loc = time.UTC
+		}
+		l.byName[u.Timezone] = loc
+	}
+	return loc
+}
+
+// defaultExportColumns is used when ?columns= is absent.
+const defaultExportColumns = "id,name,email,role,created_at"
+
+// ExportUsers handles GET /users/export?format=csv&columns=id,email&tz=user (protected). Takes the
+// same filters as ListUsers (q, email, created_after, created_before) and streams every match as
+// an attachment, reading the table in batches rather than all at once. Timestamps are UTC unless
+// tz names a zone, or is "user" for each user's own timezone.
+func (h *UserHandler) ExportUsers(c *gin.Context) {
+	var q models.ListUserQuery
+	if err := normalizeTimeQuery(c, "created_after", "created_before"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := c.ShouldBindQuery(&q); err != nil { // Bad email or RFC 3339 timestamp → 400.
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var v core.Violations
+	if format := c.DefaultQuery("format", "csv"); format != "csv" {
+		v = append(v, core.Violation{Field: "format", Code: core.CodeFormatInvalid, Message: "supported formats: csv"})
+	}
+	locs, err := newExportLocations(c.Query("tz"))
+	if err != nil {
+		v = append(v, core.Violation{Field: "tz", Code: core.CodeFormatInvalid, Message: `want an IANA time zone (e.g. Africa/Cairo) or "user"`})
+	}
+	names := strings.Split(c.DefaultQuery("columns", defaultExportColumns), ",")
+	cols := make([]exportColumn, 0, len(names))
+	for i, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		col, ok := exportColumns[name]
+		if !ok {
+			v = append(v, core.Violation{Field: "columns", Code: core.CodeColumnInvalid, Message: fmt.Sprintf("unknown column %q", name)})
+			continue
+		}
+		names[i] = name
+		cols = append(cols, col)
+	}
+	if err := v.Err(); err != nil {
+		badRequest(c, err)
+		return
+	}
+
+	w := csv.NewWriter(c.Writer)
+	started := false // Once the header row is out, the status can no longer change.
+	start := func() error {
+		started = true
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="users-%s.csv"`, time.Now().UTC().Format("20060102T150405Z")))
+		c.Header("Cache-Control", "no-store") // Personal data; keep it out of shared caches.
+		c.Status(http.StatusOK)
+		return w.Write(names)
+	}
+	err = h.svc.ExportUsers(c.Request.Context(), q, func(batch []models.User) error {
+		if !started {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		row := make([]string, len(cols))
+		for _, u := range batch {
+			loc := locs.of(u)
+			for i, col := range cols {
+				row[i] = csvSafe(col(u, loc))
+			}
+			if err := w.Write(row); err != nil {
+				return err
+			}
+		}
+		w.Flush() // Push each batch to the client instead of buffering the whole file.
+		c.Writer.Flush()
+		return w.Error()
+	})
+	if err != nil && !started { // Nothing sent yet → a normal error response.
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil { // Mid-stream: the status is already out, so stop writing and leave it to the access log.
+		_ = c.Error(err)
+		c.Abort()
+		return
+	}
+	if !started { // No matching users: header row only.
+		if err := start(); err != nil {
+			_ = c.Error(err)
+			return
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		_ = c.Error(err)
+	}
+}
+
+// csvSafe defuses spreadsheet formula injection: cells starting with = + - @ (or a tab/CR) are
+// prefixed with a quote so Excel/Sheets show them as text instead of evaluating them.
+func csvSafe(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
@@ -1,66 +1,106 @@
 package handlers // Controller layer translates HTTP <-> service calls.
 
 import ( // Imports needed by handlers.
+	"context" // Rate-limit reset hook.
+	"errors" // Match service sentinel errors.
+	"fmt" // Query parameter errors.
 	"net/http" // Status codes and HTTP primitives.
 	"strconv" // String->int parsing for URL params.
-	"time" // For passing JWT expiration to service login.
+	"time" // Timestamp query normalization.
 
+	"HelmyTask/audit" // Change records for mutating endpoints.
+	"HelmyTask/core" // Domain rule violations.
+	"HelmyTask/global" // Context key for the authenticated user ID.
 	"HelmyTask/models" // Request/response DTOs.
 	"HelmyTask/services" // Use-case interface.
 
 	"github.com/gin-gonic/gin" // Gin web framework.
 )
 
-// UserHandler bundles dependencies needed by user endpoints.
+// UserHandler bundles dependencies needed by user management and /me endpoints; sign-up,
+// login and credentials are AuthHandler's.
 type UserHandler struct {
-	svc        services.UserService // Injected business logic.
-	jwtSecret  string // JWT signing secret configured in main.
-	jwtExpires time.Duration // JWT validity duration.
+	svc   services.UserAdminService // Injected business logic.
+	audit *audit.Recorder // Records create/update/delete (nil = not audited).
+	resetRateLimits func(ctx context.Context, ip string) error // Refills an IP's rate-limit buckets on flush (nil = not offered).
+	deletionApproval bool // DELETE /me refused; accounts close through POST /me/deletion-request.
 }
 
-// NewUserHandler constructs a handler for users with its dependencies.
-func NewUserHandler(svc services.UserService, jwtSecret string, jwtExp time.Duration) *UserHandler {
-	return &UserHandler{svc: svc, jwtSecret: jwtSecret, jwtExpires: jwtExp} // Return pointer for methods.
+// UserHandlerOption customizes a UserHandler.
+type UserHandlerOption func(*UserHandler)
+
+// WithAudit records every user create/update/delete with the acting uid and a diff.
+func WithAudit(rec *audit.Recorder) UserHandlerOption {
+	return func(h *UserHandler) { h.audit = rec }
+}
+
+// WithRateLimitReset lets POST /admin/users/:id/flush refill the rate-limit buckets of the IPs
+// it is given (limits are per client IP, not per user).
+func WithRateLimitReset(reset func(ctx context.Context, ip string) error) UserHandlerOption {
+	return func(h *UserHandler) { h.resetRateLimits = reset }
 }
 
-// Register handles POST /auth/register (public).
-func (h *UserHandler) Register(c *gin.Context) {
-	var req models.RegisterRequest // Allocate request payload struct.
-	if err := c.ShouldBindJSON(&req); err != nil { // Bind and validate JSON input.
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()}) // 400 if validation fails.
-		return // Stop handler here.
+// WithDeletionApproval makes closing an account an admin-approved request: DELETE /me answers
+// 403 pointing at POST /me/deletion-request (admins can still delete users directly).
+func WithDeletionApproval() UserHandlerOption {
+	return func(h *UserHandler) { h.deletionApproval = true }
+}
+
+// NewUserHandler constructs a handler for users with its dependencies.
+func NewUserHandler(svc services.UserAdminService, opts ...UserHandlerOption) *UserHandler {
+	h := &UserHandler{svc: svc}
+	for _, o := range opts {
+		o(h)
 	}
-	u, err := h.svc.Register(req) // Delegate to service (hash + save + optional cache warm).
-	if err != nil { // Typically "email already exists".
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()}) // Report error to client.
+	return h // Return pointer for methods.
+}
+
+// record writes an audit entry for a user change made by the authenticated caller.
+func (h *UserHandler) record(c *gin.Context, action string, target core.UserID, before, after *models.User, secrets ...string) {
+	if h.audit == nil {
 		return
 	}
-	c.JSON(http.StatusCreated, u) // 201 Created with user JSON.
+	actor, _ := currentUserID(c)
+	h.audit.Record(audit.Event{
+		ActorID: uint(actor), Action: action, TargetType: "user", TargetID: uint(target),
+		Before: before, After: after, Secrets: secrets, IP: c.ClientIP(),
+	})
 }
 
-// Login handles POST /auth/login (public).
-func (h *UserHandler) Login(c *gin.Context) {
-	var req models.LoginRequest // Allocate request payload struct.
-	if err := c.ShouldBindJSON(&req); err != nil { // Bind/validate JSON.
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()}) // 400 on invalid input.
-		return
+// audited is the request context carrying the audit entry for a change the service makes under
+// a transaction (see audit.WithPending): the service fills in before/after and writes it there.
+func (h *UserHandler) audited(c *gin.Context, action string, target core.UserID, reason string, secrets ...string) context.Context {
+	ctx := c.Request.Context()
+	if h.audit == nil {
+		return ctx
 	}
-	tok, err := h.svc.Login(req, h.jwtSecret, h.jwtExpires) // Delegate to service (validates + signs JWT).
-	if err != nil { // Wrong credentials → 401 Unauthorized.
-		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
-		return
+	actor, _ := currentUserID(c)
+	return audit.WithPending(ctx, audit.Event{
+		ActorID: uint(actor), Action: action, TargetType: "user", TargetID: uint(target),
+		Secrets: secrets, IP: c.ClientIP(), Reason: reason,
+	})
+}
+
+// snapshot is the pre-change state for the audit diff (only fetched when auditing).
+func (h *UserHandler) snapshot(c *gin.Context, id core.UserID) *models.User {
+	if h.audit == nil {
+		return nil
+	}
+	u, err := h.svc.GetUser(c.Request.Context(), id)
+	if err != nil {
+		return nil
 	}
-	c.JSON(http.StatusOK, models.AuthResponse{Token: tok}) // Return {"token": "..."}.
+	return u
 }
 
 // GetUser handles GET /users/:id (protected).
 func (h *UserHandler) GetUser(c *gin.Context) {
-	id, err := parseUint(c.Param("id")) // Parse :id from URL.
+	id, err := core.ParseUserID(c.Param("id")) // Parse :id from URL.
 	if err != nil { // Invalid ID → 400.
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
 		return
 	}
-	u, err := h.svc.GetUser(id) // Fetch user (cache-aware).
+	u, err := h.svc.GetUser(c.Request.Context(), id) // Fetch user (cache-aware).
 	if err != nil { // Not found → 404.
 		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
 		return
@@ -68,6 +108,69 @@ func (h *UserHandler) GetUser(c *gin.Context) {
 	c.JSON(http.StatusOK, u) // Respond with user JSON.
 }
 
+// Me handles GET /me (protected): the current user, identified by the uid Auth put in the context.
+func (h *UserHandler) Me(c *gin.Context) {
+	uid, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthenticated"})
+		return
+	}
+	u, err := h.svc.GetUser(c.Request.Context(), uid) // Same cache-aware read as GET /users/:id.
+	if err != nil { // Account deleted while the token is still valid.
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+	c.JSON(http.StatusOK, u)
+}
+
+// UpdateMe handles PUT /me (protected): self-service partial update.
+func (h *UserHandler) UpdateMe(c *gin.Context) {
+	uid, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthenticated"})
+		return
+	}
+	var req models.UpdateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Role != nil { // Nobody promotes themselves; roles change via PUT /users/:id (admin).
+		c.JSON(http.StatusForbidden, gin.H{"error": "role cannot be changed via /me"})
+		return
+	}
+	if req.Password != nil { // Must prove the current password; see ChangePassword.
+		c.JSON(http.StatusBadRequest, gin.H{"error": "use POST /me/password to change the password"})
+		return
+	}
+	u, err := h.svc.UpdateUser(h.audited(c, audit.ActionUserUpdate, uid, ""), uid, req)
+	if err != nil {
+		badRequest(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, u)
+}
+
+// DeleteMe handles DELETE /me (protected): the user closes their own account.
+func (h *UserHandler) DeleteMe(c *gin.Context) {
+	uid, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthenticated"})
+		return
+	}
+	if h.deletionApproval {
+		c.JSON(http.StatusForbidden, gin.H{"error": "account deletion needs admin approval; use POST /api/v1/me/deletion-request"})
+		return
+	}
+	before := h.snapshot(c, uid)
+	if err := h.svc.DeleteUser(c.Request.Context(), uid); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+	h.record(c, audit.ActionUserDelete, uid, before, nil)
+	c.Status(http.StatusNoContent)
+}
+
 // CreateUser handles POST /users (protected; typically admin-only).
 func (h *UserHandler) CreateUser(c *gin.Context) {
 	var req models.RegisterRequest // Reuse register DTO (requires password).
@@ -75,17 +178,18 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	u, err := h.svc.CreateUser(req) // Service creates user (hash + uniqueness).
+	u, err := h.svc.CreateUser(c.Request.Context(), req) // Service creates user (hash + uniqueness).
 	if err != nil { // Business error → 400.
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		badRequest(c, err)
 		return
 	}
+	h.record(c, audit.ActionUserCreate, core.UserID(u.ID), nil, u, "password")
 	c.JSON(http.StatusCreated, u) // 201 Created with user JSON.
 }
 
 // UpdateUser handles PUT /users/:id (protected).
 func (h *UserHandler) UpdateUser(c *gin.Context) {
-	id, err := parseUint(c.Param("id")) // Parse :id path param.
+	id, err := core.ParseUserID(c.Param("id")) // Parse :id path param.
 	if err != nil { // Invalid ID → 400.
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
 		return
@@ -95,9 +199,13 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	u, err := h.svc.UpdateUser(id, req) // Update via service (hash if password; refresh cache).
-	if err != nil { // Could be "email exists" or not found.
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var secrets []string
+	if req.Password != nil { // hashes never go in the diff, only the fact it changed
+		secrets = append(secrets, "password")
+	}
+	u, err := h.svc.UpdateUser(h.audited(c, audit.ActionUserUpdate, id, "", secrets...), id, req) // Update via service (hash if password; refresh cache); audited in its transaction.
+	if err != nil { // Could be "email exists", rule violations, or not found.
+		badRequest(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, u) // 200 OK with updated user.
@@ -105,25 +213,151 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 
 // DeleteUser handles DELETE /users/:id (protected).
 func (h *UserHandler) DeleteUser(c *gin.Context) {
-	id, err := parseUint(c.Param("id")) // Parse :id.
+	id, err := core.ParseUserID(c.Param("id")) // Parse :id.
 	if err != nil { // Invalid ID → 400.
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
 		return
 	}
-	if err := h.svc.DeleteUser(id); err != nil { // Service delete (also clears cache).
+	before := h.snapshot(c, id)
+	if err := h.svc.DeleteUser(c.Request.Context(), id); err != nil { // Service delete (also clears cache).
 		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"}) // Simplified mapping to 404.
 		return
 	}
+	h.record(c, audit.ActionUserDelete, id, before, nil)
 	c.Status(http.StatusNoContent) // 204 No Content on success (typical REST delete).
 }
 
-// ListUsers handles GET /users?page=1&limit=10 (protected).
+// DeleteUsers handles DELETE /users with {"ids":[...]} (protected): bulk delete in one transaction.
+func (h *UserHandler) DeleteUsers(c *gin.Context) {
+	var req models.BulkDeleteUsersRequest
+	if err := c.ShouldBindJSON(&req); err != nil { // 1..500 positive IDs.
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ids := make([]core.UserID, len(req.IDs))
+	for i, id := range req.IDs {
+		ids[i] = core.UserID(id)
+	}
+	res, err := h.svc.DeleteUsers(c.Request.Context(), ids)
+	if err != nil { // Transaction failed; nothing was deleted.
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	for i := range res.Users { // Each with the row as it was.
+		h.record(c, audit.ActionUserDelete, core.UserID(res.Users[i].ID), &res.Users[i], nil)
+	}
+	c.JSON(http.StatusOK, res) // 200 with counts (some IDs may not have existed).
+}
+
+// BanUser handles POST /users/:id/ban (protected): blocks login and ends the user's sessions/JWTs.
+func (h *UserHandler) BanUser(c *gin.Context) {
+	h.setStatus(c, models.StatusBanned, audit.ActionUserBan)
+}
+
+// UnbanUser handles POST /users/:id/unban (protected): the account can log in again.
+func (h *UserHandler) UnbanUser(c *gin.Context) {
+	h.setStatus(c, models.StatusActive, audit.ActionUserUnban)
+}
+
+// setStatus is shared by BanUser/UnbanUser; the body ({"reason": "..."}) is optional.
+func (h *UserHandler) setStatus(c *gin.Context, status, action string) {
+	id, err := core.ParseUserID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	var req models.UserStatusRequest
+	if c.Request.ContentLength != 0 { // No body is fine.
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if actor, _ := currentUserID(c); actor == id && status != models.StatusActive {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cannot ban yourself"}) // would lock the admin out
+		return
+	}
+	u, err := h.svc.SetStatus(h.audited(c, action, id, req.Reason), id, status, req.Reason)
+	if errors.Is(err, services.ErrUserNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+	if err != nil { // DB trouble: the status is unchanged, not the user missing.
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, u)
+}
+
+// FlushUser handles POST /admin/users/:id/flush (protected): clears the user's cached copy,
+// sessions, JWTs and pending 2FA enrollment, plus the rate-limit buckets of any IPs in the
+// optional body ({"ips": [...], "reason": "..."}). Audited; answers what was cleared.
+func (h *UserHandler) FlushUser(c *gin.Context) {
+	id, err := core.ParseUserID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	var req models.FlushUserRequest
+	if c.Request.ContentLength != 0 { // No body is fine.
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if len(req.IPs) > 0 && h.resetRateLimits == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "rate limiting is not enabled"})
+		return
+	}
+	flushed, err := h.svc.FlushUser(c.Request.Context(), id)
+	if err != nil && flushed == nil { // Lookup failed; simplified mapping to 404, like DeleteUser.
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+	if err == nil && len(req.IPs) > 0 {
+		for _, ip := range req.IPs {
+			if err = h.resetRateLimits(c.Request.Context(), ip); err != nil {
+				break
+			}
+		}
+		if err == nil {
+			flushed = append(flushed, "rate_limits")
+		}
+	}
+	res := models.FlushUserResult{UserID: uint(id), Flushed: flushed}
+	if h.audit != nil && len(flushed) > 0 { // Partial flushes are recorded too.
+		actor, _ := currentUserID(c)
+		h.audit.Record(audit.Event{
+			ActorID: uint(actor), Action: audit.ActionUserFlush, TargetType: "user", TargetID: uint(id),
+			After: res, IP: c.ClientIP(), Reason: req.Reason,
+		})
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "flush incomplete: " + err.Error(), "flushed": flushed})
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// ListUsers handles GET /users?page=1&limit=10&q=&email=&created_after=&created_before=&sort=&order= (protected);
+// ?cursor= instead of ?page= selects keyset pagination (see models.ListUserQuery).
 func (h *UserHandler) ListUsers(c *gin.Context) {
-	// Parse query params with defaults; we let the service clamp them too.
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1")) // Default page=1.
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10")) // Default limit=10.
+	q := models.ListUserQuery{Page: 1, Limit: 10} // Defaults; the service clamps them too.
+	if err := normalizeTimeQuery(c, "created_after", "created_before"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := c.ShouldBindQuery(&q); err != nil { // Bad page/limit, email or RFC 3339 timestamp → 400.
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-	paged, err := h.svc.ListUsers(page, limit) // Get page via service (items + total + page + limit).
+	paged, err := h.svc.ListUsers(c.Request.Context(), q) // Get page via service (items + total + page + limit).
+	var v core.Violations
+	if errors.As(err, &v) { // Unknown sort column/order, bad cursor → 400.
+		badRequest(c, err)
+		return
+	}
 	if err != nil { // Internal error → 500.
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -131,6 +365,44 @@ func (h *UserHandler) ListUsers(c *gin.Context) {
 	c.JSON(http.StatusOK, paged) // 200 OK with envelope.
 }
 
+// normalizeTimeQuery rewrites the given query parameters to RFC 3339 UTC before binding, so
+// any core.ParseTimestamp format (a bare date, Unix seconds, ...) is accepted.
+func normalizeTimeQuery(c *gin.Context, keys ...string) error {
+	query := c.Request.URL.Query()
+	for _, key := range keys {
+		if v := query.Get(key); v != "" {
+			t, err := core.ParseTimestamp(v)
+			if err != nil {
+				return fmt.Errorf("invalid %s: %w", key, err)
+			}
+			query.Set(key, t.Format(time.RFC3339Nano)) // keeps sub-second precision, unlike FormatTimestamp
+		}
+	}
+	c.Request.URL.RawQuery = query.Encode()
+	return nil
+}
+
+// badRequest writes a 400; domain rule violations are listed field by field so clients can
+// highlight the offending inputs.
+func badRequest(c *gin.Context, err error) {
+	var v core.Violations
+	if errors.As(err, &v) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation failed", "violations": v})
+		return
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+}
+
+// currentUserID reads the authenticated user ID that Auth stored in the context.
+func currentUserID(c *gin.Context) (core.UserID, bool) {
+	v, ok := c.Get(global.CtxUserIDKey)
+	if !ok {
+		return 0, false
+	}
+	id, ok := v.(uint)
+	return core.UserID(id), ok && id != 0
+}
+
 // parseUint safely converts a numeric string to uint.
 func parseUint(s string) (uint, error) {
 	id64, err := strconv.ParseUint(s, 10, 0) // Parse base-10 as unsigned.
@@ -2,24 +2,31 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
 	
+	"HelmyTask/accounts"
+	"HelmyTask/audit"
+	"HelmyTask/core"
+	"HelmyTask/global"
 	"HelmyTask/mocks"
 	"HelmyTask/models"
+	"HelmyTask/services"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
-func setup(r *gin.Engine, svc *mocks.UserServiceMock) {
-	h := NewUserHandler(svc, "test-secret", time.Minute)
-	r.POST("/auth/register", h.Register)
-	r.POST("/auth/login", h.Login)
+func setup(r *gin.Engine, svc *mocks.UserAdminServiceMock) {
+	h := NewUserHandler(svc)
 	r.GET("/users/:id", h.GetUser)
 	r.POST("/users", h.CreateUser)
 	r.PUT("/users/:id", h.UpdateUser)
@@ -27,56 +34,389 @@ func setup(r *gin.Engine, svc *mocks.UserServiceMock) {
 	r.GET("/users", h.ListUsers)
 }
 
-func TestRegister_Success(t *testing.T) {
+func TestGetUser_NotFound(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	r := gin.New()
-	svc := new(mocks.UserServiceMock)
+	svc := new(mocks.UserAdminServiceMock)
 	setup(r, svc)
 
-	req := models.RegisterRequest{Name: "ahmed", Email: "a@b.c", Password: "123456"}
-	resp := &models.User{ID: 1, Name: "Ahmed", Email: "a@b.c"}
-	svc.On("Register", req).Return(resp, nil)
+	svc.On("GetUser", core.UserID(99)).Return(nil, assert.AnError)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/users/99", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestMe_UsesAuthenticatedUID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	svc := new(mocks.UserAdminServiceMock)
+	h := NewUserHandler(svc)
+	// stand-in for Auth: uid 7 is logged in
+	r.Use(func(c *gin.Context) { c.Set(global.CtxUserIDKey, uint(7)); c.Next() })
+	r.GET("/me", h.Me)
+	r.PUT("/me", h.UpdateMe)
+
+	svc.On("GetUser", core.UserID(7)).Return(&models.User{ID: 7, Email: "me@x.io"}, nil)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/me", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"id":7`)
+
+	// self-promotion is refused before reaching the service
+	w = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, "/me", bytes.NewReader([]byte(`{"role":"admin"}`)))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	svc.AssertNotCalled(t, "UpdateUser", mock.Anything, mock.Anything)
+}
+
+// auditedService records the audit event the handler hands to the service (which writes it in
+// the change's transaction).
+type auditedService struct {
+	*mocks.UserAdminServiceMock
+	pending audit.Event
+}
+
+func (s *auditedService) UpdateUser(ctx context.Context, id core.UserID, req models.UpdateUserRequest) (*models.User, error) {
+	s.pending, _ = audit.Pending(ctx)
+	return s.UserAdminServiceMock.UpdateUser(ctx, id, req)
+}
+
+func (s *auditedService) SetStatus(ctx context.Context, id core.UserID, status, reason string) (*models.User, error) {
+	s.pending, _ = audit.Pending(ctx)
+	return s.UserAdminServiceMock.SetStatus(ctx, id, status, reason)
+}
+
+func TestUpdateUser_Audited(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	svc := &auditedService{UserAdminServiceMock: new(mocks.UserAdminServiceMock)}
+	repo := new(mocks.AuditRepositoryMock)
+	h := NewUserHandler(svc, WithAudit(audit.New(repo, nil)))
+	r.Use(func(c *gin.Context) { c.Set(global.CtxUserIDKey, uint(1)); c.Next() }) // admin uid 1
+	r.PUT("/users/:id", h.UpdateUser)
+
+	role, pw := "admin", "N3w-Passw0rd!"
+	req := models.UpdateUserRequest{Role: &role, Password: &pw}
+	svc.On("UpdateUser", core.UserID(5), req).Return(&models.User{ID: 5, Name: "Sara", Role: "admin"}, nil)
 
 	b, _ := json.Marshal(req)
 	w := httptest.NewRecorder()
-	httpReq := httptest.NewRequest(http.MethodPost, "/auth/register", bytes.NewReader(b))
+	httpReq := httptest.NewRequest(http.MethodPut, "/users/5", bytes.NewReader(b))
 	httpReq.Header.Set("Content-Type", "application/json")
 	r.ServeHTTP(w, httpReq)
 
-	assert.Equal(t, http.StatusCreated, w.Code)
-	assert.Contains(t, w.Body.String(), `"id":1`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, audit.Event{ActorID: 1, Action: audit.ActionUserUpdate, TargetType: "user", TargetID: 5, Secrets: []string{"password"}, IP: "192.0.2.1"}, svc.pending)
+	repo.AssertNotCalled(t, "Create", mock.Anything) // the service writes it, in its transaction
 }
 
-func TestLogin_Unauthorized(t *testing.T) {
+func TestDeleteUsers_AuditsEachWithBefore(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	r := gin.New()
-	svc := new(mocks.UserServiceMock)
-	setup(r, svc)
+	svc := new(mocks.UserAdminServiceMock)
+	repo := new(mocks.AuditRepositoryMock)
+	h := NewUserHandler(svc, WithAudit(audit.New(repo, nil)))
+	r.Use(func(c *gin.Context) { c.Set(global.CtxUserIDKey, uint(1)); c.Next() })
+	r.DELETE("/users", h.DeleteUsers)
 
-	body := models.LoginRequest{Email: "x@y.z", Password: "oops"}
-	svc.On("Login", body, "test-secret", time.Minute).Return("", assert.AnError)
+	svc.On("DeleteUsers", []core.UserID{4, 9}).Return(&models.BulkDeleteUsersResult{
+		Deleted: 1, DeletedIDs: []uint{4}, NotFound: []uint{9},
+		Users: []models.User{{ID: 4, Name: "Sara", Email: "sara@example.com"}},
+	}, nil)
+	var logged []models.AuditLog
+	repo.On("Create", mock.Anything).Run(func(args mock.Arguments) { logged = append(logged, *args.Get(0).(*models.AuditLog)) }).Return(nil)
 
-	b, _ := json.Marshal(body)
 	w := httptest.NewRecorder()
-	req := httptest.NewRequest(http.MethodPost, "/auth/login", bytes.NewReader(b))
+	req := httptest.NewRequest(http.MethodDelete, "/users", bytes.NewBufferString(`{"ids":[4,9]}`))
 	req.Header.Set("Content-Type", "application/json")
 	r.ServeHTTP(w, req)
 
-	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "sara@example.com") // the snapshots are for the log only
+	if assert.Len(t, logged, 1) {
+		assert.Equal(t, uint(4), logged[0].TargetID)
+		assert.Contains(t, logged[0].Diff, "Sara") // the row as it was, not an empty before
+	}
 }
 
-func TestGetUser_NotFound(t *testing.T) {
+func TestListUsers_Filters(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	r := gin.New()
-	svc := new(mocks.UserServiceMock)
+	svc := new(mocks.UserAdminServiceMock)
 	setup(r, svc)
 
-	svc.On("GetUser", uint(99)).Return(nil, assert.AnError)
+	after := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	svc.On("ListUsers", mock.MatchedBy(func(q models.ListUserQuery) bool {
+		return q.Page == 1 && q.Limit == 10 && q.Q == "helmy" && q.CreatedAfter != nil && q.CreatedAfter.Equal(after) && q.CreatedBefore == nil
+	})).Return(&models.PagedUsers{Total: 0, Page: 1, Limit: 10}, nil)
 
 	w := httptest.NewRecorder()
-	req := httptest.NewRequest(http.MethodGet, "/users/99", nil)
-	r.ServeHTTP(w, req)
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users?q=helmy&created_after=2024-05-01T00:00:00Z", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// an empty ?cursor= starts keyset pagination
+	svc.On("ListUsers", mock.MatchedBy(func(q models.ListUserQuery) bool { return q.Cursor != nil && *q.Cursor == "" })).
+		Return(&models.PagedUsers{Limit: 10, NextCursor: "10"}, nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users?cursor=", nil))
+	assert.Contains(t, w.Body.String(), `"next_cursor":"10"`)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users?created_before=yesterday", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	svc.AssertNumberOfCalls(t, "ListUsers", 2)
+}
+
+func TestExportUsers_CSV(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	svc := new(mocks.UserAdminServiceMock)
+	h := NewUserHandler(svc)
+	r.GET("/users/export", h.ExportUsers)
+
+	batches := [][]models.User{
+		{{ID: 1, Name: "Ahmed", Email: "a@b.c"}, {ID: 2, Name: "=HYPERLINK(\"x\")", Email: "x@y.z"}},
+		{{ID: 3, Name: "Sara, M.", Email: "s@b.c"}},
+	}
+	svc.On("ExportUsers", mock.MatchedBy(func(q models.ListUserQuery) bool { return q.Q == "b.c" }), mock.Anything).
+		Return(batches, nil).Once()
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/export?format=csv&columns=id,+Name,email&q=b.c", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Regexp(t, `^attachment; filename="users-\d{8}T\d{6}Z\.csv"$`, w.Header().Get("Content-Disposition"))
+	assert.Equal(t, "id,name,email\n1,Ahmed,a@b.c\n2,\"'=HYPERLINK(\"\"x\"\")\",x@y.z\n3,\"Sara, M.\",s@b.c\n", w.Body.String())
+
+	// unknown columns / formats are rejected before anything is streamed
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/export?format=xlsx&columns=id,password", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"format_invalid"`)
+	assert.Contains(t, w.Body.String(), `"code":"column_invalid"`)
+	svc.AssertNumberOfCalls(t, "ExportUsers", 1)
+}
+
+func TestExportUsers_TimeZone(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	svc := new(mocks.UserAdminServiceMock)
+	h := NewUserHandler(svc)
+	r.GET("/users/export", h.ExportUsers)
+
+	created := time.Date(2026, 10, 16, 9, 30, 0, 0, time.FixedZone("driver", 2*3600)) // 07:30 UTC
+	batches := [][]models.User{{
+		{ID: 1, Timezone: "Africa/Cairo", CreatedAt: created},
+		{ID: 2, Timezone: "Not/AZone", CreatedAt: created},
+		{ID: 3, CreatedAt: created},
+	}}
+	svc.On("ExportUsers", mock.Anything, mock.Anything).Return(batches, nil).Twice()
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/export?columns=id,created_at", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "id,created_at\n1,2026-10-16T07:30:00Z\n2,2026-10-16T07:30:00Z\n3,2026-10-16T07:30:00Z\n", w.Body.String())
 
+	// each row in its user's zone; unknown or unset zones fall back to UTC
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/export?columns=id,created_at&tz=user", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "id,created_at\n1,2026-10-16T10:30:00+03:00\n2,2026-10-16T07:30:00Z\n3,2026-10-16T07:30:00Z\n", w.Body.String())
+
+	for _, tz := range []string{"Mars/Olympus", "Local"} {
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/export?tz="+tz, nil))
+		assert.Equal(t, http.StatusBadRequest, w.Code, tz)
+		assert.Contains(t, w.Body.String(), `"field":"tz"`, tz)
+	}
+	svc.AssertNumberOfCalls(t, "ExportUsers", 2)
+}
+
+func TestImportUsers_CSV(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	svc := new(mocks.UserAdminServiceMock)
+	h := NewUserHandler(svc)
+	r.POST("/users/import", h.ImportUsers)
+
+	upload := func(filename, content string) *http.Request {
+		var buf bytes.Buffer
+		mw := multipart.NewWriter(&buf)
+		fw, _ := mw.CreateFormFile("file", filename)
+		_, _ = fw.Write([]byte(content))
+		_ = mw.Close()
+		req := httptest.NewRequest(http.MethodPost, "/users/import", &buf)
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+		return req
+	}
+
+	rows := []models.RegisterRequest{{Name: "Sara", Email: "s@b.c", Password: " pass word "}, {Name: "Omar", Email: "o@b.c", Password: "123456"}}
+	svc.On("ImportUsers", rows).Return(&models.ImportUsersResult{Total: 2, Created: 1,
+		Errors: []models.ImportRowError{{Row: 2, Email: "o@b.c", Error: "email already exists"}}}, nil).Once()
+
+	// columns in any order, extras ignored, BOM tolerated
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, upload("users.csv", "\ufeffEmail,name,password,team\ns@b.c, Sara , pass word ,x\no@b.c,Omar,123456,y\n"))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"errors":[{"row":2,"email":"o@b.c","error":"email already exists"}]`)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, upload("users.csv", "name,email\nSara,s@b.c\n"))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `missing column \"password\"`)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, upload("users.xlsx", "..."))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	svc.AssertNumberOfCalls(t, "ImportUsers", 1)
+}
+
+func TestUpdateUser_ProfileFieldsValidatedAtBind(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	svc := new(mocks.UserAdminServiceMock)
+	setup(r, svc)
+
+	put := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPut, "/users/5", bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
+	}
+	for _, body := range []string{`{"phone":"0100 123"}`, `{"timezone":"Mars/Olympus"}`, `{"locale":"not a tag"}`, `{"date_of_birth":"01/05/1990"}`} {
+		assert.Equal(t, http.StatusBadRequest, put(body).Code, body)
+	}
+	svc.AssertNotCalled(t, "UpdateUser", mock.Anything, mock.Anything)
+
+	// valid values, and "" to clear one
+	svc.On("UpdateUser", core.UserID(5), mock.MatchedBy(func(req models.UpdateUserRequest) bool {
+		return *req.Phone == "+201001234567" && *req.Timezone == "Africa/Cairo" && *req.Locale == "ar-EG" && *req.Bio == ""
+	})).Return(&models.User{ID: 5, Phone: "+201001234567", Timezone: "Africa/Cairo", Locale: "ar-EG"}, nil).Once()
+	w := put(`{"phone":"+201001234567","timezone":"Africa/Cairo","locale":"ar-EG","bio":""}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"timezone":"Africa/Cairo"`)
+}
+
+func TestBanUser_AuditedWithReason(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	svc := &auditedService{UserAdminServiceMock: new(mocks.UserAdminServiceMock)}
+	repo := new(mocks.AuditRepositoryMock)
+	h := NewUserHandler(svc, WithAudit(audit.New(repo, nil)))
+	r.Use(func(c *gin.Context) { c.Set(global.CtxUserIDKey, uint(1)); c.Next() }) // admin uid 1
+	r.POST("/users/:id/ban", h.BanUser)
+
+	svc.On("SetStatus", core.UserID(5), models.StatusBanned, "spam").Return(&models.User{ID: 5, Status: models.StatusBanned}, nil)
+
+	w := httptest.NewRecorder()
+	httpReq := httptest.NewRequest(http.MethodPost, "/users/5/ban", bytes.NewReader([]byte(`{"reason":"spam"}`)))
+	httpReq.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, httpReq)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, audit.Event{ActorID: 1, Action: audit.ActionUserBan, TargetType: "user", TargetID: 5, IP: "192.0.2.1", Reason: "spam"}, svc.pending)
+	repo.AssertNotCalled(t, "Create", mock.Anything)
+
+	// banning yourself would lock you out
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/users/1/ban", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	svc.AssertNotCalled(t, "SetStatus", core.UserID(1), mock.Anything, mock.Anything)
+
+	// only a missing user is a 404; anything else failed on our side
+	svc.On("SetStatus", core.UserID(6), models.StatusBanned, "").Return(nil, services.ErrUserNotFound)
+	svc.On("SetStatus", core.UserID(7), models.StatusBanned, "").Return(nil, errors.New("connection reset"))
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/users/6/ban", nil))
 	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/users/7/ban", nil))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
 }
 
+func TestAccounts_ExportThenImport(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	svc := new(mocks.UserAdminServiceMock)
+	h := NewUserHandler(svc)
+	r.POST("/admin/accounts/export", h.ExportAccounts)
+	r.POST("/admin/accounts/import", h.ImportAccounts)
+	const pass = "correct horse battery"
+
+	accts := []accounts.Account{{Email: "a@b.c", Name: "Sara", PasswordHash: "$2a$10$x", Role: models.RoleUser}}
+	svc.On("ExportAccounts", []core.UserID{1, 2}).Return(accts, []uint{2}, nil).Once()
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/accounts/export", bytes.NewBufferString(`{"ids":[1,2],"passphrase":"`+pass+`"}`)))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "2", w.Header().Get("X-Export-Missing"))
+	assert.Contains(t, w.Header().Get("Content-Disposition"), "attachment")
+	bundle := w.Body.Bytes()
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/accounts/export", bytes.NewBufferString(`{"ids":[1],"passphrase":"short"}`)))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	upload := func(passphrase, overwrite string) *http.Request {
+		var buf bytes.Buffer
+		mw := multipart.NewWriter(&buf)
+		fw, _ := mw.CreateFormFile("bundle", "accounts.bundle")
+		_, _ = fw.Write(bundle)
+		_ = mw.WriteField("passphrase", passphrase)
+		if overwrite != "" {
+			_ = mw.WriteField("overwrite", overwrite)
+		}
+		_ = mw.Close()
+		req := httptest.NewRequest(http.MethodPost, "/admin/accounts/import", &buf)
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+		return req
+	}
+
+	svc.On("ImportAccounts", accts, true).Return(&models.ImportAccountsResult{Total: 1, Updated: 1, Errors: []models.ImportRowError{}}, nil).Once()
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, upload(pass, "true"))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"updated":1`)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, upload("not the passphrase", ""))
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, upload(pass, "maybe"))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	svc.AssertExpectations(t)
+}
+
+func TestFlushUser_ResetsRateLimitsAndAudits(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	svc := new(mocks.UserAdminServiceMock)
+	repo := new(mocks.AuditRepositoryMock)
+	var reset []string
+	h := NewUserHandler(svc, WithAudit(audit.New(repo, nil)),
+		WithRateLimitReset(func(_ context.Context, ip string) error { reset = append(reset, ip); return nil }))
+	r.Use(func(c *gin.Context) { c.Set(global.CtxUserIDKey, uint(1)); c.Next() })
+	r.POST("/admin/users/:id/flush", h.FlushUser)
+
+	svc.On("FlushUser", core.UserID(5)).Return([]string{"cache", "tokens", "sessions"}, nil)
+	repo.On("Create", mock.MatchedBy(func(e *models.AuditLog) bool {
+		return e.ActorID == 1 && e.Action == audit.ActionUserFlush && e.TargetID == 5 && e.Reason == "stuck login"
+	})).Return(nil).Once()
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/admin/users/5/flush", bytes.NewBufferString(`{"ips":["1.2.3.4"],"reason":"stuck login"}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"1.2.3.4"}, reset)
+	assert.JSONEq(t, `{"user_id":5,"flushed":["cache","tokens","sessions","rate_limits"]}`, w.Body.String())
+	repo.AssertExpectations(t)
+}
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"HelmyTask/audit"
+	"HelmyTask/core"
+	"HelmyTask/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Import limits: one request must stay well inside a normal request timeout (bcrypt per row).
+const (
+	maxImportBytes = 5 << 20
+	maxImportRows  = 1000
+)
+
+// ImportUsers handles POST /users/import (protected): multipart field "file" holding a CSV
+// (header row with name,email,password in any order) or a JSON array of
+// {"name","email","password"}. Valid rows are created; the response lists every skipped row.
+func (h *UserHandler) ImportUsers(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportBytes)
+	fh, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "multipart field \"file\" is required (max 5 MB)"})
+		return
+	}
+	f, err := fh.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer f.Close()
+
+	var rows []models.RegisterRequest
+	switch importFormat(fh.Filename, fh.Header.Get("Content-Type")) {
+	case "csv":
+		rows, err = parseImportCSV(f)
+	case "json":
+		err = json.NewDecoder(f).Decode(&rows)
+	default:
+		err = errors.New("unsupported file type; upload .csv or .json")
+	}
+	if err == nil && len(rows) == 0 {
+		err = errors.New("file has no rows")
+	}
+	if err == nil && len(rows) > maxImportRows {
+		err = fmt.Errorf("too many rows; the limit is %d per file", maxImportRows)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	res, err := h.svc.ImportUsers(c.Request.Context(), rows)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	for i := range res.Users {
+		u := res.Users[i]
+		h.record(c, audit.ActionUserCreate, core.UserID(u.ID), nil, &u, "password")
+	}
+	c.JSON(http.StatusOK, res) // 200 even with failed rows; the report says which.
+}
+
+// importFormat picks the parser from the file extension, falling back to the part's content type.
+func importFormat(name, contentType string) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv":
+		return "csv"
+	case ".json":
+		return "json"
+	}
+	switch {
+	case strings.HasPrefix(contentType, "text/csv"):
+		return "csv"
+	case strings.HasPrefix(contentType, "application/json"):
+		return "json"
+	}
+	return ""
+}
+
+// parseImportCSV reads a CSV with a header row; columns are matched by name, extra ones ignored.
+func parseImportCSV(r io.Reader) ([]models.RegisterRequest, error) {
+	cr := csv.NewReader(r)
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("csv header: %w", err)
+	}
+	idx := map[string]int{}
+	for i, name := range header {
+		idx[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i // Excel adds a BOM
+	}
+	for _, want := range []string{"name", "email", "password"} {
+		if _, ok := idx[want]; !ok {
+			return nil, fmt.Errorf("csv header: missing column %q", want)
+		}
+	}
+
+	var rows []models.RegisterRequest
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("csv: %w", err) // includes the line number
+		}
+		if len(rows) == maxImportRows { // stop reading; the caller reports the limit
+			return append(rows, models.RegisterRequest{}), nil
+		}
+		rows = append(rows, models.RegisterRequest{
+			Name:     strings.TrimSpace(rec[idx["name"]]),
+			Email:    strings.TrimSpace(rec[idx["email"]]),
+			Password: rec[idx["password"]], // never trimmed: spaces may be part of it
+		})
+	}
+}
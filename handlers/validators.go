@@ -0,0 +1,30 @@
+package handlers // Custom Gin binding tags.
+
+import (
+	"sync/atomic"
+
+	"HelmyTask/core"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// passwordPolicy backs the "password" binding tag. The validator caches the tag's function per
+// struct on first use, so the function stays fixed and only the policy it reads is swapped.
+var passwordPolicy atomic.Pointer[core.PasswordPolicy]
+
+// init installs the tag with the default policy so DTOs tagged `binding:"password"` always bind.
+func init() {
+	SetPasswordPolicy(core.DefaultPasswordPolicy())
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		_ = v.RegisterValidation("password", func(fl validator.FieldLevel) bool {
+			return passwordPolicy.Load().Validate(fl.Field().String()).Err() == nil
+		})
+	}
+}
+
+// SetPasswordPolicy changes the rules the "password" binding tag enforces. The service layer
+// enforces the same policy, so non-HTTP callers get identical rules.
+func SetPasswordPolicy(p core.PasswordPolicy) {
+	passwordPolicy.Store(&p)
+}
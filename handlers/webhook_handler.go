@@ -0,0 +1,141 @@
+package handlers // Admin endpoints for webhook targets.
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"HelmyTask/core"
+	"HelmyTask/models"
+	"HelmyTask/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WebhookHandler exposes /admin/webhooks.
+type WebhookHandler struct {
+	svc services.WebhookService
+}
+
+// NewWebhookHandler constructs the handler.
+func NewWebhookHandler(svc services.WebhookService) *WebhookHandler {
+	return &WebhookHandler{svc: svc}
+}
+
+// Create handles POST /admin/webhooks.
+func (h *WebhookHandler) Create(c *gin.Context) {
+	uid, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthenticated"})
+		return
+	}
+	var req models.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	w, err := h.svc.Create(uid, req)
+	if errors.Is(err, services.ErrUnsafeWebhookURL) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, w)
+}
+
+// List handles GET /admin/webhooks.
+func (h *WebhookHandler) List(c *gin.Context) {
+	items, err := h.svc.List()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": items})
+}
+
+// Test handles POST /admin/webhooks/:id/test: sends a signed sample event and echoes the
+// exact request (headers + body) so integrators can check their signature verification.
+func (h *WebhookHandler) Test(c *gin.Context) {
+	id, err := parseUint(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	res, err := h.svc.Test(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "webhook not found"})
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// ListDeliveries handles GET /admin/webhook-deliveries?status=failed&webhook_id=&from=&to=&page=&limit=
+// (from/to are RFC 3339 timestamps).
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	var f models.WebhookDeliveryFilter
+	f.Status = c.Query("status")
+	if v := c.Query("webhook_id"); v != "" {
+		id, err := parseUint(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid webhook_id"})
+			return
+		}
+		f.WebhookID = id
+	}
+	for key, dst := range map[string]**time.Time{"from": &f.From, "to": &f.To} {
+		if v := c.Query(key); v != "" {
+			t, err := core.ParseTimestamp(v) // RFC 3339 preferred; dates and Unix seconds work too
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + key + " (want RFC 3339)"})
+				return
+			}
+			*dst = &t
+		}
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	paged, err := h.svc.ListDeliveries(f, page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, paged)
+}
+
+// Replay handles POST /admin/webhook-deliveries/replay with {"ids":[...]} or {"from","to","webhook_id"}.
+func (h *WebhookHandler) Replay(c *gin.Context) {
+	var req models.ReplayWebhooksRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	res, err := h.svc.Replay(req)
+	if errors.Is(err, services.ErrReplaySelection) || errors.Is(err, services.ErrReplayTooMany) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// Delete handles DELETE /admin/webhooks/:id.
+func (h *WebhookHandler) Delete(c *gin.Context) {
+	id, err := parseUint(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	if err := h.svc.Delete(id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "webhook not found"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
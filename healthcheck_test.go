@@ -0,0 +1,33 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProbe(t *testing.T) {
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(status) }))
+	defer srv.Close()
+
+	assert.NoError(t, probe(srv.URL, time.Second, false))
+	assert.Equal(t, 0, runHealthcheck([]string{"-url", srv.URL}))
+
+	status = http.StatusServiceUnavailable
+	assert.ErrorContains(t, probe(srv.URL, time.Second, false), "503")
+	assert.Equal(t, 1, runHealthcheck([]string{"-url", srv.URL}))
+
+	assert.Equal(t, 2, runHealthcheck([]string{"-bogus"}))
+}
+
+func TestProbe_LoopbackTLSSkipsVerification(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) }))
+	defer srv.Close()
+
+	assert.Error(t, probe(srv.URL, time.Second, false), "self-signed")
+	assert.NoError(t, probe(srv.URL, time.Second, true))
+}
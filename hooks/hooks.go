@@ -0,0 +1,59 @@
+// Package hooks lets deployments plug custom behaviour into the user lifecycle (CRM sync,
+// welcome flows, extra logging...) without patching the user service.
+//
+// A fork implements UserLifecycle (embedding Base to skip events it doesn't care about) and
+// registers it at bootstrap, typically from an init function:
+//
+//	func init() { hooks.Register(myHooks{}) }
+//
+// main.go hands Registered() to the user service, which calls every hook after the change is
+// committed. Hooks run synchronously on the request path, so slow work belongs in a goroutine
+// or queue; a panicking hook is recovered and logged, never failing the request.
+package hooks
+
+import (
+	"context"
+	"sync"
+
+	"HelmyTask/models"
+)
+
+// UserLifecycle receives user events after they happen. Users are passed by value so a hook
+// can't modify the record the service goes on to return.
+type UserLifecycle interface {
+	OnRegistered(ctx context.Context, u models.User) // Account created (register or admin create).
+	OnUpdated(ctx context.Context, u models.User)    // Profile, role or password changed; u is the new state.
+	OnDeleted(ctx context.Context, id uint)          // Account removed.
+	OnLogin(ctx context.Context, u models.User)      // Credentials (and 2FA, if on) accepted.
+}
+
+// Base implements every event as a no-op; embed it and override what you need.
+type Base struct{}
+
+func (Base) OnRegistered(context.Context, models.User) {}
+func (Base) OnUpdated(context.Context, models.User)    {}
+func (Base) OnDeleted(context.Context, uint)           {}
+func (Base) OnLogin(context.Context, models.User)      {}
+
+var (
+	mu         sync.Mutex
+	registered []UserLifecycle
+)
+
+// Register adds h to the hooks handed to the user service at bootstrap.
+// Hooks registered after the service is built are not picked up.
+func Register(h UserLifecycle) {
+	if h == nil {
+		return
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	registered = append(registered, h)
+}
+
+// Registered returns the hooks registered so far, in registration order.
+func Registered() []UserLifecycle {
+	mu.Lock()
+	defer mu.Unlock()
+	return append([]UserLifecycle(nil), registered...)
+}
@@ -0,0 +1,26 @@
+package hooks
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type named struct {
+	Base
+	name string
+}
+
+func TestRegister(t *testing.T) {
+	t.Cleanup(func() { registered = nil })
+
+	Register(named{name: "a"})
+	Register(nil) // ignored
+	Register(named{name: "b"})
+
+	got := Registered()
+	assert.Equal(t, []UserLifecycle{named{name: "a"}, named{name: "b"}}, got)
+
+	got[0] = nil // callers get a copy
+	assert.NotNil(t, Registered()[0])
+}
@@ -0,0 +1,354 @@
+// Package jobs is a small Redis-backed background job queue: request handlers enqueue slow side
+// effects (cache warming, welcome emails, webhook dispatch) and workers on every replica run them.
+//
+// Keys, under a prefix such as "jobs:":
+//
+//	queue       list of ready jobs (LPUSH in, BLMOVE out: FIFO)
+//	processing  list of jobs a worker has claimed
+//	leases      sorted set: claimed job → deadline; a job still claimed past it (its worker died)
+//	            goes back to the queue
+//	scheduled   sorted set: job → run-at, for retries after a backoff
+//	dead        list of jobs that used up their attempts, failed permanently, or had no handler
+//
+// Delivery is at least once: a handler can run twice for the same job (e.g. it outlived its
+// lease), so handlers must be idempotent.
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"HelmyTask/utils/redisscript"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// promoteScript moves due retries, and claimed jobs whose lease ran out, back to the queue.
+// KEYS: scheduled, queue, leases, processing. ARGV: now (unix ms), batch size.
+var promoteScript = redisscript.Register("jobs.promote", `
+local moved = 0
+for _, raw in ipairs(redis.call("ZRANGEBYSCORE", KEYS[1], "-inf", ARGV[1], "LIMIT", 0, ARGV[2])) do
+	redis.call("ZREM", KEYS[1], raw)
+	redis.call("LPUSH", KEYS[2], raw)
+	moved = moved + 1
+end
+for _, raw in ipairs(redis.call("ZRANGEBYSCORE", KEYS[3], "-inf", ARGV[1], "LIMIT", 0, ARGV[2])) do
+	redis.call("ZREM", KEYS[3], raw)
+	if redis.call("LREM", KEYS[4], 1, raw) == 1 then
+		redis.call("LPUSH", KEYS[2], raw)
+		moved = moved + 1
+	end
+end
+return moved`)
+
+// requeueScript moves one dead job (ARGV[1]) back to the queue as ARGV[2], unless it is gone.
+// KEYS: dead, queue.
+var requeueScript = redisscript.Register("jobs.requeue", `
+if redis.call("LREM", KEYS[1], 1, ARGV[1]) == 1 then
+	redis.call("LPUSH", KEYS[2], ARGV[2])
+	return 1
+end
+return 0`)
+
+// ErrNoHandler is recorded on jobs whose type nothing handles (they go straight to the dead list).
+var ErrNoHandler = errors.New("jobs: no handler for job type")
+
+// Job is one unit of work as stored in Redis.
+type Job struct {
+	ID          string          `json:"id"`
+	Type        string          `json:"type"` // e.g. "email.welcome"
+	Payload     json.RawMessage `json:"payload"`
+	Attempts    int             `json:"attempts"` // runs so far
+	MaxAttempts int             `json:"max_attempts"`
+	EnqueuedAt  time.Time       `json:"enqueued_at"`
+	LastError   string          `json:"last_error,omitempty"`
+	FailedAt    *time.Time      `json:"failed_at,omitempty"` // set when dead-lettered
+}
+
+// Handler runs one job. A returned error is retried with backoff unless wrapped by Permanent.
+type Handler func(ctx context.Context, payload json.RawMessage) error
+
+// Enqueuer is what producers need (services take this, not the whole Queue).
+type Enqueuer interface {
+	Enqueue(ctx context.Context, typ string, payload any) error
+}
+
+type permanentError struct{ err error }
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// Permanent marks err as not worth retrying (bad payload, record gone): the job is dead-lettered.
+func Permanent(err error) error { return permanentError{err} }
+
+// Stats counts jobs per state.
+type Stats struct {
+	Queued     int64 `json:"queued"`
+	Processing int64 `json:"processing"`
+	Scheduled  int64 `json:"scheduled"` // waiting for a retry
+	Dead       int64 `json:"dead"`
+}
+
+// Queue enqueues jobs and, with Run, works them.
+type Queue struct {
+	rdb    *redis.Client
+	prefix string
+
+	concurrency int
+	maxAttempts int
+	deadMax     int64
+	timeout     time.Duration // per run; the lease is twice this
+	backoff     func(attempts int) time.Duration
+	now         func() time.Time
+
+	mu       sync.RWMutex
+	handlers map[string]Handler
+}
+
+// Option tweaks a Queue.
+type Option func(*Queue)
+
+// WithConcurrency sets how many jobs this replica runs at once (default 4).
+func WithConcurrency(n int) Option { return func(q *Queue) { q.concurrency = n } }
+
+// WithMaxAttempts sets how many runs a job gets before it is dead-lettered (default 5).
+func WithMaxAttempts(n int) Option { return func(q *Queue) { q.maxAttempts = n } }
+
+// WithDeadLetterMax caps the dead list, oldest dropped first (default 1000).
+func WithDeadLetterMax(n int64) Option { return func(q *Queue) { q.deadMax = n } }
+
+// WithTimeout bounds one run of a handler (default 1m).
+func WithTimeout(d time.Duration) Option { return func(q *Queue) { q.timeout = d } }
+
+// New builds a queue under prefix (e.g. "jobs:").
+func New(rdb *redis.Client, prefix string, opts ...Option) *Queue {
+	q := &Queue{rdb: rdb, prefix: prefix, concurrency: 4, maxAttempts: 5, deadMax: 1000, timeout: time.Minute,
+		backoff: Backoff, now: time.Now, handlers: map[string]Handler{}}
+	for _, opt := range opts {
+		opt(q)
+	}
+	return q
+}
+
+// Backoff is the default retry delay: 10s after the first failure, doubling, at most an hour.
+func Backoff(attempts int) time.Duration {
+	d := 10 * time.Second
+	for i := 1; i < attempts && d < time.Hour; i++ {
+		d *= 2
+	}
+	if d > time.Hour {
+		d = time.Hour
+	}
+	return d
+}
+
+func (q *Queue) key(name string) string { return q.prefix + name }
+
+// Handle registers h for jobs of type typ. Register every handler before Run.
+func (q *Queue) Handle(typ string, h Handler) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.handlers[typ] = h
+}
+
+// Enqueue adds a job of type typ; payload is stored as JSON.
+func (q *Queue) Enqueue(ctx context.Context, typ string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("jobs: encode %s payload: %w", typ, err)
+	}
+	id := make([]byte, 12)
+	if _, err := rand.Read(id); err != nil {
+		return err
+	}
+	raw, err := json.Marshal(Job{ID: "job_" + hex.EncodeToString(id), Type: typ, Payload: body, MaxAttempts: q.maxAttempts, EnqueuedAt: q.now().UTC()})
+	if err != nil {
+		return err
+	}
+	return q.rdb.LPush(ctx, q.key("queue"), raw).Err()
+}
+
+// Run works the queue with the configured concurrency and moves due retries and expired leases
+// back to the queue, until ctx is done; jobs already running are finished first.
+func (q *Queue) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < q.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			q.work(ctx)
+		}()
+	}
+	t := time.NewTicker(time.Second)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		case <-t.C:
+			if _, err := q.Promote(ctx); err != nil && ctx.Err() == nil {
+				slog.Warn("jobs: promote failed", "err", err)
+			}
+		}
+	}
+}
+
+// Promote moves due retries and expired leases back to the queue and returns how many moved.
+func (q *Queue) Promote(ctx context.Context) (int64, error) {
+	return promoteScript.Run(ctx, q.rdb, []string{q.key("scheduled"), q.key("queue"), q.key("leases"), q.key("processing")},
+		q.now().UnixMilli(), 100).Int64()
+}
+
+// work claims and runs jobs one at a time until ctx is done.
+func (q *Queue) work(ctx context.Context) {
+	for ctx.Err() == nil {
+		raw, err := q.rdb.BLMove(ctx, q.key("queue"), q.key("processing"), "RIGHT", "LEFT", 2*time.Second).Result()
+		if errors.Is(err, redis.Nil) {
+			continue // nothing within the block time
+		}
+		if err != nil {
+			if ctx.Err() == nil {
+				slog.Warn("jobs: claim failed", "err", err)
+				time.Sleep(time.Second) // Redis down: don't spin
+			}
+			continue
+		}
+		q.process(context.WithoutCancel(ctx), raw) // a shutdown lets the running job finish
+	}
+}
+
+// process runs one claimed job and records the outcome (done, retry later, or dead).
+func (q *Queue) process(ctx context.Context, raw string) {
+	deadline := q.now().Add(2 * q.timeout)
+	if err := q.rdb.ZAdd(ctx, q.key("leases"), redis.Z{Score: float64(deadline.UnixMilli()), Member: raw}).Err(); err != nil {
+		slog.Warn("jobs: lease failed", "err", err) // the job still runs; it just can't be recovered if we die
+	}
+	var job Job
+	if err := json.Unmarshal([]byte(raw), &job); err != nil {
+		job = Job{Type: "?", LastError: "undecodable job: " + err.Error()}
+		q.finish(ctx, raw, &job, Permanent(err))
+		return
+	}
+	q.mu.RLock()
+	h := q.handlers[job.Type]
+	q.mu.RUnlock()
+	if h == nil {
+		q.finish(ctx, raw, &job, Permanent(ErrNoHandler))
+		return
+	}
+	q.finish(ctx, raw, &job, q.run(ctx, h, job))
+}
+
+// run calls h with the per-run timeout; a panic is an error like any other.
+func (q *Queue) run(ctx context.Context, h Handler, job Job) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, q.timeout)
+	defer cancel()
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return h(ctx, job.Payload)
+}
+
+// finish releases the claim on raw and, on failure, schedules the retry or dead-letters the job.
+func (q *Queue) finish(ctx context.Context, raw string, job *Job, runErr error) {
+	job.Attempts++
+	var next string
+	var retryAt time.Time
+	if runErr != nil {
+		job.LastError = runErr.Error()
+		var perm permanentError
+		if errors.As(runErr, &perm) || job.Attempts >= job.MaxAttempts {
+			at := q.now().UTC()
+			job.FailedAt = &at
+			slog.Error("jobs: dead-lettered", "type", job.Type, "id", job.ID, "attempts", job.Attempts, "err", runErr)
+		} else {
+			retryAt = q.now().Add(q.backoff(job.Attempts))
+			slog.Warn("jobs: failed, will retry", "type", job.Type, "id", job.ID, "attempts", job.Attempts, "retry_at", retryAt, "err", runErr)
+		}
+		b, _ := json.Marshal(job)
+		next = string(b)
+	}
+	_, err := q.rdb.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		p.LRem(ctx, q.key("processing"), 1, raw)
+		p.ZRem(ctx, q.key("leases"), raw)
+		switch {
+		case runErr == nil:
+		case job.FailedAt == nil:
+			p.ZAdd(ctx, q.key("scheduled"), redis.Z{Score: float64(retryAt.UnixMilli()), Member: next})
+		default:
+			p.LPush(ctx, q.key("dead"), next)
+			p.LTrim(ctx, q.key("dead"), 0, q.deadMax-1)
+		}
+		return nil
+	})
+	if err != nil {
+		slog.Warn("jobs: finish failed (the job may run again)", "type", job.Type, "id", job.ID, "err", err)
+	}
+}
+
+// Dead returns up to n dead-lettered jobs, newest first.
+func (q *Queue) Dead(ctx context.Context, n int64) ([]Job, error) {
+	raws, err := q.rdb.LRange(ctx, q.key("dead"), 0, n-1).Result()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Job, 0, len(raws))
+	for _, raw := range raws {
+		var job Job
+		if json.Unmarshal([]byte(raw), &job) == nil {
+			out = append(out, job)
+		}
+	}
+	return out, nil
+}
+
+// RequeueDead puts dead jobs back on the queue with fresh attempts: those with the given IDs,
+// or every one if ids is empty. It returns how many were requeued.
+func (q *Queue) RequeueDead(ctx context.Context, ids []string) (int, error) {
+	raws, err := q.rdb.LRange(ctx, q.key("dead"), 0, -1).Result()
+	if err != nil {
+		return 0, err
+	}
+	want := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		want[id] = true
+	}
+	n := 0
+	for _, raw := range raws {
+		var job Job
+		if err := json.Unmarshal([]byte(raw), &job); err != nil || (len(ids) > 0 && !want[job.ID]) {
+			continue
+		}
+		job.Attempts, job.FailedAt = 0, nil // LastError stays, for context
+		b, _ := json.Marshal(job)
+		moved, err := requeueScript.Run(ctx, q.rdb, []string{q.key("dead"), q.key("queue")}, raw, b).Int()
+		if err != nil {
+			return n, err
+		}
+		n += moved // 0 if someone else requeued it first
+	}
+	return n, nil
+}
+
+// Stats counts the jobs in each state.
+func (q *Queue) Stats(ctx context.Context) (Stats, error) {
+	p := q.rdb.Pipeline()
+	queued := p.LLen(ctx, q.key("queue"))
+	processing := p.LLen(ctx, q.key("processing"))
+	scheduled := p.ZCard(ctx, q.key("scheduled"))
+	dead := p.LLen(ctx, q.key("dead"))
+	if _, err := p.Exec(ctx); err != nil {
+		return Stats{}, err
+	}
+	return Stats{Queued: queued.Val(), Processing: processing.Val(), Scheduled: scheduled.Val(), Dead: dead.Val()}, nil
+}
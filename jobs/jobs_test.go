@@ -0,0 +1,105 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var at = time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+func newTestQueue() (*Queue, redismock.ClientMock) {
+	rdb, m := redismock.NewClientMock()
+	q := New(rdb, "jobs:", WithMaxAttempts(3), WithTimeout(time.Minute))
+	q.now = func() time.Time { return at }
+	return q, m
+}
+
+func encode(t *testing.T, j Job) string {
+	b, err := json.Marshal(j)
+	require.NoError(t, err)
+	return string(b)
+}
+
+func TestProcess_SuccessReleasesTheClaim(t *testing.T) {
+	q, m := newTestQueue()
+	var got string
+	q.Handle("email.welcome", func(_ context.Context, p json.RawMessage) error { got = string(p); return nil })
+	raw := encode(t, Job{ID: "job_1", Type: "email.welcome", Payload: json.RawMessage(`{"user_id":7}`), MaxAttempts: 3})
+
+	m.ExpectZAdd("jobs:leases", redis.Z{Score: float64(at.Add(2 * time.Minute).UnixMilli()), Member: raw}).SetVal(1)
+	m.ExpectTxPipeline()
+	m.ExpectLRem("jobs:processing", 1, raw).SetVal(1)
+	m.ExpectZRem("jobs:leases", raw).SetVal(1)
+	m.ExpectTxPipelineExec()
+
+	q.process(context.Background(), raw)
+	assert.Equal(t, `{"user_id":7}`, got)
+	assert.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestProcess_FailureIsRetriedWithBackoff(t *testing.T) {
+	q, m := newTestQueue()
+	q.Handle("webhook.dispatch", func(context.Context, json.RawMessage) error { return errors.New("db down") })
+	job := Job{ID: "job_2", Type: "webhook.dispatch", Payload: json.RawMessage(`{}`), MaxAttempts: 3, Attempts: 1}
+	raw := encode(t, job)
+	job.Attempts, job.LastError = 2, "db down"
+
+	m.ExpectZAdd("jobs:leases", redis.Z{Score: float64(at.Add(2 * time.Minute).UnixMilli()), Member: raw}).SetVal(1)
+	m.ExpectTxPipeline()
+	m.ExpectLRem("jobs:processing", 1, raw).SetVal(1)
+	m.ExpectZRem("jobs:leases", raw).SetVal(1)
+	m.ExpectZAdd("jobs:scheduled", redis.Z{Score: float64(at.Add(20 * time.Second).UnixMilli()), Member: encode(t, job)}).SetVal(1)
+	m.ExpectTxPipelineExec()
+
+	q.process(context.Background(), raw)
+	assert.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestProcess_DeadLetters(t *testing.T) {
+	for name, tc := range map[string]struct {
+		attempts int
+		handler  Handler
+		err      string
+	}{
+		"attempts used up": {2, func(context.Context, json.RawMessage) error { return errors.New("smtp timeout") }, "smtp timeout"},
+		"permanent":        {0, func(context.Context, json.RawMessage) error { return Permanent(errors.New("bad payload")) }, "bad payload"},
+		"panic":            {2, func(context.Context, json.RawMessage) error { panic("boom") }, "panic: boom"},
+		"no handler":       {0, nil, ErrNoHandler.Error()},
+	} {
+		t.Run(name, func(t *testing.T) {
+			q, m := newTestQueue()
+			if tc.handler != nil {
+				q.Handle("email.welcome", tc.handler)
+			}
+			job := Job{ID: "job_3", Type: "email.welcome", Payload: json.RawMessage(`{}`), MaxAttempts: 3, Attempts: tc.attempts}
+			raw := encode(t, job)
+			failed := at
+			job.Attempts, job.LastError, job.FailedAt = tc.attempts+1, tc.err, &failed
+
+			m.ExpectZAdd("jobs:leases", redis.Z{Score: float64(at.Add(2 * time.Minute).UnixMilli()), Member: raw}).SetVal(1)
+			m.ExpectTxPipeline()
+			m.ExpectLRem("jobs:processing", 1, raw).SetVal(1)
+			m.ExpectZRem("jobs:leases", raw).SetVal(1)
+			m.ExpectLPush("jobs:dead", encode(t, job)).SetVal(1)
+			m.ExpectLTrim("jobs:dead", 0, 999).SetVal("OK")
+			m.ExpectTxPipelineExec()
+
+			q.process(context.Background(), raw)
+			assert.NoError(t, m.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, 10*time.Second, Backoff(1))
+	assert.Equal(t, 80*time.Second, Backoff(4))
+	assert.Equal(t, time.Hour, Backoff(20))
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"HelmyTask/handlers"
+	"HelmyTask/utils/redislog"
+)
+
+// serve runs srv on ln until ctx is cancelled (SIGTERM/SIGINT), then shuts down the way rolling
+// deploys need it:
+//
+//  1. /readyz starts failing, so the load balancer / Endpoints controller stops sending traffic;
+//  2. requests keep being served for drainDelay while that propagates (the pre-stop window);
+//  3. the listener closes and in-flight requests get up to timeout to finish.
+//
+// With srv.TLSConfig set it serves HTTPS (HTTP/2 included). It returns nil after a clean
+// shutdown, or the serve/shutdown error.
+func serve(ctx context.Context, srv *http.Server, ln net.Listener, health *handlers.HealthHandler, drainDelay, timeout time.Duration, rlog *redislog.Logger) error {
+	errc := make(chan error, 1)
+	go func() {
+		if srv.TLSConfig != nil {
+			errc <- srv.ServeTLS(ln, "", "") // certificates come from TLSConfig
+			return
+		}
+		errc <- srv.Serve(ln)
+	}()
+
+	select {
+	case err := <-errc: // never started or died on its own
+		return err
+	case <-ctx.Done():
+	}
+
+	health.Drain()
+	if rlog != nil { rlog.Info("shutdown: draining", map[string]string{"drain_delay": drainDelay.String(), "timeout": timeout.String()}) }
+	time.Sleep(drainDelay)
+
+	sctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	err := srv.Shutdown(sctx) // stops accepting, waits for active requests
+	if err != nil {
+		_ = srv.Close() // grace period over: cut the stragglers
+	}
+	if serr := <-errc; !errors.Is(serr, http.ErrServerClosed) && err == nil {
+		err = serr
+	}
+	if rlog != nil { rlog.Info("shutdown: complete", nil) }
+	return err
+}
+
+// backgroundStopTimeout bounds how long closeAll waits for background goroutines.
+const backgroundStopTimeout = 3 * time.Second
+
+// closer is a resource to release on shutdown.
+type closer struct {
+	name  string
+	close func() error
+}
+
+// closeAll runs once serve has returned. It waits (up to backgroundStopTimeout) for the
+// background goroutines in bg to notice the cancelled context (the leader releases its lease,
+// pollers stop), flushes folded log repeats, then closes resources in order; pass Redis last,
+// since the log and the lease release still need it. Every closer runs; the first error is returned.
+func closeAll(bg *sync.WaitGroup, rlog *redislog.Logger, closers ...closer) error {
+	done := make(chan struct{})
+	go func() { bg.Wait(); close(done) }()
+	select {
+	case <-done:
+	case <-time.After(backgroundStopTimeout):
+		if rlog != nil { rlog.Warn("shutdown: background tasks still running", map[string]string{"waited": backgroundStopTimeout.String()}) }
+	}
+	rlog.Flush()
+
+	var first error
+	for _, c := range closers {
+		if err := c.close(); err != nil && first == nil {
+			first = fmt.Errorf("close %s: %w", c.name, err)
+		}
+	}
+	return first
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"HelmyTask/handlers"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServe_DrainsBeforeShutdown(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	health := handlers.NewHealthHandler(nil)
+	r := gin.New()
+	r.GET("/readyz", health.Ready)
+	r.GET("/slow", func(c *gin.Context) { time.Sleep(300 * time.Millisecond); c.Status(http.StatusOK) })
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	base := "http://" + ln.Addr().String()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- serve(ctx, &http.Server{Handler: r}, ln, health, 200*time.Millisecond, 2*time.Second, nil) }()
+
+	slow := make(chan int, 1)
+	go func() {
+		resp, err := http.Get(base + "/slow")
+		if err != nil {
+			slow <- 0
+			return
+		}
+		resp.Body.Close()
+		slow <- resp.StatusCode
+	}()
+	time.Sleep(50 * time.Millisecond) // request in flight
+	cancel()                          // "SIGTERM"
+	time.Sleep(50 * time.Millisecond)
+
+	// still serving during the drain delay, but no longer ready
+	resp, err := http.Get(base + "/readyz")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+
+	assert.Equal(t, http.StatusOK, <-slow, "in-flight request completes")
+	assert.NoError(t, <-done)
+}
+
+func TestCloseAll_WaitsForBackgroundThenClosesInOrder(t *testing.T) {
+	var (
+		bg    sync.WaitGroup
+		order []string
+	)
+	bg.Add(1)
+	go func() { time.Sleep(50 * time.Millisecond); order = append(order, "task"); bg.Done() }()
+
+	err := closeAll(&bg, nil,
+		closer{"database", func() error { order = append(order, "database"); return errors.New("boom") }},
+		closer{"redis", func() error { order = append(order, "redis"); return nil }},
+	)
+
+	assert.EqualError(t, err, "close database: boom")
+	assert.Equal(t, []string{"task", "database", "redis"}, order, "every closer runs, after the background task")
+}
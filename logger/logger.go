@@ -0,0 +1,194 @@
+// Package logger is the process log: JSON lines on stdout through log/slog, at a level that
+// follows log_level (and its runtime overrides), optionally copied to the Redis log
+// (utils/redislog) so admins see problems where they already look.
+//
+// Init installs the logger as slog's default, so packages log with slog.Info/Warn/Error and
+// the stdlib log package (used by some dependencies) is routed through it as well. It runs
+// first thing in main; the Redis sink is attached later with SetSink, once Redis is up, and
+// applies to loggers handed out before that too (e.g. GORM's).
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"HelmyTask/utils/redislog"
+)
+
+// level is shared by every logger built here, so SetLevel applies at once everywhere.
+var level = new(slog.LevelVar)
+
+// sink is the current Redis copy target (nil = none); see SetSink.
+var sink atomic.Pointer[Sink]
+
+// ParseLevel maps debug|info|warn|error to a slog level.
+func ParseLevel(s string) (slog.Level, error) {
+	switch strings.ToLower(s) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info", "":
+		return slog.LevelInfo, nil
+	case "warn":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return 0, fmt.Errorf("unknown log level %q (want debug|info|warn|error)", s)
+}
+
+// SetLevel changes the minimum level of every logger; unknown names are rejected.
+func SetLevel(s string) error {
+	l, err := ParseLevel(s)
+	if err != nil {
+		return err
+	}
+	level.Set(l)
+	return nil
+}
+
+// Sink copies records at or above MinLevel to a Redis log.
+type Sink struct {
+	Log      *redislog.Logger
+	MinLevel slog.Level
+}
+
+// SetSink starts (or, with nil, stops) copying records to a Redis log.
+func SetSink(s *Sink) {
+	if s != nil && s.Log == nil {
+		s = nil
+	}
+	sink.Store(s)
+}
+
+// Copies reports whether records at l currently reach the Redis log through the sink, so
+// code that also writes to redislog directly can avoid a second copy.
+func Copies(l slog.Level) bool {
+	s := sink.Load()
+	return s != nil && l >= s.MinLevel
+}
+
+// New returns a JSON logger writing to w (and to the sink, when one is set).
+func New(w io.Writer) *slog.Logger {
+	return slog.New(fanout{slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level}), &redisHandler{}})
+}
+
+// Local returns a stdout-only logger at the shared level, never copied to the Redis sink: for
+// code reporting on Redis itself, whose log lines must not add to the load they describe.
+func Local() *slog.Logger {
+	return slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: level}))
+}
+
+// Init installs New(os.Stdout) as the default logger and returns it.
+func Init() *slog.Logger {
+	l := New(os.Stdout)
+	slog.SetDefault(l)
+	return l
+}
+
+// Fatal logs at error level and exits with status 1 (the structured log.Fatalf).
+func Fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
+
+// fanout sends each record to both handlers.
+type fanout [2]slog.Handler
+
+func (f fanout) Enabled(ctx context.Context, l slog.Level) bool {
+	return f[0].Enabled(ctx, l) || f[1].Enabled(ctx, l)
+}
+
+func (f fanout) Handle(ctx context.Context, r slog.Record) error {
+	var first error
+	for _, h := range f {
+		if h.Enabled(ctx, r.Level) {
+			if err := h.Handle(ctx, r.Clone()); err != nil && first == nil {
+				first = err
+			}
+		}
+	}
+	return first
+}
+
+func (f fanout) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return fanout{f[0].WithAttrs(attrs), f[1].WithAttrs(attrs)}
+}
+
+func (f fanout) WithGroup(name string) slog.Handler {
+	return fanout{f[0].WithGroup(name), f[1].WithGroup(name)}
+}
+
+// redisHandler turns records into entries for the current sink; attributes become meta
+// (groups are dotted: "db.driver"), and redislog moves known keys such as "err" into typed fields.
+type redisHandler struct {
+	attrs  []slog.Attr // from WithAttrs, already prefixed
+	prefix string      // from WithGroup, "a.b."
+}
+
+func (h *redisHandler) Enabled(_ context.Context, l slog.Level) bool {
+	return Copies(l) // redislog applies log_level itself
+}
+
+func (h *redisHandler) Handle(_ context.Context, r slog.Record) error {
+	s := sink.Load()
+	if s == nil {
+		return nil
+	}
+	meta := make(map[string]string, len(h.attrs)+r.NumAttrs())
+	for _, a := range h.attrs {
+		flatten(meta, "", a)
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		flatten(meta, h.prefix, a)
+		return true
+	})
+	s.Log.Forward(redisLevel(r.Level), r.Message, meta) // not re-reported: the sources report for themselves
+	return nil
+}
+
+func (h *redisHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	c := *h
+	c.attrs = append([]slog.Attr{}, h.attrs...)
+	for _, a := range attrs {
+		a.Key = h.prefix + a.Key
+		c.attrs = append(c.attrs, a)
+	}
+	return &c
+}
+
+func (h *redisHandler) WithGroup(name string) slog.Handler {
+	c := *h
+	c.prefix = h.prefix + name + "."
+	return &c
+}
+
+// flatten writes a (and any group it holds) into meta under dotted keys.
+func flatten(meta map[string]string, prefix string, a slog.Attr) {
+	v := a.Value.Resolve()
+	if v.Kind() == slog.KindGroup {
+		for _, g := range v.Group() {
+			flatten(meta, prefix+a.Key+".", g)
+		}
+		return
+	}
+	if a.Key != "" {
+		meta[prefix+a.Key] = v.String()
+	}
+}
+
+func redisLevel(l slog.Level) string {
+	switch {
+	case l >= slog.LevelError:
+		return "error"
+	case l >= slog.LevelWarn:
+		return "warn"
+	case l >= slog.LevelInfo:
+		return "info"
+	}
+	return "debug"
+}
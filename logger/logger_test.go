@@ -0,0 +1,75 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"time"
+
+	"HelmyTask/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew_JSONAtSharedLevel(t *testing.T) {
+	defer level.Set(slog.LevelInfo)
+	var buf bytes.Buffer
+	l := New(&buf)
+
+	l.Debug("hidden")
+	l.Info("boot: starting", "port", "8080")
+	require.NoError(t, SetLevel("warn"))
+	l.Info("hidden too")
+	l.Warn("slow query", "ms", 250)
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	require.Len(t, lines, 2)
+	var first map[string]any
+	require.NoError(t, json.Unmarshal(lines[0], &first))
+	assert.Equal(t, "INFO", first["level"])
+	assert.Equal(t, "boot: starting", first["msg"])
+	assert.Equal(t, "8080", first["port"])
+	assert.Contains(t, string(lines[1]), `"level":"WARN"`)
+
+	assert.Error(t, SetLevel("verbose"))
+}
+
+func TestSink_CopiesFromMinLevelWithFlattenedAttrs(t *testing.T) {
+	rlog, _, m := mocks.NewRedisLoggerWithMock()
+	defer SetSink(nil)
+	SetSink(&Sink{Log: rlog, MinLevel: slog.LevelWarn})
+	l := New(&bytes.Buffer{}).With("component", "db").WithGroup("query")
+
+	var pushed []byte
+	m.CustomMatch(func(_, actual []interface{}) error { pushed = actual[2].([]byte); return nil }).ExpectLPush("logs:app", "").SetVal(1)
+	m.ExpectLTrim("logs:app", 0, 99).SetVal("OK")
+	m.ExpectExpire("logs:app", 24*time.Hour).SetVal(true)
+
+	l.Info("below the sink level")
+	l.Warn("slow", "ms", 250)
+	assert.NoError(t, m.ExpectationsWereMet())
+	var entry struct {
+		Level, Msg string
+		Meta       map[string]string
+	}
+	require.NoError(t, json.Unmarshal(pushed, &entry))
+	assert.Equal(t, "warn", entry.Level)
+	assert.Equal(t, "slow", entry.Msg)
+	assert.Equal(t, map[string]string{"component": "db", "query.ms": "250"}, entry.Meta)
+
+	SetSink(nil)
+	assert.False(t, (&redisHandler{}).Enabled(context.Background(), slog.LevelError))
+}
+
+func TestParseLevel(t *testing.T) {
+	for in, want := range map[string]slog.Level{"debug": slog.LevelDebug, "": slog.LevelInfo, "WARN": slog.LevelWarn, "error": slog.LevelError} {
+		got, err := ParseLevel(in)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+	_, err := ParseLevel("off")
+	assert.Error(t, err)
+}
@@ -0,0 +1,44 @@
+// Package mailer hands rendered emails (see emailtmpl) to a mail transport: an SMTP server, or
+// the Redis log for deployments without one. Services send from the job queue, never on the
+// request path, so a slow or unreachable server only delays (and retries) the email.
+package mailer
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	"HelmyTask/emailtmpl"
+	"HelmyTask/utils/redislog"
+)
+
+// Mailer sends a rendered email and returns the provider name and its message ID (for bounce
+// tracking, see EmailDeliveryService). An error means the email may not have gone out and the
+// send can be retried.
+type Mailer interface {
+	Send(ctx context.Context, to string, msg emailtmpl.Message) (provider, messageID string, err error)
+}
+
+// Log "sends" by writing the message to the Redis log (provider "log"), for deployments
+// without a mail transport; the email still shows up in the delivery log.
+type Log struct{ Log *redislog.Logger }
+
+// Send logs msg.
+func (m Log) Send(_ context.Context, to string, msg emailtmpl.Message) (string, string, error) {
+	id, err := randomHex(12)
+	if err != nil {
+		return "", "", err
+	}
+	if m.Log != nil {
+		m.Log.Info("email (log transport)", map[string]string{"to": to, "kind": msg.Kind, "locale": msg.Locale, "subject": msg.Subject, "message_id": id})
+	}
+	return "log", id, nil
+}
+
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
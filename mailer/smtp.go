@@ -0,0 +1,189 @@
+// SMTP transport.
+
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"HelmyTask/emailtmpl"
+)
+
+// TLS modes for SMTPOptions.TLS.
+const (
+	TLSStartTLS = "starttls" // plain connection upgraded with STARTTLS (port 587); refused if the server can't
+	TLSImplicit = "implicit" // TLS from the first byte (port 465)
+	TLSNone     = "none"     // local relays only: credentials are then refused by net/smtp
+)
+
+// SMTPOptions configure the SMTP transport.
+type SMTPOptions struct {
+	Host     string
+	Port     int
+	Username string // "" = no AUTH
+	Password string
+	From     string        // "Name <addr>" or a bare address
+	TLS      string        // starttls (default) | implicit | none
+	Timeout  time.Duration // whole conversation per email; 0 = 30s
+}
+
+// SMTP sends email through one SMTP server, one connection per email.
+type SMTP struct {
+	opts SMTPOptions
+	from *mail.Address
+	now  func() time.Time
+}
+
+// NewSMTP checks the options.
+func NewSMTP(o SMTPOptions) (*SMTP, error) {
+	if o.Host == "" || o.Port <= 0 {
+		return nil, errors.New("mailer: smtp host and port are required")
+	}
+	from, err := mail.ParseAddress(o.From)
+	if err != nil {
+		return nil, fmt.Errorf("mailer: from address: %w", err)
+	}
+	switch o.TLS {
+	case "":
+		o.TLS = TLSStartTLS
+	case TLSStartTLS, TLSImplicit, TLSNone:
+	default:
+		return nil, fmt.Errorf("mailer: unknown tls mode %q", o.TLS)
+	}
+	if o.Timeout <= 0 {
+		o.Timeout = 30 * time.Second
+	}
+	return &SMTP{opts: o, from: from, now: time.Now}, nil
+}
+
+// Send delivers msg to to as multipart/alternative (text and HTML). The message ID is the
+// Message-ID header without its angle brackets.
+func (m *SMTP) Send(ctx context.Context, to string, msg emailtmpl.Message) (string, string, error) {
+	rcpt, err := mail.ParseAddress(to) // also rules out header injection through to
+	if err != nil {
+		return "", "", fmt.Errorf("mailer: recipient: %w", err)
+	}
+	id, err := randomHex(16)
+	if err != nil {
+		return "", "", err
+	}
+	id += "@" + m.from.Address[strings.LastIndex(m.from.Address, "@")+1:]
+	body, err := m.build(rcpt, id, msg)
+	if err != nil {
+		return "", "", err
+	}
+	if err := m.deliver(ctx, rcpt.Address, body); err != nil {
+		return "", "", err
+	}
+	return "smtp", id, nil
+}
+
+// deliver runs one SMTP conversation, bounded by the timeout and ctx.
+func (m *SMTP) deliver(ctx context.Context, to string, body []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, m.opts.Timeout)
+	defer cancel()
+	addr := net.JoinHostPort(m.opts.Host, fmt.Sprint(m.opts.Port))
+	tlsConf := &tls.Config{ServerName: m.opts.Host, MinVersion: tls.VersionTLS12}
+
+	var conn net.Conn
+	var err error
+	if m.opts.TLS == TLSImplicit {
+		conn, err = (&tls.Dialer{Config: tlsConf}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return err
+	}
+	deadline, _ := ctx.Deadline()
+	_ = conn.SetDeadline(deadline)
+	c, err := smtp.NewClient(conn, m.opts.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if m.opts.TLS == TLSStartTLS {
+		if ok, _ := c.Extension("STARTTLS"); !ok {
+			return errors.New("mailer: server does not offer STARTTLS")
+		}
+		if err := c.StartTLS(tlsConf); err != nil {
+			return err
+		}
+	}
+	if m.opts.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", m.opts.Username, m.opts.Password, m.opts.Host)); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(m.from.Address); err != nil {
+		return err
+	}
+	if err := c.Rcpt(to); err != nil {
+		return err
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(body); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil { // the server accepted (or refused) the message here
+		return err
+	}
+	return c.Quit()
+}
+
+// build renders the MIME message: headers, then a text and an HTML part (quoted-printable).
+func (m *SMTP) build(to *mail.Address, id string, msg emailtmpl.Message) ([]byte, error) {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	header := []string{
+		"From: " + m.from.String(),
+		"To: " + to.String(),
+		"Subject: " + mime.QEncoding.Encode("utf-8", msg.Subject),
+		"Date: " + m.now().Format(time.RFC1123Z),
+		"Message-ID: <" + id + ">",
+		"MIME-Version: 1.0",
+		"Content-Type: multipart/alternative; boundary=" + mw.Boundary(),
+	}
+	if msg.Locale != "" {
+		header = append(header, "Content-Language: "+msg.Locale)
+	}
+	buf.WriteString(strings.Join(header, "\r\n") + "\r\n\r\n")
+
+	for _, part := range []struct{ typ, body string }{{"text/plain", msg.Text}, {"text/html", msg.HTML}} {
+		pw, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.typ + "; charset=utf-8"},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qw := quotedprintable.NewWriter(pw)
+		if _, err := qw.Write([]byte(part.body)); err != nil {
+			return nil, err
+		}
+		if err := qw.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,62 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"HelmyTask/emailtmpl"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSMTP_BuildsMultipartMessage(t *testing.T) {
+	m, err := NewSMTP(SMTPOptions{Host: "smtp.example.com", Port: 587, From: "App <no-reply@example.com>"})
+	require.NoError(t, err)
+	m.now = func() time.Time { return time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC) }
+
+	to, _ := mail.ParseAddress("Ali <ali@example.org>")
+	raw, err := m.build(to, "abc@example.com", emailtmpl.Message{Locale: "ar", Subject: "مرحبا", Text: "hi", HTML: "<p>hi</p>"})
+	require.NoError(t, err)
+
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	require.NoError(t, err)
+	assert.Equal(t, "<abc@example.com>", msg.Header.Get("Message-ID"))
+	assert.Equal(t, "ar", msg.Header.Get("Content-Language"))
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "مرحبا", subject)
+
+	_, params, _ := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	r := multipart.NewReader(msg.Body, params["boundary"])
+	var types []string
+	for {
+		p, err := r.NextPart()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		body, _ := io.ReadAll(p) // quoted-printable decoded by the reader
+		types = append(types, strings.SplitN(p.Header.Get("Content-Type"), ";", 2)[0]+":"+string(body))
+	}
+	assert.Equal(t, []string{"text/plain:hi", "text/html:<p>hi</p>"}, types)
+}
+
+func TestSMTP_RejectsBadAddresses(t *testing.T) {
+	_, err := NewSMTP(SMTPOptions{Host: "smtp.example.com", Port: 587, From: "not an address"})
+	assert.Error(t, err)
+	_, err = NewSMTP(SMTPOptions{Host: "smtp.example.com", Port: 587, From: "a@b.c", TLS: "sometimes"})
+	assert.Error(t, err)
+
+	m, err := NewSMTP(SMTPOptions{Host: "smtp.example.com", Port: 587, From: "a@b.c"})
+	require.NoError(t, err)
+	_, _, err = m.Send(context.Background(), "x@y.z\r\nBcc: victim@example.com", emailtmpl.Message{})
+	assert.Error(t, err, "no header injection through the recipient")
+}
@@ -1,22 +1,84 @@
 package main
 
 import (
-	"log"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"runtime"
+	"sync"
+	"syscall"
 	"time"
 
+	"HelmyTask/audit"
+	"HelmyTask/bootinfo"
+	"HelmyTask/changelog"
 	"HelmyTask/config"
+	"HelmyTask/emailtmpl"
+	"HelmyTask/errreport"
+	"HelmyTask/events"
+	"HelmyTask/experiments"
+	"HelmyTask/global"
+	grpcapi "HelmyTask/grpc"
+	"HelmyTask/grpc/userpb"
+	"HelmyTask/handlers"
+	"HelmyTask/hooks"
+	"HelmyTask/jobs"
+	"HelmyTask/logger"
+	"HelmyTask/mailer"
+	"HelmyTask/metrics"
+	"HelmyTask/models"
+	"HelmyTask/notifications"
+	"HelmyTask/prober"
 	"HelmyTask/repositories"
+	"HelmyTask/requestsign"
 	"HelmyTask/routes"
+	"HelmyTask/scheduler"
+	"HelmyTask/schemacheck"
+	"HelmyTask/scripting"
 	"HelmyTask/services"
+	"HelmyTask/settings"
+	"HelmyTask/slo"
+	"HelmyTask/stats"
+	"HelmyTask/tracing"
+	"HelmyTask/utils/httpclient"
+	"HelmyTask/utils/jwtkeys"
+	"HelmyTask/utils/knownemails"
+	"HelmyTask/utils/leader"
+	"HelmyTask/utils/lockout"
+	"HelmyTask/utils/nonce"
+	"HelmyTask/utils/openapi"
+	"HelmyTask/utils/origins"
+	"HelmyTask/utils/ratelimit"
+	"HelmyTask/utils/redishook"
 	"HelmyTask/utils/redislog"
+	"HelmyTask/utils/redisscript"
+	"HelmyTask/utils/revocation"
+	"HelmyTask/utils/session"
 
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+	gogrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
 func main() {
+	os.Exit(execute(os.Args[1:])) // `server [serve]` runs the API; the other subcommands are in cli.go
+}
+
+// runServer is `server serve`: the API until SIGTERM.
+func runServer() {
+	bootStart := time.Now() // for the boot report
+	logger.Init() // JSON on stdout; the Redis copy is attached once Redis is up
+
 	// 1) Load config from file and||or env
 	cfg := config.Load() // Returns *config.Config with merged settings.
-	log.Printf("[boot] %s starting in %s on :%s", cfg.AppName, cfg.Env, cfg.HTTPPort)
+	_ = logger.SetLevel(cfg.LogLevel) // validated in config.Load
+	slog.Info("boot: starting", "app", cfg.AppName, "env", cfg.Env, "port", cfg.HTTPPort)
 
 	// 2) Initialize infrastructure (DB and Redis).
 	db := config.InitDB(cfg)     // Open DB based on cfg.DBDriver and run migrations.
@@ -24,17 +86,200 @@ func main() {
 	rdb := config.InitRedis(cfg) // single Redis client (Ping verified)
 
 	
-	// 3) Build Redis logger (list key: logs:app)
-	rlog := redislog.New(rdb, "logs:app", 1000, 7*24*time.Hour)
+	// 3) Build Redis logger (list key: logs:app; stream key: logs:app:stream)
+	logOpts := []redislog.Option{redislog.WithMinLevel(cfg.LogRedisMinLevel)} // validated in config.Load
+	var sentry *errreport.Sentry // nil = errors stay in the logs
+	if cfg.ErrorReporting.SentryDSN != "" {
+		var err error
+		if sentry, err = errreport.NewSentry(cfg.ErrorReporting.Options(cfg.Env)); err != nil {
+			logger.Fatal("boot: sentry", "err", err)
+		}
+		errreport.Set(sentry) // recovered panics
+		logOpts = append(logOpts, redislog.WithReporter(cfg.ErrorReporting.MinLevel, errreport.ReportEntry)) // service errors
+	}
+	for level, rule := range cfg.LogSampling {
+		logOpts = append(logOpts, redislog.WithSampling(level, rule.Sampling()))
+	}
+	if cfg.LogRedisBuffer > 0 { // batched writes once rlog.Run is spawned below; synchronous until then
+		logOpts = append(logOpts, redislog.WithAsync(cfg.LogRedisBuffer))
+	}
+	logKey, stream := "logs:app", cfg.LogRedisMode == "stream"
+	if stream {
+		logKey = "logs:app:stream" // the list key can't turn into a stream in place
+		logOpts = append(logOpts, redislog.WithStream())
+	}
+	rlog := redislog.New(rdb, logKey, cfg.LogMaxLen, cfg.Settings().Retention(), logOpts...) // limits follow runtime settings below
+	if stream {
+		if err := rlog.CreateGroups(context.Background(), cfg.LogRedisStreamGroups...); err != nil {
+			logger.Fatal("boot: log_redis_stream_groups", "err", err)
+		}
+	}
+	if sinkLevel, err := logger.ParseLevel(cfg.LogRedisSink); err == nil { // "off" fails to parse
+		logger.SetSink(&logger.Sink{Log: rlog, MinLevel: sinkLevel})
+	}
+	config.SetSlowQueryLog(rlog) // slow queries reach the Redis log even with the sink off
 	rlog.Info("app boot", map[string]string{
 		"env":   cfg.Env,
 		"port":  cfg.HTTPPort,
 		"redis": cfg.RedisAddr,
 	})
 
+	runtimeSettings, err := settings.New(rdb, cfg.Settings()) // config base + admin overrides from Redis
+	if err != nil {
+		logger.Fatal("boot: settings", "err", err)
+	}
+	if err := runtimeSettings.Refresh(context.Background()); err != nil { // start with the current overrides
+		slog.Warn("boot: settings overrides not loaded", "err", err)
+	}
+	runtimeSettings.OnChange(func(s settings.Settings) { rlog.SetLevel(s.LogLevel); _ = logger.SetLevel(s.LogLevel); rlog.SetLimits(s.LogMaxLen, s.Retention()) })
+	config.OnReload(func(c *config.Config) { // config.yaml edited: new base, admin overrides still on top
+		if err := runtimeSettings.SetBase(c.Settings()); err != nil {
+			slog.Warn("config: reloaded settings refused", "err", err)
+		}
+	})
+
+	var tracer trace.TracerProvider // nil = no spans
+	closers := []closer{}           // released after shutdown, in order
+	if sentry != nil {
+		closers = append(closers, closer{"sentry", func() error { // send queued error reports
+			if !sentry.Flush(5 * time.Second) {
+				return errors.New("queued events not sent")
+			}
+			return nil
+		}})
+	}
+	if cfg.Tracing.Enabled {
+		tp, err := tracing.Setup(context.Background(), cfg.Tracing.Options(cfg.AppName, cfg.Env))
+		if err != nil {
+			logger.Fatal("boot: tracing", "err", err)
+		}
+		if err := db.Use(tracing.GORM(tp)); err != nil { // queries become child spans of the request
+			logger.Fatal("boot: tracing gorm", "err", err)
+		}
+		rdb.AddHook(tracing.Redis(tp)) // and so do Redis commands
+		tracer = tp
+		closers = append(closers, closer{"tracing", func() error { // export buffered spans
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			return tp.Shutdown(ctx)
+		}})
+	}
+
+	jwtExp, _ := time.ParseDuration(cfg.JWTExpires) // Convert "72h" to time.Duration (ignore parse err due to defaults).
+	sessionTTL, _ := time.ParseDuration(cfg.SessionTTL) // validated in config.Load
+	sessions := session.New(rdb, sessionTTL)
+	revocations := revocation.New(rdb, jwtExp) // markers live as long as a token can
+	var knownEmails *knownemails.Store // nil = every uniqueness check goes to the DB
+	if ttl, _ := time.ParseDuration(cfg.KnownEmailsTTL); ttl > 0 { // validated in config.Load
+		knownEmails = knownemails.New(rdb, ttl)
+	}
+
+	userEvents := events.NewHub(rdb, "events:users") // user changes for GET /ws, across replicas
+	jwtKeys := jwtkeys.NewHMAC(cfg.JWTSecret) // HS256 unless RS256 key files are configured
+	if cfg.JWTAlgorithm == jwtkeys.RS256 {
+		var err error
+		if jwtKeys, err = jwtkeys.LoadRSA(cfg.JWTKeyID, cfg.JWTPrivateKeyPath, cfg.JWTPublicKeys); err != nil {
+			logger.Fatal("boot: jwt keys", "err", err)
+		}
+	}
+	passwordPolicy := cfg.PasswordPolicy.Policy() // shared by the service and the "password" binding tag
+	scripts, err := scripting.New(cfg.Scripting.Spec()) // already validated by config.Load
+	if err != nil {
+		logger.Fatal("boot: scripting", "err", err)
+	}
+
+	var promMetrics *metrics.Metrics // nil = no /metrics, no instrumentation
+	if cfg.MetricsEnabled {
+		promMetrics = metrics.New()
+	}
+	redisSlow, _ := time.ParseDuration(cfg.RedisSlowThreshold) // validated in config.Load
+	if promMetrics != nil || redisSlow > 0 {
+		hookOpts := redishook.Options{Slow: redisSlow, Log: logger.Local()} // slow commands never go to the Redis log
+		if promMetrics != nil { // not a typed nil
+			hookOpts.Metrics = promMetrics
+		}
+		rdb.AddHook(redishook.New(hookOpts)) // command latency histograms + slow command log
+	}
+
 	// 4) Construct repositories and services (dependency injection).
 	userRepo := repositories.NewUserRepository(db) // Repo uses *gorm.DB to talk to chosen DB.
-	userSvc := services.NewUserService(userRepo, rdb, rlog)  // Service wraps business rules and JWT issuance.
+	if promMetrics != nil || tracer != nil { // per-method call counts, latency, errors + spans
+		var calls repositories.CallRecorder // stays nil (not a typed nil) with metrics off
+		if promMetrics != nil {
+			calls = promMetrics
+		}
+		userRepo = repositories.NewInstrumentedUserRepository(userRepo, calls, tracer)
+	}
+	jobQueue := jobs.New(rdb, "jobs:", cfg.Jobs.Options()...) // slow side effects run here, off the request path
+	emailTemplates, err := emailtmpl.New(cfg.EmailDefaultLocale) // per-locale variants, RTL-aware
+	if err != nil {
+		logger.Fatal("boot: email templates", "err", err)
+	}
+	var mailTransport mailer.Mailer = mailer.Log{Log: rlog} // mail.transport: log
+	if cfg.Mail.Transport == "smtp" {
+		if mailTransport, err = mailer.NewSMTP(cfg.Mail.Options()); err != nil {
+			logger.Fatal("boot: mail.smtp", "err", err)
+		}
+	}
+	emailSvc := services.NewEmailDeliveryService(repositories.NewEmailDeliveryRepository(db), userRepo, rlog) // Bounce tracking.
+	webhookOpts := cfg.Egress.ClientOptions() // proxy/allowlist + re-checked private-IP block
+	webhookOpts.BlockPrivate, webhookOpts.TrustedHosts = true, cfg.WebhookTrustedHosts
+	webhookClient, err := httpclient.New(webhookOpts)
+	if err != nil {
+		logger.Fatal("boot: webhook http client", "err", err)
+	}
+	webhookSvc := services.NewWebhookService(repositories.NewWebhookRepository(db), repositories.NewWebhookDeliveryRepository(db), webhookClient, cfg.WebhookTrustedHosts, scripts, rlog) // SSRF-checked targets; the configured filter picks events.
+	emailSender := services.NewEmailSender(jobQueue, userRepo, emailTemplates, mailTransport, emailSvc, cfg.AppName, rlog) // Welcome/verification/reset emails.
+	var smsSender notifications.SMSSender = notifications.LogSMS{Log: rlog} // notifications.sms.provider: log
+	if t := cfg.Notifications.SMS.Twilio; cfg.Notifications.SMS.Provider == "twilio" {
+		smsClient, err := httpclient.New(cfg.Egress.ClientOptions()) // through the egress proxy/allowlist
+		if err != nil {
+			logger.Fatal("boot: sms http client", "err", err)
+		}
+		smsSender = notifications.Twilio{AccountSID: t.AccountSID, AuthToken: t.AuthToken, From: t.From, Client: smsClient}
+	}
+	notificationRepo := repositories.NewNotificationRepository(db)
+	notifier, err := notifications.NewDispatcher(cfg.Notifications.Routes,
+		notifications.InApp{Repo: notificationRepo}, notifications.Email{Mailer: mailTransport}, notifications.SMS{Sender: smsSender})
+	if err != nil {
+		logger.Fatal("boot: notifications", "err", err)
+	}
+	var eventBus events.Publisher // user events for other services (nil = off)
+	switch cfg.EventBus.Driver {
+	case "nats":
+		if eventBus, err = events.NewNATS(cfg.EventBus.NATS.URL, cfg.EventBus.NATS.SubjectPrefix); err != nil {
+			logger.Fatal("boot: event_bus.nats", "err", err)
+		}
+	case "kafka":
+		eventBus = events.NewKafka(cfg.EventBus.Kafka.Brokers, cfg.EventBus.Kafka.Topic)
+	}
+	lifecycleHooks := append(hooks.Registered(), // Plug-ins added via hooks.Register in init(), plus:
+		userEvents, // the /ws feed
+		services.NewWebhookHooks(webhookSvc, jobQueue, rlog), // webhook targets, via the job queue
+		emailSender, // welcome email, via the job queue
+		services.NewNotificationHooks(notifier, userRepo, notificationRepo, jobQueue, cfg.AppName, rlog)) // email/SMS/in-app, via the job queue
+	if eventBus != nil {
+		lifecycleHooks = append(lifecycleHooks, services.NewEventBusHooks(eventBus, jobQueue, rlog)) // NATS/Kafka, via the job queue
+		closers = append(closers, closer{"event bus", eventBus.Close}) // after the job workers stop
+	}
+	userSvc := services.NewUserService(userRepo, rdb, rlog, // Service wraps business rules and JWT issuance.
+		services.WithTwoFactor(cfg.TwoFactorKey, cfg.AppName), // TOTP secrets encrypted at rest.
+		services.WithPasswordMinScore(cfg.PasswordMinScore), // Strength floor for register/password change.
+		services.WithPasswordPolicy(passwordPolicy), // Length/classes/banned list.
+		services.WithJWTKeys(jwtKeys), // HS256 secret or RS256 signing key.
+		services.WithLifecycleHooks(lifecycleHooks...),
+		services.WithJobs(jobQueue), // Cache warming after register runs in the background.
+		services.WithScripts(scripts), // Configured registration rules + extra JWT claims.
+		services.WithCredentialRevocation(sessions, revocations), // Password change logs out everywhere.
+		services.WithCacheTTL(func() time.Duration { return runtimeSettings.Current().TTL() }), // cache_ttl, overridable at runtime.
+		services.WithKnownEmails(knownEmails), // Redis pre-filter for duplicate emails.
+		services.WithLoginLockout(lockout.New(rdb, cfg.LoginLockout.Policy())), // Repeated failed logins lock the email for a while.
+		services.WithMetrics(promMetrics)) // Cache hit/miss counters.
+	apiKeyRepo := repositories.NewAPIKeyRepository(db) // API keys for machine clients.
+	apiKeySvc := services.NewAPIKeyService(apiKeyRepo, userRepo, rlog)
+	auditRec := audit.New(repositories.NewAuditRepository(db), rlog) // Who changed which user, and how.
+	incidentSvc := services.NewIncidentService(repositories.NewIncidentRepository(db), rlog) // Status page incidents.
+	deletionSvc := services.NewDeletionService(repositories.NewDeletionRequestRepository(db), userSvc, jobQueue, auditRec, cfg.AccountDeletion.Window(), rlog) // Admin-approved account erasure.
 
 	// 5) Create Gin engine and wire routes
 	r := gin.New()                                  // Create a new bare Gin engine (no default middleware).
@@ -44,16 +289,257 @@ func main() {
 _ = r.SetTrustedProxies(nil)
 // or trust only local proxies
 // _ = r.SetTrustedProxies([]string{"127.0.0.1"})
-	jwtExp, _ := time.ParseDuration(cfg.JWTExpires) // Convert "72h" to time.Duration (ignore parse err due to defaults).
-	routes.Setup(r, userSvc, cfg.JWTSecret, jwtExp) // Attach middlewares and endpoints.
+	rateRules := map[string]ratelimit.Rule{} // config → limiter rules per route group
+	for group, rule := range cfg.RateLimits {
+		rateRules[group] = ratelimit.Rule{RequestsPerMinute: rule.RequestsPerMinute, Burst: rule.Burst}
+	}
+	schema, err := schemacheck.New(db, config.MigratedModels()...) // does the DB still match our models (another release may have migrated since)?
+	if err != nil {
+		logger.Fatal("boot: schema check", "err", err)
+	}
+	if r := schema.Check(context.Background()); !r.Compatible { // before serving; /readyz keeps it current
+		slog.Warn("boot: database schema incompatible, writes disabled", "mismatches", r.Mismatches)
+	}
+	healthOpts := []handlers.HealthOption{handlers.WithCheckCache(cfg.Readiness.CacheDuration())}
+	for name, rc := range cfg.Readiness.Checks {
+		optional, timeout := rc.Policy()
+		healthOpts = append(healthOpts, handlers.WithCheckPolicy(name, handlers.CheckPolicy{Optional: optional, Timeout: timeout}))
+	}
+	health := handlers.NewHealthHandler(map[string]handlers.HealthCheck{ // /readyz
+		"db": func(ctx context.Context) error {
+			sqlDB, err := db.DB()
+			if err != nil {
+				return err
+			}
+			return sqlDB.PingContext(ctx)
+		},
+		"redis": func(ctx context.Context) error { return rdb.Ping(ctx).Err() },
+		"schema": schema.Ready, // also what SchemaGuard goes by
+	}, healthOpts...)
+	// Singleton background work runs only on the replica holding the "singletons" lease.
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
+	var background sync.WaitGroup // waited on before Redis is closed (see closeAll)
+	spawn := func(task func(context.Context)) {
+		background.Add(1)
+		go func() { defer background.Done(); task(ctx) }()
+	}
+	spawn(rlog.Run) // background Redis log writer; drains its queue on shutdown, before Redis closes
+	spawn(userEvents.Run) // relays user change events to this replica's WebSocket clients; disconnects them on shutdown
+	spawn(jobQueue.Run) // background job workers; finish the jobs in hand on shutdown
+	spawn(func(ctx context.Context) { rlog.FlushEvery(ctx, time.Minute) }) // report folded log repeats even when a burst just stops
+	spawn(func(ctx context.Context) { // every replica
+		runtimeSettings.RefreshEvery(ctx, 5*time.Second, func(err error) { slog.Warn("settings: refresh", "err", err) })
+	})
+	singletons := leader.New(rdb, "singletons", 15*time.Second) // a dead leader is replaced within 15s
+	singletonTasks := []func(context.Context){ // periodic jobs append here
+		func(ctx context.Context) { webhookSvc.RetryEvery(ctx, 30*time.Second) }, // failed webhook deliveries, on their backoff schedule
+		func(ctx context.Context) { deletionSvc.ExpireEvery(ctx, time.Minute) }, // deletion requests left undecided past their window
+	}
+	if probeOpts := cfg.Probes.Options(); probeOpts.Interval > 0 {
+		checks := []prober.Check{prober.CacheRoundTrip(rdb, leader.InstanceID()), prober.DBRoundTrip(db)}
+		if cfg.Probes.CanaryEmail != "" {
+			canary := models.LoginRequest{Email: cfg.Probes.CanaryEmail, Password: cfg.Probes.CanaryPassword}
+			checks = append(checks, prober.Login(func(ctx context.Context) (string, error) { return userSvc.Login(ctx, canary, cfg.JWTSecret, time.Minute) }))
+		}
+		singletonTasks = append(singletonTasks, prober.New(rdb, rlog, probeOpts, checks...).Run)
+	}
+	var schedJobs []scheduler.Job // maintenance jobs from config (names validated in config.Load)
+	statsRepo := repositories.NewStatsRepository(db)
+	for name, jc := range cfg.Scheduler.Jobs {
+		every, maxAge := jc.Durations()
+		if every <= 0 {
+			continue
+		}
+		job := scheduler.Job{Name: name, Every: every}
+		switch name {
+		case "trim_log":
+			job.Run = func(ctx context.Context) (string, error) {
+				n, err := rlog.TrimOlderThan(ctx, maxAge)
+				return fmt.Sprintf("removed %d log entries", n), err
+			}
+		case "purge_deletion_requests":
+			job.Run = func(context.Context) (string, error) {
+				n, err := deletionSvc.PurgeFinished(maxAge)
+				return fmt.Sprintf("purged %d deletion requests", n), err
+			}
+		case "refresh_stats":
+			job.Run = func(ctx context.Context) (string, error) {
+				u, err := stats.Refresh(ctx, rdb, statsRepo)
+				return fmt.Sprintf("%d users", u.Total), err
+			}
+		}
+		schedJobs = append(schedJobs, job)
+	}
+	sched := scheduler.New(rdb, rlog, schedJobs...)
+	singletonTasks = append(singletonTasks, sched.Run)
+	sloTracker := slo.New(rdb, cfg.SLO.Objectives())
+	trustedOrigins, err := origins.New(rdb, cfg.CORSAllowedOrigins, 10*time.Second) // runtime additions reach other replicas within 10s
+	if err != nil {
+		logger.Fatal("boot: cors_allowed_origins", "err", err)
+	}
+	apiChanges, err := changelog.Load(cfg.ChangelogPath)
+	if err != nil {
+		logger.Fatal("boot: changelog_path", "err", err)
+	}
+	var apiSpec *openapi.Spec
+	if cfg.OpenAPIValidation.Enabled {
+		if apiSpec, err = openapi.Load(cfg.OpenAPIValidation.Spec); err != nil {
+			logger.Fatal("boot: openapi_validation.spec", "err", err)
+		}
+	}
+	if every, _ := time.ParseDuration(cfg.SLO.ReportInterval); every > 0 {
+		singletonTasks = append(singletonTasks, func(ctx context.Context) { sloTracker.ReportEvery(ctx, every, rlog) })
+	}
+	spawn(func(ctx context.Context) { singletons.Run(ctx, leader.Group(singletonTasks...)) }) // releases the lease on shutdown
+
+	limiter := ratelimit.New(rdb, "rl:")
+	// Lua scripts (rate limiter, leader lease) go by EVALSHA from the first call; on failure they load on demand.
+	if err := redisscript.LoadAll(context.Background(), rdb); err != nil {
+		rlog.Warn("redis script preload failed", map[string]string{"err": err.Error()})
+	}
 
+	var experimentReg *experiments.Registry // nil = no experiments configured
+	if len(cfg.Experiments.Definitions) > 0 {
+		sink := experiments.NewRedisSink(rdb, cfg.Experiments.Stream, cfg.Experiments.StreamMaxLen) // exposures for analytics
+		if experimentReg, err = experiments.New(cfg.Experiments.List(), sink); err != nil {
+			logger.Fatal("boot: experiments", "err", err) // validated in config.Load
+		}
+	}
+
+	var seedHandler *handlers.SeedHandler // POST /admin/seed exists on dev databases only
+	if cfg.Env == "dev" {
+		seedHandler = handlers.NewSeedHandler(db)
+	}
 
-	rlog.Info("http server start", map[string]string{"port": cfg.HTTPPort})
-	if err := r.Run(":" + cfg.HTTPPort); err != nil {
-		rlog.Error("http server error", map[string]string{"err": err.Error()})
+	routes.Setup(r, routes.Deps{ // Attach middlewares and endpoints.
+		Auth:                userSvc,
+		Users:               userSvc,
+		Setup:               userSvc,
+		SetupToken:          cfg.SetupToken,
+		APIKeys:             apiKeySvc,
+		SignatureNonces:     nonce.New(rdb, "nonce:sig:", 2*requestsign.DefaultTolerance), // a timestamp is accepted up to the tolerance either side
+		Emails:              emailSvc,
+		Webhooks:            webhookSvc,
+		Incidents:           incidentSvc,
+		Deletions:           deletionSvc,
+		Notifications:       services.NewNotificationService(notificationRepo),
+		DeletionApproval:    cfg.AccountDeletion.RequireApproval,
+		Audit:               auditRec,
+		EmailWebhookSecret:  cfg.EmailWebhookSecret,
+		JWTSecret:           cfg.JWTSecret,
+		JWTKeys:             jwtKeys,
+		JWTExpires:          jwtExp,
+		MaxBodyBytes:        cfg.MaxBodyBytes,
+		RequestTimeouts:     cfg.Timeouts(),
+		StrictSlash:         !cfg.RedirectTrailingSlash,
+		RateLimiter:         limiter,
+		RateLimits:          rateRules,
+		AuthMode:            cfg.AuthMode,
+		Sessions:            sessions,
+		SessionCookieSecure: cfg.SessionCookieSecure,
+		Revocations:         revocations,
+		PasswordPolicy:      &passwordPolicy,
+		Health:              health,
+		Diagnostics:         handlers.NewDiagnosticsHandler(schema, singletons),
+		BootInfo:            handlers.NewBootInfoHandler(rdb, singletons.Status().Instance),
+		Seed:                seedHandler,
+		SchemaCompatible:    schema.Compatible,
+		SLO:                 sloTracker,
+		Probes:              handlers.NewProbeHandler(rdb),
+		Scheduler:           handlers.NewSchedulerHandler(sched, rdb),
+		Jobs:                jobQueue,
+		LogStream:           handlers.NewLogStreamHandler(rlog, handlers.WithLogStreamAccessCheck(revocations, userSvc)),
+		LogAdmin:            handlers.NewLogAdminHandler(rlog, runtimeSettings, auditRec, cfg.LogArchiveDir),
+		EmailTemplates:      handlers.NewEmailTemplateHandler(emailTemplates, cfg.AppName),
+		Origins:             trustedOrigins,
+		OpenAPI:             apiSpec,
+		Settings:            runtimeSettings,
+		Metrics:             promMetrics,
+		Profiling:           cfg.PprofEnabled,
+		Changelog:           apiChanges,
+		ErrorDocsURL:        cfg.ErrorDocsURL,
+		Experiments:         experimentReg,
+		Events:              userEvents,
+		Tracer:              tracer,
+		OpenAPIResponses:    cfg.OpenAPIValidation.Responses && gin.IsDebugging(),
+		ResponseStyles:      cfg.Styles(),
+	})
+
+	// 6) Serve until SIGTERM/SIGINT, then drain (see lifecycle.go); fatal if it fails to bind.
+	ln, err := net.Listen("tcp", ":"+cfg.HTTPPort)
+	if err != nil {
+		logger.Fatal("boot: listen", "port", cfg.HTTPPort, "err", err) // Stop the process if server fails to start.
+	}
+	drainDelay, _ := time.ParseDuration(cfg.ShutdownDrainDelay) // validated in config.Load
+	shutdownTimeout, _ := time.ParseDuration(cfg.ShutdownTimeout)
+	tlsConfig, redirect, err := serverTLS(cfg)
+	if err != nil {
+		logger.Fatal("boot: tls", "err", err)
+	}
+	if redirect != nil && cfg.TLSRedirectPort != "" { // plain HTTP → HTTPS (and ACME challenges); closed with the rest below
+		rln, err := net.Listen("tcp", ":"+cfg.TLSRedirectPort)
+		if err != nil {
+			logger.Fatal("boot: listen", "port", cfg.TLSRedirectPort, "err", err)
+		}
+		redirectSrv := &http.Server{Handler: redirect, ReadHeaderTimeout: 10 * time.Second}
+		go func() {
+			if err := redirectSrv.Serve(rln); !errors.Is(err, http.ErrServerClosed) {
+				slog.Error("http: redirect server error", "err", err)
+			}
+		}()
+		closers = append([]closer{{"https redirect", redirectSrv.Close}}, closers...)
+	}
+	if cfg.GRPCPort != "" { // the user API over gRPC: same services, JWTs and certificate; stopped with the rest below
+		gln, err := net.Listen("tcp", ":"+cfg.GRPCPort)
+		if err != nil {
+			logger.Fatal("boot: listen", "port", cfg.GRPCPort, "err", err)
+		}
+		interceptor := grpcapi.NewInterceptor(jwtKeys,
+			grpcapi.WithRevocation(revocations),
+			grpcapi.WithRateLimit(limiter, func() ratelimit.Rule { return runtimeSettings.Current().Rule("auth") }), // shares the REST /auth buckets
+			grpcapi.WithMaintenance(func() (bool, string) { cur := runtimeSettings.Current(); return cur.MaintenanceMode, cur.MaintenanceMessage }),
+			grpcapi.WithSchemaGuard(schema.Compatible))
+		grpcOpts := []gogrpc.ServerOption{gogrpc.UnaryInterceptor(interceptor.Unary)}
+		if tlsConfig != nil {
+			grpcOpts = append(grpcOpts, gogrpc.Creds(credentials.NewTLS(tlsConfig)))
+		}
+		grpcSrv := gogrpc.NewServer(grpcOpts...)
+		userpb.RegisterUserServiceServer(grpcSrv, grpcapi.NewServer(userSvc, userSvc, cfg.JWTSecret, jwtExp, grpcapi.WithAudit(auditRec)))
+		go func() {
+			if err := grpcSrv.Serve(gln); err != nil && !errors.Is(err, gogrpc.ErrServerStopped) {
+				slog.Error("grpc: server error", "err", err)
+			}
+		}()
+		closers = append([]closer{{"grpc", func() error { grpcSrv.GracefulStop(); return nil }}}, closers...)
+		rlog.Info("grpc server start", map[string]string{"port": cfg.GRPCPort, "tls": fmt.Sprint(tlsConfig != nil)})
+	}
+	bootReport := bootinfo.Report{Instance: singletons.Status().Instance, App: cfg.AppName, Version: global.AppVersion, GoVersion: runtime.Version(), Env: cfg.Env,
+		StartedAt: bootStart.UTC(), BootMS: time.Since(bootStart).Milliseconds(),
+		DB:       bootinfo.DB{Driver: cfg.DBDriver, Migrations: config.LastMigration.Models, CreatedTables: config.LastMigration.Created,
+			Schema: config.LastMigration.Checksum, Compatible: schema.Compatible()},
+		Redis:    bootinfo.RedisInfo(context.Background(), rdb),
+		Features: cfg.Features(), FeatureFlags: runtimeSettings.Current().FeatureFlags, Routes: len(r.Routes())}
+	slog.Info("boot: report", "report", bootReport) // one line to check a deployment by; GET /admin/boot-info has every replica's
+	if err := bootinfo.Save(context.Background(), rdb, bootReport); err != nil {
+		slog.Warn("boot: report not stored", "err", err)
+	}
+	rlog.Info("http server start", map[string]string{"port": cfg.HTTPPort, "tls": fmt.Sprint(tlsConfig != nil)})
+	if err := serve(ctx, &http.Server{Handler: r, TLSConfig: tlsConfig}, ln, health, drainDelay, shutdownTimeout, rlog); err != nil {
+		logger.Fatal("http: server error", "err", err) // copied to the Redis log by the sink
+	}
+	// 7) Release resources: background tasks stop, then buffered spans, the DB pool and Redis (last; the log uses it).
+	sqlDB, err := db.DB()
+	if err != nil {
+		logger.Fatal("shutdown: database handle", "err", err)
 	}
-	// 6) Start HTTP server on configured port; fatal if it fails to bind.
-	if err := r.Run(":" + cfg.HTTPPort); err != nil {
-		log.Fatal(err) // Stop the process if server fails to start.
+	closers = append(closers,
+		closer{"database", sqlDB.Close},
+		closer{"redis", rdb.Close},
+	)
+	logger.SetSink(nil) // the Redis client closes below; stdout only from here
+	if err := closeAll(&background, rlog, closers...); err != nil {
+		slog.Error("shutdown: close", "err", err)
 	}
+	slog.Info("shutdown: done")
 }
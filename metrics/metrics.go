@@ -0,0 +1,133 @@
+// Package metrics exposes Prometheus metrics at /metrics: HTTP traffic (recorded by
+// middlewares.Metrics), user cache effectiveness (recorded by the user service), repository
+// calls (recorded by the instrumented repositories) and Redis commands (recorded by
+// utils/redishook), plus the standard Go runtime and process collectors.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Metrics owns a private registry, so tests and multiple instances don't collide on the
+// global default one. All methods are safe on a nil *Metrics (metrics disabled).
+type Metrics struct {
+	registry      *prometheus.Registry
+	requests      *prometheus.CounterVec   // http_requests_total{method,route,status}
+	duration      *prometheus.HistogramVec // http_request_duration_seconds{method,route,status}
+	inFlight      *prometheus.GaugeVec     // http_requests_in_flight{method,route}
+	cache         *prometheus.CounterVec   // cache_lookups_total{cache,result}
+	repoCalls     *prometheus.CounterVec   // repository_calls_total{repository,method,result}
+	repoDuration  *prometheus.HistogramVec // repository_call_duration_seconds{repository,method}
+	redisCalls    *prometheus.CounterVec   // redis_commands_total{command,result}
+	redisDuration *prometheus.HistogramVec // redis_command_duration_seconds{command}
+}
+
+// New registers every collector.
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "HTTP requests handled, by method, route template and status code.",
+		}, []string{"method", "route", "status"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "HTTP request latency, by method, route template and status code.",
+			Buckets: []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+		}, []string{"method", "route", "status"}),
+		inFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "http_requests_in_flight",
+			Help: "HTTP requests currently being served, by method and route template.",
+		}, []string{"method", "route"}),
+		cache: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "cache_lookups_total",
+			Help: "Cache lookups by cache name and result (hit|miss).",
+		}, []string{"cache", "result"}),
+		repoCalls: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "repository_calls_total",
+			Help: "Repository method calls by repository, method and result (ok|not_found|error).",
+		}, []string{"repository", "method", "result"}),
+		repoDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "repository_call_duration_seconds",
+			Help:    "Repository method latency, including every query the method runs, by repository and method.",
+			Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		}, []string{"repository", "method"}),
+		redisCalls: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "redis_commands_total",
+			Help: "Redis commands by command name (\"pipeline\" for a pipeline) and result (ok|error; a missing key is ok).",
+		}, []string{"command", "result"}),
+		redisDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "redis_command_duration_seconds",
+			Help:    "Redis command latency including client retries, by command name.",
+			Buckets: []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+		}, []string{"command"}),
+	}
+	m.registry.MustRegister(m.requests, m.duration, m.inFlight, m.cache, m.repoCalls, m.repoDuration, m.redisCalls, m.redisDuration,
+		collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	return m
+}
+
+// Handler serves the text exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// RequestStarted counts a request as in flight. route is the template ("/api/v1/users/:id"),
+// never the raw path, to keep label cardinality bounded.
+func (m *Metrics) RequestStarted(method, route string) {
+	if m == nil {
+		return
+	}
+	m.inFlight.WithLabelValues(method, route).Inc()
+}
+
+// RequestFinished records a request started with RequestStarted.
+func (m *Metrics) RequestFinished(method, route string, status int, elapsed time.Duration) {
+	if m == nil {
+		return
+	}
+	code := strconv.Itoa(status)
+	m.inFlight.WithLabelValues(method, route).Dec()
+	m.requests.WithLabelValues(method, route, code).Inc()
+	m.duration.WithLabelValues(method, route, code).Observe(elapsed.Seconds())
+}
+
+// CacheHit counts a lookup served from the named cache.
+func (m *Metrics) CacheHit(cache string) {
+	if m == nil {
+		return
+	}
+	m.cache.WithLabelValues(cache, "hit").Inc()
+}
+
+// CacheMiss counts a lookup that had to go to the source (absent, undecodable or Redis error).
+func (m *Metrics) CacheMiss(cache string) {
+	if m == nil {
+		return
+	}
+	m.cache.WithLabelValues(cache, "miss").Inc()
+}
+
+// RepositoryCall records one repository method call; result is ok|not_found|error.
+func (m *Metrics) RepositoryCall(repository, method, result string, elapsed time.Duration) {
+	if m == nil {
+		return
+	}
+	m.repoCalls.WithLabelValues(repository, method, result).Inc()
+	m.repoDuration.WithLabelValues(repository, method).Observe(elapsed.Seconds())
+}
+
+// RedisCommand records one Redis command (or pipeline); result is ok|error.
+func (m *Metrics) RedisCommand(command, result string, elapsed time.Duration) {
+	if m == nil {
+		return
+	}
+	m.redisCalls.WithLabelValues(command, result).Inc()
+	m.redisDuration.WithLabelValues(command).Observe(elapsed.Seconds())
+}
@@ -0,0 +1,76 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func scrape(t *testing.T, m *Metrics) string {
+	t.Helper()
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	b, _ := io.ReadAll(w.Body)
+	return string(b)
+}
+
+func TestMetrics_RequestsAndCache(t *testing.T) {
+	m := New()
+	m.RequestStarted("GET", "/api/v1/users/:id")
+	assert.Contains(t, scrape(t, m), `http_requests_in_flight{method="GET",route="/api/v1/users/:id"} 1`)
+
+	m.RequestFinished("GET", "/api/v1/users/:id", 404, 30*time.Millisecond)
+	m.CacheHit("user")
+	m.CacheHit("user")
+	m.CacheMiss("user")
+
+	out := scrape(t, m)
+	assert.Contains(t, out, `http_requests_in_flight{method="GET",route="/api/v1/users/:id"} 0`)
+	assert.Contains(t, out, `http_requests_total{method="GET",route="/api/v1/users/:id",status="404"} 1`)
+	assert.Contains(t, out, `http_request_duration_seconds_bucket{method="GET",route="/api/v1/users/:id",status="404",le="0.05"} 1`)
+	assert.Contains(t, out, `cache_lookups_total{cache="user",result="hit"} 2`)
+	assert.Contains(t, out, `cache_lookups_total{cache="user",result="miss"} 1`)
+	assert.Contains(t, out, "go_goroutines")
+}
+
+func TestMetrics_NilIsNoop(t *testing.T) {
+	var m *Metrics
+	assert.NotPanics(t, func() {
+		m.RequestStarted("GET", "/")
+		m.RequestFinished("GET", "/", 200, time.Second)
+		m.CacheHit("user")
+		m.CacheMiss("user")
+		m.RepositoryCall("user", "FindByID", "ok", time.Millisecond)
+		m.RedisCommand("get", "ok", time.Millisecond)
+	})
+}
+
+func TestMetrics_RepositoryCalls(t *testing.T) {
+	m := New()
+	m.RepositoryCall("user", "FindByID", "ok", 3*time.Millisecond)
+	m.RepositoryCall("user", "FindByID", "not_found", time.Millisecond)
+	m.RepositoryCall("user", "List", "error", 2*time.Second)
+
+	out := scrape(t, m)
+	assert.Contains(t, out, `repository_calls_total{method="FindByID",repository="user",result="ok"} 1`)
+	assert.Contains(t, out, `repository_calls_total{method="FindByID",repository="user",result="not_found"} 1`)
+	assert.Contains(t, out, `repository_calls_total{method="List",repository="user",result="error"} 1`)
+	assert.Contains(t, out, `repository_call_duration_seconds_count{method="FindByID",repository="user"} 2`)
+	assert.Contains(t, out, `repository_call_duration_seconds_bucket{method="List",repository="user",le="2.5"} 1`)
+}
+
+func TestMetrics_RedisCommands(t *testing.T) {
+	m := New()
+	m.RedisCommand("get", "ok", 800*time.Microsecond)
+	m.RedisCommand("pipeline", "error", 300*time.Millisecond)
+
+	out := scrape(t, m)
+	assert.Contains(t, out, `redis_commands_total{command="get",result="ok"} 1`)
+	assert.Contains(t, out, `redis_commands_total{command="pipeline",result="error"} 1`)
+	assert.Contains(t, out, `redis_command_duration_seconds_bucket{command="get",le="0.001"} 1`)
+	assert.Contains(t, out, `redis_command_duration_seconds_bucket{command="pipeline",le="0.25"} 0`)
+}
@@ -4,19 +4,45 @@
 package middlewares
 
 import (
+	"context"
+	"errors"
+	"math"
 	"net/http"
 	"strconv" // Convert string claim to int when needed.
+	"strings" // WebSocket subprotocol list.
+	"time"
 
 	"HelmyTask/global" // For the context key to store user ID.
+	"HelmyTask/models" // Role constants.
+	"HelmyTask/utils/jwtkeys" // HS256 secret or RS256 key set.
 
 	"github.com/gin-gonic/gin"     // Gin context/request/response types
 	"github.com/golang-jwt/jwt/v5" // JWT parsing and validation
 )
 
+// TokenRevocations answers whether a token issued at issuedAt was revoked afterwards
+// (e.g. by a password change); implemented by utils/revocation.Store.
+type TokenRevocations interface {
+	Revoked(ctx context.Context, uid uint, issuedAt time.Time) (bool, error)
+}
+
 // Auth returns a Gin middleware that validates "Authorization: Bearer <token>"
 // and injects the user ID ("uid") into the request context if the token is valid.
 func Auth(jwtSecret string) gin.HandlerFunc {
-	return func(c *gin.Context) { // Middleware function closure captures jwtSecret. 
+	return AuthWithKeys(jwtkeys.NewHMAC(jwtSecret), nil)
+}
+
+// AuthWithRevocation is Auth plus a revocation check on the token's "iat" claim.
+// A nil revocations disables the check; a revocation store error fails open so a Redis
+// outage doesn't log everybody out.
+func AuthWithRevocation(jwtSecret string, revocations TokenRevocations) gin.HandlerFunc {
+	return AuthWithKeys(jwtkeys.NewHMAC(jwtSecret), revocations)
+}
+
+// AuthWithKeys verifies tokens against a key set (HS256 secret, or the active RS256 public
+// keys selected by the token's "kid"), plus the optional revocation check.
+func AuthWithKeys(keys *jwtkeys.KeySet, revocations TokenRevocations) gin.HandlerFunc {
+	return func(c *gin.Context) { // Middleware function closure captures the key set.
 		auth := c.GetHeader("Authorization") //read authorization header from request
 		// Quick check : must start with "bearer" and be long 
 		if len(auth) < 8 || auth[:7] != "Bearer " {
@@ -25,31 +51,81 @@ func Auth(jwtSecret string) gin.HandlerFunc {
 		}
 		raw := auth[7:] //extract the token substring after "Bearer"
 
-		// parse and validate token signature using the shared secret
-		t, err := jwt.Parse(raw, func(token *jwt.Token) (interface{}, error) {
-			return []byte(jwtSecret), nil 
-		})
-		//reject with 401 if the token is not valid or if an error exist 
-		if err != nil || !t.Valid {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
+		uid, role, err := VerifyToken(c.Request.Context(), keys, revocations, raw)
+		if err != nil { // reject with 401; the message says which check failed
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 			return
 		}
-		//we expect MapClaims (string any map) to exract tored fields 
-		claims, ok := t.Claims.(jwt.MapClaims)
-		if !ok {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid claims"})
-			return
+		if uid != nil {
+			c.Set(global.CtxUserIDKey, *uid)
+		}
+		c.Set(global.CtxUserRoleKey, role) // role claim drives the policy engine
+		c.Next() // Continue to the actual handler. 
+	}
+}
+
+// Errors of VerifyToken, worded like the 401 bodies of Auth.
+var (
+	ErrInvalidToken  = errors.New("invalid token")
+	ErrInvalidClaims = errors.New("invalid claims")
+	ErrTokenRevoked  = errors.New("token revoked")
+)
+
+// VerifyToken checks a raw bearer token the way AuthWithKeys does, for transports other than
+// Gin (the gRPC API). It returns the user ID from "sub" (nil if it has none usable) and the
+// role from "rol" (models.RoleUser when absent).
+func VerifyToken(ctx context.Context, keys *jwtkeys.KeySet, revocations TokenRevocations, raw string) (*uint, string, error) {
+	// parse and validate the signature; the algorithm is pinned to the key set's
+	t, err := jwt.Parse(raw, keys.Keyfunc, keys.ParserOptions()...)
+	if err != nil || !t.Valid {
+		return nil, "", ErrInvalidToken
+	}
+	//we expect MapClaims (string any map) to exract tored fields 
+	claims, ok := t.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, "", ErrInvalidClaims
+	}
+	// extract subject (user ID) from the claims and normalize its type 
+	var uid *uint
+	switch v := claims["sub"].(type) {
+	case float64: // JSON numbers often decode to float64; cast to uint.
+		id := uint(v)
+		uid = &id
+	case string: // Sometimes IDs may be strings; try to parse.
+		if n, err := strconv.Atoi(v); err == nil {
+			id := uint(n)
+			uid = &id
+		}
+	}
+	// tokens minted before the user's last revocation are dead even if not yet expired
+	if revocations != nil {
+		var id uint
+		if uid != nil {
+			id = *uid
 		}
-		// extract subject (user ID) from the claims and normalize its type 
-		sub := claims["sub"]
-		switch v := sub.(type) {
-		case float64: // JSON numbers often decode to float64; cast to uint.
-			c.Set(global.CtxUserIDKey, uint(v))
-		case string: // Sometimes IDs may be strings; try to parse.
-			if n, err := strconv.Atoi(v); err == nil {
-				c.Set(global.CtxUserIDKey, uint(n))
+		iat, _ := claims["iat"].(float64) // missing iat → epoch → revoked if anything was; may carry milliseconds
+		if revoked, err := revocations.Revoked(ctx, id, time.UnixMilli(int64(math.Round(iat*1000)))); err == nil && revoked {
+			return nil, "", ErrTokenRevoked
+		}
+	}
+	// tokens without a role claim act as plain users
+	if role, ok := claims["rol"].(string); ok && role != "" {
+		return uid, role, nil
+	}
+	return uid, models.RoleUser, nil
+}
+
+// WebSocketBearer lets browsers, which can't set headers on a WebSocket handshake, send the JWT
+// as subprotocols: "Sec-WebSocket-Protocol: bearer, <token>". It copies the token into
+// Authorization for the auth middleware that follows; an Authorization header wins.
+func WebSocketBearer() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			parts := strings.Split(c.GetHeader("Sec-WebSocket-Protocol"), ",")
+			if len(parts) == 2 && strings.TrimSpace(parts[0]) == "bearer" {
+				c.Request.Header.Set("Authorization", "Bearer "+strings.TrimSpace(parts[1]))
 			}
 		}
-		c.Next() // Continue to the actual handler. 
+		c.Next()
 	}
 }
@@ -1,12 +1,15 @@
 package middlewares
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
-	
+	"HelmyTask/utils/jwtkeys"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
@@ -63,3 +66,56 @@ func TestAuth_ValidToken_Passes(t *testing.T) {
 	assert.Equal(t, "ok", w.Body.String())
 }
 
+type stubRevocations struct{ before time.Time }
+
+func (s stubRevocations) Revoked(_ context.Context, _ uint, issuedAt time.Time) (bool, error) {
+	return issuedAt.Before(s.before), nil
+}
+
+func TestAuthWithRevocation_RejectsTokensIssuedBeforeRevocation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	now := time.Now()
+	r := gin.New()
+	r.Use(AuthWithRevocation(testSecret, stubRevocations{before: now.Add(-time.Minute)}))
+	r.GET("/p", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	for _, tc := range []struct {
+		iat  time.Time
+		want int
+	}{
+		{now.Add(-time.Hour), http.StatusUnauthorized},                          // issued before the password change
+		{now, http.StatusOK},                                                    // fresh login afterwards
+		{now.Add(-time.Minute - 300*time.Millisecond), http.StatusUnauthorized}, // milliseconds are kept
+		{now.Add(-time.Minute + 300*time.Millisecond), http.StatusOK},
+	} {
+		claims := jwt.MapClaims{"sub": 1, "exp": now.Add(time.Hour).Unix(), "iat": float64(tc.iat.UnixMilli()) / 1000}
+		signed, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testSecret))
+		req := httptest.NewRequest(http.MethodGet, "/p", nil)
+		req.Header.Set("Authorization", "Bearer "+signed)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, tc.want, w.Code)
+	}
+}
+
+func TestAuthWithKeys_RS256(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	keys, _ := jwtkeys.NewRSA("k1", key, nil)
+
+	r := gin.New()
+	r.Use(AuthWithKeys(keys, nil))
+	r.GET("/p", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	good, _ := keys.Sign(jwt.MapClaims{"sub": 1, "exp": time.Now().Add(time.Minute).Unix()})
+	forged, _ := jwtkeys.NewHMAC(testSecret).Sign(jwt.MapClaims{"sub": 1, "exp": time.Now().Add(time.Minute).Unix()})
+
+	for tok, want := range map[string]int{good: http.StatusOK, forged: http.StatusUnauthorized} {
+		req := httptest.NewRequest(http.MethodGet, "/p", nil)
+		req.Header.Set("Authorization", "Bearer "+tok)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, want, w.Code)
+	}
+}
@@ -0,0 +1,58 @@
+package middlewares
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBodyLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(BodyLimit(func(c *gin.Context) int64 {
+		if c.FullPath() == "/upload" {
+			return 64
+		}
+		return 8
+	}))
+	echo := func(c *gin.Context) {
+		b, err := io.ReadAll(c.Request.Body)
+		assert.NoError(t, err)
+		c.String(http.StatusOK, string(b))
+	}
+	r.POST("/json", echo)
+	r.POST("/upload", echo)
+
+	send := func(path, body string, chunked bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		if chunked {
+			req.ContentLength = -1 // length not declared
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := send("/json", "12345678", false)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "12345678", w.Body.String())
+
+	w = send("/json", "123456789", false)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Contains(t, w.Body.String(), "limit 8 bytes")
+
+	w = send("/json", "123456789", true)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code, "caught without a Content-Length too")
+
+	w = send("/json", "1234", true)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "1234", w.Body.String(), "buffered body is handed on intact")
+
+	w = send("/upload", strings.Repeat("x", 64), false)
+	assert.Equal(t, http.StatusOK, w.Code, "per-route cap")
+}
@@ -0,0 +1,58 @@
+// CORS for trusted browser origins (see utils/origins).
+
+package middlewares
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OriginChecker is satisfied by *origins.Registry.
+type OriginChecker interface {
+	Allowed(ctx context.Context, origin string) bool
+}
+
+// Headers a browser client may send cross-origin (and read back).
+var (
+	corsAllowHeaders  = strings.Join([]string{"Authorization", "Content-Type", "X-API-Key", "Idempotency-Key"}, ", ")
+	corsExposeHeaders = strings.Join([]string{"Retry-After", "Content-Disposition"}, ", ")
+)
+
+// CORS answers preflights and adds CORS headers for trusted origins. Requests from other origins
+// get no CORS headers (the browser then hides the response); untrusted preflights get 403.
+// Credentials are allowed, so the session cookie works cross-origin for trusted frontends.
+func CORS(origins OriginChecker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" || origins == nil {
+			c.Next()
+			return
+		}
+		c.Writer.Header().Add("Vary", "Origin") // responses differ per origin; keep shared caches honest
+		allowed := origins.Allowed(c.Request.Context(), origin)
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+		if !allowed {
+			if preflight {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			c.Next()
+			return
+		}
+		h := c.Writer.Header()
+		h.Set("Access-Control-Allow-Origin", origin)
+		h.Set("Access-Control-Allow-Credentials", "true")
+		if preflight {
+			h.Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE")
+			h.Set("Access-Control-Allow-Headers", corsAllowHeaders)
+			h.Set("Access-Control-Max-Age", "600")
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+		h.Set("Access-Control-Expose-Headers", corsExposeHeaders)
+		c.Next()
+	}
+}
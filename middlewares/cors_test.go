@@ -0,0 +1,100 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type fixedOrigins map[string]bool
+
+func (f fixedOrigins) Allowed(_ context.Context, origin string) bool { return f[origin] }
+
+func newCORSRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(CORS(fixedOrigins{"https://app.example.com": true}))
+	r.POST("/x", func(c *gin.Context) { c.Status(http.StatusOK) })
+	return r
+}
+
+func TestCORS_TrustedOrigin(t *testing.T) {
+	r := newCORSRouter()
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodOptions, "/x", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	req.Header.Set("Access-Control-Request-Method", "POST")
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "https://app.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+	assert.Contains(t, w.Header().Get("Access-Control-Allow-Headers"), "Authorization")
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/x", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "https://app.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "Origin", w.Header().Get("Vary"))
+}
+
+func TestCORS_UntrustedOrigin(t *testing.T) {
+	r := newCORSRouter()
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodOptions, "/x", nil)
+	req.Header.Set("Origin", "https://evil.io")
+	req.Header.Set("Access-Control-Request-Method", "POST")
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/x", nil)
+	req.Header.Set("Origin", "https://evil.io")
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code) // served, but the browser won't expose it
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestCSRF(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(CSRF(fixedOrigins{"https://app.example.com": true}))
+	r.POST("/x", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	cases := []struct {
+		name    string
+		cookie  bool
+		origin  string
+		referer string
+		want    int
+	}{
+		{"no cookie (bearer/API key)", false, "https://evil.io", "", http.StatusOK},
+		{"trusted origin", true, "https://app.example.com", "", http.StatusOK},
+		{"same origin", true, "http://api.local", "", http.StatusOK},
+		{"trusted referer", true, "", "https://app.example.com/settings", http.StatusOK},
+		{"untrusted origin", true, "https://evil.io", "", http.StatusForbidden},
+		{"no origin or referer", true, "", "", http.StatusForbidden},
+	}
+	for _, tc := range cases {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "http://api.local/x", nil)
+		if tc.cookie {
+			req.AddCookie(&http.Cookie{Name: SessionCookieName, Value: "s"})
+		}
+		if tc.origin != "" {
+			req.Header.Set("Origin", tc.origin)
+		}
+		if tc.referer != "" {
+			req.Header.Set("Referer", tc.referer)
+		}
+		r.ServeHTTP(w, req)
+		assert.Equal(t, tc.want, w.Code, tc.name)
+	}
+}
@@ -0,0 +1,39 @@
+// Deprecation/Sunset/Link response headers from the API changelog (see the changelog package).
+
+package middlewares
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"HelmyTask/changelog"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Deprecation marks responses from deprecated routes: "Deprecation: @<unix>" (RFC 9745),
+// "Sunset: <HTTP-date>" (RFC 8594) when a removal date is set, and Link headers pointing at
+// the migration notes (rel="deprecation") and the replacement (rel="successor-version").
+// Headers are set before the handler runs, so they're on every response, errors included.
+func Deprecation(reg *changelog.Registry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		e := reg.Deprecation(c.Request.Method, c.FullPath(), time.Now())
+		if e == nil {
+			c.Next()
+			return
+		}
+		h := c.Writer.Header()
+		h.Set("Deprecation", fmt.Sprintf("@%d", e.DeprecatedAt().Unix()))
+		if !e.SunsetAt().IsZero() {
+			h.Set("Sunset", e.SunsetAt().UTC().Format(http.TimeFormat))
+		}
+		if e.Link != "" {
+			h.Add("Link", fmt.Sprintf(`<%s>; rel="deprecation"; type="text/html"`, e.Link))
+		}
+		if e.Successor != "" {
+			h.Add("Link", fmt.Sprintf(`<%s>; rel="successor-version"`, e.Successor))
+		}
+		c.Next()
+	}
+}
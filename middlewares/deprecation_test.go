@@ -0,0 +1,59 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"HelmyTask/changelog"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeprecation_SetsHeadersOnDeprecatedRoutesOnly(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	reg, err := changelog.Parse([]byte(`
+entries:
+  - date: "2020-01-01"
+    kind: deprecated
+    method: GET
+    path: /users/{id}
+    summary: Use /v2/users/{id}.
+    deprecated: "2020-01-01"
+    sunset: "2020-07-01"
+    link: https://example.com/migrate
+    successor: /v2/users/{id}
+`))
+	require.NoError(t, err)
+	r := gin.New()
+	r.Use(Deprecation(reg))
+	r.GET("/users/:id", func(c *gin.Context) { c.Status(http.StatusNotFound) })
+	r.POST("/users/:id", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/42", nil))
+	assert.Equal(t, "@1577836800", w.Header().Get("Deprecation"))
+	assert.Equal(t, "Wed, 01 Jul 2020 00:00:00 GMT", w.Header().Get("Sunset"))
+	assert.Equal(t, []string{
+		`<https://example.com/migrate>; rel="deprecation"; type="text/html"`,
+		`</v2/users/{id}>; rel="successor-version"`,
+	}, w.Header().Values("Link"))
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/users/42", nil))
+	assert.Empty(t, w.Header().Get("Deprecation"), "other methods on the same path are unaffected")
+}
+
+func TestDeprecation_NilRegistryIsNoop(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(Deprecation(nil))
+	r.GET("/x", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/x", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("Deprecation"))
+}
@@ -0,0 +1,30 @@
+// Experiment assignments on authenticated responses (see the experiments package).
+
+package middlewares
+
+import (
+	"HelmyTask/experiments"
+	"HelmyTask/global"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Experiments tells the client which variants the authenticated user is in, as
+// "X-Experiments: checkout=green, onboarding=short", and records the exposure. It runs after
+// auth; requests without a user ID (none yet, or an API key without one) pass untouched.
+func Experiments(reg *experiments.Registry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		v, ok := c.Get(global.CtxUserIDKey)
+		uid, _ := v.(uint)
+		if !ok || uid == 0 {
+			c.Next()
+			return
+		}
+		as := reg.Assign(uid)
+		if len(as) > 0 {
+			c.Header("X-Experiments", experiments.Header(as))
+			reg.Expose(c.Request.Context(), uid, as)
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,31 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"HelmyTask/experiments"
+	"HelmyTask/global"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExperiments_HeaderOnlyForUsers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	reg, err := experiments.New([]experiments.Experiment{{Name: "checkout", Variants: []experiments.Variant{{Name: "control", Weight: 1}, {Name: "green", Weight: 1}}}}, nil)
+	require.NoError(t, err)
+	r := gin.New()
+	r.GET("/me", func(c *gin.Context) { c.Set(global.CtxUserIDKey, uint(7)) }, Experiments(reg), func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.GET("/anon", Experiments(reg), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/me", nil))
+	assert.Equal(t, "checkout="+reg.Assign(7)[0].Variant, w.Header().Get("X-Experiments"))
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/anon", nil))
+	assert.Empty(t, w.Header().Get("X-Experiments"))
+}
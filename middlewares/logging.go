@@ -1,25 +1,33 @@
-// simple structured request logging
+// structured request logging (JSON via log/slog; see the logger package)
 
 package middlewares
 
 import (
-	"log"
+	"log/slog"
+	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
-//RequestLogger prints method , path , status and duration for each request 
-//simple and effective for local dev ;;; in prod you might use structured logging 
+//RequestLogger logs method , route , path , status and duration for each request as one JSON line
+//(info level ;; 5xx at error so they also reach the Redis log sink)
 func RequestLogger() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now() //erecord start time
 		path := c.Request.URL.Path //// Keep the path for logging (useful after c.Next()).
 		c.Next() // Run downstream handlers/middlewares.
-		log.Printf("%s %s %d %s",  //log  linee
-		c.Request.Method, //http method (get , POST ,etc ...)
-		 path, //request path 
-		  c.Writer.Status(), //final status code
-		  time.Since(start)) //elapsed time
+		status := c.Writer.Status() //final status code
+		level := slog.LevelInfo
+		if status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		slog.Log(c.Request.Context(), level, "request",
+			"method", c.Request.Method, //http method (get , POST ,etc ...)
+			"route", c.FullPath(), //route template ("" when nothing matched)
+			"path", path, //request path
+			"status", status,
+			"latency_ms", float64(time.Since(start).Microseconds())/1000, //elapsed time
+			"client_ip", c.ClientIP())
 	}
 }
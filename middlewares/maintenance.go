@@ -0,0 +1,36 @@
+// Maintenance mode: answer 503 while an admin has the API switched off.
+
+package middlewares
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maintenanceRetryAfter is the Retry-After hint (seconds) sent during maintenance.
+const maintenanceRetryAfter = "120"
+
+// Maintenance answers 503 + Retry-After while state reports maintenance on. Paths starting
+// with one of exempt still work, so admins can log in and switch it off again.
+func Maintenance(state func() (on bool, message string), exempt ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		on, msg := state()
+		if !on {
+			c.Next()
+			return
+		}
+		for _, p := range exempt {
+			if strings.HasPrefix(c.Request.URL.Path, p) {
+				c.Next()
+				return
+			}
+		}
+		if msg == "" {
+			msg = "down for maintenance"
+		}
+		c.Header("Retry-After", maintenanceRetryAfter)
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": msg, "maintenance": true})
+	}
+}
@@ -0,0 +1,33 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaintenance(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	on := false
+	r := gin.New()
+	r.Use(Maintenance(func() (bool, string) { return on, "" }, "/admin/"))
+	r.GET("/users", func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.GET("/admin/settings", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	on = true
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, maintenanceRetryAfter, w.Header().Get("Retry-After"))
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/settings", nil))
+	assert.Equal(t, http.StatusOK, w.Code, "admins can switch it off again")
+}
@@ -0,0 +1,40 @@
+// HTTP instrumentation for Prometheus (see the metrics package).
+
+package middlewares
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HTTPMetrics is satisfied by *metrics.Metrics.
+type HTTPMetrics interface {
+	RequestStarted(method, route string)
+	RequestFinished(method, route string, status int, elapsed time.Duration)
+}
+
+// unmatchedRoute labels requests no route matched (404s, scanners), so arbitrary paths
+// can't create new time series.
+const unmatchedRoute = "unmatched"
+
+// Metrics records request count, latency and in-flight requests by method, route template and
+// status. The route is known before the handlers run (Gin routes first), so in-flight requests
+// carry it too.
+func Metrics(m HTTPMetrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if m == nil {
+			c.Next()
+			return
+		}
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		method := c.Request.Method
+		start := time.Now()
+		m.RequestStarted(method, route)
+		defer func() { m.RequestFinished(method, route, c.Writer.Status(), time.Since(start)) }()
+		c.Next()
+	}
+}
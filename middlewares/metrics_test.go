@@ -0,0 +1,47 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordedRequest struct {
+	method, route string
+	status        int
+}
+
+type fakeHTTPMetrics struct {
+	inFlight int
+	done     []recordedRequest
+}
+
+func (f *fakeHTTPMetrics) RequestStarted(string, string) { f.inFlight++ }
+
+func (f *fakeHTTPMetrics) RequestFinished(method, route string, status int, _ time.Duration) {
+	f.inFlight--
+	f.done = append(f.done, recordedRequest{method, route, status})
+}
+
+func TestMetrics_LabelsByRouteTemplate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rec := &fakeHTTPMetrics{}
+	r := gin.New()
+	r.Use(Metrics(rec))
+	var seen int
+	r.GET("/users/:id", func(c *gin.Context) { seen = rec.inFlight; c.Status(http.StatusNoContent) })
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/42", nil))
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/wp-admin.php", nil))
+
+	assert.Equal(t, 1, seen, "in flight while the handler runs")
+	assert.Equal(t, 0, rec.inFlight)
+	assert.Equal(t, []recordedRequest{
+		{"GET", "/users/:id", http.StatusNoContent},
+		{"GET", unmatchedRoute, http.StatusNotFound}, // raw paths never become labels
+	}, rec.done)
+}
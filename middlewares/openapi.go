@@ -0,0 +1,90 @@
+// Runtime request/response checks against docs/swagger.yaml (see utils/openapi).
+
+package middlewares
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"HelmyTask/utils/openapi"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxValidatedBody caps how much of a body is read for validation.
+const maxValidatedBody = 1 << 20
+
+// OpenAPI rejects requests that don't match the documented operation with a 422 listing every
+// mismatch. Paths the spec doesn't describe pass through untouched. With checkResponses, JSON
+// responses are also compared with the spec and mismatches logged; that buffers a copy of each
+// body, so it's meant for debug/staging.
+func OpenAPI(spec *openapi.Spec, checkResponses bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if spec == nil {
+			c.Next()
+			return
+		}
+		op, params := spec.Find(c.Request.Method, c.Request.URL.Path)
+		if op == nil {
+			c.Next()
+			return
+		}
+
+		var body []byte
+		if c.Request.Body != nil && c.ContentType() == "application/json" {
+			var err error
+			body, err = io.ReadAll(io.LimitReader(c.Request.Body, maxValidatedBody+1))
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "could not read body"})
+				return
+			}
+			if len(body) > maxValidatedBody {
+				c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"error": "body too large"})
+				return
+			}
+			c.Request.Body = io.NopCloser(bytes.NewReader(body)) // handler binds it again
+		}
+		if v := spec.ValidateRequest(op, params, c.Request, body); len(v) > 0 {
+			c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"error": "request does not match the API schema", "violations": v})
+			return
+		}
+
+		if !checkResponses {
+			c.Next()
+			return
+		}
+		rec := &teeWriter{ResponseWriter: c.Writer}
+		c.Writer = rec
+		c.Next()
+		if v := spec.ValidateResponse(op, rec.Status(), rec.Header().Get("Content-Type"), rec.buf.Bytes()); len(v) > 0 {
+			slog.Warn("openapi: response does not match the spec", "route", c.Request.Method+" "+c.FullPath(), "status", rec.Status(), "violations", v)
+		}
+	}
+}
+
+// teeWriter keeps a copy of the response body (up to maxValidatedBody) while writing it through.
+type teeWriter struct {
+	gin.ResponseWriter
+	buf bytes.Buffer
+}
+
+func (w *teeWriter) Write(b []byte) (int, error) {
+	w.keep(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *teeWriter) WriteString(s string) (int, error) {
+	w.keep([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *teeWriter) keep(b []byte) {
+	if room := maxValidatedBody - w.buf.Len(); room > 0 {
+		if len(b) > room {
+			b = b[:room]
+		}
+		w.buf.Write(b)
+	}
+}
@@ -0,0 +1,40 @@
+// enforces role permissions from the policy engine on top of Auth.
+
+package middlewares
+
+import (
+	"net/http"
+
+	"HelmyTask/global" // For the context key holding the caller's role.
+	"HelmyTask/policy" // Role -> permission table.
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequirePermission returns a middleware that lets the request through only when
+// the authenticated role (set by Auth) holds the given permission, and, for an API key
+// limited to scopes (set by APIKeyAuth), the permission is one of them. Must run after Auth.
+func RequirePermission(p policy.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := c.GetString(global.CtxUserRoleKey) // Empty when the token carried no role.
+		if !policy.Allowed(role, p) || !inScope(c, p) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+			return // caller is authenticated but not allowed
+		}
+		c.Next()
+	}
+}
+
+// inScope reports whether the caller's API key scopes (if any) include p.
+func inScope(c *gin.Context, p policy.Permission) bool {
+	v, ok := c.Get(global.CtxScopesKey)
+	if !ok {
+		return true // JWT/session, or a key without scopes
+	}
+	for _, s := range v.([]string) {
+		if s == string(p) {
+			return true
+		}
+	}
+	return false
+}
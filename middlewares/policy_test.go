@@ -0,0 +1,52 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"HelmyTask/global"
+	"HelmyTask/models"
+	"HelmyTask/policy"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequirePermission_SupportIsReadOnly(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	// stand-in for Auth: inject the support role
+	r.Use(func(c *gin.Context) { c.Set(global.CtxUserRoleKey, models.RoleSupport); c.Next() })
+	r.GET("/users", RequirePermission(policy.UsersRead), func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.DELETE("/users/1", RequirePermission(policy.UsersDelete), func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/users/1", nil))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestRequirePermission_KeyScopes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	// stand-in for APIKeyAuth: an admin's key limited to reading users
+	r.Use(func(c *gin.Context) {
+		c.Set(global.CtxUserRoleKey, models.RoleAdmin)
+		c.Set(global.CtxScopesKey, []string{string(policy.UsersRead)})
+		c.Next()
+	})
+	r.GET("/users", RequirePermission(policy.UsersRead), func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.DELETE("/users/1", RequirePermission(policy.UsersDelete), func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/users/1", nil))
+	assert.Equal(t, http.StatusForbidden, w.Code, "the role allows it, the key's scopes don't")
+}
@@ -0,0 +1,57 @@
+// per-client rate limiting backed by Redis, answering 429 with Retry-After.
+
+package middlewares
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"HelmyTask/utils/ratelimit"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimiter is satisfied by *ratelimit.Limiter (an interface keeps the middleware testable).
+type RateLimiter interface {
+	Allow(ctx context.Context, key string, rule ratelimit.Rule) (bool, time.Duration, error)
+}
+
+// RateLimitResetter is satisfied by *ratelimit.Limiter; it refills buckets (support tooling).
+type RateLimitResetter interface {
+	Reset(ctx context.Context, keys ...string) error
+}
+
+// RateLimitKey is the bucket of a client IP within a group.
+func RateLimitKey(group, ip string) string { return group + ":" + ip }
+
+// RateLimit limits requests per client IP within a named group (e.g. "auth").
+// Redis errors fail open: an outage of the limiter must not take the API down with it.
+func RateLimit(l RateLimiter, group string, rule ratelimit.Rule) gin.HandlerFunc {
+	return RateLimitFunc(l, group, func() ratelimit.Rule { return rule })
+}
+
+// RateLimitFunc is RateLimit with the rule looked up per request (runtime settings).
+func RateLimitFunc(l RateLimiter, group string, rule func() ratelimit.Rule) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if l == nil {
+			c.Next()
+			return
+		}
+		ok, wait, err := l.Allow(c.Request.Context(), RateLimitKey(group, c.ClientIP()), rule())
+		if err != nil {
+			slog.Warn("ratelimit: limiter unavailable, allowing request", "group", group, "err", err)
+			c.Next()
+			return
+		}
+		if !ok {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds())))) // whole seconds, at least 1
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "rate limit exceeded"})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,38 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"HelmyTask/utils/ratelimit"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// countingLimiter allows the first n calls.
+type countingLimiter struct{ n int }
+
+func (l *countingLimiter) Allow(context.Context, string, ratelimit.Rule) (bool, time.Duration, error) {
+	l.n--
+	return l.n >= 0, 1500 * time.Millisecond, nil
+}
+
+func TestRateLimit_429WithRetryAfter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(RateLimit(&countingLimiter{n: 1}, "auth", ratelimit.Rule{RequestsPerMinute: 1}))
+	r.GET("/p", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/p", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/p", nil))
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "2", w.Header().Get("Retry-After"))
+}
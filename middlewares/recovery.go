@@ -3,10 +3,16 @@
 package middlewares
 
 import (
-	"log"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"runtime/debug"
+
+	"HelmyTask/errreport"
+	"HelmyTask/global"
 
 	"github.com/gin-gonic/gin" //gin context and middleware support 
+	"go.opentelemetry.io/otel/trace"
 )
 
 
@@ -20,7 +26,12 @@ func Recovery() gin.HandlerFunc {
 		//defer a function that recovers from panic if one happens during c.Next()
 		defer func() {
 			if r := recover(); r != nil { // if r is not nill , a panic occurred
-				log.Printf("[panic] %v", r) //logthe panic valuee
+				route, stack := c.Request.Method+" "+c.FullPath(), string(debug.Stack())
+				slog.Error("panic recovered", "panic", fmt.Sprint(r), "route", route, "stack", stack) //logthe panic valuee + where
+				uid, _ := c.Get(global.CtxUserIDKey) // set by Auth when the panic came after it
+				id, _ := uid.(uint)
+				errreport.Report(errreport.Event{Level: "fatal", Message: "panic recovered", Error: fmt.Sprint(r),
+					RequestID: requestID(c), UID: id, Route: route, Stack: stack}) // no-op unless a reporter (Sentry) is set
 				c.AbortWithStatusJSON(http.StatusInternalServerError, //return 500 json 
 					gin.H{"error": "internal error"}) 
 			}
@@ -28,3 +39,15 @@ func Recovery() gin.HandlerFunc {
 		c.Next() // proceed to subsequent handlers ;; if one panics , defer above will handle it 
 	}
 }
+
+// requestID identifies the request for error reports: the caller's X-Request-Id (e.g. from a
+// load balancer) or else the trace ID, which clients also get back in X-Trace-Id.
+func requestID(c *gin.Context) string {
+	if id := c.GetHeader("X-Request-Id"); id != "" && len(id) <= 128 {
+		return id
+	}
+	if sc := trace.SpanContextFromContext(c.Request.Context()); sc.HasTraceID() {
+		return sc.TraceID().String()
+	}
+	return ""
+}
@@ -5,7 +5,8 @@ import (
 	"net/http/httptest"
 	"testing"
 
-
+	"HelmyTask/errreport"
+	"HelmyTask/global"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -28,3 +29,37 @@ func TestRecovery_PanicReturns500(t *testing.T) {
 	assert.Contains(t, w.Body.String(), "internal error")
 }
 
+
+type recordingReporter struct{ events []errreport.Event }
+
+func (r *recordingReporter) Report(e errreport.Event) { r.events = append(r.events, e) }
+
+func TestRecovery_ReportsPanic(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rep := &recordingReporter{}
+	errreport.Set(rep)
+	defer errreport.Set(nil)
+
+	r := gin.New()
+	r.Use(Recovery())
+	r.GET("/boom/:id", func(c *gin.Context) {
+		c.Set(global.CtxUserIDKey, uint(42))
+		panic("kaboom")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/boom/1", nil)
+	req.Header.Set("X-Request-Id", "req-123")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	if assert.Len(t, rep.events, 1) {
+		e := rep.events[0]
+		assert.Equal(t, "fatal", e.Level)
+		assert.Equal(t, "kaboom", e.Error)
+		assert.Equal(t, "req-123", e.RequestID)
+		assert.Equal(t, uint(42), e.UID)
+		assert.Equal(t, "GET /boom/:id", e.Route)
+		assert.Contains(t, e.Stack, "goroutine")
+	}
+}
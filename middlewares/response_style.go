@@ -0,0 +1,98 @@
+// Per-version JSON shape: envelope and field naming (see utils/jsonstyle).
+
+package middlewares
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"HelmyTask/utils/jsonstyle"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ResponseStyle reshapes JSON bodies into style: requests are converted back to the native
+// snake_case before handlers bind them, and JSON responses are buffered and rewritten once
+// the handler is done. Other content types (CSV exports, files) stream through untouched.
+// Paths starting with one of exempt (third-party callbacks) keep the native shape. The native
+// style installs a pass-through.
+func ResponseStyle(style jsonstyle.Style, exempt ...string) gin.HandlerFunc {
+	if style.Native() {
+		return func(c *gin.Context) { c.Next() }
+	}
+	return func(c *gin.Context) {
+		for _, p := range exempt {
+			if strings.HasPrefix(c.Request.URL.Path, p) {
+				c.Next()
+				return
+			}
+		}
+		if style.Naming == jsonstyle.CamelCase && c.Request.Body != nil && c.ContentType() == "application/json" {
+			body, err := io.ReadAll(c.Request.Body)
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "could not read body"})
+				return
+			}
+			// Keep what the client sent: a signed API key request is signed over it (see APIKeyAuth).
+			c.Set(rawBodyKey, body)
+			if native, err := style.Request(body); err == nil { // invalid JSON goes on as-is; binding reports it
+				body = native
+			}
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+			c.Request.ContentLength = int64(len(body))
+		}
+
+		w := &styleWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		c.Next()
+		if !w.buffering {
+			return
+		}
+		out, err := style.Response(w.Status(), w.buf.Bytes())
+		if err != nil { // not actually JSON; send what the handler wrote
+			slog.Warn("response style: body left as is", "route", c.Request.Method+" "+c.FullPath(), "err", err)
+			out = w.buf.Bytes()
+		}
+		_, _ = w.ResponseWriter.Write(out)
+	}
+}
+
+// styleWriter holds back JSON bodies until the handler returns; anything else is written through.
+type styleWriter struct {
+	gin.ResponseWriter
+	buf       bytes.Buffer
+	decided   bool // content type checked (on the first write)
+	buffering bool
+}
+
+func (w *styleWriter) Write(b []byte) (int, error) {
+	if w.hold() {
+		return w.buf.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *styleWriter) WriteString(s string) (int, error) {
+	if w.hold() {
+		return w.buf.WriteString(s)
+	}
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Flush is a no-op while buffering: the reshaped body can only go out in one piece.
+func (w *styleWriter) Flush() {
+	if !w.buffering {
+		w.ResponseWriter.Flush()
+	}
+}
+
+func (w *styleWriter) hold() bool {
+	if !w.decided {
+		w.decided = true
+		w.buffering = strings.HasPrefix(w.Header().Get("Content-Type"), "application/json")
+	}
+	return w.buffering
+}
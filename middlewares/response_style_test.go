@@ -0,0 +1,53 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"HelmyTask/utils/jsonstyle"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResponseStyle_EnvelopeAndCamelCase(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(ResponseStyle(jsonstyle.Style{Version: "v2", Envelope: true, Naming: jsonstyle.CamelCase}, "/hooks/"))
+	r.PUT("/me", func(c *gin.Context) {
+		var req struct {
+			DateOfBirth string `json:"date_of_birth"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil || req.DateOfBirth == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "bad body"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"date_of_birth": req.DateOfBirth})
+	})
+	r.GET("/export", func(c *gin.Context) { c.Data(http.StatusOK, "text/csv", []byte("created_at\n")) })
+	r.POST("/hooks/email", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"message_id": "m1"}) })
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, "/me", strings.NewReader(`{"dateOfBirth":"2000-01-01"}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"data":{"dateOfBirth":"2000-01-01"},"meta":{"apiVersion":"v2"}}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPut, "/me", strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error":{"message":"bad body"},"meta":{"apiVersion":"v2"}}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/export", nil))
+	assert.Equal(t, "created_at\n", w.Body.String(), "non-JSON passes through")
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/hooks/email", nil))
+	assert.JSONEq(t, `{"message_id":"m1"}`, w.Body.String(), "exempt path keeps the native shape")
+}
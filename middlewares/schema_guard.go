@@ -0,0 +1,27 @@
+// Schema guard: refuse writes while the database schema doesn't match this build.
+
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SchemaGuard answers 503 to writes (anything but GET/HEAD/OPTIONS) while compatible reports
+// false, e.g. after a deploy whose migration didn't apply, or a rollback to a build whose
+// columns were changed since. Reads keep working. nil compatible = pass-through.
+func SchemaGuard(compatible func() bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if compatible == nil || compatible() {
+			c.Next()
+			return
+		}
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "database schema is incompatible with this release; writes are disabled"})
+	}
+}
@@ -0,0 +1,32 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchemaGuard_RefusesWritesOnly(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	compatible := false
+	r := gin.New()
+	r.Use(SchemaGuard(func() bool { return compatible }))
+	r.GET("/users", func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.POST("/users", func(c *gin.Context) { c.Status(http.StatusCreated) })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/users", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	compatible = true
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/users", nil))
+	assert.Equal(t, http.StatusCreated, w.Code)
+}
@@ -0,0 +1,35 @@
+// validates an opaque session cookie (auth_mode: session) and injects uid/role like Auth does.
+
+package middlewares
+
+import (
+	"net/http"
+
+	"HelmyTask/global"
+	"HelmyTask/utils/session"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SessionCookieName is the cookie carrying the opaque session ID.
+const SessionCookieName = "sid"
+
+// SessionAuth returns a middleware that resolves the session cookie through the Redis store.
+// Sliding expiry happens inside store.Get, so active users stay logged in.
+func SessionAuth(store *session.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		sid, err := c.Cookie(SessionCookieName)
+		if err != nil || sid == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing session"})
+			return
+		}
+		d, err := store.Get(c.Request.Context(), sid)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid session"})
+			return
+		}
+		c.Set(global.CtxUserIDKey, d.UserID)
+		c.Set(global.CtxUserRoleKey, d.Role)
+		c.Next()
+	}
+}
@@ -0,0 +1,37 @@
+// request outcome tracking for the SLO report (see the slo package).
+
+package middlewares
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SLORecorder is satisfied by *slo.Tracker.
+type SLORecorder interface {
+	Record(ctx context.Context, status int, latency time.Duration) error
+}
+
+// SLO records every routed request's status and latency. Probes (uptime monitors' /ping too),
+// unmatched paths and the long-lived /ws and log streams are skipped so they don't dilute the numbers. Redis errors are logged
+// and ignored.
+func SLO(rec SLORecorder) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if rec == nil {
+			c.Next()
+			return
+		}
+		start := time.Now()
+		c.Next()
+		switch c.FullPath() {
+		case "", "/healthz", "/readyz", "/ping", "/ws", "/api/v1/admin/logs/stream":
+			return
+		}
+		if err := rec.Record(context.Background(), c.Writer.Status(), time.Since(start)); err != nil {
+			slog.Warn("slo: record failed", "err", err)
+		}
+	}
+}
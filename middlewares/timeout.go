@@ -0,0 +1,36 @@
+// Per-route deadlines and cache headers, declared in the route policy table (routes/policies.go).
+
+package middlewares
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Timeout gives the request context a deadline of d. It is cooperative: handlers and the
+// Redis/DB/HTTP calls they pass c.Request.Context() to give up once it passes, and if the
+// handler then returns without answering, the client gets a 504.
+func Timeout(d time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), d)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) && !c.Writer.Written() {
+			c.AbortWithStatusJSON(http.StatusGatewayTimeout, gin.H{"error": "request timed out"})
+		}
+	}
+}
+
+// CacheControl sets the Cache-Control header before the handler runs; a handler that sets
+// its own (e.g. a public max-age) wins.
+func CacheControl(directive string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Cache-Control", directive)
+		c.Next()
+	}
+}
@@ -0,0 +1,47 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(Timeout(20 * time.Millisecond))
+	r.GET("/slow", func(c *gin.Context) { <-c.Request.Context().Done() }) // gives up, answers nothing
+	r.GET("/fast", func(c *gin.Context) {
+		_, ok := c.Request.Context().Deadline()
+		assert.True(t, ok)
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/slow", nil))
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/fast", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestCacheControl_HandlerWins(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(CacheControl("no-store"))
+	r.GET("/me", func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.GET("/status", func(c *gin.Context) { c.Header("Cache-Control", "public, max-age=15"); c.Status(http.StatusOK) })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/me", nil))
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/status", nil))
+	assert.Equal(t, "public, max-age=15", w.Header().Get("Cache-Control"))
+}
@@ -0,0 +1,48 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracing_ContinuesCallerTraceAndNamesByRoute(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	rec := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+	r := gin.New()
+	r.Use(Tracing(tp))
+	var inHandler trace.SpanContext
+	r.GET("/users/:id", func(c *gin.Context) {
+		inHandler = trace.SpanContextFromContext(c.Request.Context())
+		c.Status(http.StatusServiceUnavailable)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/nope/123", nil))
+
+	spans := rec.Ended()
+	require.Len(t, spans, 2)
+	s := spans[0]
+	assert.Equal(t, "GET /users/:id", s.Name())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", s.SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", s.Parent().SpanID().String())
+	assert.Equal(t, s.SpanContext().SpanID(), inHandler.SpanID(), "handlers see the request span")
+	assert.Equal(t, codes.Error, s.Status().Code)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", w.Header().Get("X-Trace-Id"))
+	assert.Equal(t, "GET", spans[1].Name(), "raw paths never become span names")
+}
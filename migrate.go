@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"HelmyTask/config"
+	"HelmyTask/logger"
+	"HelmyTask/schemacheck"
+
+	"github.com/spf13/cobra"
+)
+
+// newMigrateCmd is `server migrate up|down|status`: the schema migration serve runs at boot, on
+// its own (e.g. as a deploy step before the new replicas start). It needs the database only.
+func newMigrateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Database schema migrations",
+		Args:  usage(cobra.NoArgs),
+		RunE: func(*cobra.Command, []string) error {
+			return usageError{errors.New("want up, down or status")}
+		},
+	}
+
+	up := &cobra.Command{
+		Use:   "up",
+		Short: "Create or update the tables to match the models (what serve does at boot)",
+		Args:  usage(cobra.NoArgs),
+		RunE: func(*cobra.Command, []string) error {
+			logger.Init()
+			db := config.OpenDB(config.Load())
+			if err := config.Migrate(db); err != nil {
+				return err
+			}
+			m := config.LastMigration
+			fmt.Printf("migrated %d models, schema %s\n", m.Models, m.Checksum)
+			for _, t := range m.Created {
+				fmt.Println("created", t)
+			}
+			return nil
+		},
+	}
+
+	var yes bool
+	down := &cobra.Command{
+		Use:   "down --yes",
+		Short: "Drop every migrated table, data included",
+		Args:  usage(cobra.NoArgs),
+		RunE: func(*cobra.Command, []string) error {
+			if !yes {
+				return usageError{errors.New("this drops every table and its data; pass --yes")}
+			}
+			logger.Init()
+			dropped, err := config.MigrateDown(config.OpenDB(config.Load()))
+			for _, t := range dropped {
+				fmt.Println("dropped", t)
+			}
+			return err
+		},
+	}
+	down.Flags().BoolVar(&yes, "yes", false, "confirm dropping the tables")
+
+	status := &cobra.Command{
+		Use:   "status",
+		Short: "Compare the database schema with the models; exits 1 if they don't match or the check fails",
+		Args:  usage(cobra.NoArgs),
+		RunE: func(*cobra.Command, []string) error {
+			logger.Init()
+			schema, err := schemacheck.New(config.OpenDB(config.Load()), config.MigratedModels()...)
+			if err != nil {
+				return err
+			}
+			r := schema.Check(context.Background())
+			j, _ := json.MarshalIndent(r, "", "  ")
+			fmt.Fprintln(os.Stdout, string(j))
+			if !r.Compatible || r.Error != "" { // a failed check keeps New's optimistic verdict
+				return exitCode(1)
+			}
+			return nil
+		},
+	}
+
+	cmd.AddCommand(up, down, status)
+	return cmd
+}
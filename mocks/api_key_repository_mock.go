@@ -0,0 +1,56 @@
+package mocks
+
+import (
+	"time"
+
+	"HelmyTask/core"
+	"HelmyTask/models"
+	"github.com/stretchr/testify/mock"
+)
+
+// APIKeyRepositoryMock is a testify/mock for repositories.APIKeyRepository.
+type APIKeyRepositoryMock struct{ mock.Mock }
+
+func (m *APIKeyRepositoryMock) Create(k *models.APIKey) error {
+	return m.Called(k).Error(0)
+}
+
+func (m *APIKeyRepositoryMock) FindActiveByHash(hash string) (*models.APIKey, error) {
+	args := m.Called(hash)
+	if v := args.Get(0); v != nil {
+		return v.(*models.APIKey), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *APIKeyRepositoryMock) ListByUser(userID core.UserID) ([]models.APIKey, error) {
+	args := m.Called(userID)
+	if v := args.Get(0); v != nil {
+		return v.([]models.APIKey), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *APIKeyRepositoryMock) FindActiveByID(id uint, userID core.UserID) (*models.APIKey, error) {
+	args := m.Called(id, userID)
+	if v := args.Get(0); v != nil {
+		return v.(*models.APIKey), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *APIKeyRepositoryMock) Rename(id uint, userID core.UserID, name string) error {
+	return m.Called(id, userID, name).Error(0)
+}
+
+func (m *APIKeyRepositoryMock) Rotate(id uint, userID core.UserID, next *models.APIKey) error {
+	return m.Called(id, userID, next).Error(0)
+}
+
+func (m *APIKeyRepositoryMock) Revoke(id uint, userID core.UserID) error {
+	return m.Called(id, userID).Error(0)
+}
+
+func (m *APIKeyRepositoryMock) TouchLastUsed(id uint, at time.Time) error {
+	return m.Called(id, at).Error(0)
+}
@@ -0,0 +1,21 @@
+package mocks
+
+import (
+	"HelmyTask/models"
+	"github.com/stretchr/testify/mock"
+)
+
+// AuditRepositoryMock is a testify/mock for repositories.AuditRepository.
+type AuditRepositoryMock struct{ mock.Mock }
+
+func (m *AuditRepositoryMock) Create(e *models.AuditLog) error {
+	return m.Called(e).Error(0)
+}
+
+func (m *AuditRepositoryMock) List(f models.AuditFilter, offset, limit int) ([]models.AuditLog, int64, error) {
+	args := m.Called(f, offset, limit)
+	if v := args.Get(0); v != nil {
+		return v.([]models.AuditLog), args.Get(1).(int64), args.Error(2)
+	}
+	return nil, args.Get(1).(int64), args.Error(2)
+}
@@ -0,0 +1,59 @@
+package mocks
+
+import (
+	"context"
+	"HelmyTask/core"
+	"HelmyTask/models"
+	"github.com/stretchr/testify/mock"
+	"time"
+)
+
+// AuthServiceMock is a testify/mock for services.AuthService.
+// We use this to test the register/login/credential handlers without real business logic.
+// Contexts are accepted but not part of the expectations (.On takes the other arguments).
+type AuthServiceMock struct{ mock.Mock }
+
+func (m *AuthServiceMock) Register(_ context.Context, req models.RegisterRequest) (*models.User, error) {
+	args := m.Called(req)
+	if v := args.Get(0); v != nil {
+		return v.(*models.User), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *AuthServiceMock) Login(_ context.Context, req models.LoginRequest, jwtSecret string, exp time.Duration) (string, error) {
+	args := m.Called(req, jwtSecret, exp)
+	return args.String(0), args.Error(1)
+}
+
+func (m *AuthServiceMock) Authenticate(_ context.Context, req models.LoginRequest) (*models.User, error) {
+	args := m.Called(req)
+	if v := args.Get(0); v != nil {
+		return v.(*models.User), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *AuthServiceMock) LoginLockout(_ context.Context, email string) time.Duration {
+	return m.Called(email).Get(0).(time.Duration)
+}
+
+func (m *AuthServiceMock) EnableTwoFactor(_ context.Context, id core.UserID) (*models.TwoFactorSetup, error) {
+	args := m.Called(id)
+	if v := args.Get(0); v != nil {
+		return v.(*models.TwoFactorSetup), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *AuthServiceMock) ConfirmTwoFactor(_ context.Context, id core.UserID, code string) error {
+	return m.Called(id, code).Error(0)
+}
+
+func (m *AuthServiceMock) ChangePassword(_ context.Context, id core.UserID, req models.ChangePasswordRequest) error {
+	return m.Called(id, req).Error(0)
+}
+
+func (m *AuthServiceMock) PasswordStrength(req models.PasswordStrengthRequest) models.PasswordStrengthResponse {
+	return m.Called(req).Get(0).(models.PasswordStrengthResponse)
+}
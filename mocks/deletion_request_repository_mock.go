@@ -0,0 +1,66 @@
+package mocks
+
+import (
+	"time"
+
+	"HelmyTask/models"
+	"github.com/stretchr/testify/mock"
+)
+
+// DeletionRequestRepositoryMock is a testify/mock for repositories.DeletionRequestRepository.
+type DeletionRequestRepositoryMock struct{ mock.Mock }
+
+func (m *DeletionRequestRepositoryMock) Create(d *models.DeletionRequest) error {
+	return m.Called(d).Error(0)
+}
+
+func (m *DeletionRequestRepositoryMock) Update(d *models.DeletionRequest) error {
+	return m.Called(d).Error(0)
+}
+
+func (m *DeletionRequestRepositoryMock) FindByID(id uint) (*models.DeletionRequest, error) {
+	args := m.Called(id)
+	if v := args.Get(0); v != nil {
+		return v.(*models.DeletionRequest), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *DeletionRequestRepositoryMock) LatestForUser(userID uint) (*models.DeletionRequest, error) {
+	args := m.Called(userID)
+	if v := args.Get(0); v != nil {
+		return v.(*models.DeletionRequest), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *DeletionRequestRepositoryMock) List(status string, offset, limit int) ([]models.DeletionRequest, int64, error) {
+	args := m.Called(status, offset, limit)
+	if v := args.Get(0); v != nil {
+		return v.([]models.DeletionRequest), args.Get(1).(int64), args.Error(2)
+	}
+	return nil, 0, args.Error(2)
+}
+
+func (m *DeletionRequestRepositoryMock) FindOverdue(now time.Time, limit int) ([]models.DeletionRequest, error) {
+	args := m.Called(now, limit)
+	if v := args.Get(0); v != nil {
+		return v.([]models.DeletionRequest), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *DeletionRequestRepositoryMock) Decide(d *models.DeletionRequest) (bool, error) {
+	args := m.Called(d)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *DeletionRequestRepositoryMock) PurgeFinished(before time.Time) (int64, error) {
+	args := m.Called(before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *DeletionRequestRepositoryMock) ErasePersonalData(userID uint, email string) (models.ErasureCounts, error) {
+	args := m.Called(userID, email)
+	return args.Get(0).(models.ErasureCounts), args.Error(1)
+}
@@ -0,0 +1,25 @@
+package mocks
+
+import (
+	"HelmyTask/models"
+	"github.com/stretchr/testify/mock"
+)
+
+// EmailDeliveryRepositoryMock is a testify/mock for repositories.EmailDeliveryRepository.
+type EmailDeliveryRepositoryMock struct{ mock.Mock }
+
+func (m *EmailDeliveryRepositoryMock) Create(d *models.EmailDelivery) error {
+	return m.Called(d).Error(0)
+}
+
+func (m *EmailDeliveryRepositoryMock) FindByProviderMessageID(id string) (*models.EmailDelivery, error) {
+	args := m.Called(id)
+	if v := args.Get(0); v != nil {
+		return v.(*models.EmailDelivery), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *EmailDeliveryRepositoryMock) UpdateStatus(id uint, status, detail string) error {
+	return m.Called(id, status, detail).Error(0)
+}
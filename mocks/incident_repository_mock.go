@@ -0,0 +1,35 @@
+package mocks
+
+import (
+	"time"
+
+	"HelmyTask/models"
+	"github.com/stretchr/testify/mock"
+)
+
+// IncidentRepositoryMock is a testify/mock for repositories.IncidentRepository.
+type IncidentRepositoryMock struct{ mock.Mock }
+
+func (m *IncidentRepositoryMock) Create(i *models.Incident) error {
+	return m.Called(i).Error(0)
+}
+
+func (m *IncidentRepositoryMock) Update(i *models.Incident) error {
+	return m.Called(i).Error(0)
+}
+
+func (m *IncidentRepositoryMock) FindByID(id uint) (*models.Incident, error) {
+	args := m.Called(id)
+	if v := args.Get(0); v != nil {
+		return v.(*models.Incident), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *IncidentRepositoryMock) ListSince(since time.Time, limit int) ([]models.Incident, error) {
+	args := m.Called(since, limit)
+	if v := args.Get(0); v != nil {
+		return v.([]models.Incident), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
@@ -0,0 +1,24 @@
+package mocks
+
+import (
+	"context"
+	"HelmyTask/models"
+	"github.com/stretchr/testify/mock"
+)
+
+// SetupServiceMock is a testify/mock for services.SetupService.
+// Contexts are accepted but not part of the expectations (.On takes the other arguments).
+type SetupServiceMock struct{ mock.Mock }
+
+func (m *SetupServiceMock) SetupRequired(_ context.Context) (bool, error) {
+	args := m.Called()
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *SetupServiceMock) Setup(_ context.Context, req models.RegisterRequest) (*models.User, error) {
+	args := m.Called(req)
+	if v := args.Get(0); v != nil {
+		return v.(*models.User), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
@@ -0,0 +1,124 @@
+package mocks
+
+import (
+	"context"
+	"HelmyTask/accounts"
+	"HelmyTask/core"
+	"HelmyTask/models"
+	"github.com/stretchr/testify/mock"
+)
+
+// UserAdminServiceMock is a testify/mock for services.UserAdminService.
+// We use this to test the user management handlers without real business logic.
+// Contexts are accepted but not part of the expectations (.On takes the other arguments).
+type UserAdminServiceMock struct{ mock.Mock }
+
+func (m *UserAdminServiceMock) GetByID(_ context.Context, id core.UserID) (*models.User, error) {
+	args := m.Called(id)
+	if v := args.Get(0); v != nil {
+		return v.(*models.User), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *UserAdminServiceMock) CreateUser(_ context.Context, req models.RegisterRequest) (*models.User, error) {
+	args := m.Called(req)
+	if v := args.Get(0); v != nil {
+		return v.(*models.User), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *UserAdminServiceMock) GetUser(_ context.Context, id core.UserID) (*models.User, error) {
+	args := m.Called(id)
+	if v := args.Get(0); v != nil {
+		return v.(*models.User), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *UserAdminServiceMock) UpdateUser(_ context.Context, id core.UserID, req models.UpdateUserRequest) (*models.User, error) {
+	args := m.Called(id, req)
+	if v := args.Get(0); v != nil {
+		return v.(*models.User), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *UserAdminServiceMock) DeleteUser(_ context.Context, id core.UserID) error {
+	return m.Called(id).Error(0)
+}
+
+func (m *UserAdminServiceMock) SetStatus(_ context.Context, id core.UserID, status, reason string) (*models.User, error) {
+	args := m.Called(id, status, reason)
+	if v := args.Get(0); v != nil {
+		return v.(*models.User), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *UserAdminServiceMock) ImportUsers(_ context.Context, rows []models.RegisterRequest) (*models.ImportUsersResult, error) {
+	args := m.Called(rows)
+	if v := args.Get(0); v != nil {
+		return v.(*models.ImportUsersResult), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *UserAdminServiceMock) DeleteUsers(_ context.Context, ids []core.UserID) (*models.BulkDeleteUsersResult, error) {
+	args := m.Called(ids)
+	if v := args.Get(0); v != nil {
+		return v.(*models.BulkDeleteUsersResult), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+// ExportUsers hands the configured batches (args.Get(0), [][]models.User) to each, then returns args.Error(1).
+func (m *UserAdminServiceMock) ExportUsers(_ context.Context, q models.ListUserQuery, each func([]models.User) error) error {
+	args := m.Called(q, each)
+	if batches, ok := args.Get(0).([][]models.User); ok {
+		for _, b := range batches {
+			if err := each(b); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *UserAdminServiceMock) ListUsers(_ context.Context, q models.ListUserQuery) (*models.PagedUsers, error) {
+	args := m.Called(q)
+	if v := args.Get(0); v != nil {
+		return v.(*models.PagedUsers), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *UserAdminServiceMock) ExportAccounts(_ context.Context, ids []core.UserID) ([]accounts.Account, []uint, error) {
+	args := m.Called(ids)
+	var accts []accounts.Account
+	if v := args.Get(0); v != nil {
+		accts = v.([]accounts.Account)
+	}
+	var missing []uint
+	if v := args.Get(1); v != nil {
+		missing = v.([]uint)
+	}
+	return accts, missing, args.Error(2)
+}
+
+func (m *UserAdminServiceMock) ImportAccounts(_ context.Context, accts []accounts.Account, overwrite bool) (*models.ImportAccountsResult, error) {
+	args := m.Called(accts, overwrite)
+	if v := args.Get(0); v != nil {
+		return v.(*models.ImportAccountsResult), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *UserAdminServiceMock) FlushUser(_ context.Context, id core.UserID) ([]string, error) {
+	args := m.Called(id)
+	if v := args.Get(0); v != nil {
+		return v.([]string), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
@@ -1,19 +1,23 @@
 package mocks
 
 import (
+	"context"
+	"HelmyTask/core"
 	"HelmyTask/models"
+	"HelmyTask/repositories"
 	"github.com/stretchr/testify/mock"
 )
 
 // UserRepositoryMock is a testify/mock for repositories.UserRepository.
 // We use this to unit-test the service layer without touching a DB.
+// Contexts are accepted but not part of the expectations (.On takes the other arguments).
 type UserRepositoryMock struct{ mock.Mock }
 
-func (m *UserRepositoryMock) Create(u *models.User) error {
+func (m *UserRepositoryMock) Create(_ context.Context, u *models.User) error {
 	return m.Called(u).Error(0)
 }
 
-func (m *UserRepositoryMock) FindByEmail(email string) (*models.User, error) {
+func (m *UserRepositoryMock) FindByEmail(_ context.Context, email core.Email) (*models.User, error) {
 	args := m.Called(email)
 	if v := args.Get(0); v != nil {
 		return v.(*models.User), args.Error(1)
@@ -21,7 +25,7 @@ func (m *UserRepositoryMock) FindByEmail(email string) (*models.User, error) {
 	return nil, args.Error(1)
 }
 
-func (m *UserRepositoryMock) FindByID(id uint) (*models.User, error) { 
+func (m *UserRepositoryMock) FindByID(_ context.Context, id core.UserID) (*models.User, error) { 
 	args := m.Called(id)
 	if v := args.Get(0); v != nil {
 		return v.(*models.User), args.Error(1)
@@ -29,16 +33,16 @@ func (m *UserRepositoryMock) FindByID(id uint) (*models.User, error) {
 	return nil, args.Error(1)
 }
 
-func (m *UserRepositoryMock) Update(u *models.User) error {
+func (m *UserRepositoryMock) Update(_ context.Context, u *models.User) error {
 	return m.Called(u).Error(0)
 }
 
-func (m *UserRepositoryMock) Delete(id uint) error {
+func (m *UserRepositoryMock) Delete(_ context.Context, id core.UserID) error {
 	return m.Called(id).Error(0)
 }
 
-func (m *UserRepositoryMock) List(offset, limit int) ([]models.User, int64, error) {
-	args := m.Called(offset, limit)
+func (m *UserRepositoryMock) List(_ context.Context, q models.ListUserQuery, offset, limit int) ([]models.User, int64, error) {
+	args := m.Called(q, offset, limit)
 	var items []models.User
 	if v := args.Get(0); v != nil {
 		items = v.([]models.User)
@@ -49,3 +53,41 @@ func (m *UserRepositoryMock) List(offset, limit int) ([]models.User, int64, erro
 	}
 	return items, total, args.Error(2)
 }
+
+func (m *UserRepositoryMock) ListAfter(_ context.Context, q models.ListUserQuery, afterID uint, limit int) ([]models.User, error) {
+	args := m.Called(q, afterID, limit)
+	if v := args.Get(0); v != nil {
+		return v.([]models.User), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *UserRepositoryMock) DeleteMany(_ context.Context, ids []core.UserID) ([]models.User, error) {
+	args := m.Called(ids)
+	if v := args.Get(0); v != nil {
+		return v.([]models.User), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *UserRepositoryMock) CreateBatch(_ context.Context, users []models.User) error {
+	args := m.Called(users)
+	if err := args.Error(0); err != nil {
+		return err
+	}
+	for i := range users { // emulate the DB assigning IDs
+		users[i].ID = uint(i + 1)
+	}
+	return nil
+}
+
+// WithTx runs fn against the mock itself: there is no transaction to emulate, and tests set
+// expectations on the calls fn makes, not on WithTx.
+func (m *UserRepositoryMock) WithTx(_ context.Context, fn func(repositories.UserRepository) error) error {
+	return fn(m)
+}
+
+// Audit returns the AuditRepository the test configured with On("Audit").
+func (m *UserRepositoryMock) Audit() repositories.AuditRepository {
+	return m.Called().Get(0).(repositories.AuditRepository)
+}
@@ -0,0 +1,43 @@
+package mocks
+
+import (
+	"time"
+
+	"HelmyTask/models"
+	"github.com/stretchr/testify/mock"
+)
+
+// WebhookDeliveryRepositoryMock is a testify/mock for repositories.WebhookDeliveryRepository.
+type WebhookDeliveryRepositoryMock struct{ mock.Mock }
+
+func (m *WebhookDeliveryRepositoryMock) Create(d *models.WebhookDelivery) error {
+	return m.Called(d).Error(0)
+}
+
+func (m *WebhookDeliveryRepositoryMock) Update(d *models.WebhookDelivery) error {
+	return m.Called(d).Error(0)
+}
+
+func (m *WebhookDeliveryRepositoryMock) FindByIDs(ids []uint) ([]models.WebhookDelivery, error) {
+	args := m.Called(ids)
+	if v := args.Get(0); v != nil {
+		return v.([]models.WebhookDelivery), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *WebhookDeliveryRepositoryMock) List(f models.WebhookDeliveryFilter, offset, limit int) ([]models.WebhookDelivery, int64, error) {
+	args := m.Called(f, offset, limit)
+	if v := args.Get(0); v != nil {
+		return v.([]models.WebhookDelivery), args.Get(1).(int64), args.Error(2)
+	}
+	return nil, 0, args.Error(2)
+}
+
+func (m *WebhookDeliveryRepositoryMock) FindDue(now time.Time, limit int) ([]models.WebhookDelivery, error) {
+	args := m.Called(now, limit)
+	if v := args.Get(0); v != nil {
+		return v.([]models.WebhookDelivery), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
@@ -0,0 +1,33 @@
+package mocks
+
+import (
+	"HelmyTask/models"
+	"github.com/stretchr/testify/mock"
+)
+
+// WebhookRepositoryMock is a testify/mock for repositories.WebhookRepository.
+type WebhookRepositoryMock struct{ mock.Mock }
+
+func (m *WebhookRepositoryMock) Create(w *models.Webhook) error {
+	return m.Called(w).Error(0)
+}
+
+func (m *WebhookRepositoryMock) List() ([]models.Webhook, error) {
+	args := m.Called()
+	if v := args.Get(0); v != nil {
+		return v.([]models.Webhook), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *WebhookRepositoryMock) FindByID(id uint) (*models.Webhook, error) {
+	args := m.Called(id)
+	if v := args.Get(0); v != nil {
+		return v.(*models.Webhook), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *WebhookRepositoryMock) Delete(id uint) error {
+	return m.Called(id).Error(0)
+}
@@ -0,0 +1,89 @@
+// API keys for machine clients (CI jobs, integrations) that can't do an interactive login.
+
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"HelmyTask/core"
+)
+
+// APIKey is a long-lived credential owned by a user. Only a SHA-256 hash of the key is stored;
+// the plaintext is shown once at creation time.
+type APIKey struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	UserID        uint       `gorm:"index;not null" json:"user_id"`         // owner; requests act as this user
+	Name          string     `gorm:"size:100;not null" json:"name"`         // label chosen by the owner
+	Prefix        string     `gorm:"size:16;not null" json:"prefix"`        // first chars of the key, safe to display
+	KeyHash       string     `gorm:"size:64;uniqueIndex;not null" json:"-"` // hex(sha256(key))
+	SigningSecret string     `gorm:"size:100" json:"-"`                     // HMAC key for X-Signature; set = every request must be signed; shown once
+	Scopes        string     `gorm:"size:500" json:"-"`                     // space-separated permissions the key may use; "" = all of the owner's
+	LastUsedAt    *time.Time `json:"last_used_at,omitempty"`                // updated on successful auth
+	RevokedAt     *time.Time `gorm:"index" json:"revoked_at,omitempty"`     // nil = active
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// ScopeList is Scopes as a list (empty = unrestricted).
+func (k APIKey) ScopeList() []string { return strings.Fields(k.Scopes) }
+
+// CreateAPIKeyRequest is the payload for issuing a new key.
+type CreateAPIKeyRequest struct {
+	Name   string   `json:"name" binding:"required,max=100"`
+	Signed bool     `json:"signed"`                              // also issue a signing secret; requests with the key must then carry X-Signature
+	Scopes []string `json:"scopes" binding:"max=50,dive,max=64"` // permissions (e.g. "users:read") the key is limited to; none = all of the owner's
+}
+
+// RenameAPIKeyRequest is the payload for PATCH /me/api-keys/:id.
+type RenameAPIKeyRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+// APIKeyCreated is returned once on creation; Key and SigningSecret are never retrievable again.
+type APIKeyCreated struct {
+	APIKey
+	Key           string `json:"key"`
+	SigningSecret string `json:"signing_secret,omitempty"` // signed keys only
+}
+
+// APIKeyIdentity is who a presented key acts as, and whether its requests must be signed.
+type APIKeyIdentity struct {
+	UserID        uint
+	Role          string
+	SigningSecret string   // "" = unsigned key
+	Scopes        []string // nil = the role's permissions, unrestricted
+}
+
+// apiKeyJSON is APIKey's wire form: its times as core.Timestamp (RFC 3339 UTC).
+type apiKeyJSON struct {
+	apiKeyFields
+	Signed     bool            `json:"signed"`
+	Scopes     []string        `json:"scopes"` // [] = all of the owner's permissions
+	LastUsedAt *core.Timestamp `json:"last_used_at,omitempty"`
+	RevokedAt  *core.Timestamp `json:"revoked_at,omitempty"`
+	CreatedAt  core.Timestamp  `json:"created_at"`
+}
+
+type apiKeyFields APIKey // same fields, no MarshalJSON
+
+func (k APIKey) wire() apiKeyJSON {
+	scopes := k.ScopeList()
+	if scopes == nil {
+		scopes = []string{}
+	}
+	return apiKeyJSON{apiKeyFields(k), k.SigningSecret != "", scopes, core.TimestampPtr(k.LastUsedAt), core.TimestampPtr(k.RevokedAt), core.Timestamp(k.CreatedAt)}
+}
+
+// MarshalJSON implements json.Marshaler (see apiKeyJSON).
+func (k APIKey) MarshalJSON() ([]byte, error) { return json.Marshal(k.wire()) }
+
+// MarshalJSON keeps Key and SigningSecret: the embedded APIKey's MarshalJSON would otherwise be
+// promoted and drop them.
+func (k APIKeyCreated) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		apiKeyJSON
+		Key           string `json:"key"`
+		SigningSecret string `json:"signing_secret,omitempty"`
+	}{k.APIKey.wire(), k.Key, k.SigningSecret})
+}
@@ -0,0 +1,47 @@
+// Audit trail of mutating operations.
+
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"HelmyTask/core"
+)
+
+// AuditLog is one recorded change: who did what to which record, when, and what changed.
+type AuditLog struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	ActorID    uint      `gorm:"index;not null" json:"actor_id"`       // authenticated uid (0 = anonymous/system)
+	Action     string    `gorm:"size:60;index;not null" json:"action"` // e.g. "user.update"
+	TargetType string    `gorm:"size:40;not null" json:"target_type"`  // e.g. "user"
+	TargetID   uint      `gorm:"index" json:"target_id"`
+	Diff       string    `gorm:"type:text" json:"diff"` // JSON: {"field": {"from": .., "to": ..}}; secrets only as {"changed": true}
+	IP         string    `gorm:"size:64" json:"ip,omitempty"`
+	Reason     string    `gorm:"size:500" json:"reason,omitempty"` // actor's note, e.g. ban reason
+	CreatedAt  time.Time `gorm:"index" json:"created_at"`
+}
+
+// AuditFilter narrows GET /admin/audit.
+type AuditFilter struct {
+	ActorID  uint       // 0 = any
+	Action   string     // "" = any
+	From, To *time.Time // CreatedAt range, inclusive; nil = open
+}
+
+// PagedAuditLogs is a page of the audit trail.
+type PagedAuditLogs struct {
+	Items []AuditLog `json:"items"`
+	Total int64      `json:"total"`
+	Page  int        `json:"page"`
+	Limit int        `json:"limit"`
+}
+
+// MarshalJSON renders CreatedAt as core.Timestamp (RFC 3339 UTC), whatever the driver returned.
+func (a AuditLog) MarshalJSON() ([]byte, error) {
+	type fields AuditLog
+	return json.Marshal(struct {
+		fields
+		CreatedAt core.Timestamp `json:"created_at"`
+	}{fields(a), core.Timestamp(a.CreatedAt)})
+}
@@ -0,0 +1,82 @@
+// Account deletion requests reviewed by admins (regulated deployments).
+
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"HelmyTask/core"
+)
+
+// Deletion request statuses. Pending requests are decided by an admin before DueAt or expire;
+// approved ones stay approved until the erasure has run.
+const (
+	DeletionPending   = "pending"
+	DeletionApproved  = "approved" // erasure queued or running
+	DeletionRejected  = "rejected"
+	DeletionExpired   = "expired"   // not decided within the review window; the user may ask again
+	DeletionCompleted = "completed" // account erased
+)
+
+// DeletionRequest is a user's request to have their account erased. It outlives the account:
+// after erasure UserID is a bare number no longer tied to anyone.
+type DeletionRequest struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	UserID      uint       `gorm:"index;not null" json:"user_id"`
+	Reason      string     `gorm:"size:500" json:"reason,omitempty"` // the user's note
+	Status      string     `gorm:"size:20;not null;index" json:"status"`
+	DueAt       time.Time  `gorm:"index;not null" json:"due_at"`          // decide by then or it expires
+	ReviewerID  uint       `json:"reviewer_id,omitempty"`                 // admin who approved/rejected
+	ReviewNote  string     `gorm:"size:500" json:"review_note,omitempty"` // shown to the user on rejection
+	DecidedAt   *time.Time `json:"decided_at,omitempty"`                  // approval, rejection or expiry
+	CompletedAt *time.Time `json:"completed_at,omitempty"`                // erasure finished
+	LastError   string     `gorm:"size:500" json:"last_error,omitempty"`  // last failed erasure attempt (retried)
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// Open reports whether the request still blocks a new one (pending, or approved and not yet erased).
+func (d DeletionRequest) Open() bool {
+	return d.Status == DeletionPending || d.Status == DeletionApproved
+}
+
+// ErasureCounts is what the erasure of an account removed or pseudonymized besides the user row.
+type ErasureCounts struct {
+	APIKeys           int64 `json:"api_keys_deleted"`
+	Notifications     int64 `json:"notifications_deleted"`
+	EmailDeliveries   int64 `json:"email_deliveries_pseudonymized"`
+	AuditLogs         int64 `json:"audit_logs_pseudonymized"`
+	WebhookDeliveries int64 `json:"webhook_deliveries_pseudonymized"`
+}
+
+// CreateDeletionRequest is the body of POST /me/deletion-request.
+type CreateDeletionRequest struct {
+	Reason string `json:"reason" binding:"max=500"`
+}
+
+// ReviewDeletionRequest is the body of the approve/reject endpoints (a note is required to reject).
+type ReviewDeletionRequest struct {
+	Note string `json:"note" binding:"max=500"`
+}
+
+// PagedDeletionRequests is a page of GET /admin/deletion-requests.
+type PagedDeletionRequests struct {
+	Items []DeletionRequest `json:"items"`
+	Total int64             `json:"total"`
+	Page  int               `json:"page"`
+	Limit int               `json:"limit"`
+}
+
+// MarshalJSON renders the timestamps as core.Timestamp (RFC 3339 UTC).
+func (d DeletionRequest) MarshalJSON() ([]byte, error) {
+	type fields DeletionRequest
+	return json.Marshal(struct {
+		fields
+		DueAt       core.Timestamp  `json:"due_at"`
+		DecidedAt   *core.Timestamp `json:"decided_at,omitempty"`
+		CompletedAt *core.Timestamp `json:"completed_at,omitempty"`
+		CreatedAt   core.Timestamp  `json:"created_at"`
+		UpdatedAt   core.Timestamp  `json:"updated_at"`
+	}{fields(d), core.Timestamp(d.DueAt), core.TimestampPtr(d.DecidedAt), core.TimestampPtr(d.CompletedAt), core.Timestamp(d.CreatedAt), core.Timestamp(d.UpdatedAt)})
+}
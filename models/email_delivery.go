@@ -0,0 +1,50 @@
+// Outbound email bookkeeping: what we sent, what the provider told us happened to it.
+
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"HelmyTask/core"
+)
+
+// Delivery statuses, advanced by provider webhooks.
+const (
+	EmailStatusSent       = "sent"
+	EmailStatusDelivered  = "delivered"
+	EmailStatusBounced    = "bounced"
+	EmailStatusComplained = "complained" // recipient marked it as spam
+)
+
+// EmailDelivery records one sent email and its latest known status.
+type EmailDelivery struct {
+	ID                uint      `gorm:"primaryKey" json:"id"`
+	To                string    `gorm:"size:180;index;not null" json:"to"`
+	Kind              string    `gorm:"size:40;not null" json:"kind"`     // welcome|verification|password_reset...
+	Provider          string    `gorm:"size:40;not null" json:"provider"` // smtp|ses|sendgrid...
+	ProviderMessageID string    `gorm:"size:255;uniqueIndex;not null" json:"provider_message_id"`
+	Status            string    `gorm:"size:20;not null" json:"status"`
+	Detail            string    `gorm:"size:500" json:"detail,omitempty"` // bounce reason etc.
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// EmailEvent is the provider-neutral webhook payload for delivery callbacks.
+type EmailEvent struct {
+	Type       string `json:"type" binding:"required,oneof=delivered bounce complaint"`
+	MessageID  string `json:"message_id" binding:"required"`
+	Email      string `json:"email"`       // recipient as the provider reports it; informational, the recorded delivery's address is flagged
+	BounceType string `json:"bounce_type"` // hard|soft (bounces only); soft bounces don't flag the address
+	Reason     string `json:"reason"`
+}
+
+// MarshalJSON renders the timestamps as core.Timestamp (RFC 3339 UTC).
+func (d EmailDelivery) MarshalJSON() ([]byte, error) {
+	type fields EmailDelivery
+	return json.Marshal(struct {
+		fields
+		CreatedAt core.Timestamp `json:"created_at"`
+		UpdatedAt core.Timestamp `json:"updated_at"`
+	}{fields(d), core.Timestamp(d.CreatedAt), core.Timestamp(d.UpdatedAt)})
+}
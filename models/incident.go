@@ -0,0 +1,66 @@
+// Service incidents announced on the public status page (GET /status).
+
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"HelmyTask/core"
+)
+
+// Incident statuses, in the usual status-page progression.
+const (
+	IncidentInvestigating = "investigating"
+	IncidentIdentified    = "identified"
+	IncidentMonitoring    = "monitoring"
+	IncidentResolved      = "resolved"
+)
+
+// Incident impact levels; they set the page's overall status while the incident is open.
+const (
+	ImpactMinor    = "minor"    // some users or one feature affected → degraded
+	ImpactMajor    = "major"    // a core flow (login, signup) broken → partial outage
+	ImpactCritical = "critical" // the service is down → major outage
+)
+
+// Incident is an admin-written notice about a service problem. Everything here is public.
+type Incident struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	Title       string     `gorm:"size:200;not null" json:"title"`
+	Status      string     `gorm:"size:20;not null;index" json:"status"`
+	Impact      string     `gorm:"size:20;not null" json:"impact"`
+	Message     string     `gorm:"size:2000" json:"message,omitempty"` // latest update shown to users
+	CreatedByID uint       `gorm:"not null" json:"-"`                  // admin who opened it (not published)
+	ResolvedAt  *time.Time `gorm:"index" json:"resolved_at,omitempty"` // nil while open
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// CreateIncidentRequest opens an incident.
+type CreateIncidentRequest struct {
+	Title   string `json:"title" binding:"required,max=200"`
+	Impact  string `json:"impact" binding:"required,oneof=minor major critical"`
+	Status  string `json:"status" binding:"omitempty,oneof=investigating identified monitoring resolved"` // default investigating
+	Message string `json:"message" binding:"max=2000"`
+}
+
+// UpdateIncidentRequest posts an update; nil fields are left as they are.
+// Status "resolved" closes the incident; any other status re-opens it.
+type UpdateIncidentRequest struct {
+	Title   *string `json:"title" binding:"omitempty,min=1,max=200"`
+	Impact  *string `json:"impact" binding:"omitempty,oneof=minor major critical"`
+	Status  *string `json:"status" binding:"omitempty,oneof=investigating identified monitoring resolved"`
+	Message *string `json:"message" binding:"omitempty,max=2000"`
+}
+
+// MarshalJSON renders the timestamps as core.Timestamp (RFC 3339 UTC).
+func (i Incident) MarshalJSON() ([]byte, error) {
+	type fields Incident
+	return json.Marshal(struct {
+		fields
+		ResolvedAt *core.Timestamp `json:"resolved_at,omitempty"`
+		CreatedAt  core.Timestamp  `json:"created_at"`
+		UpdatedAt  core.Timestamp  `json:"updated_at"`
+	}{fields(i), core.TimestampPtr(i.ResolvedAt), core.Timestamp(i.CreatedAt), core.Timestamp(i.UpdatedAt)})
+}
@@ -0,0 +1,41 @@
+// In-app notifications shown to users (the "in_app" channel of the notifications package).
+
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"HelmyTask/core"
+)
+
+// Notification is one in-app notification; ReadAt is nil until the user marks it read.
+type Notification struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	UserID    uint       `gorm:"index:idx_notifications_user_read;not null" json:"-"`
+	Kind      string     `gorm:"size:40;not null" json:"kind"` // e.g. welcome, account_updated
+	Title     string     `gorm:"size:200;not null" json:"title"`
+	Body      string     `gorm:"size:1000" json:"body,omitempty"`
+	Link      string     `gorm:"size:500" json:"link,omitempty"` // where the client should take the user
+	ReadAt    *time.Time `gorm:"index:idx_notifications_user_read" json:"read_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// PagedNotifications is a page of GET /me/notifications, newest first.
+type PagedNotifications struct {
+	Items  []Notification `json:"items"`
+	Total  int64          `json:"total"`
+	Unread int64          `json:"unread"` // across all pages, for the badge
+	Page   int            `json:"page"`
+	Limit  int            `json:"limit"`
+}
+
+// MarshalJSON renders the timestamps as core.Timestamp (RFC 3339 UTC).
+func (n Notification) MarshalJSON() ([]byte, error) {
+	type fields Notification
+	return json.Marshal(struct {
+		fields
+		ReadAt    *core.Timestamp `json:"read_at,omitempty"`
+		CreatedAt core.Timestamp  `json:"created_at"`
+	}{fields(n), core.TimestampPtr(n.ReadAt), core.Timestamp(n.CreatedAt)})
+}
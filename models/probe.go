@@ -0,0 +1,27 @@
+// Synthetic monitoring scratch row.
+
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"HelmyTask/core"
+)
+
+// ProbeHeartbeat is written and read back by the DB round-trip probe (a single row; probes run
+// on the leader only).
+type ProbeHeartbeat struct {
+	Instance  string    `gorm:"primaryKey;size:64" json:"instance"`
+	Token     string    `gorm:"size:64;not null" json:"token"` // random per run; read back to prove the write landed
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// MarshalJSON renders UpdatedAt as core.Timestamp (RFC 3339 UTC).
+func (h ProbeHeartbeat) MarshalJSON() ([]byte, error) {
+	type fields ProbeHeartbeat
+	return json.Marshal(struct {
+		fields
+		UpdatedAt core.Timestamp `json:"updated_at"`
+	}{fields(h), core.Timestamp(h.UpdatedAt)})
+}
@@ -0,0 +1,14 @@
+// Schema migration history, for the compatibility check (see schemacheck).
+
+package models
+
+import "time"
+
+// SchemaVersion is one auto-migration: the checksum of the schema the migrating build expects.
+// The latest row is the schema the database was last brought in line with.
+type SchemaVersion struct {
+	ID         uint      `gorm:"primaryKey"`
+	Checksum   string    `gorm:"size:64;not null;index"`
+	AppVersion string    `gorm:"size:32"`
+	AppliedAt  time.Time `gorm:"not null"`
+}
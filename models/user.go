@@ -2,7 +2,14 @@
 
 package models
 
-import "time"
+import (
+	"encoding/json" // Custom timestamp rendering.
+	"time"
+
+	"HelmyTask/core" // Strength estimator result type.
+
+	"gorm.io/gorm" // Save hook keeping the search columns current.
+)
 
 //user represents a user record in the database 
 //Gorm tags configure primary key , sizes and constrains
@@ -12,23 +19,80 @@ type User struct {
 	Name      string    `gorm:"size:120;not null" json:"name"` //amybe add uniqueIndex
 	Email     string    `gorm:"size:180;uniqueIndex;not null" json:"email"`
 	Password  string    `gorm:"size:255;not null" json:"-"` // hashed
+	Role      string    `gorm:"size:20;not null;default:user" json:"role"` // user|support|admin (see policy package)
+	TOTPSecret  string  `gorm:"size:255" json:"-"`                                    // AES-GCM encrypted TOTP seed (pending until confirmed)
+	TOTPEnabled bool    `gorm:"not null;default:false" json:"two_factor_enabled"` // Login requires a TOTP code when true
+	TOTPLastStep int64  `gorm:"not null;default:0" json:"-"`                        // Time step of the last code accepted; older and equal ones are replays
+	EmailUndeliverable bool `gorm:"not null;default:false" json:"email_undeliverable"` // Set by hard bounces/complaints
+	Status    string    `gorm:"size:20;not null;default:active;index" json:"status"` // active|disabled|banned; only active accounts can log in
+	NameSearch   string `gorm:"size:255;index" json:"-"` // core.FoldSearch(Name); set by BeforeSave
+	NameSkeleton string `gorm:"size:255;index" json:"-"` // core.SearchSkeleton(Name); set by BeforeSave
+
+	// Optional profile; "" = not set.
+	Phone       string `gorm:"size:20" json:"phone,omitempty"`         // E.164, e.g. +201001234567
+	Bio         string `gorm:"size:500" json:"bio,omitempty"`          // free text, up to 500 characters
+	DateOfBirth string `gorm:"size:10" json:"date_of_birth,omitempty"` // YYYY-MM-DD
+	Locale      string `gorm:"size:35" json:"locale,omitempty"`        // BCP 47 tag, e.g. ar-EG
+	Timezone    string `gorm:"size:64" json:"timezone,omitempty"`      // IANA name, e.g. Africa/Cairo
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// Roles understood by the policy engine.
+// New users always start as RoleUser; only admins can promote others.
+const (
+	RoleUser    = "user"    // regular account (self-service only)
+	RoleSupport = "support" // customer support: read-only access to users and audit logs
+	RoleAdmin   = "admin"   // full access
+)
+
+// Account statuses. Rows created before statuses existed ("") count as active.
+const (
+	StatusActive   = "active"   // normal account
+	StatusDisabled = "disabled" // switched off (e.g. on request); can be re-enabled
+	StatusBanned   = "banned"   // blocked by an admin for abuse; see POST /users/:id/ban
+)
+
+// IsActive reports whether the account may log in and use its credentials.
+func (u User) IsActive() bool {
+	return u.Status == "" || u.Status == StatusActive
+}
+
+// BeforeSave keeps the normalized search columns in step with Name on every Create/Save.
+func (u *User) BeforeSave(*gorm.DB) error {
+	u.NameSearch, u.NameSkeleton = core.FoldSearch(u.Name), core.SearchSkeleton(u.Name)
+	return nil
+}
+
+// MarshalJSON renders the timestamps as core.Timestamp (RFC 3339 UTC, whole seconds), so
+// responses and cached copies look the same whichever driver loaded the row.
+func (u User) MarshalJSON() ([]byte, error) {
+	type fields User
+	return json.Marshal(struct {
+		fields
+		CreatedAt core.Timestamp `json:"created_at"`
+		UpdatedAt core.Timestamp `json:"updated_at"`
+	}{fields(u), core.Timestamp(u.CreatedAt), core.Timestamp(u.UpdatedAt)})
+}
+
 // DTOs (request/response)
 // RegisterRequest is the expected payload for the register endpoint.
 // Gin's binding tags add basic validation rules automatically.
 type RegisterRequest struct {
 	Name     string `json:"name" binding:"required,min=2"`
 	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required,min=6"`
+	Password string `json:"password" binding:"required,password"` // configured core.PasswordPolicy
+
+	// IdempotencyKey comes from the Idempotency-Key header (never the body); lets retried
+	// registrations replay the original result.
+	IdempotencyKey string `json:"-"`
 }
 
 //expectedd payload for the login endpoint
 type LoginRequest struct {
-	Email    string `json:"email" binding:"required,email"`
+	Email    string `json:"email" binding:"required"` // Only normalized (see core.NormalizeEmail): no format check at login.
 	Password string `json:"password" binding:"required"`
+	Code     string `json:"code,omitempty"` // TOTP code; second step, only needed when 2FA is enabled
 }
 
 //small resonse object hodl jwt token 
@@ -37,22 +101,139 @@ type AuthResponse struct {
 }
 
 
+// TwoFactorSetup is returned when enrolling TOTP; the client renders the URI as a QR code.
+type TwoFactorSetup struct {
+	Secret     string `json:"secret"`      // base32 seed for manual entry
+	OTPAuthURL string `json:"otpauth_url"` // otpauth://totp/... for QR codes
+}
+
+// TwoFactorConfirmRequest carries the first code from the authenticator app.
+type TwoFactorConfirmRequest struct {
+	Code string `json:"code" binding:"required,len=6,numeric"`
+}
+
+// PasswordStrengthRequest asks the server to score a candidate password.
+// Name/email are optional; passing them lets the estimator penalize passwords built from them.
+type PasswordStrengthRequest struct {
+	Password string `json:"password" binding:"required,max=256"`
+	Name     string `json:"name,omitempty"`
+	Email    string `json:"email,omitempty"`
+}
+
+// PasswordStrengthResponse is the estimator output plus whether the server would accept it.
+type PasswordStrengthResponse struct {
+	core.StrengthResult
+	MinScore   int  `json:"min_score"`  // configured minimum enforced on register/change
+	Acceptable bool `json:"acceptable"` // score >= min_score and the composition policy passes
+}
+
+// ChangePasswordRequest is the payload for POST /me/password.
+type ChangePasswordRequest struct {
+	Old string `json:"old_password" binding:"required"` // Current password, re-verified.
+	New string `json:"new_password" binding:"required,password"`
+}
+
 //update user requst aylpad fpr updating a usr 
 //allow parial updates by making fields pointers (nil means "no change")
 type UpdateUserRequest struct {
 	// Optional new name||email | password; if nil, keep existing. -> omitempty means do not change 
 	Name *string `json:"name,omitempty"`
 	Email *string `json:"email,omitempty"`
-	Password *string `json:"password,omitempty"`
+	Password *string `json:"password,omitempty" binding:"omitempty,password"`
+	Role *string `json:"role,omitempty" binding:"omitempty,oneof=user support admin"` // Admin-only in practice (route is admin-guarded).
+	Status *string `json:"status,omitempty" binding:"omitempty,oneof=active disabled banned"` // Admin-only, like Role; non-active logs the user out everywhere.
+
+	// Profile fields; "" clears one.
+	Phone       *string `json:"phone,omitempty" binding:"omitempty,eq=|e164"`
+	Bio         *string `json:"bio,omitempty" binding:"omitempty,max=500"`
+	DateOfBirth *string `json:"date_of_birth,omitempty" binding:"omitempty,eq=|datetime=2006-01-02"`
+	Locale      *string `json:"locale,omitempty" binding:"omitempty,eq=|bcp47_language_tag"`
+	Timezone    *string `json:"timezone,omitempty" binding:"omitempty,eq=|timezone"`
 }
 
 
+// UserStatusRequest is the optional body of POST /users/:id/ban and /unban.
+type UserStatusRequest struct {
+	Reason string `json:"reason,omitempty" binding:"max=500"` // Kept in the audit trail and logs.
+}
+
+// FlushUserRequest is the optional body of POST /admin/users/:id/flush.
+type FlushUserRequest struct {
+	IPs    []string `json:"ips,omitempty" binding:"max=20,dive,ip"` // Client IPs whose rate-limit buckets to refill (limits are per IP).
+	Reason string   `json:"reason,omitempty" binding:"max=500"`     // Kept in the audit trail.
+}
+
+// FlushUserResult reports what a flush cleared: cache, tokens, sessions, lockout, pending_2fa, rate_limits.
+type FlushUserResult struct {
+	UserID  uint     `json:"user_id"`
+	Flushed []string `json:"flushed"`
+}
+
+// BulkDeleteUsersRequest is the body of DELETE /users.
+type BulkDeleteUsersRequest struct {
+	IDs []uint `json:"ids" binding:"required,min=1,max=500,dive,gt=0"` // Capped so one call can't hold a huge transaction.
+}
+
+// ExportAccountsRequest is the body of POST /admin/accounts/export.
+type ExportAccountsRequest struct {
+	IDs        []uint `json:"ids" binding:"required,min=1,max=500,dive,gt=0"` // Same cap as a bundle (accounts.MaxAccounts).
+	Passphrase string `json:"passphrase" binding:"required"`                  // Encrypts the bundle; share it out of band.
+}
+
+// BulkDeleteUsersResult reports the outcome per ID.
+type BulkDeleteUsersResult struct {
+	Deleted    int    `json:"deleted"`     // How many users were removed.
+	DeletedIDs []uint `json:"deleted_ids"` // Which ones.
+	NotFound   []uint `json:"not_found"`   // Requested IDs that didn't exist.
+
+	Users []User `json:"-"` // The deleted rows as they were, for the audit log (not in the response).
+}
+
+// ImportRowError explains why one row of POST /users/import was not created.
+type ImportRowError struct {
+	Row        int             `json:"row"`             // 1-based data row (CSV header / JSON array index not counted)
+	Email      string          `json:"email,omitempty"` // as given, to help find the row
+	Error      string          `json:"error"`
+	Violations core.Violations `json:"violations,omitempty"` // rule failures, same codes as register
+}
+
+// ImportUsersResult is the per-row report of POST /users/import.
+type ImportUsersResult struct {
+	Total   int              `json:"total"`   // rows in the file
+	Created int              `json:"created"` // rows inserted
+	Errors  []ImportRowError `json:"errors"`  // rows skipped, in file order
+	Users   []User           `json:"-"`       // the created users (for the audit trail)
+}
+
+// ImportAccountsResult is the per-account report of an accounts bundle import.
+type ImportAccountsResult struct {
+	Total    int              `json:"total"`   // accounts in the bundle
+	Created  int              `json:"created"` // new users
+	Updated  int              `json:"updated"` // existing users replaced (overwrite)
+	Errors   []ImportRowError `json:"errors"`  // accounts skipped; Row is the 1-based position in the bundle
+	Users    []User           `json:"-"`       // the created users (for the audit trail)
+	Replaced []User           `json:"-"`       // the updated users (for the audit trail)
+}
+
 //list users query parameters for pagination when listing users 
 //we keep i tin models to share between handlesr and service 
 type ListUserQuery struct {
 Page int `form:"page"` // Page number (1-based). We'll default in handler/service if 0.
 Limit int `form:"limit"` // Page size (items per page). We'll clamp sane defaults.
 
+	// Filters (all optional, combined with AND).
+	Q             string     `form:"q" binding:"max=100"`                                  // substring of name (accent/Arabic-script insensitive) or email, case-insensitive
+	Email         string     `form:"email" binding:"omitempty,email"`                    // exact address
+	CreatedAfter  *time.Time `form:"created_after" time_format:"2006-01-02T15:04:05Z07:00"`  // RFC 3339, inclusive
+	CreatedBefore *time.Time `form:"created_before" time_format:"2006-01-02T15:04:05Z07:00"` // RFC 3339, exclusive
+
+	// Ordering; the service only lets whitelisted columns through (see services.userSortColumns).
+	Sort  string `form:"sort"`  // id (default) | name | email | created_at | updated_at
+	Order string `form:"order"` // asc (default) | desc
+
+	// Cursor switches to keyset pagination (WHERE id > cursor): pass "" for the first page, then
+	// each response's next_cursor. Nil = offset pagination via Page. Only sort=id is allowed with it.
+	Cursor *string `form:"cursor"`
 }
 
 
@@ -62,4 +243,14 @@ type PagedUsers struct {
 	Total int64  `json:"total"` // Total number of users in DB (for pagination UIs).
 	Page  int    `json:"page"`  // Current page number (1-based).
 	Limit int    `json:"limit"` // Page size used.
-}
\ No newline at end of file
+
+	// Cursor mode only: pass as ?cursor= for the next page; empty on the last page.
+	// Total and Page are not computed in cursor mode (counting is what makes big tables slow).
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+// UserCount is one (status, role) group of users, as counted for the admin stats snapshot.
+type UserCount struct {
+	Status string
+	Role   string
+	Count  int64
+}
@@ -0,0 +1,155 @@
+// Outbound webhook targets registered by admins.
+
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"HelmyTask/core"
+)
+
+// Webhook is an endpoint that receives event notifications. URLs are SSRF-checked on
+// registration and again (by the dispatch client) on every connection.
+type Webhook struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	URL         string    `gorm:"size:500;not null" json:"url"`
+	Description string    `gorm:"size:200" json:"description,omitempty"`
+	Secret      string    `gorm:"size:100;not null" json:"-"`          // HMAC key for X-Webhook-Signature; shown once
+	CreatedByID uint      `gorm:"index;not null" json:"created_by_id"` // admin who registered it
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// CreateWebhookRequest is the payload for registering a webhook target.
+type CreateWebhookRequest struct {
+	URL         string `json:"url" binding:"required,url,max=500"`
+	Description string `json:"description" binding:"max=200"`
+}
+
+// WebhookCreated is returned once on registration; the signing secret is never shown again.
+type WebhookCreated struct {
+	Webhook
+	Secret string `json:"secret"`
+}
+
+// WebhookEvent is the JSON envelope POSTed to webhook targets.
+type WebhookEvent struct {
+	ID        string      `json:"id"`   // unique per event; consumers can dedupe on it
+	Type      string      `json:"type"` // e.g. "user.registered", "webhook.test"
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// SignedWebhookRequest is exactly what a target receives (headers + raw body).
+type SignedWebhookRequest struct {
+	Headers map[string]string `json:"headers"`
+	Body    string            `json:"body"`
+}
+
+// WebhookTestResult echoes the signed sample and how the target answered.
+type WebhookTestResult struct {
+	Request    SignedWebhookRequest `json:"request"`
+	StatusCode int                  `json:"status_code,omitempty"` // target's HTTP status
+	Error      string               `json:"error,omitempty"`       // transport error, if any
+}
+
+// Webhook delivery statuses.
+const (
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"   // final: automatic retries used up (replay it by hand)
+	WebhookDeliveryRetrying  = "retrying" // failed, next attempt at NextAttemptAt
+)
+
+// WebhookDelivery logs one event sent to one target, so failures can be inspected and replayed.
+// The body is stored as sent; replays re-sign it with a fresh timestamp.
+type WebhookDelivery struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	WebhookID      uint       `gorm:"index;not null" json:"webhook_id"`
+	EventID        string     `gorm:"size:40;index;not null" json:"event_id"` // same ID on replays, so consumers can dedupe
+	EventType      string     `gorm:"size:60;not null" json:"event_type"`
+	Payload        string     `gorm:"type:text;not null" json:"payload"`
+	Status         string     `gorm:"size:20;index;not null" json:"status"` // succeeded|retrying|failed
+	Attempts       int        `gorm:"not null;default:0" json:"attempts"`
+	LastStatusCode int        `json:"last_status_code,omitempty"`
+	LastError      string     `gorm:"size:500" json:"last_error,omitempty"`
+	NextAttemptAt  *time.Time `gorm:"index" json:"next_attempt_at,omitempty"` // set while retrying
+	CreatedAt      time.Time  `gorm:"index" json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// WebhookDeliveryFilter narrows delivery listings and range replays.
+type WebhookDeliveryFilter struct {
+	Status    string     // "" = any
+	WebhookID uint       // 0 = any
+	From, To  *time.Time // CreatedAt range, inclusive; nil = open
+}
+
+// PagedWebhookDeliveries is a page of the delivery log.
+type PagedWebhookDeliveries struct {
+	Items []WebhookDelivery `json:"items"`
+	Total int64             `json:"total"`
+	Page  int               `json:"page"`
+	Limit int               `json:"limit"`
+}
+
+// ReplayWebhooksRequest selects failed deliveries to resend: explicit IDs, or every failed
+// delivery in a time range (optionally for one webhook).
+type ReplayWebhooksRequest struct {
+	IDs       []uint          `json:"ids" binding:"max=500,dive,gt=0"` // Same cap as a range replay.
+	From      *core.Timestamp `json:"from"`                            // any core.ParseTimestamp format
+	To        *core.Timestamp `json:"to"`
+	WebhookID uint            `json:"webhook_id"`
+}
+
+// ReplayWebhooksResult reports a replay batch: how many deliveries were queued for the retry
+// worker (status retrying, due now); the rest had lost their webhook.
+type ReplayWebhooksResult struct {
+	Queued int               `json:"queued"`
+	Items  []WebhookDelivery `json:"items"` // updated rows
+}
+
+// webhookJSON is Webhook's wire form: CreatedAt as core.Timestamp (RFC 3339 UTC).
+type webhookJSON struct {
+	webhookFields
+	CreatedAt core.Timestamp `json:"created_at"`
+}
+
+type webhookFields Webhook // same fields, no MarshalJSON
+
+// MarshalJSON implements json.Marshaler (see webhookJSON).
+func (w Webhook) MarshalJSON() ([]byte, error) {
+	return json.Marshal(webhookJSON{webhookFields(w), core.Timestamp(w.CreatedAt)})
+}
+
+// MarshalJSON keeps Secret: the embedded Webhook's MarshalJSON would otherwise be promoted and drop it.
+func (w WebhookCreated) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		webhookJSON
+		Secret string `json:"secret"`
+	}{webhookJSON{webhookFields(w.Webhook), core.Timestamp(w.CreatedAt)}, w.Secret})
+}
+
+// MarshalJSON renders CreatedAt as core.Timestamp (RFC 3339 UTC), as targets see everywhere else.
+func (e WebhookEvent) MarshalJSON() ([]byte, error) {
+	type fields WebhookEvent
+	return json.Marshal(struct {
+		fields
+		CreatedAt core.Timestamp `json:"created_at"`
+	}{fields(e), core.Timestamp(e.CreatedAt)})
+}
+
+// MarshalJSON renders the timestamps as core.Timestamp (RFC 3339 UTC).
+func (d WebhookDelivery) MarshalJSON() ([]byte, error) {
+	type fields WebhookDelivery
+	var next *core.Timestamp
+	if d.NextAttemptAt != nil {
+		t := core.Timestamp(*d.NextAttemptAt)
+		next = &t
+	}
+	return json.Marshal(struct {
+		fields
+		NextAttemptAt *core.Timestamp `json:"next_attempt_at,omitempty"`
+		CreatedAt     core.Timestamp  `json:"created_at"`
+		UpdatedAt     core.Timestamp  `json:"updated_at"`
+	}{fields(d), next, core.Timestamp(d.CreatedAt), core.Timestamp(d.UpdatedAt)})
+}
@@ -0,0 +1,64 @@
+// The built-in channels.
+
+package notifications
+
+import (
+	"context"
+	"html"
+	"strings"
+
+	"HelmyTask/emailtmpl"
+	"HelmyTask/mailer"
+	"HelmyTask/models"
+	"HelmyTask/repositories"
+)
+
+// InApp stores messages for GET /me/notifications.
+type InApp struct {
+	Repo repositories.NotificationRepository
+}
+
+func (InApp) Name() string { return ChannelInApp }
+
+// Deliver inserts the notification.
+func (c InApp) Deliver(_ context.Context, u models.User, m Message) error {
+	return c.Repo.Create(&models.Notification{UserID: u.ID, Kind: m.Kind, Title: m.Title, Body: m.Body, Link: m.Link})
+}
+
+// Email sends messages through a mail transport as a plain email (Title as the subject).
+type Email struct{ Mailer mailer.Mailer }
+
+func (Email) Name() string { return ChannelEmail }
+
+// Deliver emails u unless the address is flagged undeliverable.
+func (c Email) Deliver(ctx context.Context, u models.User, m Message) error {
+	if u.Email == "" || u.EmailUndeliverable {
+		return nil
+	}
+	text := m.Body
+	htmlBody := "<p>" + html.EscapeString(m.Body) + "</p>"
+	if m.Link != "" {
+		text += "\n\n" + m.Link
+		htmlBody += `<p><a href="` + html.EscapeString(m.Link) + `">` + html.EscapeString(m.Link) + "</a></p>"
+	}
+	_, _, err := c.Mailer.Send(ctx, u.Email, emailtmpl.Message{Kind: "notification." + m.Kind, Locale: u.Locale, Dir: "ltr", Subject: m.Title, Text: text, HTML: htmlBody})
+	return err
+}
+
+// SMS texts messages to the user's phone number.
+type SMS struct{ Sender SMSSender }
+
+func (SMS) Name() string { return ChannelSMS }
+
+// Deliver texts u unless they have no phone number.
+func (c SMS) Deliver(ctx context.Context, u models.User, m Message) error {
+	if u.Phone == "" {
+		return nil
+	}
+	body := strings.TrimSpace(m.Title + "\n" + m.Body)
+	if m.Link != "" {
+		body += "\n" + m.Link
+	}
+	_, err := c.Sender.SendSMS(ctx, u.Phone, body)
+	return err
+}
@@ -0,0 +1,84 @@
+// Package notifications tells users about things that happened to their account over one or
+// more channels: email, SMS and in-app (a table the user reads at GET /me/notifications).
+//
+// Which channels a kind of notification goes to is configuration (notifications.routes), so
+// a deployment can, say, text users about a password change but only show the welcome in the
+// app. Channels skip users they can't reach (no phone number, undeliverable email) silently.
+package notifications
+
+import (
+	"context"
+	"fmt"
+
+	"HelmyTask/models"
+)
+
+// Channel names, as used in routes.
+const (
+	ChannelEmail = "email"
+	ChannelSMS   = "sms"
+	ChannelInApp = "in_app"
+)
+
+// Kinds sent on account events (see services.NewNotificationHooks).
+const (
+	KindWelcome        = "welcome"         // account created
+	KindAccountUpdated = "account_updated" // profile, email or status changed
+	KindNewLogin       = "new_login"       // successful login
+)
+
+// Message is one notification, channel-agnostic: email uses Title as the subject, SMS sends
+// Title and Body as one text, in-app stores all of it.
+type Message struct {
+	Kind  string `json:"kind"`
+	Title string `json:"title"`
+	Body  string `json:"body,omitempty"`
+	Link  string `json:"link,omitempty"`
+}
+
+// Channel delivers messages one way. An error means the delivery may not have happened and
+// can be retried; a user the channel can't reach is not an error.
+type Channel interface {
+	Name() string
+	Deliver(ctx context.Context, u models.User, m Message) error
+}
+
+// Dispatcher routes each kind of message to its channels.
+type Dispatcher struct {
+	channels map[string]Channel
+	routes   map[string][]string // kind → channel names
+}
+
+// NewDispatcher checks that every routed channel exists. Kinds without a route are dropped.
+func NewDispatcher(routes map[string][]string, channels ...Channel) (*Dispatcher, error) {
+	d := &Dispatcher{channels: map[string]Channel{}, routes: routes}
+	for _, c := range channels {
+		d.channels[c.Name()] = c
+	}
+	for kind, names := range routes {
+		for _, name := range names {
+			if d.channels[name] == nil {
+				return nil, fmt.Errorf("notifications: route %q: unknown channel %q", kind, name)
+			}
+		}
+	}
+	return d, nil
+}
+
+// Routed lists the channels kind goes to (none if unrouted).
+func (d *Dispatcher) Routed(kind string) []string {
+	if d == nil {
+		return nil
+	}
+	return d.routes[kind]
+}
+
+// Deliver sends m to u on one channel. Each channel is delivered (and retried) on its own, so
+// a failing SMS provider doesn't repeat the in-app notification.
+func (d *Dispatcher) Deliver(ctx context.Context, u models.User, m Message, channel string) error {
+	c := d.channels[channel]
+	if c == nil {
+		return fmt.Errorf("notifications: unknown channel %q", channel)
+	}
+	return c.Deliver(ctx, u, m)
+}
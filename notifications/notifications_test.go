@@ -0,0 +1,70 @@
+package notifications
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"HelmyTask/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingSMS struct{ to, body []string }
+
+func (s *recordingSMS) SendSMS(_ context.Context, to, body string) (string, error) {
+	s.to, s.body = append(s.to, to), append(s.body, body)
+	return "SM1", nil
+}
+
+func TestDispatcher_RoutesAndChannels(t *testing.T) {
+	_, err := NewDispatcher(map[string][]string{KindWelcome: {"pigeon"}}, SMS{})
+	assert.Error(t, err, "unknown channel in a route")
+
+	sms := &recordingSMS{}
+	d, err := NewDispatcher(map[string][]string{KindNewLogin: {ChannelSMS}}, SMS{Sender: sms})
+	require.NoError(t, err)
+	assert.Equal(t, []string{ChannelSMS}, d.Routed(KindNewLogin))
+	assert.Empty(t, d.Routed(KindWelcome))
+
+	m := Message{Kind: KindNewLogin, Title: "New login", Body: "Was it you?"}
+	require.NoError(t, d.Deliver(context.Background(), models.User{ID: 1}, m, ChannelSMS), "no phone: skipped")
+	require.NoError(t, d.Deliver(context.Background(), models.User{ID: 2, Phone: "+201001234567"}, m, ChannelSMS))
+	assert.Equal(t, []string{"+201001234567"}, sms.to)
+	assert.Equal(t, []string{"New login\nWas it you?"}, sms.body)
+	assert.Error(t, d.Deliver(context.Background(), models.User{}, m, ChannelEmail))
+}
+
+func TestTwilio_SendSMS(t *testing.T) {
+	var got *http.Request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		got = r
+		if r.PostForm.Get("To") == "+1bad" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"code":21211,"message":"invalid To number"}`))
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"sid":"SM123"}`))
+	}))
+	defer srv.Close()
+	old := twilioAPI
+	twilioAPI = srv.URL + "/"
+	defer func() { twilioAPI = old }()
+
+	tw := Twilio{AccountSID: "AC1", AuthToken: "tok", From: "+15005550006", Client: srv.Client()}
+	id, err := tw.SendSMS(context.Background(), "+201001234567", "hi")
+	require.NoError(t, err)
+	assert.Equal(t, "SM123", id)
+	assert.Equal(t, "/AC1/Messages.json", got.URL.Path)
+	user, pass, _ := got.BasicAuth()
+	assert.Equal(t, [2]string{"AC1", "tok"}, [2]string{user, pass})
+	assert.Equal(t, "+15005550006", got.PostForm.Get("From"))
+
+	_, err = tw.SendSMS(context.Background(), "+1bad", "hi")
+	assert.True(t, errors.Is(err, ErrSMSRejected))
+}
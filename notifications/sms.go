@@ -0,0 +1,86 @@
+// SMS providers.
+
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"HelmyTask/utils/redislog"
+)
+
+// SMSSender sends one text message and returns the provider's message ID. to is E.164.
+type SMSSender interface {
+	SendSMS(ctx context.Context, to, body string) (id string, err error)
+}
+
+// LogSMS "sends" by writing the text to the Redis log, for deployments without a provider.
+type LogSMS struct{ Log *redislog.Logger }
+
+// SendSMS logs the message (not its body, which may carry a link meant only for the user).
+func (s LogSMS) SendSMS(_ context.Context, to, body string) (string, error) {
+	if s.Log != nil {
+		s.Log.Info("sms (log provider)", map[string]string{"to": to, "chars": fmt.Sprint(len([]rune(body)))})
+	}
+	return "log", nil
+}
+
+// twilioAPI is the Messages endpoint base; a var so tests can point it at a fake.
+var twilioAPI = "https://api.twilio.com/2010-04-01/Accounts/"
+
+// Twilio sends through Twilio's Messages API (or any provider speaking it).
+type Twilio struct {
+	AccountSID string
+	AuthToken  string
+	From       string // sender number (E.164) or messaging service SID ("MG...")
+	Client     *http.Client
+}
+
+// SendSMS posts the message and returns its SID. 4xx answers other than 429 are not worth
+// retrying (bad number, unverified sender) and come back as ErrSMSRejected.
+func (t Twilio) SendSMS(ctx context.Context, to, body string) (string, error) {
+	form := url.Values{"To": {to}, "Body": {body}}
+	if strings.HasPrefix(t.From, "MG") {
+		form.Set("MessagingServiceSid", t.From)
+	} else {
+		form.Set("From", t.From)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, twilioAPI+url.PathEscape(t.AccountSID)+"/Messages.json", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.SetBasicAuth(t.AccountSID, t.AuthToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	client := t.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	var out struct {
+		SID     string `json:"sid"`
+		Message string `json:"message"`
+	}
+	_ = json.Unmarshal(raw, &out)
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return out.SID, nil
+	case resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests:
+		return "", fmt.Errorf("%w: %d %s", ErrSMSRejected, resp.StatusCode, out.Message)
+	default:
+		return "", fmt.Errorf("sms provider: %d %s", resp.StatusCode, out.Message)
+	}
+}
+
+// ErrSMSRejected is a send the provider refused for good.
+var ErrSMSRejected = errors.New("sms rejected by the provider")
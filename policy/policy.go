@@ -0,0 +1,66 @@
+// Policy engine: maps roles to the permissions they hold.
+// Keeping the table in one place makes the security posture of every role auditable
+// and lets routes ask "may this caller do X?" instead of hard-coding role names.
+
+package policy
+
+import "HelmyTask/models" // Role constants live next to the User model.
+
+// Permission names a single action on a resource (resource:action).
+type Permission string
+
+const (
+	UsersRead   Permission = "users:read"   // list users / read one user
+	UsersCreate Permission = "users:create" // admin-style create
+	UsersUpdate Permission = "users:update" // edit any user (including role changes)
+	UsersDelete Permission = "users:delete" // delete any user
+	UsersFlush  Permission = "users:flush"  // clear a user's cache, logins and rate limits (support tool)
+	LogsRead    Permission = "logs:read"    // tail the application log (entries carry user IDs, errors, stacks)
+	LogsManage  Permission = "logs:manage"  // change the Redis log's size limits; archive old entries
+	AuditRead   Permission = "audit:read"   // read the audit trail
+
+	WebhooksManage  Permission = "webhooks:manage"  // register/list/delete webhook targets
+	DiagnosticsRead Permission = "diagnostics:read" // per-replica runtime state (leadership, uptime)
+	SLORead         Permission = "slo:read"         // availability/latency objectives and error budget
+	OriginsManage   Permission = "origins:manage"   // trusted CORS/CSRF origins
+	SettingsManage  Permission = "settings:manage"  // runtime overrides (log level, rate limits, maintenance, flags)
+	EmailTemplatesRead Permission = "email_templates:read" // list/preview localized email templates
+	IncidentsManage    Permission = "incidents:manage"     // open/update incidents on the public status page
+	ProfilingRead      Permission = "profiling:read"       // CPU/heap/goroutine profiles (/debug/pprof)
+	AccountsTransfer   Permission = "accounts:transfer"    // export/import users with their credentials (encrypted bundles)
+	JobsManage         Permission = "jobs:manage"          // background job queue status; requeue dead jobs
+	DeletionsReview    Permission = "deletions:review"     // approve/reject account deletion requests (approval erases the account)
+)
+
+// rolePermissions is the static grant table. Unknown roles get nothing.
+var rolePermissions = map[string][]Permission{
+	models.RoleAdmin:   {UsersRead, UsersCreate, UsersUpdate, UsersDelete, AuditRead, WebhooksManage, DiagnosticsRead, SLORead, OriginsManage, SettingsManage, EmailTemplatesRead, IncidentsManage, ProfilingRead, AccountsTransfer, UsersFlush, LogsRead, LogsManage, JobsManage, DeletionsReview},
+	models.RoleSupport: {UsersRead, AuditRead, UsersFlush}, // read-only apart from flushing: no create/update/delete
+	models.RoleUser:    {},                     // self-service routes only (/me)
+}
+
+// Allowed reports whether the given role holds the permission.
+func Allowed(role string, p Permission) bool {
+	for _, granted := range rolePermissions[role] {
+		if granted == p {
+			return true
+		}
+	}
+	return false
+}
+
+// Known reports whether p is a permission some role can hold (API key scopes must be).
+func Known(p Permission) bool {
+	for _, granted := range rolePermissions[models.RoleAdmin] { // admins hold every permission
+		if granted == p {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidRole reports whether role is one the policy engine knows about.
+func ValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
@@ -0,0 +1,56 @@
+package policy
+
+import (
+	"testing"
+
+	"HelmyTask/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAllowed_Table(t *testing.T) {
+	tests := []struct {
+		name string
+		role string
+		perm Permission
+		want bool
+	}{
+		{"admin-delete", models.RoleAdmin, UsersDelete, true},
+		{"support-read", models.RoleSupport, UsersRead, true},
+		{"support-audit", models.RoleSupport, AuditRead, true},
+		{"support-update", models.RoleSupport, UsersUpdate, false},
+		{"support-delete", models.RoleSupport, UsersDelete, false},
+		{"admin-webhooks", models.RoleAdmin, WebhooksManage, true},
+		{"support-webhooks", models.RoleSupport, WebhooksManage, false},
+		{"admin-diagnostics", models.RoleAdmin, DiagnosticsRead, true},
+		{"support-diagnostics", models.RoleSupport, DiagnosticsRead, false},
+		{"admin-email-templates", models.RoleAdmin, EmailTemplatesRead, true},
+		{"support-email-templates", models.RoleSupport, EmailTemplatesRead, false},
+		{"admin-incidents", models.RoleAdmin, IncidentsManage, true},
+		{"support-incidents", models.RoleSupport, IncidentsManage, false},
+		{"admin-profiling", models.RoleAdmin, ProfilingRead, true},
+		{"support-profiling", models.RoleSupport, ProfilingRead, false},
+		{"admin-accounts-transfer", models.RoleAdmin, AccountsTransfer, true},
+		{"support-accounts-transfer", models.RoleSupport, AccountsTransfer, false},
+		{"admin-flush", models.RoleAdmin, UsersFlush, true},
+		{"support-flush", models.RoleSupport, UsersFlush, true},
+		{"user-flush", models.RoleUser, UsersFlush, false},
+		{"admin-logs", models.RoleAdmin, LogsRead, true},
+		{"support-logs", models.RoleSupport, LogsRead, false},
+		{"admin-logs-manage", models.RoleAdmin, LogsManage, true},
+		{"support-logs-manage", models.RoleSupport, LogsManage, false},
+		{"admin-jobs", models.RoleAdmin, JobsManage, true},
+		{"support-jobs", models.RoleSupport, JobsManage, false},
+		{"admin-deletions", models.RoleAdmin, DeletionsReview, true},
+		{"support-deletions", models.RoleSupport, DeletionsReview, false},
+		{"user-read", models.RoleUser, UsersRead, false},
+		{"unknown-role", "root", UsersRead, false},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, Allowed(tc.role, tc.perm))
+		})
+	}
+}
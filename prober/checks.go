@@ -0,0 +1,92 @@
+package prober
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+
+	"HelmyTask/models"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// CacheRoundTrip writes a random value to Redis, reads it back and deletes it.
+func CacheRoundTrip(rdb *redis.Client, instance string) Check {
+	return Check{Name: "cache_round_trip", Run: func(ctx context.Context) error {
+		key, want := "probe:cache:"+instance, token()
+		if err := rdb.Set(ctx, key, want, 0).Err(); err != nil {
+			return fmt.Errorf("set: %w", err)
+		}
+		got, err := rdb.Get(ctx, key).Result()
+		if err != nil {
+			return fmt.Errorf("get: %w", err)
+		}
+		_ = rdb.Del(ctx, key).Err()
+		if got != want {
+			return fmt.Errorf("read back %q, wrote %q", got, want)
+		}
+		return nil
+	}}
+}
+
+// heartbeatRow keys the DB round-trip row. Probes run on the singletons leader only, so one
+// fixed row serves every replica; a per-process ID would leave a row behind on every restart.
+const heartbeatRow = "prober"
+
+// DBRoundTrip upserts the heartbeat row and reads it back. Rows left by older builds, which
+// keyed them per process, are removed on the way.
+func DBRoundTrip(db *gorm.DB) Check {
+	return Check{Name: "db_write_read", Run: func(ctx context.Context) error {
+		tx := db.WithContext(ctx)
+		want := token()
+		if err := tx.Save(&models.ProbeHeartbeat{Instance: heartbeatRow, Token: want}).Error; err != nil {
+			return fmt.Errorf("write: %w", err)
+		}
+		var got models.ProbeHeartbeat
+		if err := tx.First(&got, "instance = ?", heartbeatRow).Error; err != nil {
+			return fmt.Errorf("read: %w", err)
+		}
+		if got.Token != want {
+			return fmt.Errorf("read back a stale row")
+		}
+		_ = tx.Where("instance <> ?", heartbeatRow).Delete(&models.ProbeHeartbeat{}).Error // best-effort
+		return nil
+	}}
+}
+
+// Login signs in a canary account through the real login path (hashing, lookup, token issuing).
+// login gets the check's context; the check gives up at its timeout even if login doesn't.
+func Login(login func(ctx context.Context) (string, error)) Check {
+	return Check{Name: "canary_login", Run: func(ctx context.Context) error {
+		type result struct {
+			tok string
+			err error
+		}
+		done := make(chan result, 1) // buffered: a login finishing after the timeout doesn't block
+		go func() {
+			tok, err := login(ctx)
+			done <- result{tok, err}
+		}()
+		var r result
+		select {
+		case r = <-done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if r.err != nil {
+			return r.err
+		}
+		if r.tok == "" {
+			return fmt.Errorf("empty token")
+		}
+		return nil
+	}}
+}
+
+func token() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,147 @@
+// Package prober exercises critical flows (login, cache, database) on a schedule so breakage
+// shows up in the logs and at GET /admin/probes before users report it.
+//
+// Results are kept in the Redis hash "probe:results" (check name -> JSON) so any replica can
+// serve them while only the leader runs the probes.
+package prober
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"HelmyTask/utils/redislog"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// resultsKey holds the latest Result per check.
+const resultsKey = "probe:results"
+
+// Check is one synthetic flow; a nil error means healthy.
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+// Result is the latest outcome of a check.
+type Result struct {
+	Name                string    `json:"name"`
+	OK                  bool      `json:"ok"`
+	Error               string    `json:"error,omitempty"`
+	LatencyMS           int64     `json:"latency_ms"`
+	At                  time.Time `json:"at"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	Alerting            bool      `json:"alerting"` // failures reached the threshold
+}
+
+// Options tune a Prober.
+type Options struct {
+	Interval         time.Duration // between rounds
+	Timeout          time.Duration // per check
+	FailureThreshold int           // consecutive failures before alerting (min 1)
+}
+
+// Prober runs the checks and records results.
+type Prober struct {
+	rdb    *redis.Client
+	log    *redislog.Logger
+	opts   Options
+	checks []Check
+
+	mu       sync.Mutex
+	failures map[string]int
+}
+
+// New creates a prober.
+func New(rdb *redis.Client, rlog *redislog.Logger, opts Options, checks ...Check) *Prober {
+	if opts.FailureThreshold < 1 {
+		opts.FailureThreshold = 1
+	}
+	return &Prober{rdb: rdb, log: rlog, opts: opts, checks: checks, failures: map[string]int{}}
+}
+
+// Run probes every Interval until ctx is done (meant for leader.Group).
+func (p *Prober) Run(ctx context.Context) {
+	t := time.NewTicker(p.opts.Interval)
+	defer t.Stop()
+	for {
+		p.Round(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// Round runs every check once, in order, and records the results.
+func (p *Prober) Round(ctx context.Context) []Result {
+	out := make([]Result, 0, len(p.checks))
+	for _, c := range p.checks {
+		if ctx.Err() != nil {
+			break
+		}
+		out = append(out, p.runOne(ctx, c))
+	}
+	return out
+}
+
+func (p *Prober) runOne(ctx context.Context, c Check) Result {
+	cctx, cancel := context.WithTimeout(ctx, p.opts.Timeout)
+	start := time.Now()
+	err := c.Run(cctx)
+	cancel()
+
+	r := Result{Name: c.Name, OK: err == nil, LatencyMS: time.Since(start).Milliseconds(), At: start.UTC()}
+	p.mu.Lock()
+	prev := p.failures[c.Name]
+	if err != nil {
+		r.Error = err.Error()
+		p.failures[c.Name] = prev + 1
+	} else {
+		p.failures[c.Name] = 0
+	}
+	r.ConsecutiveFailures = p.failures[c.Name]
+	p.mu.Unlock()
+	r.Alerting = r.ConsecutiveFailures >= p.opts.FailureThreshold
+
+	meta := map[string]string{"probe": c.Name, "latency_ms": fmt.Sprint(r.LatencyMS)}
+	switch {
+	case err != nil && r.ConsecutiveFailures == p.opts.FailureThreshold: // alert once per outage
+		meta["err"] = r.Error
+		meta["failures"] = fmt.Sprint(r.ConsecutiveFailures)
+		p.log.Error("probe failing", meta)
+	case err != nil:
+		meta["err"] = r.Error
+		p.log.Warn("probe failed", meta)
+	case prev >= p.opts.FailureThreshold:
+		meta["failures"] = fmt.Sprint(prev)
+		p.log.Info("probe recovered", meta)
+	}
+
+	if b, jerr := json.Marshal(r); jerr == nil {
+		_ = p.rdb.HSet(context.Background(), resultsKey, c.Name, b).Err()
+	}
+	return r
+}
+
+// Results returns the latest result of every check that has run, by name.
+func Results(ctx context.Context, rdb *redis.Client) ([]Result, error) {
+	raw, err := rdb.HGetAll(ctx, resultsKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Result, 0, len(raw))
+	for _, v := range raw {
+		var r Result
+		if json.Unmarshal([]byte(v), &r) == nil {
+			out = append(out, r)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
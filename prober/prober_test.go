@@ -0,0 +1,75 @@
+package prober
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"HelmyTask/mocks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRound_AlertsAfterThresholdAndRecovers(t *testing.T) {
+	rdb, m := mocks.NewRedisMock()
+	anyArgs := func(expected, actual []interface{}) error { return nil } // results embed timings
+	fail := true
+	p := New(rdb, nil, Options{Interval: time.Minute, Timeout: 20 * time.Millisecond, FailureThreshold: 2},
+		Check{Name: "flaky", Run: func(ctx context.Context) error {
+			if fail {
+				return errors.New("boom")
+			}
+			return nil
+		}},
+		Check{Name: "slow", Run: func(ctx context.Context) error { <-ctx.Done(); return ctx.Err() }}, // hits the timeout
+	)
+
+	var last []Result
+	for i := 0; i < 2; i++ {
+		m.CustomMatch(anyArgs).ExpectHSet(resultsKey, "flaky", "").SetVal(1)
+		m.CustomMatch(anyArgs).ExpectHSet(resultsKey, "slow", "").SetVal(1)
+		last = p.Round(context.Background())
+	}
+	assert.Equal(t, "boom", last[0].Error)
+	assert.Equal(t, 2, last[0].ConsecutiveFailures)
+	assert.True(t, last[0].Alerting)
+	assert.Equal(t, context.DeadlineExceeded.Error(), last[1].Error)
+
+	fail = false
+	m.CustomMatch(anyArgs).ExpectHSet(resultsKey, "flaky", "").SetVal(1)
+	m.CustomMatch(anyArgs).ExpectHSet(resultsKey, "slow", "").SetVal(1)
+	last = p.Round(context.Background())
+	assert.True(t, last[0].OK)
+	assert.Zero(t, last[0].ConsecutiveFailures)
+	assert.False(t, last[0].Alerting)
+	assert.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestResults_SortedByName(t *testing.T) {
+	rdb, m := mocks.NewRedisMock()
+	m.ExpectHGetAll(resultsKey).SetVal(map[string]string{
+		"db_write_read":    `{"name":"db_write_read","ok":true}`,
+		"cache_round_trip": `{"name":"cache_round_trip","ok":false,"alerting":true}`,
+	})
+	rs, err := Results(context.Background(), rdb)
+	assert.NoError(t, err)
+	if assert.Len(t, rs, 2) {
+		assert.Equal(t, "cache_round_trip", rs[0].Name)
+		assert.True(t, rs[0].Alerting)
+	}
+}
+
+func TestLogin_GivesUpAtTheCheckTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	c := Login(func(context.Context) (string, error) { <-release; return "tok", nil }) // ignores ctx
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	assert.ErrorIs(t, c.Run(ctx), context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+
+	assert.EqualError(t, Login(func(context.Context) (string, error) { return "", nil }).Run(context.Background()), "empty token")
+}
@@ -0,0 +1,114 @@
+// Data access for API keys (only talks to the DB).
+
+package repositories
+
+import (
+	"time"
+
+	"HelmyTask/core"
+	"HelmyTask/models"
+
+	"gorm.io/gorm"
+)
+
+// APIKeyRepository defines the API key operations the service layer needs.
+type APIKeyRepository interface {
+	Create(k *models.APIKey) error
+	FindActiveByHash(hash string) (*models.APIKey, error) // Only non-revoked keys.
+	ListByUser(userID core.UserID) ([]models.APIKey, error)
+	FindActiveByID(id uint, userID core.UserID) (*models.APIKey, error) // Scoped to owner; ErrRecordNotFound if not theirs/revoked.
+	Rename(id uint, userID core.UserID, name string) error              // Scoped to owner, active keys only.
+	Revoke(id uint, userID core.UserID) error                           // Scoped to owner; ErrRecordNotFound if not theirs/already revoked.
+	Rotate(id uint, userID core.UserID, next *models.APIKey) error      // Revokes id and creates next in one transaction.
+	TouchLastUsed(id uint, at time.Time) error
+}
+
+type apiKeyRepo struct{ db *gorm.DB }
+
+// NewAPIKeyRepository injects *gorm.DB and returns the interface.
+func NewAPIKeyRepository(db *gorm.DB) APIKeyRepository {
+	return &apiKeyRepo{db: db}
+}
+
+// Create inserts a new key row.
+func (r *apiKeyRepo) Create(k *models.APIKey) error {
+	return r.db.Create(k).Error
+}
+
+// FindActiveByHash looks up a non-revoked key by its hash (the lookup used on every request).
+func (r *apiKeyRepo) FindActiveByHash(hash string) (*models.APIKey, error) {
+	var k models.APIKey
+	if err := r.db.Where("key_hash = ? AND revoked_at IS NULL", hash).First(&k).Error; err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+// ListByUser returns all keys (active and revoked) owned by the user, newest first.
+func (r *apiKeyRepo) ListByUser(userID core.UserID) ([]models.APIKey, error) {
+	var items []models.APIKey
+	if err := r.db.Where("user_id = ?", uint(userID)).Order("id DESC").Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// FindActiveByID loads one of the user's non-revoked keys.
+func (r *apiKeyRepo) FindActiveByID(id uint, userID core.UserID) (*models.APIKey, error) {
+	var k models.APIKey
+	if err := r.db.Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, uint(userID)).First(&k).Error; err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+// Rename changes the label of one of the user's active keys.
+func (r *apiKeyRepo) Rename(id uint, userID core.UserID, name string) error {
+	res := r.db.Model(&models.APIKey{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, uint(userID)).
+		Update("name", name)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 { // MySQL counts only changed rows: tell "same name" from "not found"
+		if _, err := r.FindActiveByID(id, userID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Revoke marks the key revoked; it never deletes so the audit trail survives.
+func (r *apiKeyRepo) Revoke(id uint, userID core.UserID) error {
+	return revokeKey(r.db, id, userID)
+}
+
+// Rotate replaces a key: the old one is revoked and next inserted, or neither happens (a key
+// revoked meanwhile yields ErrRecordNotFound).
+func (r *apiKeyRepo) Rotate(id uint, userID core.UserID, next *models.APIKey) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := revokeKey(tx, id, userID); err != nil {
+			return err
+		}
+		return tx.Create(next).Error
+	})
+}
+
+// revokeKey is Revoke on db (Rotate's transaction, or the plain connection).
+func revokeKey(db *gorm.DB, id uint, userID core.UserID) error {
+	res := db.Model(&models.APIKey{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, uint(userID)).
+		Update("revoked_at", time.Now())
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound // Not found, not owned, or already revoked.
+	}
+	return nil
+}
+
+// TouchLastUsed records when the key last authenticated a request.
+func (r *apiKeyRepo) TouchLastUsed(id uint, at time.Time) error {
+	return r.db.Model(&models.APIKey{}).Where("id = ?", id).Update("last_used_at", at).Error
+}
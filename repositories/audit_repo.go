@@ -0,0 +1,62 @@
+// Data access for the audit trail.
+
+package repositories
+
+import (
+	"HelmyTask/models"
+	"HelmyTask/repositories/query"
+
+	"gorm.io/gorm"
+)
+
+// AuditRepository appends and queries audit entries (never updates or deletes them).
+type AuditRepository interface {
+	Create(e *models.AuditLog) error
+	List(f models.AuditFilter, offset, limit int) ([]models.AuditLog, int64, error) // Newest first.
+}
+
+type auditRepo struct{ db *gorm.DB }
+
+// NewAuditRepository injects *gorm.DB and returns the interface.
+func NewAuditRepository(db *gorm.DB) AuditRepository {
+	return &auditRepo{db: db}
+}
+
+// Create appends an entry.
+func (r *auditRepo) Create(e *models.AuditLog) error {
+	return r.db.Create(e).Error
+}
+
+// auditFields whitelists what audit queries may filter on (see the query package).
+var auditFields = query.Schema{
+	"actor_id":   {Column: "actor_id", Ops: []query.Op{query.Eq}},
+	"action":     {Column: "action", Ops: []query.Op{query.Eq}},
+	"created_at": {Column: "created_at", Ops: []query.Op{query.Gte, query.Lte}},
+}
+
+// List returns one page of entries matching f plus the total count.
+func (r *auditRepo) List(f models.AuditFilter, offset, limit int) ([]models.AuditLog, int64, error) {
+	b := query.New(auditFields)
+	if f.ActorID != 0 {
+		b.Where("actor_id", query.Eq, f.ActorID)
+	}
+	if f.Action != "" {
+		b.Where("action", query.Eq, f.Action)
+	}
+	if f.From != nil {
+		b.Where("created_at", query.Gte, *f.From)
+	}
+	if f.To != nil {
+		b.Where("created_at", query.Lte, *f.To)
+	}
+	q := b.Apply(r.db.Model(&models.AuditLog{}))
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var items []models.AuditLog
+	if err := q.Order("id DESC").Offset(offset).Limit(limit).Find(&items).Error; err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
@@ -0,0 +1,183 @@
+// Data access for account deletion requests and the erasure of a user's related rows.
+
+package repositories
+
+import (
+	"fmt"
+	"time"
+
+	"HelmyTask/models"
+	"HelmyTask/repositories/query"
+
+	"gorm.io/gorm"
+)
+
+// DeletionRequestRepository stores deletion requests.
+type DeletionRequestRepository interface {
+	Create(d *models.DeletionRequest) error
+	Update(d *models.DeletionRequest) error
+	FindByID(id uint) (*models.DeletionRequest, error)
+	LatestForUser(userID uint) (*models.DeletionRequest, error)                     // Newest request of the user; ErrRecordNotFound if none.
+	List(status string, offset, limit int) ([]models.DeletionRequest, int64, error) // status "" = any; oldest first (review order).
+	FindOverdue(now time.Time, limit int) ([]models.DeletionRequest, error)         // Pending past DueAt.
+	Decide(d *models.DeletionRequest) (bool, error)                                 // Saves d's decision if still pending; false = decided elsewhere first.
+	ErasePersonalData(userID uint, email string) (models.ErasureCounts, error)      // One transaction; see the implementation.
+	PurgeFinished(before time.Time) (int64, error)                                  // Deletes rejected/expired/completed requests last changed before before.
+}
+
+type deletionRequestRepo struct{ db *gorm.DB }
+
+// NewDeletionRequestRepository injects *gorm.DB and returns the interface.
+func NewDeletionRequestRepository(db *gorm.DB) DeletionRequestRepository {
+	return &deletionRequestRepo{db: db}
+}
+
+// Create inserts a request.
+func (r *deletionRequestRepo) Create(d *models.DeletionRequest) error {
+	return r.db.Create(d).Error
+}
+
+// Update saves every field of an existing request.
+func (r *deletionRequestRepo) Update(d *models.DeletionRequest) error {
+	return r.db.Save(d).Error
+}
+
+// FindByID loads one request.
+func (r *deletionRequestRepo) FindByID(id uint) (*models.DeletionRequest, error) {
+	var d models.DeletionRequest
+	if err := r.db.First(&d, id).Error; err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// LatestForUser returns the user's newest request.
+func (r *deletionRequestRepo) LatestForUser(userID uint) (*models.DeletionRequest, error) {
+	var d models.DeletionRequest
+	if err := r.db.Where("user_id = ?", userID).Order("id DESC").First(&d).Error; err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// deletionFields whitelists what the review queue may filter on (see the query package).
+var deletionFields = query.Schema{
+	"status": {Column: "status", Ops: []query.Op{query.Eq}},
+	"id":     {Column: "id", Sortable: true},
+}
+
+// List returns one page of requests plus the total count, oldest first so the ones closest to
+// their deadline come up first.
+func (r *deletionRequestRepo) List(status string, offset, limit int) ([]models.DeletionRequest, int64, error) {
+	b := query.New(deletionFields)
+	if status != "" {
+		b.Where("status", query.Eq, status)
+	}
+	q := b.Apply(r.db.Model(&models.DeletionRequest{}))
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var items []models.DeletionRequest
+	if err := query.New(deletionFields).OrderBy("id", false).Apply(q).Offset(offset).Limit(limit).Find(&items).Error; err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+// FindOverdue returns pending requests whose review window has passed, oldest first.
+func (r *deletionRequestRepo) FindOverdue(now time.Time, limit int) ([]models.DeletionRequest, error) {
+	var out []models.DeletionRequest
+	err := r.db.Where("status = ? AND due_at <= ?", models.DeletionPending, now).
+		Order("due_at").Limit(limit).Find(&out).Error
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Decide writes d's status and review fields only while the stored row is still pending, so two
+// admins (or an admin and the expiry sweep) can't both decide the same request.
+func (r *deletionRequestRepo) Decide(d *models.DeletionRequest) (bool, error) {
+	res := r.db.Model(&models.DeletionRequest{}).
+		Where("id = ? AND status = ?", d.ID, models.DeletionPending).
+		Updates(map[string]any{"status": d.Status, "reviewer_id": d.ReviewerID, "review_note": d.ReviewNote, "decided_at": d.DecidedAt})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected == 1, nil
+}
+
+// PurgeFinished deletes decided requests that have been closed for a while (scheduler job
+// purge_deletion_requests); the audit trail keeps their history.
+func (r *deletionRequestRepo) PurgeFinished(before time.Time) (int64, error) {
+	res := r.db.Where("status IN ? AND updated_at < ?", []string{models.DeletionRejected, models.DeletionExpired, models.DeletionCompleted}, before).
+		Delete(&models.DeletionRequest{})
+	return res.RowsAffected, res.Error
+}
+
+// ErasedEmail replaces a user's address in rows that are kept after erasure (delivery history).
+func ErasedEmail(userID uint) string {
+	return fmt.Sprintf("erased-%d@erased.invalid", userID)
+}
+
+// ErasedDiff replaces the diff of audit entries about an erased user: the entry (who did what,
+// when) stays, the before/after values (name, email, profile) go.
+const ErasedDiff = `{"erased":{"changed":true}}`
+
+// ErasedPayload replaces the body of webhook deliveries that carried an erased user's data.
+const ErasedPayload = `{"erased":true}`
+
+// ErasePersonalData removes or pseudonymizes what other tables hold about a user, in one
+// transaction:
+//   - their API keys and notifications are deleted;
+//   - email deliveries to their address keep their status but lose the address;
+//   - audit entries about them keep who/what/when but lose the diff, and entries by them lose the IP;
+//   - webhook deliveries whose payload names their address lose the payload (and are not retried).
+//
+// The user row itself is deleted by the user service afterwards (so cache, lifecycle hooks and
+// events follow). Safe to run again.
+func (r *deletionRequestRepo) ErasePersonalData(userID uint, email string) (models.ErasureCounts, error) {
+	var n models.ErasureCounts
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("user_id = ?", userID).Delete(&models.APIKey{})
+		if res.Error != nil {
+			return res.Error
+		}
+		n.APIKeys = res.RowsAffected
+		if res = tx.Where("user_id = ?", userID).Delete(&models.Notification{}); res.Error != nil {
+			return res.Error
+		}
+		n.Notifications = res.RowsAffected
+		res = tx.Model(&models.AuditLog{}).Where("target_type = ? AND target_id = ? AND diff <> ?", "user", userID, ErasedDiff).
+			Updates(map[string]any{"diff": ErasedDiff, "ip": ""})
+		if res.Error != nil {
+			return res.Error
+		}
+		n.AuditLogs = res.RowsAffected
+		if res = tx.Model(&models.AuditLog{}).Where("actor_id = ? AND ip <> ?", userID, "").Update("ip", ""); res.Error != nil {
+			return res.Error
+		}
+		n.AuditLogs += res.RowsAffected
+		if email == "" { // a zero struct condition would match every row
+			return nil
+		}
+		res = tx.Model(&models.EmailDelivery{}).Where(&models.EmailDelivery{To: email}).Update("to", ErasedEmail(userID)) // "to" is a keyword: let the dialect quote it
+		if res.Error != nil {
+			return res.Error
+		}
+		n.EmailDeliveries = res.RowsAffected
+		res = tx.Model(&models.WebhookDelivery{}).Where("payload LIKE ? ESCAPE '!'", "%\""+query.EscapeLike(email)+"\"%").
+			Updates(map[string]any{
+				"payload":         ErasedPayload,
+				"status":          gorm.Expr("CASE WHEN status = ? THEN ? ELSE status END", models.WebhookDeliveryRetrying, models.WebhookDeliveryFailed),
+				"next_attempt_at": nil,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		n.WebhookDeliveries = res.RowsAffected
+		return nil
+	})
+	return n, err
+}
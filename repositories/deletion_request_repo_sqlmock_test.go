@@ -0,0 +1,35 @@
+package repositories
+
+import (
+	"regexp"
+	"testing"
+
+	"HelmyTask/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeletionRequestRepository_ErasePersonalData(t *testing.T) {
+	db, mock, sqlDB := newMySQLMockDB(t)
+	defer sqlDB.Close()
+	repo := NewDeletionRequestRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `api_keys` WHERE user_id = ?")).WithArgs(7).WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `notifications` WHERE user_id = ?")).WithArgs(7).WillReturnResult(sqlmock.NewResult(0, 4))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `audit_logs` SET `diff`=?,`ip`=? WHERE target_type = ? AND target_id = ? AND diff <> ?")).
+		WithArgs(ErasedDiff, "", "user", 7, ErasedDiff).WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `audit_logs` SET `ip`=? WHERE actor_id = ? AND ip <> ?")).
+		WithArgs("", 7, "").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `email_deliveries` SET `to`=?")).WillReturnResult(sqlmock.NewResult(0, 5))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `webhook_deliveries` SET") + ".*" + regexp.QuoteMeta("WHERE payload LIKE ? ESCAPE '!'")).
+		WillReturnResult(sqlmock.NewResult(0, 6))
+	mock.ExpectCommit()
+
+	n, err := repo.ErasePersonalData(7, "gone_1@b.c")
+	require.NoError(t, err)
+	assert.Equal(t, models.ErasureCounts{APIKeys: 2, Notifications: 4, AuditLogs: 4, EmailDeliveries: 5, WebhookDeliveries: 6}, n)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,43 @@
+// Data access for the email delivery log.
+
+package repositories
+
+import (
+	"HelmyTask/models"
+
+	"gorm.io/gorm"
+)
+
+// EmailDeliveryRepository stores sent emails and their provider status.
+type EmailDeliveryRepository interface {
+	Create(d *models.EmailDelivery) error
+	FindByProviderMessageID(id string) (*models.EmailDelivery, error)
+	UpdateStatus(id uint, status, detail string) error
+}
+
+type emailDeliveryRepo struct{ db *gorm.DB }
+
+// NewEmailDeliveryRepository injects *gorm.DB and returns the interface.
+func NewEmailDeliveryRepository(db *gorm.DB) EmailDeliveryRepository {
+	return &emailDeliveryRepo{db: db}
+}
+
+// Create inserts a delivery row.
+func (r *emailDeliveryRepo) Create(d *models.EmailDelivery) error {
+	return r.db.Create(d).Error
+}
+
+// FindByProviderMessageID looks up the row a webhook refers to.
+func (r *emailDeliveryRepo) FindByProviderMessageID(id string) (*models.EmailDelivery, error) {
+	var d models.EmailDelivery
+	if err := r.db.Where("provider_message_id = ?", id).First(&d).Error; err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// UpdateStatus advances the delivery status.
+func (r *emailDeliveryRepo) UpdateStatus(id uint, status, detail string) error {
+	return r.db.Model(&models.EmailDelivery{}).Where("id = ?", id).
+		Updates(map[string]any{"status": status, "detail": detail}).Error
+}
@@ -0,0 +1,57 @@
+// Data access for status page incidents.
+
+package repositories
+
+import (
+	"time"
+
+	"HelmyTask/models"
+
+	"gorm.io/gorm"
+)
+
+// IncidentRepository stores incidents.
+type IncidentRepository interface {
+	Create(i *models.Incident) error
+	Update(i *models.Incident) error
+	FindByID(id uint) (*models.Incident, error)
+	// ListSince returns open incidents plus those resolved after since, newest first.
+	ListSince(since time.Time, limit int) ([]models.Incident, error)
+}
+
+type incidentRepo struct{ db *gorm.DB }
+
+// NewIncidentRepository injects *gorm.DB and returns the interface.
+func NewIncidentRepository(db *gorm.DB) IncidentRepository {
+	return &incidentRepo{db: db}
+}
+
+// Create inserts an incident.
+func (r *incidentRepo) Create(i *models.Incident) error {
+	return r.db.Create(i).Error
+}
+
+// Update saves every field of an existing incident.
+func (r *incidentRepo) Update(i *models.Incident) error {
+	return r.db.Save(i).Error
+}
+
+// FindByID loads one incident.
+func (r *incidentRepo) FindByID(id uint) (*models.Incident, error) {
+	var i models.Incident
+	if err := r.db.First(&i, id).Error; err != nil {
+		return nil, err
+	}
+	return &i, nil
+}
+
+// ListSince returns open incidents and recently resolved ones, newest first.
+func (r *incidentRepo) ListSince(since time.Time, limit int) ([]models.Incident, error) {
+	var out []models.Incident
+	err := r.db.Where("resolved_at IS NULL OR resolved_at >= ?", since).
+		Order("created_at DESC").Order("id DESC").Limit(limit).Find(&out).Error
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
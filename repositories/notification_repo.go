@@ -0,0 +1,86 @@
+// Data access for in-app notifications.
+
+package repositories
+
+import (
+	"time"
+
+	"HelmyTask/models"
+
+	"gorm.io/gorm"
+)
+
+// NotificationRepository stores in-app notifications. Every read and write is scoped to one
+// user, so a notification ID from someone else's list behaves as not found.
+type NotificationRepository interface {
+	Create(n *models.Notification) error
+	ListForUser(userID uint, unreadOnly bool, offset, limit int) ([]models.Notification, int64, error) // Newest first.
+	CountUnread(userID uint) (int64, error)
+	MarkRead(userID, id uint, at time.Time) (bool, error) // false = no such notification of the user (already read is fine).
+	MarkAllRead(userID uint, at time.Time) (int64, error) // How many were unread.
+	DeleteForUser(userID uint) (int64, error)             // On account deletion.
+}
+
+type notificationRepo struct{ db *gorm.DB }
+
+// NewNotificationRepository injects *gorm.DB and returns the interface.
+func NewNotificationRepository(db *gorm.DB) NotificationRepository {
+	return &notificationRepo{db: db}
+}
+
+// Create inserts a notification.
+func (r *notificationRepo) Create(n *models.Notification) error {
+	return r.db.Create(n).Error
+}
+
+// ListForUser returns one page of the user's notifications plus the total count.
+func (r *notificationRepo) ListForUser(userID uint, unreadOnly bool, offset, limit int) ([]models.Notification, int64, error) {
+	q := r.db.Model(&models.Notification{}).Where("user_id = ?", userID)
+	if unreadOnly {
+		q = q.Where("read_at IS NULL")
+	}
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var items []models.Notification
+	if err := q.Order("id DESC").Offset(offset).Limit(limit).Find(&items).Error; err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+// CountUnread counts the user's unread notifications.
+func (r *notificationRepo) CountUnread(userID uint) (int64, error) {
+	var n int64
+	err := r.db.Model(&models.Notification{}).Where("user_id = ? AND read_at IS NULL", userID).Count(&n).Error
+	return n, err
+}
+
+// MarkRead sets ReadAt on one of the user's notifications, keeping the first read time.
+func (r *notificationRepo) MarkRead(userID, id uint, at time.Time) (bool, error) {
+	var n models.Notification
+	if err := r.db.Where("id = ? AND user_id = ?", id, userID).First(&n).Error; err != nil {
+		if IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	if n.ReadAt != nil {
+		return true, nil
+	}
+	err := r.db.Model(&models.Notification{}).Where("id = ? AND read_at IS NULL", id).Update("read_at", at).Error
+	return err == nil, err
+}
+
+// MarkAllRead marks every unread notification of the user read.
+func (r *notificationRepo) MarkAllRead(userID uint, at time.Time) (int64, error) {
+	res := r.db.Model(&models.Notification{}).Where("user_id = ? AND read_at IS NULL", userID).Update("read_at", at)
+	return res.RowsAffected, res.Error
+}
+
+// DeleteForUser removes all of the user's notifications.
+func (r *notificationRepo) DeleteForUser(userID uint) (int64, error) {
+	res := r.db.Where("user_id = ?", userID).Delete(&models.Notification{})
+	return res.RowsAffected, res.Error
+}
@@ -0,0 +1,198 @@
+// Package query builds WHERE and ORDER BY clauses for list endpoints from a whitelist, so
+// caller-chosen filters and sort keys can't smuggle SQL into a query.
+//
+// A repository declares a Schema for its table: the fields a caller may filter or sort on, each
+// mapped to a column written in code and the operators allowed on it. Field names and operators
+// outside the schema are errors; values are always bound parameters, never spliced into the SQL;
+// "contains" patterns have their LIKE wildcards escaped. The rendered SQL therefore depends only
+// on which fields and operators were used, never on the values.
+//
+//	var auditSchema = query.Schema{
+//		"actor_id":   {Column: "actor_id", Ops: []query.Op{query.Eq}},
+//		"created_at": {Column: "created_at", Ops: []query.Op{query.Gte, query.Lte}},
+//		"id":         {Column: "id", Sortable: true},
+//	}
+//
+//	b := query.New(auditSchema).Where("actor_id", query.Eq, 7).OrderBy("id", true)
+//	err := b.Apply(db.Model(&models.AuditLog{})).Find(&out).Error
+package query
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Op is a comparison operator.
+type Op string
+
+// Operators. Contains is a case-sensitive substring match (LIKE with the wildcards in the value
+// escaped); fold the column and the value first for a case-insensitive one.
+const (
+	Eq       Op = "eq"
+	Ne       Op = "ne"
+	Gt       Op = "gt"
+	Gte      Op = "gte"
+	Lt       Op = "lt"
+	Lte      Op = "lte"
+	In       Op = "in"
+	Contains Op = "contains"
+)
+
+// opSQL renders each operator after the column.
+var opSQL = map[Op]string{
+	Eq: "= ?", Ne: "<> ?", Gt: "> ?", Gte: ">= ?", Lt: "< ?", Lte: "<= ?", In: "IN ?",
+	Contains: "LIKE ? ESCAPE '!'", // '!' because a backslash means different things in MySQL and Postgres literals
+}
+
+// Errors from building a query (wrapped with the offending name).
+var (
+	ErrUnknownField = errors.New("query: unknown field")
+	ErrOpNotAllowed = errors.New("query: operator not allowed on field")
+	ErrNotSortable  = errors.New("query: field is not sortable")
+	ErrBadValue     = errors.New("query: value does not suit the operator")
+)
+
+// Field is one filterable/sortable field.
+type Field struct {
+	Column   string // SQL written in code, never from input: "email", "LOWER(email)"
+	Ops      []Op   // operators allowed in filters; none = not filterable
+	Sortable bool   // may be used in OrderBy (Column must then be a plain column name)
+}
+
+// Schema whitelists a table's fields by the name callers use.
+type Schema map[string]Field
+
+// Cond is one comparison, for Any.
+type Cond struct {
+	Field string
+	Op    Op
+	Value any
+}
+
+// Builder collects conditions (ANDed) and sort keys. The first invalid call sets Err and makes
+// Apply fail the query, so a mistake can't silently widen a result set.
+type Builder struct {
+	schema Schema
+	terms  [][]clause.Expr // each term ANDed; the expressions within a term ORed
+	order  []clause.OrderByColumn
+	err    error
+}
+
+// New starts a builder over s.
+func New(s Schema) *Builder {
+	return &Builder{schema: s}
+}
+
+// Where adds "field op value".
+func (b *Builder) Where(field string, op Op, value any) *Builder {
+	return b.Any(Cond{Field: field, Op: op, Value: value})
+}
+
+// Any adds a group of conditions of which at least one must hold (ORed, in parentheses when
+// combined with others). No conditions adds nothing.
+func (b *Builder) Any(conds ...Cond) *Builder {
+	if b.err != nil || len(conds) == 0 {
+		return b
+	}
+	term := make([]clause.Expr, 0, len(conds))
+	for _, c := range conds {
+		e, err := b.render(c)
+		if err != nil {
+			b.err = err
+			return b
+		}
+		term = append(term, e)
+	}
+	b.terms = append(b.terms, term)
+	return b
+}
+
+// OrderBy adds a sort key; the column is quoted as an identifier.
+func (b *Builder) OrderBy(field string, desc bool) *Builder {
+	if b.err != nil {
+		return b
+	}
+	f, ok := b.schema[field]
+	switch {
+	case !ok:
+		b.err = fmt.Errorf("%w: %q", ErrUnknownField, field)
+	case !f.Sortable:
+		b.err = fmt.Errorf("%w: %q", ErrNotSortable, field)
+	default:
+		b.order = append(b.order, clause.OrderByColumn{Column: clause.Column{Name: f.Column}, Desc: desc})
+	}
+	return b
+}
+
+// Err is the first error from building, if any.
+func (b *Builder) Err() error { return b.err }
+
+// Apply adds the conditions and sort keys to tx. With a build error, tx carries it instead, so
+// the query fails rather than running unfiltered.
+func (b *Builder) Apply(tx *gorm.DB) *gorm.DB {
+	if b.err != nil {
+		_ = tx.AddError(b.err)
+		return tx
+	}
+	for _, term := range b.terms {
+		if len(term) == 1 {
+			tx = tx.Where(term[0].SQL, term[0].Vars...)
+			continue
+		}
+		group := tx.Session(&gorm.Session{NewDB: true}).Where(term[0].SQL, term[0].Vars...)
+		for _, e := range term[1:] {
+			group = group.Or(e.SQL, e.Vars...)
+		}
+		tx = tx.Where(group)
+	}
+	for _, o := range b.order {
+		tx = tx.Order(o)
+	}
+	return tx
+}
+
+// render checks c against the schema and returns its SQL with the value bound.
+func (b *Builder) render(c Cond) (clause.Expr, error) {
+	f, ok := b.schema[c.Field]
+	if !ok {
+		return clause.Expr{}, fmt.Errorf("%w: %q", ErrUnknownField, c.Field)
+	}
+	if !allowed(f.Ops, c.Op) {
+		return clause.Expr{}, fmt.Errorf("%w: %q %q", ErrOpNotAllowed, c.Field, c.Op)
+	}
+	value := c.Value
+	switch c.Op {
+	case Contains:
+		s, ok := value.(string)
+		if !ok {
+			return clause.Expr{}, fmt.Errorf("%w: %q contains wants a string", ErrBadValue, c.Field)
+		}
+		value = "%" + EscapeLike(s) + "%"
+	case In:
+		if v := reflect.ValueOf(value); v.Kind() != reflect.Slice || v.Len() == 0 {
+			return clause.Expr{}, fmt.Errorf("%w: %q in wants a non-empty list", ErrBadValue, c.Field)
+		}
+	}
+	return clause.Expr{SQL: f.Column + " " + opSQL[c.Op], Vars: []any{value}}, nil
+}
+
+func allowed(ops []Op, op Op) bool {
+	for _, o := range ops {
+		if o == op {
+			return true
+		}
+	}
+	return false
+}
+
+// likeEscaper makes input literal inside LIKE patterns ('!' is the ESCAPE character; '[' is
+// special on SQL Server).
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_", "[", "![")
+
+// EscapeLike escapes s for use inside a LIKE ... ESCAPE '!' pattern.
+func EscapeLike(s string) string { return likeEscaper.Replace(s) }
@@ -0,0 +1,119 @@
+package query
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+var testFields = Schema{
+	"id":    {Column: "id", Ops: []Op{Gt, In}, Sortable: true},
+	"name":  {Column: "name", Ops: []Op{Eq, Contains}, Sortable: true},
+	"email": {Column: "LOWER(email)", Ops: []Op{Contains}},
+}
+
+type row struct{ ID uint }
+
+// dryRun renders b's SELECT over `rows` without touching a database.
+func dryRun(t testing.TB, b *Builder) (string, []any, error) {
+	sqlDB, _, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { sqlDB.Close() })
+	db, err := gorm.Open(mysql.New(mysql.Config{Conn: sqlDB, SkipInitializeWithVersion: true}), &gorm.Config{DryRun: true})
+	require.NoError(t, err)
+	var out []row
+	stmt := b.Apply(db.Table("rows")).Find(&out)
+	return stmt.Statement.SQL.String(), stmt.Statement.Vars, stmt.Error
+}
+
+func TestBuilder_RendersWhitelistedColumns(t *testing.T) {
+	b := New(testFields).
+		Any(Cond{Field: "name", Op: Contains, Value: "a_b"}, Cond{Field: "email", Op: Contains, Value: "50%"}).
+		Where("id", Gt, 7).
+		OrderBy("name", true)
+	sql, vars, err := dryRun(t, b)
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM `rows` WHERE (name LIKE ? ESCAPE '!' OR LOWER(email) LIKE ? ESCAPE '!') AND id > ? ORDER BY `name` DESC", sql)
+	assert.Equal(t, []any{"%a!_b%", "%50!%%", 7}, vars)
+}
+
+func TestBuilder_RejectsOutsideTheSchema(t *testing.T) {
+	cases := []struct {
+		name string
+		b    *Builder
+		want error
+	}{
+		{"unknown field", New(testFields).Where("password", Eq, "x"), ErrUnknownField},
+		{"column as field", New(testFields).Where("LOWER(email)", Contains, "x"), ErrUnknownField},
+		{"op not allowed", New(testFields).Where("id", Eq, 1), ErrOpNotAllowed},
+		{"not sortable", New(testFields).OrderBy("email", false), ErrNotSortable},
+		{"unknown sort", New(testFields).OrderBy("id; DROP TABLE users", false), ErrUnknownField},
+		{"contains non-string", New(testFields).Where("name", Contains, 1), ErrBadValue},
+		{"empty in", New(testFields).Where("id", In, []int{}), ErrBadValue},
+		{"bad cond in group", New(testFields).Any(Cond{Field: "name", Op: Eq, Value: "a"}, Cond{Field: "nope", Op: Eq}), ErrUnknownField},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.True(t, errors.Is(tc.b.Err(), tc.want), "got %v", tc.b.Err())
+			_, _, err := dryRun(t, tc.b) // the query fails instead of running unfiltered
+			assert.True(t, errors.Is(err, tc.want), "got %v", err)
+		})
+	}
+}
+
+func TestBuilder_FirstErrorSticks(t *testing.T) {
+	b := New(testFields).Where("nope", Eq, 1).Where("name", Eq, "a").OrderBy("id", false)
+	assert.ErrorIs(t, b.Err(), ErrUnknownField)
+	assert.Contains(t, b.Err().Error(), `"nope"`)
+}
+
+func TestEscapeLike(t *testing.T) {
+	assert.Equal(t, "100!% !_off!! ![x]", EscapeLike("100% _off! [x]"))
+}
+
+// FuzzWhere feeds hostile values and field names: the SQL must never depend on a value (it is
+// only ever a bound parameter), contains patterns keep no live wildcards, and names outside the
+// schema never render.
+func FuzzWhere(f *testing.F) {
+	for _, s := range []string{"", "x", "' OR 1=1 --", "a%b_c", "!%", "\\'; DROP TABLE users; --", "name", "[a-z]", "`id`"} {
+		f.Add(s)
+	}
+	baseEq, _, err := dryRun(f, New(testFields).Where("name", Eq, "x"))
+	require.NoError(f, err)
+	baseContains, _, err := dryRun(f, New(testFields).Where("name", Contains, "x"))
+	require.NoError(f, err)
+
+	f.Fuzz(func(t *testing.T, s string) {
+		sql, vars, err := dryRun(t, New(testFields).Where("name", Eq, s))
+		require.NoError(t, err)
+		assert.Equal(t, baseEq, sql)
+		assert.Equal(t, []any{s}, vars)
+
+		sql, vars, err = dryRun(t, New(testFields).Where("name", Contains, s))
+		require.NoError(t, err)
+		assert.Equal(t, baseContains, sql)
+		require.Len(t, vars, 1)
+		pattern := vars[0].(string)
+		inner := strings.TrimSuffix(strings.TrimPrefix(pattern, "%"), "%")
+		for i := 0; i < len(inner); i++ {
+			switch inner[i] {
+			case '!':
+				i++ // escaped character follows
+				require.Less(t, i, len(inner), "dangling escape in %q", pattern)
+			case '%', '_', '[':
+				t.Fatalf("live wildcard in %q", pattern)
+			}
+		}
+
+		if _, ok := testFields[s]; !ok {
+			assert.ErrorIs(t, New(testFields).Where(s, Eq, 1).Err(), ErrUnknownField)
+			assert.ErrorIs(t, New(testFields).OrderBy(s, false).Err(), ErrUnknownField)
+		}
+	})
+}
@@ -0,0 +1,34 @@
+// Aggregate queries behind the admin stats snapshot.
+
+package repositories
+
+import (
+	"context"
+
+	"HelmyTask/models"
+
+	"gorm.io/gorm"
+)
+
+// StatsRepository runs the aggregate queries the scheduler's refresh_stats job caches.
+type StatsRepository interface {
+	CountUsers(ctx context.Context) ([]models.UserCount, error) // One row per (status, role).
+}
+
+type statsRepo struct{ db *gorm.DB }
+
+// NewStatsRepository injects *gorm.DB and returns the interface.
+func NewStatsRepository(db *gorm.DB) StatsRepository {
+	return &statsRepo{db: db}
+}
+
+// CountUsers groups the users table by status and role (a full scan: run it on a schedule,
+// not per request).
+func (r *statsRepo) CountUsers(ctx context.Context) ([]models.UserCount, error) {
+	var out []models.UserCount
+	err := r.db.WithContext(ctx).Model(&models.User{}).
+		Select("status, role, COUNT(*) AS count").
+		Group("status, role").
+		Scan(&out).Error
+	return out, err
+}
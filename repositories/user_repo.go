@@ -1,33 +1,49 @@
 // repository hides GORM details behind an interface—DB-agnostic.
 // Data-access layer. Only talks to the database (via GORM here)-> (only talks to DB, no HTTP/JSON).
 // Implements all repository operations the service needs: Create, FindByID/Email, Update, Delete, and List with total count. Clean and DB-agnostic.
+// Lookups take core.UserID / core.Email value objects so IDs and emails can't be swapped by accident.
 // 3
 package repositories
 
 import (
+	"context" // Every call runs under the caller's context (request deadline, client gone).
+
+	"HelmyTask/core"   // Value objects (UserID, Email) in signatures.
 	"HelmyTask/models" // Import our User model to map results.
+	"HelmyTask/repositories/query" // Whitelisted filters and sort keys for List/ListAfter.
 	"errors"
+	"strings"
 
+	"gorm.io/gorm/clause" // Row locking for DeleteMany and WithTx.
 	"gorm.io/gorm" // GORM DB type is injected so repos are testable/mocked.
 )
 
 // UserRepository defines the operations our service layer expects.
 // Depending on interfaces (not concrete types) helps testability and swapping implementations.
+// Queries run under ctx, so they are cancelled when the request's deadline passes or its client goes away.
 type UserRepository interface {
-	Create(user *models.User) error
-	FindByEmail(email string) (*models.User, error)
-	FindByID(id uint) (*models.User, error)
+	Create(ctx context.Context, user *models.User) error
+	FindByEmail(ctx context.Context, email core.Email) (*models.User, error)
+	FindByID(ctx context.Context, id core.UserID) (*models.User, error)
 	//ADDIGN  THE reamin CRUD
-	Update(user *models.User) error
-	Delete(id uint) error                                 // Delete by primary key.
-	List(offset, limit int) ([]models.User, int64, error) // Page through users + total count.
+	Update(ctx context.Context, user *models.User) error
+	Delete(ctx context.Context, id core.UserID) error                                 // Delete by primary key.
+	CreateBatch(ctx context.Context, users []models.User) error // Multi-row INSERT in one transaction; sets each ID.
+	DeleteMany(ctx context.Context, ids []core.UserID) ([]models.User, error) // One transaction; returns the rows that existed, as they were before the delete.
+	List(ctx context.Context, q models.ListUserQuery, offset, limit int) ([]models.User, int64, error) // Page through users matching q's filters + total count.
+	ListAfter(ctx context.Context, q models.ListUserQuery, afterID uint, limit int) ([]models.User, error) // Keyset page: ids past afterID in q.Order, no count.
+	WithTx(ctx context.Context, fn func(r UserRepository) error) error // Run fn's calls in one transaction; see userRepo.WithTx.
+	Audit() AuditRepository // audit_logs on the same connection: inside WithTx, entries commit with the change.
 
 }
 
 // privvv
 // userRepo is a private struct implementing UserRepository.
 // It holds a *gorm.DB that can connect to any dialect (mysql/postgres/sqlite/sqlserver).
-type userRepo struct{ db *gorm.DB }
+type userRepo struct {
+	db      *gorm.DB
+	locking bool // inside WithTx: finds lock the row they return
+}
 
 // NewUserRepository is a constructor that injects *gorm.DB and returns an interface.
 // This allows main.go to wire dependencies without exposing concrete types to other layers.
@@ -37,36 +53,75 @@ func NewUserRepository(db *gorm.DB) UserRepository {
 }
 
 // Create inserts a new user row using GORM's Create method.
-func (r *userRepo) Create(u *models.User) error {
-	return r.db.Create(u).Error // .Error exposes any DB error to caller.
+func (r *userRepo) Create(ctx context.Context, u *models.User) error {
+	return r.db.WithContext(ctx).Create(u).Error // .Error exposes any DB error to caller.
+}
+
+// WithTx runs fn with a UserRepository bound to one transaction: everything fn does through it
+// commits together when fn returns nil, and rolls back when fn returns an error (or panics).
+// Inside, FindByID and FindByEmail lock the row they return (SELECT ... FOR UPDATE) until the
+// end of the transaction, so a read-modify-write doesn't lose a concurrent update to the same
+// user. WithTx on the bound repository nests (a savepoint).
+func (r *userRepo) WithTx(ctx context.Context, fn func(r UserRepository) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&userRepo{db: tx, locking: true})
+	})
+}
+
+// Audit writes audit entries through r's connection, so inside WithTx they are part of the
+// transaction.
+func (r *userRepo) Audit() AuditRepository {
+	return &auditRepo{db: r.db}
+}
+
+// find is the query a single-row read starts from: locking inside WithTx.
+func (r *userRepo) find(ctx context.Context) *gorm.DB {
+	db := r.db.WithContext(ctx)
+	if r.locking {
+		db = db.Clauses(clause.Locking{Strength: "UPDATE"})
+	}
+	return db
+}
+
+// createBatchSize caps rows per INSERT statement (placeholder limits, packet size).
+const createBatchSize = 100
+
+// CreateBatch inserts users with multi-row INSERTs inside one transaction: all or nothing.
+func (r *userRepo) CreateBatch(ctx context.Context, users []models.User) error {
+	if len(users) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return tx.CreateInBatches(&users, createBatchSize).Error
+	})
 }
 
 // FindByEmail queries for a user with the given email.
 // We use a parameterized query (WHERE email = ?) which GORM compiles safely for the dialect.
-func (r *userRepo) FindByEmail(email string) (*models.User, error) {
+func (r *userRepo) FindByEmail(ctx context.Context, email core.Email) (*models.User, error) {
 	var u models.User
-	if err := r.db.Where("email = ?", email).First(&u).Error; err != nil {
+	if err := r.find(ctx).Where("email = ?", email.String()).First(&u).Error; err != nil {
 		return nil, err
 	}
 	return &u, nil // Return pointer to the found user.
 }
 
-func (r *userRepo) FindByID(id uint) (*models.User, error) {
+func (r *userRepo) FindByID(ctx context.Context, id core.UserID) (*models.User, error) {
 	var u models.User
-	if err := r.db.First(&u, id).Error; err != nil { // First(&u, id) loads where primary key = id.
+	if err := r.find(ctx).First(&u, uint(id)).Error; err != nil { // First(&u, id) loads where primary key = id.
 		return nil, err
 	}
 	return &u, nil
 }
 
 // Update saves fields on an existing user (assumes u has valid ID).
-func (r *userRepo) Update(u *models.User) error {
-	return r.db.Save(u).Error // Save writes all fields; for partial updates use Select/Omit.
+func (r *userRepo) Update(ctx context.Context, u *models.User) error {
+	return r.db.WithContext(ctx).Save(u).Error // Save writes all fields; for partial updates use Select/Omit.
 }
 
 // Delete removes a user row by primary key. If not found, return ErrRecordNotFound.
-func (r *userRepo) Delete(id uint) error {
-	res := r.db.Delete(&models.User{}, id) // Soft delete if GORM soft-deletes are enabled; here it's hard delete.
+func (r *userRepo) Delete(ctx context.Context, id core.UserID) error {
+	res := r.db.WithContext(ctx).Delete(&models.User{}, uint(id)) // Soft delete if GORM soft-deletes are enabled; here it's hard delete.
 	if res.Error != nil {
 		return res.Error                   // Return DB error if any.
 	}
@@ -76,19 +131,98 @@ func (r *userRepo) Delete(id uint) error {
 	return nil
 }
 
-// List returns a page of users and the total count (for pagination UIs).
-func (r *userRepo) List(offset, limit int) ([]models.User, int64, error) {
+// DeleteMany deletes every listed user in one transaction and returns the rows that existed, as
+// they were. The SELECT ... FOR UPDATE pins the rows so the returned ones are exactly the ones
+// deleted (and their contents what the audit log should show as "before").
+func (r *userRepo) DeleteMany(ctx context.Context, ids []core.UserID) ([]models.User, error) {
+	raw := make([]uint, len(ids))
+	for i, id := range ids {
+		raw[i] = uint(id)
+	}
+	var found []models.User
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id IN ?", raw).Order("id").Find(&found).Error; err != nil {
+			return err
+		}
+		if len(found) == 0 {
+			return nil
+		}
+		gone := make([]uint, len(found))
+		for i, u := range found {
+			gone[i] = u.ID
+		}
+		return tx.Where("id IN ?", gone).Delete(&models.User{}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return found, nil
+}
+
+// userFields whitelists what list queries may filter and sort users on (see the query package).
+var userFields = query.Schema{
+	"id":            {Column: "id", Ops: []query.Op{query.Gt, query.Lt}, Sortable: true},
+	"name":          {Column: "name", Sortable: true},
+	"name_search":   {Column: "name_search", Ops: []query.Op{query.Contains}},   // core.FoldSearch(name)
+	"name_skeleton": {Column: "name_skeleton", Ops: []query.Op{query.Contains}}, // core.SearchSkeleton(name)
+	"email":         {Column: "email", Ops: []query.Op{query.Eq}, Sortable: true},
+	"email_lower":   {Column: "LOWER(email)", Ops: []query.Op{query.Contains}},
+	"created_at":    {Column: "created_at", Ops: []query.Op{query.Gte, query.Lt}, Sortable: true},
+	"updated_at":    {Column: "updated_at", Sortable: true},
+}
+
+// filtered builds q's search filters (not paging or sorting).
+func filtered(q models.ListUserQuery) *query.Builder {
+	b := query.New(userFields)
+	if q.Q != "" { // Names match on the folded columns (accents, Arabic script); emails case-insensitively.
+		search := []query.Cond{
+			{Field: "name_search", Op: query.Contains, Value: core.FoldSearch(q.Q)},
+			{Field: "email_lower", Op: query.Contains, Value: strings.ToLower(q.Q)},
+		}
+		if skel := core.SearchSkeleton(q.Q); len(skel) >= minSkeletonSearch { // vowel-blind: "ahmed" finds "أحمد"
+			search = append(search, query.Cond{Field: "name_skeleton", Op: query.Contains, Value: skel})
+		}
+		b.Any(search...) // grouped in parentheses
+	}
+	if q.Email != "" {
+		b.Where("email", query.Eq, q.Email)
+	}
+	if q.CreatedAfter != nil {
+		b.Where("created_at", query.Gte, *q.CreatedAfter)
+	}
+	if q.CreatedBefore != nil {
+		b.Where("created_at", query.Lt, *q.CreatedBefore)
+	}
+	return b
+}
+
+// minSkeletonSearch is the shortest consonant skeleton worth matching; shorter ones
+// ("ali" → "al") would match far too many names.
+const minSkeletonSearch = 3
+
+// List returns a page of users matching the filters and their total count (for pagination UIs).
+func (r *userRepo) List(ctx context.Context, q models.ListUserQuery, offset, limit int) ([]models.User, int64, error) {
 	var (
 		items []models.User // Slice to collect this page.
-		total int64         // Total rows in table.
+		total int64         // Total matching rows.
 	)
-	if err := r.db.Model(&models.User{}).Count(&total).Error; err != nil {
+	b := filtered(q)
+	tx := b.Apply(r.db.WithContext(ctx).Model(&models.User{}))
+	if err := tx.Count(&total).Error; err != nil {
 		return nil, 0, err // Counting failed → return error.
 	}
-	if err := r.db.
+	sortCol := q.Sort // Whitelisted by the service, and again by userFields.
+	if sortCol == "" {
+		sortCol = "id"
+	}
+	tx = query.New(userFields).OrderBy(sortCol, q.Order == "desc").Apply(tx)
+	if sortCol != "id" {
+		tx = tx.Order("id ASC") // Tie-breaker keeps pages deterministic.
+	}
+	if err := tx.
 		Limit(limit).      // Restrict page size.
 		Offset(offset).    // Start from offset (page-1)*limit.
-		Order("id ASC").   // Deterministic ordering.
 		Find(&items).      // Load rows into slice.
 		Error; err != nil {
 		return nil, 0, err // Find failed → return error.
@@ -100,3 +234,20 @@ func (r *userRepo) List(offset, limit int) ([]models.User, int64, error) {
 func IsNotFound(err error) bool {
 	return errors.Is(err, gorm.ErrRecordNotFound) // True if wrapped or direct ErrRecordNotFound.
 }
+
+// ListAfter returns up to limit users after afterID (0 = from the start) in id order.
+// Seeks via the primary key index, so deep pages cost the same as the first one.
+func (r *userRepo) ListAfter(ctx context.Context, q models.ListUserQuery, afterID uint, limit int) ([]models.User, error) {
+	b := filtered(q)
+	desc := q.Order == "desc"
+	if afterID != 0 {
+		if desc {
+			b.Where("id", query.Lt, afterID)
+		} else {
+			b.Where("id", query.Gt, afterID)
+		}
+	}
+	var items []models.User
+	err := b.OrderBy("id", desc).Apply(r.db.WithContext(ctx).Model(&models.User{})).Limit(limit).Find(&items).Error
+	return items, err
+}
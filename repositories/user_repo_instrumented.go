@@ -0,0 +1,122 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"HelmyTask/core"
+	"HelmyTask/models"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+	"gorm.io/gorm"
+)
+
+// Results a CallRecorder sees.
+const (
+	CallOK       = "ok"
+	CallNotFound = "not_found" // gorm.ErrRecordNotFound: an answer, not a failure
+	CallError    = "error"
+)
+
+// CallRecorder is satisfied by *metrics.Metrics.
+type CallRecorder interface {
+	RepositoryCall(repository, method, result string, elapsed time.Duration)
+}
+
+// instrumentedUserRepo decorates a UserRepository with per-method metrics and spans. Each
+// method is measured as a whole, so a List (count + page) or DeleteMany (lock + delete)
+// shows up as one operation, whatever GORM runs underneath.
+type instrumentedUserRepo struct {
+	next   UserRepository
+	rec    CallRecorder // nil = no metrics
+	tracer trace.Tracer
+}
+
+// NewInstrumentedUserRepository wraps next. rec and tp may be nil (metrics or tracing off).
+func NewInstrumentedUserRepository(next UserRepository, rec CallRecorder, tp trace.TracerProvider) UserRepository {
+	if tp == nil {
+		tp = noop.NewTracerProvider()
+	}
+	return &instrumentedUserRepo{next: next, rec: rec, tracer: tp.Tracer("HelmyTask/repositories")}
+}
+
+// observe times call, records the outcome and wraps it in a "UserRepository.<method>" span,
+// a child of the request's span when ctx carries one; call gets the span's context.
+func (r *instrumentedUserRepo) observe(ctx context.Context, method string, call func(ctx context.Context) error) error {
+	ctx, span := r.tracer.Start(ctx, "UserRepository."+method,
+		trace.WithAttributes(attribute.String("repository", "user"), attribute.String("repository.method", method)))
+	defer span.End()
+	start := time.Now()
+	err := call(ctx)
+	result := CallOK
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		result = CallNotFound
+	case err != nil:
+		result = CallError
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	if r.rec != nil {
+		r.rec.RepositoryCall("user", method, result, time.Since(start))
+	}
+	return err
+}
+
+func (r *instrumentedUserRepo) Create(ctx context.Context, u *models.User) error {
+	return r.observe(ctx, "Create", func(ctx context.Context) error { return r.next.Create(ctx, u) })
+}
+
+func (r *instrumentedUserRepo) FindByEmail(ctx context.Context, email core.Email) (u *models.User, err error) {
+	err = r.observe(ctx, "FindByEmail", func(ctx context.Context) error { u, err = r.next.FindByEmail(ctx, email); return err })
+	return u, err
+}
+
+func (r *instrumentedUserRepo) FindByID(ctx context.Context, id core.UserID) (u *models.User, err error) {
+	err = r.observe(ctx, "FindByID", func(ctx context.Context) error { u, err = r.next.FindByID(ctx, id); return err })
+	return u, err
+}
+
+func (r *instrumentedUserRepo) Update(ctx context.Context, u *models.User) error {
+	return r.observe(ctx, "Update", func(ctx context.Context) error { return r.next.Update(ctx, u) })
+}
+
+func (r *instrumentedUserRepo) Delete(ctx context.Context, id core.UserID) error {
+	return r.observe(ctx, "Delete", func(ctx context.Context) error { return r.next.Delete(ctx, id) })
+}
+
+func (r *instrumentedUserRepo) CreateBatch(ctx context.Context, users []models.User) error {
+	return r.observe(ctx, "CreateBatch", func(ctx context.Context) error { return r.next.CreateBatch(ctx, users) })
+}
+
+func (r *instrumentedUserRepo) DeleteMany(ctx context.Context, ids []core.UserID) (deleted []models.User, err error) {
+	err = r.observe(ctx, "DeleteMany", func(ctx context.Context) error { deleted, err = r.next.DeleteMany(ctx, ids); return err })
+	return deleted, err
+}
+
+func (r *instrumentedUserRepo) List(ctx context.Context, q models.ListUserQuery, offset, limit int) (items []models.User, total int64, err error) {
+	err = r.observe(ctx, "List", func(ctx context.Context) error { items, total, err = r.next.List(ctx, q, offset, limit); return err })
+	return items, total, err
+}
+
+func (r *instrumentedUserRepo) ListAfter(ctx context.Context, q models.ListUserQuery, afterID uint, limit int) (items []models.User, err error) {
+	err = r.observe(ctx, "ListAfter", func(ctx context.Context) error { items, err = r.next.ListAfter(ctx, q, afterID, limit); return err })
+	return items, err
+}
+
+// Audit is passed through unmeasured: audit writes are not user queries.
+func (r *instrumentedUserRepo) Audit() AuditRepository { return r.next.Audit() }
+
+// WithTx is measured as a whole (the transaction, fn included); the calls fn makes through the
+// bound repository are measured on their own as well.
+func (r *instrumentedUserRepo) WithTx(ctx context.Context, fn func(UserRepository) error) error {
+	return r.observe(ctx, "WithTx", func(ctx context.Context) error {
+		return r.next.WithTx(ctx, func(tx UserRepository) error {
+			return fn(&instrumentedUserRepo{next: tx, rec: r.rec, tracer: r.tracer})
+		})
+	})
+}
@@ -0,0 +1,67 @@
+package repositories_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"HelmyTask/core"
+	"HelmyTask/mocks"
+	"HelmyTask/models"
+	"HelmyTask/repositories"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"gorm.io/gorm"
+)
+
+type recordedCall struct{ repository, method, result string }
+
+type fakeCallRecorder struct{ calls []recordedCall }
+
+func (f *fakeCallRecorder) RepositoryCall(repository, method, result string, _ time.Duration) {
+	f.calls = append(f.calls, recordedCall{repository, method, result})
+}
+
+func TestInstrumentedUserRepository_RecordsOutcomeAndSpans(t *testing.T) {
+	next := new(mocks.UserRepositoryMock)
+	next.On("FindByID", core.UserID(1)).Return(&models.User{ID: 1}, nil)
+	next.On("FindByID", core.UserID(2)).Return(nil, gorm.ErrRecordNotFound)
+	next.On("List", models.ListUserQuery{}, 0, 20).Return(nil, int64(0), errors.New("connection reset"))
+	rec := &fakeCallRecorder{}
+	spans := tracetest.NewSpanRecorder()
+	repo := repositories.NewInstrumentedUserRepository(next, rec, sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)))
+
+	u, err := repo.FindByID(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, uint(1), u.ID, "results pass through")
+	_, err = repo.FindByID(context.Background(), 2)
+	assert.True(t, repositories.IsNotFound(err))
+	_, _, err = repo.List(context.Background(), models.ListUserQuery{}, 0, 20)
+	assert.EqualError(t, err, "connection reset")
+
+	assert.Equal(t, []recordedCall{
+		{"user", "FindByID", repositories.CallOK},
+		{"user", "FindByID", repositories.CallNotFound},
+		{"user", "List", repositories.CallError},
+	}, rec.calls)
+	ended := spans.Ended()
+	require.Len(t, ended, 3)
+	assert.Equal(t, "UserRepository.FindByID", ended[0].Name())
+	assert.Equal(t, codes.Unset, ended[1].Status().Code, "not found is not a failed span")
+	assert.Equal(t, codes.Error, ended[2].Status().Code)
+	next.AssertExpectations(t)
+}
+
+func TestInstrumentedUserRepository_NilRecorderAndTracer(t *testing.T) {
+	next := new(mocks.UserRepositoryMock)
+	next.On("Delete", core.UserID(3)).Return(nil)
+	repo := repositories.NewInstrumentedUserRepository(next, nil, nil)
+
+	assert.NoError(t, repo.Delete(context.Background(), 3))
+	next.AssertExpectations(t)
+}
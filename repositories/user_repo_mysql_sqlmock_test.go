@@ -1,11 +1,14 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"regexp"
 	"testing"
 	"time"
 
+	"HelmyTask/core"
 	"HelmyTask/models"
 
 	"github.com/DATA-DOG/go-sqlmock"
@@ -41,13 +44,13 @@ func TestUserRepository_Create(t *testing.T) {
 	// GORM INSERT: we match the table and columns. Exact SQL can differ slightly,
 	// so we use a regexp with only the important bits.
 	mock.ExpectBegin()
-	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `users` (`name`,`email`,`password`,`created_at`,`updated_at`) VALUES (?,?,?,?,?)")).
-		WithArgs("Ahmed", "a@b.c", "hash", sqlmock.AnyArg(), sqlmock.AnyArg()).
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `users` (`name`,`email`,`password`,`role`,`totp_secret`,`totp_enabled`,`totp_last_step`,`email_undeliverable`,`status`,`name_search`,`name_skeleton`,`phone`,`bio`,`date_of_birth`,`locale`,`timezone`,`created_at`,`updated_at`) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)")).
+		WithArgs("Ahmed", "a@b.c", "hash", "user", "", false, 0, false, "active", "ahmed", "ahmd", "", "", "", "", "", sqlmock.AnyArg(), sqlmock.AnyArg()).
 		WillReturnResult(sqlmock.NewResult(1, 1)) // last insert id=1, affected=1
 	mock.ExpectCommit()
 
-	u := &models.User{Name: "Ahmed", Email: "a@b.c", Password: "hash", CreatedAt: now, UpdatedAt: now}
-	err := repo.Create(u)
+	u := &models.User{Name: "Ahmed", Email: "a@b.c", Password: "hash", Role: models.RoleUser, CreatedAt: now, UpdatedAt: now}
+	err := repo.Create(context.Background(), u)
 	require.NoError(t, err)
 	assert.Equal(t, uint(1), u.ID) // GORM maps last insert id
 	require.NoError(t, mock.ExpectationsWereMet())
@@ -70,7 +73,7 @@ func TestUserRepository_FindByEmail(t *testing.T) {
 	)).WithArgs(email, sqlmock.AnyArg()).
 		WillReturnRows(rows)
 
-	u, err := repo.FindByEmail("a@b.c")
+	u, err := repo.FindByEmail(context.Background(), "a@b.c")
 	require.NoError(t, err)
 	assert.Equal(t, uint(2), u.ID)
 	require.NoError(t, mock.ExpectationsWereMet())
@@ -87,7 +90,135 @@ func TestUserRepository_Delete_NotFound(t *testing.T) {
 		WillReturnResult(sqlmock.NewResult(0, 0)) // RowsAffected = 0 -> not found
 	mock.ExpectCommit()
 
-	err := repo.Delete(999)
+	err := repo.Delete(context.Background(), 999)
 	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
 	require.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestUserRepository_List_Filters(t *testing.T) {
+	db, mock, sqlDB := newMySQLMockDB(t)
+	defer sqlDB.Close()
+
+	repo := NewUserRepository(db)
+	after := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// LIKE wildcards in the search term are matched literally
+	where := "WHERE (name_search LIKE ? ESCAPE '!' OR LOWER(email) LIKE ? ESCAPE '!') AND created_at >= ?"
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT count(*) FROM `users` " + where)).
+		WithArgs("%50!%%", "%50!%%", after).
+		WillReturnRows(sqlmock.NewRows([]string{"count(*)"}).AddRow(1))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `users` " + where + " ORDER BY `created_at` DESC,id ASC LIMIT ?")).
+		WithArgs("%50!%%", "%50!%%", after, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email"}).AddRow(3, "Fifty", "50%off@b.c"))
+
+	items, total, err := repo.List(context.Background(), models.ListUserQuery{Q: "50%", CreatedAfter: &after, Sort: "created_at", Order: "desc"}, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Len(t, items, 1)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserRepository_List_FoldedNameSearch(t *testing.T) {
+	db, mock, sqlDB := newMySQLMockDB(t)
+	defer sqlDB.Close()
+	repo := NewUserRepository(db)
+
+	// accents folded; the consonant skeleton also matches Arabic-script names ("أحمد" → "ahmd")
+	where := "WHERE name_search LIKE ? ESCAPE '!' OR LOWER(email) LIKE ? ESCAPE '!' OR name_skeleton LIKE ? ESCAPE '!'"
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT count(*) FROM `users` " + where)).
+		WithArgs("%ahmed%", "%ahméd%", "%ahmd%").
+		WillReturnRows(sqlmock.NewRows([]string{"count(*)"}).AddRow(0))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `users` " + where)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	_, total, err := repo.List(context.Background(), models.ListUserQuery{Q: "Ahméd", Sort: "id", Order: "asc"}, 0, 10)
+	require.NoError(t, err)
+	assert.Zero(t, total)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserRepository_DeleteMany_OneTransaction(t *testing.T) {
+	db, mock, sqlDB := newMySQLMockDB(t)
+	defer sqlDB.Close()
+	repo := NewUserRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `users` WHERE id IN (?,?,?) ORDER BY id FOR UPDATE")).
+		WithArgs(1, 2, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "email"}).AddRow(1, "a@b.c").AddRow(3, "c@b.c")) // 2 doesn't exist
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `users` WHERE id IN (?,?)")).
+		WithArgs(1, 3).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	deleted, err := repo.DeleteMany(context.Background(), []core.UserID{1, 2, 3})
+	require.NoError(t, err)
+	require.Len(t, deleted, 2)
+	assert.Equal(t, uint(1), deleted[0].ID)
+	assert.Equal(t, "c@b.c", deleted[1].Email) // the row as it was, for the audit log
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserRepository_CreateBatch_MultiRowInsert(t *testing.T) {
+	db, mock, sqlDB := newMySQLMockDB(t)
+	defer sqlDB.Close()
+	repo := NewUserRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `users` .* VALUES \\(.*\\),\\(.*\\)$").
+		WillReturnResult(sqlmock.NewResult(7, 2)) // MySQL reports the first auto-increment id
+	mock.ExpectCommit()
+
+	users := []models.User{{Name: "A", Email: "a@b.c", Password: "h"}, {Name: "B", Email: "b@b.c", Password: "h"}}
+	require.NoError(t, repo.CreateBatch(context.Background(), users))
+	assert.Equal(t, uint(7), users[0].ID)
+	assert.Equal(t, uint(8), users[1].ID)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserRepository_WithTx_LocksAndCommits(t *testing.T) {
+	db, mock, sqlDB := newMySQLMockDB(t)
+	defer sqlDB.Close()
+	repo := NewUserRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `users` WHERE `users`.`id` = ? ORDER BY `users`.`id` LIMIT ? FOR UPDATE")).
+		WithArgs(5, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email"}).AddRow(5, "Ahmed", "a@b.c"))
+	mock.ExpectExec("UPDATE `users` SET .* WHERE `id` = \\?").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := repo.WithTx(context.Background(), func(tx UserRepository) error {
+		u, err := tx.FindByID(context.Background(), 5)
+		if err != nil {
+			return err
+		}
+		u.Name = "Ahmed Helmy"
+		return tx.Update(context.Background(), u)
+	})
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserRepository_WithTx_RollsBackOnError(t *testing.T) {
+	db, mock, sqlDB := newMySQLMockDB(t)
+	defer sqlDB.Close()
+	repo := NewUserRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `users` WHERE email = ? ORDER BY `users`.`id` LIMIT ? FOR UPDATE")).
+		WithArgs("taken@b.c", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "email"}).AddRow(9, "taken@b.c"))
+	mock.ExpectRollback()
+
+	conflict := errors.New("email already exists")
+	err := repo.WithTx(context.Background(), func(tx UserRepository) error {
+		if _, err := tx.FindByEmail(context.Background(), "taken@b.c"); err == nil {
+			return conflict
+		}
+		return nil
+	})
+	assert.ErrorIs(t, err, conflict)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
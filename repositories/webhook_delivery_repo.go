@@ -0,0 +1,92 @@
+// Data access for the webhook delivery log.
+
+package repositories
+
+import (
+	"time"
+
+	"HelmyTask/models"
+	"HelmyTask/repositories/query"
+
+	"gorm.io/gorm"
+)
+
+// WebhookDeliveryRepository stores webhook delivery attempts.
+type WebhookDeliveryRepository interface {
+	Create(d *models.WebhookDelivery) error
+	Update(d *models.WebhookDelivery) error
+	FindByIDs(ids []uint) ([]models.WebhookDelivery, error)
+	List(f models.WebhookDeliveryFilter, offset, limit int) ([]models.WebhookDelivery, int64, error) // Newest first.
+	FindDue(now time.Time, limit int) ([]models.WebhookDelivery, error)                              // Retrying, next attempt at or before now; oldest due first.
+}
+
+type webhookDeliveryRepo struct{ db *gorm.DB }
+
+// NewWebhookDeliveryRepository injects *gorm.DB and returns the interface.
+func NewWebhookDeliveryRepository(db *gorm.DB) WebhookDeliveryRepository {
+	return &webhookDeliveryRepo{db: db}
+}
+
+// Create inserts a delivery row.
+func (r *webhookDeliveryRepo) Create(d *models.WebhookDelivery) error {
+	return r.db.Create(d).Error
+}
+
+// Update saves a delivery after a (re)attempt.
+func (r *webhookDeliveryRepo) Update(d *models.WebhookDelivery) error {
+	return r.db.Save(d).Error
+}
+
+// FindByIDs loads the given deliveries (missing IDs are skipped).
+func (r *webhookDeliveryRepo) FindByIDs(ids []uint) ([]models.WebhookDelivery, error) {
+	var out []models.WebhookDelivery
+	if err := r.db.Where("id IN ?", ids).Order("id ASC").Find(&out).Error; err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// FindDue loads deliveries waiting for an automatic retry whose time has come.
+func (r *webhookDeliveryRepo) FindDue(now time.Time, limit int) ([]models.WebhookDelivery, error) {
+	var out []models.WebhookDelivery
+	err := r.db.Where("status = ? AND next_attempt_at <= ?", models.WebhookDeliveryRetrying, now).
+		Order("next_attempt_at ASC").Limit(limit).Find(&out).Error
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// deliveryFields whitelists what delivery log queries may filter on (see the query package).
+var deliveryFields = query.Schema{
+	"status":     {Column: "status", Ops: []query.Op{query.Eq}},
+	"webhook_id": {Column: "webhook_id", Ops: []query.Op{query.Eq}},
+	"created_at": {Column: "created_at", Ops: []query.Op{query.Gte, query.Lte}},
+}
+
+// List returns one page of deliveries matching f plus the total count.
+func (r *webhookDeliveryRepo) List(f models.WebhookDeliveryFilter, offset, limit int) ([]models.WebhookDelivery, int64, error) {
+	b := query.New(deliveryFields)
+	if f.Status != "" {
+		b.Where("status", query.Eq, f.Status)
+	}
+	if f.WebhookID != 0 {
+		b.Where("webhook_id", query.Eq, f.WebhookID)
+	}
+	if f.From != nil {
+		b.Where("created_at", query.Gte, *f.From)
+	}
+	if f.To != nil {
+		b.Where("created_at", query.Lte, *f.To)
+	}
+	q := b.Apply(r.db.Model(&models.WebhookDelivery{}))
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var items []models.WebhookDelivery
+	if err := q.Order("id DESC").Offset(offset).Limit(limit).Find(&items).Error; err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
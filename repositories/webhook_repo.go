@@ -0,0 +1,59 @@
+// Data access for webhook targets.
+
+package repositories
+
+import (
+	"HelmyTask/models"
+
+	"gorm.io/gorm"
+)
+
+// WebhookRepository stores registered webhook targets.
+type WebhookRepository interface {
+	Create(w *models.Webhook) error
+	List() ([]models.Webhook, error)
+	FindByID(id uint) (*models.Webhook, error)
+	Delete(id uint) error // ErrRecordNotFound if absent.
+}
+
+type webhookRepo struct{ db *gorm.DB }
+
+// NewWebhookRepository injects *gorm.DB and returns the interface.
+func NewWebhookRepository(db *gorm.DB) WebhookRepository {
+	return &webhookRepo{db: db}
+}
+
+// Create inserts a webhook row.
+func (r *webhookRepo) Create(w *models.Webhook) error {
+	return r.db.Create(w).Error
+}
+
+// List returns all webhooks, oldest first.
+func (r *webhookRepo) List() ([]models.Webhook, error) {
+	var out []models.Webhook
+	if err := r.db.Order("id ASC").Find(&out).Error; err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// FindByID loads one webhook.
+func (r *webhookRepo) FindByID(id uint) (*models.Webhook, error) {
+	var w models.Webhook
+	if err := r.db.First(&w, id).Error; err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+// Delete removes a webhook.
+func (r *webhookRepo) Delete(id uint) error {
+	res := r.db.Delete(&models.Webhook{}, id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package requestsign
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignVerify(t *testing.T) {
+	body := []byte(`{"name":"ci"}`)
+	at := time.Unix(1_700_000_000, 0)
+	h := Sign("hks_1", "POST", "/api/v1/users?dry=1", body, at)
+
+	assert.NoError(t, Verify("hks_1", h, "post", "/api/v1/users?dry=1", body, 0, at.Add(time.Minute)))
+	assert.ErrorIs(t, Verify("hks_2", h, "POST", "/api/v1/users?dry=1", body, 0, at), ErrBadSignature)
+	assert.ErrorIs(t, Verify("hks_1", h, "PUT", "/api/v1/users?dry=1", body, 0, at), ErrBadSignature, "method is signed")
+	assert.ErrorIs(t, Verify("hks_1", h, "POST", "/api/v1/users", body, 0, at), ErrBadSignature, "query is signed")
+	assert.ErrorIs(t, Verify("hks_1", h, "POST", "/api/v1/users?dry=1", []byte(`{"name":"x"}`), 0, at), ErrBadSignature)
+	assert.ErrorIs(t, Verify("hks_1", h, "POST", "/api/v1/users?dry=1", body, 0, at.Add(10*time.Minute)), ErrStaleRequest)
+	assert.ErrorIs(t, Verify("hks_1", "t=abc,v1=00", "POST", "/", nil, 0, at), ErrNoSignature)
+	assert.ErrorIs(t, Verify("hks_1", "", "POST", "/", nil, 0, at), ErrNoSignature)
+}
@@ -0,0 +1,260 @@
+package routes
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"HelmyTask/middlewares"
+	"HelmyTask/policy"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RoutePolicy is what an endpoint runs behind. Setup builds each route's middleware chain
+// from its entry in Policies, in this order: rate limit, auth, permission, timeout, cache.
+// MaxBody is enforced earlier, by a global middleware, so no middleware reads a body past it.
+type RoutePolicy struct {
+	Auth       bool              // API key, else JWT/session (per auth_mode)
+	Permission policy.Permission // role check by the policy engine; requires Auth
+	RateLimit  string            // rate limit group ("auth"), per client IP; "" = none
+	Timeout    time.Duration     // request context deadline (see middlewares.Timeout); 0 = the route group's, NoTimeout = none
+	Cache      string            // default Cache-Control; "" = none (handlers may set their own)
+	MaxBody    int64             // request body cap in bytes; 0 = Deps.MaxBodyBytes
+}
+
+// NoTimeout as a policy Timeout exempts a route from its group's deadline (streamed responses).
+const NoTimeout time.Duration = -1
+
+// Policies declares every endpoint's policy, keyed "METHOD /full/path" as gin registers it.
+// Setup refuses to start with a route missing from here, so this table is the complete list
+// of what is public, what needs a login and which permission guards what. Entries for
+// optional features that are switched off are simply unused.
+var Policies = map[string]RoutePolicy{
+	// Public: probes, docs, discovery.
+	"GET /healthz":                {},
+	"GET /readyz":                 {Timeout: 10 * time.Second},
+	"GET /ping":                   {RateLimit: "ping", Cache: "no-store"}, // external uptime monitors
+	"GET /metrics":                {},                                     // keep it off the public ingress
+	"GET /swagger.yaml":           {},
+	"GET /api/changelog":          {},
+	"GET /api/errors":             {},
+	"GET /api/errors/:code":       {},
+	"GET /.well-known/jwks.json":  {},
+	"GET /status":                 {Timeout: 10 * time.Second},
+	"POST /api/v1/webhooks/email": {}, // shared secret checked by the handler
+
+	// Public auth endpoints, rate limited per client IP.
+	"POST /api/v1/auth/register":          {RateLimit: "auth"},
+	"POST /api/v1/auth/password-strength": {RateLimit: "auth"},
+	"GET /api/v1/auth/lockout-status":     {RateLimit: "auth", Cache: "no-store"},
+	"POST /api/v1/auth/login":             {RateLimit: "auth", Timeout: 10 * time.Second},
+	"POST /api/v1/auth/logout":            {Timeout: 10 * time.Second}, // session mode only; not limited: ending a session must always work
+
+	// First-run setup: public, but it only works while there are no users.
+	"GET /api/v1/setup":  {Cache: "no-store"},
+	"POST /api/v1/setup": {RateLimit: "auth", Cache: "no-store"},
+
+	// The current user.
+	"GET /api/v1/me":                         {Auth: true, Cache: "no-store"},
+	"PUT /api/v1/me":                         {Auth: true, Cache: "no-store"},
+	"DELETE /api/v1/me":                      {Auth: true},
+	"POST /api/v1/me/password":               {Auth: true},
+	"POST /api/v1/me/2fa/enable":             {Auth: true, Cache: "no-store"}, // the TOTP secret
+	"POST /api/v1/me/2fa/confirm":            {Auth: true},
+	"GET /api/v1/me/api-keys":                {Auth: true, Cache: "no-store"},
+	"POST /api/v1/me/api-keys":               {Auth: true, Cache: "no-store"}, // the plaintext key
+	"PATCH /api/v1/me/api-keys/:id":          {Auth: true, Cache: "no-store"},
+	"POST /api/v1/me/api-keys/:id/rotate":    {Auth: true, Cache: "no-store"}, // the plaintext key
+	"DELETE /api/v1/me/api-keys/:id":         {Auth: true},
+	"GET /api/v1/me/experiments":             {Auth: true, Cache: "no-store"},
+	"GET /api/v1/me/deletion-request":        {Auth: true, Cache: "no-store"},
+	"POST /api/v1/me/deletion-request":       {Auth: true, Cache: "no-store"},
+	"GET /api/v1/me/notifications":           {Auth: true, Cache: "no-store"},
+	"POST /api/v1/me/notifications/read-all": {Auth: true},
+	"POST /api/v1/me/notifications/:id/read": {Auth: true},
+
+	// Users, gated per action (admins get everything; support staff are read-only).
+	"POST /api/v1/users":           {Auth: true, Permission: policy.UsersCreate, Cache: "no-store"},
+	"POST /api/v1/users/import":    {Auth: true, Permission: policy.UsersCreate, Cache: "no-store", MaxBody: uploadMaxBody, Timeout: importTimeout},
+	"GET /api/v1/users":            {Auth: true, Permission: policy.UsersRead, Cache: "no-store"},
+	"GET /api/v1/users/export":     {Auth: true, Permission: policy.UsersRead, Timeout: NoTimeout}, // streamed; sets no-store itself
+	"GET /api/v1/users/:id":        {Auth: true, Permission: policy.UsersRead, Cache: "no-store"},
+	"PUT /api/v1/users/:id":        {Auth: true, Permission: policy.UsersUpdate, Cache: "no-store"},
+	"DELETE /api/v1/users/:id":     {Auth: true, Permission: policy.UsersDelete},
+	"POST /api/v1/users/:id/ban":   {Auth: true, Permission: policy.UsersUpdate, Cache: "no-store"},
+	"POST /api/v1/users/:id/unban": {Auth: true, Permission: policy.UsersUpdate, Cache: "no-store"},
+	"DELETE /api/v1/users":         {Auth: true, Permission: policy.UsersDelete},
+
+	// Support tooling: clear a user's cached and session state (admins and support staff).
+	"POST /api/v1/admin/users/:id/flush": {Auth: true, Permission: policy.UsersFlush, Cache: "no-store"},
+
+	// Account bundles (admin only): credentials leave or enter the environment.
+	"POST /api/v1/admin/accounts/export": {Auth: true, Permission: policy.AccountsTransfer, Cache: "no-store"},
+	"POST /api/v1/admin/accounts/import": {Auth: true, Permission: policy.AccountsTransfer, Cache: "no-store", MaxBody: uploadMaxBody, Timeout: importTimeout},
+
+	// Admin.
+	"POST /api/v1/admin/webhooks":                      {Auth: true, Permission: policy.WebhooksManage},
+	"GET /api/v1/admin/webhooks":                       {Auth: true, Permission: policy.WebhooksManage},
+	"DELETE /api/v1/admin/webhooks/:id":                {Auth: true, Permission: policy.WebhooksManage},
+	"POST /api/v1/admin/webhooks/:id/test":             {Auth: true, Permission: policy.WebhooksManage},
+	"GET /api/v1/admin/webhook-deliveries":             {Auth: true, Permission: policy.WebhooksManage},
+	"POST /api/v1/admin/webhook-deliveries/replay":     {Auth: true, Permission: policy.WebhooksManage},
+	"GET /api/v1/admin/audit":                          {Auth: true, Permission: policy.AuditRead, Cache: "no-store"},
+	"GET /api/v1/admin/diagnostics":                    {Auth: true, Permission: policy.DiagnosticsRead},
+	"GET /api/v1/admin/boot-info":                      {Auth: true, Permission: policy.DiagnosticsRead, Cache: "no-store"},
+	"POST /api/v1/admin/seed":                          {Auth: true, Permission: policy.UsersCreate, Cache: "no-store", Timeout: 2 * time.Minute}, // dev env only; large counts take a while
+	"GET /api/v1/admin/origins":                        {Auth: true, Permission: policy.OriginsManage, Timeout: 10 * time.Second},
+	"POST /api/v1/admin/origins":                       {Auth: true, Permission: policy.OriginsManage, Timeout: 10 * time.Second},
+	"DELETE /api/v1/admin/origins":                     {Auth: true, Permission: policy.OriginsManage, Timeout: 10 * time.Second},
+	"GET /api/v1/admin/settings":                       {Auth: true, Permission: policy.SettingsManage},
+	"PUT /api/v1/admin/settings":                       {Auth: true, Permission: policy.SettingsManage, Timeout: 10 * time.Second},
+	"GET /api/v1/admin/probes":                         {Auth: true, Permission: policy.DiagnosticsRead, Timeout: 10 * time.Second},
+	"GET /api/v1/admin/scheduler":                      {Auth: true, Permission: policy.DiagnosticsRead, Cache: "no-store"},
+	"GET /api/v1/admin/stats":                          {Auth: true, Permission: policy.DiagnosticsRead, Cache: "no-store"},
+	"GET /api/v1/admin/incidents":                      {Auth: true, Permission: policy.IncidentsManage},
+	"POST /api/v1/admin/incidents":                     {Auth: true, Permission: policy.IncidentsManage},
+	"PATCH /api/v1/admin/incidents/:id":                {Auth: true, Permission: policy.IncidentsManage},
+	"GET /api/v1/admin/email-templates":                {Auth: true, Permission: policy.EmailTemplatesRead},
+	"GET /api/v1/admin/email-templates/:kind/preview":  {Auth: true, Permission: policy.EmailTemplatesRead},
+	"GET /api/v1/admin/slo":                            {Auth: true, Permission: policy.SLORead, Timeout: 10 * time.Second},
+	"GET /api/v1/admin/jobs":                           {Auth: true, Permission: policy.JobsManage, Cache: "no-store"}, // dead jobs carry payloads (user IDs)
+	"POST /api/v1/admin/jobs/dead/requeue":             {Auth: true, Permission: policy.JobsManage},
+	"GET /api/v1/admin/deletion-requests":              {Auth: true, Permission: policy.DeletionsReview, Cache: "no-store"},
+	"POST /api/v1/admin/deletion-requests/:id/approve": {Auth: true, Permission: policy.DeletionsReview, Cache: "no-store"},
+	"POST /api/v1/admin/deletion-requests/:id/reject":  {Auth: true, Permission: policy.DeletionsReview, Cache: "no-store"},
+	"GET /api/v1/admin/logs/stream":                    {Auth: true, Permission: policy.LogsRead, Timeout: NoTimeout}, // SSE, open until the viewer closes it; sets no-store itself
+	"GET /api/v1/admin/logs/storage":                   {Auth: true, Permission: policy.LogsRead, Cache: "no-store"},
+	"PUT /api/v1/admin/logs/retention":                 {Auth: true, Permission: policy.LogsManage, Timeout: 10 * time.Second},
+	"POST /api/v1/admin/logs/archive":                  {Auth: true, Permission: policy.LogsManage, Timeout: 2 * time.Minute}, // reads the whole backlog in batches
+
+	// User change events over WebSocket (long-lived, so no timeout); carries user records.
+	"GET /ws": {Auth: true, Permission: policy.UsersRead},
+
+	// Go runtime profiles; profile?seconds= runs as long as asked, so no timeout.
+	"GET /debug/pprof/*profile": {Auth: true, Permission: policy.ProfilingRead},
+	"POST /debug/pprof/symbol":  {Auth: true, Permission: policy.ProfilingRead},
+}
+
+// uploadMaxBody is the body cap of file upload routes (the handlers' own limit is 5 MB of file;
+// the rest is multipart framing and form fields).
+const uploadMaxBody = 6 << 20
+
+// importTimeout bounds the import routes: a full file is thousands of rows, each hashed or
+// looked up on its own, so the default request timeout would cut it off part way.
+const importTimeout = 2 * time.Minute
+
+// router registers routes with the middleware their policy declares.
+type router struct {
+	d        Deps
+	auth     gin.HandlerFunc // API key, else JWT/session
+	openapi  gin.HandlerFunc // request validation for /api/v1 routes (nil = off)
+	policies map[string]RoutePolicy
+}
+
+// handle registers method+rel on g behind the route's declared policy.
+func (rt *router) handle(g *gin.RouterGroup, method, rel string, h ...gin.HandlerFunc) {
+	key := method + " " + path.Join(g.BasePath(), rel)
+	p, ok := rt.policies[key]
+	if !ok {
+		panic("routes: no policy declared for " + key)
+	}
+	g.Handle(method, rel, append(rt.chain(key, p), h...)...)
+}
+
+// chain turns a policy into middleware, in a fixed order.
+func (rt *router) chain(key string, p RoutePolicy) []gin.HandlerFunc {
+	var mw []gin.HandlerFunc
+	if p.RateLimit != "" {
+		mw = append(mw, middlewares.RateLimitFunc(rt.d.RateLimiter, p.RateLimit, rateRule(rt.d, p.RateLimit)))
+	}
+	if p.Permission != "" && !p.Auth {
+		panic("routes: " + key + " has a permission but no auth")
+	}
+	if p.Auth {
+		mw = append(mw, rt.auth)
+		if rt.d.Experiments != nil {
+			mw = append(mw, middlewares.Experiments(rt.d.Experiments))
+		}
+	}
+	if p.Permission != "" {
+		mw = append(mw, middlewares.RequirePermission(p.Permission))
+	}
+	if _, route, _ := strings.Cut(key, " "); rt.openapi != nil && strings.HasPrefix(route, "/api/v1/") {
+		mw = append(mw, rt.openapi) // After auth: anonymous callers learn nothing about the schema.
+	}
+	if d := rt.timeout(key, p); d > 0 {
+		mw = append(mw, middlewares.Timeout(d))
+	}
+	if p.Cache != "" {
+		mw = append(mw, middlewares.CacheControl(p.Cache))
+	}
+	return mw
+}
+
+// timeout is the deadline of the route key: its policy's Timeout, else its group's from
+// Deps.RequestTimeouts. The group of an /api/v1 route is the path segment after the version
+// ("auth", "users", "admin", ...), falling back to "default"; other routes have none.
+func (rt *router) timeout(key string, p RoutePolicy) time.Duration {
+	if p.Timeout != 0 {
+		return p.Timeout
+	}
+	_, route, _ := strings.Cut(key, " ")
+	rest, ok := strings.CutPrefix(route, "/api/v1/")
+	if !ok {
+		return 0
+	}
+	group, _, _ := strings.Cut(rest, "/")
+	if d, ok := rt.d.RequestTimeouts[group]; ok {
+		return d
+	}
+	return rt.d.RequestTimeouts["default"]
+}
+
+// rateLimitReset refills an IP's bucket in every rate limit group a route uses.
+func (rt *router) rateLimitReset(r middlewares.RateLimitResetter) func(ctx context.Context, ip string) error {
+	seen := map[string]bool{}
+	var groups []string
+	for _, p := range rt.policies {
+		if p.RateLimit != "" && !seen[p.RateLimit] {
+			seen[p.RateLimit] = true
+			groups = append(groups, p.RateLimit)
+		}
+	}
+	return func(ctx context.Context, ip string) error {
+		keys := make([]string, len(groups))
+		for i, g := range groups {
+			keys[i] = middlewares.RateLimitKey(g, ip)
+		}
+		return r.Reset(ctx, keys...)
+	}
+}
+
+// bodyLimit is the request body cap of c's route: its policy's MaxBody, else the default
+// (also for unknown routes, which 404 anyway).
+func (rt *router) bodyLimit(c *gin.Context) int64 {
+	if p, ok := rt.policies[c.Request.Method+" "+c.FullPath()]; ok && p.MaxBody != 0 {
+		return p.MaxBody
+	}
+	return rt.d.MaxBodyBytes
+}
+
+// checkDeclared panics if r has a route that was registered without a policy (e.g. with
+// r.GET instead of handle); HEAD routes mirror their GET (StaticFile).
+func (rt *router) checkDeclared(r *gin.Engine) {
+	var missing []string
+	for _, route := range r.Routes() {
+		if key := route.Method + " " + route.Path; route.Method != "HEAD" {
+			if _, ok := rt.policies[key]; !ok {
+				missing = append(missing, key)
+			}
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		panic(fmt.Sprintf("routes: no policy declared for %v", missing))
+	}
+}
@@ -0,0 +1,117 @@
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"HelmyTask/core"
+	"HelmyTask/mocks"
+	"HelmyTask/models"
+	"HelmyTask/utils/openapi"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicies_PermissionsNeedAuth(t *testing.T) {
+	for key, p := range Policies {
+		if p.Permission != "" {
+			assert.True(t, p.Auth, key)
+		}
+	}
+}
+
+func TestSetup_UndeclaredRoutePanics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	p := Policies["GET /healthz"]
+	delete(Policies, "GET /healthz")
+	defer func() { Policies["GET /healthz"] = p }()
+
+	assert.PanicsWithValue(t, "routes: no policy declared for GET /healthz", func() {
+		Setup(gin.New(), Deps{Auth: new(mocks.AuthServiceMock), Users: new(mocks.UserAdminServiceMock), JWTSecret: "secret"})
+	})
+}
+
+func TestSetup_ChainFromPolicy(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	users := new(mocks.UserAdminServiceMock)
+	users.On("GetUser", core.UserID(1)).Return(&models.User{ID: 1}, nil)
+	r := gin.New()
+	Setup(r, Deps{Auth: new(mocks.AuthServiceMock), Users: users, JWTSecret: "secret", JWTExpires: time.Hour})
+	claims := jwt.MapClaims{"sub": 1, "rol": "user", "exp": time.Now().Add(time.Minute).Unix(), "iat": time.Now().Unix()}
+	token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("secret"))
+	get := func(path, bearer string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if bearer != "" {
+			req.Header.Set("Authorization", "Bearer "+bearer)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("/api/v1/me", token) // Auth + Cache
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+	assert.Equal(t, http.StatusUnauthorized, get("/api/v1/me", "").Code)
+	assert.Equal(t, http.StatusForbidden, get("/api/v1/users", token).Code) // Permission
+	assert.Equal(t, http.StatusOK, get("/healthz", "").Code)                // public
+}
+
+func TestSetup_BodyLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	auth := new(mocks.AuthServiceMock) // never called: the body is refused first
+	r := gin.New()
+	Setup(r, Deps{Auth: auth, Users: new(mocks.UserAdminServiceMock), JWTSecret: "secret", MaxBodyBytes: 64})
+	post := func(path string, size int) int {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"name":"`+strings.Repeat("x", size)+`"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, post("/api/v1/auth/register", 100))
+	assert.Equal(t, http.StatusUnauthorized, post("/api/v1/users/import", 100), "upload routes allow more")
+	auth.AssertExpectations(t)
+}
+
+func TestSetup_OpenAPIAfterAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	spec, err := openapi.Load("../docs/swagger.yaml")
+	require.NoError(t, err)
+	r := gin.New()
+	Setup(r, Deps{Auth: new(mocks.AuthServiceMock), Users: new(mocks.UserAdminServiceMock), JWTSecret: "secret", OpenAPI: spec})
+	claims := jwt.MapClaims{"sub": 1, "rol": "user", "exp": time.Now().Add(time.Minute).Unix(), "iat": time.Now().Unix()}
+	token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("secret"))
+	post := func(path, bearer, body string) int {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if bearer != "" {
+			req.Header.Set("Authorization", "Bearer "+bearer)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, post("/api/v1/me/password", "", `{"old_password":1}`), "no schema details before auth")
+	assert.Equal(t, http.StatusUnprocessableEntity, post("/api/v1/me/password", token, `{"old_password":1}`))
+	assert.Equal(t, http.StatusUnprocessableEntity, post("/api/v1/auth/register", "", `{"name":1}`), "public routes are still checked")
+}
+
+func TestRouter_TimeoutPerGroup(t *testing.T) {
+	rt := &router{d: Deps{RequestTimeouts: map[string]time.Duration{"default": 30 * time.Second, "users": 5 * time.Second, "me": 0}}}
+
+	assert.Equal(t, 5*time.Second, rt.timeout("GET /api/v1/users/:id", RoutePolicy{}))
+	assert.Equal(t, 30*time.Second, rt.timeout("GET /api/v1/admin/audit", RoutePolicy{}), "unlisted group → default")
+	assert.Equal(t, time.Duration(0), rt.timeout("GET /api/v1/me", RoutePolicy{}), "0 switches the group off")
+	assert.Equal(t, 10*time.Second, rt.timeout("POST /api/v1/users", RoutePolicy{Timeout: 10 * time.Second}), "policy wins")
+	assert.Equal(t, NoTimeout, rt.timeout("GET /api/v1/users/export", Policies["GET /api/v1/users/export"]))
+	assert.Equal(t, time.Duration(0), rt.timeout("GET /debug/pprof/*profile", RoutePolicy{}), "outside /api/v1")
+}
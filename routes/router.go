@@ -3,42 +3,419 @@ package routes // Router setup layer.
 import ( // Imports used in the router.
 	"time" // For JWT expiration type.
 
+	"HelmyTask/audit" // Audit trail recorder.
+	"HelmyTask/changelog" // API changes + deprecation headers.
+	"HelmyTask/core" // Password policy type.
+	"HelmyTask/events" // User change events (WebSocket feed).
+	"HelmyTask/experiments" // Experiment variant assignment.
 	"HelmyTask/handlers" // User handler constructor.
+	"HelmyTask/jobs" // Background job queue (admin view).
 	"HelmyTask/middlewares" // Logging & recovery & auth middlewares.
 	"HelmyTask/services" // User service interface.
+	"HelmyTask/metrics"  // Prometheus /metrics.
+	"HelmyTask/settings" // Runtime settings overrides.
+	"HelmyTask/slo" // SLO tracker.
+	"HelmyTask/utils/jsonstyle" // Per-version JSON shape.
+	"HelmyTask/utils/jwtkeys" // JWT key set.
+	"HelmyTask/utils/openapi" // Runtime spec validation.
+	"HelmyTask/utils/origins" // Trusted browser origins.
+	"HelmyTask/utils/ratelimit" // Rate limit rules.
+	"HelmyTask/utils/session" // Redis session store (session auth mode).
 
-	"github.com/gin-gonic/gin" // Gin router.
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace" // Gin router.
 )
 
+// Deps groups everything the router needs; main.go fills it once at boot.
+// Optional services may be nil (their routes are then not registered).
+type Deps struct {
+	Auth               services.AuthService          // Register/login/2FA/password (required).
+	Users              services.UserAdminService     // User CRUD, list, bulk and /me (required).
+	Setup              services.SetupService         // First-run POST /setup while there are no users (optional).
+	SetupToken         string                        // When set, POST /setup needs it in X-Setup-Token.
+	APIKeys            services.APIKeyService        // API key issuing + X-API-Key auth (optional).
+	SignatureNonces    middlewares.SignatureNonces   // X-Signature values already used by signed API keys (optional; nil = timestamp window only).
+	Emails             services.EmailDeliveryService // Delivery tracking; bounce webhook (optional).
+	Webhooks           services.WebhookService       // Admin-registered webhook targets (optional).
+	Incidents          services.IncidentService      // Public GET /status + /admin/incidents (optional).
+	Deletions          services.DeletionService      // Approved account deletion: /me/deletion-request + /admin/deletion-requests (optional).
+	Notifications      services.NotificationService  // In-app notifications at /me/notifications (optional).
+	DeletionApproval   bool                          // DELETE /me refused in favour of a deletion request (needs Deletions).
+	Audit              *audit.Recorder               // Audit trail of user changes (optional).
+	EmailWebhookSecret string                        // Shared secret for the bounce webhook; empty disables it.
+	JWTSecret          string                        // HS256 secret.
+	JWTKeys            *jwtkeys.KeySet               // Verification keys (RS256 rotation); nil = HS256 with JWTSecret.
+	JWTExpires         time.Duration                 // Token lifetime.
+	MaxBodyBytes       int64                         // Request body cap unless a route's policy says otherwise (0 = none).
+	StrictSlash        bool                          // No redirect between /path/ and /path: the other spelling is a 404.
+	RequestTimeouts    map[string]time.Duration      // Request deadline per /api/v1 route group ("auth", "users", ...; "default" for the rest) unless a route's policy says otherwise.
+
+	RateLimiter middlewares.RateLimiter   // Redis token buckets (optional; nil disables limiting).
+	RateLimits  map[string]ratelimit.Rule // Rules per route group ("auth", ...).
+
+	AuthMode            string         // "jwt" (default) or "session".
+	Sessions            *session.Store // Required when AuthMode is "session".
+	SessionCookieSecure bool           // Secure flag on the session cookie.
+
+	Revocations middlewares.TokenRevocations // JWTs voided by password changes (optional).
+
+	PasswordPolicy *core.PasswordPolicy // Backs the "password" binding tag; nil keeps core defaults.
+
+	Diagnostics *handlers.DiagnosticsHandler // GET /admin/diagnostics (optional).
+	SLO         *slo.Tracker                 // Request outcomes + GET /admin/slo (optional).
+	Probes      *handlers.ProbeHandler       // GET /admin/probes (optional).
+	BootInfo    *handlers.BootInfoHandler    // GET /admin/boot-info (optional).
+	Seed        *handlers.SeedHandler        // POST /admin/seed fake users (optional; dev env only).
+	SchemaCompatible func() bool // False refuses /api/v1 writes with 503 (nil = always compatible).
+	Scheduler   *handlers.SchedulerHandler   // GET /admin/scheduler + /admin/stats (optional).
+	LogStream   *handlers.LogStreamHandler   // GET /admin/logs/stream live log viewer (optional).
+	LogAdmin    *handlers.LogAdminHandler    // Redis log usage, runtime limits and archival at /admin/logs/... (optional).
+	Jobs        *jobs.Queue                  // Background job queue status + dead letters at /admin/jobs (optional).
+	EmailTemplates *handlers.EmailTemplateHandler // GET /admin/email-templates (optional).
+	Origins     *origins.Registry            // Trusted browser origins for CORS/CSRF + /admin/origins (nil = no CORS, same-origin only).
+	OpenAPI          *openapi.Spec // Validate /api/v1 requests against the spec (nil = off).
+	OpenAPIResponses bool          // Also log responses that drift from the spec (debug/staging).
+	ResponseStyles map[string]jsonstyle.Style // JSON envelope/naming per API version ("v1"); missing = native.
+	Changelog   *changelog.Registry          // GET /api/changelog + Deprecation headers (nil = off).
+	ErrorDocsURL string                      // Base of the docs links in GET /api/errors ("" = no links).
+	Experiments *experiments.Registry        // Variant assignment: X-Experiments header + GET /me/experiments (nil = off).
+	Events      *events.Hub                  // User change events streamed at GET /ws (nil = off).
+	Metrics     *metrics.Metrics             // HTTP instrumentation + GET /metrics (nil = off).
+	Tracer      trace.TracerProvider         // OpenTelemetry server span per request (nil = off).
+	Settings    *settings.Store              // Runtime overrides (rate limits, maintenance) + /admin/settings (nil = config only).
+	Health *handlers.HealthHandler // /healthz + /readyz; main keeps it to flip readiness on shutdown (nil = no checks).
+	Profiling bool                 // /debug/pprof for admins (same auth as /api/v1).
+}
+
 // Setup attaches middlewares and registers all endpoints.
-func Setup(r *gin.Engine, svc services.UserService, jwtSecret string, jwtExp time.Duration) {
+func Setup(r *gin.Engine, d Deps) {
+	// Bind-time password rules must match the service's, so install the configured policy.
+	if d.PasswordPolicy != nil {
+		handlers.SetPasswordPolicy(*d.PasswordPolicy)
+	}
+
+	// Every route gets its middleware from its entry in Policies (see policies.go); Auth means
+	// an API key, else a JWT or session depending on auth mode.
+	keys := d.JWTKeys
+	if keys == nil {
+		keys = jwtkeys.NewHMAC(d.JWTSecret)
+	}
+	authMW := middlewares.AuthWithKeys(keys, d.Revocations) // Bearer JWT by default.
+	if d.AuthMode == "session" {
+		authMW = middlewares.SessionAuth(d.Sessions)
+	}
+	var keyAuth middlewares.APIKeyAuthenticator // nil interface when API keys are disabled
+	if d.APIKeys != nil {
+		keyAuth = d.APIKeys
+	}
+	rt := &router{d: d, auth: middlewares.APIKeyAuth(keyAuth, d.SignatureNonces, authMW), policies: Policies}
+	defer rt.checkDeclared(r) // no route without a declared policy
+	if d.OpenAPI != nil { // Per route, after its rate limit and auth (see chain).
+		rt.openapi = middlewares.OpenAPI(d.OpenAPI, d.OpenAPIResponses)
+	}
+
+	// Unknown paths and methods get JSON errors like the rest of the API; a 405 carries Allow.
+	r.RedirectTrailingSlash = !d.StrictSlash
+	r.HandleMethodNotAllowed = true
+	r.NoRoute(handlers.NotFound)
+	r.NoMethod(handlers.MethodNotAllowed)
+
 	// Attach standard middlewares globally.
-	r.Use(middlewares.RequestLogger(), middlewares.Recovery()) // Access log + panic recovery.
+	if d.Tracer != nil {
+		r.Use(middlewares.Tracing(d.Tracer)) // Outermost, so the request span covers every other middleware.
+	}
+	// SLO sits outside Recovery so a recovered panic counts as the 500 it becomes (nil tracker = pass-through).
+	r.Use(middlewares.RequestLogger(), middlewares.SLO(sloRecorder(d.SLO)), middlewares.Metrics(httpMetrics(d.Metrics)), middlewares.Recovery()) // Access log + SLO + Prometheus + panic recovery.
+	r.Use(middlewares.CORS(originChecker(d.Origins))) // Also answers preflights (OPTIONS never reaches a route).
+	r.Use(middlewares.BodyLimit(rt.bodyLimit)) // 413 before anything (OpenAPI checks, camelCase rewriting, binding) reads a body.
+	if d.Changelog != nil {
+		r.Use(middlewares.Deprecation(d.Changelog)) // Before rate limits/auth, so refusals carry the notice too.
+		rt.handle(&r.RouterGroup, "GET", "/api/changelog", handlers.Changelog(d.Changelog))
+	}
+
+	rt.handle(&r.RouterGroup, "GET", "/api/errors", handlers.ErrorCatalog(d.ErrorDocsURL)) // Error codes for client teams.
+	rt.handle(&r.RouterGroup, "GET", "/api/errors/:code", handlers.ErrorCode(d.ErrorDocsURL))
 
 	// Swagger (if you have docs/swagger.yaml); serves static file at /swagger.yaml.
 	r.StaticFile("/swagger.yaml", "./docs/swagger.yaml")
 
-	// Group API under /api/v1 for versioning.
+	// Probes for orchestrators/load balancers (no auth, no rate limit).
+	hh := d.Health
+	if hh == nil {
+		hh = handlers.NewHealthHandler(nil)
+	}
+	rt.handle(&r.RouterGroup, "GET", "/healthz", hh.Live) // process is up
+	rt.handle(&r.RouterGroup, "GET", "/readyz", hh.Ready) // dependencies reachable; `server healthcheck` probes this
+	rt.handle(&r.RouterGroup, "GET", "/ping", hh.Ping) // version + uptime for external monitors; no dependency checks
+	if d.Metrics != nil {
+		rt.handle(&r.RouterGroup, "GET", "/metrics", gin.WrapH(d.Metrics.Handler())) // Prometheus scrape target; keep it off the public ingress
+	}
+
+	// Public key discovery (RS256 only; an HS256 secret is never published).
+	if d.JWTKeys != nil && d.JWTKeys.Algorithm() == jwtkeys.RS256 {
+		rt.handle(&r.RouterGroup, "GET", "/.well-known/jwks.json", handlers.JWKS(d.JWTKeys))
+	}
+
+	// Live user change events for admin UIs (outside /api/v1: a stream, not a versioned resource).
+	if d.Events != nil {
+		ws := r.Group("/", middlewares.WebSocketBearer()) // browsers send the JWT as a subprotocol
+		rt.handle(ws, "GET", "/ws", handlers.NewEventsHandler(d.Events, originChecker(d.Origins), handlers.WithStreamAccessCheck(d.Revocations, d.Users)).Stream)
+	}
+
+	// Group API under /api/v1 for versioning; cookie-authenticated writes must come from a trusted origin.
 	api := r.Group("/api/v1")
+	// First, so every /api/v1 answer has the version's shape (OpenAPI checks the native one); mail provider callbacks keep theirs.
+	api.Use(middlewares.ResponseStyle(d.ResponseStyles["v1"], "/api/v1/webhooks/"))
+	api.Use(middlewares.CSRF(originChecker(d.Origins)))
+	var maintenance func() (bool, string) // runtime maintenance mode (nil without a settings store)
+	if d.Settings != nil {
+		maintenance = func() (bool, string) {
+			cur := d.Settings.Current()
+			return cur.MaintenanceMode, cur.MaintenanceMessage
+		}
+		// Admins can still log in and switch maintenance off.
+		api.Use(middlewares.Maintenance(maintenance, "/api/v1/admin/", "/api/v1/auth/login", "/api/v1/auth/logout"))
+	}
+	api.Use(middlewares.SchemaGuard(d.SchemaCompatible)) // Writes wait for a schema this build matches.
+
+	// Create the user handlers (auth gets the JWT parameters, management the audit trail).
+	ah := handlers.NewAuthHandler(d.Auth, d.JWTSecret, d.JWTExpires)
+	uopts := []handlers.UserHandlerOption{handlers.WithAudit(d.Audit)}
+	if resetter, ok := d.RateLimiter.(middlewares.RateLimitResetter); ok {
+		uopts = append(uopts, handlers.WithRateLimitReset(rt.rateLimitReset(resetter)))
+	}
+	if d.DeletionApproval && d.Deletions != nil {
+		uopts = append(uopts, handlers.WithDeletionApproval())
+	}
+	uh := handlers.NewUserHandler(d.Users, uopts...)
 
-	// Create the user handler (injecting service + JWT parameters).
-	uh := handlers.NewUserHandler(svc, jwtSecret, jwtExp)
+	// Public auth endpoints (no JWT required), rate limited per client IP.
+	auth := api.Group("/auth")
+	rt.handle(auth, "POST", "/register", ah.Register) // Register new user.
+	rt.handle(auth, "POST", "/password-strength", ah.PasswordStrength) // Strength meter for signup/change forms.
+	rt.handle(auth, "GET", "/lockout-status", ah.LockoutStatus) // "Try again in N minutes" after repeated failed logins.
 
-	// Public auth endpoints (no JWT required).
-	api.POST("/auth/register", uh.Register) // Register new user.
-	api.POST("/auth/login", uh.Login) // Login and get JWT.
+	// First-run bootstrap: creates the first admin, then locks itself.
+	if d.Setup != nil {
+		su := handlers.NewSetupHandler(d.Setup, d.Settings, d.SetupToken, d.Audit)
+		rt.handle(api, "GET", "/setup", su.Status) // {"setup_required": bool}
+		rt.handle(api, "POST", "/setup", su.Run) // Only while the users table is empty.
+	}
 
-	// Protected group (requires valid Authorization: Bearer <token>).
+	// Mail provider callbacks (bounces/complaints), authenticated by a shared secret.
+	if d.Emails != nil && d.EmailWebhookSecret != "" {
+		rt.handle(api, "POST", "/webhooks/email", handlers.NewEmailWebhookHandler(d.Emails, d.EmailWebhookSecret).Receive)
+	}
+
+	// Login flavour (the guard matching it was picked above).
+	if d.AuthMode == "session" {
+		sh := handlers.NewSessionHandler(d.Auth, d.Sessions, d.SessionCookieSecure)
+		rt.handle(auth, "POST", "/login", sh.Login) // Sets the session cookie.
+		rt.handle(auth, "POST", "/logout", sh.Logout) // Ends the session.
+	} else {
+		rt.handle(auth, "POST", "/login", ah.Login) // Login and get JWT.
+	}
+
+	// Everything else under /api/v1; routes declared Auth need a valid JWT or session depending
+	// on auth mode, or X-API-Key for machine clients.
 	protected := api.Group("/")
-	protected.Use(middlewares.Auth(jwtSecret)) // JWT auth middleware.
 
-	// "Me" endpoint (current user).
-	protected.GET("/me", uh.GetUser) // You could point to a dedicated 'Me' handler; here we reuse GetUser with context in your baseline.
+	// "Me" endpoints (current user, identified by the authenticated uid — no :id param).
+	rt.handle(protected, "GET", "/me", uh.Me) // Read own profile.
+	rt.handle(protected, "PUT", "/me", uh.UpdateMe) // Self-service partial update (no role changes).
+	rt.handle(protected, "DELETE", "/me", uh.DeleteMe) // Close own account.
+	rt.handle(protected, "POST", "/me/password", ah.ChangePassword) // Requires the current password; logs out everywhere.
+
+	// Experiment variants of the current user (also in the X-Experiments header).
+	if d.Experiments != nil {
+		rt.handle(protected, "GET", "/me/experiments", handlers.NewExperimentHandler(d.Experiments).Mine)
+	}
+
+	// In-app notifications of the current user.
+	if d.Notifications != nil {
+		nh := handlers.NewNotificationHandler(d.Notifications)
+		rt.handle(protected, "GET", "/me/notifications", nh.List) // ?unread=true; newest first + unread count.
+		rt.handle(protected, "POST", "/me/notifications/read-all", nh.MarkAllRead)
+		rt.handle(protected, "POST", "/me/notifications/:id/read", nh.MarkRead)
+	}
+
+	// Account deletion that needs an admin's approval (regulated deployments); approval erases the account.
+	if d.Deletions != nil {
+		dh := handlers.NewDeletionHandler(d.Deletions)
+		rt.handle(protected, "GET", "/me/deletion-request", dh.Mine) // Latest request and its status.
+		rt.handle(protected, "POST", "/me/deletion-request", dh.Request) // {"reason": "..."}; 409 while one is open.
+		dg := protected.Group("/admin/deletion-requests")
+		rt.handle(dg, "GET", "", dh.List) // ?status=pending, oldest first.
+		rt.handle(dg, "POST", "/:id/approve", dh.Approve) // Optional {"note": "..."}; not one's own.
+		rt.handle(dg, "POST", "/:id/reject", dh.Reject) // {"note": "..."} required.
+	}
+
+	// Two-factor enrollment for the current user.
+	rt.handle(protected, "POST", "/me/2fa/enable", ah.EnableTwoFactor) // Returns secret + otpauth URL.
+	rt.handle(protected, "POST", "/me/2fa/confirm", ah.ConfirmTwoFactor) // Activates 2FA with a first code.
+
+	// API keys owned by the current user.
+	if d.APIKeys != nil {
+		kh := handlers.NewAPIKeyHandler(d.APIKeys)
+		rt.handle(protected, "GET", "/me/api-keys", kh.List) // Metadata only.
+		rt.handle(protected, "POST", "/me/api-keys", kh.Create) // Plaintext key returned once.
+		rt.handle(protected, "PATCH", "/me/api-keys/:id", kh.Rename) // Relabel.
+		rt.handle(protected, "POST", "/me/api-keys/:id/rotate", kh.Rotate) // New plaintext returned once; the old key is revoked.
+		rt.handle(protected, "DELETE", "/me/api-keys/:id", kh.Revoke) // Revoke.
+	}
+
+	// Webhook targets (admin only).
+	if d.Webhooks != nil {
+		wh := handlers.NewWebhookHandler(d.Webhooks)
+		admin := protected.Group("/admin")
+		rt.handle(admin, "POST", "/webhooks", wh.Create) // URL is SSRF-checked.
+		rt.handle(admin, "GET", "/webhooks", wh.List)
+		rt.handle(admin, "DELETE", "/webhooks/:id", wh.Delete)
+		rt.handle(admin, "POST", "/webhooks/:id/test", wh.Test) // Signed sample delivery, echoed back.
+		rt.handle(admin, "GET", "/webhook-deliveries", wh.ListDeliveries) // Delivery log (filter status=failed).
+		rt.handle(admin, "POST", "/webhook-deliveries/replay", wh.Replay) // Resend by IDs or time range.
+	}
+
+	// Audit trail (admins and support staff).
+	if d.Audit != nil {
+		rt.handle(protected, "GET", "/admin/audit", handlers.NewAuditHandler(d.Audit).List) // Filter by actor_id, action, from/to.
+	}
+
+	// Per-replica runtime state (admin only).
+	if d.Diagnostics != nil {
+		rt.handle(protected, "GET", "/admin/diagnostics", d.Diagnostics.Get) // Leadership, uptime.
+	}
+	if d.BootInfo != nil {
+		rt.handle(protected, "GET", "/admin/boot-info", d.BootInfo.Get) // What each replica started with.
+	}
+	if d.Seed != nil {
+		rt.handle(protected, "POST", "/admin/seed", d.Seed.Seed) // Fake users for development databases.
+	}
+
+	// Trusted CORS/CSRF origins (admin only).
+	if d.Origins != nil {
+		oh := handlers.NewOriginHandler(d.Origins)
+		og := protected.Group("/admin/origins")
+		rt.handle(og, "GET", "", oh.List)
+		rt.handle(og, "POST", "", oh.Add)
+		rt.handle(og, "DELETE", "", oh.Remove)
+	}
+
+	// Runtime settings overrides (admin only).
+	if d.Settings != nil {
+		stg := handlers.NewSettingsHandler(d.Settings, d.Audit)
+		rt.handle(protected, "GET", "/admin/settings", stg.Get)
+		rt.handle(protected, "PUT", "/admin/settings", stg.Put) // Replaces the override set; audited.
+	}
+
+	// Synthetic probe results (admin only).
+	if d.Probes != nil {
+		rt.handle(protected, "GET", "/admin/probes", d.Probes.List)
+	}
+
+	// Scheduled maintenance jobs and the stats snapshot they refresh (admin only).
+	if d.Scheduler != nil {
+		rt.handle(protected, "GET", "/admin/scheduler", d.Scheduler.List) // Last run, outcome, next run per job.
+		rt.handle(protected, "GET", "/admin/stats", d.Scheduler.Stats)
+	}
+
+	// Public status page (no auth, cached 15s) and the incidents it announces (admin only).
+	if d.Incidents != nil {
+		sth := handlers.NewStatusHandler(d.Incidents, hh, d.SLO, maintenance)
+		rt.handle(&r.RouterGroup, "GET", "/status", sth.Status)
+		ig := protected.Group("/admin/incidents")
+		rt.handle(ig, "GET", "", sth.ListIncidents)
+		rt.handle(ig, "POST", "", sth.CreateIncident)
+		rt.handle(ig, "PATCH", "/:id", sth.UpdateIncident) // Post an update; status "resolved" closes it.
+	}
+
+	// Localized email templates and per-locale previews (admin only).
+	if d.EmailTemplates != nil {
+		rt.handle(protected, "GET", "/admin/email-templates", d.EmailTemplates.List)
+		rt.handle(protected, "GET", "/admin/email-templates/:kind/preview", d.EmailTemplates.Preview) // ?locale=ar-EG[&format=html]
+	}
+
+	// Live application log as Server-Sent Events (admin only).
+	if d.LogStream != nil {
+		rt.handle(protected, "GET", "/admin/logs/stream", d.LogStream.Stream) // ?level=warn or ?levels=error,debug; ?backlog=50
+	}
+
+	// Redis log footprint and retention controls, for Redis memory pressure (admin only).
+	if d.LogAdmin != nil {
+		rt.handle(protected, "GET", "/admin/logs/storage", d.LogAdmin.Storage)     // Entries, memory, oldest entry, limits.
+		rt.handle(protected, "PUT", "/admin/logs/retention", d.LogAdmin.Retention) // max_len/retention for every replica; audited.
+		rt.handle(protected, "POST", "/admin/logs/archive", d.LogAdmin.Archive)    // Move old entries to a file; audited.
+	}
+
+	// Background job queue and its dead letters (admin only).
+	if d.Jobs != nil {
+		jh := handlers.NewJobsHandler(d.Jobs)
+		rt.handle(protected, "GET", "/admin/jobs", jh.Get)
+		rt.handle(protected, "POST", "/admin/jobs/dead/requeue", jh.RequeueDead) // {"ids":[...]} or {} for all
+	}
+
+	// Rolling SLO compliance (admin only).
+	if d.SLO != nil {
+		rt.handle(protected, "GET", "/admin/slo", handlers.NewSLOHandler(d.SLO).Get)
+	}
+
+	// Go runtime profiles (admin only); outside /api/v1, so no response style, CSRF or maintenance.
+	if d.Profiling {
+		dbg := r.Group("/debug/pprof")
+		rt.handle(dbg, "GET", "/*profile", handlers.Pprof()) // index, profile?seconds=, heap, goroutine, trace, ...
+		rt.handle(dbg, "POST", "/symbol", handlers.Pprof())
+	}
+
+	// RESTful CRUD for users, gated per action by the policy engine
+	// (admins get everything; support staff are read-only).
+	rt.handle(protected, "POST", "/users", uh.CreateUser) // Create
+	rt.handle(protected, "POST", "/users/import", uh.ImportUsers) // CSV/JSON upload, per-row report
+	rt.handle(protected, "GET", "/users", uh.ListUsers) // List (paginated)
+	rt.handle(protected, "GET", "/users/export", uh.ExportUsers) // CSV download (streamed)
+	rt.handle(protected, "GET", "/users/:id", uh.GetUser) // Read (one)
+	rt.handle(protected, "PUT", "/users/:id", uh.UpdateUser) // Update (partial)
+	rt.handle(protected, "DELETE", "/users/:id", uh.DeleteUser) // Delete
+	rt.handle(protected, "POST", "/users/:id/ban", uh.BanUser) // Block login, end sessions/JWTs
+	rt.handle(protected, "POST", "/users/:id/unban", uh.UnbanUser) // Re-activate
+	rt.handle(protected, "DELETE", "/users", uh.DeleteUsers) // Bulk delete {"ids":[...]}
+
+	// Support tool: reset a user's cache, sessions, JWTs, pending 2FA and (given IPs) rate limits.
+	rt.handle(protected, "POST", "/admin/users/:id/flush", uh.FlushUser) // Optional {"ips":[...],"reason":"..."}
+
+	// Encrypted account bundles for staging refreshes / tenant migrations (admin only).
+	rt.handle(protected, "POST", "/admin/accounts/export", uh.ExportAccounts) // {"ids":[...],"passphrase":"..."} -> bundle file
+	rt.handle(protected, "POST", "/admin/accounts/import", uh.ImportAccounts) // Multipart bundle + passphrase, per-account report
+}
+
+// sloRecorder avoids a typed-nil interface when no tracker is configured.
+func sloRecorder(t *slo.Tracker) middlewares.SLORecorder {
+	if t == nil {
+		return nil
+	}
+	return t
+}
+
+// httpMetrics avoids a typed-nil interface when metrics are disabled.
+func httpMetrics(m *metrics.Metrics) middlewares.HTTPMetrics {
+	if m == nil {
+		return nil
+	}
+	return m
+}
+
+// originChecker avoids a typed-nil interface when no registry is configured.
+func originChecker(r *origins.Registry) middlewares.OriginChecker {
+	if r == nil {
+		return nil
+	}
+	return r
+}
 
-	// RESTful CRUD for users (admin-style).
-	protected.POST("/users", uh.CreateUser) // Create
-	protected.GET("/users", uh.ListUsers) // List (paginated)
-	protected.GET("/users/:id", uh.GetUser) // Read (one)
-	protected.PUT("/users/:id", uh.UpdateUser) // Update (partial)
-	protected.DELETE("/users/:id", uh.DeleteUser) // Delete
+// rateRule reads a group's limit from the runtime settings when present, else from config.
+func rateRule(d Deps, group string) func() ratelimit.Rule {
+	if d.Settings != nil {
+		return func() ratelimit.Rule { return d.Settings.Current().Rule(group) }
+	}
+	rule := d.RateLimits[group]
+	return func() ratelimit.Rule { return rule }
 }
@@ -1,23 +1,28 @@
 package routes
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
 	"HelmyTask/mocks"
+	"HelmyTask/utils/jwtkeys"
+	"HelmyTask/utils/ratelimit"
+	"HelmyTask/utils/session"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestSetup_Smoke(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	r := gin.New()
-	svc := new(mocks.UserServiceMock)
-
-	Setup(r, svc, "secret", time.Hour)
+	Setup(r, Deps{Auth: new(mocks.AuthServiceMock), Users: new(mocks.UserAdminServiceMock), JWTSecret: "secret", JWTExpires: time.Hour})
 
 	w := httptest.NewRecorder()
 	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", nil)
@@ -25,3 +30,125 @@ func TestSetup_Smoke(t *testing.T) {
 
 	assert.Equal(t, http.StatusBadRequest, w.Code) // route exists; body missing
 }
+
+func TestSetup_SessionMode_ProtectedNeedsCookie(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	rdb, _ := mocks.NewRedisMock()
+
+	Setup(r, Deps{Auth: new(mocks.AuthServiceMock), Users: new(mocks.UserAdminServiceMock), AuthMode: "session", Sessions: session.New(rdb, time.Hour)})
+
+	// a bearer token means nothing in session mode
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/me", nil)
+	req.Header.Set("Authorization", "Bearer whatever")
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "missing session")
+
+	// logout route only exists in session mode
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/auth/logout", nil))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
+
+// denyAll is a RateLimiter whose buckets are always empty.
+type denyAll struct{}
+
+func (denyAll) Allow(context.Context, string, ratelimit.Rule) (bool, time.Duration, error) {
+	return false, time.Second, nil
+}
+
+func TestSetup_LogoutNotRateLimited(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	rdb, _ := mocks.NewRedisMock()
+	Setup(r, Deps{Auth: new(mocks.AuthServiceMock), Users: new(mocks.UserAdminServiceMock), AuthMode: "session", Sessions: session.New(rdb, time.Hour), RateLimiter: denyAll{}})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", nil))
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/auth/logout", nil))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
+
+func TestSetup_JWKSOnlyInRS256Mode(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	keys, _ := jwtkeys.NewRSA("k1", key, nil)
+
+	r := gin.New()
+	Setup(r, Deps{Auth: new(mocks.AuthServiceMock), Users: new(mocks.UserAdminServiceMock), JWTKeys: keys})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"kid":"k1"`)
+
+	r = gin.New()
+	Setup(r, Deps{Auth: new(mocks.AuthServiceMock), Users: new(mocks.UserAdminServiceMock), JWTKeys: jwtkeys.NewHMAC("secret")})
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestSetup_PprofAdminOnly(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	token := func(role string) string {
+		claims := jwt.MapClaims{"sub": 1, "rol": role, "exp": time.Now().Add(time.Minute).Unix(), "iat": time.Now().Unix()}
+		signed, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("secret"))
+		return signed
+	}
+	get := func(r *gin.Engine, path, bearer string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if bearer != "" {
+			req.Header.Set("Authorization", "Bearer "+bearer)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	r := gin.New()
+	Setup(r, Deps{Auth: new(mocks.AuthServiceMock), Users: new(mocks.UserAdminServiceMock), JWTSecret: "secret", JWTExpires: time.Hour, Profiling: true})
+	assert.Equal(t, http.StatusUnauthorized, get(r, "/debug/pprof/", "").Code)
+	assert.Equal(t, http.StatusForbidden, get(r, "/debug/pprof/", token("support")).Code)
+
+	w := get(r, "/debug/pprof/goroutine?debug=1", token("admin"))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "goroutine profile")
+	w = get(r, "/debug/pprof/", token("admin"))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "heap")
+
+	r = gin.New()
+	Setup(r, Deps{Auth: new(mocks.AuthServiceMock), Users: new(mocks.UserAdminServiceMock), JWTSecret: "secret", JWTExpires: time.Hour})
+	assert.Equal(t, http.StatusNotFound, get(r, "/debug/pprof/", token("admin")).Code)
+}
+
+func TestSetup_UnknownRoutesAndMethods(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	serve := func(strict bool, method, path string) *httptest.ResponseRecorder {
+		r := gin.New()
+		Setup(r, Deps{Auth: new(mocks.AuthServiceMock), Users: new(mocks.UserAdminServiceMock), JWTSecret: "secret", JWTExpires: time.Hour, StrictSlash: strict})
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		return w
+	}
+
+	w := serve(false, http.MethodGet, "/api/v1/nope")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.JSONEq(t, `{"error":"not found"}`, w.Body.String())
+
+	w = serve(false, http.MethodPatch, "/api/v1/me")
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	assert.Equal(t, "GET, PUT, DELETE", w.Header().Get("Allow"))
+	assert.JSONEq(t, `{"error":"method not allowed","allowed":"GET, PUT, DELETE"}`, w.Body.String())
+
+	w = serve(false, http.MethodGet, "/api/v1/me/")
+	assert.Equal(t, http.StatusMovedPermanently, w.Code)
+	assert.Equal(t, "/api/v1/me", w.Header().Get("Location"))
+
+	assert.Equal(t, http.StatusNotFound, serve(true, http.MethodGet, "/api/v1/me/").Code, "strict slashes")
+}
@@ -0,0 +1,170 @@
+// Package scheduler runs periodic maintenance jobs defined in config (log trimming, purges,
+// stats refresh) on the leader replica, each on its own interval.
+//
+// Each job's latest run is kept in the Redis hash "scheduler:runs" (job name -> JSON), so any
+// replica can serve GET /admin/scheduler, and a new leader carries on with the schedule where
+// the old one left it instead of running everything again at once.
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"HelmyTask/utils/redislog"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// runsKey holds the latest Status per job.
+const runsKey = "scheduler:runs"
+
+// tick is how often Run looks for due jobs.
+const tick = time.Second
+
+// Task is one job's work. The string is a one-line summary for the status ("removed 120
+// entries"); the context ends after the job's interval, so a run never overlaps the next.
+type Task func(ctx context.Context) (string, error)
+
+// Job is a named task and how often it runs.
+type Job struct {
+	Name  string
+	Every time.Duration
+	Run   Task
+}
+
+// Status is a job's latest run.
+type Status struct {
+	Name                string     `json:"name"`
+	Every               string     `json:"every"`
+	LastRun             *time.Time `json:"last_run,omitempty"` // nil = not run yet
+	DurationMS          int64      `json:"duration_ms"`
+	OK                  bool       `json:"ok"`
+	Result              string     `json:"result,omitempty"`
+	Error               string     `json:"error,omitempty"`
+	Runs                int64      `json:"runs"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	NextRun             *time.Time `json:"next_run,omitempty"`
+}
+
+// Scheduler runs the jobs and records their status.
+type Scheduler struct {
+	rdb  *redis.Client
+	log  *redislog.Logger
+	jobs []Job
+	now  func() time.Time
+}
+
+// New creates a scheduler. Every replica builds the same one (for Status); only the leader
+// calls Run.
+func New(rdb *redis.Client, rlog *redislog.Logger, jobs ...Job) *Scheduler {
+	return &Scheduler{rdb: rdb, log: rlog, jobs: jobs, now: time.Now}
+}
+
+// Run runs each job when due until ctx is done (meant for leader.Group). A job is due Every
+// after its last recorded run, by whichever replica; one that never ran is due right away.
+// Jobs run one at a time, so a slow one delays the others rather than piling up.
+func (s *Scheduler) Run(ctx context.Context) {
+	if len(s.jobs) == 0 {
+		return
+	}
+	last, err := s.load(ctx)
+	if err != nil && s.log != nil {
+		s.log.Warn("scheduler: previous runs not loaded", map[string]string{"err": err.Error()})
+	}
+	t := time.NewTicker(tick)
+	defer t.Stop()
+	for {
+		for _, j := range s.jobs {
+			if ctx.Err() != nil {
+				return
+			}
+			prev := last[j.Name]
+			if prev.LastRun == nil || !s.now().Before(prev.LastRun.Add(j.Every)) {
+				last[j.Name] = s.RunJob(ctx, j, prev)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// RunJob runs j once after prev and records the outcome. A panicking task counts as failed.
+func (s *Scheduler) RunJob(ctx context.Context, j Job, prev Status) Status {
+	cctx, cancel := context.WithTimeout(ctx, j.Every)
+	start := s.now()
+	result, err := safeRun(cctx, j.Run)
+	cancel()
+
+	at := start.UTC()
+	next := at.Add(j.Every)
+	st := Status{Name: j.Name, Every: j.Every.String(), LastRun: &at, DurationMS: s.now().Sub(start).Milliseconds(), OK: err == nil, Result: result, Runs: prev.Runs + 1, NextRun: &next}
+	meta := map[string]string{"job": j.Name, "duration_ms": fmt.Sprint(st.DurationMS)}
+	if err != nil {
+		st.Error = err.Error()
+		st.ConsecutiveFailures = prev.ConsecutiveFailures + 1
+		meta["err"], meta["failures"] = st.Error, fmt.Sprint(st.ConsecutiveFailures)
+		if s.log != nil {
+			s.log.Warn("scheduled job failed", meta)
+		}
+	} else if s.log != nil {
+		meta["result"] = result
+		s.log.Info("scheduled job ran", meta)
+	}
+
+	if b, jerr := json.Marshal(st); jerr == nil {
+		_ = s.rdb.HSet(context.WithoutCancel(ctx), runsKey, j.Name, b).Err()
+	}
+	return st
+}
+
+// safeRun turns a panic in the task into an error so one bad job can't stop the others.
+func safeRun(ctx context.Context, task Task) (result string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return task(ctx)
+}
+
+// Status returns every configured job's latest run, by name (jobs that haven't run yet show
+// only their name and interval).
+func (s *Scheduler) Status(ctx context.Context) ([]Status, error) {
+	last, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Status, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		st, ok := last[j.Name]
+		if !ok {
+			st = Status{Name: j.Name}
+		}
+		st.Every = j.Every.String() // the configured interval, even if it changed since the run
+		out = append(out, st)
+	}
+	sort.Slice(out, func(i, k int) bool { return out[i].Name < out[k].Name })
+	return out, nil
+}
+
+// load reads the recorded runs (always a usable map, even on error).
+func (s *Scheduler) load(ctx context.Context) (map[string]Status, error) {
+	out := map[string]Status{}
+	raw, err := s.rdb.HGetAll(ctx, runsKey).Result()
+	if err != nil {
+		return out, err
+	}
+	for name, v := range raw {
+		var st Status
+		if json.Unmarshal([]byte(v), &st) == nil {
+			out[name] = st
+		}
+	}
+	return out, nil
+}
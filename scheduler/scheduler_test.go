@@ -0,0 +1,74 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"HelmyTask/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunJob_RecordsOutcome(t *testing.T) {
+	rdb, m := mocks.NewRedisMock()
+	anyArgs := func(expected, actual []interface{}) error { return nil } // status embeds timings
+	s := New(rdb, nil)
+	fail := true
+	job := Job{Name: "trim_log", Every: time.Hour, Run: func(context.Context) (string, error) {
+		if fail {
+			return "", errors.New("redis down")
+		}
+		return "removed 3 log entries", nil
+	}}
+
+	m.CustomMatch(anyArgs).ExpectHSet(runsKey, "trim_log", "").SetVal(1)
+	st := s.RunJob(context.Background(), job, Status{})
+	assert.False(t, st.OK)
+	assert.Equal(t, "redis down", st.Error)
+	assert.Equal(t, 1, st.ConsecutiveFailures)
+
+	fail = false
+	m.CustomMatch(anyArgs).ExpectHSet(runsKey, "trim_log", "").SetVal(1)
+	st = s.RunJob(context.Background(), job, st)
+	assert.True(t, st.OK)
+	assert.Equal(t, "removed 3 log entries", st.Result)
+	assert.Zero(t, st.ConsecutiveFailures)
+	assert.Equal(t, int64(2), st.Runs)
+	require.NotNil(t, st.NextRun)
+	assert.Equal(t, st.LastRun.Add(time.Hour), *st.NextRun)
+	assert.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestRunJob_PanicIsAFailure(t *testing.T) {
+	rdb, m := mocks.NewRedisMock()
+	m.CustomMatch(func(expected, actual []interface{}) error { return nil }).ExpectHSet(runsKey, "refresh_stats", "").SetVal(1)
+	s := New(rdb, nil)
+
+	st := s.RunJob(context.Background(), Job{Name: "refresh_stats", Every: time.Minute, Run: func(context.Context) (string, error) {
+		panic("nil map")
+	}}, Status{})
+	assert.False(t, st.OK)
+	assert.Equal(t, "panic: nil map", st.Error)
+}
+
+func TestStatus_ConfiguredJobsOnly(t *testing.T) {
+	rdb, m := mocks.NewRedisMock()
+	noop := func(context.Context) (string, error) { return "", nil }
+	s := New(rdb, nil, Job{Name: "trim_log", Every: 2 * time.Hour, Run: noop}, Job{Name: "refresh_stats", Every: 5 * time.Minute, Run: noop})
+	m.ExpectHGetAll(runsKey).SetVal(map[string]string{
+		"trim_log":    `{"name":"trim_log","every":"1h0m0s","ok":true,"runs":4}`,
+		"retired_job": `{"name":"retired_job","ok":true}`,
+	})
+
+	got, err := s.Status(context.Background())
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	assert.Equal(t, "refresh_stats", got[0].Name)
+	assert.Nil(t, got[0].LastRun, "not run yet")
+	assert.Equal(t, "5m0s", got[0].Every)
+	assert.Equal(t, int64(4), got[1].Runs)
+	assert.Equal(t, "2h0m0s", got[1].Every, "the configured interval wins")
+}
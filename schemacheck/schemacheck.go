@@ -0,0 +1,259 @@
+// Package schemacheck verifies that the database schema is one this build can write to.
+//
+// Each build expects the tables and columns of its models; their checksum identifies that
+// schema. InitDB records the checksum after migrating (models.SchemaVersion). Before serving,
+// and on every readiness check, Check compares: if the latest recorded migration is ours the
+// schema is compatible; otherwise (another build migrated since, or a rollback) the actual
+// columns are inspected for missing tables/columns and type changes. While incompatible,
+// writes are refused (middlewares.SchemaGuard) and the details shown in /admin/diagnostics.
+package schemacheck
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"HelmyTask/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// Mismatch problems.
+const (
+	MissingTable  = "missing_table"
+	MissingColumn = "missing_column"
+	TypeChanged   = "type_changed"
+)
+
+// Mismatch is one way the database differs from what the models expect.
+type Mismatch struct {
+	Table   string `json:"table"`
+	Column  string `json:"column,omitempty"`
+	Problem string `json:"problem"`        // missing_table|missing_column|type_changed
+	Want    string `json:"want,omitempty"` // type_changed: the model's kind (string, int, time...)
+	Got     string `json:"got,omitempty"`  // type_changed: the column's database type
+}
+
+// Report is the outcome of a Check.
+type Report struct {
+	Compatible bool       `json:"compatible"`
+	Checksum   string     `json:"checksum"`             // the schema this build expects
+	Applied    string     `json:"applied"`              // checksum of the latest recorded migration ("" = none)
+	AppliedBy  string     `json:"applied_by,omitempty"` // app version that recorded it
+	Mismatches []Mismatch `json:"mismatches,omitempty"`
+	CheckedAt  time.Time  `json:"checked_at"`
+	Error      string     `json:"error,omitempty"` // the check itself failed; Compatible is the previous verdict
+}
+
+// table is what a model expects of its table.
+type table struct {
+	model   any
+	name    string
+	columns []column
+}
+
+type column struct {
+	name string
+	kind schema.DataType // "" = a custom type, not compared
+}
+
+// Checker compares the database with the models it was built from.
+type Checker struct {
+	db       *gorm.DB
+	tables   []table
+	checksum string
+
+	mu   sync.Mutex
+	last Report
+	now  func() time.Time
+}
+
+// New parses the models (the ones config.Migrate migrates). Until the first Check the schema counts
+// as compatible.
+func New(db *gorm.DB, models ...any) (*Checker, error) {
+	c := &Checker{db: db, now: time.Now}
+	h := sha256.New()
+	for _, m := range models {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(m); err != nil {
+			return nil, fmt.Errorf("schemacheck: %T: %w", m, err)
+		}
+		t := table{model: m, name: stmt.Schema.Table}
+		for _, f := range stmt.Schema.Fields {
+			if f.DBName == "" {
+				continue // not a column (ignored or relation)
+			}
+			t.columns = append(t.columns, column{name: f.DBName, kind: checkedKind(f.DataType)})
+		}
+		sort.Slice(t.columns, func(i, j int) bool { return t.columns[i].name < t.columns[j].name })
+		c.tables = append(c.tables, t)
+	}
+	sort.Slice(c.tables, func(i, j int) bool { return c.tables[i].name < c.tables[j].name })
+	for _, t := range c.tables {
+		for _, col := range t.columns {
+			fmt.Fprintf(h, "%s.%s:%s\n", t.name, col.name, col.kind)
+		}
+	}
+	c.checksum = hex.EncodeToString(h.Sum(nil))
+	c.last = Report{Compatible: true, Checksum: c.checksum}
+	return c, nil
+}
+
+// Checksum identifies the schema this build expects.
+func (c *Checker) Checksum() string { return c.checksum }
+
+// Record notes that the database was just migrated to this build's schema (a no-op when the
+// latest migration already was, as on every boot but the first of a release).
+func (c *Checker) Record(appVersion string) error {
+	latest, err := c.latest(c.db)
+	if err != nil {
+		return err
+	}
+	if latest.Checksum == c.checksum {
+		return nil
+	}
+	return c.db.Create(&models.SchemaVersion{Checksum: c.checksum, AppVersion: appVersion, AppliedAt: c.now().UTC()}).Error
+}
+
+// latest is the most recent recorded migration (zero if none).
+func (c *Checker) latest(db *gorm.DB) (models.SchemaVersion, error) {
+	var v models.SchemaVersion
+	err := db.Order("id DESC").Limit(1).Find(&v).Error
+	return v, err
+}
+
+// Check compares the database with the models and keeps the result for Last/Compatible.
+func (c *Checker) Check(ctx context.Context) Report {
+	r := c.compare(ctx)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if r.Error != "" {
+		r.Compatible = c.last.Compatible // a DB outage isn't a schema verdict
+	}
+	c.last = r
+	return r
+}
+
+// Last is the latest Check's result.
+func (c *Checker) Last() Report {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.last
+}
+
+// Compatible reports the latest verdict (true until checked).
+func (c *Checker) Compatible() bool { return c.Last().Compatible }
+
+// Ready is Check as a readiness check: an error while the schema is incompatible.
+func (c *Checker) Ready(ctx context.Context) error {
+	r := c.Check(ctx)
+	if r.Error != "" {
+		return errors.New(r.Error)
+	}
+	if !r.Compatible {
+		return fmt.Errorf("schema incompatible: %d mismatches (see /admin/diagnostics)", len(r.Mismatches))
+	}
+	return nil
+}
+
+func (c *Checker) compare(ctx context.Context) Report {
+	r := Report{Checksum: c.checksum, CheckedAt: c.now().UTC()}
+	db := c.db.WithContext(ctx)
+	latest, err := c.latest(db)
+	if err != nil {
+		r.Error = err.Error()
+		return r
+	}
+	r.Applied, r.AppliedBy = latest.Checksum, latest.AppVersion
+	if r.Applied == c.checksum { // migrated to exactly our models
+		r.Compatible = true
+		return r
+	}
+	for _, t := range c.tables {
+		if !db.Migrator().HasTable(t.model) {
+			r.Mismatches = append(r.Mismatches, Mismatch{Table: t.name, Problem: MissingTable})
+			continue
+		}
+		types, err := db.Migrator().ColumnTypes(t.model)
+		if err != nil {
+			r.Error = err.Error()
+			return r
+		}
+		got := make(map[string]string, len(types))
+		for _, ct := range types {
+			got[strings.ToLower(ct.Name())] = ct.DatabaseTypeName()
+		}
+		for _, col := range t.columns {
+			dbType, ok := got[strings.ToLower(col.name)]
+			switch {
+			case !ok:
+				r.Mismatches = append(r.Mismatches, Mismatch{Table: t.name, Column: col.name, Problem: MissingColumn})
+			case !fits(col.kind, dbType):
+				r.Mismatches = append(r.Mismatches, Mismatch{Table: t.name, Column: col.name, Problem: TypeChanged, Want: string(col.kind), Got: dbType})
+			}
+		}
+	}
+	r.Compatible = len(r.Mismatches) == 0
+	return r
+}
+
+// checkedKind is the kind of a model field whose column type is checked ("" for custom types).
+func checkedKind(t schema.DataType) schema.DataType {
+	switch t {
+	case schema.Bool, schema.Int, schema.Uint, schema.Float, schema.String, schema.Time, schema.Bytes:
+		return t
+	}
+	return ""
+}
+
+// fits reports whether a column of the database type can hold the model's kind. Drivers name
+// types differently (MySQL bools are TINYINT, SQL Server's BIT, SQLite's NUMERIC...), so this goes by
+// families; unfamiliar type names are given the benefit of the doubt.
+func fits(kind schema.DataType, dbType string) bool {
+	family := typeFamily(dbType)
+	if kind == "" || family == "" {
+		return true
+	}
+	switch kind {
+	case schema.Bool:
+		return family == "bool" || family == "int" || family == "float" // SQLite's are NUMERIC
+	case schema.Int, schema.Uint:
+		return family == "int"
+	case schema.Float:
+		return family == "float" || family == "int" // SQLite's REAL is fine, and so is a NUMERIC
+	case schema.String:
+		return family == "string"
+	case schema.Time:
+		return family == "time" || family == "string" // SQLite keeps times as text
+	case schema.Bytes:
+		return family == "bytes" || family == "string"
+	}
+	return true
+}
+
+// typeFamily groups database type names; "" = not recognized.
+func typeFamily(dbType string) string {
+	t := strings.ToLower(dbType)
+	switch {
+	case strings.Contains(t, "bool") || t == "bit":
+		return "bool"
+	case strings.Contains(t, "int") || strings.Contains(t, "serial"):
+		return "int"
+	case strings.Contains(t, "float") || strings.Contains(t, "double") || strings.Contains(t, "real") || strings.Contains(t, "numeric") || strings.Contains(t, "decimal"):
+		return "float"
+	case strings.Contains(t, "time") || strings.Contains(t, "date"):
+		return "time"
+	case strings.Contains(t, "char") || strings.Contains(t, "text") || strings.Contains(t, "clob") || strings.Contains(t, "json") || strings.Contains(t, "enum"):
+		return "string"
+	case strings.Contains(t, "blob") || strings.Contains(t, "binary") || strings.Contains(t, "bytea"):
+		return "bytes"
+	}
+	return ""
+}
@@ -0,0 +1,110 @@
+package schemacheck
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+type widget struct {
+	ID    uint
+	Name  string
+	Price float64
+}
+
+type widgetV2 struct {
+	ID    uint
+	Name  string
+	Price float64
+	Stock int
+}
+
+func (widgetV2) TableName() string { return "widgets" }
+
+func newMockDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	db, err := gorm.Open(mysql.New(mysql.Config{Conn: sqlDB, SkipInitializeWithVersion: true}), &gorm.Config{})
+	require.NoError(t, err)
+	return db, mock
+}
+
+func TestNew_ChecksumFollowsColumns(t *testing.T) {
+	db, _ := newMockDB(t)
+	v1, err := New(db, &widget{})
+	require.NoError(t, err)
+	again, err := New(db, &widget{})
+	require.NoError(t, err)
+	v2, err := New(db, &widgetV2{})
+	require.NoError(t, err)
+
+	assert.Len(t, v1.Checksum(), 64)
+	assert.Equal(t, v1.Checksum(), again.Checksum())
+	assert.NotEqual(t, v1.Checksum(), v2.Checksum(), "a new column is a new schema")
+	assert.True(t, v1.Compatible(), "compatible until checked")
+}
+
+func TestCheck_RecordedChecksumIsCompatible(t *testing.T) {
+	db, mock := newMockDB(t)
+	c, err := New(db, &widget{})
+	require.NoError(t, err)
+	latest := regexp.QuoteMeta("SELECT * FROM `schema_versions` ORDER BY id DESC LIMIT ?")
+
+	mock.ExpectQuery(latest).WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "checksum", "app_version"}).AddRow(4, c.Checksum(), "1.0.0"))
+	r := c.Check(context.Background())
+	assert.True(t, r.Compatible)
+	assert.Equal(t, c.Checksum(), r.Applied)
+	assert.Equal(t, "1.0.0", r.AppliedBy)
+
+	// the DB being down is no verdict on the schema
+	mock.ExpectQuery(latest).WithArgs(1).WillReturnError(errors.New("connection refused"))
+	r = c.Check(context.Background())
+	assert.Equal(t, "connection refused", r.Error)
+	assert.True(t, r.Compatible)
+	assert.Error(t, c.Ready(context.Background()))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFits_ByTypeFamily(t *testing.T) {
+	for _, tc := range []struct {
+		kind   schema.DataType
+		dbType string
+		ok     bool
+	}{
+		{schema.Bool, "TINYINT", true},
+		{schema.Bool, "bit", true},
+		{schema.Bool, "numeric", true},
+		{schema.Uint, "BIGINT UNSIGNED", true},
+		{schema.Int, "VARCHAR", false},
+		{schema.String, "longtext", true},
+		{schema.String, "INT", false},
+		{schema.Time, "datetime(3)", true},
+		{schema.Time, "timestamptz", true},
+		{schema.Float, "double precision", true},
+		{schema.Bytes, "bytea", true},
+		{schema.String, "geometry", true}, // unknown to us: not flagged
+		{"", "INT", true},                 // custom type: not compared
+	} {
+		assert.Equal(t, tc.ok, fits(tc.kind, tc.dbType), "%s in %s", tc.kind, tc.dbType)
+	}
+}
+
+func TestRecord_OncePerSchema(t *testing.T) {
+	db, mock := newMockDB(t)
+	c, err := New(db, &widget{})
+	require.NoError(t, err)
+	latest := regexp.QuoteMeta("SELECT * FROM `schema_versions` ORDER BY id DESC LIMIT ?")
+
+	mock.ExpectQuery(latest).WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "checksum"}).AddRow(4, c.Checksum()))
+	require.NoError(t, c.Record("1.0.0")) // already recorded: no insert
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
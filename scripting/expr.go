@@ -0,0 +1,446 @@
+package scripting
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrEval wraps every runtime failure of a script (type mismatch, unknown name...).
+var ErrEval = errors.New("script evaluation failed")
+
+// Program is a compiled expression. Programs are immutable and safe for concurrent use.
+type Program struct {
+	src  string
+	expr ast.Expr
+}
+
+// Compile parses src (Go expression syntax) and rejects anything outside the supported subset:
+// literals, names, field/index access, ! - + * / % comparisons && || and the builtin functions.
+func Compile(src string) (*Program, error) {
+	e, err := parser.ParseExpr(src)
+	if err != nil {
+		return nil, fmt.Errorf("scripting: %q: %w", src, err)
+	}
+	var bad error
+	ast.Inspect(e, func(n ast.Node) bool {
+		if bad != nil || n == nil {
+			return false
+		}
+		switch n := n.(type) {
+		case *ast.Ident, *ast.SelectorExpr, *ast.IndexExpr, *ast.ParenExpr:
+		case *ast.BasicLit:
+			if n.Kind != token.INT && n.Kind != token.FLOAT && n.Kind != token.STRING {
+				bad = fmt.Errorf("unsupported literal %s", n.Value)
+			}
+		case *ast.UnaryExpr:
+			if n.Op != token.NOT && n.Op != token.SUB {
+				bad = fmt.Errorf("unsupported operator %s", n.Op)
+			}
+		case *ast.BinaryExpr:
+			if !binaryOps[n.Op] {
+				bad = fmt.Errorf("unsupported operator %s", n.Op)
+			}
+		case *ast.CallExpr:
+			id, ok := n.Fun.(*ast.Ident)
+			if !ok || builtins[id.Name] == nil || n.Ellipsis.IsValid() {
+				bad = fmt.Errorf("unknown function %s", exprString(n.Fun))
+			}
+		default:
+			bad = fmt.Errorf("unsupported syntax %T", n)
+		}
+		return bad == nil
+	})
+	if bad != nil {
+		return nil, fmt.Errorf("scripting: %q: %w", src, bad)
+	}
+	return &Program{src: src, expr: e}, nil
+}
+
+// String returns the source text.
+func (p *Program) String() string { return p.src }
+
+// Eval runs the program against env (names → values; maps/slices/structs of basic types).
+func (p *Program) Eval(env map[string]interface{}) (interface{}, error) {
+	v, err := eval(p.expr, env)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q: %v", ErrEval, p.src, err)
+	}
+	return v, nil
+}
+
+// Bool runs the program and requires a boolean result.
+func (p *Program) Bool(env map[string]interface{}) (bool, error) {
+	v, err := p.Eval(env)
+	if err != nil {
+		return false, err
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("%w: %q: want bool, got %T", ErrEval, p.src, v)
+	}
+	return b, nil
+}
+
+var binaryOps = map[token.Token]bool{
+	token.LAND: true, token.LOR: true, token.EQL: true, token.NEQ: true,
+	token.LSS: true, token.LEQ: true, token.GTR: true, token.GEQ: true,
+	token.ADD: true, token.SUB: true, token.MUL: true, token.QUO: true, token.REM: true,
+}
+
+func eval(e ast.Expr, env map[string]interface{}) (interface{}, error) {
+	switch e := e.(type) {
+	case *ast.ParenExpr:
+		return eval(e.X, env)
+	case *ast.BasicLit:
+		if e.Kind == token.STRING {
+			return strconv.Unquote(e.Value)
+		}
+		return strconv.ParseFloat(strings.ReplaceAll(e.Value, "_", ""), 64)
+	case *ast.Ident:
+		switch e.Name {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "nil":
+			return nil, nil
+		}
+		v, ok := env[e.Name]
+		if !ok {
+			return nil, fmt.Errorf("unknown name %s", e.Name)
+		}
+		return normalize(v), nil
+	case *ast.SelectorExpr:
+		x, err := eval(e.X, env)
+		if err != nil {
+			return nil, err
+		}
+		m, ok := x.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s is %T, not an object", exprString(e.X), x)
+		}
+		return normalize(m[e.Sel.Name]), nil // missing fields read as nil
+	case *ast.IndexExpr:
+		return evalIndex(e, env)
+	case *ast.UnaryExpr:
+		x, err := eval(e.X, env)
+		if err != nil {
+			return nil, err
+		}
+		if e.Op == token.NOT {
+			b, ok := x.(bool)
+			if !ok {
+				return nil, fmt.Errorf("! needs a bool, got %T", x)
+			}
+			return !b, nil
+		}
+		f, ok := x.(float64)
+		if !ok {
+			return nil, fmt.Errorf("- needs a number, got %T", x)
+		}
+		return -f, nil
+	case *ast.BinaryExpr:
+		return evalBinary(e, env)
+	case *ast.CallExpr:
+		args := make([]interface{}, len(e.Args))
+		for i, a := range e.Args {
+			v, err := eval(a, env)
+			if err != nil {
+				return nil, err
+			}
+			args[i] = v
+		}
+		name := e.Fun.(*ast.Ident).Name // checked by Compile
+		v, err := builtins[name](args)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+		return v, nil
+	}
+	return nil, fmt.Errorf("unsupported syntax %T", e)
+}
+
+func evalIndex(e *ast.IndexExpr, env map[string]interface{}) (interface{}, error) {
+	x, err := eval(e.X, env)
+	if err != nil {
+		return nil, err
+	}
+	idx, err := eval(e.Index, env)
+	if err != nil {
+		return nil, err
+	}
+	switch x := x.(type) {
+	case []interface{}:
+		f, ok := idx.(float64)
+		if !ok || f != float64(int(f)) {
+			return nil, fmt.Errorf("list index must be an integer, got %v", idx)
+		}
+		i := int(f)
+		if i < 0 {
+			i += len(x) // list[-1] is the last element
+		}
+		if i < 0 || i >= len(x) {
+			return nil, fmt.Errorf("index %v out of range (len %d)", idx, len(x))
+		}
+		return x[i], nil
+	case map[string]interface{}:
+		k, ok := idx.(string)
+		if !ok {
+			return nil, fmt.Errorf("object key must be a string, got %T", idx)
+		}
+		return normalize(x[k]), nil
+	}
+	return nil, fmt.Errorf("cannot index %T", x)
+}
+
+func evalBinary(e *ast.BinaryExpr, env map[string]interface{}) (interface{}, error) {
+	x, err := eval(e.X, env)
+	if err != nil {
+		return nil, err
+	}
+	if e.Op == token.LAND || e.Op == token.LOR { // short-circuit
+		b, ok := x.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%s needs bools, got %T", e.Op, x)
+		}
+		if b == (e.Op == token.LOR) {
+			return b, nil
+		}
+		y, err := eval(e.Y, env)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := y.(bool); !ok {
+			return nil, fmt.Errorf("%s needs bools, got %T", e.Op, y)
+		}
+		return y, nil
+	}
+	y, err := eval(e.Y, env)
+	if err != nil {
+		return nil, err
+	}
+	switch e.Op {
+	case token.EQL:
+		return reflect.DeepEqual(x, y), nil
+	case token.NEQ:
+		return !reflect.DeepEqual(x, y), nil
+	}
+	if xs, ok := x.(string); ok {
+		ys, ok := y.(string)
+		if !ok {
+			return nil, fmt.Errorf("mismatched types string %s %T", e.Op, y)
+		}
+		switch e.Op {
+		case token.ADD:
+			return xs + ys, nil
+		case token.LSS:
+			return xs < ys, nil
+		case token.LEQ:
+			return xs <= ys, nil
+		case token.GTR:
+			return xs > ys, nil
+		case token.GEQ:
+			return xs >= ys, nil
+		}
+		return nil, fmt.Errorf("operator %s not defined on strings", e.Op)
+	}
+	xf, ok1 := x.(float64)
+	yf, ok2 := y.(float64)
+	if !ok1 || !ok2 {
+		return nil, fmt.Errorf("operator %s needs numbers or strings, got %T and %T", e.Op, x, y)
+	}
+	switch e.Op {
+	case token.ADD:
+		return xf + yf, nil
+	case token.SUB:
+		return xf - yf, nil
+	case token.MUL:
+		return xf * yf, nil
+	case token.QUO, token.REM:
+		if yf == 0 {
+			return nil, errors.New("division by zero")
+		}
+		if e.Op == token.QUO {
+			return xf / yf, nil
+		}
+		if int64(yf) == 0 { // % works on whole numbers: 0.5 truncates to 0
+			return nil, errors.New("division by zero")
+		}
+		return float64(int64(xf) % int64(yf)), nil
+	case token.LSS:
+		return xf < yf, nil
+	case token.LEQ:
+		return xf <= yf, nil
+	case token.GTR:
+		return xf > yf, nil
+	default: // token.GEQ
+		return xf >= yf, nil
+	}
+}
+
+// normalize maps Go values onto the script's types: float64, string, bool, nil,
+// []interface{} and map[string]interface{}.
+func normalize(v interface{}) interface{} {
+	switch v := v.(type) {
+	case nil, bool, string, float64, []interface{}, map[string]interface{}:
+		return v
+	case []string:
+		out := make([]interface{}, len(v))
+		for i, s := range v {
+			out[i] = s
+		}
+		return out
+	case map[string]string:
+		out := make(map[string]interface{}, len(v))
+		for k, s := range v {
+			out[k] = s
+		}
+		return out
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint())
+	case reflect.Float32:
+		return rv.Float()
+	case reflect.String:
+		return rv.String()
+	case reflect.Bool:
+		return rv.Bool()
+	}
+	return fmt.Sprint(v) // anything else (time.Time...) compares as text
+}
+
+// builtins are the only functions scripts can call; none of them touch I/O.
+var builtins = map[string]func(args []interface{}) (interface{}, error){
+	"len": func(a []interface{}) (interface{}, error) {
+		if err := arity(a, 1); err != nil {
+			return nil, err
+		}
+		switch v := a[0].(type) {
+		case string:
+			return float64(utf8.RuneCountInString(v)), nil
+		case []interface{}:
+			return float64(len(v)), nil
+		case map[string]interface{}:
+			return float64(len(v)), nil
+		case nil:
+			return 0.0, nil
+		}
+		return nil, fmt.Errorf("want string, list or object, got %T", a[0])
+	},
+	"lower": stringFn(strings.ToLower),
+	"upper": stringFn(strings.ToUpper),
+	"trim":  stringFn(strings.TrimSpace),
+	"domain": stringFn(func(s string) string { // part after the last "@", lowercased
+		return strings.ToLower(s[strings.LastIndex(s, "@")+1:])
+	}),
+	"hasPrefix": stringPred(strings.HasPrefix),
+	"hasSuffix": stringPred(strings.HasSuffix),
+	"contains": func(a []interface{}) (interface{}, error) { // substring, or list membership
+		if err := arity(a, 2); err != nil {
+			return nil, err
+		}
+		if list, ok := a[0].([]interface{}); ok {
+			for _, v := range list {
+				if reflect.DeepEqual(v, a[1]) {
+					return true, nil
+				}
+			}
+			return false, nil
+		}
+		return stringPred(strings.Contains)(a)
+	},
+	"matches": func(a []interface{}) (interface{}, error) { // RE2 syntax, linear time
+		s, pattern, err := twoStrings(a)
+		if err != nil {
+			return nil, err
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, err
+		}
+		return re.MatchString(s), nil
+	},
+	"split": func(a []interface{}) (interface{}, error) {
+		s, sep, err := twoStrings(a)
+		if err != nil {
+			return nil, err
+		}
+		return normalize(strings.Split(s, sep)), nil
+	},
+	"oneOf": func(a []interface{}) (interface{}, error) { // oneOf(role, "admin", "support")
+		if len(a) < 2 {
+			return nil, errors.New("want a value and at least one option")
+		}
+		for _, v := range a[1:] {
+			if reflect.DeepEqual(a[0], v) {
+				return true, nil
+			}
+		}
+		return false, nil
+	},
+}
+
+func arity(a []interface{}, n int) error {
+	if len(a) != n {
+		return fmt.Errorf("want %d arguments, got %d", n, len(a))
+	}
+	return nil
+}
+
+func stringFn(f func(string) string) func([]interface{}) (interface{}, error) {
+	return func(a []interface{}) (interface{}, error) {
+		if err := arity(a, 1); err != nil {
+			return nil, err
+		}
+		s, ok := a[0].(string)
+		if !ok {
+			return nil, fmt.Errorf("want string, got %T", a[0])
+		}
+		return f(s), nil
+	}
+}
+
+func stringPred(f func(string, string) bool) func([]interface{}) (interface{}, error) {
+	return func(a []interface{}) (interface{}, error) {
+		s, t, err := twoStrings(a)
+		if err != nil {
+			return nil, err
+		}
+		return f(s, t), nil
+	}
+}
+
+func twoStrings(a []interface{}) (string, string, error) {
+	if err := arity(a, 2); err != nil {
+		return "", "", err
+	}
+	s, ok1 := a[0].(string)
+	t, ok2 := a[1].(string)
+	if !ok1 || !ok2 {
+		return "", "", fmt.Errorf("want two strings, got %T and %T", a[0], a[1])
+	}
+	return s, t, nil
+}
+
+// exprString renders a node for error messages.
+func exprString(e ast.Expr) string {
+	switch e := e.(type) {
+	case *ast.Ident:
+		return e.Name
+	case *ast.SelectorExpr:
+		return exprString(e.X) + "." + e.Sel.Name
+	}
+	return fmt.Sprintf("%T", e)
+}
@@ -0,0 +1,68 @@
+package scripting
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProgram_Eval(t *testing.T) {
+	env := map[string]interface{}{
+		"name":  "Ahmed Helmy",
+		"email": "Ahmed@Example.COM",
+		"id":    uint(42),
+		"tags":  []string{"beta", "staff"},
+		"data":  map[string]interface{}{"plan": "pro", "seats": 5.0},
+	}
+	cases := []struct {
+		src  string
+		want interface{}
+	}{
+		{`domain(email) == "example.com"`, true},
+		{`hasSuffix(lower(email), "@example.com") && len(name) > 3`, true},
+		{`id % 2 == 0 || undefinedIsNeverEvaluated`, true},
+		{`contains(tags, "staff")`, true},
+		{`oneOf(data.plan, "pro", "enterprise")`, true},
+		{`data["seats"] * 2`, 10.0},
+		{`data.missing == nil`, true},
+		{`split(email, "@")[-1]`, "Example.COM"},
+		{`matches(name, "^[A-Z][a-z]+ [A-Z]")`, true},
+		{`"org-" + upper(split(name, " ")[0])`, "org-AHMED"},
+		{`!(1 < 2)`, false},
+	}
+	for _, tc := range cases {
+		p, err := Compile(tc.src)
+		require.NoError(t, err, tc.src)
+		got, err := p.Eval(env)
+		require.NoError(t, err, tc.src)
+		assert.Equal(t, tc.want, got, tc.src)
+	}
+}
+
+func TestCompile_RejectsUnsupported(t *testing.T) {
+	for _, src := range []string{
+		`os.Exit(1)`, // unknown function
+		`func() bool { return true }()`,
+		`[]int{1}`,   // composite literals
+		`x.(string)`, // type assertions
+		`a & b`,      // bit ops
+		`'c'`,        // rune literals
+		`email ==`,   // syntax error
+	} {
+		_, err := Compile(src)
+		assert.Error(t, err, src)
+	}
+}
+
+func TestProgram_RuntimeErrors(t *testing.T) {
+	for _, src := range []string{`nope == 1`, `name + 1`, `len(1)`, `1 / 0`, `len(name) % 0.5`, `name && true`} {
+		p, err := Compile(src)
+		require.NoError(t, err, src)
+		_, err = p.Eval(map[string]interface{}{"name": "x"})
+		assert.ErrorIs(t, err, ErrEval, src)
+	}
+	p, _ := Compile(`"text"`)
+	_, err := p.Bool(nil)
+	assert.ErrorIs(t, err, ErrEval)
+}
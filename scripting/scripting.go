@@ -0,0 +1,142 @@
+// Package scripting runs small, per-environment customizations written as expressions in
+// config — no recompiling, no plug-in binaries. Scripts use Go expression syntax over a
+// sandboxed subset (no loops, assignments or I/O; see Compile), e.g.
+//
+//	domain(email) == "example.com" && !contains(lower(name), "test")
+//
+// Three extension points are supported: registration rules (reject sign-ups), claim
+// enrichment (extra JWT claims), and a webhook filter (skip deliveries).
+package scripting
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"HelmyTask/core"
+	"HelmyTask/models"
+)
+
+// CodeRegistrationRule is the violation code for sign-ups rejected by a registration rule.
+const CodeRegistrationRule = "registration_rule"
+
+// reservedClaims are set by the service itself and can't be overridden by scripts.
+var reservedClaims = map[string]bool{"sub": true, "exp": true, "iat": true, "nbf": true, "iss": true, "aud": true, "jti": true, "eml": true, "rol": true}
+
+// Rule is one registration check: Expr must evaluate to true, else the sign-up is rejected with Message.
+type Rule struct {
+	Expr    string
+	Message string
+}
+
+// Spec is the uncompiled script configuration.
+type Spec struct {
+	RegistrationRules []Rule            // env: name, email
+	Claims            map[string]string // claim name → expression; env: id, name, email, role, two_factor_enabled
+	WebhookFilter     string            // deliver only when true; env: event, data
+}
+
+// Scripts holds the compiled programs. A nil *Scripts has no rules, adds no claims and filters nothing.
+type Scripts struct {
+	rules         []compiledRule
+	claims        map[string]*Program
+	webhookFilter *Program
+}
+
+type compiledRule struct {
+	prog    *Program
+	message string
+}
+
+// New compiles spec; any syntax error or reserved claim name is reported so bad config fails at boot.
+func New(spec Spec) (*Scripts, error) {
+	s := &Scripts{claims: map[string]*Program{}}
+	for _, r := range spec.RegistrationRules {
+		p, err := Compile(r.Expr)
+		if err != nil {
+			return nil, fmt.Errorf("registration rule: %w", err)
+		}
+		msg := r.Message
+		if msg == "" {
+			msg = "rejected by registration policy"
+		}
+		s.rules = append(s.rules, compiledRule{prog: p, message: msg})
+	}
+	for name, src := range spec.Claims {
+		if reservedClaims[name] {
+			return nil, fmt.Errorf("claim %q is reserved", name)
+		}
+		p, err := Compile(src)
+		if err != nil {
+			return nil, fmt.Errorf("claim %q: %w", name, err)
+		}
+		s.claims[name] = p
+	}
+	if spec.WebhookFilter != "" {
+		p, err := Compile(spec.WebhookFilter)
+		if err != nil {
+			return nil, fmt.Errorf("webhook filter: %w", err)
+		}
+		s.webhookFilter = p
+	}
+	return s, nil
+}
+
+// ValidateRegistration runs the registration rules. Violations mean the sign-up is refused;
+// an error means a rule itself is broken (wrong types for this input) and nothing was decided.
+func (s *Scripts) ValidateRegistration(name, email string) (core.Violations, error) {
+	if s == nil {
+		return nil, nil
+	}
+	env := map[string]interface{}{"name": name, "email": email}
+	var v core.Violations
+	for _, r := range s.rules {
+		ok, err := r.prog.Bool(env)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			v = append(v, core.Violation{Field: "registration", Code: CodeRegistrationRule, Message: r.message})
+		}
+	}
+	return v, nil
+}
+
+// Claims evaluates the extra JWT claims for u. Claims whose script fails are left out and
+// reported in the returned error; nil results are omitted.
+func (s *Scripts) Claims(u models.User) (map[string]interface{}, error) {
+	if s == nil || len(s.claims) == 0 {
+		return nil, nil
+	}
+	env := map[string]interface{}{"id": u.ID, "name": u.Name, "email": u.Email, "role": u.Role, "two_factor_enabled": u.TOTPEnabled}
+	out := make(map[string]interface{}, len(s.claims))
+	var errs []error
+	for name, p := range s.claims {
+		v, err := p.Eval(env)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("claim %q: %w", name, err))
+			continue
+		}
+		if v != nil {
+			out[name] = v
+		}
+	}
+	return out, errors.Join(errs...)
+}
+
+// AllowWebhook reports whether an event should be delivered. data is seen by the script in its
+// JSON form (data.email, data.id...). A broken filter fails open so events aren't silently lost.
+func (s *Scripts) AllowWebhook(event string, data interface{}) (bool, error) {
+	if s == nil || s.webhookFilter == nil {
+		return true, nil
+	}
+	var generic interface{}
+	if b, err := json.Marshal(data); err == nil {
+		_ = json.Unmarshal(b, &generic)
+	}
+	ok, err := s.webhookFilter.Bool(map[string]interface{}{"event": event, "data": generic})
+	if err != nil {
+		return true, err
+	}
+	return ok, nil
+}
@@ -0,0 +1,48 @@
+package scripting
+
+import (
+	"testing"
+
+	"HelmyTask/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScripts(t *testing.T) {
+	s, err := New(Spec{
+		RegistrationRules: []Rule{{Expr: `domain(email) == "corp.io"`, Message: "corporate addresses only"}},
+		Claims:            map[string]string{"org": `domain(email)`, "staff": `oneOf(role, "admin", "support")`},
+		WebhookFilter:     `event != "user.login"`,
+	})
+	require.NoError(t, err)
+
+	v, err := s.ValidateRegistration("Ahmed", "ahmed@gmail.com")
+	require.NoError(t, err)
+	require.Len(t, v, 1)
+	assert.Equal(t, CodeRegistrationRule, v[0].Code)
+	v, _ = s.ValidateRegistration("Ahmed", "ahmed@corp.io")
+	assert.Empty(t, v)
+
+	claims, err := s.Claims(models.User{ID: 1, Email: "a@Corp.io", Role: "support"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"org": "corp.io", "staff": true}, claims)
+
+	ok, _ := s.AllowWebhook("user.login", models.User{ID: 1})
+	assert.False(t, ok)
+	ok, _ = s.AllowWebhook("user.created", models.User{ID: 1})
+	assert.True(t, ok)
+
+	// nil scripts: nothing configured, nothing enforced
+	var none *Scripts
+	v, err = none.ValidateRegistration("x", "y")
+	assert.NoError(t, err)
+	assert.Empty(t, v)
+}
+
+func TestNew_RejectsBadConfig(t *testing.T) {
+	_, err := New(Spec{Claims: map[string]string{"sub": `"admin"`}})
+	assert.ErrorContains(t, err, "reserved")
+	_, err = New(Spec{RegistrationRules: []Rule{{Expr: `exec("rm")`}}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"HelmyTask/config"
+	"HelmyTask/core"
+	"HelmyTask/logger"
+	"HelmyTask/models"
+	"HelmyTask/policy"
+	"HelmyTask/seed"
+	"HelmyTask/services"
+
+	"github.com/spf13/cobra"
+)
+
+// adminPasswordEnv is create-admin's alternative to --password, which shows up in ps and shell
+// history.
+const adminPasswordEnv = "ADMIN_PASSWORD"
+
+// seedUser is one entry of a fixtures file: a registration plus the role to give the account.
+type seedUser struct {
+	models.RegisterRequest
+	Role string `json:"role"` // "" = user
+}
+
+// newSeedCmd is `server seed [--file fixtures/users.json]`: it creates the fixture users (for
+// development and demo databases). Users whose email is already registered are skipped, so
+// running it again is harmless. With --fake N it creates N generated users instead (see package
+// seed), e.g. for load tests. Refused in prod, where the fixtures' passwords are public.
+func newSeedCmd() *cobra.Command {
+	var file string
+	var fake seed.Options
+	cmd := &cobra.Command{
+		Use:   "seed",
+		Short: "Create the fixture users, or --fake N generated ones (not in prod)",
+		Args:  usage(cobra.NoArgs),
+		RunE: func(*cobra.Command, []string) error {
+			var fixtures []seedUser
+			if fake.Count == 0 {
+				var err error
+				if fixtures, err = readFixtures(file); err != nil {
+					return err
+				}
+			}
+			logger.Init()
+			cfg := config.Load()
+			if cfg.Env == "prod" {
+				return errors.New("refused in prod: the fixture passwords are in the repository")
+			}
+			if fake.Count != 0 {
+				res, err := seed.Run(context.Background(), config.InitDB(cfg), fake)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("seeded %d fake users (%d already there), seed %d, e.g. %s\n", res.Created, res.Skipped, res.Seed, res.Example)
+				return nil
+			}
+			rdb := config.InitRedis(cfg)
+			defer rdb.Close()
+			return seedUsers(context.Background(), newAccountsService(cfg, config.InitDB(cfg), rdb), fixtures)
+		},
+	}
+	cmd.Flags().StringVar(&file, "file", "fixtures/users.json", "JSON array of {name, email, password, role}")
+	cmd.Flags().IntVar(&fake.Count, "fake", 0, fmt.Sprintf("create this many generated users instead (at most %d)", seed.MaxCount))
+	cmd.Flags().Int64Var(&fake.Seed, "seed", 1, "--fake: which users (the same seed gives the same users; 0 = random)")
+	cmd.Flags().StringVar(&fake.Password, "password", seed.DefaultPassword, "--fake: the users' password")
+	cmd.Flags().StringVar(&fake.Role, "role", models.RoleUser, "--fake: the users' role")
+	return cmd
+}
+
+// readFixtures reads a fixtures file and checks its roles.
+func readFixtures(file string) ([]seedUser, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var fixtures []seedUser
+	if err := json.Unmarshal(data, &fixtures); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	for i, f := range fixtures {
+		if f.Role != "" && !policy.ValidRole(f.Role) {
+			return nil, fmt.Errorf("%s: entry %d: unknown role %q", file, i+1, f.Role)
+		}
+	}
+	return fixtures, nil
+}
+
+// seedUsers imports the fixtures (the same rules as POST /users/import) and then gives the
+// created users their roles. Skipped entries are listed; only other failures are errors.
+func seedUsers(ctx context.Context, svc services.UserAdminService, fixtures []seedUser) error {
+	rows := make([]models.RegisterRequest, len(fixtures))
+	roles := map[string]string{} // canonical email -> role
+	for i, f := range fixtures {
+		rows[i] = f.RegisterRequest
+		if email, err := core.ParseEmail(f.Email); err == nil && f.Role != "" && f.Role != models.RoleUser {
+			roles[email.String()] = f.Role
+		}
+	}
+	res, err := svc.ImportUsers(ctx, rows)
+	if err != nil {
+		return err
+	}
+	for _, e := range res.Errors {
+		fmt.Printf("skipped %s: %s\n", e.Email, e.Error)
+	}
+	for _, u := range res.Users {
+		role, ok := roles[u.Email]
+		if !ok {
+			continue
+		}
+		if _, err := svc.UpdateUser(ctx, core.UserID(u.ID), models.UpdateUserRequest{Role: &role}); err != nil {
+			return fmt.Errorf("%s: set role %s: %w", u.Email, role, err)
+		}
+	}
+	fmt.Printf("seeded %d of %d users\n", res.Created, res.Total)
+	return nil
+}
+
+// newCreateAdminCmd is `server create-admin --email E --password P [--name N]`: it bootstraps
+// an admin account, e.g. on a fresh production database (an alternative to /setup). The
+// password may come from $ADMIN_PASSWORD instead of the command line.
+func newCreateAdminCmd() *cobra.Command {
+	var name, email, password string
+	cmd := &cobra.Command{
+		Use:   "create-admin --email EMAIL [--password PASSWORD] [--name NAME]",
+		Short: "Create an admin account",
+		Args:  usage(cobra.NoArgs),
+		RunE: func(*cobra.Command, []string) error {
+			if password == "" {
+				password = os.Getenv(adminPasswordEnv)
+			}
+			if strings.TrimSpace(email) == "" || password == "" {
+				return usageError{fmt.Errorf("--email and --password (or $%s) are required", adminPasswordEnv)}
+			}
+			logger.Init()
+			cfg := config.Load()
+			rdb := config.InitRedis(cfg)
+			defer rdb.Close()
+			svc := newAccountsService(cfg, config.InitDB(cfg), rdb)
+
+			ctx := context.Background()
+			u, err := svc.CreateUser(ctx, models.RegisterRequest{Name: name, Email: email, Password: password})
+			if err != nil {
+				return err
+			}
+			role := models.RoleAdmin
+			if _, err := svc.UpdateUser(ctx, core.UserID(u.ID), models.UpdateUserRequest{Role: &role}); err != nil {
+				return fmt.Errorf("created user %d but could not make it admin: %w", u.ID, err)
+			}
+			fmt.Printf("created admin %d (%s)\n", u.ID, u.Email)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&email, "email", "", "the admin's email")
+	cmd.Flags().StringVar(&password, "password", "", "the admin's password (default $"+adminPasswordEnv+")")
+	cmd.Flags().StringVar(&name, "name", "Site Admin", "the admin's name (\"Admin\" and the like are reserved)")
+	return cmd
+}
@@ -0,0 +1,147 @@
+// Package seed fills a development or load-test database with fake users: `server seed --fake N`
+// and POST /admin/seed (dev env only). A seed number picks the users: the same seed gives the same
+// names and emails every time, so load-test scripts can log in as them, and running it again only
+// adds what is missing. Seed 0 picks a random one (different users each run).
+//
+// Users are inserted directly, in batches, with one shared password hash: seeding thousands of
+// accounts through registration would spend most of its time in bcrypt.
+package seed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"HelmyTask/models"
+	"HelmyTask/policy"
+	"HelmyTask/utils"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// MaxCount caps the users one Run creates.
+	MaxCount = 100000
+	// DefaultPassword is every generated user's password unless Options.Password says otherwise.
+	DefaultPassword = "Seed-Fake-User-2026"
+	// Domain is the email domain of generated users (under a per-seed subdomain).
+	Domain = "seed.example.com"
+)
+
+// batch is how many users are checked for and inserted per round.
+const batch = 500
+
+// ErrCount is returned by Run for a count outside 1..MaxCount.
+var ErrCount = fmt.Errorf("seed: count must be 1..%d", MaxCount)
+
+// ErrRole is returned by Run for an unknown role.
+var ErrRole = errors.New("seed: unknown role")
+
+// Options selects the users Run creates.
+type Options struct {
+	Count    int    `json:"count"`
+	Seed     int64  `json:"seed"`     // same seed, same users; 0 = random
+	Password string `json:"password"` // "" = DefaultPassword
+	Role     string `json:"role"`     // "" = user
+}
+
+// Result is what a Run did.
+type Result struct {
+	Seed    int64  `json:"seed"`    // the seed used (the random one, if Options.Seed was 0)
+	Created int    `json:"created"` // users inserted
+	Skipped int    `json:"skipped"` // already registered, e.g. by an earlier run with the same seed
+	Example string `json:"example"` // the first generated email
+}
+
+var firstNames = []string{
+	"Ada", "Alan", "Amara", "Bruno", "Chen", "Clara", "Dmitri", "Elena", "Farah", "Gustav",
+	"Hana", "Ibrahim", "Ines", "Jonas", "Kofi", "Lena", "Mateo", "Nadia", "Omar", "Priya",
+	"Quentin", "Rosa", "Sami", "Tariq", "Uma", "Viktor", "Wen", "Yara", "Youssef", "Zoe",
+}
+
+var lastNames = []string{
+	"Abbott", "Bakker", "Costa", "Dubois", "Eriksen", "Fischer", "Garcia", "Haddad", "Ivanova", "Jensen",
+	"Kim", "Lambert", "Mansour", "Novak", "Okafor", "Petrov", "Quinn", "Rossi", "Santos", "Tanaka",
+	"Ueda", "Varga", "Walsh", "Xu", "Yilmaz", "Zhang", "Nakamura", "Moreau", "Larsen", "Hassan",
+}
+
+// Users generates n users for seed (no password; Run sets it): the same seed always gives the
+// same users, in the same order. Emails are unique within a seed.
+func Users(seed int64, n int) []models.User {
+	rnd := rand.New(rand.NewSource(seed))
+	out := make([]models.User, n)
+	for i := range out {
+		first, last := firstNames[rnd.Intn(len(firstNames))], lastNames[rnd.Intn(len(lastNames))]
+		out[i] = models.User{
+			Name:   first + " " + last,
+			Email:  fmt.Sprintf("%s.%s.%d@s%x.%s", strings.ToLower(first), strings.ToLower(last), i+1, uint64(seed), Domain),
+			Role:   models.RoleUser,
+			Status: models.StatusActive,
+		}
+	}
+	return out
+}
+
+// Run inserts o.Count users for o.Seed, skipping those already registered.
+func Run(ctx context.Context, db *gorm.DB, o Options) (Result, error) {
+	if o.Count < 1 || o.Count > MaxCount {
+		return Result{}, ErrCount
+	}
+	if o.Role == "" {
+		o.Role = models.RoleUser
+	}
+	if !policy.ValidRole(o.Role) {
+		return Result{}, ErrRole
+	}
+	if o.Password == "" {
+		o.Password = DefaultPassword
+	}
+	for o.Seed == 0 {
+		o.Seed = rand.New(rand.NewSource(time.Now().UnixNano())).Int63()
+	}
+	hash, err := utils.HashPassword(o.Password)
+	if err != nil {
+		return Result{}, err
+	}
+
+	users := Users(o.Seed, o.Count)
+	res := Result{Seed: o.Seed, Example: users[0].Email}
+	db = db.WithContext(ctx)
+	for start := 0; start < len(users); start += batch {
+		end := start + batch
+		if end > len(users) {
+			end = len(users)
+		}
+		chunk := users[start:end]
+		emails := make([]string, len(chunk))
+		for i, u := range chunk {
+			emails[i] = u.Email
+		}
+		var taken []string
+		if err := db.Model(&models.User{}).Where("email IN ?", emails).Pluck("email", &taken).Error; err != nil {
+			return res, err
+		}
+		skip := make(map[string]bool, len(taken))
+		for _, e := range taken {
+			skip[e] = true
+		}
+		fresh := make([]models.User, 0, len(chunk))
+		for _, u := range chunk {
+			if !skip[u.Email] {
+				u.Password, u.Role = hash, o.Role
+				fresh = append(fresh, u)
+			}
+		}
+		if len(fresh) > 0 {
+			if err := db.CreateInBatches(&fresh, 100).Error; err != nil {
+				return res, err
+			}
+		}
+		res.Created += len(fresh)
+		res.Skipped += len(chunk) - len(fresh)
+	}
+	return res, nil
+}
@@ -0,0 +1,33 @@
+package seed
+
+import (
+	"context"
+	"testing"
+
+	"HelmyTask/core"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUsers_SameSeedSameUsers(t *testing.T) {
+	a, b := Users(42, 50), Users(42, 50)
+	assert.Equal(t, a, b)
+	assert.NotEqual(t, a[0].Email, Users(43, 1)[0].Email)
+
+	seen := map[string]bool{}
+	for _, u := range a {
+		assert.Empty(t, core.ValidateEmail(u.Email), u.Email)
+		assert.Empty(t, core.ValidateName(u.Name), u.Name)
+		assert.False(t, seen[u.Email], "duplicate %s", u.Email)
+		seen[u.Email] = true
+	}
+}
+
+func TestRun_RejectsBadOptions(t *testing.T) {
+	_, err := Run(context.Background(), nil, Options{Count: 0})
+	assert.ErrorIs(t, err, ErrCount)
+	_, err = Run(context.Background(), nil, Options{Count: MaxCount + 1})
+	assert.ErrorIs(t, err, ErrCount)
+	_, err = Run(context.Background(), nil, Options{Count: 1, Role: "owner"})
+	assert.ErrorIs(t, err, ErrRole)
+}
@@ -0,0 +1,217 @@
+package services // Use-case layer for API keys.
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"HelmyTask/core"
+	"HelmyTask/models"
+	"HelmyTask/policy"
+	"HelmyTask/repositories"
+	"HelmyTask/utils/redislog"
+)
+
+// apiKeyPrefix marks our keys so they are easy to spot in logs and secret scanners.
+const apiKeyPrefix = "htk_"
+
+// signingSecretPrefix marks request signing secrets of signed keys.
+const signingSecretPrefix = "hks_"
+
+// ErrInvalidAPIKey is returned for unknown, revoked, or malformed keys.
+var ErrInvalidAPIKey = errors.New("invalid api key")
+
+// ErrAPIKeyNotFound is returned when the key isn't the caller's, or is revoked.
+var ErrAPIKeyNotFound = errors.New("api key not found")
+
+// ErrAPIKeyScope is returned when asking for a scope that isn't a permission the owner holds.
+var ErrAPIKeyScope = errors.New("invalid api key scope")
+
+// APIKeyService issues, lists, revokes and authenticates API keys.
+type APIKeyService interface {
+	Issue(userID core.UserID, req models.CreateAPIKeyRequest) (*models.APIKeyCreated, error) // Plaintext (and signing secret) returned once.
+	List(userID core.UserID) ([]models.APIKey, error)
+	Rename(userID core.UserID, keyID uint, name string) (*models.APIKey, error)
+	Rotate(userID core.UserID, keyID uint) (*models.APIKeyCreated, error) // Same name, scopes and signing; the old key stops working.
+	Revoke(userID core.UserID, keyID uint) error
+	Authenticate(ctx context.Context, key string) (*models.APIKeyIdentity, error) // The owner's ID and role, and the key's signing secret.
+}
+
+type apiKeyService struct {
+	keys  repositories.APIKeyRepository
+	users repositories.UserRepository // Owner lookup (role for the policy engine).
+	log   *redislog.Logger
+}
+
+// NewAPIKeyService wires the API key use-cases.
+func NewAPIKeyService(keys repositories.APIKeyRepository, users repositories.UserRepository, rlog *redislog.Logger) APIKeyService {
+	return &apiKeyService{keys: keys, users: users, log: rlog}
+}
+
+// hashAPIKey is the at-rest form of a key; keys are high-entropy so a plain SHA-256 is enough.
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Issue creates a new random key for the user; a signed key also gets a signing secret.
+func (s *apiKeyService) Issue(userID core.UserID, req models.CreateAPIKeyRequest) (*models.APIKeyCreated, error) {
+	scopes, err := s.checkScopes(userID, req.Scopes)
+	if err != nil {
+		return nil, err
+	}
+	k, created, err := newAPIKey(userID, req.Name, req.Signed, scopes)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.keys.Create(k); err != nil {
+		if s.log != nil { s.log.Error("api key create error", map[string]string{"user_id": fmt.Sprint(userID), "err": err.Error()}) }
+		return nil, err
+	}
+	if s.log != nil { s.log.Info("api key issued", map[string]string{"user_id": fmt.Sprint(userID), "key_id": fmt.Sprint(k.ID), "signed": fmt.Sprint(req.Signed), "scopes": scopes}) }
+	created.APIKey = *k
+	return created, nil
+}
+
+// newAPIKey generates a key (and signing secret if signed): the row to store, and the
+// plaintext for the response (its APIKey is filled in once the row is saved).
+func newAPIKey(userID core.UserID, name string, signed bool, scopes string) (*models.APIKey, *models.APIKeyCreated, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, nil, err
+	}
+	key := apiKeyPrefix + hex.EncodeToString(buf)
+
+	k := &models.APIKey{
+		UserID:  uint(userID),
+		Name:    name,
+		Prefix:  key[:len(apiKeyPrefix)+6], // e.g. "htk_1a2b3c"
+		KeyHash: hashAPIKey(key),
+		Scopes:  scopes,
+	}
+	if signed {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, nil, err
+		}
+		k.SigningSecret = signingSecretPrefix + hex.EncodeToString(secret) // Kept as-is: the server recomputes every signature (like webhook secrets).
+	}
+	return k, &models.APIKeyCreated{Key: key, SigningSecret: k.SigningSecret}, nil
+}
+
+// checkScopes validates requested scopes against the owner's role and returns them as stored
+// (sorted, space-separated; "" = unrestricted). A key can only narrow what its owner may do.
+func (s *apiKeyService) checkScopes(userID core.UserID, scopes []string) (string, error) {
+	if len(scopes) == 0 {
+		return "", nil
+	}
+	u, err := s.users.FindByID(context.Background(), userID)
+	if err != nil {
+		return "", err
+	}
+	set := map[string]bool{}
+	for _, sc := range scopes {
+		if !policy.Known(policy.Permission(sc)) || !policy.Allowed(roleOrDefault(u.Role), policy.Permission(sc)) {
+			return "", fmt.Errorf("%w: %q", ErrAPIKeyScope, sc)
+		}
+		set[sc] = true
+	}
+	out := make([]string, 0, len(set))
+	for sc := range set {
+		out = append(out, sc)
+	}
+	sort.Strings(out)
+	return strings.Join(out, " "), nil
+}
+
+// List returns the user's keys (metadata only).
+func (s *apiKeyService) List(userID core.UserID) ([]models.APIKey, error) {
+	return s.keys.ListByUser(userID)
+}
+
+// Rename relabels one of the user's active keys.
+func (s *apiKeyService) Rename(userID core.UserID, keyID uint, name string) (*models.APIKey, error) {
+	err := s.keys.Rename(keyID, userID, name)
+	if repositories.IsNotFound(err) {
+		return nil, ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.find(userID, keyID)
+}
+
+// Rotate swaps one of the user's keys for a new one with the same name, scopes and signing
+// (a new secret for signed keys); the old key is revoked at once. The plaintext is returned once.
+func (s *apiKeyService) Rotate(userID core.UserID, keyID uint) (*models.APIKeyCreated, error) {
+	old, err := s.find(userID, keyID)
+	if err != nil {
+		return nil, err
+	}
+	k, created, err := newAPIKey(userID, old.Name, old.SigningSecret != "", old.Scopes)
+	if err != nil {
+		return nil, err
+	}
+	err = s.keys.Rotate(keyID, userID, k)
+	if repositories.IsNotFound(err) {
+		return nil, ErrAPIKeyNotFound // revoked meanwhile
+	}
+	if err != nil {
+		return nil, err
+	}
+	if s.log != nil { s.log.Info("api key rotated", map[string]string{"user_id": fmt.Sprint(userID), "key_id": fmt.Sprint(keyID), "new_key_id": fmt.Sprint(k.ID)}) }
+	created.APIKey = *k
+	return created, nil
+}
+
+// find loads one of the user's active keys.
+func (s *apiKeyService) find(userID core.UserID, keyID uint) (*models.APIKey, error) {
+	k, err := s.keys.FindActiveByID(keyID, userID)
+	if repositories.IsNotFound(err) {
+		return nil, ErrAPIKeyNotFound
+	}
+	return k, err
+}
+
+// Revoke disables one of the user's keys.
+func (s *apiKeyService) Revoke(userID core.UserID, keyID uint) error {
+	if err := s.keys.Revoke(keyID, userID); err != nil {
+		return err
+	}
+	if s.log != nil { s.log.Info("api key revoked", map[string]string{"user_id": fmt.Sprint(userID), "key_id": fmt.Sprint(keyID)}) }
+	return nil
+}
+
+// Authenticate resolves a presented key to its owner. Checking the signature a signed key
+// demands is up to the caller (middlewares.APIKeyAuth), which has the request.
+func (s *apiKeyService) Authenticate(ctx context.Context, key string) (*models.APIKeyIdentity, error) {
+	if len(key) <= len(apiKeyPrefix) || key[:len(apiKeyPrefix)] != apiKeyPrefix {
+		return nil, ErrInvalidAPIKey // Cheap reject before touching the DB.
+	}
+	k, err := s.keys.FindActiveByHash(hashAPIKey(key))
+	if err != nil {
+		if s.log != nil { s.log.Warn("api key rejected", map[string]string{"prefix": keyHint(key)}) }
+		return nil, ErrInvalidAPIKey
+	}
+	u, err := s.users.FindByID(ctx, core.UserID(k.UserID))
+	if err != nil || !u.IsActive() { // Owner deleted, disabled or banned → key is dead too.
+		return nil, ErrInvalidAPIKey
+	}
+	_ = s.keys.TouchLastUsed(k.ID, time.Now()) // Best-effort bookkeeping.
+	return &models.APIKeyIdentity{UserID: u.ID, Role: roleOrDefault(u.Role), SigningSecret: k.SigningSecret, Scopes: k.ScopeList()}, nil
+}
+
+// keyHint is the start of a presented key, enough to tell keys apart in logs without exposing
+// them. Keys can be any length here (they haven't matched), so it never slices past the end.
+func keyHint(key string) string {
+	if n := len(apiKeyPrefix) + 6; len(key) > n {
+		return key[:n]
+	}
+	return key
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"HelmyTask/core"
+	"HelmyTask/mocks"
+	"HelmyTask/models"
+	"HelmyTask/utils/redislog"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestAPIKeyService_IssueThenAuthenticate(t *testing.T) {
+	keys := new(mocks.APIKeyRepositoryMock)
+	users := new(mocks.UserRepositoryMock)
+	svc := NewAPIKeyService(keys, users, nil)
+
+	var stored models.APIKey
+	keys.On("Create", mock.AnythingOfType("*models.APIKey")).Return(nil).Run(func(args mock.Arguments) {
+		k := args.Get(0).(*models.APIKey)
+		k.ID = 3
+		stored = *k
+	})
+
+	created, err := svc.Issue(core.UserID(8), models.CreateAPIKeyRequest{Name: "ci"})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(created.Key, "htk_"))
+	assert.NotEqual(t, created.Key, stored.KeyHash) // only the hash is persisted
+
+	keys.On("FindActiveByHash", stored.KeyHash).Return(&stored, nil)
+	keys.On("TouchLastUsed", uint(3), mock.Anything).Return(nil)
+	users.On("FindByID", core.UserID(8)).Return(&models.User{ID: 8, Role: models.RoleSupport}, nil)
+
+	id, err := svc.Authenticate(context.Background(), created.Key)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(8), id.UserID)
+	assert.Equal(t, models.RoleSupport, id.Role)
+	assert.Empty(t, id.SigningSecret)
+}
+
+func TestAPIKeyService_Authenticate_Rejects(t *testing.T) {
+	keys := new(mocks.APIKeyRepositoryMock)
+	svc := NewAPIKeyService(keys, new(mocks.UserRepositoryMock), redislog.New(nil, "", 0, 0)) // rejections are logged
+
+	_, err := svc.Authenticate(context.Background(), "not-ours")
+	assert.ErrorIs(t, err, ErrInvalidAPIKey)
+
+	keys.On("FindActiveByHash", mock.Anything).Return(nil, errors.New("not found"))
+	_, err = svc.Authenticate(context.Background(), "htk_revoked")
+	assert.ErrorIs(t, err, ErrInvalidAPIKey)
+
+	_, err = svc.Authenticate(context.Background(), "htk_x") // shorter than the logged hint
+	assert.ErrorIs(t, err, ErrInvalidAPIKey)
+}
+
+func TestAPIKeyService_SignedKeyCarriesSecret(t *testing.T) {
+	keys := new(mocks.APIKeyRepositoryMock)
+	users := new(mocks.UserRepositoryMock)
+	svc := NewAPIKeyService(keys, users, nil)
+
+	var stored models.APIKey
+	keys.On("Create", mock.AnythingOfType("*models.APIKey")).Return(nil).Run(func(args mock.Arguments) { stored = *args.Get(0).(*models.APIKey) })
+	created, err := svc.Issue(core.UserID(8), models.CreateAPIKeyRequest{Name: "deploy", Signed: true})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(created.SigningSecret, "hks_"))
+	assert.Equal(t, created.SigningSecret, stored.SigningSecret)
+
+	keys.On("FindActiveByHash", stored.KeyHash).Return(&stored, nil)
+	keys.On("TouchLastUsed", mock.Anything, mock.Anything).Return(nil)
+	users.On("FindByID", core.UserID(8)).Return(&models.User{ID: 8, Role: models.RoleAdmin}, nil)
+	id, err := svc.Authenticate(context.Background(), created.Key)
+	require.NoError(t, err)
+	assert.Equal(t, created.SigningSecret, id.SigningSecret)
+}
+
+func TestAPIKeyService_ScopesNarrowTheOwner(t *testing.T) {
+	keys := new(mocks.APIKeyRepositoryMock)
+	users := new(mocks.UserRepositoryMock)
+	svc := NewAPIKeyService(keys, users, nil)
+	users.On("FindByID", core.UserID(8)).Return(&models.User{ID: 8, Role: models.RoleSupport}, nil)
+
+	_, err := svc.Issue(core.UserID(8), models.CreateAPIKeyRequest{Name: "ci", Scopes: []string{"users:delete"}})
+	assert.ErrorIs(t, err, ErrAPIKeyScope, "support can't delete users, so neither can its key")
+	_, err = svc.Issue(core.UserID(8), models.CreateAPIKeyRequest{Name: "ci", Scopes: []string{"users:everything"}})
+	assert.ErrorIs(t, err, ErrAPIKeyScope)
+	keys.AssertNotCalled(t, "Create", mock.Anything)
+
+	var stored models.APIKey
+	keys.On("Create", mock.AnythingOfType("*models.APIKey")).Return(nil).Run(func(args mock.Arguments) { stored = *args.Get(0).(*models.APIKey) })
+	created, err := svc.Issue(core.UserID(8), models.CreateAPIKeyRequest{Name: "ci", Scopes: []string{"users:read", "audit:read", "users:read"}})
+	require.NoError(t, err)
+	assert.Equal(t, "audit:read users:read", stored.Scopes)
+
+	keys.On("FindActiveByHash", stored.KeyHash).Return(&stored, nil)
+	keys.On("TouchLastUsed", mock.Anything, mock.Anything).Return(nil)
+	id, err := svc.Authenticate(context.Background(), created.Key)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"audit:read", "users:read"}, id.Scopes)
+}
+
+func TestAPIKeyService_RotateKeepsSettings(t *testing.T) {
+	keys := new(mocks.APIKeyRepositoryMock)
+	svc := NewAPIKeyService(keys, new(mocks.UserRepositoryMock), nil)
+	old := &models.APIKey{ID: 3, UserID: 8, Name: "deploy", Prefix: "htk_aaaaaa", KeyHash: "old", SigningSecret: "hks_old", Scopes: "users:read"}
+	keys.On("FindActiveByID", uint(3), core.UserID(8)).Return(old, nil)
+	keys.On("FindActiveByID", uint(4), core.UserID(8)).Return(nil, gorm.ErrRecordNotFound)
+
+	var next models.APIKey
+	keys.On("Rotate", uint(3), core.UserID(8), mock.AnythingOfType("*models.APIKey")).Return(nil).Run(func(args mock.Arguments) {
+		k := args.Get(2).(*models.APIKey)
+		k.ID = 9
+		next = *k
+	})
+	created, err := svc.Rotate(core.UserID(8), 3)
+	require.NoError(t, err)
+	assert.Equal(t, uint(9), created.ID)
+	assert.Equal(t, "deploy", next.Name)
+	assert.Equal(t, "users:read", next.Scopes)
+	assert.NotEqual(t, old.KeyHash, next.KeyHash)
+	assert.True(t, strings.HasPrefix(created.SigningSecret, "hks_"), "signed keys stay signed")
+	assert.NotEqual(t, old.SigningSecret, created.SigningSecret)
+
+	_, err = svc.Rotate(core.UserID(8), 4)
+	assert.ErrorIs(t, err, ErrAPIKeyNotFound)
+}
+
+func TestAPIKeyService_Rename(t *testing.T) {
+	keys := new(mocks.APIKeyRepositoryMock)
+	svc := NewAPIKeyService(keys, new(mocks.UserRepositoryMock), nil)
+	keys.On("Rename", uint(3), core.UserID(8), "prod deploy").Return(nil)
+	keys.On("FindActiveByID", uint(3), core.UserID(8)).Return(&models.APIKey{ID: 3, Name: "prod deploy"}, nil)
+	keys.On("Rename", uint(4), core.UserID(8), "x").Return(gorm.ErrRecordNotFound)
+
+	k, err := svc.Rename(core.UserID(8), 3, "prod deploy")
+	require.NoError(t, err)
+	assert.Equal(t, "prod deploy", k.Name)
+	_, err = svc.Rename(core.UserID(8), 4, "x")
+	assert.ErrorIs(t, err, ErrAPIKeyNotFound)
+}
@@ -0,0 +1,298 @@
+package services // Account deletion requests: user asks, an admin approves or rejects, approved accounts are erased.
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"HelmyTask/audit"
+	"HelmyTask/core"
+	"HelmyTask/jobs"
+	"HelmyTask/models"
+	"HelmyTask/repositories"
+	"HelmyTask/utils/redislog"
+)
+
+// Errors handlers map to specific HTTP responses.
+var (
+	ErrDeletionNotFound   = errors.New("deletion request not found")
+	ErrDeletionOpen       = errors.New("a deletion request is already open")
+	ErrDeletionDecided    = errors.New("deletion request was already decided")
+	ErrDeletionOverdue    = errors.New("deletion request review window has passed")
+	ErrDeletionSelfReview = errors.New("a deletion request must be reviewed by another admin")
+)
+
+// JobEraseAccount is the job type that runs the erasure of an approved request.
+const JobEraseAccount = "account.erase"
+
+type eraseAccountJob struct {
+	RequestID uint `json:"request_id"`
+}
+
+// maxExpireBatch caps one expiry round; the next round takes the rest.
+const maxExpireBatch = 500
+
+// DeletionService runs the account deletion workflow for deployments where closing an account
+// needs an admin's sign-off. Every step is in the audit trail.
+type DeletionService interface {
+	Request(ctx context.Context, uid core.UserID, req models.CreateDeletionRequest, ip string) (*models.DeletionRequest, error) // ErrDeletionOpen if one is pending/approved.
+	Mine(uid core.UserID) (*models.DeletionRequest, error) // The user's latest request; ErrDeletionNotFound if none.
+	List(status string, page, limit int) (*models.PagedDeletionRequests, error) // Review queue, oldest first.
+	Approve(ctx context.Context, id uint, by core.UserID, note, ip string) (*models.DeletionRequest, error) // Then erases the account (job queue).
+	Reject(ctx context.Context, id uint, by core.UserID, note, ip string) (*models.DeletionRequest, error)
+	ExpireOverdue(now time.Time) (int, error) // Pending requests past their window become expired.
+	ExpireEvery(ctx context.Context, every time.Duration)
+	PurgeFinished(olderThan time.Duration) (int64, error) // Deletes closed requests (scheduler job purge_deletion_requests).
+}
+
+type deletionService struct {
+	repo   repositories.DeletionRequestRepository
+	users  UserAdminService // logins, account row, cache and lifecycle hooks go through the user service
+	audit  *audit.Recorder
+	jobs   jobs.Enqueuer // nil = erase inline
+	window time.Duration // time admins have to decide
+	log    *redislog.Logger
+	now    func() time.Time
+}
+
+// NewDeletionService wires the deletion workflow. With a queue, approved requests are erased by
+// JobEraseAccount (retried on failure); without one, during the approving request.
+func NewDeletionService(repo repositories.DeletionRequestRepository, users UserAdminService, q *jobs.Queue, rec *audit.Recorder, window time.Duration, rlog *redislog.Logger) DeletionService {
+	s := &deletionService{repo: repo, users: users, audit: rec, window: window, log: rlog, now: time.Now}
+	if q != nil { // not a typed nil
+		q.Handle(JobEraseAccount, s.eraseJob)
+		s.jobs = q
+	}
+	return s
+}
+
+// Request files a deletion request for uid, due for review within the window.
+func (s *deletionService) Request(ctx context.Context, uid core.UserID, req models.CreateDeletionRequest, ip string) (*models.DeletionRequest, error) {
+	if _, err := s.users.GetByID(ctx, uid); err != nil { // deleted while the token is still valid
+		return nil, err
+	}
+	last, err := s.repo.LatestForUser(uint(uid))
+	if err != nil && !repositories.IsNotFound(err) {
+		return nil, err
+	}
+	if last != nil && last.Open() {
+		return last, ErrDeletionOpen
+	}
+	d := models.DeletionRequest{UserID: uint(uid), Reason: req.Reason, Status: models.DeletionPending, DueAt: s.now().Add(s.window)}
+	if err := s.repo.Create(&d); err != nil {
+		if s.log != nil { s.log.Error("deletion request db error", map[string]string{"user_id": fmt.Sprint(uid), "err": err.Error()}) }
+		return nil, err
+	}
+	s.audit.Record(audit.Event{ActorID: uint(uid), Action: audit.ActionDeletionRequest, TargetType: "deletion_request", TargetID: d.ID, After: d, IP: ip, Reason: req.Reason})
+	if s.log != nil { s.log.Info("deletion requested", map[string]string{"id": fmt.Sprint(d.ID), "user_id": fmt.Sprint(uid), "due_at": d.DueAt.UTC().Format(time.RFC3339)}) }
+	return &d, nil
+}
+
+// Mine returns the user's latest request, whatever its status.
+func (s *deletionService) Mine(uid core.UserID) (*models.DeletionRequest, error) {
+	d, err := s.repo.LatestForUser(uint(uid))
+	if repositories.IsNotFound(err) {
+		return nil, ErrDeletionNotFound
+	}
+	return d, err
+}
+
+// List pages through requests (status "" = all).
+func (s *deletionService) List(status string, page, limit int) (*models.PagedDeletionRequests, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	items, total, err := s.repo.List(status, (page-1)*limit, limit)
+	if err != nil {
+		return nil, err
+	}
+	return &models.PagedDeletionRequests{Items: items, Total: total, Page: page, Limit: limit}, nil
+}
+
+// Approve accepts a pending request and starts the erasure.
+func (s *deletionService) Approve(ctx context.Context, id uint, by core.UserID, note, ip string) (*models.DeletionRequest, error) {
+	d, err := s.decide(id, by, models.DeletionApproved, note, ip, audit.ActionDeletionApprove)
+	if err != nil {
+		return nil, err
+	}
+	s.startErasure(ctx, d)
+	return d, nil
+}
+
+// Reject turns a pending request down; the note tells the user why.
+func (s *deletionService) Reject(_ context.Context, id uint, by core.UserID, note, ip string) (*models.DeletionRequest, error) {
+	return s.decide(id, by, models.DeletionRejected, note, ip, audit.ActionDeletionReject)
+}
+
+// decide moves a pending request to status on behalf of admin by. Admins can't decide their own
+// request, and an overdue one is left for the expiry sweep.
+func (s *deletionService) decide(id uint, by core.UserID, status, note, ip, action string) (*models.DeletionRequest, error) {
+	d, err := s.repo.FindByID(id)
+	if repositories.IsNotFound(err) {
+		return nil, ErrDeletionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	switch {
+	case d.Status != models.DeletionPending:
+		return d, ErrDeletionDecided
+	case d.UserID == uint(by):
+		return nil, ErrDeletionSelfReview
+	case !now.Before(d.DueAt):
+		return d, ErrDeletionOverdue
+	}
+	before := *d
+	d.Status, d.ReviewerID, d.ReviewNote, d.DecidedAt = status, uint(by), note, &now
+	ok, err := s.repo.Decide(d)
+	if err != nil {
+		return nil, err
+	}
+	if !ok { // another admin (or the sweep) got there first
+		return nil, ErrDeletionDecided
+	}
+	s.audit.Record(audit.Event{ActorID: uint(by), Action: action, TargetType: "deletion_request", TargetID: d.ID, Before: before, After: d, IP: ip, Reason: note})
+	if s.log != nil { s.log.Info("deletion request "+status, map[string]string{"id": fmt.Sprint(d.ID), "user_id": fmt.Sprint(d.UserID), "by": fmt.Sprint(by)}) }
+	return d, nil
+}
+
+// startErasure queues the erasure, or runs it now without a queue (or when Redis refuses the
+// job); an inline failure is kept on the request as LastError.
+func (s *deletionService) startErasure(ctx context.Context, d *models.DeletionRequest) {
+	if s.jobs != nil {
+		err := s.jobs.Enqueue(ctx, JobEraseAccount, eraseAccountJob{RequestID: d.ID})
+		if err == nil {
+			return
+		}
+		if s.log != nil { s.log.Warn("erasure enqueue failed, erasing inline", map[string]string{"id": fmt.Sprint(d.ID), "err": err.Error()}) }
+	}
+	_ = s.erase(context.WithoutCancel(ctx), d.ID) // logged and recorded by erase
+}
+
+// eraseJob handles JobEraseAccount.
+func (s *deletionService) eraseJob(ctx context.Context, payload json.RawMessage) error {
+	var job eraseAccountJob
+	if err := json.Unmarshal(payload, &job); err != nil {
+		return jobs.Permanent(err)
+	}
+	return s.erase(ctx, job.RequestID)
+}
+
+// erasure is what the user.erase audit entry records: counts and flags, never the erased data.
+type erasure struct {
+	RequestID uint     `json:"request_id"`
+	Revoked   []string `json:"revoked,omitempty"` // cache, tokens, sessions, lockout, pending_2fa
+	models.ErasureCounts
+	AccountDeleted bool `json:"account_deleted"`
+}
+
+// erase carries out an approved request: logins revoked, related rows erased, the account
+// deleted, then the request completed. Every step is safe to repeat, so a failed attempt is
+// simply retried; a request that isn't approved (already completed) is left alone.
+func (s *deletionService) erase(ctx context.Context, id uint) error {
+	d, err := s.repo.FindByID(id)
+	if repositories.IsNotFound(err) {
+		return jobs.Permanent(ErrDeletionNotFound)
+	}
+	if err != nil {
+		return err
+	}
+	if d.Status != models.DeletionApproved {
+		return nil
+	}
+	done, err := s.eraseAccount(ctx, d)
+	if err != nil {
+		if s.log != nil { s.log.Error("erasure failed", map[string]string{"id": fmt.Sprint(d.ID), "user_id": fmt.Sprint(d.UserID), "err": err.Error()}) }
+		d.LastError = truncate(err.Error(), 500)
+		_ = s.repo.Update(d) // best-effort: the retry matters more than the note
+		return err
+	}
+	now := s.now()
+	d.Status, d.CompletedAt, d.LastError = models.DeletionCompleted, &now, ""
+	if err := s.repo.Update(d); err != nil {
+		return err // the retry finds the account gone and only completes the request
+	}
+	s.audit.Record(audit.Event{ActorID: d.ReviewerID, Action: audit.ActionUserErase, TargetType: "user", TargetID: d.UserID, After: done, Reason: fmt.Sprintf("deletion request %d", d.ID)})
+	if s.log != nil { s.log.Info("account erased", map[string]string{"id": fmt.Sprint(d.ID), "user_id": fmt.Sprint(d.UserID)}) }
+	return nil
+}
+
+// eraseAccount runs the erasure steps in order. A missing user means an earlier attempt already
+// got through the last step.
+func (s *deletionService) eraseAccount(ctx context.Context, d *models.DeletionRequest) (erasure, error) {
+	done := erasure{RequestID: d.ID}
+	uid := core.UserID(d.UserID)
+	u, err := s.users.GetByID(ctx, uid)
+	if repositories.IsNotFound(err) {
+		done.AccountDeleted = true
+		return done, nil
+	}
+	if err != nil {
+		return done, err
+	}
+	if done.Revoked, err = s.users.FlushUser(ctx, uid); err != nil {
+		return done, fmt.Errorf("revoke logins: %w", err)
+	}
+	if done.ErasureCounts, err = s.repo.ErasePersonalData(d.UserID, u.Email); err != nil {
+		return done, fmt.Errorf("erase related data: %w", err)
+	}
+	if err := s.users.DeleteUser(ctx, uid); err != nil && !repositories.IsNotFound(err) {
+		return done, fmt.Errorf("delete account: %w", err)
+	}
+	done.AccountDeleted = true
+	return done, nil
+}
+
+// ExpireOverdue marks pending requests past their window expired. Each is logged as a warning:
+// in a regulated deployment a missed deadline is something to follow up.
+func (s *deletionService) ExpireOverdue(now time.Time) (int, error) {
+	due, err := s.repo.FindOverdue(now, maxExpireBatch)
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for i := range due {
+		d := &due[i]
+		before := *d
+		d.Status, d.DecidedAt = models.DeletionExpired, &now
+		ok, err := s.repo.Decide(d)
+		if err != nil {
+			return n, err
+		}
+		if !ok { // decided just now by an admin
+			continue
+		}
+		n++
+		s.audit.Record(audit.Event{Action: audit.ActionDeletionExpire, TargetType: "deletion_request", TargetID: d.ID, Before: before, After: d})
+		if s.log != nil { s.log.Warn("deletion request expired undecided", map[string]string{"id": fmt.Sprint(d.ID), "user_id": fmt.Sprint(d.UserID)}) }
+	}
+	return n, nil
+}
+
+// ExpireEvery runs ExpireOverdue every interval until ctx is done; errors are logged.
+func (s *deletionService) ExpireEvery(ctx context.Context, every time.Duration) {
+	t := time.NewTicker(every)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-t.C:
+			if _, err := s.ExpireOverdue(now); err != nil && s.log != nil {
+				s.log.Error("deletion expiry error", map[string]string{"err": err.Error()})
+			}
+		}
+	}
+}
+
+// PurgeFinished deletes rejected, expired and completed requests closed more than olderThan
+// ago. Their decisions stay in the audit trail.
+func (s *deletionService) PurgeFinished(olderThan time.Duration) (int64, error) {
+	return s.repo.PurgeFinished(s.now().Add(-olderThan))
+}
@@ -0,0 +1,139 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"HelmyTask/audit"
+	"HelmyTask/core"
+	"HelmyTask/mocks"
+	"HelmyTask/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func newDeletionTestService(now time.Time) (*deletionService, *mocks.DeletionRequestRepositoryMock, *mocks.UserAdminServiceMock, *[]models.AuditLog) {
+	repo, users, auditRepo := new(mocks.DeletionRequestRepositoryMock), new(mocks.UserAdminServiceMock), new(mocks.AuditRepositoryMock)
+	var entries []models.AuditLog
+	auditRepo.On("Create", mock.AnythingOfType("*models.AuditLog")).Run(func(args mock.Arguments) {
+		entries = append(entries, *args.Get(0).(*models.AuditLog))
+	}).Return(nil)
+	svc := &deletionService{repo: repo, users: users, audit: audit.New(auditRepo, nil), window: 72 * time.Hour, now: func() time.Time { return now }}
+	return svc, repo, users, &entries
+}
+
+func TestDeletionService_RequestOnePerUser(t *testing.T) {
+	now := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+	svc, repo, users, entries := newDeletionTestService(now)
+	users.On("GetByID", core.UserID(7)).Return(&models.User{ID: 7}, nil)
+
+	repo.On("LatestForUser", uint(7)).Return(nil, gorm.ErrRecordNotFound).Once()
+	repo.On("Create", mock.AnythingOfType("*models.DeletionRequest")).Run(func(args mock.Arguments) {
+		args.Get(0).(*models.DeletionRequest).ID = 1
+	}).Return(nil)
+	d, err := svc.Request(context.Background(), 7, models.CreateDeletionRequest{Reason: "moving on"}, "10.0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, models.DeletionPending, d.Status)
+	assert.Equal(t, now.Add(72*time.Hour), d.DueAt)
+	require.Len(t, *entries, 1)
+	assert.Equal(t, audit.ActionDeletionRequest, (*entries)[0].Action)
+	assert.Equal(t, uint(7), (*entries)[0].ActorID)
+
+	repo.On("LatestForUser", uint(7)).Return(d, nil)
+	_, err = svc.Request(context.Background(), 7, models.CreateDeletionRequest{}, "")
+	assert.ErrorIs(t, err, ErrDeletionOpen)
+	repo.AssertNumberOfCalls(t, "Create", 1)
+}
+
+func TestDeletionService_ReviewRules(t *testing.T) {
+	now := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+	svc, repo, _, _ := newDeletionTestService(now)
+	pending := func(id uint, due time.Time) *models.DeletionRequest {
+		return &models.DeletionRequest{ID: id, UserID: 7, Status: models.DeletionPending, DueAt: due}
+	}
+	repo.On("FindByID", uint(1)).Return(pending(1, now.Add(time.Hour)), nil)
+	repo.On("FindByID", uint(2)).Return(pending(2, now.Add(-time.Second)), nil)
+	repo.On("FindByID", uint(3)).Return(&models.DeletionRequest{ID: 3, UserID: 7, Status: models.DeletionRejected}, nil)
+	repo.On("FindByID", uint(4)).Return(nil, gorm.ErrRecordNotFound)
+
+	_, err := svc.Approve(context.Background(), 1, 7, "", "")
+	assert.ErrorIs(t, err, ErrDeletionSelfReview, "not one's own request")
+	_, err = svc.Reject(context.Background(), 2, 1, "no", "")
+	assert.ErrorIs(t, err, ErrDeletionOverdue)
+	_, err = svc.Approve(context.Background(), 3, 1, "", "")
+	assert.ErrorIs(t, err, ErrDeletionDecided)
+	_, err = svc.Approve(context.Background(), 4, 1, "", "")
+	assert.ErrorIs(t, err, ErrDeletionNotFound)
+
+	repo.On("Decide", mock.AnythingOfType("*models.DeletionRequest")).Return(false, nil) // another admin was first
+	_, err = svc.Reject(context.Background(), 1, 1, "no", "")
+	assert.ErrorIs(t, err, ErrDeletionDecided)
+}
+
+func TestDeletionService_ApproveErasesAccount(t *testing.T) {
+	now := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+	svc, repo, users, entries := newDeletionTestService(now) // no queue: erased inline
+	d := &models.DeletionRequest{ID: 5, UserID: 7, Status: models.DeletionPending, DueAt: now.Add(time.Hour)}
+	repo.On("FindByID", uint(5)).Return(d, nil)
+	repo.On("Decide", d).Return(true, nil)
+	users.On("GetByID", core.UserID(7)).Return(&models.User{ID: 7, Email: "gone@b.c"}, nil)
+	users.On("FlushUser", core.UserID(7)).Return([]string{"cache", "tokens"}, nil)
+	repo.On("ErasePersonalData", uint(7), "gone@b.c").Return(models.ErasureCounts{APIKeys: 2, EmailDeliveries: 3, AuditLogs: 4}, nil)
+	users.On("DeleteUser", core.UserID(7)).Return(nil)
+	repo.On("Update", d).Return(nil)
+
+	got, err := svc.Approve(context.Background(), 5, 1, "verified by phone", "10.0.0.2")
+	require.NoError(t, err)
+	assert.Equal(t, uint(1), got.ReviewerID)
+	assert.Equal(t, models.DeletionCompleted, d.Status)
+	require.NotNil(t, d.CompletedAt)
+
+	require.Len(t, *entries, 2)
+	assert.Equal(t, audit.ActionDeletionApprove, (*entries)[0].Action)
+	assert.Equal(t, "verified by phone", (*entries)[0].Reason)
+	erase := (*entries)[1]
+	assert.Equal(t, audit.ActionUserErase, erase.Action)
+	assert.Equal(t, uint(1), erase.ActorID, "on the approver's authority")
+	assert.Contains(t, erase.Diff, `"api_keys_deleted":{"to":2}`)
+	assert.Contains(t, erase.Diff, `"audit_logs_pseudonymized":{"to":4}`)
+	assert.NotContains(t, erase.Diff, "gone@b.c", "the audit entry never holds the erased data")
+	users.AssertExpectations(t)
+}
+
+func TestDeletionService_EraseRetryAfterAccountGone(t *testing.T) {
+	now := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+	svc, repo, users, _ := newDeletionTestService(now)
+	d := &models.DeletionRequest{ID: 5, UserID: 7, Status: models.DeletionApproved, ReviewerID: 1}
+	repo.On("FindByID", uint(5)).Return(d, nil)
+	users.On("GetByID", core.UserID(7)).Return(nil, gorm.ErrRecordNotFound)
+	repo.On("Update", d).Return(nil)
+
+	require.NoError(t, svc.erase(context.Background(), 5))
+	assert.Equal(t, models.DeletionCompleted, d.Status)
+	users.AssertNotCalled(t, "DeleteUser", mock.Anything)
+
+	require.NoError(t, svc.erase(context.Background(), 5), "completed: nothing left to do")
+	repo.AssertNumberOfCalls(t, "Update", 1)
+}
+
+func TestDeletionService_ExpireOverdue(t *testing.T) {
+	now := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+	svc, repo, _, entries := newDeletionTestService(now)
+	repo.On("FindOverdue", now, maxExpireBatch).Return([]models.DeletionRequest{
+		{ID: 1, UserID: 7, Status: models.DeletionPending},
+		{ID: 2, UserID: 8, Status: models.DeletionPending},
+	}, nil)
+	repo.On("Decide", mock.MatchedBy(func(d *models.DeletionRequest) bool { return d.ID == 1 })).Return(true, nil)
+	repo.On("Decide", mock.MatchedBy(func(d *models.DeletionRequest) bool { return d.ID == 2 })).Return(false, nil) // approved meanwhile
+
+	n, err := svc.ExpireOverdue(now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	require.Len(t, *entries, 1)
+	assert.Equal(t, audit.ActionDeletionExpire, (*entries)[0].Action)
+	assert.Zero(t, (*entries)[0].ActorID)
+}
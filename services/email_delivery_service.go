@@ -0,0 +1,83 @@
+package services // Email delivery tracking + bounce handling.
+
+import (
+	"context"
+	"errors"
+
+	"HelmyTask/core"
+	"HelmyTask/models"
+	"HelmyTask/repositories"
+	"HelmyTask/utils/redislog"
+)
+
+// ErrEmailUndeliverable lets verification/reset flows tell the user their address bounced,
+// instead of silently sending into the void (see EmailSender).
+var ErrEmailUndeliverable = errors.New("email address is undeliverable; please update your email")
+
+// EmailDeliveryService records sends and processes provider callbacks.
+type EmailDeliveryService interface {
+	RecordSent(to, kind, provider, providerMessageID string) error // Called by the mail sender.
+	HandleEvent(ev models.EmailEvent) error // Bounce/complaint/delivered webhook.
+}
+
+type emailDeliveryService struct {
+	deliveries repositories.EmailDeliveryRepository
+	users      repositories.UserRepository // Flag accounts whose address bounced.
+	log        *redislog.Logger
+}
+
+// NewEmailDeliveryService wires the delivery tracking use-cases.
+func NewEmailDeliveryService(deliveries repositories.EmailDeliveryRepository, users repositories.UserRepository, rlog *redislog.Logger) EmailDeliveryService {
+	return &emailDeliveryService{deliveries: deliveries, users: users, log: rlog}
+}
+
+// RecordSent stores a new delivery in "sent" state.
+func (s *emailDeliveryService) RecordSent(to, kind, provider, providerMessageID string) error {
+	return s.deliveries.Create(&models.EmailDelivery{
+		To: to, Kind: kind, Provider: provider, ProviderMessageID: providerMessageID, Status: models.EmailStatusSent,
+	})
+}
+
+// HandleEvent applies a provider callback. Hard bounces and complaints flag the address.
+func (s *emailDeliveryService) HandleEvent(ev models.EmailEvent) error {
+	d, err := s.deliveries.FindByProviderMessageID(ev.MessageID)
+	if err != nil { // Unknown message (e.g. sent by another system) → nothing to update.
+		if s.log != nil { s.log.Warn("email event for unknown message", map[string]string{"message_id": ev.MessageID, "type": ev.Type}) }
+		return err
+	}
+
+	status := models.EmailStatusDelivered
+	flag := false
+	switch ev.Type {
+	case "bounce":
+		status, flag = models.EmailStatusBounced, ev.BounceType != "soft" // soft = mailbox full etc.
+	case "complaint":
+		status, flag = models.EmailStatusComplained, true // never mail people who report spam
+	}
+	if err := s.deliveries.UpdateStatus(d.ID, status, ev.Reason); err != nil {
+		return err
+	}
+
+	if flag { // The address we sent to, not the event's: a forged event must not flag other accounts.
+		s.flagUndeliverable(d.To, ev)
+	}
+	return nil
+}
+
+// flagUndeliverable marks the owning account (if any) as undeliverable; best-effort.
+func (s *emailDeliveryService) flagUndeliverable(to string, ev models.EmailEvent) {
+	email, err := core.ParseEmail(to)
+	if err != nil {
+		return
+	}
+	u, err := s.users.FindByEmail(context.Background(), email)
+	if err != nil || u.EmailUndeliverable {
+		return // no such account, or already flagged
+	}
+	u.EmailUndeliverable = true
+	if err := s.users.Update(context.Background(), u); err != nil {
+		if s.log != nil { s.log.Error("flag undeliverable db error", map[string]string{"email": to, "err": err.Error()}) }
+		return
+	}
+	if s.log != nil { s.log.Warn("email flagged undeliverable", map[string]string{"email": to, "type": ev.Type, "reason": ev.Reason}) }
+}
@@ -0,0 +1,54 @@
+package services
+
+import (
+	"testing"
+
+	"HelmyTask/core"
+	"HelmyTask/mocks"
+	"HelmyTask/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestEmailDeliveryService_HardBounceFlagsUser(t *testing.T) {
+	deliveries := new(mocks.EmailDeliveryRepositoryMock)
+	users := new(mocks.UserRepositoryMock)
+	svc := NewEmailDeliveryService(deliveries, users, nil)
+
+	deliveries.On("FindByProviderMessageID", "m-1").Return(&models.EmailDelivery{ID: 4, To: "a@b.c"}, nil)
+	deliveries.On("UpdateStatus", uint(4), models.EmailStatusBounced, "mailbox does not exist").Return(nil)
+	users.On("FindByEmail", core.Email("a@b.c")).Return(&models.User{ID: 1, Email: "a@b.c"}, nil)
+	users.On("Update", mock.MatchedBy(func(u *models.User) bool { return u.EmailUndeliverable })).Return(nil)
+
+	err := svc.HandleEvent(models.EmailEvent{Type: "bounce", MessageID: "m-1", BounceType: "hard", Reason: "mailbox does not exist"})
+	assert.NoError(t, err)
+	users.AssertExpectations(t)
+}
+
+func TestEmailDeliveryService_FlagsRecordedRecipientOnly(t *testing.T) {
+	deliveries := new(mocks.EmailDeliveryRepositoryMock)
+	users := new(mocks.UserRepositoryMock)
+	svc := NewEmailDeliveryService(deliveries, users, nil)
+
+	deliveries.On("FindByProviderMessageID", "m-3").Return(&models.EmailDelivery{ID: 6, To: "a@b.c"}, nil)
+	deliveries.On("UpdateStatus", uint(6), models.EmailStatusComplained, "").Return(nil)
+	users.On("FindByEmail", core.Email("a@b.c")).Return(&models.User{ID: 1, Email: "a@b.c"}, nil)
+	users.On("Update", mock.MatchedBy(func(u *models.User) bool { return u.ID == 1 && u.EmailUndeliverable })).Return(nil)
+
+	assert.NoError(t, svc.HandleEvent(models.EmailEvent{Type: "complaint", MessageID: "m-3", Email: "victim@b.c"}))
+	users.AssertNotCalled(t, "FindByEmail", core.Email("victim@b.c"))
+	users.AssertExpectations(t)
+}
+
+func TestEmailDeliveryService_SoftBounceOnlyRecords(t *testing.T) {
+	deliveries := new(mocks.EmailDeliveryRepositoryMock)
+	users := new(mocks.UserRepositoryMock)
+	svc := NewEmailDeliveryService(deliveries, users, nil)
+
+	deliveries.On("FindByProviderMessageID", "m-2").Return(&models.EmailDelivery{ID: 5, To: "a@b.c"}, nil)
+	deliveries.On("UpdateStatus", uint(5), models.EmailStatusBounced, "mailbox full").Return(nil)
+
+	assert.NoError(t, svc.HandleEvent(models.EmailEvent{Type: "bounce", MessageID: "m-2", BounceType: "soft", Reason: "mailbox full"}))
+	users.AssertNotCalled(t, "Update", mock.Anything)
+}
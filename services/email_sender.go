@@ -0,0 +1,167 @@
+package services // Transactional emails (welcome, verification, password reset), sent from the background job queue.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"HelmyTask/core"
+	"HelmyTask/emailtmpl"
+	"HelmyTask/hooks"
+	"HelmyTask/jobs"
+	"HelmyTask/mailer"
+	"HelmyTask/models"
+	"HelmyTask/repositories"
+	"HelmyTask/utils/redislog"
+)
+
+// JobSendEmail is the job type that renders and sends one email (payload emailJob).
+const JobSendEmail = "email.send"
+
+// JobWelcomeEmail is the welcome-only job type queued before JobSendEmail (payload
+// {"user_id"}); still handled so jobs queued by older replicas go out.
+const JobWelcomeEmail = "email.welcome"
+
+// Email kinds, one emailtmpl template directory each.
+const (
+	EmailWelcome       = "welcome"
+	EmailVerification  = "verify_email"
+	EmailPasswordReset = "password_reset"
+)
+
+// EmailSender queues emails to users. Sending happens in the job queue, so a slow or down
+// mail server never holds up a request; failed sends are retried there.
+type EmailSender interface {
+	hooks.UserLifecycle // OnRegistered queues the welcome email
+
+	// SendVerification queues the email address confirmation email with its link, or returns
+	// ErrEmailUndeliverable if the user's address has bounced.
+	SendVerification(ctx context.Context, userID core.UserID, link string, validFor time.Duration) error
+	// SendPasswordReset queues the password reset email with its link, or returns
+	// ErrEmailUndeliverable if the user's address has bounced.
+	SendPasswordReset(ctx context.Context, userID core.UserID, link string, validFor time.Duration) error
+}
+
+type emailSender struct {
+	hooks.Base // only registrations
+	jobs       jobs.Enqueuer
+	users      repositories.UserRepository
+	templates  *emailtmpl.Registry
+	mailer     mailer.Mailer
+	deliveries EmailDeliveryService
+	appName    string
+	log        *redislog.Logger
+}
+
+// NewEmailSender registers the email job handlers on q. The returned sender is also the
+// lifecycle hook (services.WithLifecycleHooks) that welcomes every new account. Emails are
+// rendered in the user's locale and skipped for addresses flagged undeliverable.
+func NewEmailSender(q *jobs.Queue, users repositories.UserRepository, templates *emailtmpl.Registry, m mailer.Mailer, deliveries EmailDeliveryService, appName string, rlog *redislog.Logger) EmailSender {
+	s := &emailSender{jobs: q, users: users, templates: templates, mailer: m, deliveries: deliveries, appName: appName, log: rlog}
+	q.Handle(JobSendEmail, s.send)
+	q.Handle(JobWelcomeEmail, s.send) // {"user_id"} decodes as a welcome emailJob
+	return s
+}
+
+// emailJob is the JobSendEmail payload. Only the user ID is stored, not the address or name,
+// so they are read fresh at send time; Link is the one secret, kept for as long as the job.
+type emailJob struct {
+	Kind    string `json:"kind,omitempty"` // "" = welcome (JobWelcomeEmail payloads)
+	UserID  uint   `json:"user_id"`
+	Link    string `json:"link,omitempty"`
+	Expires string `json:"expires,omitempty"` // validity of Link, already humanized
+}
+
+// OnRegistered queues the welcome email.
+func (s *emailSender) OnRegistered(ctx context.Context, u models.User) {
+	if err := s.enqueue(ctx, emailJob{Kind: EmailWelcome, UserID: u.ID}); err != nil {
+		if s.log != nil { s.log.Error("welcome email enqueue failed", map[string]string{"user_id": fmt.Sprint(u.ID), "err": err.Error()}) }
+	}
+}
+
+func (s *emailSender) SendVerification(ctx context.Context, userID core.UserID, link string, validFor time.Duration) error {
+	if err := s.checkDeliverable(ctx, userID); err != nil {
+		return err
+	}
+	return s.enqueue(ctx, emailJob{Kind: EmailVerification, UserID: uint(userID), Link: link, Expires: humanDuration(validFor)})
+}
+
+func (s *emailSender) SendPasswordReset(ctx context.Context, userID core.UserID, link string, validFor time.Duration) error {
+	if err := s.checkDeliverable(ctx, userID); err != nil {
+		return err
+	}
+	return s.enqueue(ctx, emailJob{Kind: EmailPasswordReset, UserID: uint(userID), Link: link, Expires: humanDuration(validFor)})
+}
+
+// checkDeliverable refuses a link email up front when the user's address is flagged, so the
+// caller can ask for a new address; send would only drop it later. A user not found is left
+// to send.
+func (s *emailSender) checkDeliverable(ctx context.Context, userID core.UserID) error {
+	u, err := s.users.FindByID(ctx, userID)
+	if repositories.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if u.EmailUndeliverable {
+		return ErrEmailUndeliverable
+	}
+	return nil
+}
+
+func (s *emailSender) enqueue(ctx context.Context, job emailJob) error {
+	return s.jobs.Enqueue(ctx, JobSendEmail, job)
+}
+
+// send handles JobSendEmail. The user is re-read so a rename or locale change made in the
+// meantime is honoured; a user deleted since gets nothing.
+func (s *emailSender) send(ctx context.Context, payload json.RawMessage) error {
+	var job emailJob
+	if err := json.Unmarshal(payload, &job); err != nil {
+		return jobs.Permanent(err)
+	}
+	if job.Kind == "" {
+		job.Kind = EmailWelcome
+	}
+	u, err := s.users.FindByID(ctx, core.UserID(job.UserID))
+	if repositories.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if u.EmailUndeliverable {
+		return nil
+	}
+	msg, err := s.templates.Render(job.Kind, u.Locale, emailtmpl.Data{AppName: s.appName, Name: u.Name, Link: job.Link, Expires: job.Expires})
+	if err != nil {
+		return jobs.Permanent(err) // a broken template or unknown kind won't fix itself on retry
+	}
+	provider, messageID, err := s.mailer.Send(ctx, u.Email, msg)
+	if err != nil {
+		return err
+	}
+	if s.deliveries != nil {
+		if err := s.deliveries.RecordSent(u.Email, job.Kind, provider, messageID); err != nil && s.log != nil {
+			s.log.Warn("email delivery record failed", map[string]string{"user_id": fmt.Sprint(u.ID), "kind": job.Kind, "err": err.Error()})
+		}
+	}
+	return nil
+}
+
+// humanDuration renders a link's validity for the email body: "1 hour", "30 minutes", "2 days".
+func humanDuration(d time.Duration) string {
+	unit, n := "minute", int64(d/time.Minute)
+	switch {
+	case d >= 24*time.Hour && d%(24*time.Hour) == 0:
+		unit, n = "day", int64(d/(24*time.Hour))
+	case d >= time.Hour && d%time.Hour == 0:
+		unit, n = "hour", int64(d/time.Hour)
+	}
+	if n == 1 {
+		return "1 " + unit
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
@@ -0,0 +1,61 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"HelmyTask/core"
+	"HelmyTask/emailtmpl"
+	"HelmyTask/mocks"
+	"HelmyTask/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type sentEmail struct {
+	to  string
+	msg emailtmpl.Message
+}
+
+type recordingMailer struct{ sent []sentEmail }
+
+func (m *recordingMailer) Send(_ context.Context, to string, msg emailtmpl.Message) (string, string, error) {
+	m.sent = append(m.sent, sentEmail{to, msg})
+	return "test", "id-1", nil
+}
+
+func TestEmailSender_SendsQueuedEmails(t *testing.T) {
+	reg, err := emailtmpl.New("en")
+	require.NoError(t, err)
+	users, m, q := new(mocks.UserRepositoryMock), &recordingMailer{}, &recordingEnqueuer{}
+	users.On("FindByID", core.UserID(7)).Return(&models.User{ID: 7, Name: "Mona", Email: "mona@b.c", Locale: "ar-EG"}, nil)
+	users.On("FindByID", core.UserID(8)).Return(&models.User{ID: 8, Email: "gone@b.c", EmailUndeliverable: true}, nil)
+	s := &emailSender{jobs: q, users: users, templates: reg, mailer: m, appName: "App"}
+
+	require.NoError(t, s.SendPasswordReset(context.Background(), 7, "https://app/reset?t=x", time.Hour))
+	assert.ErrorIs(t, s.SendVerification(context.Background(), 8, "https://app/verify?t=x", time.Hour), ErrEmailUndeliverable)
+	s.OnRegistered(context.Background(), models.User{ID: 8})
+	require.Len(t, q.jobs, 2)
+	for _, job := range q.jobs {
+		payload, _ := json.Marshal(job)
+		require.NoError(t, s.send(context.Background(), payload))
+	}
+	require.NoError(t, s.send(context.Background(), json.RawMessage(`{"user_id":7}`)), "legacy email.welcome payload")
+
+	require.Len(t, m.sent, 2, "nothing for the undeliverable address")
+	assert.Equal(t, "mona@b.c", m.sent[0].to)
+	assert.Equal(t, EmailPasswordReset, m.sent[0].msg.Kind)
+	assert.Equal(t, "ar-EG", m.sent[0].msg.Locale)
+	assert.Contains(t, m.sent[0].msg.Text, "https://app/reset?t=x")
+	assert.Equal(t, EmailWelcome, m.sent[1].msg.Kind)
+}
+
+func TestHumanDuration(t *testing.T) {
+	assert.Equal(t, "1 hour", humanDuration(time.Hour))
+	assert.Equal(t, "90 minutes", humanDuration(90*time.Minute))
+	assert.Equal(t, "2 days", humanDuration(48*time.Hour))
+	assert.Equal(t, "1 minute", humanDuration(time.Minute))
+}
@@ -0,0 +1,87 @@
+package services // Lifecycle hook that publishes user changes to the message broker.
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"HelmyTask/events"
+	"HelmyTask/hooks"
+	"HelmyTask/jobs"
+	"HelmyTask/models"
+	"HelmyTask/utils/redislog"
+)
+
+// JobEventPublish is the job type that hands one event to the broker.
+const JobEventPublish = "event.publish"
+
+type eventPublishJob struct {
+	ID   string          `json:"id"`
+	Type string          `json:"type"`
+	Key  string          `json:"key"`
+	Data json.RawMessage `json:"data"`
+}
+
+// eventBusHooks queues user events for the broker.
+type eventBusHooks struct {
+	hooks.Base // logins aren't published
+	pub        events.Publisher
+	jobs       jobs.Enqueuer
+	log        *redislog.Logger
+	now        func() time.Time
+}
+
+// NewEventBusHooks registers the JobEventPublish handler on q and returns the lifecycle hook
+// (services.WithLifecycleHooks) that publishes user.created, user.updated and user.deleted
+// through pub. Publishing runs on the job queue, so a broker outage holds up no request and
+// messages are retried until the broker takes them; each keeps its ID across retries.
+func NewEventBusHooks(pub events.Publisher, q *jobs.Queue, rlog *redislog.Logger) hooks.UserLifecycle {
+	q.Handle(JobEventPublish, func(ctx context.Context, payload json.RawMessage) error {
+		var job eventPublishJob
+		if err := json.Unmarshal(payload, &job); err != nil {
+			return jobs.Permanent(err)
+		}
+		return pub.Publish(ctx, events.Message{ID: job.ID, Type: job.Type, Key: job.Key, Data: job.Data})
+	})
+	return &eventBusHooks{pub: pub, jobs: q, log: rlog, now: time.Now}
+}
+
+// OnRegistered publishes user.created with the new user.
+func (h *eventBusHooks) OnRegistered(ctx context.Context, u models.User) {
+	h.publish(ctx, events.Event{Type: events.UserCreated, UserID: u.ID, User: &u})
+}
+
+// OnUpdated publishes user.updated with the user's new state.
+func (h *eventBusHooks) OnUpdated(ctx context.Context, u models.User) {
+	h.publish(ctx, events.Event{Type: events.UserUpdated, UserID: u.ID, User: &u})
+}
+
+// OnDeleted publishes user.deleted with the removed user's ID.
+func (h *eventBusHooks) OnDeleted(ctx context.Context, id uint) {
+	h.publish(ctx, events.Event{Type: events.UserDeleted, UserID: id})
+}
+
+// publish stamps e and queues it; if Redis refuses the job, it is published right away in the
+// background instead (once, without retries).
+func (h *eventBusHooks) publish(ctx context.Context, e events.Event) {
+	id := make([]byte, 12)
+	_, _ = rand.Read(id)
+	e.ID, e.At = "evt_"+hex.EncodeToString(id), h.now().UTC()
+	data, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	job := eventPublishJob{ID: e.ID, Type: e.Type, Key: fmt.Sprint(e.UserID), Data: data}
+	if err := h.jobs.Enqueue(ctx, JobEventPublish, job); err != nil {
+		if h.log != nil { h.log.Warn("event enqueue failed, publishing inline", map[string]string{"event": e.Type, "err": err.Error()}) }
+		go func() {
+			m := events.Message{ID: job.ID, Type: job.Type, Key: job.Key, Data: job.Data}
+			if err := h.pub.Publish(context.Background(), m); err != nil && h.log != nil {
+				h.log.Error("event publish failed", map[string]string{"event": m.Type, "id": m.ID, "err": err.Error()})
+			}
+		}()
+	}
+}
@@ -0,0 +1,68 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"HelmyTask/events"
+	"HelmyTask/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingEnqueuer struct {
+	jobs []any
+	err  error
+}
+
+func (q *recordingEnqueuer) Enqueue(_ context.Context, _ string, payload any) error {
+	q.jobs = append(q.jobs, payload)
+	return q.err
+}
+
+type chanPublisher chan events.Message
+
+func (p chanPublisher) Publish(_ context.Context, m events.Message) error { p <- m; return nil }
+func (p chanPublisher) Close() error                                      { return nil }
+
+func TestEventBusHooks_QueuesUserEvents(t *testing.T) {
+	q := &recordingEnqueuer{}
+	at := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+	h := &eventBusHooks{pub: make(chanPublisher), jobs: q, now: func() time.Time { return at }}
+
+	h.OnUpdated(context.Background(), models.User{ID: 7, Email: "a@b.c", Password: "hash"})
+	h.OnDeleted(context.Background(), 7)
+	require.Len(t, q.jobs, 2)
+
+	job := q.jobs[0].(eventPublishJob)
+	assert.Equal(t, events.UserUpdated, job.Type)
+	assert.Equal(t, "7", job.Key, "a user's events share a key")
+	var e events.Event
+	require.NoError(t, json.Unmarshal(job.Data, &e))
+	assert.Equal(t, job.ID, e.ID)
+	assert.Equal(t, "a@b.c", e.User.Email)
+	assert.Equal(t, at, e.At)
+	assert.NotContains(t, string(job.Data), "hash")
+
+	del := q.jobs[1].(eventPublishJob)
+	assert.Equal(t, events.UserDeleted, del.Type)
+	assert.NotEqual(t, job.ID, del.ID)
+}
+
+func TestEventBusHooks_PublishesInlineWhenQueueFails(t *testing.T) {
+	pub := make(chanPublisher, 1)
+	h := &eventBusHooks{pub: pub, jobs: &recordingEnqueuer{err: errors.New("redis down")}, now: time.Now}
+
+	h.OnRegistered(context.Background(), models.User{ID: 3})
+	select {
+	case m := <-pub:
+		assert.Equal(t, events.UserCreated, m.Type)
+		assert.Equal(t, "3", m.Key)
+	case <-time.After(time.Second):
+		t.Fatal("not published")
+	}
+}
@@ -0,0 +1,99 @@
+package services // Status page incidents (admin-managed, publicly visible).
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"HelmyTask/core"
+	"HelmyTask/models"
+	"HelmyTask/repositories"
+	"HelmyTask/utils/redislog"
+)
+
+// ErrIncidentNotFound is returned when updating an incident that doesn't exist.
+var ErrIncidentNotFound = errors.New("incident not found")
+
+// incidentHistory is how long resolved incidents stay on the status page.
+const incidentHistory = 7 * 24 * time.Hour
+
+// maxListedIncidents caps the status page list.
+const maxListedIncidents = 50
+
+// IncidentService opens, updates and lists incidents.
+type IncidentService interface {
+	Create(by core.UserID, req models.CreateIncidentRequest) (*models.Incident, error)
+	Update(id uint, req models.UpdateIncidentRequest) (*models.Incident, error) // ErrIncidentNotFound if absent.
+	Recent() ([]models.Incident, error) // Open ones plus those resolved in the last 7 days, newest first.
+}
+
+type incidentService struct {
+	repo repositories.IncidentRepository
+	log  *redislog.Logger
+	now  func() time.Time
+}
+
+// NewIncidentService wires the incident use-cases.
+func NewIncidentService(repo repositories.IncidentRepository, rlog *redislog.Logger) IncidentService {
+	return &incidentService{repo: repo, log: rlog, now: time.Now}
+}
+
+// Create opens an incident (status investigating unless given).
+func (s *incidentService) Create(by core.UserID, req models.CreateIncidentRequest) (*models.Incident, error) {
+	i := models.Incident{Title: req.Title, Impact: req.Impact, Status: req.Status, Message: req.Message, CreatedByID: uint(by)}
+	if i.Status == "" {
+		i.Status = models.IncidentInvestigating
+	}
+	s.setResolved(&i)
+	if err := s.repo.Create(&i); err != nil {
+		if s.log != nil { s.log.Error("incident create db error", map[string]string{"title": req.Title, "err": err.Error()}) }
+		return nil, err
+	}
+	if s.log != nil { s.log.Warn("incident opened", map[string]string{"id": fmt.Sprint(i.ID), "impact": i.Impact, "by": fmt.Sprint(by)}) }
+	return &i, nil
+}
+
+// Update applies the non-nil fields. Resolving stamps ResolvedAt; any other status re-opens.
+func (s *incidentService) Update(id uint, req models.UpdateIncidentRequest) (*models.Incident, error) {
+	i, err := s.repo.FindByID(id)
+	if repositories.IsNotFound(err) {
+		return nil, ErrIncidentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if req.Title != nil {
+		i.Title = *req.Title
+	}
+	if req.Impact != nil {
+		i.Impact = *req.Impact
+	}
+	if req.Status != nil {
+		i.Status = *req.Status
+	}
+	if req.Message != nil {
+		i.Message = *req.Message
+	}
+	s.setResolved(i)
+	if err := s.repo.Update(i); err != nil {
+		return nil, err
+	}
+	if s.log != nil { s.log.Info("incident updated", map[string]string{"id": fmt.Sprint(i.ID), "status": i.Status}) }
+	return i, nil
+}
+
+// Recent lists what the status page shows.
+func (s *incidentService) Recent() ([]models.Incident, error) {
+	return s.repo.ListSince(s.now().Add(-incidentHistory), maxListedIncidents)
+}
+
+// setResolved keeps ResolvedAt consistent with Status (the first resolution time is kept).
+func (s *incidentService) setResolved(i *models.Incident) {
+	switch {
+	case i.Status != models.IncidentResolved:
+		i.ResolvedAt = nil
+	case i.ResolvedAt == nil:
+		now := s.now()
+		i.ResolvedAt = &now
+	}
+}
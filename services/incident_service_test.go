@@ -0,0 +1,55 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"HelmyTask/mocks"
+	"HelmyTask/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestIncidentService_ResolveAndReopen(t *testing.T) {
+	repo := new(mocks.IncidentRepositoryMock)
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	svc := &incidentService{repo: repo, now: func() time.Time { return now }}
+
+	repo.On("Create", mock.AnythingOfType("*models.Incident")).Return(nil)
+	i, err := svc.Create(1, models.CreateIncidentRequest{Title: "Login errors", Impact: models.ImpactMajor})
+	require.NoError(t, err)
+	assert.Equal(t, models.IncidentInvestigating, i.Status)
+	assert.Nil(t, i.ResolvedAt)
+
+	repo.On("FindByID", uint(3)).Return(i, nil)
+	repo.On("Update", i).Return(nil)
+	resolved := models.IncidentResolved
+	i, err = svc.Update(3, models.UpdateIncidentRequest{Status: &resolved})
+	require.NoError(t, err)
+	require.NotNil(t, i.ResolvedAt)
+	assert.Equal(t, now, *i.ResolvedAt)
+
+	monitoring := models.IncidentMonitoring
+	i, err = svc.Update(3, models.UpdateIncidentRequest{Status: &monitoring})
+	require.NoError(t, err)
+	assert.Nil(t, i.ResolvedAt, "re-opened")
+
+	repo.On("FindByID", uint(4)).Return(nil, gorm.ErrRecordNotFound)
+	_, err = svc.Update(4, models.UpdateIncidentRequest{Status: &resolved})
+	assert.ErrorIs(t, err, ErrIncidentNotFound)
+}
+
+func TestIncidentService_RecentCoversAWeek(t *testing.T) {
+	repo := new(mocks.IncidentRepositoryMock)
+	now := time.Date(2025, 3, 8, 0, 0, 0, 0, time.UTC)
+	svc := &incidentService{repo: repo, now: func() time.Time { return now }}
+	repo.On("ListSince", now.Add(-7*24*time.Hour), maxListedIncidents).Return([]models.Incident{{ID: 1}}, nil)
+
+	items, err := svc.Recent()
+	require.NoError(t, err)
+	assert.Len(t, items, 1)
+	repo.AssertExpectations(t)
+}
@@ -0,0 +1,122 @@
+package services // Lifecycle hook that notifies users about their account over the configured channels.
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"HelmyTask/core"
+	"HelmyTask/hooks"
+	"HelmyTask/jobs"
+	"HelmyTask/models"
+	"HelmyTask/notifications"
+	"HelmyTask/repositories"
+	"HelmyTask/utils/redislog"
+)
+
+// JobNotify is the job type that delivers one notification on one channel.
+const JobNotify = "notification.send"
+
+type notifyJob struct {
+	UserID  uint   `json:"user_id"`
+	Kind    string `json:"kind"`
+	Channel string `json:"channel"`
+}
+
+// notificationHooks queues a job per routed channel on account events.
+type notificationHooks struct {
+	dispatch *notifications.Dispatcher
+	users    repositories.UserRepository
+	inbox    repositories.NotificationRepository
+	jobs     jobs.Enqueuer
+	appName  string
+	log      *redislog.Logger
+}
+
+// NewNotificationHooks registers the JobNotify handler on q and returns the lifecycle hook
+// (services.WithLifecycleHooks) that notifies users of registration (welcome), account
+// changes (account_updated) and logins (new_login) on the channels d routes each kind to.
+// Deleting an account also deletes its in-app notifications.
+func NewNotificationHooks(d *notifications.Dispatcher, users repositories.UserRepository, inbox repositories.NotificationRepository, q *jobs.Queue, appName string, rlog *redislog.Logger) hooks.UserLifecycle {
+	h := &notificationHooks{dispatch: d, users: users, inbox: inbox, jobs: q, appName: appName, log: rlog}
+	q.Handle(JobNotify, h.deliver)
+	return h
+}
+
+// OnRegistered sends the welcome notification.
+func (h *notificationHooks) OnRegistered(ctx context.Context, u models.User) {
+	h.notify(ctx, u.ID, notifications.KindWelcome)
+}
+
+// OnUpdated tells the user their account changed.
+func (h *notificationHooks) OnUpdated(ctx context.Context, u models.User) {
+	h.notify(ctx, u.ID, notifications.KindAccountUpdated)
+}
+
+// OnLogin tells the user about a new login.
+func (h *notificationHooks) OnLogin(ctx context.Context, u models.User) {
+	h.notify(ctx, u.ID, notifications.KindNewLogin)
+}
+
+// OnDeleted removes the user's in-app notifications.
+func (h *notificationHooks) OnDeleted(_ context.Context, id uint) {
+	if _, err := h.inbox.DeleteForUser(id); err != nil && h.log != nil {
+		h.log.Error("notifications delete failed", map[string]string{"user_id": fmt.Sprint(id), "err": err.Error()})
+	}
+}
+
+// notify queues kind on each of its channels; if Redis refuses a job, that channel is
+// delivered right away in the background instead (once, without retries).
+func (h *notificationHooks) notify(ctx context.Context, userID uint, kind string) {
+	for _, channel := range h.dispatch.Routed(kind) {
+		job := notifyJob{UserID: userID, Kind: kind, Channel: channel}
+		if err := h.jobs.Enqueue(ctx, JobNotify, job); err != nil {
+			if h.log != nil { h.log.Warn("notification enqueue failed, delivering inline", map[string]string{"kind": kind, "channel": channel, "err": err.Error()}) }
+			go func() {
+				payload, _ := json.Marshal(job)
+				if err := h.deliver(context.Background(), payload); err != nil && h.log != nil {
+					h.log.Error("notification delivery failed", map[string]string{"kind": job.Kind, "channel": job.Channel, "err": err.Error()})
+				}
+			}()
+		}
+	}
+}
+
+// deliver handles JobNotify. The user is re-read so the latest email, phone and name are
+// used; a user deleted since gets nothing.
+func (h *notificationHooks) deliver(ctx context.Context, payload json.RawMessage) error {
+	var job notifyJob
+	if err := json.Unmarshal(payload, &job); err != nil {
+		return jobs.Permanent(err)
+	}
+	msg, ok := h.message(job.Kind)
+	if !ok {
+		return jobs.Permanent(fmt.Errorf("unknown notification kind %q", job.Kind))
+	}
+	u, err := h.users.FindByID(ctx, core.UserID(job.UserID))
+	if repositories.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	err = h.dispatch.Deliver(ctx, *u, msg, job.Channel)
+	if errors.Is(err, notifications.ErrSMSRejected) {
+		return jobs.Permanent(err) // a bad number won't fix itself on retry
+	}
+	return err
+}
+
+// message is the text of each kind.
+func (h *notificationHooks) message(kind string) (notifications.Message, bool) {
+	switch kind {
+	case notifications.KindWelcome:
+		return notifications.Message{Kind: kind, Title: "Welcome to " + h.appName, Body: "Your account is ready."}, true
+	case notifications.KindAccountUpdated:
+		return notifications.Message{Kind: kind, Title: "Your account was updated", Body: "Your account details changed. If this wasn't you, change your password and contact support."}, true
+	case notifications.KindNewLogin:
+		return notifications.Message{Kind: kind, Title: "New login to your account", Body: "Someone just logged in to your " + h.appName + " account. If this wasn't you, change your password."}, true
+	}
+	return notifications.Message{}, false
+}
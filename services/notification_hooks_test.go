@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"HelmyTask/core"
+	"HelmyTask/mocks"
+	"HelmyTask/models"
+	"HelmyTask/notifications"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memInbox is an in-memory repositories.NotificationRepository.
+type memInbox struct{ items []models.Notification }
+
+func (r *memInbox) Create(n *models.Notification) error {
+	n.ID = uint(len(r.items) + 1)
+	r.items = append(r.items, *n)
+	return nil
+}
+
+func (r *memInbox) ListForUser(userID uint, unreadOnly bool, offset, limit int) ([]models.Notification, int64, error) {
+	var out []models.Notification
+	for i := len(r.items) - 1; i >= 0; i-- {
+		if n := r.items[i]; n.UserID == userID && (!unreadOnly || n.ReadAt == nil) {
+			out = append(out, n)
+		}
+	}
+	total := int64(len(out))
+	if offset > len(out) {
+		offset = len(out)
+	}
+	out = out[offset:]
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, total, nil
+}
+
+func (r *memInbox) CountUnread(userID uint) (int64, error) {
+	_, n, err := r.ListForUser(userID, true, 0, 0)
+	return n, err
+}
+
+func (r *memInbox) MarkRead(userID, id uint, at time.Time) (bool, error) {
+	for i := range r.items {
+		if n := &r.items[i]; n.ID == id && n.UserID == userID {
+			if n.ReadAt == nil {
+				n.ReadAt = &at
+			}
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *memInbox) MarkAllRead(userID uint, at time.Time) (int64, error) {
+	var n int64
+	for i := range r.items {
+		if it := &r.items[i]; it.UserID == userID && it.ReadAt == nil {
+			it.ReadAt, n = &at, n+1
+		}
+	}
+	return n, nil
+}
+
+func (r *memInbox) DeleteForUser(userID uint) (int64, error) {
+	kept := r.items[:0]
+	for _, n := range r.items {
+		if n.UserID != userID {
+			kept = append(kept, n)
+		}
+	}
+	removed := int64(len(r.items) - len(kept))
+	r.items = kept
+	return removed, nil
+}
+
+func TestNotificationHooks_QueuesAndDelivers(t *testing.T) {
+	inbox, users, q := &memInbox{}, new(mocks.UserRepositoryMock), &recordingEnqueuer{}
+	users.On("FindByID", core.UserID(7)).Return(&models.User{ID: 7, Name: "Mona"}, nil)
+	d, err := notifications.NewDispatcher(map[string][]string{notifications.KindWelcome: {notifications.ChannelInApp}}, notifications.InApp{Repo: inbox})
+	require.NoError(t, err)
+	h := &notificationHooks{dispatch: d, users: users, inbox: inbox, jobs: q, appName: "App"}
+
+	h.OnRegistered(context.Background(), models.User{ID: 7})
+	h.OnLogin(context.Background(), models.User{ID: 7}) // not routed
+	require.Len(t, q.jobs, 1)
+	payload, _ := json.Marshal(q.jobs[0])
+	require.NoError(t, h.deliver(context.Background(), payload))
+	require.Len(t, inbox.items, 1)
+	assert.Equal(t, "Welcome to App", inbox.items[0].Title)
+
+	svc := NewNotificationService(inbox)
+	paged, err := svc.List(7, false, 1, 20)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, paged.Unread)
+	assert.ErrorIs(t, svc.MarkRead(8, inbox.items[0].ID), ErrNotificationNotFound, "someone else's")
+	require.NoError(t, svc.MarkRead(7, inbox.items[0].ID))
+	paged, _ = svc.List(7, true, 1, 20)
+	assert.Empty(t, paged.Items)
+
+	h.OnDeleted(context.Background(), 7)
+	assert.Empty(t, inbox.items)
+}
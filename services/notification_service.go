@@ -0,0 +1,68 @@
+package services // In-app notifications: the user's list and read state.
+
+import (
+	"errors"
+	"time"
+
+	"HelmyTask/core"
+	"HelmyTask/models"
+	"HelmyTask/repositories"
+)
+
+// ErrNotificationNotFound is returned for an ID that isn't one of the caller's notifications.
+var ErrNotificationNotFound = errors.New("notification not found")
+
+// NotificationService serves GET /me/notifications and the mark-as-read endpoints.
+type NotificationService interface {
+	List(userID core.UserID, unreadOnly bool, page, limit int) (*models.PagedNotifications, error)
+	MarkRead(userID core.UserID, id uint) error
+	MarkAllRead(userID core.UserID) (int64, error)
+}
+
+type notificationService struct {
+	repo repositories.NotificationRepository
+	now  func() time.Time
+}
+
+// NewNotificationService wires the repository.
+func NewNotificationService(repo repositories.NotificationRepository) NotificationService {
+	return &notificationService{repo: repo, now: time.Now}
+}
+
+// List returns one page of the user's notifications, newest first, with the unread count.
+func (s *notificationService) List(userID core.UserID, unreadOnly bool, page, limit int) (*models.PagedNotifications, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	items, total, err := s.repo.ListForUser(uint(userID), unreadOnly, (page-1)*limit, limit)
+	if err != nil {
+		return nil, err
+	}
+	unread := total
+	if !unreadOnly {
+		if unread, err = s.repo.CountUnread(uint(userID)); err != nil {
+			return nil, err
+		}
+	}
+	return &models.PagedNotifications{Items: items, Total: total, Unread: unread, Page: page, Limit: limit}, nil
+}
+
+// MarkRead marks one notification read (again is fine).
+func (s *notificationService) MarkRead(userID core.UserID, id uint) error {
+	ok, err := s.repo.MarkRead(uint(userID), id, s.now().UTC())
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrNotificationNotFound
+	}
+	return nil
+}
+
+// MarkAllRead marks all of the user's notifications read and returns how many were unread.
+func (s *notificationService) MarkAllRead(userID core.UserID) (int64, error) {
+	return s.repo.MarkAllRead(uint(userID), s.now().UTC())
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"HelmyTask/core"
+	"HelmyTask/hooks"
+	"HelmyTask/models"
+	"HelmyTask/utils"
+)
+
+// SetupService is the first-run bootstrap: on a deployment with no users yet, the first
+// admin account is created over the API instead of with DB access or seed credentials.
+type SetupService interface {
+	SetupRequired(ctx context.Context) (bool, error)                              // True until the first account exists.
+	Setup(ctx context.Context, req models.RegisterRequest) (*models.User, error) // Create the first admin; works once.
+}
+
+// Errors the setup handler maps to specific HTTP responses.
+var (
+	ErrSetupDone        = errors.New("setup already completed")
+	ErrSetupInProgress  = errors.New("setup already in progress")
+	ErrSetupUnavailable = errors.New("setup needs Redis") // Its lock lives there; without it two first requests could both create an admin.
+)
+
+const (
+	setupDoneKey = "setup:done" // Set for good once the first admin exists, so setup stays locked even if every user is deleted later.
+	setupLockKey = "setup:lock" // Held while one request creates the admin; a concurrent one gets ErrSetupInProgress.
+	setupLockTTL = 30 * time.Second
+)
+
+// SetupRequired reports whether setup is still open: never completed and no users at all.
+func (s *userService) SetupRequired(ctx context.Context) (bool, error) {
+	if s.rdb != nil {
+		n, err := s.rdb.Exists(ctx, setupDoneKey).Result()
+		if err != nil {
+			return false, err
+		}
+		if n > 0 {
+			return false, nil
+		}
+	}
+	users, err := s.repo.ListAfter(ctx, models.ListUserQuery{}, 0, 1)
+	if err != nil {
+		return false, err
+	}
+	return len(users) == 0, nil
+}
+
+// Setup creates the first account as an admin, after the same checks as Register, then locks
+// itself. It fails with ErrSetupDone once any user exists, and with ErrSetupUnavailable
+// without Redis.
+func (s *userService) Setup(ctx context.Context, req models.RegisterRequest) (*models.User, error) {
+	if s.log != nil { s.log.Info("Setup called", map[string]string{"email": req.Email}) } // Trace call.
+
+	if s.rdb == nil { // No lock to take: refuse rather than let two first requests both pass the check.
+		return nil, ErrSetupUnavailable
+	}
+	ok, err := s.rdb.SetNX(ctx, setupLockKey, time.Now().Unix(), setupLockTTL).Result() // Two first requests racing: only one gets to check and create.
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrSetupInProgress
+	}
+	defer s.rdb.Del(context.WithoutCancel(ctx), setupLockKey)
+	open, err := s.SetupRequired(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !open {
+		return nil, ErrSetupDone
+	}
+
+	email, err := s.validateNewUser(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := utils.HashPassword(req.Password)
+	if err != nil {
+		return nil, err
+	}
+	u := &models.User{Name: core.NormalizeName(req.Name), Email: email.String(), Password: hash, Role: models.RoleAdmin, Status: models.StatusActive}
+	if err := s.repo.Create(ctx, u); err != nil {
+		if s.log != nil { s.log.Error("Setup db create error", map[string]string{"email": req.Email, "err": err.Error()}) }
+		return nil, err
+	}
+	if err := s.rdb.Set(context.WithoutCancel(ctx), setupDoneKey, time.Now().Unix(), 0).Err(); err != nil { // The user row still keeps setup closed.
+		if s.log != nil { s.log.Error("Setup done marker error", map[string]string{"err": err.Error()}) }
+	}
+	s.rememberEmails(ctx, u.Email)
+	s.users.Set(ctx, core.UserID(u.ID), u)
+	s.notify(ctx, "registered", func(ctx context.Context, h hooks.UserLifecycle) { h.OnRegistered(ctx, *u) })
+
+	if s.log != nil { s.log.Warn("Setup completed: first admin created", map[string]string{"user_id": fmt.Sprint(u.ID), "email": u.Email}) }
+	return u, nil
+}
@@ -2,62 +2,261 @@ package services // Use-case layer; orchestrates business rules, not HTTP/DB det
 
 import ( // Imports for this service layer.
 	"context" // For Redis commands (need a Context).
-	"encoding/json" // For caching user structs as JSON strings in Redis.
+	"encoding/json" // Job payloads.
 	"errors" // For returning friendly domain errors (e.g., "email already exists").
-	"fmt" // For formatting Redis cache keys.
+	"fmt" // For formatting log fields and errors.
+	"sort" // Import report in row order.
+	"strconv" // Pagination cursors.
+	"strings" // Split idempotency values.
 	"time" // For TTLs and JWT expiration.
 
+	"HelmyTask/accounts" // Encrypted account bundles (export/import between environments).
+	"HelmyTask/audit" // Audit entries written in the change's transaction.
 	"HelmyTask/core" // Domain helpers; e.g., NormalizeName.
+	"HelmyTask/hooks" // Deployment plug-ins for user lifecycle events.
+	"HelmyTask/jobs" // Background queue for slow side effects.
+	"HelmyTask/metrics" // Prometheus cache counters.
 	"HelmyTask/models" // DTOs and User model.
+	"HelmyTask/policy" // Role validation.
 	"HelmyTask/repositories" // Repository interface.
+	"HelmyTask/scripting" // Configured registration rules / claim scripts.
 	"HelmyTask/utils" // HashPassword / CheckPassword helpers.
+	"HelmyTask/utils/idempotency" // Idempotency-Key result store.
+	"HelmyTask/utils/jwtkeys" // Token signing keys (HS256/RS256).
+	"HelmyTask/utils/knownemails" // Redis pre-filter for taken emails.
+	"HelmyTask/utils/lockout" // Failed-login lockout per email.
+	"HelmyTask/utils/readcache" // Read-through "user:<id>" cache.
 	"HelmyTask/utils/redislog" // Redis logger interface (your provided file).
+	"HelmyTask/utils/revocation" // Bulk JWT revocation on password change.
+	"HelmyTask/utils/session" // Server-side sessions to end on password change.
 
 	"github.com/golang-jwt/jwt/v5" // JWT token creation/signing.
 	"github.com/redis/go-redis/v9" // Redis client for cache.
 )
 
-// UserService lists all use-cases that handlers can call.
-type UserService interface {
-	// Auth & read:
-	Register(req models.RegisterRequest) (*models.User, error) // Public register.
-	Login(req models.LoginRequest, jwtSecret string, exp time.Duration) (string, error) // Login and get JWT.
-	GetByID(id uint) (*models.User, error) // Fetch one (cache-aware); used by /me.
+// AuthService is the credential side of users: sign-up, login, 2FA and password changes.
+// It grows on its own (OAuth, WebAuthn, ...) without touching the admin surface.
+type AuthService interface {
+	Register(ctx context.Context, req models.RegisterRequest) (*models.User, error) // Public register.
+	Login(ctx context.Context, req models.LoginRequest, jwtSecret string, exp time.Duration) (string, error) // Login and get JWT.
+	Authenticate(ctx context.Context, req models.LoginRequest) (*models.User, error) // Verify credentials (+2FA) only; used by session mode.
+	LoginLockout(ctx context.Context, email string) time.Duration // Remaining lockout after repeated failed logins (0 = none).
+
+	// Two-factor (TOTP):
+	EnableTwoFactor(ctx context.Context, id core.UserID) (*models.TwoFactorSetup, error) // Generate + store a pending secret.
+	ConfirmTwoFactor(ctx context.Context, id core.UserID, code string) error // Verify first code and switch 2FA on.
 
-	// CRUD:
-	CreateUser(req models.RegisterRequest) (*models.User, error) // Admin create (same behavior as register).
-	GetUser(id uint) (*models.User, error) // Read one; alias of GetByID for clarity.
-	UpdateUser(id uint, req models.UpdateUserRequest) (*models.User, error) // Partial update.
-	DeleteUser(id uint) error // Delete by ID.
-	ListUsers(page, limit int) (*models.PagedUsers, error) // Paginated list.
+	// Self-service password change (re-verifies the current one, then logs out everywhere).
+	ChangePassword(ctx context.Context, id core.UserID, req models.ChangePasswordRequest) error
+
+	// Password strength (same estimator that register/update enforce).
+	PasswordStrength(req models.PasswordStrengthRequest) models.PasswordStrengthResponse
 }
 
+// UserAdminService is user management: CRUD, status, list and bulk operations (also what
+// /me reads, updates and deletes through).
+type UserAdminService interface {
+	GetByID(ctx context.Context, id core.UserID) (*models.User, error) // Fetch one (cache-aware).
+	CreateUser(ctx context.Context, req models.RegisterRequest) (*models.User, error) // Admin create (same behavior as register).
+	GetUser(ctx context.Context, id core.UserID) (*models.User, error) // Read one; alias of GetByID for clarity.
+	UpdateUser(ctx context.Context, id core.UserID, req models.UpdateUserRequest) (*models.User, error) // Partial update.
+	DeleteUser(ctx context.Context, id core.UserID) error // Delete by ID.
+	SetStatus(ctx context.Context, id core.UserID, status, reason string) (*models.User, error) // Ban/unban/disable; non-active logs the user out everywhere.
+	ImportUsers(ctx context.Context, rows []models.RegisterRequest) (*models.ImportUsersResult, error) // Validate + batch insert; per-row report.
+	DeleteUsers(ctx context.Context, ids []core.UserID) (*models.BulkDeleteUsersResult, error) // Bulk delete in one transaction.
+	ExportUsers(ctx context.Context, q models.ListUserQuery, each func([]models.User) error) error // Every matching user, in id order, batch by batch.
+	ListUsers(ctx context.Context, q models.ListUserQuery) (*models.PagedUsers, error) // Paginated, optionally filtered list.
+	ExportAccounts(ctx context.Context, ids []core.UserID) ([]accounts.Account, []uint, error) // Users for a bundle, 2FA seeds decrypted; plus missing IDs.
+	ImportAccounts(ctx context.Context, accts []accounts.Account, overwrite bool) (*models.ImportAccountsResult, error) // Create (or replace) bundle accounts by email.
+	FlushUser(ctx context.Context, id core.UserID) ([]string, error) // Clear cache, sessions, JWTs, login lockout and a pending 2FA enrollment; returns what was cleared.
+}
+
+// UserService is every part, as implemented by NewUserService; handlers take only the part they use.
+type UserService interface {
+	AuthService
+	UserAdminService
+	SetupService
+}
+
+// Errors handlers map to specific HTTP responses.
+var (
+	ErrTwoFactorRequired = errors.New("two-factor code required") // Password ok, TOTP code missing.
+	ErrInvalidTwoFactor  = errors.New("invalid two-factor code") // Wrong/expired/already used TOTP code.
+	ErrTwoFactorUnavailable = errors.New("two-factor check failed") // Secret undecryptable or DB error; details are logged only.
+	ErrWrongPassword     = errors.New("current password is incorrect") // Change-password re-check failed.
+	ErrAccountInactive   = errors.New("account is disabled") // Status is disabled/banned; login refused.
+	ErrLoginLocked       = errors.New("too many failed logins; try again later") // Email locked out; see LoginLockout.
+	ErrUserNotFound      = errors.New("user not found") // No user with that ID (SetStatus).
+)
+
 // userService is the concrete implementation; it depends on repo + Redis + Redis logger.
 type userService struct {
 	repo repositories.UserRepository // Data access abstraction.
 	rdb  *redis.Client // Redis client (may be nil if cache disabled).
 	log  *redislog.Logger // Redis logger (may be nil if not configured).
+
+	totpKey    string // Encrypts TOTP secrets at rest.
+	totpIssuer string // Issuer label shown in authenticator apps.
+
+	idem *idempotency.Store // Idempotency-Key results for Register (nil-safe).
+
+	minPasswordScore int // 0..4; passwords scoring lower are rejected (0 = off).
+	passwords        core.PasswordPolicy // Composition rules (length, classes, banned list).
+
+	sessions *session.Store    // Ended on password change (nil in pure JWT setups).
+	tokens   *revocation.Store // Voids outstanding JWTs on password change (nil-safe).
+
+	jwtKeys *jwtkeys.KeySet // Signs access tokens; nil = HS256 with the jwtSecret passed to Login.
+
+	lifecycle []hooks.UserLifecycle // Deployment plug-ins notified after register/update/delete/login.
+
+	scripts *scripting.Scripts // Registration rules and extra JWT claims from config (nil-safe).
+
+	cacheTTL func() time.Duration // User cache lifetime; read per write so runtime settings apply.
+
+	metrics *metrics.Metrics // User cache hit/miss counters (nil-safe).
+
+	users *readcache.Cache[core.UserID, *models.User] // "user:<id>" read-through cache (no Redis = always the DB).
+
+	known *knownemails.Store // Recently written emails; skips FindByEmail for obvious duplicates (nil-safe).
+
+	jobs jobs.Enqueuer // Background queue for side effects like cache warming (nil = done inline).
+
+	lockout *lockout.Tracker // Failed logins per email; locks the email after too many (nil-safe).
+}
+
+// Option tweaks optional service settings without growing the constructor signature.
+type Option func(*userService)
+
+// WithTwoFactor sets the at-rest encryption key for TOTP secrets and the issuer label.
+func WithTwoFactor(key, issuer string) Option {
+	return func(s *userService) { s.totpKey, s.totpIssuer = key, issuer }
+}
+
+// WithPasswordMinScore enforces a minimum zxcvbn-style score on register and password changes.
+func WithPasswordMinScore(score int) Option {
+	return func(s *userService) { s.minPasswordScore = score }
+}
+
+// WithCredentialRevocation lets a password change end the user's sessions and void their JWTs.
+func WithCredentialRevocation(sessions *session.Store, tokens *revocation.Store) Option {
+	return func(s *userService) { s.sessions, s.tokens = sessions, tokens }
+}
+
+// WithPasswordPolicy replaces the default composition rules (same policy as the "password" binding tag).
+func WithPasswordPolicy(p core.PasswordPolicy) Option {
+	return func(s *userService) { s.passwords = p }
+}
+
+// WithJWTKeys signs tokens with a key set (e.g. RS256 with a kid) instead of the HS256 secret.
+func WithJWTKeys(keys *jwtkeys.KeySet) Option {
+	return func(s *userService) { s.jwtKeys = keys }
+}
+
+// WithLifecycleHooks notifies hs (see hooks.Register) after users are created, updated, deleted or log in.
+func WithLifecycleHooks(hs ...hooks.UserLifecycle) Option {
+	return func(s *userService) { s.lifecycle = append(s.lifecycle, hs...) }
+}
+
+// WithScripts applies configured registration rules and adds scripted claims to issued JWTs.
+func WithScripts(scripts *scripting.Scripts) Option {
+	return func(s *userService) { s.scripts = scripts }
+}
+
+// WithCacheTTL makes the user cache lifetime come from ttl (e.g. runtime settings) instead of
+// userCacheTTL; values <= 0 fall back to the default.
+func WithCacheTTL(ttl func() time.Duration) Option {
+	return func(s *userService) { s.cacheTTL = ttl }
+}
+
+// WithMetrics counts user cache hits and misses on /metrics.
+func WithMetrics(m *metrics.Metrics) Option {
+	return func(s *userService) { s.metrics = m }
+}
+
+// WithKnownEmails lets Register/ImportUsers/UpdateUser reject emails the store knows are taken
+// without asking the database; the service keeps the store current on every write.
+func WithKnownEmails(known *knownemails.Store) Option {
+	return func(s *userService) { s.known = known }
+}
+
+// WithLoginLockout locks an email out of login for a while after repeated failures.
+func WithLoginLockout(t *lockout.Tracker) Option {
+	return func(s *userService) { s.lockout = t }
+}
+
+// WithJobs moves slow side effects (warming the cache after Register) to the background queue,
+// registering their handlers on q.
+func WithJobs(q *jobs.Queue) Option {
+	return func(s *userService) {
+		s.jobs = q
+		q.Handle(JobWarmUserCache, s.warmUserCache)
+	}
 }
 
 // NewUserService constructs a service with all dependencies injected.
-func NewUserService(repo repositories.UserRepository, rdb *redis.Client, rlog *redislog.Logger) UserService {
-	return &userService{repo: repo, rdb: rdb, log: rlog} // Return a struct implementing the interface.
+func NewUserService(repo repositories.UserRepository, rdb *redis.Client, rlog *redislog.Logger, opts ...Option) UserService {
+	s := &userService{repo: repo, rdb: rdb, log: rlog, totpIssuer: "HelmyTask", passwords: core.DefaultPasswordPolicy(),
+		idem: idempotency.New(rdb, "idem:register:", idempotencyKeyTTL)}
+	for _, opt := range opts {
+		opt(s) // Apply optional settings.
+	}
+	cacheOpts := readcache.Options{Log: rlog}
+	if s.metrics != nil { // no typed-nil Recorder
+		cacheOpts.Metrics = s.metrics
+	}
+	s.users = readcache.New[core.UserID, *models.User](rdb, "user", s.userTTL, cacheOpts)
+	return s // Return a struct implementing the interface.
 }
 
 // userCacheTTL is how long a cached user stays in Redis before expiring.
 const userCacheTTL = 10 * time.Minute // Adjust based on your read/write pattern.
 
-// cacheKeyUser formats a consistent Redis key for a user's cached JSON.
-func (s *userService) cacheKeyUser(id uint) string {
-	return fmt.Sprintf("user:%d", id) // e.g., "user:42".
+// registerRetryWindow: a repeat Register with the same email+password inside this window is
+// treated as a client retry (flaky mobile network) and returns the account created moments ago.
+const registerRetryWindow = 2 * time.Minute
+
+// idempotencyKeyTTL is how long an Idempotency-Key result is remembered.
+const idempotencyKeyTTL = 24 * time.Hour
+
+// userTTL is the lifetime for a cached user.
+func (s *userService) userTTL() time.Duration {
+	if s.cacheTTL != nil {
+		if d := s.cacheTTL(); d > 0 {
+			return d
+		}
+	}
+	return userCacheTTL
 }
 
 // ---------------- Auth & single read ----------------
 
 // Register creates a new user (after checking email uniqueness), hashes password, and warms cache.
-func (s *userService) Register(req models.RegisterRequest) (*models.User, error) {
+func (s *userService) Register(ctx context.Context, req models.RegisterRequest) (*models.User, error) {
+	// Replay: same Idempotency-Key as an earlier successful call → same user back.
+	if u := s.replayRegister(ctx, req); u != nil {
+		return u, nil
+	}
+
+	email, err := s.validateNewUser(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Known duplicate that is too old to be a client retry: no DB round trip needed.
+	if added, ok := s.knownEmails(ctx, email)[email.String()]; ok && time.Since(added) > registerRetryWindow {
+		if s.log != nil { s.log.Warn("register email exists", map[string]string{"email": req.Email, "source": "known_emails"}) }
+		return nil, errors.New("email already exists")
+	}
+
 	// Check for existing email to maintain uniqueness.
-	if _, err := s.repo.FindByEmail(req.Email); err == nil { // If no error, a row with that email exists.
+	if existing, err := s.repo.FindByEmail(ctx, email); err == nil { // If no error, a row with that email exists.
+		// Retry of a registration that already went through (same password, just created)?
+		if time.Since(existing.CreatedAt) <= registerRetryWindow && utils.CheckPassword(existing.Password, req.Password) {
+			if s.log != nil { s.log.Info("register retry returned existing user", map[string]string{"user_id": fmt.Sprint(existing.ID)}) }
+			s.rememberRegister(ctx, req, existing)
+			return existing, nil
+		}
 		if s.log != nil { s.log.Warn("register email exists", map[string]string{"email": req.Email}) } // Log to Redis.
 		return nil, errors.New("email already exists") // Return a friendly message for the handler.
 	}
@@ -72,55 +271,238 @@ func (s *userService) Register(req models.RegisterRequest) (*models.User, error)
 	// Build the new User entity (domain-normalized name).
 	u := &models.User{
 		Name:     core.NormalizeName(req.Name), // Apply any naming rules (e.g., capitalize).
-		Email:    req.Email, // Store unique email.
+		Email:    email.String(), // Store unique (canonical) email.
 		Password: hash, // Store hashed password, not plaintext.
+		Role:     models.RoleUser, // Everyone starts unprivileged; admins promote via UpdateUser.
+		Status:   models.StatusActive, // Until an admin disables or bans the account.
 	}
 
 	// Insert into the database.
-	if err := s.repo.Create(u); err != nil { // Will set u.ID on success.
+	if err := s.repo.Create(ctx, u); err != nil { // Will set u.ID on success.
 		if s.log != nil { s.log.Error("register db create error", map[string]string{"email": req.Email, "err": err.Error()}) }
 		return nil, err
 	}
+	s.rememberEmails(ctx, u.Email)
 
-	// Optionally warm cache: write the JSON into Redis so the first /me is a HIT.
-	if s.rdb != nil { // Only if Redis is configured.
-		ctx := context.Background() // Use a background context for one-off calls.
-		if b, _ := json.Marshal(u); len(b) > 0 { // Marshal struct -> JSON bytes.
-			_ = s.rdb.Set(ctx, s.cacheKeyUser(u.ID), b, userCacheTTL).Err() // SET key value EX ttl
-			if s.log != nil { s.log.Info("cache warm after register", map[string]string{"key": s.cacheKeyUser(u.ID), "user_id": fmt.Sprint(u.ID)}) }
-		}
-	}
+	// Warm the cache so the first /me is a HIT (in the background when there is a job queue).
+	s.warmCache(ctx, u)
+
+	s.rememberRegister(ctx, req, u) // Let retries carrying the same Idempotency-Key replay this result.
+	s.notify(ctx, "registered", func(ctx context.Context, h hooks.UserLifecycle) { h.OnRegistered(ctx, *u) })
 
 	// Log final success of the registration flow.
 	if s.log != nil { s.log.Info("register success", map[string]string{"user_id": fmt.Sprint(u.ID), "email": u.Email}) }
 	return u, nil // Return created user (password omitted in JSON due to json:"-").
 }
 
-// Login validates credentials and issues a signed JWT.
-func (s *userService) Login(req models.LoginRequest, jwtSecret string, exp time.Duration) (string, error) {
+// JobWarmUserCache is the job type that loads a user into the cache (payload {"user_id"}).
+const JobWarmUserCache = "user.cache_warm"
+
+type warmUserCacheJob struct {
+	UserID uint `json:"user_id"`
+}
+
+// warmCache caches u now, or enqueues a job to load it when there is a queue.
+func (s *userService) warmCache(ctx context.Context, u *models.User) {
+	if s.jobs == nil {
+		s.users.Set(ctx, core.UserID(u.ID), u)
+		return
+	}
+	if err := s.jobs.Enqueue(ctx, JobWarmUserCache, warmUserCacheJob{UserID: u.ID}); err != nil {
+		if s.log != nil { s.log.Warn("cache warm enqueue failed", map[string]string{"user_id": fmt.Sprint(u.ID), "err": err.Error()}) } // the first read fills it instead
+	}
+}
+
+// warmUserCache handles JobWarmUserCache: reads the user from the DB so the cached copy is
+// current even if the job runs late.
+func (s *userService) warmUserCache(ctx context.Context, payload json.RawMessage) error {
+	var job warmUserCacheJob
+	if err := json.Unmarshal(payload, &job); err != nil {
+		return jobs.Permanent(err)
+	}
+	u, err := s.repo.FindByID(ctx, core.UserID(job.UserID))
+	if repositories.IsNotFound(err) {
+		return nil // deleted since: nothing to warm
+	}
+	if err != nil {
+		return err
+	}
+	s.users.Set(ctx, core.UserID(u.ID), u)
+	return nil
+}
+
+// validateNewUser applies every rule a new account must pass (Register, CreateUser, ImportUsers)
+// and returns the canonical email.
+func (s *userService) validateNewUser(ctx context.Context, req models.RegisterRequest) (core.Email, error) {
+	// Domain rules (same for HTTP, imports, CLI...): name charset/reserved, email, password.
+	if err := core.ValidateRegistration(core.NormalizeName(req.Name), req.Email, req.Password, s.passwords).Err(); err != nil {
+		if s.log != nil { s.log.Warn("register validation failed", map[string]string{"email": req.Email, "err": err.Error()}) }
+		return "", err
+	}
+	if err := core.ValidatePasswordStrength(req.Password, s.minPasswordScore, req.Name, req.Email).Err(); err != nil {
+		return "", err
+	}
+	ruled, err := s.scripts.ValidateRegistration(core.NormalizeName(req.Name), req.Email) // Deployment-specific rules.
+	if err != nil {
+		if s.log != nil { s.log.Error("register rule script error", map[string]string{"email": req.Email, "err": err.Error()}) }
+		return "", err
+	}
+	if err := ruled.Err(); err != nil {
+		if s.log != nil { s.log.Warn("register rejected by rule", map[string]string{"email": req.Email, "err": err.Error()}) }
+		return "", err
+	}
+	email, _ := core.ParseEmail(req.Email) // Already validated above; canonicalizes the domain.
+	return email, nil
+}
+
+// replayRegister returns the user recorded for req.IdempotencyKey, or nil if there is none.
+// The stored value is "email|id" so a key can't be replayed to fetch someone else's account.
+func (s *userService) replayRegister(ctx context.Context, req models.RegisterRequest) *models.User {
+	val, ok, err := s.idem.Get(ctx, req.IdempotencyKey)
+	if err != nil || !ok {
+		return nil // Unknown key (or Redis trouble): fall through to the normal path.
+	}
+	email, idStr, found := strings.Cut(val, "|")
+	want, err := core.ParseEmail(req.Email) // Stored value is the canonical form.
+	if !found || err != nil || email != want.String() {
+		return nil
+	}
+	id, err := core.ParseUserID(idStr)
+	if err != nil {
+		return nil
+	}
+	u, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil
+	}
+	if s.log != nil { s.log.Info("register replayed via idempotency key", map[string]string{"user_id": idStr}) }
+	return u
+}
+
+// rememberRegister stores the outcome under the request's Idempotency-Key (best-effort).
+func (s *userService) rememberRegister(ctx context.Context, req models.RegisterRequest, u *models.User) {
+	_ = s.idem.Put(context.WithoutCancel(ctx), req.IdempotencyKey, fmt.Sprintf("%s|%d", u.Email, u.ID))
+}
+
+// Authenticate checks the password (and TOTP code when 2FA is on) and returns the user.
+// It issues nothing, so JWT and session modes share the exact same checks.
+func (s *userService) Authenticate(ctx context.Context, req models.LoginRequest) (*models.User, error) {
 	// Look up by email; return invalid on any error (don't leak info).
-	u, err := s.repo.FindByEmail(req.Email)
+	email := core.NormalizeEmail(req.Email) // Not validated: an address that was never valid just matches no account.
+	if email == "" {
+		return nil, errors.New("invalid credentials")
+	}
+	if wait := s.LoginLockout(ctx, email.String()); wait > 0 { // Checked first: no password check while locked.
+		if s.log != nil { s.log.Warn("login locked out", map[string]string{"email": req.Email, "retry_after": wait.Round(time.Second).String()}) }
+		return nil, ErrLoginLocked
+	}
+	u, err := s.repo.FindByEmail(ctx, email)
+	if err != nil && ctx.Err() != nil { // Caller gave up (e.g. a probe timeout): not a failed guess.
+		return nil, ctx.Err()
+	}
 	if err != nil { // If not found or DB error, treat as invalid.
 		if s.log != nil { s.log.Warn("login user not found", map[string]string{"email": req.Email}) }
-		return "", errors.New("invalid credentials")
+		return nil, s.loginFailed(ctx, email, errors.New("invalid credentials")) // Counted like a wrong password, so lockouts don't reveal accounts.
 	}
 	// Verify supplied password against stored bcrypt hash.
 	if !utils.CheckPassword(u.Password, req.Password) {
 		if s.log != nil { s.log.Warn("login wrong password", map[string]string{"email": req.Email}) }
-		return "", errors.New("invalid credentials")
+		return nil, s.loginFailed(ctx, email, errors.New("invalid credentials"))
+	}
+	// Checked after the password so the status isn't revealed to someone guessing it.
+	if !u.IsActive() {
+		if s.log != nil { s.log.Warn("login inactive account", map[string]string{"user_id": fmt.Sprint(u.ID), "status": u.Status}) }
+		return nil, ErrAccountInactive
+	}
+
+	// Second step: when 2FA is on, the password alone is not enough.
+	if u.TOTPEnabled {
+		if req.Code == "" { // Tell the client to prompt for the authenticator code.
+			return nil, ErrTwoFactorRequired
+		}
+		err := s.repo.WithTx(ctx, func(repo repositories.UserRepository) error { // Locked: two logins can't both spend one code.
+			locked, err := repo.FindByID(ctx, core.UserID(u.ID))
+			if err != nil {
+				return err
+			}
+			if err := s.checkTOTP(locked, req.Code); err != nil {
+				return err
+			}
+			return repo.Update(ctx, locked)
+		})
+		if errors.Is(err, ErrInvalidTwoFactor) {
+			if s.log != nil { s.log.Warn("login wrong totp code", map[string]string{"email": req.Email}) }
+			return nil, s.loginFailed(ctx, email, ErrInvalidTwoFactor)
+		}
+		if err != nil {
+			if !errors.Is(err, ErrTwoFactorUnavailable) && s.log != nil { s.log.Error("login totp db error", map[string]string{"user_id": fmt.Sprint(u.ID), "err": err.Error()}) }
+			return nil, ErrTwoFactorUnavailable
+		}
+	}
+	if err := s.lockout.Reset(ctx, email.String()); err != nil && s.log != nil {
+		s.log.Warn("login lockout reset failed", map[string]string{"user_id": fmt.Sprint(u.ID), "err": err.Error()})
+	}
+	s.notify(ctx, "login", func(ctx context.Context, h hooks.UserLifecycle) { h.OnLogin(ctx, *u) })
+	return u, nil
+}
+
+// LoginLockout returns how long email stays locked out of login (0 = not locked, also for
+// malformed addresses and when Redis can't tell: an outage must not lock everyone out).
+func (s *userService) LoginLockout(ctx context.Context, email string) time.Duration {
+	e := core.NormalizeEmail(email) // Keyed like Authenticate counts failures.
+	if e == "" {
+		return 0
+	}
+	wait, err := s.lockout.Locked(ctx, e.String())
+	if err != nil && s.log != nil {
+		s.log.Warn("login lockout check failed", map[string]string{"err": err.Error()})
+	}
+	return wait
+}
+
+// loginFailed counts a failed login for email and returns err, or ErrLoginLocked if this
+// failure locked the email.
+func (s *userService) loginFailed(ctx context.Context, email core.Email, err error) error {
+	locked, ferr := s.lockout.Fail(ctx, email.String())
+	if ferr != nil {
+		if s.log != nil { s.log.Warn("login failure not counted", map[string]string{"err": ferr.Error()}) }
+		return err
+	}
+	if locked > 0 {
+		if s.log != nil { s.log.Warn("login email locked", map[string]string{"email": email.String(), "for": locked.String()}) }
+		return ErrLoginLocked
+	}
+	return err
+}
+
+// Login validates credentials and issues a signed JWT.
+func (s *userService) Login(ctx context.Context, req models.LoginRequest, jwtSecret string, exp time.Duration) (string, error) {
+	u, err := s.Authenticate(ctx, req) // Password + optional TOTP step.
+	if err != nil {
+		return "", err
 	}
 
 	// Build JWT claims (subject, issued-at, expiration, plus optional email).
 	claims := jwt.MapClaims{
 		"sub": u.ID, // Subject: user ID.
 		"exp": time.Now().Add(exp).Unix(), // Expiration time (unix seconds).
-		"iat": time.Now().Unix(), // Issued-at (unix seconds).
+		"iat": float64(time.Now().UnixMilli()) / 1000, // Issued-at (unix seconds, to the millisecond so revocation can tell apart tokens of the same second).
 		"eml": u.Email, // Optional claim to carry email.
+		"rol": roleOrDefault(u.Role), // Role consumed by the policy engine in middlewares.
+	}
+	extra, err := s.scripts.Claims(*u) // Scripted claims; reserved names are refused at boot.
+	if err != nil { // A broken claim script only drops that claim.
+		if s.log != nil { s.log.Error("login claim script error", map[string]string{"user_id": fmt.Sprint(u.ID), "err": err.Error()}) }
+	}
+	for k, v := range extra {
+		claims[k] = v
+	}
+	// Sign with the configured key set (RS256 + kid), else HS256 with the shared secret.
+	keys := s.jwtKeys
+	if keys == nil {
+		keys = jwtkeys.NewHMAC(jwtSecret)
 	}
-	// Create a token with HS256 signing method.
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	// Sign the token with the shared secret.
-	signed, err := token.SignedString([]byte(jwtSecret))
+	signed, err := keys.Sign(claims)
 	if err != nil { // Log and propagate signing error.
 		if s.log != nil { s.log.Error("login token sign error", map[string]string{"email": u.Email, "err": err.Error()}) }
 		return "", err
@@ -131,155 +513,596 @@ func (s *userService) Login(req models.LoginRequest, jwtSecret string, exp time.
 	return signed, nil // Return compact JWT string.
 }
 
+// roleOrDefault treats rows created before roles existed as plain users.
+func roleOrDefault(role string) string {
+	if role == "" {
+		return models.RoleUser
+	}
+	return role
+}
+
 // GetByID returns a user, preferring Redis cache and falling back to DB.
-func (s *userService) GetByID(id uint) (*models.User, error) {
-	// Try Redis first for speed.
-	if s.rdb != nil { // Only if Redis configured.
-		ctx := context.Background() // Context needed for Redis commands.
-		key := s.cacheKeyUser(id) // Compose key like "user:1".
-		if s.log != nil { s.log.Info("cache try GET", map[string]string{"key": key, "user_id": fmt.Sprint(id)}) }
-
-		val, err := s.rdb.Get(ctx, key).Result() // Attempt GET.
-		if err == nil { // Found a value (string).
-			var u models.User // Destination struct.
-			if json.Unmarshal([]byte(val), &u) == nil { // Decode JSON → struct.
-				if s.log != nil { s.log.Info("cache HIT", map[string]string{"key": key, "user_id": fmt.Sprint(id)}) }
-				return &u, nil // Return cached result immediately.
-			}
-			// If unmarshal failed, ignore cache and continue to DB.
-			if s.log != nil { s.log.Warn("cache unmarshal failed", map[string]string{"key": key}) }
-		} else if err == redis.Nil { // Key not present → MISS.
-			if s.log != nil { s.log.Warn("cache MISS", map[string]string{"key": key, "user_id": fmt.Sprint(id)}) }
-		} else { // Some other Redis error occurred.
-			if s.log != nil { s.log.Error("cache GET error", map[string]string{"key": key, "err": err.Error()}) }
+func (s *userService) GetByID(ctx context.Context, id core.UserID) (*models.User, error) {
+	return s.users.Get(ctx, id, func(ctx context.Context, id core.UserID) (*models.User, error) {
+		u, err := s.repo.FindByID(ctx, id) // Cache miss (or no Redis): query DB.
+		if err != nil { // Not found or DB error → propagate; nothing is cached.
+			if s.log != nil { s.log.Error("db fetch error in GetByID", map[string]string{"user_id": fmt.Sprint(id), "err": err.Error()}) }
+			return nil, err
+		}
+		if s.log != nil { s.log.Info("db fetch success in GetByID", map[string]string{"user_id": fmt.Sprint(id)}) }
+		return u, nil
+	})
+}
+
+// ---------------- CRUD ----------------
+
+// CreateUser — admin-style create; use same semantics as Register.
+func (s *userService) CreateUser(ctx context.Context, req models.RegisterRequest) (*models.User, error) {
+	if s.log != nil { s.log.Info("CreateUser called", map[string]string{"email": req.Email}) } // Trace call.
+	return s.Register(ctx, req) // Reuse register path for uniqueness & hashing logic.
+}
+
+// importChunkSize is how many validated rows go into one CreateBatch transaction; a failing
+// chunk (e.g. an email registered concurrently) only costs those rows.
+const importChunkSize = 100
+
+// ImportUsers creates users from an uploaded file's rows. Each row passes the same rules as
+// Register; rows that fail (invalid, duplicate in the file, email taken) are reported and
+// skipped, the rest are inserted in batches. Imported users start as RoleUser.
+func (s *userService) ImportUsers(ctx context.Context, rows []models.RegisterRequest) (*models.ImportUsersResult, error) {
+	if s.log != nil { s.log.Info("ImportUsers called", map[string]string{"rows": fmt.Sprint(len(rows))}) } // Trace call.
+
+	res := &models.ImportUsersResult{Total: len(rows), Errors: []models.ImportRowError{}}
+	fail := func(row int, req models.RegisterRequest, err error) {
+		e := models.ImportRowError{Row: row, Email: req.Email, Error: err.Error()}
+		var v core.Violations
+		if errors.As(err, &v) {
+			e.Error, e.Violations = "validation failed", v
 		}
+		res.Errors = append(res.Errors, e)
 	}
 
-	// Fallback to DB if cache did not return a valid user.
-	u, err := s.repo.FindByID(id) // Query DB.
-	if err != nil { // Not found or DB error → propagate.
-		if s.log != nil { s.log.Error("db fetch error in GetByID", map[string]string{"user_id": fmt.Sprint(id), "err": err.Error()}) }
-		return nil, err
+	var pending []models.User
+	var pendingRows []int
+	seen := map[core.Email]int{} // canonical email -> first row using it
+	type candidate struct {
+		row   int
+		req   models.RegisterRequest
+		email core.Email
 	}
-	if s.log != nil { s.log.Info("db fetch success in GetByID", map[string]string{"user_id": fmt.Sprint(id)}) }
-
-	// Store result in cache for next time.
-	if s.rdb != nil { // Only if Redis configured.
-		ctx := context.Background() // Redis context.
-		key := s.cacheKeyUser(id) // Cache key again.
-		if b, _ := json.Marshal(u); len(b) > 0 { // Marshal user to JSON.
-			if err := s.rdb.Set(ctx, key, b, userCacheTTL).Err(); err == nil { // SET key value with TTL.
-				if s.log != nil { s.log.Info("cache SET", map[string]string{"key": key, "user_id": fmt.Sprint(id), "ttl": userCacheTTL.String()}) }
-			} else { // Log cache SET failure if it happens.
-				if s.log != nil { s.log.Error("cache SET error", map[string]string{"key": key, "err": err.Error()}) }
+	var candidates []candidate // valid and unique within the file
+	for i, req := range rows {
+		row := i + 1
+		email, err := s.validateNewUser(ctx, req)
+		if err != nil {
+			fail(row, req, err)
+			continue
+		}
+		if first, dup := seen[email]; dup {
+			fail(row, req, fmt.Errorf("duplicate email (same as row %d)", first))
+			continue
+		}
+		seen[email] = row
+		candidates = append(candidates, candidate{row, req, email})
+	}
+
+	// One Redis round trip turns away emails known to be taken; the rest still go to the DB.
+	emails := make([]core.Email, 0, len(candidates))
+	for _, c := range candidates {
+		emails = append(emails, c.email)
+	}
+	known := s.knownEmails(ctx, emails...)
+	for n, c := range candidates {
+		if err := ctx.Err(); err != nil { // Timed out or client gone: the rest isn't attempted.
+			for _, c := range candidates[n:] {
+				fail(c.row, c.req, fmt.Errorf("not imported: %w", err))
 			}
+			break
+		}
+		if _, taken := known[c.email.String()]; taken {
+			fail(c.row, c.req, errors.New("email already exists"))
+			continue
+		}
+		if _, err := s.repo.FindByEmail(ctx, c.email); err == nil {
+			fail(c.row, c.req, errors.New("email already exists"))
+			continue
+		} else if !repositories.IsNotFound(err) { // Can't tell whether it's taken.
+			if s.log != nil { s.log.Error("ImportUsers lookup error", map[string]string{"email": c.email.String(), "err": err.Error()}) }
+			fail(c.row, c.req, fmt.Errorf("email lookup failed: %w", err))
+			continue
 		}
+		hash, err := utils.HashPassword(c.req.Password)
+		if err != nil {
+			fail(c.row, c.req, err)
+			continue
+		}
+		pending = append(pending, models.User{Name: core.NormalizeName(c.req.Name), Email: c.email.String(), Password: hash, Role: models.RoleUser, Status: models.StatusActive})
+		pendingRows = append(pendingRows, c.row)
 	}
-	return u, nil // Return the DB result.
-}
 
-// ---------------- CRUD ----------------
+	res.Users = s.insertBatches(ctx, "ImportUsers", pending, pendingRows, func(row int, err error) { fail(row, rows[row-1], err) })
+	sort.Slice(res.Errors, func(i, j int) bool { return res.Errors[i].Row < res.Errors[j].Row })
+	res.Created = len(res.Users)
 
-// CreateUser — admin-style create; use same semantics as Register.
-func (s *userService) CreateUser(req models.RegisterRequest) (*models.User, error) {
-	if s.log != nil { s.log.Info("CreateUser called", map[string]string{"email": req.Email}) } // Trace call.
-	return s.Register(req) // Reuse register path for uniqueness & hashing logic.
+	for _, u := range res.Users {
+		u := u
+		s.notify(ctx, "registered", func(ctx context.Context, h hooks.UserLifecycle) { h.OnRegistered(ctx, u) })
+	}
+
+	if s.log != nil { s.log.Info("ImportUsers done", map[string]string{"created": fmt.Sprint(res.Created), "failed": fmt.Sprint(len(res.Errors))}) }
+	return res, nil
+}
+
+// insertBatches inserts pending (from the given 1-based rows) in importChunkSize transactions
+// and returns the users created. Rows of a chunk that fails are reported through fail.
+func (s *userService) insertBatches(ctx context.Context, op string, pending []models.User, rows []int, fail func(row int, err error)) []models.User {
+	var created []models.User
+	for start := 0; start < len(pending); start += importChunkSize {
+		end := start + importChunkSize
+		if end > len(pending) {
+			end = len(pending)
+		}
+		chunk := pending[start:end]
+		if err := ctx.Err(); err != nil { // Not attempted: the request is over.
+			for k := start; k < end; k++ {
+				fail(rows[k], fmt.Errorf("not imported: %w", err))
+			}
+			continue
+		}
+		if err := s.repo.CreateBatch(ctx, chunk); err != nil { // Whole chunk rolled back.
+			if s.log != nil { s.log.Error(op+" batch insert error", map[string]string{"rows": fmt.Sprintf("%d-%d", rows[start], rows[end-1]), "err": err.Error()}) }
+			for k := start; k < end; k++ {
+				fail(rows[k], fmt.Errorf("insert failed: %w", err))
+			}
+			continue
+		}
+		created = append(created, chunk...)
+		emails := make([]string, 0, len(chunk))
+		for _, u := range chunk {
+			emails = append(emails, u.Email)
+		}
+		s.rememberEmails(ctx, emails...)
+	}
+	return created
 }
 
 // GetUser — explicit method name for CRUD; same as GetByID.
-func (s *userService) GetUser(id uint) (*models.User, error) {
+func (s *userService) GetUser(ctx context.Context, id core.UserID) (*models.User, error) {
 	if s.log != nil { s.log.Info("GetUser called", map[string]string{"user_id": fmt.Sprint(id)}) } // Trace call.
-	return s.GetByID(id) // Reuse existing cache-aware read.
+	return s.GetByID(ctx, id) // Reuse existing cache-aware read.
 }
 
 // UpdateUser applies partial updates; re-hashes password if provided; refreshes cache.
-func (s *userService) UpdateUser(id uint, req models.UpdateUserRequest) (*models.User, error) {
+func (s *userService) UpdateUser(ctx context.Context, id core.UserID, req models.UpdateUserRequest) (*models.User, error) {
 	if s.log != nil { s.log.Info("UpdateUser called", map[string]string{"user_id": fmt.Sprint(id)}) } // Trace call.
 
-	// Load current user state.
-	u, err := s.repo.FindByID(id)
+	// Read, check and write in one transaction: the row stays locked until the update commits,
+	// so concurrent edits of the same user apply one after the other instead of overwriting.
+	var u *models.User
+	var before models.User
+	emailChanged, revoke := false, false
+	err := s.repo.WithTx(ctx, func(repo repositories.UserRepository) error {
+		var err error
+		// Load current user state.
+		u, err = repo.FindByID(ctx, id)
+		if err != nil {
+			if s.log != nil { s.log.Error("UpdateUser not found", map[string]string{"user_id": fmt.Sprint(id), "err": err.Error()}) }
+			return err
+		}
+		before = *u // For the audit diff.
+
+		// Validate only the fields being changed.
+		var violations core.Violations
+		if req.Name != nil {
+			violations = append(violations, core.ValidateName(core.NormalizeName(*req.Name))...)
+		}
+		if req.Email != nil {
+			violations = append(violations, core.ValidateEmail(*req.Email)...)
+		}
+		if req.Password != nil {
+			violations = append(violations, s.passwords.Validate(*req.Password)...)
+			violations = append(violations, core.ValidatePasswordStrength(*req.Password, s.minPasswordScore, u.Name, u.Email)...)
+		}
+		if req.DateOfBirth != nil { // Format is checked at bind time; the range needs a clock.
+			violations = append(violations, core.ValidateDateOfBirth(*req.DateOfBirth, time.Now())...)
+		}
+		if err := violations.Err(); err != nil {
+			return err
+		}
+
+		// Apply provided changes.
+		if req.Name != nil { // Update name if provided.
+			u.Name = core.NormalizeName(*req.Name) // Normalize new name.
+		}
+		if req.Email != nil { // If email change requested...
+			email, _ := core.ParseEmail(*req.Email) // Validated above.
+			if email.String() != u.Email { // Only if it's different.
+				_, taken := s.knownEmails(ctx, email)[email.String()] // Redis first...
+				if !taken {
+					_, err := repo.FindByEmail(ctx, email) // ...then the DB.
+					taken = err == nil
+				}
+				if taken {
+					if s.log != nil { s.log.Warn("UpdateUser email exists", map[string]string{"email": *req.Email}) }
+					return errors.New("email already exists") // Abort on conflict.
+				}
+				u.Email = email.String() // Apply new email.
+				u.EmailUndeliverable = false // Bounces and complaints were about the old address.
+				emailChanged = true
+			}
+		}
+		if req.Password != nil { // If new password provided...
+			hash, err := utils.HashPassword(*req.Password) // Hash it.
+			if err != nil {
+				if s.log != nil { s.log.Error("UpdateUser hash error", map[string]string{"user_id": fmt.Sprint(id), "err": err.Error()}) }
+				return err
+			}
+			u.Password = hash // Store hashed password.
+		}
+
+		// Profile fields ("" clears).
+		for dst, src := range map[*string]*string{&u.Phone: req.Phone, &u.Bio: req.Bio, &u.DateOfBirth: req.DateOfBirth, &u.Locale: req.Locale, &u.Timezone: req.Timezone} {
+			if src != nil {
+				*dst = strings.TrimSpace(*src)
+			}
+		}
+
+		if req.Role != nil { // Role change (route is admin-guarded by the policy engine).
+			if !policy.ValidRole(*req.Role) {
+				return errors.New("invalid role")
+			}
+			revoke = revoke || u.Role != *req.Role // Tokens carry the role: old ones would keep the old rights.
+			u.Role = *req.Role
+		}
+		if req.Status != nil { // Admin-only, like Role.
+			revoke = revoke || (u.IsActive() && *req.Status != models.StatusActive)
+			u.Status = *req.Status
+		}
+
+		// Persist the update, and its audit entry with it.
+		if err := repo.Update(ctx, u); err != nil { // Write to DB.
+			if s.log != nil { s.log.Error("UpdateUser db error", map[string]string{"user_id": fmt.Sprint(id), "err": err.Error()}) }
+			return err
+		}
+		return recordPending(ctx, repo, &before, u)
+	})
 	if err != nil {
-		if s.log != nil { s.log.Error("UpdateUser not found", map[string]string{"user_id": fmt.Sprint(id), "err": err.Error()}) }
 		return nil, err
 	}
+	if emailChanged { // The old address is free again.
+		s.forgetEmails(ctx, "UpdateUser")
+		s.rememberEmails(ctx, u.Email)
+	}
+
+	// Refresh cache: drop the old value (running the invalidation hooks) and set the new one.
+	s.users.Invalidate(ctx, id)
+	s.users.Set(ctx, id, u)
 
-	// Apply provided changes.
-	if req.Name != nil { // Update name if provided.
-		u.Name = core.NormalizeName(*req.Name) // Normalize new name.
+	if revoke {
+		s.revokeLogins(ctx, id, "UpdateUser")
 	}
-	if req.Email != nil { // If email change requested...
-		if *req.Email != u.Email { // Only if it's different.
-			if _, err := s.repo.FindByEmail(*req.Email); err == nil { // Check uniqueness.
-				if s.log != nil { s.log.Warn("UpdateUser email exists", map[string]string{"email": *req.Email}) }
-				return nil, errors.New("email already exists") // Abort on conflict.
-			}
-			u.Email = *req.Email // Apply new email.
+
+	s.notify(ctx, "updated", func(ctx context.Context, h hooks.UserLifecycle) { h.OnUpdated(ctx, *u) })
+	// Return updated user.
+	return u, nil
+}
+
+// SetStatus moves an account to active, disabled or banned. Leaving active also ends every
+// session and voids outstanding JWTs; API keys stop working because their owner is checked.
+func (s *userService) SetStatus(ctx context.Context, id core.UserID, status, reason string) (*models.User, error) {
+	if s.log != nil { s.log.Info("SetStatus called", map[string]string{"user_id": fmt.Sprint(id), "status": status}) } // Trace call.
+	switch status {
+	case models.StatusActive, models.StatusDisabled, models.StatusBanned:
+	default:
+		return nil, fmt.Errorf("invalid status %q", status)
+	}
+	var u *models.User
+	changed, wasActive := false, false
+	err := s.repo.WithTx(ctx, func(repo repositories.UserRepository) error { // Locked read-modify-write, as in UpdateUser.
+		var err error
+		if u, err = repo.FindByID(ctx, id); repositories.IsNotFound(err) {
+			return ErrUserNotFound
+		} else if err != nil {
+			return err
 		}
+		before := *u
+		if u.Status == status { // Already there; nothing to revoke or notify, but the attempt is audited.
+			return recordPending(ctx, repo, &before, u)
+		}
+		changed, wasActive = true, u.IsActive()
+		u.Status = status
+		if err := repo.Update(ctx, u); err != nil {
+			if s.log != nil { s.log.Error("SetStatus db error", map[string]string{"user_id": fmt.Sprint(id), "err": err.Error()}) }
+			return err
+		}
+		return recordPending(ctx, repo, &before, u)
+	})
+	if err != nil {
+		return nil, err
 	}
-	if req.Password != nil { // If new password provided...
-		hash, err := utils.HashPassword(*req.Password) // Hash it.
-		if err != nil {
-			if s.log != nil { s.log.Error("UpdateUser hash error", map[string]string{"user_id": fmt.Sprint(id), "err": err.Error()}) }
-			return nil, err
+	if !changed {
+		return u, nil
+	}
+	s.users.Invalidate(ctx, id) // Best-effort; next read reloads.
+	if wasActive && !u.IsActive() {
+		s.revokeLogins(ctx, id, "SetStatus")
+	}
+	s.notify(ctx, "updated", func(ctx context.Context, h hooks.UserLifecycle) { h.OnUpdated(ctx, *u) })
+	if s.log != nil { s.log.Info("SetStatus success", map[string]string{"user_id": fmt.Sprint(id), "status": status, "reason": reason}) }
+	return u, nil
+}
+
+// recordPending inserts the audit entry the caller attached to ctx (audit.WithPending), if any,
+// through repo: inside WithTx it commits, or rolls back, together with the change it describes.
+func recordPending(ctx context.Context, repo repositories.UserRepository, before, after *models.User) error {
+	e, ok := audit.Pending(ctx)
+	if !ok {
+		return nil
+	}
+	e.Before, e.After = before, after
+	entry := audit.Entry(e)
+	return repo.Audit().Create(&entry)
+}
+
+// revokeLogins ends the user's sessions and voids their JWTs. Failures are logged, not returned:
+// the change that called for it is already committed.
+func (s *userService) revokeLogins(ctx context.Context, id core.UserID, op string) {
+	ctx = context.WithoutCancel(ctx) // Must finish even if the client has gone.
+	if err := s.tokens.RevokeAll(ctx, uint(id)); err != nil {
+		if s.log != nil { s.log.Error(op+" token revoke error", map[string]string{"user_id": fmt.Sprint(id), "err": err.Error()}) }
+	}
+	if s.sessions != nil {
+		if err := s.sessions.DeleteAllForUser(ctx, uint(id)); err != nil {
+			if s.log != nil { s.log.Error(op+" session revoke error", map[string]string{"user_id": fmt.Sprint(id), "err": err.Error()}) }
 		}
-		u.Password = hash // Store hashed password.
 	}
+}
+
+// FlushUser is the support tool for a user stuck in odd state: it drops the cached copy, ends
+// every session and voids outstanding JWTs (the user logs in again), lifts a failed-login
+// lockout of their email, and discards a 2FA enrollment that was started but never confirmed. Unlike revokeLogins, a revocation failure
+// is returned: the caller asked for exactly this. It returns the names of what was cleared.
+func (s *userService) FlushUser(ctx context.Context, id core.UserID) ([]string, error) {
+	if s.log != nil { s.log.Info("FlushUser called", map[string]string{"user_id": fmt.Sprint(id)}) } // Trace call.
 
-	// Persist the update.
-	if err := s.repo.Update(u); err != nil { // Write to DB.
-		if s.log != nil { s.log.Error("UpdateUser db error", map[string]string{"user_id": fmt.Sprint(id), "err": err.Error()}) }
+	u, err := s.repo.FindByID(ctx, id)
+	if err != nil {
 		return nil, err
 	}
-
-	// Refresh cache: delete the old value and set new.
-	if s.rdb != nil {
-		ctx := context.Background() // Redis context.
-		key := s.cacheKeyUser(id) // Cache key.
-		_ = s.rdb.Del(ctx, key).Err() // Best-effort invalidate; ignore error.
-		if b, _ := json.Marshal(u); len(b) > 0 { // Marshal updated user.
-			_ = s.rdb.Set(ctx, key, b, userCacheTTL).Err() // Best-effort set; ignore error.
+	s.users.Invalidate(ctx, id)
+	flushed := []string{"cache"}
+	if err := s.tokens.RevokeAll(ctx, uint(id)); err != nil {
+		return flushed, err
+	}
+	flushed = append(flushed, "tokens")
+	if s.sessions != nil {
+		if err := s.sessions.DeleteAllForUser(ctx, uint(id)); err != nil {
+			return flushed, err
+		}
+		flushed = append(flushed, "sessions")
+	}
+	if s.lockout != nil {
+		if err := s.lockout.Clear(ctx, u.Email); err != nil {
+			return flushed, err
+		}
+		flushed = append(flushed, "lockout")
+	}
+	pending := false
+	err = s.repo.WithTx(ctx, func(repo repositories.UserRepository) error { // Re-read under lock: the flush above took a while.
+		u, err := repo.FindByID(ctx, id)
+		if err != nil || u.TOTPSecret == "" || u.TOTPEnabled {
+			return err
 		}
-		if s.log != nil { s.log.Info("UpdateUser cache refreshed", map[string]string{"key": key}) } // Log cache refresh.
+		u.TOTPSecret, pending = "", true
+		return repo.Update(ctx, u)
+	})
+	if err != nil {
+		return flushed, err
+	}
+	if pending {
+		flushed = append(flushed, "pending_2fa")
 	}
 
-	// Return updated user.
-	return u, nil
+	if s.log != nil { s.log.Info("FlushUser success", map[string]string{"user_id": fmt.Sprint(id), "flushed": strings.Join(flushed, ",")}) }
+	return flushed, nil
 }
 
 // DeleteUser removes a user and deletes any cache entry.
-func (s *userService) DeleteUser(id uint) error {
+func (s *userService) DeleteUser(ctx context.Context, id core.UserID) error {
 	if s.log != nil { s.log.Info("DeleteUser called", map[string]string{"user_id": fmt.Sprint(id)}) } // Trace call.
 
 	// Delete from DB (returns ErrRecordNotFound if not present).
-	if err := s.repo.Delete(id); err != nil {
+	if err := s.repo.Delete(ctx, id); err != nil {
 		if s.log != nil { s.log.Error("DeleteUser db error", map[string]string{"user_id": fmt.Sprint(id), "err": err.Error()}) }
 		return err
 	}
 
-	// Delete cache key to avoid stale reads.
-	if s.rdb != nil {
-		ctx := context.Background() // Redis context.
-		_ = s.rdb.Del(ctx, s.cacheKeyUser(id)).Err() // Best-effort delete.
-	}
+	s.users.Invalidate(ctx, id) // Avoid stale reads (best-effort).
+	s.forgetEmails(ctx, "DeleteUser") // The address can be registered again.
+
+	s.notify(ctx, "deleted", func(ctx context.Context, h hooks.UserLifecycle) { h.OnDeleted(ctx, uint(id)) })
 
 	// Log success.
 	if s.log != nil { s.log.Info("DeleteUser success", map[string]string{"user_id": fmt.Sprint(id)}) }
 	return nil // Done.
 }
 
-// ListUsers returns a paginated page of users and total count.
-func (s *userService) ListUsers(page, limit int) (*models.PagedUsers, error) {
-	if s.log != nil { s.log.Info("ListUsers called", map[string]string{"page": fmt.Sprint(page), "limit": fmt.Sprint(limit)}) } // Trace.
+// DeleteUsers removes several users in one transaction, clears their cache entries, and reports
+// which IDs were deleted and which didn't exist.
+func (s *userService) DeleteUsers(ctx context.Context, ids []core.UserID) (*models.BulkDeleteUsersResult, error) {
+	if s.log != nil { s.log.Info("DeleteUsers called", map[string]string{"count": fmt.Sprint(len(ids))}) } // Trace call.
+
+	seen := make(map[core.UserID]bool, len(ids)) // Duplicates in the request count once.
+	uniq := make([]core.UserID, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			uniq = append(uniq, id)
+		}
+	}
+	deleted, err := s.repo.DeleteMany(ctx, uniq)
+	if err != nil {
+		if s.log != nil { s.log.Error("DeleteUsers db error", map[string]string{"err": err.Error()}) }
+		return nil, err
+	}
+
+	res := &models.BulkDeleteUsersResult{Deleted: len(deleted), DeletedIDs: make([]uint, 0, len(deleted)), NotFound: []uint{}, Users: deleted}
+	gone := make(map[core.UserID]bool, len(deleted))
+	goneIDs := make([]core.UserID, 0, len(deleted))
+	for _, u := range deleted {
+		gone[core.UserID(u.ID)] = true
+		goneIDs = append(goneIDs, core.UserID(u.ID))
+		res.DeletedIDs = append(res.DeletedIDs, u.ID)
+	}
+	for _, id := range uniq {
+		if !gone[id] {
+			res.NotFound = append(res.NotFound, uint(id))
+		}
+	}
+
+	s.users.Invalidate(ctx, goneIDs...) // One DEL for all cache keys (best-effort, like DeleteUser).
+	if len(deleted) > 0 {
+		s.forgetEmails(ctx, "DeleteUsers")
+	}
+	for _, id := range goneIDs {
+		id := id
+		s.notify(ctx, "deleted", func(ctx context.Context, h hooks.UserLifecycle) { h.OnDeleted(ctx, uint(id)) })
+	}
+
+	if s.log != nil { s.log.Info("DeleteUsers success", map[string]string{"deleted": fmt.Sprint(res.Deleted), "not_found": fmt.Sprint(len(res.NotFound))}) }
+	return res, nil
+}
+
+// knownEmails returns the emails the known-email store says are taken, with when they were
+// recorded. A Redis error just means every email goes to the DB.
+func (s *userService) knownEmails(ctx context.Context, emails ...core.Email) map[string]time.Time {
+	keys := make([]string, 0, len(emails))
+	for _, e := range emails {
+		keys = append(keys, e.String())
+	}
+	found, err := s.known.Lookup(ctx, keys...)
+	if err != nil {
+		if s.log != nil { s.log.Warn("known emails lookup error", map[string]string{"err": err.Error()}) }
+	}
+	return found
+}
+
+// rememberEmails records freshly written emails (best-effort; a miss only costs a DB lookup).
+func (s *userService) rememberEmails(ctx context.Context, emails ...string) {
+	if err := s.known.Add(context.WithoutCancel(ctx), emails...); err != nil {
+		if s.log != nil { s.log.Warn("known emails add error", map[string]string{"err": err.Error()}) }
+	}
+}
+
+// forgetEmails voids the known-email store after an address stops being taken. If that fails
+// the store could reject a now-free email until its entries expire, so it's logged as an error.
+func (s *userService) forgetEmails(ctx context.Context, op string) {
+	if err := s.known.Invalidate(context.WithoutCancel(ctx)); err != nil {
+		if s.log != nil { s.log.Error(op+" known emails invalidate error", map[string]string{"err": err.Error()}) }
+	}
+}
+
+// notify runs call for every lifecycle hook. A panicking hook is logged and skipped: the change
+// it was told about has already been committed, so hooks don't inherit the request's cancellation.
+func (s *userService) notify(ctx context.Context, event string, call func(context.Context, hooks.UserLifecycle)) {
+	ctx = context.WithoutCancel(ctx)
+	for _, h := range s.lifecycle {
+		func() {
+			defer func() {
+				if r := recover(); r != nil && s.log != nil {
+					s.log.Error("lifecycle hook panic", map[string]string{"event": event, "hook": fmt.Sprintf("%T", h), "panic": fmt.Sprint(r)})
+				}
+			}()
+			call(ctx, h)
+		}()
+	}
+}
+
+// listUsersAfter serves cursor pagination; the cursor is the last ID of the previous page.
+func (s *userService) listUsersAfter(ctx context.Context, q models.ListUserQuery) (*models.PagedUsers, error) {
+	var v core.Violations
+	if q.Sort != "id" {
+		v = append(v, core.Violation{Field: "sort", Code: core.CodeSortInvalid, Message: "cursor pagination only supports sort=id"})
+	}
+	var after uint64
+	if *q.Cursor != "" {
+		var err error
+		if after, err = strconv.ParseUint(*q.Cursor, 10, 0); err != nil || after == 0 {
+			v = append(v, core.Violation{Field: "cursor", Code: core.CodeCursorInvalid, Message: "use the next_cursor of a previous response"})
+		}
+	}
+	if err := v.Err(); err != nil {
+		return nil, err
+	}
+
+	items, err := s.repo.ListAfter(ctx, q, uint(after), q.Limit+1) // One extra row tells us whether there is a next page.
+	if err != nil {
+		if s.log != nil { s.log.Error("ListUsers db error", map[string]string{"err": err.Error()}) }
+		return nil, err
+	}
+	resp := &models.PagedUsers{Items: items, Limit: q.Limit}
+	if len(items) > q.Limit {
+		resp.Items = items[:q.Limit]
+		resp.NextCursor = strconv.FormatUint(uint64(resp.Items[q.Limit-1].ID), 10)
+	}
+	return resp, nil
+}
+
+// userSortColumns maps the public sort keys to columns; anything else is rejected.
+var userSortColumns = map[string]string{
+	"id": "id", "name": "name", "email": "email", "created_at": "created_at", "updated_at": "updated_at",
+}
+
+// normalizeUserSort validates q.Sort/q.Order against the whitelist and fills in the defaults (id asc).
+func normalizeUserSort(q *models.ListUserQuery) error {
+	var v core.Violations
+	col, ok := userSortColumns[strings.ToLower(q.Sort)]
+	switch {
+	case q.Sort == "":
+		col = "id"
+	case !ok:
+		v = append(v, core.Violation{Field: "sort", Code: core.CodeSortInvalid, Message: "must be one of id, name, email, created_at, updated_at"})
+	}
+	order := strings.ToLower(q.Order)
+	switch order {
+	case "":
+		order = "asc"
+	case "asc", "desc":
+	default:
+		v = append(v, core.Violation{Field: "order", Code: core.CodeSortInvalid, Message: "must be asc or desc"})
+	}
+	q.Sort, q.Order = col, order
+	return v.Err()
+}
+
+// ListUsers returns a paginated page of users matching q's filters and their total count.
+func (s *userService) ListUsers(ctx context.Context, q models.ListUserQuery) (*models.PagedUsers, error) {
+	page, limit := q.Page, q.Limit
+	if s.log != nil { s.log.Info("ListUsers called", map[string]string{"page": fmt.Sprint(page), "limit": fmt.Sprint(limit), "q": q.Q}) } // Trace.
+
+	// Stored addresses are canonical (lower-cased domain), so canonicalize the exact-match filter too.
+	if q.Email != "" {
+		if email, err := core.ParseEmail(q.Email); err == nil {
+			q.Email = email.String()
+		}
+	}
 
 	// Sanitize inputs: default page=1, limit=10..100
 	if page < 1 { page = 1 } // Avoid zero/negative page.
 	if limit <= 0 || limit > 100 { limit = 10 } // Clamp page size.
+	q.Page, q.Limit = page, limit
+	if err := normalizeUserSort(&q); err != nil { // Only whitelisted columns reach ORDER BY.
+		return nil, err
+	}
+	if q.Cursor != nil { // Keyset mode: no OFFSET, no COUNT.
+		return s.listUsersAfter(ctx, q)
+	}
 
 	// Compute offset for SQL LIMIT/OFFSET.
 	offset := (page - 1) * limit // Skip previous pages.
 
 	// Query repository for items + total.
-	items, total, err := s.repo.List(offset, limit)
+	items, total, err := s.repo.List(ctx, q, offset, limit)
 	if err != nil { // Propagate DB error to handler.
 		if s.log != nil { s.log.Error("ListUsers db error", map[string]string{"err": err.Error()}) }
 		return nil, err
@@ -294,3 +1117,319 @@ func (s *userService) ListUsers(page, limit int) (*models.PagedUsers, error) {
 	// Return page.
 	return resp, nil
 }
+
+// exportBatchSize is how many rows ExportUsers reads per query.
+const exportBatchSize = 500
+
+// ExportUsers walks every user matching q's filters in id order and hands them to each one
+// batch at a time (keyset pages via ListAfter), so memory stays flat however large the table.
+// Sort, order and paging fields of q are ignored. An error from each stops the walk and is returned,
+// and so does ctx ending (the client hung up): the next batch query fails with ctx's error.
+func (s *userService) ExportUsers(ctx context.Context, q models.ListUserQuery, each func([]models.User) error) error {
+	if s.log != nil { s.log.Info("ExportUsers called", map[string]string{"q": q.Q}) } // Trace.
+
+	if q.Email != "" { // Same canonicalization as ListUsers.
+		if email, err := core.ParseEmail(q.Email); err == nil {
+			q.Email = email.String()
+		}
+	}
+	q.Sort, q.Order = "id", "asc"
+
+	var after uint
+	rows := 0
+	for {
+		items, err := s.repo.ListAfter(ctx, q, after, exportBatchSize)
+		if err != nil {
+			if s.log != nil { s.log.Error("ExportUsers db error", map[string]string{"err": err.Error(), "rows": fmt.Sprint(rows)}) }
+			return err
+		}
+		if len(items) > 0 {
+			if err := each(items); err != nil {
+				return err
+			}
+			rows += len(items)
+			after = items[len(items)-1].ID
+		}
+		if len(items) < exportBatchSize { // Short page = end of table.
+			break
+		}
+	}
+
+	if s.log != nil { s.log.Info("ExportUsers success", map[string]string{"rows": fmt.Sprint(rows)}) }
+	return nil
+}
+
+// ---------------- Account transfer ----------------
+
+// ExportAccounts returns the given users as bundle accounts, 2FA seeds decrypted (a pending,
+// unconfirmed seed is left behind), plus the IDs that don't exist.
+func (s *userService) ExportAccounts(ctx context.Context, ids []core.UserID) ([]accounts.Account, []uint, error) {
+	if s.log != nil { s.log.Info("ExportAccounts called", map[string]string{"count": fmt.Sprint(len(ids))}) } // Trace call.
+
+	out := make([]accounts.Account, 0, len(ids))
+	missing := []uint{}
+	seen := make(map[core.UserID]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		u, err := s.repo.FindByID(ctx, id)
+		if repositories.IsNotFound(err) {
+			missing = append(missing, uint(id))
+			continue
+		}
+		if err != nil {
+			if s.log != nil { s.log.Error("ExportAccounts db error", map[string]string{"user_id": fmt.Sprint(id), "err": err.Error()}) }
+			return nil, nil, err
+		}
+		seed := ""
+		if u.TOTPEnabled && u.TOTPSecret != "" {
+			if seed, err = utils.DecryptString(s.totpKey, u.TOTPSecret); err != nil { // Exporting without it would drop 2FA.
+				if s.log != nil { s.log.Error("ExportAccounts 2fa decrypt error", map[string]string{"user_id": fmt.Sprint(id), "err": err.Error()}) }
+				return nil, nil, fmt.Errorf("user %d: cannot decrypt two-factor seed: %w", id, err)
+			}
+		}
+		out = append(out, accounts.FromUser(*u, seed))
+	}
+
+	if s.log != nil { s.log.Info("ExportAccounts success", map[string]string{"exported": fmt.Sprint(len(out)), "missing": fmt.Sprint(len(missing))}) }
+	return out, missing, nil
+}
+
+// ImportAccounts creates the bundle's accounts, matched by email. An email that is already
+// registered is reported and skipped, or with overwrite replaced in place (same ID; its
+// sessions and tokens end, since the credentials changed). 2FA seeds are re-encrypted with
+// this environment's key.
+func (s *userService) ImportAccounts(ctx context.Context, accts []accounts.Account, overwrite bool) (*models.ImportAccountsResult, error) {
+	if s.log != nil { s.log.Info("ImportAccounts called", map[string]string{"accounts": fmt.Sprint(len(accts)), "overwrite": fmt.Sprint(overwrite)}) } // Trace call.
+
+	res := &models.ImportAccountsResult{Total: len(accts), Errors: []models.ImportRowError{}}
+	fail := func(row int, err error) {
+		res.Errors = append(res.Errors, models.ImportRowError{Row: row, Email: accts[row-1].Email, Error: err.Error()})
+	}
+
+	var pending []models.User
+	var pendingRows []int
+	seen := map[core.Email]int{}
+	for i, a := range accts {
+		row := i + 1
+		if err := ctx.Err(); err != nil { // Timed out or client gone: the rest isn't attempted.
+			for r := row; r <= len(accts); r++ {
+				fail(r, fmt.Errorf("not imported: %w", err))
+			}
+			break
+		}
+		email, err := core.ParseEmail(a.Email)
+		if err != nil {
+			fail(row, err)
+			continue
+		}
+		if err := a.Validate(); err != nil {
+			fail(row, err)
+			continue
+		}
+		if first, dup := seen[email]; dup {
+			fail(row, fmt.Errorf("duplicate email (same as row %d)", first))
+			continue
+		}
+		seen[email] = row
+		secret := ""
+		if a.TOTPSeed != "" {
+			if secret, err = utils.EncryptString(s.totpKey, a.TOTPSeed); err != nil {
+				fail(row, err)
+				continue
+			}
+		}
+
+		var existing *models.User
+		err = s.repo.WithTx(ctx, func(repo repositories.UserRepository) error { // Replace under lock, as in UpdateUser.
+			var err error
+			if existing, err = repo.FindByEmail(ctx, email); err != nil || !overwrite {
+				return err
+			}
+			a.Apply(existing)
+			existing.Name, existing.TOTPSecret = core.NormalizeName(existing.Name), secret
+			if err := repo.Update(ctx, existing); err != nil {
+				return fmt.Errorf("update failed: %w", err)
+			}
+			return nil
+		})
+		switch {
+		case err == nil && !overwrite:
+			fail(row, errors.New("email already exists"))
+		case err == nil:
+			id := core.UserID(existing.ID)
+			s.users.Invalidate(ctx, id)
+			s.revokeLogins(ctx, id, "ImportAccounts")
+			res.Replaced = append(res.Replaced, *existing)
+		case repositories.IsNotFound(err):
+			u := models.User{Email: email.String(), TOTPSecret: secret, CreatedAt: a.CreatedAt}
+			a.Apply(&u)
+			u.Name = core.NormalizeName(u.Name)
+			pending = append(pending, u)
+			pendingRows = append(pendingRows, row)
+		default:
+			if s.log != nil { s.log.Error("ImportAccounts db error", map[string]string{"email": email.String(), "err": err.Error()}) }
+			fail(row, err)
+		}
+	}
+
+	res.Users = s.insertBatches(ctx, "ImportAccounts", pending, pendingRows, fail)
+	sort.Slice(res.Errors, func(i, j int) bool { return res.Errors[i].Row < res.Errors[j].Row })
+	res.Created, res.Updated = len(res.Users), len(res.Replaced)
+
+	for _, u := range res.Users {
+		u := u
+		s.notify(ctx, "registered", func(ctx context.Context, h hooks.UserLifecycle) { h.OnRegistered(ctx, u) })
+	}
+	for _, u := range res.Replaced {
+		u := u
+		s.notify(ctx, "updated", func(ctx context.Context, h hooks.UserLifecycle) { h.OnUpdated(ctx, u) })
+	}
+
+	if s.log != nil { s.log.Info("ImportAccounts done", map[string]string{"created": fmt.Sprint(res.Created), "updated": fmt.Sprint(res.Updated), "failed": fmt.Sprint(len(res.Errors))}) }
+	return res, nil
+}
+
+// ---------------- Two-factor (TOTP) ----------------
+
+// EnableTwoFactor generates a fresh TOTP secret and stores it encrypted but not yet active.
+// Re-enrolling before confirming simply replaces the pending secret.
+func (s *userService) EnableTwoFactor(ctx context.Context, id core.UserID) (*models.TwoFactorSetup, error) {
+	var u *models.User
+	var secret string
+	err := s.repo.WithTx(ctx, func(repo repositories.UserRepository) error { // Locked read-modify-write, as in UpdateUser.
+		var err error
+		if u, err = repo.FindByID(ctx, id); err != nil {
+			return err
+		}
+		if u.TOTPEnabled { // Must be disabled first; avoids silently swapping an active seed.
+			return errors.New("two-factor already enabled")
+		}
+
+		if secret, err = utils.GenerateTOTPSecret(); err != nil {
+			return err
+		}
+		enc, err := utils.EncryptString(s.totpKey, secret) // Never store the raw seed.
+		if err != nil {
+			return err
+		}
+		u.TOTPSecret = enc
+		if err := repo.Update(ctx, u); err != nil {
+			if s.log != nil { s.log.Error("2fa enable db error", map[string]string{"user_id": fmt.Sprint(id), "err": err.Error()}) }
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if s.log != nil { s.log.Info("2fa enrollment started", map[string]string{"user_id": fmt.Sprint(id)}) }
+	return &models.TwoFactorSetup{
+		Secret:     secret,
+		OTPAuthURL: utils.TOTPProvisioningURI(s.totpIssuer, u.Email, secret),
+	}, nil
+}
+
+// ConfirmTwoFactor activates 2FA once the user proves their app produces valid codes.
+func (s *userService) ConfirmTwoFactor(ctx context.Context, id core.UserID, code string) error {
+	err := s.repo.WithTx(ctx, func(repo repositories.UserRepository) error { // Locked read-modify-write, as in UpdateUser.
+		u, err := repo.FindByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if u.TOTPSecret == "" { // Nothing pending.
+			return errors.New("two-factor enrollment not started")
+		}
+		if err := s.checkTOTP(u, code); err != nil {
+			return err
+		}
+
+		u.TOTPEnabled = true
+		return repo.Update(ctx, u)
+	})
+	if err != nil {
+		return err
+	}
+	s.users.Invalidate(ctx, id) // Cached copy still says disabled.
+	if s.log != nil { s.log.Info("2fa enabled", map[string]string{"user_id": fmt.Sprint(id)}) }
+	return nil
+}
+
+// checkTOTP verifies code against u's secret and refuses a code whose time step isn't newer than
+// the last one accepted (a code stays valid for ~90s, so it could be replayed). It records the
+// step on u; the caller saves u in the same locked transaction.
+func (s *userService) checkTOTP(u *models.User, code string) error {
+	secret, err := utils.DecryptString(s.totpKey, u.TOTPSecret)
+	if err != nil { // Wrong key or corrupted column: the detail is for the log, not the client.
+		if s.log != nil { s.log.Error("totp decrypt error", map[string]string{"user_id": fmt.Sprint(u.ID), "err": err.Error()}) }
+		return ErrTwoFactorUnavailable
+	}
+	step, ok := utils.MatchTOTP(secret, code, time.Now())
+	if !ok || step <= u.TOTPLastStep {
+		return ErrInvalidTwoFactor
+	}
+	u.TOTPLastStep = step
+	return nil
+}
+
+// ---------------- Password strength ----------------
+
+// PasswordStrength scores a candidate password for the frontend meter.
+func (s *userService) PasswordStrength(req models.PasswordStrengthRequest) models.PasswordStrengthResponse {
+	res := core.EstimatePasswordStrength(req.Password, req.Name, req.Email)
+	return models.PasswordStrengthResponse{
+		StrengthResult: res,
+		MinScore:       s.minPasswordScore,
+		Acceptable:     res.Score >= s.minPasswordScore && s.passwords.Validate(req.Password).Err() == nil,
+	}
+}
+
+// ChangePassword verifies the current password, enforces the strength policy, stores the new
+// hash, and revokes every existing login (JWTs and sessions) so a leaked credential stops working.
+func (s *userService) ChangePassword(ctx context.Context, id core.UserID, req models.ChangePasswordRequest) error {
+	var u *models.User
+	err := s.repo.WithTx(ctx, func(repo repositories.UserRepository) error { // Locked read-modify-write, as in UpdateUser.
+		var err error
+		if u, err = repo.FindByID(ctx, id); err != nil {
+			return err
+		}
+		if !utils.CheckPassword(u.Password, req.Old) {
+			if s.log != nil { s.log.Warn("change password wrong current", map[string]string{"user_id": fmt.Sprint(id)}) }
+			return ErrWrongPassword
+		}
+
+		violations := s.passwords.Validate(req.New)
+		violations = append(violations, core.ValidatePasswordStrength(req.New, s.minPasswordScore, u.Name, u.Email)...)
+		if req.New == req.Old {
+			violations = append(violations, core.Violation{Field: "new_password", Code: core.CodePasswordReused, Message: "must differ from the current password"})
+		}
+		if err := violations.Err(); err != nil {
+			return err
+		}
+
+		hash, err := utils.HashPassword(req.New)
+		if err != nil {
+			if s.log != nil { s.log.Error("change password hash error", map[string]string{"user_id": fmt.Sprint(id), "err": err.Error()}) }
+			return err
+		}
+		u.Password = hash
+		if err := repo.Update(ctx, u); err != nil {
+			if s.log != nil { s.log.Error("change password db error", map[string]string{"user_id": fmt.Sprint(id), "err": err.Error()}) }
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// The password is already changed; revocation failures are logged, not returned.
+	s.users.Invalidate(ctx, id) // cached copy is from before the change
+	s.revokeLogins(ctx, id, "change password")
+	s.notify(ctx, "updated", func(ctx context.Context, h hooks.UserLifecycle) { h.OnUpdated(ctx, *u) })
+	if s.log != nil { s.log.Info("password changed", map[string]string{"user_id": fmt.Sprint(id)}) }
+	return nil
+}
@@ -1,22 +1,39 @@
 package services
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
+	"HelmyTask/accounts"
+	"HelmyTask/audit"
+	"HelmyTask/core"
+	"HelmyTask/hooks"
+	"HelmyTask/metrics"
 	"HelmyTask/mocks"
 	"HelmyTask/models"
 	"HelmyTask/repositories"
+	"HelmyTask/scripting"
 
 	"HelmyTask/utils"
+	"HelmyTask/utils/knownemails"
+	"HelmyTask/utils/lockout"
 	"HelmyTask/utils/redislog"
+	"HelmyTask/utils/revocation"
 
 	// "github.com/go-redis/redismock/v9"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
 )
 
 func newSvc(repo repositories.UserRepository, rdb *redis.Client, l *redislog.Logger) UserService {
@@ -35,14 +52,14 @@ func mustUserJSON(u models.User) string {
 func TestUserService_Register_EmailExists(t *testing.T) {
 	repo := new(mocks.UserRepositoryMock)
 	// repo claims email exists
-	repo.On("FindByEmail", "a@b.c").Return(&models.User{ID: 1}, nil)
+	repo.On("FindByEmail", core.Email("a@b.c")).Return(&models.User{ID: 1}, nil)
 
 	// use a NO-OP logger (nil redis client) so we don't need to mock LPUSH/LTRIM/EXPIRE
 	noLog := redislog.New(nil, "", 0, 0)
 
 	svc := newSvc(repo, nil, noLog)
 
-	u, err := svc.Register(models.RegisterRequest{Name: "  aHMED  ", Email: "a@b.c", Password: "123456"})
+	u, err := svc.Register(context.Background(), models.RegisterRequest{Name: "  aHMED  ", Email: "a@b.c", Password: "123456"})
 	assert.Nil(t, u)
 	assert.EqualError(t, err, "email already exists")
 }
@@ -55,7 +72,7 @@ func TestUserService_Register_Success_NormalizesAndCaches(t *testing.T) {
 	noLog := redislog.New(nil, "", 0, 0)
 
 	// email not found
-	repo.On("FindByEmail", "a@b.c").Return(nil, errors.New("not found"))
+	repo.On("FindByEmail", core.Email("a@b.c")).Return(nil, errors.New("not found"))
 	// Create sets an ID; we capture and modify the arg
 	repo.On("Create", mock.AnythingOfType("*models.User")).Return(nil).Run(func(args mock.Arguments) {
 		u := args.Get(0).(*models.User)
@@ -67,6 +84,8 @@ func TestUserService_Register_Success_NormalizesAndCaches(t *testing.T) {
 		ID:    10,
 		Name:  "AHMED", // NormalizeName applied
 		Email: "a@b.c",
+		Role:  models.RoleUser, // new accounts start unprivileged
+		Status: models.StatusActive,
 		// Password omitted by json:"-"
 		// CreatedAt/UpdatedAt are zero values → "0001-01-01T00:00:00Z"
 	})
@@ -74,7 +93,7 @@ func TestUserService_Register_Success_NormalizesAndCaches(t *testing.T) {
 
 	svc := newSvc(repo, rdb, noLog)
 
-	u, err := svc.Register(models.RegisterRequest{Name: "  aHMED  ", Email: "a@b.c", Password: "123456"})
+	u, err := svc.Register(context.Background(), models.RegisterRequest{Name: "  aHMED  ", Email: "a@b.c", Password: "123456"})
 	assert.NoError(t, err)
 	assert.Equal(t, uint(10), u.ID)
 	assert.Equal(t, "AHMED", u.Name) // PROVES NormalizeName was applied
@@ -84,10 +103,10 @@ func TestUserService_Register_Success_NormalizesAndCaches(t *testing.T) {
 
 func TestUserService_Login_Invalid(t *testing.T) {
 	repo := new(mocks.UserRepositoryMock)
-	repo.On("FindByEmail", "x@y.z").Return(nil, errors.New("not found"))
+	repo.On("FindByEmail", core.Email("x@y.z")).Return(nil, errors.New("not found"))
 
 	svc := newSvc(repo, nil, nil)
-	tok, err := svc.Login(models.LoginRequest{Email: "x@y.z", Password: "pw"}, "sec", time.Hour)
+	tok, err := svc.Login(context.Background(), models.LoginRequest{Email: "x@y.z", Password: "pw"}, "sec", time.Hour)
 	assert.Empty(t, tok)
 	assert.EqualError(t, err, "invalid credentials")
 }
@@ -95,14 +114,28 @@ func TestUserService_Login_Invalid(t *testing.T) {
 func TestUserService_Login_Success_JWT(t *testing.T) {
 	repo := new(mocks.UserRepositoryMock)
 	hash, _ := utils.HashPassword("good")
-	repo.On("FindByEmail", "x@y.z").Return(&models.User{ID: 7, Email: "x@y.z", Password: hash}, nil)
+	repo.On("FindByEmail", core.Email("x@y.z")).Return(&models.User{ID: 7, Email: "x@y.z", Password: hash}, nil)
 
 	svc := newSvc(repo, nil, nil)
-	tok, err := svc.Login(models.LoginRequest{Email: "x@y.z", Password: "good"}, "sec", time.Minute)
+	tok, err := svc.Login(context.Background(), models.LoginRequest{Email: "x@y.z", Password: "good"}, "sec", time.Minute)
 	assert.NoError(t, err)
 	assert.NotEmpty(t, tok)
 }
 
+func TestUserService_Login_NormalizesWithoutValidating(t *testing.T) {
+	repo := new(mocks.UserRepositoryMock)
+	hash, _ := utils.HashPassword("good")
+	// A single-label domain fails ValidateEmail, but an account registered under older rules must
+	// still be able to log in: login only trims and folds the domain.
+	repo.On("FindByEmail", core.Email("Ops@intranet")).Return(&models.User{ID: 8, Email: "Ops@intranet", Password: hash}, nil)
+
+	svc := newSvc(repo, nil, nil)
+	tok, err := svc.Login(context.Background(), models.LoginRequest{Email: "  Ops@INTRANET ", Password: "good"}, "sec", time.Minute)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, tok)
+	repo.AssertExpectations(t)
+}
+
 func TestUserService_GetByID_CacheHit(t *testing.T) {
 	repo := new(mocks.UserRepositoryMock)
 	rdb, rmock := mocks.NewRedisMock()
@@ -112,7 +145,7 @@ func TestUserService_GetByID_CacheHit(t *testing.T) {
 	b, _ := json.Marshal(u)
 	rmock.ExpectGet("user:5").SetVal(string(b))
 
-	got, err := svc.GetByID(5)
+	got, err := svc.GetByID(context.Background(), 5)
 	assert.NoError(t, err)
 	assert.Equal(t, u.Email, got.Email)
 	assert.NoError(t, rmock.ExpectationsWereMet())
@@ -124,7 +157,7 @@ func TestUserService_GetByID_MissThenDBThenSet(t *testing.T) {
 	svc := newSvc(repo, rdb, nil)
 
 	rmock.ExpectGet("user:9").RedisNil()
-	repo.On("FindByID", uint(9)).Return(&models.User{ID: 9, Email: "a@b.c"}, nil)
+	repo.On("FindByID", core.UserID(9)).Return(&models.User{ID: 9, Email: "a@b.c"}, nil)
 
 	// exact JSON for the cached value after DB hit
 	expectedCached := mustUserJSON(models.User{
@@ -133,18 +166,39 @@ func TestUserService_GetByID_MissThenDBThenSet(t *testing.T) {
 	})
 	rmock.ExpectSet("user:9", []byte(expectedCached), 10*time.Minute).SetVal("OK")
 
-	got, err := svc.GetByID(9)
+	got, err := svc.GetByID(context.Background(), 9)
 	assert.NoError(t, err)
 	assert.Equal(t, uint(9), got.ID)
 	assert.NoError(t, rmock.ExpectationsWereMet())
 }
 
+func TestUserService_GetByID_CountsCacheHitsAndMisses(t *testing.T) {
+	repo := new(mocks.UserRepositoryMock)
+	rdb, rmock := mocks.NewRedisMock()
+	m := metrics.New()
+	svc := NewUserService(repo, rdb, nil, WithMetrics(m))
+
+	b, _ := json.Marshal(models.User{ID: 5})
+	rmock.ExpectGet("user:5").SetVal(string(b))
+	rmock.ExpectGet("user:9").RedisNil()
+	repo.On("FindByID", core.UserID(9)).Return(&models.User{ID: 9}, nil)
+	rmock.CustomMatch(func(expected, actual []interface{}) error { return nil }).ExpectSet("user:9", "", 0).SetVal("OK")
+
+	_, _ = svc.GetByID(context.Background(), 5)
+	_, _ = svc.GetByID(context.Background(), 9)
+
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Contains(t, w.Body.String(), `cache_lookups_total{cache="user",result="hit"} 1`)
+	assert.Contains(t, w.Body.String(), `cache_lookups_total{cache="user",result="miss"} 1`)
+}
+
 func TestUserService_UpdateUser_NameNormalized_RefreshCache(t *testing.T) {
 	repo := new(mocks.UserRepositoryMock)
 	rdb, rmock := mocks.NewRedisMock()
 	svc := newSvc(repo, rdb, nil)
 
-	repo.On("FindByID", uint(2)).Return(&models.User{ID: 2, Name: "Old"}, nil)
+	repo.On("FindByID", core.UserID(2)).Return(&models.User{ID: 2, Name: "Old"}, nil)
 	repo.On("Update", mock.AnythingOfType("*models.User")).Return(nil)
 
 	rmock.ExpectDel("user:2").SetVal(1)
@@ -157,7 +211,7 @@ func TestUserService_UpdateUser_NameNormalized_RefreshCache(t *testing.T) {
 	rmock.ExpectSet("user:2", []byte(expectedCached), 10*time.Minute).SetVal("OK")
 
 	newName := "  aHMED "
-	got, err := svc.UpdateUser(2, models.UpdateUserRequest{Name: &newName})
+	got, err := svc.UpdateUser(context.Background(), 2, models.UpdateUserRequest{Name: &newName})
 	assert.NoError(t, err)
 	assert.Equal(t, "AHMED", got.Name) // again proves NormalizeName
 
@@ -169,22 +223,641 @@ func TestUserService_DeleteUser_DeletesAndClearsCache(t *testing.T) {
 	rdb, rmock := mocks.NewRedisMock()
 	svc := newSvc(repo, rdb, nil)
 
-	repo.On("Delete", uint(3)).Return(nil)
+	repo.On("Delete", core.UserID(3)).Return(nil)
 	rmock.ExpectDel("user:3").SetVal(1)
 
-	err := svc.DeleteUser(3)
+	err := svc.DeleteUser(context.Background(), 3)
+	assert.NoError(t, err)
+	assert.NoError(t, rmock.ExpectationsWereMet())
+}
+
+func TestUserService_DeleteUsers_ReportsNotFoundAndClearsCache(t *testing.T) {
+	repo := new(mocks.UserRepositoryMock)
+	rdb, rmock := mocks.NewRedisMock()
+	svc := newSvc(repo, rdb, nil)
+
+	repo.On("DeleteMany", []core.UserID{4, 9, 5}).Return([]models.User{{ID: 4}, {ID: 5}}, nil).Once()
+	rmock.ExpectDel("user:4", "user:5").SetVal(2) // one DEL for all keys
+
+	res, err := svc.DeleteUsers(context.Background(), []core.UserID{4, 9, 4, 5})
 	assert.NoError(t, err)
+	assert.Equal(t, 2, res.Deleted)
+	assert.Equal(t, []uint{4, 5}, res.DeletedIDs)
+	assert.Equal(t, []uint{9}, res.NotFound)
+	assert.Len(t, res.Users, 2)
 	assert.NoError(t, rmock.ExpectationsWereMet())
+	repo.AssertExpectations(t)
+}
+
+func TestUserService_ExportUsers_Batches(t *testing.T) {
+	repo := new(mocks.UserRepositoryMock)
+	svc := newSvc(repo, nil, nil)
+
+	full := make([]models.User, exportBatchSize)
+	for i := range full {
+		full[i].ID = uint(i + 1)
+	}
+	byID := mock.MatchedBy(func(q models.ListUserQuery) bool { return q.Sort == "id" && q.Order == "asc" && q.Q == "x" })
+	repo.On("ListAfter", byID, uint(0), exportBatchSize).Return(full, nil).Once()
+	repo.On("ListAfter", byID, uint(exportBatchSize), exportBatchSize).Return([]models.User{{ID: 900}}, nil).Once()
+
+	var rows int
+	err := svc.ExportUsers(context.Background(), models.ListUserQuery{Q: "x", Sort: "name", Order: "desc"}, func(b []models.User) error {
+		rows += len(b)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, exportBatchSize+1, rows)
+	repo.AssertExpectations(t)
+}
+
+func TestUserService_ImportUsers_PerRowReport(t *testing.T) {
+	repo := new(mocks.UserRepositoryMock)
+	svc := newSvc(repo, nil, nil)
+
+	repo.On("FindByEmail", core.Email("new@b.c")).Return(nil, gorm.ErrRecordNotFound)
+	repo.On("FindByEmail", core.Email("taken@b.c")).Return(&models.User{ID: 1}, nil)
+	repo.On("CreateBatch", mock.MatchedBy(func(us []models.User) bool {
+		return len(us) == 1 && us[0].Email == "new@b.c" && us[0].Name == "Sara" && us[0].Role == models.RoleUser && us[0].Password != "123456"
+	})).Return(nil).Once()
+
+	res, err := svc.ImportUsers(context.Background(), []models.RegisterRequest{
+		{Name: "sara", Email: "new@B.C", Password: "123456"},
+		{Name: "x", Email: "not-an-email", Password: "123456"},
+		{Name: "Sara Two", Email: "new@b.c", Password: "123456"},
+		{Name: "Omar", Email: "taken@b.c", Password: "123456"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 4, res.Total)
+	assert.Equal(t, 1, res.Created)
+	assert.Equal(t, uint(1), res.Users[0].ID)
+	if assert.Len(t, res.Errors, 3) {
+		assert.Equal(t, 2, res.Errors[0].Row)
+		assert.NotEmpty(t, res.Errors[0].Violations)
+		assert.Equal(t, models.ImportRowError{Row: 3, Email: "new@b.c", Error: "duplicate email (same as row 1)"}, res.Errors[1])
+		assert.Equal(t, "email already exists", res.Errors[2].Error)
+	}
+	repo.AssertExpectations(t)
+}
+
+func TestUserService_ImportUsers_LookupErrorsAndCancellation(t *testing.T) {
+	repo := new(mocks.UserRepositoryMock)
+	svc := newSvc(repo, nil, nil)
+
+	// A failed lookup can't tell whether the email is taken: the row fails, the rest go on.
+	repo.On("FindByEmail", core.Email("a@b.c")).Return(nil, errors.New("connection reset")).Once()
+	repo.On("FindByEmail", core.Email("b@b.c")).Return(nil, gorm.ErrRecordNotFound).Once()
+	repo.On("CreateBatch", mock.MatchedBy(func(us []models.User) bool { return len(us) == 1 && us[0].Email == "b@b.c" })).Return(nil).Once()
+	rows := []models.RegisterRequest{{Name: "Ahmed", Email: "a@b.c", Password: "123456"}, {Name: "Sara", Email: "b@b.c", Password: "123456"}}
+	res, err := svc.ImportUsers(context.Background(), rows)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, res.Created)
+	assert.Equal(t, []models.ImportRowError{{Row: 1, Email: "a@b.c", Error: "email lookup failed: connection reset"}}, res.Errors)
+
+	// Once the request is over nothing more is looked up or inserted.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	res, err = svc.ImportUsers(ctx, rows)
+	assert.NoError(t, err)
+	assert.Zero(t, res.Created)
+	assert.Len(t, res.Errors, 2)
+	assert.Equal(t, "not imported: context canceled", res.Errors[1].Error)
+	repo.AssertExpectations(t)
+}
+
+func TestUserService_UpdateUser_ProfileFields(t *testing.T) {
+	repo := new(mocks.UserRepositoryMock)
+	svc := newSvc(repo, nil, nil)
+
+	repo.On("FindByID", core.UserID(5)).Return(&models.User{ID: 5, Name: "Sara", Bio: "old bio", Locale: "en"}, nil)
+	repo.On("Update", mock.AnythingOfType("*models.User")).Return(nil)
+
+	phone, bio, dob := "+201001234567", "", "1990-05-01"
+	u, err := svc.UpdateUser(context.Background(), 5, models.UpdateUserRequest{Phone: &phone, Bio: &bio, DateOfBirth: &dob})
+	assert.NoError(t, err)
+	assert.Equal(t, "+201001234567", u.Phone)
+	assert.Equal(t, "", u.Bio, `"" clears`)
+	assert.Equal(t, "1990-05-01", u.DateOfBirth)
+	assert.Equal(t, "en", u.Locale, "untouched fields stay")
+
+	future := time.Now().AddDate(1, 0, 0).Format(core.DateLayout)
+	_, err = svc.UpdateUser(context.Background(), 5, models.UpdateUserRequest{DateOfBirth: &future})
+	var v core.Violations
+	if assert.ErrorAs(t, err, &v) {
+		assert.Equal(t, core.CodeDateOfBirthInvalid, v[0].Code)
+	}
 }
 
 func TestUserService_ListUsers_Clamp(t *testing.T) {
 	repo := new(mocks.UserRepositoryMock)
 	svc := newSvc(repo, nil, nil)
 
-	repo.On("List", 0, 10).Return([]models.User{{ID: 1}}, int64(1), nil)
+	repo.On("List", models.ListUserQuery{Page: 1, Limit: 10, Sort: "id", Order: "asc"}, 0, 10).Return([]models.User{{ID: 1}}, int64(1), nil)
 
-	out, err := svc.ListUsers(0, 1000)
+	out, err := svc.ListUsers(context.Background(), models.ListUserQuery{Page: 0, Limit: 1000})
 	assert.NoError(t, err)
 	assert.Equal(t, 1, len(out.Items))
 	assert.Equal(t, int64(1), out.Total)
 }
+
+func TestUserService_ListUsers_CanonicalEmailFilter(t *testing.T) {
+	repo := new(mocks.UserRepositoryMock)
+	svc := newSvc(repo, nil, nil)
+
+	want := models.ListUserQuery{Page: 2, Limit: 5, Q: "ahm", Email: "Ahmed@example.com", Sort: "id", Order: "asc"}
+	repo.On("List", want, 5, 5).Return([]models.User{}, int64(6), nil)
+
+	out, err := svc.ListUsers(context.Background(), models.ListUserQuery{Page: 2, Limit: 5, Q: "ahm", Email: "Ahmed@EXAMPLE.com"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(6), out.Total)
+	repo.AssertExpectations(t)
+}
+
+func TestUserService_ListUsers_SortWhitelist(t *testing.T) {
+	repo := new(mocks.UserRepositoryMock)
+	svc := newSvc(repo, nil, nil)
+
+	repo.On("List", models.ListUserQuery{Page: 1, Limit: 10, Sort: "created_at", Order: "desc"}, 0, 10).Return([]models.User{}, int64(0), nil)
+	_, err := svc.ListUsers(context.Background(), models.ListUserQuery{Sort: "CREATED_AT", Order: "DESC"})
+	assert.NoError(t, err)
+
+	_, err = svc.ListUsers(context.Background(), models.ListUserQuery{Sort: "password; DROP TABLE users", Order: "sideways"})
+	var v core.Violations
+	assert.ErrorAs(t, err, &v)
+	assert.Len(t, v, 2)
+	assert.Equal(t, core.CodeSortInvalid, v[0].Code)
+	repo.AssertNumberOfCalls(t, "List", 1)
+}
+
+func TestUserService_ListUsers_Cursor(t *testing.T) {
+	repo := new(mocks.UserRepositoryMock)
+	svc := newSvc(repo, nil, nil)
+	first, next := "", "2"
+
+	repo.On("ListAfter", models.ListUserQuery{Page: 1, Limit: 2, Sort: "id", Order: "asc", Cursor: &first}, uint(0), 3).
+		Return([]models.User{{ID: 1}, {ID: 2}, {ID: 3}}, nil)
+	out, err := svc.ListUsers(context.Background(), models.ListUserQuery{Limit: 2, Cursor: &first})
+	assert.NoError(t, err)
+	assert.Len(t, out.Items, 2)
+	assert.Equal(t, "2", out.NextCursor)
+
+	repo.On("ListAfter", models.ListUserQuery{Page: 1, Limit: 2, Sort: "id", Order: "asc", Cursor: &next}, uint(2), 3).
+		Return([]models.User{{ID: 3}}, nil)
+	out, err = svc.ListUsers(context.Background(), models.ListUserQuery{Limit: 2, Cursor: &next})
+	assert.NoError(t, err)
+	assert.Empty(t, out.NextCursor, "last page")
+
+	bad := "abc"
+	_, err = svc.ListUsers(context.Background(), models.ListUserQuery{Cursor: &bad, Sort: "name"})
+	var v core.Violations
+	assert.ErrorAs(t, err, &v)
+	assert.Len(t, v, 2)
+	repo.AssertNotCalled(t, "List", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUserService_Login_TwoFactor(t *testing.T) {
+	repo := new(mocks.UserRepositoryMock)
+	hash, _ := utils.HashPassword("good")
+	secret, _ := utils.GenerateTOTPSecret()
+	enc, _ := utils.EncryptString("k", secret)
+	u := &models.User{ID: 7, Email: "x@y.z", Password: hash, TOTPEnabled: true, TOTPSecret: enc}
+	repo.On("FindByEmail", core.Email("x@y.z")).Return(u, nil)
+	repo.On("FindByID", core.UserID(7)).Return(u, nil) // the locked re-read
+	repo.On("Update", u).Return(nil)
+
+	svc := NewUserService(repo, nil, nil, WithTwoFactor("k", "test"))
+
+	// step 1: password only → second step required
+	_, err := svc.Login(context.Background(), models.LoginRequest{Email: "x@y.z", Password: "good"}, "sec", time.Minute)
+	assert.ErrorIs(t, err, ErrTwoFactorRequired)
+
+	// step 2: wrong code rejected, valid code accepted
+	_, err = svc.Login(context.Background(), models.LoginRequest{Email: "x@y.z", Password: "good", Code: wrongTOTP(secret)}, "sec", time.Minute)
+	assert.ErrorIs(t, err, ErrInvalidTwoFactor)
+	repo.AssertNotCalled(t, "Update", u)
+
+	code, _ := utils.TOTPCode(secret, time.Now())
+	tok, err := svc.Login(context.Background(), models.LoginRequest{Email: "x@y.z", Password: "good", Code: code}, "sec", time.Minute)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, tok)
+	assert.NotZero(t, u.TOTPLastStep)
+
+	// the same code again, still within its window: a replay
+	_, err = svc.Login(context.Background(), models.LoginRequest{Email: "x@y.z", Password: "good", Code: code}, "sec", time.Minute)
+	assert.ErrorIs(t, err, ErrInvalidTwoFactor)
+}
+
+func TestUserService_Login_TwoFactorSecretErrorIsGeneric(t *testing.T) {
+	repo := new(mocks.UserRepositoryMock)
+	hash, _ := utils.HashPassword("good")
+	u := &models.User{ID: 7, Email: "x@y.z", Password: hash, TOTPEnabled: true, TOTPSecret: "garbage"}
+	repo.On("FindByEmail", core.Email("x@y.z")).Return(u, nil)
+	repo.On("FindByID", core.UserID(7)).Return(u, nil)
+
+	svc := NewUserService(repo, nil, nil, WithTwoFactor("k", "test"))
+	_, err := svc.Login(context.Background(), models.LoginRequest{Email: "x@y.z", Password: "good", Code: "123456"}, "sec", time.Minute)
+	assert.Equal(t, ErrTwoFactorUnavailable, err) // not the decryption error itself
+}
+
+// wrongTOTP is a well-formed code that none of the currently accepted steps produce.
+func wrongTOTP(secret string) string {
+	for n := 0; ; n++ {
+		c := fmt.Sprintf("%06d", n)
+		if !utils.ValidateTOTP(secret, c, time.Now()) {
+			return c
+		}
+	}
+}
+
+func TestUserService_Register_RetryWithinWindowReturnsExisting(t *testing.T) {
+	repo := new(mocks.UserRepositoryMock)
+	hash, _ := utils.HashPassword("123456")
+	existing := &models.User{ID: 4, Email: "a@b.c", Password: hash, CreatedAt: time.Now().Add(-10 * time.Second)}
+	repo.On("FindByEmail", core.Email("a@b.c")).Return(existing, nil)
+
+	svc := newSvc(repo, nil, nil)
+
+	// same email+password moments later → treated as a retry, not a conflict
+	u, err := svc.Register(context.Background(), models.RegisterRequest{Name: "ahmed", Email: "a@b.c", Password: "123456"})
+	assert.NoError(t, err)
+	assert.Equal(t, uint(4), u.ID)
+
+	// different password → still a conflict
+	_, err = svc.Register(context.Background(), models.RegisterRequest{Name: "ahmed", Email: "a@b.c", Password: "other1"})
+	assert.EqualError(t, err, "email already exists")
+	repo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestUserService_Register_WeakPasswordRejectedWhenPolicyOn(t *testing.T) {
+	repo := new(mocks.UserRepositoryMock)
+	svc := NewUserService(repo, nil, nil, WithPasswordMinScore(2))
+
+	_, err := svc.Register(context.Background(), models.RegisterRequest{Name: "ahmed", Email: "a@b.c", Password: "password1"})
+	var v core.Violations
+	assert.ErrorAs(t, err, &v)
+	assert.Equal(t, core.CodePasswordWeak, v[0].Code)
+	repo.AssertNotCalled(t, "FindByEmail", mock.Anything)
+
+	res := svc.PasswordStrength(models.PasswordStrengthRequest{Password: "password1"})
+	assert.False(t, res.Acceptable)
+	assert.Equal(t, 2, res.MinScore)
+}
+
+func TestUserService_ChangePassword(t *testing.T) {
+	repo := new(mocks.UserRepositoryMock)
+	hash, _ := utils.HashPassword("old-pass-1")
+	repo.On("FindByID", core.UserID(3)).Return(&models.User{ID: 3, Email: "a@b.c", Password: hash}, nil)
+	svc := newSvc(repo, nil, nil)
+
+	// current password must be proven
+	err := svc.ChangePassword(context.Background(), 3, models.ChangePasswordRequest{Old: "nope", New: "brand-new-pass"})
+	assert.ErrorIs(t, err, ErrWrongPassword)
+
+	// reusing the current password is a violation
+	err = svc.ChangePassword(context.Background(), 3, models.ChangePasswordRequest{Old: "old-pass-1", New: "old-pass-1"})
+	var v core.Violations
+	assert.ErrorAs(t, err, &v)
+	assert.Equal(t, core.CodePasswordReused, v[0].Code)
+	repo.AssertNotCalled(t, "Update", mock.Anything)
+
+	// success stores a new hash
+	repo.On("Update", mock.MatchedBy(func(u *models.User) bool { return utils.CheckPassword(u.Password, "brand-new-pass") })).Return(nil)
+	assert.NoError(t, svc.ChangePassword(context.Background(), 3, models.ChangePasswordRequest{Old: "old-pass-1", New: "brand-new-pass"}))
+	repo.AssertExpectations(t)
+}
+
+func TestUserService_Login_InactiveAccount(t *testing.T) {
+	repo := new(mocks.UserRepositoryMock)
+	hash, _ := utils.HashPassword("good")
+	repo.On("FindByEmail", core.Email("x@y.z")).Return(&models.User{ID: 7, Email: "x@y.z", Password: hash, Status: models.StatusBanned}, nil)
+	svc := newSvc(repo, nil, nil)
+
+	_, err := svc.Login(context.Background(), models.LoginRequest{Email: "x@y.z", Password: "bad"}, "s", time.Minute)
+	assert.EqualError(t, err, "invalid credentials", "status is only revealed with the right password")
+	_, err = svc.Login(context.Background(), models.LoginRequest{Email: "x@y.z", Password: "good"}, "s", time.Minute)
+	assert.ErrorIs(t, err, ErrAccountInactive)
+}
+
+func TestUserService_SetStatus_BanRevokesLogins(t *testing.T) {
+	repo := new(mocks.UserRepositoryMock)
+	rdb, rmock := mocks.NewRedisMock()
+	repo.On("FindByID", core.UserID(4)).Return(&models.User{ID: 4, Status: models.StatusActive}, nil)
+	repo.On("Update", mock.MatchedBy(func(u *models.User) bool { return u.Status == models.StatusBanned })).Return(nil).Once()
+	svc := NewUserService(repo, rdb, nil, WithCredentialRevocation(nil, revocation.New(rdb, time.Hour)))
+
+	rmock.ExpectDel("user:4").SetVal(1)
+	rmock.CustomMatch(func(expected, actual []interface{}) error { return nil }).ExpectSet("auth:revoked_before:4", 0, time.Hour).SetVal("OK")
+	u, err := svc.SetStatus(context.Background(), 4, models.StatusBanned, "spam")
+	assert.NoError(t, err)
+	assert.False(t, u.IsActive())
+	assert.NoError(t, rmock.ExpectationsWereMet())
+
+	_, err = svc.SetStatus(context.Background(), 4, "deleted", "")
+	assert.Error(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestUserService_UpdateUser_NewEmailIsDeliverable(t *testing.T) {
+	repo := new(mocks.UserRepositoryMock)
+	svc := newSvc(repo, nil, nil)
+
+	repo.On("FindByID", core.UserID(2)).Return(&models.User{ID: 2, Name: "Sara", Email: "old@b.c", EmailUndeliverable: true}, nil)
+	repo.On("FindByEmail", core.Email("new@b.c")).Return(nil, gorm.ErrRecordNotFound)
+	repo.On("Update", mock.MatchedBy(func(u *models.User) bool { return u.Email == "new@b.c" && !u.EmailUndeliverable })).Return(nil).Once()
+
+	email := "new@b.c"
+	got, err := svc.UpdateUser(context.Background(), 2, models.UpdateUserRequest{Email: &email})
+	assert.NoError(t, err)
+	assert.False(t, got.EmailUndeliverable)
+	repo.AssertExpectations(t)
+}
+
+func TestUserService_UpdateUser_WritesPendingAuditInTx(t *testing.T) {
+	repo, auditRepo := new(mocks.UserRepositoryMock), new(mocks.AuditRepositoryMock)
+	rdb, rmock := mocks.NewRedisMock()
+	svc := newSvc(repo, rdb, nil)
+
+	repo.On("FindByID", core.UserID(2)).Return(&models.User{ID: 2, Name: "Old"}, nil)
+	repo.On("Update", mock.AnythingOfType("*models.User")).Return(nil)
+	repo.On("Audit").Return(auditRepo)
+	auditRepo.On("Create", mock.MatchedBy(func(e *models.AuditLog) bool {
+		return e.ActorID == 1 && e.Action == audit.ActionUserUpdate && e.TargetID == 2 && e.Diff == `{"name":{"from":"Old","to":"New"}}`
+	})).Return(nil).Once()
+	rmock.MatchExpectationsInOrder(false)
+	rmock.ExpectDel("user:2").SetVal(1)
+	rmock.CustomMatch(func(expected, actual []interface{}) error { return nil }).ExpectSet("user:2", nil, 10*time.Minute).SetVal("OK")
+
+	ctx := audit.WithPending(context.Background(), audit.Event{ActorID: 1, Action: audit.ActionUserUpdate, TargetType: "user", TargetID: 2})
+	name := "New"
+	_, err := svc.UpdateUser(ctx, 2, models.UpdateUserRequest{Name: &name})
+	assert.NoError(t, err)
+	auditRepo.AssertExpectations(t)
+
+	// A failed audit write fails (and so rolls back) the update.
+	auditRepo.On("Create", mock.Anything).Return(errors.New("disk full")).Once()
+	_, err = svc.UpdateUser(ctx, 2, models.UpdateUserRequest{Name: &name})
+	assert.EqualError(t, err, "disk full")
+}
+
+func TestUserService_UpdateUser_RoleChangeRevokesLogins(t *testing.T) {
+	repo := new(mocks.UserRepositoryMock)
+	rdb, rmock := mocks.NewRedisMock()
+	repo.On("FindByID", core.UserID(4)).Return(&models.User{ID: 4, Role: models.RoleAdmin, Status: models.StatusActive}, nil)
+	repo.On("Update", mock.MatchedBy(func(u *models.User) bool { return u.Role == models.RoleUser })).Return(nil)
+	svc := NewUserService(repo, rdb, nil, WithCredentialRevocation(nil, revocation.New(rdb, time.Hour)))
+
+	rmock.ExpectDel("user:4").SetVal(1)
+	rmock.CustomMatch(func(expected, actual []interface{}) error { return nil }).ExpectSet("user:4", nil, 10*time.Minute).SetVal("OK")
+	rmock.CustomMatch(func(expected, actual []interface{}) error { return nil }).ExpectSet("auth:revoked_before:4", 0, time.Hour).SetVal("OK")
+	role := models.RoleUser
+	_, err := svc.UpdateUser(context.Background(), 4, models.UpdateUserRequest{Role: &role})
+	assert.NoError(t, err)
+	assert.NoError(t, rmock.ExpectationsWereMet())
+
+	// The same role again changes nothing a token carries: no revocation.
+	rmock.ExpectDel("user:4").SetVal(1)
+	rmock.CustomMatch(func(expected, actual []interface{}) error { return nil }).ExpectSet("user:4", nil, 10*time.Minute).SetVal("OK")
+	_, err = svc.UpdateUser(context.Background(), 4, models.UpdateUserRequest{Role: &role})
+	assert.NoError(t, err)
+	assert.NoError(t, rmock.ExpectationsWereMet())
+}
+
+func TestUserService_UpdateUser_EnforcesPasswordPolicy(t *testing.T) {
+	repo := new(mocks.UserRepositoryMock)
+	repo.On("FindByID", core.UserID(2)).Return(&models.User{ID: 2, Email: "a@b.c"}, nil)
+	svc := NewUserService(repo, nil, nil, WithPasswordPolicy(core.PasswordPolicy{MinLength: 6, BanCommon: true}))
+
+	pw := "qwerty"
+	_, err := svc.UpdateUser(context.Background(), 2, models.UpdateUserRequest{Password: &pw})
+	var v core.Violations
+	assert.ErrorAs(t, err, &v)
+	assert.Equal(t, core.CodePasswordBanned, v[0].Code)
+	repo.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+// recordingHooks captures lifecycle events; it panics on delete to prove hooks can't break requests.
+type recordingHooks struct {
+	hooks.Base
+	events []string
+}
+
+func (h *recordingHooks) OnLogin(_ context.Context, u models.User) {
+	h.events = append(h.events, fmt.Sprintf("login:%d", u.ID))
+}
+func (h *recordingHooks) OnDeleted(context.Context, uint) { panic("boom") }
+
+func TestUserService_LifecycleHooks(t *testing.T) {
+	repo := new(mocks.UserRepositoryMock)
+	hash, _ := utils.HashPassword("good")
+	repo.On("FindByEmail", core.Email("x@y.z")).Return(&models.User{ID: 7, Email: "x@y.z", Password: hash}, nil)
+	repo.On("Delete", core.UserID(7)).Return(nil)
+	h := &recordingHooks{}
+	svc := NewUserService(repo, nil, nil, WithLifecycleHooks(h))
+
+	_, err := svc.Login(context.Background(), models.LoginRequest{Email: "x@y.z", Password: "bad"}, "sec", time.Minute)
+	assert.Error(t, err)
+	_, err = svc.Login(context.Background(), models.LoginRequest{Email: "x@y.z", Password: "good"}, "sec", time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"login:7"}, h.events, "only successful logins are reported")
+
+	assert.NoError(t, svc.DeleteUser(context.Background(), 7), "a panicking hook is recovered")
+}
+
+func TestUserService_Scripts(t *testing.T) {
+	scripts, err := scripting.New(scripting.Spec{
+		RegistrationRules: []scripting.Rule{{Expr: `domain(email) == "corp.io"`, Message: "corporate addresses only"}},
+		Claims:            map[string]string{"org": `domain(email)`},
+	})
+	assert.NoError(t, err)
+	repo := new(mocks.UserRepositoryMock)
+	svc := NewUserService(repo, nil, nil, WithScripts(scripts))
+
+	// rule rejects before any DB work
+	_, err = svc.Register(context.Background(), models.RegisterRequest{Name: "ahmed", Email: "a@gmail.com", Password: "Tr1cky-Horse-Battery"})
+	var v core.Violations
+	assert.ErrorAs(t, err, &v)
+	assert.Equal(t, scripting.CodeRegistrationRule, v[0].Code)
+	repo.AssertNotCalled(t, "FindByEmail", mock.Anything)
+
+	// scripted claim lands in the token
+	hash, _ := utils.HashPassword("good")
+	repo.On("FindByEmail", core.Email("x@corp.io")).Return(&models.User{ID: 7, Email: "x@corp.io", Password: hash}, nil)
+	tok, err := svc.Login(context.Background(), models.LoginRequest{Email: "x@corp.io", Password: "good"}, "sec", time.Minute)
+	assert.NoError(t, err)
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(tok, claims, func(*jwt.Token) (interface{}, error) { return []byte("sec"), nil })
+	assert.NoError(t, err)
+	assert.Equal(t, "corp.io", claims["org"])
+}
+
+func knownEmailKey(email string) string {
+	sum := sha256.Sum256([]byte(email))
+	return "known_email:" + hex.EncodeToString(sum[:])
+}
+
+func TestUserService_FlushUser_LiftsLockout(t *testing.T) {
+	repo := new(mocks.UserRepositoryMock)
+	rdb, rmock := mocks.NewRedisMock()
+	svc := NewUserService(repo, nil, nil, WithLoginLockout(lockout.New(rdb, lockout.Policy{MaxFailures: 5, Window: time.Minute, Cooldown: time.Minute})))
+
+	repo.On("FindByID", core.UserID(4)).Return(&models.User{ID: 4, Email: "a@b.c"}, nil)
+	sum := sha256.Sum256([]byte("a@b.c"))
+	rmock.ExpectDel("login_fail:"+hex.EncodeToString(sum[:]), "login_lock:"+hex.EncodeToString(sum[:])).SetVal(1)
+
+	flushed, err := svc.FlushUser(context.Background(), 4)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"cache", "tokens", "lockout"}, flushed)
+	assert.NoError(t, rmock.ExpectationsWereMet())
+}
+
+func TestUserService_KnownEmails(t *testing.T) {
+	repo := new(mocks.UserRepositoryMock)
+	rdb, rmock := mocks.NewRedisMock()
+	svc := NewUserService(repo, nil, nil, WithKnownEmails(knownemails.New(rdb, time.Minute)))
+	old := fmt.Sprintf("0:%d", time.Now().Add(-time.Hour).Unix())
+
+	// Known and too old to be a retry: rejected without FindByEmail.
+	rmock.ExpectMGet("known_email:gen", knownEmailKey("a@b.c")).SetVal([]interface{}{nil, old})
+	_, err := svc.Register(context.Background(), models.RegisterRequest{Name: "Ahmed", Email: "a@b.c", Password: "123456"})
+	assert.EqualError(t, err, "email already exists")
+
+	// Import: one lookup for the file; only unknown emails reach the DB, created ones are recorded.
+	repo.On("FindByEmail", core.Email("new@b.c")).Return(nil, gorm.ErrRecordNotFound).Once()
+	repo.On("CreateBatch", mock.Anything).Return(nil).Once()
+	rmock.ExpectMGet("known_email:gen", knownEmailKey("a@b.c"), knownEmailKey("new@b.c")).SetVal([]interface{}{nil, old, nil})
+	rmock.ExpectGet("known_email:gen").RedisNil()
+	rmock.Regexp().ExpectSet(knownEmailKey("new@b.c"), `^0:\d+$`, time.Minute).SetVal("OK")
+	res, err := svc.ImportUsers(context.Background(), []models.RegisterRequest{
+		{Name: "Ahmed", Email: "a@b.c", Password: "123456"},
+		{Name: "Sara", Email: "new@b.c", Password: "123456"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, res.Created)
+	assert.Equal(t, models.ImportRowError{Row: 1, Email: "a@b.c", Error: "email already exists"}, res.Errors[0])
+
+	// Deleting frees an address, so every entry is voided.
+	repo.On("Delete", core.UserID(3)).Return(nil).Once()
+	rmock.ExpectIncr("known_email:gen").SetVal(1)
+	assert.NoError(t, svc.DeleteUser(context.Background(), 3))
+
+	assert.NoError(t, rmock.ExpectationsWereMet())
+	repo.AssertExpectations(t)
+}
+
+func TestUserService_ExportAccounts(t *testing.T) {
+	repo := new(mocks.UserRepositoryMock)
+	enc, _ := utils.EncryptString("k", "SEED")
+	pending, _ := utils.EncryptString("k", "PENDING")
+	repo.On("FindByID", core.UserID(1)).Return(&models.User{ID: 1, Email: "a@b.c", Password: "$2a$10$x", Role: models.RoleAdmin, TOTPEnabled: true, TOTPSecret: enc}, nil).Once()
+	repo.On("FindByID", core.UserID(2)).Return(&models.User{ID: 2, Email: "b@b.c", Password: "$2a$10$y", TOTPSecret: pending}, nil).Once()
+	repo.On("FindByID", core.UserID(3)).Return(nil, gorm.ErrRecordNotFound).Once()
+	svc := NewUserService(repo, nil, nil, WithTwoFactor("k", "test"))
+
+	accts, missing, err := svc.ExportAccounts(context.Background(), []core.UserID{1, 2, 3, 1})
+	assert.NoError(t, err)
+	assert.Equal(t, []uint{3}, missing)
+	if assert.Len(t, accts, 2, "duplicates exported once") {
+		assert.Equal(t, "SEED", accts[0].TOTPSeed)
+		assert.True(t, accts[0].TOTPEnabled)
+		assert.Equal(t, "$2a$10$x", accts[0].PasswordHash)
+		assert.Empty(t, accts[1].TOTPSeed, "unconfirmed enrollment stays behind")
+	}
+
+	repo.On("FindByID", core.UserID(4)).Return(&models.User{ID: 4, TOTPEnabled: true, TOTPSecret: "garbage"}, nil).Once()
+	_, _, err = svc.ExportAccounts(context.Background(), []core.UserID{4})
+	assert.Error(t, err, "exporting without the seed would drop 2FA")
+	repo.AssertExpectations(t)
+}
+
+func TestUserService_ImportAccounts(t *testing.T) {
+	repo := new(mocks.UserRepositoryMock)
+	svc := NewUserService(repo, nil, nil, WithTwoFactor("k2", "test"))
+	hash := "$2a$10$abcdefghijklmnopqrstuv"
+
+	repo.On("FindByEmail", core.Email("new@b.c")).Return(nil, gorm.ErrRecordNotFound)
+	repo.On("FindByEmail", core.Email("taken@b.c")).Return(&models.User{ID: 9, Email: "taken@b.c", Role: models.RoleUser}, nil)
+	repo.On("CreateBatch", mock.MatchedBy(func(us []models.User) bool {
+		if len(us) != 1 || us[0].Email != "new@b.c" || us[0].Password != hash || us[0].Status != models.StatusActive {
+			return false
+		}
+		seed, err := utils.DecryptString("k2", us[0].TOTPSecret) // re-encrypted with this environment's key
+		return err == nil && seed == "SEED" && us[0].TOTPEnabled
+	})).Return(nil).Once()
+
+	bundle := []accounts.Account{
+		{Email: "new@B.C", Name: "Sara", PasswordHash: hash, Role: models.RoleUser, TOTPSeed: "SEED", TOTPEnabled: true},
+		{Email: "taken@b.c", Name: "Omar", PasswordHash: hash, Role: models.RoleSupport},
+		{Email: "x@b.c", Name: "X", PasswordHash: "plaintext", Role: models.RoleUser},
+		{Email: "new@b.c", Name: "Dup", PasswordHash: hash, Role: models.RoleUser},
+	}
+	res, err := svc.ImportAccounts(context.Background(), bundle, false)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, res.Total)
+	assert.Equal(t, 1, res.Created)
+	assert.Equal(t, 0, res.Updated)
+	if assert.Len(t, res.Errors, 3) {
+		assert.Equal(t, models.ImportRowError{Row: 2, Email: "taken@b.c", Error: "email already exists"}, res.Errors[0])
+		assert.Equal(t, 3, res.Errors[1].Row)
+		assert.Equal(t, "duplicate email (same as row 1)", res.Errors[2].Error)
+	}
+
+	// With overwrite the existing user is replaced in place.
+	repo.On("Update", mock.MatchedBy(func(u *models.User) bool {
+		return u.ID == 9 && u.Role == models.RoleSupport && u.Name == "Omar" && u.Password == hash
+	})).Return(nil).Once()
+	res, err = svc.ImportAccounts(context.Background(), bundle[1:2], true)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, res.Updated)
+	assert.Equal(t, uint(9), res.Replaced[0].ID)
+	repo.AssertExpectations(t)
+}
+
+func TestUserService_Setup_FirstAdminOnce(t *testing.T) {
+	repo := new(mocks.UserRepositoryMock)
+	rdb, rmock := mocks.NewRedisMock()
+	svc := newSvc(repo, rdb, nil)
+	anyValue := func(expected, actual []interface{}) error { return nil }
+
+	rmock.CustomMatch(anyValue).ExpectSetNX("setup:lock", 0, 30*time.Second).SetVal(true)
+	rmock.ExpectExists("setup:done").SetVal(0)
+	repo.On("ListAfter", models.ListUserQuery{}, uint(0), 1).Return([]models.User{}, nil).Once()
+	repo.On("Create", mock.MatchedBy(func(u *models.User) bool {
+		return u.Role == models.RoleAdmin && u.Email == "admin@b.c" && u.Password != "S3cure-pass!"
+	})).Return(nil).Once()
+	rmock.CustomMatch(anyValue).ExpectSet("setup:done", 0, 0).SetVal("OK")
+	u, err := svc.Setup(context.Background(), models.RegisterRequest{Name: "Helmy", Email: "admin@B.C", Password: "S3cure-pass!"})
+	assert.NoError(t, err)
+	assert.Equal(t, models.RoleAdmin, u.Role)
+	repo.AssertExpectations(t)
+
+	// no Redis, no lock: refused before the DB is asked
+	_, err = newSvc(repo, nil, nil).Setup(context.Background(), models.RegisterRequest{Name: "other", Email: "x@b.c", Password: "S3cure-pass!"})
+	assert.ErrorIs(t, err, ErrSetupUnavailable)
+	repo.AssertNumberOfCalls(t, "ListAfter", 1)
+
+	repo.On("ListAfter", models.ListUserQuery{}, uint(0), 1).Return([]models.User{*u}, nil)
+	open, err := newSvc(repo, nil, nil).SetupRequired(context.Background())
+	assert.NoError(t, err)
+	assert.False(t, open)
+}
+
+func TestUserService_Setup_LockedInRedis(t *testing.T) {
+	repo := new(mocks.UserRepositoryMock)
+	rdb, rmock := mocks.NewRedisMock()
+	svc := newSvc(repo, rdb, nil)
+	anyValue := func(expected, actual []interface{}) error { return nil }
+
+	// Another request holds the lock.
+	rmock.CustomMatch(anyValue).ExpectSetNX("setup:lock", 0, 30*time.Second).SetVal(false)
+	_, err := svc.Setup(context.Background(), models.RegisterRequest{Name: "Helmy", Email: "admin@b.c", Password: "S3cure-pass!"})
+	assert.ErrorIs(t, err, ErrSetupInProgress)
+
+	// Completed earlier: stays locked even with no users left (the DB isn't asked).
+	rmock.CustomMatch(anyValue).ExpectSetNX("setup:lock", 0, 30*time.Second).SetVal(true)
+	rmock.ExpectExists("setup:done").SetVal(1)
+	rmock.ExpectDel("setup:lock").SetVal(1)
+	_, err = svc.Setup(context.Background(), models.RegisterRequest{Name: "Helmy", Email: "admin@b.c", Password: "S3cure-pass!"})
+	assert.ErrorIs(t, err, ErrSetupDone)
+	assert.NoError(t, rmock.ExpectationsWereMet())
+	repo.AssertNotCalled(t, "ListAfter", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,84 @@
+package services // Lifecycle hook that turns user changes into webhook events.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"HelmyTask/hooks"
+	"HelmyTask/jobs"
+	"HelmyTask/models"
+	"HelmyTask/utils/redislog"
+)
+
+// Webhook event types sent for user lifecycle changes.
+const (
+	WebhookUserRegistered = "user.registered"
+	WebhookUserUpdated    = "user.updated"
+	WebhookUserDeleted    = "user.deleted"
+)
+
+// JobWebhookDispatch is the job type that sends one event to every webhook target.
+const JobWebhookDispatch = "webhook.dispatch"
+
+type webhookDispatchJob struct {
+	EventType string          `json:"event_type"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// webhookHooks queues user events for the registered webhook targets.
+type webhookHooks struct {
+	hooks.Base // logins aren't sent
+	svc        WebhookService
+	jobs       jobs.Enqueuer
+	log        *redislog.Logger
+}
+
+// NewWebhookHooks registers the JobWebhookDispatch handler on q and returns the lifecycle hook
+// (services.WithLifecycleHooks) that sends user.registered, user.updated and user.deleted to
+// every webhook target from the job queue, so a slow target never holds up a request.
+// Per-target failures are retried by the webhook service's own schedule; the job is retried
+// only when the dispatch itself fails (e.g. the targets can't be listed).
+func NewWebhookHooks(svc WebhookService, q *jobs.Queue, rlog *redislog.Logger) hooks.UserLifecycle {
+	q.Handle(JobWebhookDispatch, func(_ context.Context, payload json.RawMessage) error {
+		var job webhookDispatchJob
+		if err := json.Unmarshal(payload, &job); err != nil {
+			return jobs.Permanent(err)
+		}
+		return svc.Dispatch(job.EventType, job.Data)
+	})
+	return &webhookHooks{svc: svc, jobs: q, log: rlog}
+}
+
+// OnRegistered sends user.registered with the new user.
+func (h *webhookHooks) OnRegistered(ctx context.Context, u models.User) {
+	h.enqueue(ctx, WebhookUserRegistered, u)
+}
+
+// OnUpdated sends user.updated with the user's new state.
+func (h *webhookHooks) OnUpdated(ctx context.Context, u models.User) {
+	h.enqueue(ctx, WebhookUserUpdated, u)
+}
+
+// OnDeleted sends user.deleted with the removed user's ID.
+func (h *webhookHooks) OnDeleted(ctx context.Context, id uint) {
+	h.enqueue(ctx, WebhookUserDeleted, map[string]uint{"id": id})
+}
+
+// enqueue queues the dispatch; if Redis refuses it, the event is sent right away in the
+// background instead of being lost.
+func (h *webhookHooks) enqueue(ctx context.Context, eventType string, data interface{}) {
+	raw, err := json.Marshal(data)
+	if err == nil {
+		err = h.jobs.Enqueue(ctx, JobWebhookDispatch, webhookDispatchJob{EventType: eventType, Data: raw})
+	}
+	if err == nil {
+		return
+	}
+	if h.log != nil { h.log.Warn("webhook enqueue failed, dispatching inline", map[string]string{"event": eventType, "err": err.Error()}) }
+	go func() {
+		if err := h.svc.Dispatch(eventType, json.RawMessage(raw)); err != nil && h.log != nil {
+			h.log.Error("webhook dispatch failed", map[string]string{"event": eventType, "err": fmt.Sprint(err)})
+		}
+	}()
+}
@@ -0,0 +1,372 @@
+package services // Use-case layer for webhook targets.
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"HelmyTask/core"
+	"HelmyTask/models"
+	"HelmyTask/repositories"
+	"HelmyTask/scripting"
+	"HelmyTask/utils/httpclient"
+	"HelmyTask/utils/redislog"
+	"HelmyTask/webhookverify"
+)
+
+// ErrUnsafeWebhookURL is returned when a target fails the SSRF checks.
+var ErrUnsafeWebhookURL = httpclient.ErrUnsafeURL
+
+// ErrReplaySelection is returned when a replay names neither IDs nor a time range.
+var ErrReplaySelection = errors.New("select deliveries by ids or by a from/to range")
+
+// ErrReplayTooMany is returned when a replay names more than maxReplayBatch IDs.
+var ErrReplayTooMany = fmt.Errorf("replay at most %d deliveries per call", maxReplayBatch)
+
+// maxReplayBatch caps one range replay (and one retry round); run it again for the rest.
+const maxReplayBatch = 500
+
+// webhookRetryDelays spaces a failed delivery's automatic retries (after the first attempt, the
+// second, ...): six attempts over about 8.5 hours, after which it stays failed until replayed.
+var webhookRetryDelays = []time.Duration{time.Minute, 5 * time.Minute, 30 * time.Minute, 2 * time.Hour, 6 * time.Hour}
+
+// webhookSecretPrefix marks webhook signing secrets (like API keys' "htk_").
+const webhookSecretPrefix = "whsec_"
+
+// WebhookService manages webhook targets and sends signed deliveries.
+type WebhookService interface {
+	Create(by core.UserID, req models.CreateWebhookRequest) (*models.WebhookCreated, error) // Secret returned once.
+	List() ([]models.Webhook, error)
+	Delete(id uint) error
+	Test(id uint) (*models.WebhookTestResult, error) // Sends a signed sample and echoes it.
+
+	// Delivery log: inspect failures and resend them after an integration hiccup.
+	ListDeliveries(f models.WebhookDeliveryFilter, page, limit int) (*models.PagedWebhookDeliveries, error)
+	Replay(req models.ReplayWebhooksRequest) (*models.ReplayWebhooksResult, error)
+
+	// Events: Dispatch sends one signed event to every target and logs each delivery; failed
+	// ones are retried on a backoff schedule by RetryDue (run periodically by RetryEvery).
+	Dispatch(eventType string, data interface{}) error
+	RetryDue(now time.Time) (int, error)
+	RetryEvery(ctx context.Context, every time.Duration)
+}
+
+type webhookService struct {
+	repo       repositories.WebhookRepository
+	deliveries repositories.WebhookDeliveryRepository // every attempt is logged for inspection/replay
+	client  *http.Client // egress-configured, private-IP-blocking client from utils/httpclient
+	log     *redislog.Logger
+	trusted []string // hosts exempt from the SSRF checks
+	scripts *scripting.Scripts // configured webhook filter: which events are delivered (nil-safe)
+}
+
+// NewWebhookService wires the webhook use-cases. trustedHosts bypass the SSRF checks
+// (operator-approved internal receivers); scripts' webhook filter picks the events Dispatch sends.
+func NewWebhookService(repo repositories.WebhookRepository, deliveries repositories.WebhookDeliveryRepository, client *http.Client, trustedHosts []string, scripts *scripting.Scripts, rlog *redislog.Logger) WebhookService {
+	return &webhookService{repo: repo, deliveries: deliveries, client: client, log: rlog, trusted: trustedHosts, scripts: scripts}
+}
+
+// Create validates the target (HTTPS, public addresses only), generates its signing secret, and stores it.
+func (s *webhookService) Create(by core.UserID, req models.CreateWebhookRequest) (*models.WebhookCreated, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second) // bounded DNS lookup
+	defer cancel()
+	if err := httpclient.CheckURL(ctx, req.URL, s.trusted, nil); err != nil {
+		if s.log != nil { s.log.Warn("webhook url rejected", map[string]string{"url": req.URL, "by": fmt.Sprint(by), "err": err.Error()}) }
+		return nil, err
+	}
+	secret, err := randomHex(32)
+	if err != nil {
+		return nil, err
+	}
+	w := models.Webhook{URL: req.URL, Description: req.Description, Secret: webhookSecretPrefix + secret, CreatedByID: uint(by)}
+	if err := s.repo.Create(&w); err != nil {
+		if s.log != nil { s.log.Error("webhook create db error", map[string]string{"url": req.URL, "err": err.Error()}) }
+		return nil, err
+	}
+	if s.log != nil { s.log.Info("webhook registered", map[string]string{"id": fmt.Sprint(w.ID), "url": w.URL, "by": fmt.Sprint(by)}) }
+	return &models.WebhookCreated{Webhook: w, Secret: w.Secret}, nil
+}
+
+// List returns every registered webhook.
+func (s *webhookService) List() ([]models.Webhook, error) {
+	return s.repo.List()
+}
+
+// Delete removes a webhook target.
+func (s *webhookService) Delete(id uint) error {
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+	if s.log != nil { s.log.Info("webhook deleted", map[string]string{"id": fmt.Sprint(id)}) }
+	return nil
+}
+
+// Test sends a signed "webhook.test" event so integrators can check their verification code;
+// the exact request is echoed back whether or not the target accepted it.
+func (s *webhookService) Test(id uint) (*models.WebhookTestResult, error) {
+	w, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	evt, err := newWebhookEvent("webhook.test", map[string]string{"message": "This is a test delivery."})
+	if err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(evt)
+	if err != nil {
+		return nil, err
+	}
+	d := &models.WebhookDelivery{WebhookID: w.ID, EventID: evt.ID, EventType: evt.Type, Payload: string(body)}
+	req := s.attempt(w, d)
+	if err := s.deliveries.Create(d); err != nil {
+		if s.log != nil { s.log.Error("webhook delivery log error", map[string]string{"webhook_id": fmt.Sprint(w.ID), "err": err.Error()}) }
+	}
+	return &models.WebhookTestResult{Request: *req, StatusCode: d.LastStatusCode, Error: d.LastError}, nil
+}
+
+// ListDeliveries pages through the delivery log, newest first.
+func (s *webhookService) ListDeliveries(f models.WebhookDeliveryFilter, page, limit int) (*models.PagedWebhookDeliveries, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	items, total, err := s.deliveries.List(f, (page-1)*limit, limit)
+	if err != nil {
+		return nil, err
+	}
+	return &models.PagedWebhookDeliveries{Items: items, Total: total, Page: page, Limit: limit}, nil
+}
+
+// Replay queues the selected deliveries for RetryDue to resend with a fresh signature (same
+// event ID and body); nothing is sent inside the caller's request. By IDs, any delivery can be
+// resent; by range, only failed ones are.
+func (s *webhookService) Replay(req models.ReplayWebhooksRequest) (*models.ReplayWebhooksResult, error) {
+	var (
+		items []models.WebhookDelivery
+		err   error
+	)
+	switch {
+	case len(req.IDs) > maxReplayBatch:
+		return nil, ErrReplayTooMany
+	case len(req.IDs) > 0:
+		items, err = s.deliveries.FindByIDs(req.IDs)
+	case req.From != nil || req.To != nil:
+		f := models.WebhookDeliveryFilter{Status: models.WebhookDeliveryFailed, WebhookID: req.WebhookID, From: req.From.TimePtr(), To: req.To.TimePtr()}
+		items, _, err = s.deliveries.List(f, 0, maxReplayBatch)
+	default:
+		return nil, ErrReplaySelection
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	res := &models.ReplayWebhooksResult{Items: make([]models.WebhookDelivery, 0, len(items))}
+	targets := map[uint]*models.Webhook{} // one lookup per webhook
+	for i := range items {
+		d := &items[i]
+		w, ok := targets[d.WebhookID]
+		if !ok {
+			if w, err = s.findTarget(d.WebhookID); err != nil {
+				return nil, err
+			}
+			targets[d.WebhookID] = w
+		}
+		if w == nil {
+			d.Status, d.LastError, d.NextAttemptAt = models.WebhookDeliveryFailed, "webhook no longer exists", nil
+		} else {
+			due := now
+			d.Status, d.NextAttemptAt = models.WebhookDeliveryRetrying, &due
+			res.Queued++
+		}
+		if err := s.deliveries.Update(d); err != nil {
+			return nil, err
+		}
+		res.Items = append(res.Items, *d)
+	}
+	if s.log != nil { s.log.Info("webhooks queued for replay", map[string]string{"queued": fmt.Sprint(res.Queued), "selected": fmt.Sprint(len(items))}) }
+	return res, nil
+}
+
+// findTarget loads a delivery's webhook; nil (no error) means it has been deleted since.
+func (s *webhookService) findTarget(id uint) (*models.Webhook, error) {
+	w, err := s.repo.FindByID(id)
+	if repositories.IsNotFound(err) {
+		return nil, nil
+	}
+	return w, err
+}
+
+// Dispatch wraps data in a fresh event and sends it to every registered target, one delivery
+// row per target. A failed delivery is left "retrying" for RetryDue.
+func (s *webhookService) Dispatch(eventType string, data interface{}) error {
+	targets, err := s.repo.List()
+	if err != nil || len(targets) == 0 {
+		return err
+	}
+	ok, err := s.scripts.AllowWebhook(eventType, data)
+	if err != nil { // Fails open (ok is true): a broken filter must not drop events.
+		if s.log != nil { s.log.Warn("webhook filter error", map[string]string{"event": eventType, "err": err.Error()}) }
+	}
+	if !ok {
+		return nil
+	}
+	evt, err := newWebhookEvent(eventType, data)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(evt)
+	if err != nil {
+		return err
+	}
+	for i := range targets {
+		w := &targets[i]
+		d := &models.WebhookDelivery{WebhookID: w.ID, EventID: evt.ID, EventType: evt.Type, Payload: string(body)}
+		s.attempt(w, d)
+		scheduleRetry(d, time.Now())
+		if err := s.deliveries.Create(d); err != nil {
+			if s.log != nil { s.log.Error("webhook delivery log error", map[string]string{"webhook_id": fmt.Sprint(w.ID), "event": evt.Type, "err": err.Error()}) }
+		}
+	}
+	return nil
+}
+
+// RetryDue re-attempts the deliveries whose retry time has come (up to maxReplayBatch) and
+// returns how many it tried. Run it on one replica only (a leader singleton task).
+func (s *webhookService) RetryDue(now time.Time) (int, error) {
+	items, err := s.deliveries.FindDue(now, maxReplayBatch)
+	if err != nil {
+		return 0, err
+	}
+	targets := map[uint]*models.Webhook{} // one lookup per webhook
+	for i := range items {
+		d := &items[i]
+		w, ok := targets[d.WebhookID]
+		if !ok {
+			if w, err = s.findTarget(d.WebhookID); err != nil {
+				return i, err
+			}
+			targets[d.WebhookID] = w
+		}
+		if w == nil {
+			d.Status, d.LastError, d.NextAttemptAt = models.WebhookDeliveryFailed, "webhook no longer exists", nil
+		} else {
+			s.attempt(w, d)
+			scheduleRetry(d, now)
+		}
+		if err := s.deliveries.Update(d); err != nil {
+			return i, err
+		}
+	}
+	return len(items), nil
+}
+
+// RetryEvery runs RetryDue every interval until ctx is done; errors are logged.
+func (s *webhookService) RetryEvery(ctx context.Context, every time.Duration) {
+	t := time.NewTicker(every)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-t.C:
+			if _, err := s.RetryDue(now); err != nil && s.log != nil {
+				s.log.Error("webhook retry failed", map[string]string{"err": err.Error()})
+			}
+		}
+	}
+}
+
+// scheduleRetry turns a failed attempt into "retrying" with its next attempt time, while
+// webhookRetryDelays lasts; after that the failure is final.
+func scheduleRetry(d *models.WebhookDelivery, now time.Time) {
+	if d.Status != models.WebhookDeliveryFailed || d.Attempts > len(webhookRetryDelays) {
+		return
+	}
+	next := now.Add(webhookRetryDelays[d.Attempts-1]).UTC()
+	d.Status, d.NextAttemptAt = models.WebhookDeliveryRetrying, &next
+}
+
+// attempt signs d's payload now, sends it, and records the outcome on d (not persisted).
+func (s *webhookService) attempt(w *models.Webhook, d *models.WebhookDelivery) *models.SignedWebhookRequest {
+	req := signPayload(w, d.EventID, d.EventType, []byte(d.Payload), time.Now())
+	status, err := s.deliver(w, req)
+	d.Attempts++
+	d.LastStatusCode, d.LastError, d.NextAttemptAt = status, "", nil
+	switch {
+	case err != nil:
+		d.Status, d.LastError = models.WebhookDeliveryFailed, truncate(err.Error(), 500)
+	case status < 200 || status > 299:
+		d.Status, d.LastError = models.WebhookDeliveryFailed, fmt.Sprintf("target answered %d", status)
+	default:
+		d.Status = models.WebhookDeliverySucceeded
+	}
+	return req
+}
+
+// newWebhookEvent wraps data in the delivery envelope with a fresh event ID.
+func newWebhookEvent(typ string, data interface{}) (models.WebhookEvent, error) {
+	id, err := randomHex(16)
+	if err != nil {
+		return models.WebhookEvent{}, err
+	}
+	return models.WebhookEvent{ID: "evt_" + id, Type: typ, CreatedAt: time.Now().UTC(), Data: data}, nil
+}
+
+// signPayload renders the exact request a target receives: JSON body plus timestamped HMAC.
+func signPayload(w *models.Webhook, eventID, eventType string, body []byte, at time.Time) *models.SignedWebhookRequest {
+	return &models.SignedWebhookRequest{
+		Headers: map[string]string{
+			"Content-Type":       "application/json",
+			"X-Webhook-Event":    eventType,
+			"X-Webhook-ID":       eventID,
+			webhookverify.Header: webhookverify.Sign(w.Secret, body, at),
+		},
+		Body: string(body),
+	}
+}
+
+// deliver POSTs a signed request and returns the target's status code (2xx = accepted).
+func (s *webhookService) deliver(w *models.Webhook, req *models.SignedWebhookRequest) (int, error) {
+	httpReq, err := http.NewRequest(http.MethodPost, w.URL, bytes.NewBufferString(req.Body))
+	if err != nil {
+		return 0, err
+	}
+	for k, v := range req.Headers {
+		httpReq.Header.Set(k, v)
+	}
+	resp, err := s.client.Do(httpReq)
+	if err != nil {
+		if s.log != nil { s.log.Warn("webhook delivery failed", map[string]string{"webhook_id": fmt.Sprint(w.ID), "err": err.Error()}) }
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10)) // drain so the connection is reused
+	return resp.StatusCode, nil
+}
+
+// truncate keeps error text within its column.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
+
+// randomHex returns n random bytes, hex-encoded.
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
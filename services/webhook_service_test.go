@@ -0,0 +1,200 @@
+package services
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"HelmyTask/core"
+	"HelmyTask/mocks"
+	"HelmyTask/models"
+	"HelmyTask/scripting"
+	"HelmyTask/webhookverify"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestWebhookService_Create_RejectsUnsafeTargets(t *testing.T) {
+	repo := new(mocks.WebhookRepositoryMock)
+	svc := NewWebhookService(repo, new(mocks.WebhookDeliveryRepositoryMock), http.DefaultClient, []string{"hooks.internal"}, nil, nil)
+
+	for _, u := range []string{
+		"http://8.8.8.8/hook",             // not https
+		"https://169.254.169.254/latest/", // cloud metadata
+		"https://[::1]/hook",              // loopback
+	} {
+		_, err := svc.Create(core.UserID(1), models.CreateWebhookRequest{URL: u})
+		assert.ErrorIs(t, err, ErrUnsafeWebhookURL, u)
+	}
+	repo.AssertNotCalled(t, "Create", mock.Anything)
+
+	// public IP literal, and a trusted internal host over plain http
+	repo.On("Create", mock.AnythingOfType("*models.Webhook")).Return(nil)
+	for _, u := range []string{"https://8.8.8.8/hook", "http://hooks.internal/hook"} {
+		w, err := svc.Create(core.UserID(1), models.CreateWebhookRequest{URL: u})
+		assert.NoError(t, err, u)
+		assert.Equal(t, uint(1), w.CreatedByID)
+		assert.Contains(t, w.Secret, webhookSecretPrefix)
+	}
+}
+
+func TestWebhookService_Test_SendsVerifiableSignature(t *testing.T) {
+	var got error
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got = webhookverify.Verify("whsec_test", r.Header.Get(webhookverify.Header), body, 0, time.Now())
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	repo := new(mocks.WebhookRepositoryMock)
+	repo.On("FindByID", uint(5)).Return(&models.Webhook{ID: 5, URL: srv.URL, Secret: "whsec_test"}, nil)
+	deliveries := new(mocks.WebhookDeliveryRepositoryMock)
+	deliveries.On("Create", mock.MatchedBy(func(d *models.WebhookDelivery) bool {
+		return d.Status == models.WebhookDeliverySucceeded && d.Attempts == 1
+	})).Return(nil)
+	svc := NewWebhookService(repo, deliveries, srv.Client(), nil, nil, nil)
+
+	res, err := svc.Test(5)
+	require.NoError(t, err)
+	assert.NoError(t, got, "consumer-side verification passes")
+	assert.Equal(t, http.StatusNoContent, res.StatusCode)
+	assert.Contains(t, res.Request.Body, `"type":"webhook.test"`)
+	assert.NoError(t, webhookverify.Verify("whsec_test", res.Request.Headers[webhookverify.Header], []byte(res.Request.Body), 0, time.Now()))
+}
+
+func TestWebhookService_Replay(t *testing.T) {
+	hits := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { hits++ }))
+	defer srv.Close()
+
+	repo := new(mocks.WebhookRepositoryMock)
+	repo.On("FindByID", uint(5)).Return(&models.Webhook{ID: 5, URL: srv.URL, Secret: "whsec_test"}, nil)
+	repo.On("FindByID", uint(6)).Return(nil, gorm.ErrRecordNotFound)
+	repo.On("FindByID", uint(7)).Return(nil, errors.New("connection refused"))
+	deliveries := new(mocks.WebhookDeliveryRepositoryMock)
+	svc := NewWebhookService(repo, deliveries, srv.Client(), nil, nil, nil)
+
+	// nothing selected, or too much
+	_, err := svc.Replay(models.ReplayWebhooksRequest{})
+	assert.ErrorIs(t, err, ErrReplaySelection)
+	_, err = svc.Replay(models.ReplayWebhooksRequest{IDs: make([]uint, maxReplayBatch+1)})
+	assert.ErrorIs(t, err, ErrReplayTooMany)
+
+	// range replay only picks failed deliveries and queues them for the retry worker;
+	// a deleted target is reported, not queued
+	from := time.Now().Add(-time.Hour)
+	failed := []models.WebhookDelivery{
+		{ID: 1, WebhookID: 5, EventID: "evt_1", EventType: "user.registered", Payload: `{}`, Status: models.WebhookDeliveryFailed, Attempts: 6},
+		{ID: 2, WebhookID: 6, EventID: "evt_2", EventType: "user.registered", Payload: `{}`, Status: models.WebhookDeliveryFailed, Attempts: 1},
+	}
+	deliveries.On("List", models.WebhookDeliveryFilter{Status: models.WebhookDeliveryFailed, From: &from}, 0, maxReplayBatch).Return(failed, int64(2), nil)
+	deliveries.On("Update", mock.Anything).Return(nil)
+
+	res, err := svc.Replay(models.ReplayWebhooksRequest{From: core.TimestampPtr(&from)})
+	require.NoError(t, err)
+	assert.Equal(t, 1, res.Queued)
+	assert.Zero(t, hits, "nothing is sent inside the request")
+	assert.Equal(t, models.WebhookDeliveryRetrying, res.Items[0].Status)
+	require.NotNil(t, res.Items[0].NextAttemptAt)
+	assert.WithinDuration(t, time.Now(), *res.Items[0].NextAttemptAt, 5*time.Second)
+	assert.Equal(t, 6, res.Items[0].Attempts)
+	assert.Equal(t, models.WebhookDeliveryFailed, res.Items[1].Status)
+	assert.Equal(t, "webhook no longer exists", res.Items[1].LastError)
+
+	// the worker picks the queued delivery up
+	now := time.Now()
+	deliveries.On("FindDue", now, maxReplayBatch).Return([]models.WebhookDelivery{res.Items[0]}, nil).Once()
+	n, err := svc.RetryDue(now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, 1, hits)
+
+	// a failed lookup is an error, not a deleted webhook
+	deliveries.On("FindByIDs", []uint{3}).Return([]models.WebhookDelivery{{ID: 3, WebhookID: 7, Status: models.WebhookDeliveryFailed}}, nil)
+	_, err = svc.Replay(models.ReplayWebhooksRequest{IDs: []uint{3}})
+	assert.EqualError(t, err, "connection refused")
+}
+
+func TestWebhookService_DispatchRetriesOnBackoff(t *testing.T) {
+	var fail bool
+	var events []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		events = append(events, r.Header.Get("X-Webhook-Event"))
+		if fail {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer srv.Close()
+
+	target := models.Webhook{ID: 5, URL: srv.URL, Secret: "whsec_test"}
+	repo := new(mocks.WebhookRepositoryMock)
+	repo.On("List").Return([]models.Webhook{target}, nil)
+	repo.On("FindByID", uint(5)).Return(&target, nil)
+	deliveries := new(mocks.WebhookDeliveryRepositoryMock)
+	var logged *models.WebhookDelivery
+	deliveries.On("Create", mock.AnythingOfType("*models.WebhookDelivery")).Run(func(args mock.Arguments) {
+		logged = args.Get(0).(*models.WebhookDelivery)
+	}).Return(nil)
+	svc := NewWebhookService(repo, deliveries, srv.Client(), nil, nil, nil)
+
+	// the target is down: the delivery waits for its first retry
+	fail = true
+	require.NoError(t, svc.Dispatch(WebhookUserRegistered, models.User{ID: 9, Email: "a@b.c"}))
+	require.NotNil(t, logged)
+	assert.Equal(t, models.WebhookDeliveryRetrying, logged.Status)
+	assert.Equal(t, "target answered 502", logged.LastError)
+	require.NotNil(t, logged.NextAttemptAt)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), *logged.NextAttemptAt, 5*time.Second)
+	assert.Contains(t, logged.Payload, `"email":"a@b.c"`)
+
+	// still down on the last attempt: the failure is final
+	now := time.Now()
+	last := *logged
+	last.Attempts = len(webhookRetryDelays)
+	deliveries.On("FindDue", now, maxReplayBatch).Return([]models.WebhookDelivery{last}, nil).Once()
+	deliveries.On("Update", mock.MatchedBy(func(d *models.WebhookDelivery) bool {
+		return d.Status == models.WebhookDeliveryFailed && d.NextAttemptAt == nil && d.Attempts == len(webhookRetryDelays)+1
+	})).Return(nil).Once()
+	n, err := svc.RetryDue(now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	// back up: the retry succeeds
+	fail = false
+	deliveries.On("FindDue", now, maxReplayBatch).Return([]models.WebhookDelivery{*logged}, nil).Once()
+	deliveries.On("Update", mock.MatchedBy(func(d *models.WebhookDelivery) bool {
+		return d.Status == models.WebhookDeliverySucceeded && d.NextAttemptAt == nil && d.Attempts == 2
+	})).Return(nil).Once()
+	_, err = svc.RetryDue(now)
+	require.NoError(t, err)
+	deliveries.AssertExpectations(t)
+	assert.Equal(t, []string{WebhookUserRegistered, WebhookUserRegistered, WebhookUserRegistered}, events)
+}
+
+func TestWebhookService_DispatchAppliesFilter(t *testing.T) {
+	var events []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		events = append(events, r.Header.Get("X-Webhook-Event"))
+	}))
+	defer srv.Close()
+
+	repo := new(mocks.WebhookRepositoryMock)
+	repo.On("List").Return([]models.Webhook{{ID: 5, URL: srv.URL, Secret: "whsec_test"}}, nil)
+	deliveries := new(mocks.WebhookDeliveryRepositoryMock)
+	deliveries.On("Create", mock.AnythingOfType("*models.WebhookDelivery")).Return(nil).Once()
+	filter, err := scripting.New(scripting.Spec{WebhookFilter: `data.email != "quiet@b.c"`})
+	require.NoError(t, err)
+	svc := NewWebhookService(repo, deliveries, srv.Client(), nil, filter, nil)
+
+	require.NoError(t, svc.Dispatch(WebhookUserRegistered, models.User{ID: 9, Email: "quiet@b.c"}))
+	require.NoError(t, svc.Dispatch(WebhookUserRegistered, models.User{ID: 10, Email: "loud@b.c"}))
+	assert.Equal(t, []string{WebhookUserRegistered}, events, "the filtered event is neither sent nor logged")
+	deliveries.AssertExpectations(t)
+}
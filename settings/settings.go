@@ -0,0 +1,340 @@
+// Package settings holds the runtime-tunable part of the configuration: log level, Redis log
+// size limits, rate limits, user cache TTL, maintenance mode and feature flags.
+//
+// Base values come from config.yaml/env, and follow edits of the file (SetBase). Admins can override a curated subset with
+// PUT /admin/settings; overrides live in Redis ("settings:overrides") and are merged over the
+// base, so every replica picks them up (RefreshEvery) without a redeploy. Readers call
+// Current(), which never touches Redis.
+package settings
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"HelmyTask/core"
+	"HelmyTask/utils/ratelimit"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisKey holds the JSON-encoded Overrides.
+const redisKey = "settings:overrides"
+
+// Violation codes for rejected overrides.
+const (
+	CodeLogLevelInvalid     = "log_level_invalid"
+	CodeLogMaxLenInvalid    = "log_max_len_invalid"
+	CodeLogRetentionInvalid = "log_retention_invalid"
+	CodeRateInvalid         = "rate_limit_invalid"
+	CodeTTLInvalid          = "cache_ttl_invalid"
+	CodeFlagInvalid         = "feature_flag_invalid"
+)
+
+// LogLevels in increasing severity; entries below the configured level are dropped.
+var LogLevels = []string{"debug", "info", "warn", "error"}
+
+// maxCacheTTL bounds cache_ttl so a typo can't pin stale users for days.
+const maxCacheTTL = 24 * time.Hour
+
+// Bounds for the Redis log limits, so a typo can't fill Redis with log entries.
+const (
+	maxLogMaxLen    = 1000000
+	maxLogRetention = 30 * 24 * time.Hour
+)
+
+var flagName = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]{0,63}$`)
+
+// RateLimit is ratelimit.Rule with JSON names.
+type RateLimit struct {
+	RequestsPerMinute int `json:"requests_per_minute"` // 0 = unlimited
+	Burst             int `json:"burst"`
+}
+
+// Settings is the effective configuration.
+type Settings struct {
+	LogLevel           string               `json:"log_level"`
+	LogMaxLen          int64                `json:"log_max_len"`   // entries kept in the Redis log
+	LogRetention       string               `json:"log_retention"` // Go duration; "0s" = no age limit
+	RateLimits         map[string]RateLimit `json:"rate_limits"`   // by route group, e.g. "auth"
+	CacheTTL           string               `json:"cache_ttl"`     // Go duration, e.g. "10m"
+	MaintenanceMode    bool                 `json:"maintenance_mode"`
+	MaintenanceMessage string               `json:"maintenance_message,omitempty"`
+	FeatureFlags       map[string]bool      `json:"feature_flags"`
+}
+
+// Overrides is what PUT /admin/settings stores; nil/absent fields fall back to the base.
+// Rate limits and feature flags are merged key by key.
+type Overrides struct {
+	LogLevel           *string              `json:"log_level,omitempty"`
+	LogMaxLen          *int64               `json:"log_max_len,omitempty"`
+	LogRetention       *string              `json:"log_retention,omitempty"`
+	RateLimits         map[string]RateLimit `json:"rate_limits,omitempty"`
+	CacheTTL           *string              `json:"cache_ttl,omitempty"`
+	MaintenanceMode    *bool                `json:"maintenance_mode,omitempty"`
+	MaintenanceMessage *string              `json:"maintenance_message,omitempty"`
+	FeatureFlags       map[string]bool      `json:"feature_flags,omitempty"`
+}
+
+// Rule returns the limiter rule for a route group (zero = unlimited).
+func (s Settings) Rule(group string) ratelimit.Rule {
+	r := s.RateLimits[group]
+	return ratelimit.Rule{RequestsPerMinute: r.RequestsPerMinute, Burst: r.Burst}
+}
+
+// Retention is LogRetention parsed (validated on the way in).
+func (s Settings) Retention() time.Duration {
+	d, _ := time.ParseDuration(s.LogRetention)
+	return d
+}
+
+// TTL is CacheTTL parsed (validated on the way in).
+func (s Settings) TTL() time.Duration {
+	d, _ := time.ParseDuration(s.CacheTTL)
+	return d
+}
+
+// Feature reports whether a flag is on; unknown flags are off.
+func (s Settings) Feature(name string) bool {
+	return s.FeatureFlags[name]
+}
+
+// Validate checks every field that is set.
+func (o Overrides) Validate() core.Violations {
+	var v core.Violations
+	if o.LogLevel != nil && !validLevel(*o.LogLevel) {
+		v = append(v, core.Violation{Field: "log_level", Code: CodeLogLevelInvalid, Message: fmt.Sprintf("must be one of %v", LogLevels)})
+	}
+	if o.LogMaxLen != nil && (*o.LogMaxLen < 1 || *o.LogMaxLen > maxLogMaxLen) {
+		v = append(v, core.Violation{Field: "log_max_len", Code: CodeLogMaxLenInvalid, Message: fmt.Sprintf("must be between 1 and %d", maxLogMaxLen)})
+	}
+	if o.LogRetention != nil {
+		if d, err := time.ParseDuration(*o.LogRetention); err != nil || d < 0 || d > maxLogRetention {
+			v = append(v, core.Violation{Field: "log_retention", Code: CodeLogRetentionInvalid, Message: "must be a duration between 0s (no age limit) and 720h, e.g. \"168h\""})
+		}
+	}
+	for _, group := range sortedKeys(o.RateLimits) {
+		if r := o.RateLimits[group]; r.RequestsPerMinute < 0 || r.Burst < 0 {
+			v = append(v, core.Violation{Field: "rate_limits." + group, Code: CodeRateInvalid, Message: "requests_per_minute and burst must be >= 0"})
+		}
+	}
+	if o.CacheTTL != nil {
+		if d, err := time.ParseDuration(*o.CacheTTL); err != nil || d <= 0 || d > maxCacheTTL {
+			v = append(v, core.Violation{Field: "cache_ttl", Code: CodeTTLInvalid, Message: "must be a duration between 1s and 24h, e.g. \"10m\""})
+		}
+	}
+	for name := range o.FeatureFlags {
+		if !flagName.MatchString(name) {
+			v = append(v, core.Violation{Field: "feature_flags", Code: CodeFlagInvalid, Message: fmt.Sprintf("invalid flag name %q (lower-case letters, digits, _ . -)", name)})
+		}
+	}
+	return v
+}
+
+// Apply merges o over base.
+func (o Overrides) Apply(base Settings) Settings {
+	out := base
+	out.RateLimits = make(map[string]RateLimit, len(base.RateLimits)+len(o.RateLimits))
+	for k, r := range base.RateLimits {
+		out.RateLimits[k] = r
+	}
+	for k, r := range o.RateLimits {
+		out.RateLimits[k] = r
+	}
+	out.FeatureFlags = make(map[string]bool, len(base.FeatureFlags)+len(o.FeatureFlags))
+	for k, on := range base.FeatureFlags {
+		out.FeatureFlags[k] = on
+	}
+	for k, on := range o.FeatureFlags {
+		out.FeatureFlags[k] = on
+	}
+	if o.LogLevel != nil {
+		out.LogLevel = *o.LogLevel
+	}
+	if o.LogMaxLen != nil {
+		out.LogMaxLen = *o.LogMaxLen
+	}
+	if o.LogRetention != nil {
+		out.LogRetention = *o.LogRetention
+	}
+	if o.CacheTTL != nil {
+		out.CacheTTL = *o.CacheTTL
+	}
+	if o.MaintenanceMode != nil {
+		out.MaintenanceMode = *o.MaintenanceMode
+	}
+	if o.MaintenanceMessage != nil {
+		out.MaintenanceMessage = *o.MaintenanceMessage
+	}
+	return out
+}
+
+// Store serves the effective settings and persists overrides.
+type Store struct {
+	rdb *redis.Client
+
+	base      atomic.Pointer[Settings] // replaced by SetBase when the config file changes
+	current   atomic.Pointer[Settings]
+	overrides atomic.Pointer[Overrides]
+	mu        sync.Mutex // serializes apply + listeners
+	listeners []func(Settings)
+	version   string // last applied overrides JSON; skips no-op refreshes
+}
+
+// New validates the base settings (from config) and starts with no overrides.
+// rdb may be nil: overrides are then refused and the base is always in effect.
+func New(rdb *redis.Client, base Settings) (*Store, error) {
+	if err := validateBase(base); err != nil {
+		return nil, err
+	}
+	b := Overrides{}.Apply(base)
+	s := &Store{rdb: rdb}
+	s.base.Store(&b)
+	s.current.Store(&b)
+	s.overrides.Store(&Overrides{})
+	return s, nil
+}
+
+// validateBase checks the base with the rules overrides follow.
+func validateBase(base Settings) error {
+	o := Overrides{LogLevel: &base.LogLevel, LogMaxLen: &base.LogMaxLen, LogRetention: &base.LogRetention, CacheTTL: &base.CacheTTL, RateLimits: base.RateLimits, FeatureFlags: base.FeatureFlags}
+	return o.Validate().Err()
+}
+
+// Current is the effective configuration (base + overrides); cheap enough for every request.
+func (s *Store) Current() Settings { return *s.current.Load() }
+
+// Base is the file/env configuration without overrides.
+func (s *Store) Base() Settings { return *s.base.Load() }
+
+// Overrides is the override set this replica last applied.
+func (s *Store) Overrides() Overrides { return *s.overrides.Load() }
+
+// OnChange registers fn and calls it right away with the current settings, then again
+// whenever the effective settings change on this replica.
+func (s *Store) OnChange(fn func(Settings)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.listeners = append(s.listeners, fn)
+	fn(s.Current())
+}
+
+// Set validates and stores a new override set (replacing the previous one) and applies it
+// here at once; other replicas follow on their next refresh.
+func (s *Store) Set(ctx context.Context, o Overrides) (Settings, error) {
+	if err := o.Validate().Err(); err != nil {
+		return Settings{}, err
+	}
+	if s.rdb == nil {
+		return Settings{}, errors.New("settings overrides need Redis")
+	}
+	b, _ := json.Marshal(o)
+	if err := s.rdb.Set(ctx, redisKey, b, 0).Err(); err != nil {
+		return Settings{}, err
+	}
+	s.apply(string(b), o)
+	return s.Current(), nil
+}
+
+// Refresh loads the overrides from Redis and applies them. Invalid stored overrides (written
+// by hand, or by an older release) are refused and the previous settings are kept.
+func (s *Store) Refresh(ctx context.Context) error {
+	if s.rdb == nil {
+		return nil
+	}
+	raw, err := s.rdb.Get(ctx, redisKey).Result()
+	if errors.Is(err, redis.Nil) {
+		raw, err = "{}", nil
+	}
+	if err != nil {
+		return err
+	}
+	var o Overrides
+	if err := json.Unmarshal([]byte(raw), &o); err != nil {
+		return fmt.Errorf("settings: stored overrides: %w", err)
+	}
+	if err := o.Validate().Err(); err != nil {
+		return fmt.Errorf("settings: stored overrides: %w", err)
+	}
+	s.apply(raw, o)
+	return nil
+}
+
+// SetBase replaces the base (the config file was edited) and applies the current overrides
+// over it, notifying listeners if that changes anything. An invalid base is refused and the
+// previous one stays.
+func (s *Store) SetBase(base Settings) error {
+	if err := validateBase(base); err != nil {
+		return err
+	}
+	b := Overrides{}.Apply(base)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if reflect.DeepEqual(b, *s.base.Load()) {
+		return nil
+	}
+	s.base.Store(&b)
+	s.publish(*s.overrides.Load())
+	return nil
+}
+
+// RefreshEvery calls Refresh until ctx is cancelled; errors are handed to onErr (may be nil).
+func (s *Store) RefreshEvery(ctx context.Context, every time.Duration, onErr func(error)) {
+	t := time.NewTicker(every)
+	defer t.Stop()
+	for {
+		if err := s.Refresh(ctx); err != nil && onErr != nil && ctx.Err() == nil {
+			onErr(err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+func (s *Store) apply(version string, o Overrides) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if version == s.version {
+		return
+	}
+	s.version = version
+	s.publish(o)
+}
+
+// publish makes o over the base the effective settings and tells the listeners (s.mu held).
+func (s *Store) publish(o Overrides) {
+	eff := o.Apply(*s.base.Load())
+	s.overrides.Store(&o)
+	s.current.Store(&eff)
+	for _, fn := range s.listeners {
+		fn(eff)
+	}
+}
+
+func validLevel(level string) bool {
+	for _, l := range LogLevels {
+		if l == level {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(m map[string]RateLimit) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
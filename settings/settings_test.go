@@ -0,0 +1,113 @@
+package settings
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"HelmyTask/core"
+	"HelmyTask/mocks"
+	"HelmyTask/utils/ratelimit"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func base() Settings {
+	return Settings{LogLevel: "info", LogMaxLen: 1000, LogRetention: "168h", CacheTTL: "10m", RateLimits: map[string]RateLimit{"auth": {RequestsPerMinute: 10, Burst: 5}},
+		FeatureFlags: map[string]bool{"beta": false}}
+}
+
+func TestOverrides_ApplyMergesOverBase(t *testing.T) {
+	level, on := "warn", true
+	eff := Overrides{LogLevel: &level, MaintenanceMode: &on,
+		RateLimits:   map[string]RateLimit{"exports": {RequestsPerMinute: 2}},
+		FeatureFlags: map[string]bool{"beta": true}}.Apply(base())
+
+	assert.Equal(t, "warn", eff.LogLevel)
+	assert.True(t, eff.MaintenanceMode)
+	assert.Equal(t, 10*time.Minute, eff.TTL(), "not overridden")
+	assert.Equal(t, 168*time.Hour, eff.Retention(), "not overridden")
+	assert.Equal(t, ratelimit.Rule{RequestsPerMinute: 10, Burst: 5}, eff.Rule("auth"), "other groups keep their base rule")
+	assert.Equal(t, ratelimit.Rule{RequestsPerMinute: 2}, eff.Rule("exports"))
+	assert.True(t, eff.Feature("beta"))
+	assert.False(t, eff.Feature("unknown"))
+}
+
+func TestOverrides_Validate(t *testing.T) {
+	level, ttl, maxLen, retention := "verbose", "3d", int64(0), "-1h"
+	v := Overrides{LogLevel: &level, CacheTTL: &ttl, LogMaxLen: &maxLen, LogRetention: &retention,
+		RateLimits:   map[string]RateLimit{"auth": {RequestsPerMinute: -1}},
+		FeatureFlags: map[string]bool{"Bad Name": true}}.Validate()
+	codes := []string{}
+	for _, x := range v {
+		codes = append(codes, x.Code)
+	}
+	assert.ElementsMatch(t, []string{CodeLogLevelInvalid, CodeLogMaxLenInvalid, CodeLogRetentionInvalid, CodeTTLInvalid, CodeRateInvalid, CodeFlagInvalid}, codes)
+}
+
+func TestStore_SetAndRefresh(t *testing.T) {
+	rdb, m := mocks.NewRedisMock()
+	s, err := New(rdb, base())
+	require.NoError(t, err)
+	var seen []string
+	s.OnChange(func(cur Settings) { seen = append(seen, cur.LogLevel) })
+
+	level := "error"
+	m.ExpectSet(redisKey, []byte(`{"log_level":"error"}`), 0).SetVal("OK")
+	eff, err := s.Set(context.Background(), Overrides{LogLevel: &level})
+	require.NoError(t, err)
+	assert.Equal(t, "error", eff.LogLevel)
+	assert.Equal(t, "error", s.Current().LogLevel)
+
+	// another replica cleared the overrides
+	m.ExpectGet(redisKey).SetVal(`{}`)
+	require.NoError(t, s.Refresh(context.Background()))
+	assert.Equal(t, "info", s.Current().LogLevel)
+
+	// unchanged overrides don't notify again
+	m.ExpectGet(redisKey).SetVal(`{}`)
+	require.NoError(t, s.Refresh(context.Background()))
+	assert.Equal(t, []string{"info", "error", "info"}, seen)
+
+	// invalid stored overrides are refused; the previous settings stay
+	m.ExpectGet(redisKey).SetVal(`{"cache_ttl":"forever"}`)
+	assert.Error(t, s.Refresh(context.Background()))
+	assert.Equal(t, 10*time.Minute, s.Current().TTL())
+	assert.NoError(t, m.ExpectationsWereMet())
+
+	// Set rejects invalid overrides before touching Redis
+	bad := "loud"
+	_, err = s.Set(context.Background(), Overrides{LogLevel: &bad})
+	var v core.Violations
+	assert.ErrorAs(t, err, &v)
+}
+
+func TestStore_SetBaseKeepsOverrides(t *testing.T) {
+	rdb, m := mocks.NewRedisMock()
+	s, err := New(rdb, base())
+	require.NoError(t, err)
+	var seen []string
+	s.OnChange(func(cur Settings) { seen = append(seen, cur.LogLevel+"/"+cur.CacheTTL) })
+
+	level := "error"
+	m.ExpectSet(redisKey, []byte(`{"log_level":"error"}`), 0).SetVal("OK")
+	_, err = s.Set(context.Background(), Overrides{LogLevel: &level})
+	require.NoError(t, err)
+
+	// config file edited: new TTL applies, the admin's log level still wins
+	b := base()
+	b.LogLevel, b.CacheTTL = "debug", "5m"
+	require.NoError(t, s.SetBase(b))
+	assert.Equal(t, "debug", s.Base().LogLevel)
+	assert.Equal(t, "error", s.Current().LogLevel)
+	assert.Equal(t, 5*time.Minute, s.Current().TTL())
+
+	// the same base again is not a change
+	require.NoError(t, s.SetBase(b))
+	// an invalid base is refused
+	b.CacheTTL = "forever"
+	assert.Error(t, s.SetBase(b))
+	assert.Equal(t, 5*time.Minute, s.Current().TTL())
+	assert.Equal(t, []string{"info/10m", "error/10m", "error/5m"}, seen)
+}
@@ -0,0 +1,176 @@
+// Package slo tracks request outcomes against service level objectives and reports rolling
+// compliance and the remaining error budget.
+//
+// Every request lands in an hourly Redis hash "slo:<yyyymmddhh>" {total, errors, slow}, shared
+// by all replicas; a report sums the hashes covering the window (hour resolution).
+package slo
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"HelmyTask/utils/redislog"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Objectives are the targets a report is measured against.
+type Objectives struct {
+	Availability     float64       // share of requests not answered with a 5xx, e.g. 0.999
+	LatencyTarget    float64       // share of requests faster than LatencyThreshold, e.g. 0.99
+	LatencyThreshold time.Duration // e.g. 500ms
+	Window           time.Duration // rolling window, e.g. 28 days
+}
+
+// Indicator is one SLI over the window.
+type Indicator struct {
+	Target          float64 `json:"target"`
+	Actual          float64 `json:"actual"`           // 1 when there was no traffic
+	Bad             int64   `json:"bad"`              // requests that missed the objective
+	BudgetRemaining float64 `json:"budget_remaining"` // 1 = untouched, 0 = spent, < 0 = overspent
+	Met             bool    `json:"met"`
+}
+
+// Report is the compliance over the rolling window ending now.
+type Report struct {
+	From         time.Time `json:"from"`
+	To           time.Time `json:"to"`
+	Requests     int64     `json:"requests"`
+	Availability Indicator `json:"availability"`
+	Latency      Indicator `json:"latency"`
+	Threshold    string    `json:"latency_threshold"`
+}
+
+// Tracker records outcomes and builds reports.
+type Tracker struct {
+	rdb *redis.Client
+	obj Objectives
+	now func() time.Time
+}
+
+// New creates a tracker.
+func New(rdb *redis.Client, obj Objectives) *Tracker {
+	return &Tracker{rdb: rdb, obj: obj, now: time.Now}
+}
+
+// Objectives returns the configured targets.
+func (t *Tracker) Objectives() Objectives { return t.obj }
+
+func hourKey(ts time.Time) string { return "slo:" + ts.UTC().Format("2006010215") }
+
+// Record counts one request: 5xx counts against availability, anything slower than the
+// threshold against latency.
+func (t *Tracker) Record(ctx context.Context, status int, latency time.Duration) error {
+	key := hourKey(t.now())
+	_, err := t.rdb.Pipelined(ctx, func(p redis.Pipeliner) error {
+		p.HIncrBy(ctx, key, "total", 1)
+		if status >= 500 {
+			p.HIncrBy(ctx, key, "errors", 1)
+		}
+		if latency > t.obj.LatencyThreshold {
+			p.HIncrBy(ctx, key, "slow", 1)
+		}
+		p.Expire(ctx, key, t.obj.Window+2*time.Hour) // outlives the window by the partial hours at each end
+		return nil
+	})
+	return err
+}
+
+// Report sums the window's hourly buckets.
+func (t *Tracker) Report(ctx context.Context) (*Report, error) {
+	to := t.now().UTC()
+	hours := int(math.Ceil(t.obj.Window.Hours()))
+	if hours < 1 {
+		hours = 1
+	}
+	from := to.Truncate(time.Hour).Add(-time.Duration(hours-1) * time.Hour)
+
+	cmds := make([]*redis.SliceCmd, 0, hours)
+	_, err := t.rdb.Pipelined(ctx, func(p redis.Pipeliner) error {
+		for h := from; !h.After(to); h = h.Add(time.Hour) {
+			cmds = append(cmds, p.HMGet(ctx, hourKey(h), "total", "errors", "slow"))
+		}
+		return nil
+	})
+	if err != nil && err != redis.Nil {
+		return nil, err
+	}
+	var total, errs, slow int64
+	for _, cmd := range cmds {
+		vals, err := cmd.Result()
+		if err != nil {
+			return nil, err
+		}
+		total += parseCount(vals[0])
+		errs += parseCount(vals[1])
+		slow += parseCount(vals[2])
+	}
+	return &Report{
+		From:         from,
+		To:           to,
+		Requests:     total,
+		Availability: indicator(t.obj.Availability, total, errs),
+		Latency:      indicator(t.obj.LatencyTarget, total, slow),
+		Threshold:    t.obj.LatencyThreshold.String(),
+	}, nil
+}
+
+// ReportEvery logs a report each interval until ctx is done: info when every objective is met,
+// warn otherwise. Run it on one replica (leader.Group) so reports aren't duplicated.
+func (t *Tracker) ReportEvery(ctx context.Context, interval time.Duration, rlog *redislog.Logger) {
+	tick := time.NewTicker(interval)
+	defer tick.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick.C:
+		}
+		r, err := t.Report(ctx)
+		if err != nil {
+			rlog.Error("slo report failed", map[string]string{"err": err.Error()})
+			continue
+		}
+		meta := map[string]string{
+			"requests":                 strconv.FormatInt(r.Requests, 10),
+			"availability":             fmt.Sprintf("%.5f", r.Availability.Actual),
+			"availability_budget_left": fmt.Sprintf("%.3f", r.Availability.BudgetRemaining),
+			"latency":                  fmt.Sprintf("%.5f", r.Latency.Actual),
+			"latency_budget_left":      fmt.Sprintf("%.3f", r.Latency.BudgetRemaining),
+			"window":                   t.obj.Window.String(),
+		}
+		if r.Availability.Met && r.Latency.Met {
+			rlog.Info("slo report", meta)
+		} else {
+			rlog.Warn("slo report: objective missed", meta)
+		}
+	}
+}
+
+// indicator computes compliance and the share of the error budget (1-target) still unspent.
+func indicator(target float64, total, bad int64) Indicator {
+	in := Indicator{Target: target, Actual: 1, Bad: bad, BudgetRemaining: 1}
+	if total > 0 {
+		in.Actual = 1 - float64(bad)/float64(total)
+		if allowed := (1 - target) * float64(total); allowed > 0 {
+			in.BudgetRemaining = 1 - float64(bad)/allowed
+		} else if bad > 0 { // 100% target: any miss overspends
+			in.BudgetRemaining = 0
+		}
+	}
+	in.Met = in.Actual >= target
+	return in
+}
+
+// parseCount reads an HMGET field (nil when the hash or field is missing).
+func parseCount(v interface{}) int64 {
+	s, ok := v.(string)
+	if !ok {
+		return 0
+	}
+	n, _ := strconv.ParseInt(s, 10, 64)
+	return n
+}
@@ -0,0 +1,57 @@
+package slo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"HelmyTask/mocks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecord_HourlyCounters(t *testing.T) {
+	rdb, m := mocks.NewRedisMock()
+	tr := New(rdb, Objectives{Availability: 0.999, LatencyTarget: 0.99, LatencyThreshold: 500 * time.Millisecond, Window: 24 * time.Hour})
+	tr.now = func() time.Time { return time.Date(2024, 3, 9, 14, 30, 0, 0, time.UTC) }
+
+	m.ExpectHIncrBy("slo:2024030914", "total", 1).SetVal(1)
+	m.ExpectHIncrBy("slo:2024030914", "errors", 1).SetVal(1)
+	m.ExpectHIncrBy("slo:2024030914", "slow", 1).SetVal(1)
+	m.ExpectExpire("slo:2024030914", 26*time.Hour).SetVal(true)
+	assert.NoError(t, tr.Record(context.Background(), 503, 2*time.Second))
+
+	m.ExpectHIncrBy("slo:2024030914", "total", 1).SetVal(2)
+	m.ExpectExpire("slo:2024030914", 26*time.Hour).SetVal(true)
+	assert.NoError(t, tr.Record(context.Background(), 404, 10*time.Millisecond)) // 4xx is the client's fault
+	assert.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestReport_SumsWindow(t *testing.T) {
+	rdb, m := mocks.NewRedisMock()
+	tr := New(rdb, Objectives{Availability: 0.99, LatencyTarget: 0.9, LatencyThreshold: time.Second, Window: 2 * time.Hour})
+	tr.now = func() time.Time { return time.Date(2024, 3, 9, 14, 30, 0, 0, time.UTC) }
+
+	m.ExpectHMGet("slo:2024030913", "total", "errors", "slow").SetVal([]interface{}{"600", "3", "100"})
+	m.ExpectHMGet("slo:2024030914", "total", "errors", "slow").SetVal([]interface{}{"400", nil, nil}) // quiet hour
+	r, err := tr.Report(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1000), r.Requests)
+
+	// 3 errors of a 10-error budget (1% of 1000)
+	assert.InDelta(t, 0.997, r.Availability.Actual, 1e-9)
+	assert.InDelta(t, 0.7, r.Availability.BudgetRemaining, 1e-9)
+	assert.True(t, r.Availability.Met)
+
+	// 100 slow of a 100-request budget: exactly spent, still met
+	assert.InDelta(t, 0.0, r.Latency.BudgetRemaining, 1e-9)
+	assert.True(t, r.Latency.Met)
+	assert.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestIndicator_NoTrafficAndOverspend(t *testing.T) {
+	assert.Equal(t, Indicator{Target: 0.999, Actual: 1, BudgetRemaining: 1, Met: true}, indicator(0.999, 0, 0))
+	in := indicator(0.99, 100, 3)
+	assert.False(t, in.Met)
+	assert.InDelta(t, -2.0, in.BudgetRemaining, 1e-9)
+}
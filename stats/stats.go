@@ -0,0 +1,70 @@
+// Package stats keeps aggregate numbers for GET /admin/stats precomputed in Redis, so the
+// endpoint never scans the users table. The scheduler's refresh_stats job recomputes them.
+package stats
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"HelmyTask/models"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// usersKey holds the latest Users snapshot as JSON.
+const usersKey = "stats:users"
+
+// UserCounter is the aggregate query behind the snapshot (repositories.StatsRepository).
+type UserCounter interface {
+	CountUsers(ctx context.Context) ([]models.UserCount, error)
+}
+
+// Users is a snapshot of the user base.
+type Users struct {
+	Total       int64            `json:"total"`
+	ByStatus    map[string]int64 `json:"by_status"`
+	ByRole      map[string]int64 `json:"by_role"`
+	GeneratedAt time.Time        `json:"generated_at"` // how stale the numbers are
+}
+
+// Refresh recomputes the snapshot from src and stores it. It is kept without a TTL: a stale
+// snapshot (see GeneratedAt) is more useful than none while the job is failing.
+func Refresh(ctx context.Context, rdb *redis.Client, src UserCounter) (Users, error) {
+	rows, err := src.CountUsers(ctx)
+	if err != nil {
+		return Users{}, err
+	}
+	u := Users{ByStatus: map[string]int64{}, ByRole: map[string]int64{}, GeneratedAt: time.Now().UTC()}
+	for _, r := range rows {
+		status := r.Status
+		if status == "" {
+			status = models.StatusActive // rows from before the status column read as active
+		}
+		u.Total += r.Count
+		u.ByStatus[status] += r.Count
+		u.ByRole[r.Role] += r.Count
+	}
+	b, err := json.Marshal(u)
+	if err != nil {
+		return u, err
+	}
+	return u, rdb.Set(ctx, usersKey, b, 0).Err()
+}
+
+// GetUsers returns the stored snapshot, or nil if none has been computed yet.
+func GetUsers(ctx context.Context, rdb *redis.Client) (*Users, error) {
+	raw, err := rdb.Get(ctx, usersKey).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var u Users
+	if err := json.Unmarshal(raw, &u); err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+
+	"HelmyTask/config"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// serverTLS returns the TLS config of the API server (nil = plain HTTP) and the handler for
+// tls_redirect_port (nil = no plain-HTTP listener). Certificate files are loaded once, here;
+// autocert obtains and renews certificates on first use of each domain.
+func serverTLS(cfg *config.Config) (*tls.Config, http.Handler, error) {
+	if !cfg.TLSEnabled() {
+		return nil, nil, nil
+	}
+	redirect := httpsRedirect(cfg.HTTPPort)
+	if len(cfg.TLSAutocertDomains) > 0 {
+		m := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(cfg.TLSAutocertDomains...), // never ask for a cert for whatever Host a client sends
+			Cache:      autocert.DirCache(cfg.TLSAutocertCacheDir),
+			Email:      cfg.TLSAutocertEmail,
+		}
+		return m.TLSConfig(), m.HTTPHandler(redirect), nil // http-01 challenges first, the redirect for the rest
+	}
+	cert, err := tls.LoadX509KeyPair(cfg.TLSCertFile, cfg.TLSKeyFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}, redirect, nil
+}
+
+// httpsRedirect sends every request to the same host and path over HTTPS on httpsPort
+// (308, so a POST stays a POST with its body).
+func httpsRedirect(httpsPort string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if httpsPort != "443" {
+			host = net.JoinHostPort(host, httpsPort)
+		}
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusPermanentRedirect)
+	})
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"HelmyTask/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPSRedirect(t *testing.T) {
+	for port, want := range map[string]string{
+		"8443": "https://api.example.com:8443/api/v1/users?limit=5",
+		"443":  "https://api.example.com/api/v1/users?limit=5",
+	} {
+		w := httptest.NewRecorder()
+		httpsRedirect(port).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "http://api.example.com:8080/api/v1/users?limit=5", nil))
+		assert.Equal(t, http.StatusPermanentRedirect, w.Code)
+		assert.Equal(t, want, w.Header().Get("Location"))
+	}
+}
+
+func TestServerTLS(t *testing.T) {
+	tlsConfig, redirect, err := serverTLS(&config.Config{HTTPPort: "8080"})
+	require.NoError(t, err)
+	assert.Nil(t, tlsConfig, "plain HTTP by default")
+	assert.Nil(t, redirect)
+
+	_, _, err = serverTLS(&config.Config{HTTPPort: "8443", TLSCertFile: "missing.pem", TLSKeyFile: "missing.key"})
+	assert.Error(t, err)
+
+	tlsConfig, redirect, err = serverTLS(&config.Config{HTTPPort: "443", TLSAutocertDomains: []string{"api.example.com"}, TLSAutocertCacheDir: t.TempDir()})
+	require.NoError(t, err)
+	assert.NotNil(t, tlsConfig.GetCertificate)
+	assert.NotNil(t, redirect)
+}
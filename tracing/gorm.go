@@ -0,0 +1,105 @@
+package tracing
+
+import (
+	"errors"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.41.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// spanKey is where the open span waits between the before and after callbacks.
+const spanKey = "tracing:span"
+
+// dbSystems maps GORM dialector names to semantic-convention db.system.name values.
+var dbSystems = map[string]string{"mysql": "mysql", "postgres": "postgresql", "sqlite": "sqlite", "sqlserver": "microsoft.sql_server"}
+
+// gormPlugin wraps every GORM operation in a client span.
+type gormPlugin struct{ tracer trace.Tracer }
+
+// GORM returns a plugin (db.Use) that records one span per statement: "SELECT users",
+// "INSERT users", ... with the SQL text (placeholders, not values), table and row count.
+// tp nil = the global provider.
+func GORM(tp trace.TracerProvider) gorm.Plugin {
+	return &gormPlugin{tracer: tracer(tp)}
+}
+
+func (p *gormPlugin) Name() string { return "tracing" }
+
+// Initialize registers before/after callbacks on each GORM processor.
+func (p *gormPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	register := []struct {
+		op     string
+		before func(name string, fn func(*gorm.DB)) error
+		after  func(name string, fn func(*gorm.DB)) error
+	}{
+		{"INSERT", cb.Create().Before("gorm:create").Register, cb.Create().After("gorm:create").Register},
+		{"SELECT", cb.Query().Before("gorm:query").Register, cb.Query().After("gorm:query").Register},
+		{"UPDATE", cb.Update().Before("gorm:update").Register, cb.Update().After("gorm:update").Register},
+		{"DELETE", cb.Delete().Before("gorm:delete").Register, cb.Delete().After("gorm:delete").Register},
+		{"ROW", cb.Row().Before("gorm:row").Register, cb.Row().After("gorm:row").Register},
+		{"RAW", cb.Raw().Before("gorm:raw").Register, cb.Raw().After("gorm:raw").Register},
+	}
+	for _, r := range register {
+		if err := r.before("tracing:before_"+r.op, p.before(r.op)); err != nil {
+			return err
+		}
+		if err := r.after("tracing:after_"+r.op, p.after(r.op)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *gormPlugin) before(op string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		ctx, span := p.tracer.Start(db.Statement.Context, spanName(op, db.Statement.Table), trace.WithSpanKind(trace.SpanKindClient))
+		db.Statement.Context = ctx
+		db.InstanceSet(spanKey, span)
+	}
+}
+
+func (p *gormPlugin) after(op string) func(*gorm.DB) {
+	return func(db *gorm.DB) { p.end(op, db) }
+}
+
+func (p *gormPlugin) end(op string, db *gorm.DB) {
+	v, ok := db.InstanceGet(spanKey)
+	if !ok {
+		return
+	}
+	span := v.(trace.Span)
+	defer span.End()
+	if op == "ROW" || op == "RAW" { // name after the statement's own verb, known only now
+		if verb, _, _ := strings.Cut(strings.TrimSpace(db.Statement.SQL.String()), " "); verb != "" {
+			span.SetName(spanName(strings.ToUpper(verb), db.Statement.Table))
+		}
+	}
+	attrs := []attribute.KeyValue{semconv.DBQueryText(db.Statement.SQL.String())} // "?" placeholders: bound values never leave the process
+	if op == "SELECT" {
+		attrs = append(attrs, semconv.DBResponseReturnedRows(int(db.RowsAffected)))
+	}
+	if system := dbSystems[db.Dialector.Name()]; system != "" {
+		attrs = append(attrs, semconv.DBSystemNameKey.String(system))
+	}
+	if db.Statement.Table != "" {
+		attrs = append(attrs, semconv.DBCollectionName(db.Statement.Table))
+	}
+	span.SetAttributes(attrs...)
+	if err := db.Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) { // not found is an answer, not a failure
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
+
+// spanName is "<VERB> <table>" per the database span conventions, or just the verb.
+func spanName(verb, table string) string {
+	if table == "" {
+		return verb
+	}
+	return verb + " " + table
+}
@@ -0,0 +1,64 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.41.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// redisHook wraps every command and pipeline in a client span.
+type redisHook struct{ tracer trace.Tracer }
+
+// Redis returns a go-redis hook (rdb.AddHook) that records one span per command ("GET",
+// "EVALSHA", ...) or pipeline ("PIPELINE"). Only command names are recorded: keys and values
+// may hold user data. tp nil = the global provider.
+func Redis(tp trace.TracerProvider) redis.Hook {
+	return &redisHook{tracer: tracer(tp)}
+}
+
+func (h *redisHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return next(ctx, network, addr) // connections are pooled; dials aren't worth a span
+	}
+}
+
+func (h *redisHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		op := strings.ToUpper(cmd.Name())
+		ctx, span := h.tracer.Start(ctx, op, trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(semconv.DBSystemNameRedis, semconv.DBOperationName(op)))
+		defer span.End()
+		err := next(ctx, cmd)
+		h.fail(span, err)
+		return err
+	}
+}
+
+func (h *redisHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		names := make([]string, len(cmds))
+		for i, cmd := range cmds {
+			names[i] = strings.ToUpper(cmd.Name())
+		}
+		ctx, span := h.tracer.Start(ctx, "PIPELINE", trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(semconv.DBSystemNameRedis, semconv.DBOperationName("PIPELINE "+strings.Join(names, " "))))
+		defer span.End()
+		err := next(ctx, cmds)
+		h.fail(span, err)
+		return err
+	}
+}
+
+// fail marks the span failed; redis.Nil (key absent) is a normal answer.
+func (h *redisHook) fail(span trace.Span, err error) {
+	if err != nil && !errors.Is(err, redis.Nil) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
@@ -0,0 +1,80 @@
+// Package tracing sets up OpenTelemetry distributed tracing: an OTLP/HTTP exporter, the
+// global tracer provider and W3C trace-context propagation, plus instrumentation for GORM
+// (GORM) and go-redis (Redis). HTTP server spans come from middlewares.Tracing.
+//
+// Spans nest through context.Context: a DB query or Redis command becomes a child of the
+// request span when it runs with the request's context (gorm's WithContext, go-redis' ctx
+// argument); calls made with context.Background() start their own trace.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.41.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentation is the tracer name every span here is created under.
+const instrumentation = "HelmyTask/tracing"
+
+// Options configures the exporter and sampler.
+type Options struct {
+	ServiceName string            // service.name, e.g. the app name
+	Environment string            // deployment.environment.name (dev|staging|prod)
+	Endpoint    string            // OTLP/HTTP collector base URL, e.g. http://otel-collector:4318
+	Headers     map[string]string // sent with every export, e.g. an API key for a hosted backend
+	SampleRatio float64           // share of new traces recorded (0..1); incoming sampled parents are always followed
+}
+
+// Provider is the SDK tracer provider; Shutdown flushes buffered spans.
+type Provider = sdktrace.TracerProvider
+
+// Setup builds the OTLP exporter and tracer provider and installs them (and the W3C
+// traceparent/baggage propagator) as the otel globals. The exporter connects lazily, so a
+// collector that is down only costs dropped spans, never a failed boot.
+func Setup(ctx context.Context, opts Options) (*Provider, error) {
+	u, err := url.Parse(opts.Endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("endpoint %q is not an http(s) URL", opts.Endpoint)
+	}
+	if opts.SampleRatio < 0 || opts.SampleRatio > 1 {
+		return nil, fmt.Errorf("sample_ratio %v is not within 0..1", opts.SampleRatio)
+	}
+	exportOpts := []otlptracehttp.Option{otlptracehttp.WithEndpointURL(opts.Endpoint)} // http:// = no TLS
+	if len(opts.Headers) > 0 {
+		exportOpts = append(exportOpts, otlptracehttp.WithHeaders(opts.Headers))
+	}
+	exporter, err := otlptracehttp.New(ctx, exportOpts...)
+	if err != nil {
+		return nil, err
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName(opts.ServiceName), semconv.DeploymentEnvironmentNameKey.String(opts.Environment)))
+	if err != nil {
+		return nil, err
+	}
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(opts.SampleRatio))),
+	)
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return tp, nil
+}
+
+// tracer returns the instrumentation tracer from tp, or from the global provider (a no-op
+// until Setup runs) when tp is nil.
+func tracer(tp trace.TracerProvider) trace.Tracer {
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	return tp.Tracer(instrumentation)
+}
@@ -0,0 +1,118 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+type user struct {
+	ID   uint
+	Name string
+}
+
+func newProvider() (*sdktrace.TracerProvider, *tracetest.SpanRecorder) {
+	rec := tracetest.NewSpanRecorder()
+	return sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)), rec
+}
+
+func attr(s sdktrace.ReadOnlySpan, key attribute.Key) attribute.Value {
+	for _, kv := range s.Attributes() {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestGORM_SpanPerStatementUnderTheRequestSpan(t *testing.T) {
+	tp, rec := newProvider()
+	sqlDB, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+	require.NoError(t, err)
+	defer sqlDB.Close()
+	db, err := gorm.Open(mysql.New(mysql.Config{Conn: sqlDB, SkipInitializeWithVersion: true}), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.Use(GORM(tp)))
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `users` WHERE `users`.`id` = ?")).
+		WithArgs(7, 1).WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(7, "secret name"))
+	mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows([]string{"id"})) // not found
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE").WillReturnError(errors.New("deadlock"))
+	mock.ExpectRollback()
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "GET /users/:id")
+	var u, none user
+	require.NoError(t, db.WithContext(ctx).First(&u, 7).Error)
+	assert.ErrorIs(t, db.WithContext(ctx).First(&none, 8).Error, gorm.ErrRecordNotFound)
+	assert.Error(t, db.WithContext(ctx).Delete(&user{}, 9).Error)
+	parent.End()
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	spans := rec.Ended()
+	require.Len(t, spans, 4)
+	found, missing, failed := spans[0], spans[1], spans[2]
+	assert.Equal(t, "SELECT users", found.Name())
+	assert.Equal(t, parent.SpanContext().SpanID(), found.Parent().SpanID())
+	assert.Equal(t, "mysql", attr(found, "db.system.name").AsString())
+	assert.Equal(t, "users", attr(found, "db.collection.name").AsString())
+	assert.NotContains(t, attr(found, "db.query.text").AsString(), "7", "bound values stay out of spans")
+	assert.Equal(t, int64(1), attr(found, "db.response.returned_rows").AsInt64())
+
+	assert.Equal(t, codes.Unset, missing.Status().Code, "record not found is not an error")
+	assert.Equal(t, "DELETE users", failed.Name())
+	assert.Equal(t, codes.Error, failed.Status().Code)
+}
+
+func TestRedis_SpanPerCommandWithoutKeys(t *testing.T) {
+	tp, rec := newProvider()
+	hook := Redis(tp)
+	// Drive the hook directly: redismock answers from its own hook, ahead of any added later.
+	process := hook.ProcessHook(func(ctx context.Context, cmd redis.Cmder) error {
+		if cmd.Name() == "get" {
+			return redis.Nil
+		}
+		return errors.New("READONLY")
+	})
+	pipeline := hook.ProcessPipelineHook(func(context.Context, []redis.Cmder) error { return nil })
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "GET /me")
+	assert.ErrorIs(t, process(ctx, redis.NewStringCmd(ctx, "get", "user:42")), redis.Nil)
+	assert.Error(t, process(ctx, redis.NewStatusCmd(ctx, "set", "user:42", "v")))
+	assert.NoError(t, pipeline(ctx, []redis.Cmder{redis.NewIntCmd(ctx, "incr", "rl:auth"), redis.NewBoolCmd(ctx, "expire", "rl:auth", 60)}))
+	parent.End()
+
+	spans := rec.Ended()
+	require.Len(t, spans, 4)
+	get, set, pipe := spans[0], spans[1], spans[2]
+	assert.Equal(t, "GET", get.Name())
+	assert.Equal(t, parent.SpanContext().SpanID(), get.Parent().SpanID())
+	assert.Equal(t, "redis", attr(get, "db.system.name").AsString())
+	assert.Equal(t, codes.Unset, get.Status().Code, "a missing key is not an error")
+	for _, kv := range get.Attributes() {
+		assert.NotContains(t, kv.Value.Emit(), "user:42")
+	}
+	assert.Equal(t, "SET", set.Name())
+	assert.Equal(t, codes.Error, set.Status().Code)
+	assert.Equal(t, "PIPELINE", pipe.Name())
+	assert.Equal(t, "PIPELINE INCR EXPIRE", attr(pipe, "db.operation.name").AsString())
+}
+
+func TestSetup_RejectsBadOptions(t *testing.T) {
+	_, err := Setup(context.Background(), Options{Endpoint: "localhost:4318", SampleRatio: 1})
+	assert.Error(t, err, "scheme required")
+	_, err = Setup(context.Background(), Options{Endpoint: "http://localhost:4318", SampleRatio: 1.5})
+	assert.Error(t, err)
+}
@@ -0,0 +1,102 @@
+// Package httpclient is the single factory for outbound HTTP clients (webhooks, OAuth, mail APIs),
+// so proxy and egress rules required by locked-down deployments are applied everywhere at once.
+package httpclient
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// ErrEgressDenied is returned (wrapped in *url.Error) for requests to hosts outside the allowlist.
+var ErrEgressDenied = errors.New("egress to host not allowed")
+
+// Options configures outbound clients.
+type Options struct {
+	ProxyURL     string        // http(s)://user:pass@proxy:3128; empty = honour HTTP(S)_PROXY env vars
+	NoProxy      string        // comma-separated hosts/CIDRs that bypass ProxyURL (NO_PROXY syntax)
+	AllowedHosts []string      // egress allowlist: "api.example.com" or "*.example.com"; empty = any host
+	Timeout      time.Duration // whole-request timeout; 0 = 30s
+
+	// BlockPrivate refuses connections to non-public IPs, checked on the address actually dialed
+	// (after DNS), for clients that call user-supplied URLs such as webhook endpoints.
+	BlockPrivate bool
+	TrustedHosts []string // exempt from BlockPrivate (operator-approved internal receivers)
+}
+
+// New builds a client with the proxy and allowlist applied. Redirects pass through the same
+// transport, so a redirect can't escape the allowlist either.
+func New(o Options) (*http.Client, error) {
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+	if o.ProxyURL != "" {
+		if _, err := url.Parse(o.ProxyURL); err != nil {
+			return nil, fmt.Errorf("invalid proxy url: %w", err)
+		}
+		proxy := (&httpproxy.Config{HTTPProxy: o.ProxyURL, HTTPSProxy: o.ProxyURL, NoProxy: o.NoProxy}).ProxyFunc()
+		tr.Proxy = func(r *http.Request) (*url.URL, error) { return proxy(r.URL) }
+	} else {
+		tr.Proxy = http.ProxyFromEnvironment
+	}
+	if o.BlockPrivate {
+		d := &safeDialer{dialer: &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second},
+			trusted: normalize(o.TrustedHosts), proxy: proxyHostPort(o.ProxyURL)}
+		tr.DialContext = d.DialContext
+	}
+
+	timeout := o.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	var rt http.RoundTripper = tr
+	if len(o.AllowedHosts) > 0 {
+		rt = &allowlist{next: tr, hosts: normalize(o.AllowedHosts)}
+	}
+	return &http.Client{Transport: rt, Timeout: timeout}, nil
+}
+
+// allowlist rejects requests whose target host isn't listed.
+type allowlist struct {
+	next  http.RoundTripper
+	hosts []string
+}
+
+func (a *allowlist) RoundTrip(r *http.Request) (*http.Response, error) {
+	if !Allowed(a.hosts, r.URL.Hostname()) {
+		return nil, fmt.Errorf("%w: %s", ErrEgressDenied, r.URL.Hostname())
+	}
+	return a.next.RoundTrip(r)
+}
+
+// Allowed reports whether host matches an allowlist entry. "*.example.com" matches subdomains
+// only; IP literals must be listed exactly.
+func Allowed(allowed []string, host string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	for _, h := range allowed {
+		if suffix, ok := strings.CutPrefix(h, "*."); ok {
+			if net.ParseIP(host) == nil && strings.HasSuffix(host, "."+suffix) {
+				return true
+			}
+			continue
+		}
+		if host == h {
+			return true
+		}
+	}
+	return false
+}
+
+func normalize(hosts []string) []string {
+	out := make([]string, 0, len(hosts))
+	for _, h := range hosts {
+		if h = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(h)), "."); h != "" {
+			out = append(out, h)
+		}
+	}
+	return out
+}
@@ -0,0 +1,50 @@
+package httpclient
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllowed(t *testing.T) {
+	list := normalize([]string{"API.example.com", "*.mail.io"})
+	assert.True(t, Allowed(list, "api.example.com"))
+	assert.True(t, Allowed(list, "API.EXAMPLE.COM."))
+	assert.True(t, Allowed(list, "eu.mail.io"))
+	assert.False(t, Allowed(list, "mail.io"), "wildcard covers subdomains only")
+	assert.False(t, Allowed(list, "example.com"))
+	assert.False(t, Allowed(list, "evil-mail.io"))
+}
+
+func TestNew_BlocksHostsOutsideAllowlist(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {}))
+	defer srv.Close()
+
+	c, err := New(Options{AllowedHosts: []string{"api.example.com"}})
+	require.NoError(t, err)
+	_, err = c.Get(srv.URL)
+	assert.True(t, errors.Is(err, ErrEgressDenied))
+
+	c, _ = New(Options{AllowedHosts: []string{"127.0.0.1"}})
+	resp, err := c.Get(srv.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+}
+
+func TestNew_ProxyHonoursNoProxy(t *testing.T) {
+	c, err := New(Options{ProxyURL: "http://proxy.corp:3128", NoProxy: "internal.corp"})
+	require.NoError(t, err)
+	proxy := c.Transport.(*http.Transport).Proxy
+
+	u, _ := proxy(&http.Request{URL: &url.URL{Scheme: "https", Host: "api.example.com"}})
+	require.NotNil(t, u)
+	assert.Equal(t, "proxy.corp:3128", u.Host)
+
+	u, _ = proxy(&http.Request{URL: &url.URL{Scheme: "https", Host: "svc.internal.corp"}})
+	assert.Nil(t, u)
+}
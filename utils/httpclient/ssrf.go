@@ -0,0 +1,125 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+)
+
+// ErrUnsafeURL marks URLs that would let a caller reach internal infrastructure (SSRF).
+var ErrUnsafeURL = errors.New("unsafe url")
+
+// blockedPrefixes are non-public ranges not already covered by netip's Is* helpers.
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),     // "this" network
+	netip.MustParsePrefix("100.64.0.0/10"), // carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),  // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"), // benchmarking
+	netip.MustParsePrefix("64:ff9b::/96"),  // NAT64 (embeds IPv4, e.g. 127.0.0.1)
+}
+
+// PublicIP reports whether ip is a globally routable unicast address.
+func PublicIP(ip netip.Addr) bool {
+	ip = ip.Unmap() // ::ffff:10.0.0.1 is 10.0.0.1
+	if !ip.IsValid() || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	for _, p := range blockedPrefixes {
+		if p.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckURL validates a user-supplied target (e.g. a webhook endpoint): HTTPS only, no
+// credentials, and every address the host resolves to must be public. Hosts in trusted skip
+// these checks (internal receivers the operator vouches for). A nil resolver uses the default.
+// This is the registration-time check; clients built with Options.BlockPrivate re-check the
+// address actually dialed, which defeats DNS rebinding.
+func CheckURL(ctx context.Context, raw string, trusted []string, resolver *net.Resolver) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("%w: not an absolute url", ErrUnsafeURL)
+	}
+	host := u.Hostname()
+	if Allowed(normalize(trusted), host) {
+		return nil
+	}
+	if u.Scheme != "https" {
+		return fmt.Errorf("%w: https is required", ErrUnsafeURL)
+	}
+	if u.User != nil {
+		return fmt.Errorf("%w: credentials in url are not allowed", ErrUnsafeURL)
+	}
+
+	if ip, err := netip.ParseAddr(host); err == nil {
+		if !PublicIP(ip) {
+			return fmt.Errorf("%w: %s is not a public address", ErrUnsafeURL, host)
+		}
+		return nil
+	}
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	addrs, err := resolver.LookupNetIP(ctx, "ip", host)
+	if err != nil || len(addrs) == 0 {
+		return fmt.Errorf("%w: %s does not resolve", ErrUnsafeURL, host)
+	}
+	for _, ip := range addrs {
+		if !PublicIP(ip) { // one private answer is enough to be abusable
+			return fmt.Errorf("%w: %s resolves to non-public %s", ErrUnsafeURL, host, ip)
+		}
+	}
+	return nil
+}
+
+// safeDialer dials like net.Dialer but refuses non-public IPs after resolution, unless the
+// dialed host is trusted or is the configured proxy (the proxy then resolves the target).
+type safeDialer struct {
+	dialer  *net.Dialer
+	trusted []string
+	proxy   string // host:port of Options.ProxyURL, if any
+}
+
+func (d *safeDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	if addr == d.proxy || Allowed(d.trusted, host) {
+		return d.dialer.DialContext(ctx, network, addr)
+	}
+	checked := *d.dialer
+	checked.ControlContext = nil
+	checked.Control = func(_, address string, _ syscall.RawConn) error {
+		h, _, _ := net.SplitHostPort(address)
+		ip, err := netip.ParseAddr(h)
+		if err != nil || !PublicIP(ip) {
+			return fmt.Errorf("%w: %s resolves to non-public %s", ErrEgressDenied, host, h)
+		}
+		return nil
+	}
+	return checked.DialContext(ctx, network, addr)
+}
+
+// proxyHostPort returns "host:port" of a proxy URL, defaulting the port by scheme.
+func proxyHostPort(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	if u.Port() != "" {
+		return u.Host
+	}
+	if strings.EqualFold(u.Scheme, "https") {
+		return net.JoinHostPort(u.Hostname(), "443")
+	}
+	return net.JoinHostPort(u.Hostname(), "80")
+}
@@ -0,0 +1,62 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublicIP(t *testing.T) {
+	for ip, want := range map[string]bool{
+		"8.8.8.8":         true,
+		"2606:4700::1111": true,
+		"127.0.0.1":       false,
+		"10.1.2.3":        false,
+		"172.16.0.1":      false,
+		"192.168.1.1":     false,
+		"169.254.169.254": false, // cloud metadata
+		"100.64.0.1":      false,
+		"0.0.0.0":         false,
+		"::1":             false,
+		"fe80::1":         false,
+		"fd00::1":         false,
+		"::ffff:10.0.0.1": false,
+		"64:ff9b::7f00:1": false,
+	} {
+		assert.Equal(t, want, PublicIP(netip.MustParseAddr(ip)), ip)
+	}
+}
+
+func TestCheckURL(t *testing.T) {
+	ctx := context.Background()
+	assert.NoError(t, CheckURL(ctx, "https://8.8.8.8/hook", nil, nil))
+	assert.ErrorIs(t, CheckURL(ctx, "http://8.8.8.8/hook", nil, nil), ErrUnsafeURL)
+	assert.ErrorIs(t, CheckURL(ctx, "https://169.254.169.254/latest", nil, nil), ErrUnsafeURL)
+	assert.ErrorIs(t, CheckURL(ctx, "https://user:pw@8.8.8.8/", nil, nil), ErrUnsafeURL)
+	assert.ErrorIs(t, CheckURL(ctx, "https://localhost/hook", nil, nil), ErrUnsafeURL)
+	assert.ErrorIs(t, CheckURL(ctx, "/relative", nil, nil), ErrUnsafeURL)
+
+	// operator allowlist overrides scheme and range checks
+	assert.NoError(t, CheckURL(ctx, "http://10.0.0.5:8080/hook", []string{"10.0.0.5"}, nil))
+}
+
+func TestNew_BlockPrivateChecksDialedAddress(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {}))
+	defer srv.Close()
+
+	c, err := New(Options{BlockPrivate: true})
+	require.NoError(t, err)
+	_, err = c.Get(srv.URL) // 127.0.0.1
+	assert.True(t, errors.Is(err, ErrEgressDenied))
+
+	c, _ = New(Options{BlockPrivate: true, TrustedHosts: []string{"127.0.0.1"}})
+	resp, err := c.Get(srv.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+}
@@ -0,0 +1,45 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Store remembers the outcome of a request identified by a client-supplied Idempotency-Key,
+// so a retried request can be answered with the original result instead of being re-executed.
+type Store struct {
+	rdb    *redis.Client
+	prefix string        // key namespace, e.g. "idem:register:"
+	ttl    time.Duration // how long a key is remembered
+}
+
+// New creates a Redis-backed store. A nil client yields a no-op store.
+func New(rdb *redis.Client, prefix string, ttl time.Duration) *Store {
+	return &Store{rdb: rdb, prefix: prefix, ttl: ttl}
+}
+
+// Get returns the stored value for key; ok is false when the key was never seen (or expired).
+func (s *Store) Get(ctx context.Context, key string) (val string, ok bool, err error) {
+	if s == nil || s.rdb == nil || key == "" {
+		return "", false, nil
+	}
+	val, err = s.rdb.Get(ctx, s.prefix+key).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", false, nil // unseen key
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return val, true, nil
+}
+
+// Put records the result for key. The first writer wins (SET NX) so concurrent retries agree.
+func (s *Store) Put(ctx context.Context, key, val string) error {
+	if s == nil || s.rdb == nil || key == "" {
+		return nil
+	}
+	return s.rdb.SetNX(ctx, s.prefix+key, val, s.ttl).Err()
+}
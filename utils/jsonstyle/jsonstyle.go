@@ -0,0 +1,166 @@
+// Package jsonstyle rewrites JSON bodies between the API's native shape (bare objects,
+// snake_case fields) and the shape a client migrating from another backend expects:
+// optionally enveloped ({"data": ..., "meta": ...}) and/or camelCase.
+//
+// Handlers keep writing the native shape; middlewares.ResponseStyle applies the Style
+// configured for the API version on the way out (and, for camelCase, turns request bodies
+// back into snake_case on the way in). Every object key is renamed, including keys that are
+// data rather than field names (e.g. a map keyed by "rate_limits" groups).
+package jsonstyle
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Field naming conventions.
+const (
+	SnakeCase = "snake_case" // native: created_at
+	CamelCase = "camelCase"  // createdAt
+)
+
+// Style is how one API version shapes its JSON. The zero value is the native style.
+type Style struct {
+	Version  string // e.g. "v1"; reported as meta.api_version when enveloped
+	Envelope bool   // wrap bodies: {"data": ..., "meta": ...} / {"error": {...}, "meta": ...}
+	Naming   string // SnakeCase (default) or CamelCase
+}
+
+// Validate checks the naming convention.
+func (s Style) Validate() error {
+	switch s.Naming {
+	case "", SnakeCase, CamelCase:
+		return nil
+	}
+	return fmt.Errorf("naming %q (want %s|%s)", s.Naming, SnakeCase, CamelCase)
+}
+
+// Native reports whether bodies pass through unchanged.
+func (s Style) Native() bool { return !s.Envelope && s.Naming != CamelCase }
+
+// Response reshapes a JSON response body sent with status.
+//
+// Enveloped, a 2xx body becomes {"data": body, "meta": {...}}; a list page ({"items": [...],
+// "total": ...}) puts the items in data and the paging fields in meta. An error body
+// ({"error": "msg", ...}) becomes {"error": {"message": "msg", ...}, "meta": {...}}.
+func (s Style) Response(status int, body []byte) ([]byte, error) {
+	if s.Native() {
+		return body, nil
+	}
+	v, err := decode(body)
+	if err != nil {
+		return nil, err
+	}
+	if s.Envelope {
+		v = s.envelope(status, v)
+	}
+	if s.Naming == CamelCase {
+		v = renameKeys(v, Camel)
+	}
+	return json.Marshal(v)
+}
+
+// Request turns a request body in this style back into the native field naming. Envelopes
+// are not expected on requests.
+func (s Style) Request(body []byte) ([]byte, error) {
+	if s.Naming != CamelCase {
+		return body, nil
+	}
+	v, err := decode(body)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(renameKeys(v, Snake))
+}
+
+func (s Style) envelope(status int, v interface{}) interface{} {
+	meta := map[string]interface{}{}
+	if s.Version != "" {
+		meta["api_version"] = s.Version
+	}
+	obj, isObj := v.(map[string]interface{})
+	if status >= 400 {
+		if isObj {
+			if msg, ok := obj["error"].(string); ok { // {"error": "msg", "violations": ...}
+				delete(obj, "error")
+				obj["message"] = msg
+			}
+		}
+		return map[string]interface{}{"error": v, "meta": meta}
+	}
+	if items, ok := obj["items"].([]interface{}); isObj && ok { // list page
+		for k, f := range obj {
+			if k != "items" {
+				meta[k] = f
+			}
+		}
+		v = items
+	}
+	return map[string]interface{}{"data": v, "meta": meta}
+}
+
+// decode keeps numbers as written (IDs and totals must not round-trip through float64).
+func decode(body []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func renameKeys(v interface{}, rename func(string) string) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(t))
+		for k, f := range t {
+			out[rename(k)] = renameKeys(f, rename)
+		}
+		return out
+	case []interface{}:
+		for i := range t {
+			t[i] = renameKeys(t[i], rename)
+		}
+	}
+	return v
+}
+
+// Camel converts snake_case to camelCase: "date_of_birth" → "dateOfBirth".
+func Camel(s string) string {
+	if !strings.Contains(s, "_") {
+		return s
+	}
+	var b strings.Builder
+	upper := false
+	for i, r := range s {
+		switch {
+		case r == '_' && i > 0:
+			upper = true
+		case upper:
+			b.WriteRune(unicode.ToUpper(r))
+			upper = false
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// Snake converts camelCase to snake_case: "dateOfBirth" → "date_of_birth".
+func Snake(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
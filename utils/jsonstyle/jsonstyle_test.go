@@ -0,0 +1,54 @@
+package jsonstyle
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNamingRoundTrip(t *testing.T) {
+	for snake, camel := range map[string]string{"id": "id", "date_of_birth": "dateOfBirth", "two_factor_enabled": "twoFactorEnabled"} {
+		assert.Equal(t, camel, Camel(snake))
+		assert.Equal(t, snake, Snake(camel))
+	}
+}
+
+func TestNativeStylePassesThrough(t *testing.T) {
+	body := []byte(`{"created_at":"x"}`)
+	out, err := Style{Version: "v1"}.Response(200, body)
+	require.NoError(t, err)
+	assert.Equal(t, body, out)
+}
+
+func TestCamelCase(t *testing.T) {
+	s := Style{Naming: CamelCase}
+	out, err := s.Response(200, []byte(`{"id":9007199254740993,"date_of_birth":"2000-01-01","items":[{"created_at":"x"}]}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id":9007199254740993,"dateOfBirth":"2000-01-01","items":[{"createdAt":"x"}]}`, string(out))
+
+	in, err := s.Request([]byte(`{"dateOfBirth":"2000-01-01","name":"Sara"}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"date_of_birth":"2000-01-01","name":"Sara"}`, string(in))
+}
+
+func TestEnvelope(t *testing.T) {
+	s := Style{Version: "v2", Envelope: true, Naming: CamelCase}
+
+	out, err := s.Response(200, []byte(`{"id":1,"created_at":"x"}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"data":{"id":1,"createdAt":"x"},"meta":{"apiVersion":"v2"}}`, string(out))
+
+	out, err = s.Response(200, []byte(`{"items":[{"id":1}],"total":1,"page":1,"limit":20,"next_cursor":"1"}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"data":[{"id":1}],"meta":{"apiVersion":"v2","total":1,"page":1,"limit":20,"nextCursor":"1"}}`, string(out))
+
+	out, err = s.Response(422, []byte(`{"error":"validation failed","violations":[{"field":"email"}]}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"error":{"message":"validation failed","violations":[{"field":"email"}]},"meta":{"apiVersion":"v2"}}`, string(out))
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Style{Naming: CamelCase}.Validate())
+	assert.Error(t, Style{Naming: "kebab-case"}.Validate())
+}
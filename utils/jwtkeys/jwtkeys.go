@@ -0,0 +1,148 @@
+// Package jwtkeys holds the keys used to sign and verify access tokens.
+// HS256 uses the shared jwt_secret. RS256 signs with one private key (advertised via the
+// "kid" header) and verifies against a set of active public keys, so a key can be rotated by
+// adding the new one, switching the signer, and removing the old public key once its tokens expire.
+package jwtkeys
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Supported algorithms.
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+)
+
+// ErrUnknownKey is returned for tokens whose kid isn't an active key.
+var ErrUnknownKey = errors.New("unknown signing key")
+
+// KeySet signs new tokens and resolves verification keys for incoming ones.
+type KeySet struct {
+	alg     string
+	secret  []byte                    // HS256
+	kid     string                    // RS256: kid of the signing key
+	private *rsa.PrivateKey           // RS256: signing key
+	public  map[string]*rsa.PublicKey // RS256: kid → active verification key
+}
+
+// NewHMAC returns an HS256 key set backed by a shared secret.
+func NewHMAC(secret string) *KeySet {
+	return &KeySet{alg: HS256, secret: []byte(secret)}
+}
+
+// NewRSA returns an RS256 key set. The signing key's public half is always trusted; extra
+// holds other still-active public keys (kid → key), e.g. the previous key during a rotation.
+func NewRSA(kid string, private *rsa.PrivateKey, extra map[string]*rsa.PublicKey) (*KeySet, error) {
+	if kid == "" || private == nil {
+		return nil, errors.New("rs256 requires a key id and a private key")
+	}
+	public := map[string]*rsa.PublicKey{kid: &private.PublicKey}
+	for id, k := range extra {
+		if id == kid {
+			continue // the signing key's own public half wins
+		}
+		public[id] = k
+	}
+	return &KeySet{alg: RS256, kid: kid, private: private, public: public}, nil
+}
+
+// LoadRSA reads a PEM private key and PEM public keys (kid → path) from disk.
+func LoadRSA(kid, privatePath string, publicPaths map[string]string) (*KeySet, error) {
+	b, err := os.ReadFile(privatePath)
+	if err != nil {
+		return nil, err
+	}
+	private, err := jwt.ParseRSAPrivateKeyFromPEM(b)
+	if err != nil {
+		return nil, fmt.Errorf("private key %s: %w", privatePath, err)
+	}
+	extra := make(map[string]*rsa.PublicKey, len(publicPaths))
+	for id, path := range publicPaths {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if extra[id], err = jwt.ParseRSAPublicKeyFromPEM(b); err != nil {
+			return nil, fmt.Errorf("public key %s (%s): %w", id, path, err)
+		}
+	}
+	return NewRSA(kid, private, extra)
+}
+
+// Algorithm is HS256 or RS256.
+func (k *KeySet) Algorithm() string { return k.alg }
+
+// PublicKeys returns the active RS256 verification keys (nil in HS256 mode).
+func (k *KeySet) PublicKeys() map[string]*rsa.PublicKey { return k.public }
+
+// Sign issues a compact JWT for claims, tagging RS256 tokens with the signing kid.
+func (k *KeySet) Sign(claims jwt.Claims) (string, error) {
+	if k.alg == RS256 {
+		t := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		t.Header["kid"] = k.kid
+		return t.SignedString(k.private)
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(k.secret)
+}
+
+// Keyfunc resolves the verification key for t; use it with ParserOptions so the algorithm is
+// pinned (an RS256 deployment never accepts HS256 tokens, which would let the public key act
+// as an HMAC secret).
+func (k *KeySet) Keyfunc(t *jwt.Token) (interface{}, error) {
+	if k.alg == HS256 {
+		return k.secret, nil
+	}
+	kid, _ := t.Header["kid"].(string)
+	if kid == "" {
+		kid = k.kid // tokens minted before kids were added
+	}
+	pub, ok := k.public[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, kid)
+	}
+	return pub, nil
+}
+
+// ParserOptions restricts parsing to this key set's algorithm.
+func (k *KeySet) ParserOptions() []jwt.ParserOption {
+	return []jwt.ParserOption{jwt.WithValidMethods([]string{k.alg})}
+}
+
+// JWK is one RSA public key in JSON Web Key form (RFC 7517/7518).
+type JWK struct {
+	Kty string `json:"kty"` // "RSA"
+	Use string `json:"use"` // "sig"
+	Alg string `json:"alg"` // "RS256"
+	Kid string `json:"kid"`
+	N   string `json:"n"` // modulus, base64url
+	E   string `json:"e"` // exponent, base64url
+}
+
+// JWKS is the document served at /.well-known/jwks.json.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS lists the active verification keys, sorted by kid (empty in HS256 mode:
+// a shared secret must never be published).
+func (k *KeySet) JWKS() JWKS {
+	out := JWKS{Keys: []JWK{}}
+	for kid, pub := range k.public {
+		out.Keys = append(out.Keys, JWK{
+			Kty: "RSA", Use: "sig", Alg: RS256, Kid: kid,
+			N: base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			E: base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		})
+	}
+	sort.Slice(out.Keys, func(i, j int) bool { return out.Keys[i].Kid < out.Keys[j].Kid })
+	return out
+}
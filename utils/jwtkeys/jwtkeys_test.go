@@ -0,0 +1,79 @@
+package jwtkeys
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func rsaKey(t *testing.T) *rsa.PrivateKey {
+	k, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	return k
+}
+
+func claims() jwt.MapClaims {
+	return jwt.MapClaims{"sub": 1, "exp": time.Now().Add(time.Minute).Unix()}
+}
+
+func parse(k *KeySet, raw string) error {
+	_, err := jwt.Parse(raw, k.Keyfunc, k.ParserOptions()...)
+	return err
+}
+
+func TestRSA_RotationKeepsOldTokensValid(t *testing.T) {
+	oldKey, newKey := rsaKey(t), rsaKey(t)
+
+	before, err := NewRSA("2024-01", oldKey, nil)
+	require.NoError(t, err)
+	oldToken, err := before.Sign(claims())
+	require.NoError(t, err)
+
+	// rotate: sign with the new key, keep the old public key active
+	after, err := NewRSA("2024-06", newKey, map[string]*rsa.PublicKey{"2024-01": &oldKey.PublicKey})
+	require.NoError(t, err)
+	newToken, _ := after.Sign(claims())
+
+	assert.NoError(t, parse(after, oldToken))
+	assert.NoError(t, parse(after, newToken))
+
+	// old key retired → its tokens stop verifying
+	retired, _ := NewRSA("2024-06", newKey, nil)
+	assert.ErrorIs(t, parse(retired, oldToken), ErrUnknownKey)
+	assert.NoError(t, parse(retired, newToken))
+}
+
+func TestRSA_RejectsHMACTokens(t *testing.T) {
+	key := rsaKey(t)
+	ks, _ := NewRSA("k1", key, nil)
+
+	// classic alg confusion: HS256 token "signed" with something public
+	forged, _ := NewHMAC("public-key-bytes").Sign(claims())
+	assert.Error(t, parse(ks, forged))
+}
+
+func TestHMAC_RoundTrip(t *testing.T) {
+	ks := NewHMAC("s3cret")
+	tok, err := ks.Sign(claims())
+	require.NoError(t, err)
+	assert.NoError(t, parse(ks, tok))
+	assert.Error(t, parse(NewHMAC("other"), tok))
+}
+
+func TestJWKS(t *testing.T) {
+	a, b := rsaKey(t), rsaKey(t)
+	ks, _ := NewRSA("k2", b, map[string]*rsa.PublicKey{"k1": &a.PublicKey})
+
+	doc := ks.JWKS()
+	require.Len(t, doc.Keys, 2)
+	assert.Equal(t, "k1", doc.Keys[0].Kid)
+	assert.Equal(t, "RSA", doc.Keys[1].Kty)
+	assert.Equal(t, "AQAB", doc.Keys[1].E) // 65537
+
+	assert.Empty(t, NewHMAC("secret").JWKS().Keys, "shared secrets are never published")
+}
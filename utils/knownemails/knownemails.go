@@ -0,0 +1,121 @@
+package knownemails
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Store is a short-lived Redis record of emails that definitely belong to an account, kept by
+// the write path (register, import, email change) so registration storms and import bursts can
+// turn away obvious duplicates without a FindByEmail round trip.
+//
+// It only ever answers "known to exist": a miss, an expired entry or a Redis error means "ask
+// the database". Entries are "known_email:<sha256 of the canonical email>" → "<gen>:<unix>"
+// (no plaintext addresses in Redis). Removing an address (delete, email change) bumps
+// "known_email:gen", which voids every entry at once; they refill from later writes.
+type Store struct {
+	rdb *redis.Client
+	ttl time.Duration // how long an entry is trusted
+}
+
+const genKey = "known_email:gen"
+
+// lookupChunk caps the keys per MGET so one big import doesn't block Redis.
+const lookupChunk = 500
+
+// New creates a store. A nil client yields a no-op store (every lookup misses).
+func New(rdb *redis.Client, ttl time.Duration) *Store {
+	return &Store{rdb: rdb, ttl: ttl}
+}
+
+func key(email string) string {
+	sum := sha256.Sum256([]byte(email))
+	return "known_email:" + hex.EncodeToString(sum[:])
+}
+
+// Add records canonical emails that were just written to the database.
+func (s *Store) Add(ctx context.Context, emails ...string) error {
+	if s == nil || s.rdb == nil || len(emails) == 0 {
+		return nil
+	}
+	gen, err := s.gen(ctx)
+	if err != nil {
+		return err
+	}
+	// An Invalidate between reading gen and the SETs leaves these entries on the old generation,
+	// so they are ignored: the race can only cost a DB lookup, never a false duplicate.
+	val := gen + ":" + strconv.FormatInt(time.Now().Unix(), 10)
+	_, err = s.rdb.Pipelined(ctx, func(p redis.Pipeliner) error {
+		for _, e := range emails {
+			p.Set(ctx, key(e), val, s.ttl)
+		}
+		return nil
+	})
+	return err
+}
+
+// Lookup returns, for each of the canonical emails known to exist, when it was recorded.
+// Emails missing from the result must be checked against the database.
+func (s *Store) Lookup(ctx context.Context, emails ...string) (map[string]time.Time, error) {
+	found := map[string]time.Time{}
+	if s == nil || s.rdb == nil {
+		return found, nil
+	}
+	for start := 0; start < len(emails); start += lookupChunk {
+		end := start + lookupChunk
+		if end > len(emails) {
+			end = len(emails)
+		}
+		keys := []string{genKey} // read with the entries so a concurrent Invalidate can't slip between
+		for _, e := range emails[start:end] {
+			keys = append(keys, key(e))
+		}
+		vals, err := s.rdb.MGet(ctx, keys...).Result()
+		if err != nil {
+			return found, err
+		}
+		gen, _ := vals[0].(string)
+		if gen == "" {
+			gen = "0"
+		}
+		for i, v := range vals[1:] {
+			raw, ok := v.(string)
+			if !ok {
+				continue
+			}
+			entryGen, unix, ok := strings.Cut(raw, ":")
+			if !ok || entryGen != gen {
+				continue // recorded before the last Invalidate
+			}
+			sec, err := strconv.ParseInt(unix, 10, 64)
+			if err != nil {
+				continue
+			}
+			found[emails[start+i]] = time.Unix(sec, 0)
+		}
+	}
+	return found, nil
+}
+
+// Invalidate forgets every entry; call it whenever an email stops belonging to an account.
+func (s *Store) Invalidate(ctx context.Context) error {
+	if s == nil || s.rdb == nil {
+		return nil
+	}
+	return s.rdb.Incr(ctx, genKey).Err()
+}
+
+func (s *Store) gen(ctx context.Context) (string, error) {
+	gen, err := s.rdb.Get(ctx, genKey).Result()
+	if errors.Is(err, redis.Nil) {
+		return "0", nil // never invalidated
+	}
+	return gen, err
+}
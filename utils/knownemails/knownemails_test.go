@@ -0,0 +1,58 @@
+package knownemails
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddThenLookup(t *testing.T) {
+	rdb, m := redismock.NewClientMock()
+	s := New(rdb, 15*time.Minute)
+	ctx := context.Background()
+
+	m.ExpectGet(genKey).RedisNil()
+	m.Regexp().ExpectSet(key("a@x.com"), `^0:\d+$`, 15*time.Minute).SetVal("OK")
+	require.NoError(t, s.Add(ctx, "a@x.com"))
+
+	m.ExpectMGet(genKey, key("a@x.com"), key("b@x.com")).SetVal([]interface{}{nil, "0:1700000000", nil})
+	found, err := s.Lookup(ctx, "a@x.com", "b@x.com")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]time.Time{"a@x.com": time.Unix(1_700_000_000, 0)}, found)
+
+	assert.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestInvalidateVoidsOlderEntries(t *testing.T) {
+	rdb, m := redismock.NewClientMock()
+	s := New(rdb, time.Minute)
+	ctx := context.Background()
+
+	m.ExpectIncr(genKey).SetVal(3)
+	require.NoError(t, s.Invalidate(ctx))
+
+	m.ExpectMGet(genKey, key("a@x.com"), key("b@x.com")).SetVal([]interface{}{"3", "2:1700000000", "3:1700000100"})
+	found, err := s.Lookup(ctx, "a@x.com", "b@x.com")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]time.Time{"b@x.com": time.Unix(1_700_000_100, 0)}, found, "entry from before the bump is ignored")
+
+	assert.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestNilStoreIsNoop(t *testing.T) {
+	var s *Store
+	ctx := context.Background()
+	assert.NoError(t, s.Add(ctx, "a@x.com"))
+	assert.NoError(t, s.Invalidate(ctx))
+	found, err := s.Lookup(ctx, "a@x.com")
+	assert.NoError(t, err)
+	assert.Empty(t, found)
+
+	found, err = New(nil, time.Minute).Lookup(ctx, "a@x.com")
+	assert.NoError(t, err)
+	assert.Empty(t, found)
+}
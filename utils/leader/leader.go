@@ -0,0 +1,185 @@
+// Package leader elects one replica to run singleton background work (cron jobs, relays,
+// archivers) in a multi-instance deployment.
+//
+// The lease is a Redis key "leader:<name>" holding the instance ID, taken with SET NX PX and
+// kept alive by the holder; renew and release are Lua compare-and-set scripts so an instance
+// can never extend or delete a lease that has passed to someone else. If renewal fails the
+// task context is cancelled before the lease can expire, so two replicas don't overlap.
+package leader
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"sync"
+	"time"
+
+	"HelmyTask/utils/redisscript"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// renewScript extends the lease only if we still hold it.
+var renewScript = redisscript.Register("leader.renew", `
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+
+// releaseScript deletes the lease only if we still hold it.
+var releaseScript = redisscript.Register("leader.release", `
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+// Status is the diagnostics view of one election.
+type Status struct {
+	Name     string     `json:"name"`
+	Instance string     `json:"instance"`
+	Leader   bool       `json:"leader"`
+	Since    *time.Time `json:"since,omitempty"` // when this instance became leader
+	LastErr  string     `json:"last_error,omitempty"`
+}
+
+// Elector campaigns for one named lease.
+type Elector struct {
+	rdb  *redis.Client
+	name string
+	id   string
+	ttl  time.Duration
+
+	mu      sync.Mutex
+	since   time.Time // zero when not leading
+	lastErr string
+}
+
+// New builds an elector for name. ttl is how long a crashed leader blocks takeover;
+// the lease is renewed every ttl/3.
+func New(rdb *redis.Client, name string, ttl time.Duration) *Elector {
+	return &Elector{rdb: rdb, name: name, id: InstanceID(), ttl: ttl}
+}
+
+// InstanceID identifies this process: hostname (the pod name on Kubernetes) plus a random suffix.
+func InstanceID() string {
+	host, _ := os.Hostname()
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return host + "-" + hex.EncodeToString(b)
+}
+
+func (e *Elector) key() string { return "leader:" + e.name }
+
+// Run campaigns until ctx is done. Whenever this instance holds the lease, task runs with a
+// context that is cancelled as soon as leadership is lost (or ctx ends); Run waits for task to
+// return before campaigning again. task should therefore stop promptly on ctx.Done().
+func (e *Elector) Run(ctx context.Context, task func(ctx context.Context)) {
+	tick := time.NewTicker(e.ttl / 3)
+	defer tick.Stop()
+	for {
+		if e.acquire(ctx) {
+			e.lead(ctx, task, tick.C)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick.C:
+		}
+	}
+}
+
+// lead runs task while renewing the lease; returns when renewal fails or ctx ends.
+func (e *Elector) lead(ctx context.Context, task func(ctx context.Context), tick <-chan time.Time) {
+	tctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() { defer close(done); task(tctx) }()
+
+	finished := (<-chan struct{})(done)
+	var err error // why the lease was lost, if Redis said so
+	for held := true; held; {
+		select {
+		case <-ctx.Done():
+			held = false
+		case <-finished: // task returned on its own; keep the lease so no peer starts it again
+			finished = nil
+		case <-tick:
+			held, err = e.renew(ctx)
+		}
+	}
+	cancel()
+	<-done
+	e.setLeader(false, err)
+	rctx, rcancel := context.WithTimeout(context.Background(), time.Second) // ctx may already be done
+	defer rcancel()
+	_ = releaseScript.Run(rctx, e.rdb, []string{e.key()}, e.id).Err() // let a peer take over right away
+}
+
+func (e *Elector) acquire(ctx context.Context) bool {
+	ok, err := e.rdb.SetNX(ctx, e.key(), e.id, e.ttl).Result()
+	if err != nil {
+		e.setLeader(false, err)
+		return false
+	}
+	if ok {
+		e.setLeader(true, nil)
+	}
+	return ok
+}
+
+// renew extends the lease; false means it is lost, with the Redis error if that was the cause
+// (the caller steps down and records it).
+func (e *Elector) renew(ctx context.Context) (bool, error) {
+	n, err := renewScript.Run(ctx, e.rdb, []string{e.key()}, e.id, e.ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
+func (e *Elector) setLeader(leading bool, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	switch {
+	case leading && e.since.IsZero():
+		e.since = time.Now()
+	case !leading:
+		e.since = time.Time{}
+	}
+	e.lastErr = ""
+	if err != nil {
+		e.lastErr = err.Error()
+	}
+}
+
+// IsLeader reports whether this instance currently holds the lease.
+func (e *Elector) IsLeader() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return !e.since.IsZero()
+}
+
+// Status snapshots the election for diagnostics.
+func (e *Elector) Status() Status {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	st := Status{Name: e.name, Instance: e.id, Leader: !e.since.IsZero(), LastErr: e.lastErr}
+	if st.Leader {
+		since := e.since
+		st.Since = &since
+	}
+	return st
+}
+
+// Group runs every task concurrently and returns when all have returned; use it to hang several
+// singleton jobs off one election.
+func Group(tasks ...func(ctx context.Context)) func(ctx context.Context) {
+	return func(ctx context.Context) {
+		var wg sync.WaitGroup
+		for _, t := range tasks {
+			wg.Add(1)
+			go func(t func(ctx context.Context)) { defer wg.Done(); t(ctx) }(t)
+		}
+		wg.Wait()
+	}
+}
@@ -0,0 +1,80 @@
+package leader
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"HelmyTask/mocks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestElector_AcquireRenewStatus(t *testing.T) {
+	rdb, m := mocks.NewRedisMock()
+	e := New(rdb, "cron", 30*time.Second)
+	e.id = "pod-a"
+	ctx := context.Background()
+
+	// someone else holds it
+	m.ExpectSetNX("leader:cron", "pod-a", 30*time.Second).SetVal(false)
+	assert.False(t, e.acquire(ctx))
+	assert.False(t, e.Status().Leader)
+
+	m.ExpectSetNX("leader:cron", "pod-a", 30*time.Second).SetVal(true)
+	assert.True(t, e.acquire(ctx))
+	st := e.Status()
+	assert.True(t, st.Leader)
+	assert.NotNil(t, st.Since)
+
+	// renew succeeds while we hold it, fails once the key belongs to another instance
+	m.ExpectEvalSha(renewScript.SHA(), []string{"leader:cron"}, "pod-a", int64(30000)).SetVal(int64(1))
+	held, err := e.renew(ctx)
+	assert.True(t, held)
+	assert.NoError(t, err)
+	m.ExpectEvalSha(renewScript.SHA(), []string{"leader:cron"}, "pod-a", int64(30000)).SetVal(int64(0))
+	held, err = e.renew(ctx)
+	assert.False(t, held)
+	assert.NoError(t, err)
+
+	// redis errors are surfaced in diagnostics
+	m.ExpectSetNX("leader:cron", "pod-a", 30*time.Second).SetErr(errors.New("connection refused"))
+	e.setLeader(false, nil)
+	assert.False(t, e.acquire(ctx))
+	assert.Equal(t, "connection refused", e.Status().LastErr)
+	assert.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestElector_RenewErrorSurvivesSteppingDown(t *testing.T) {
+	rdb, m := mocks.NewRedisMock()
+	e := New(rdb, "cron", 30*time.Second)
+	e.id = "pod-a"
+	m.ExpectEvalSha(renewScript.SHA(), []string{"leader:cron"}, "pod-a", int64(30000)).SetErr(errors.New("connection reset"))
+	m.ExpectEvalSha(releaseScript.SHA(), []string{"leader:cron"}, "pod-a").SetVal(int64(1))
+
+	e.setLeader(true, nil)
+	tick := make(chan time.Time, 1)
+	tick <- time.Now()
+	stopped := false
+	e.lead(context.Background(), func(ctx context.Context) { <-ctx.Done(); stopped = true }, tick)
+
+	assert.True(t, stopped)
+	st := e.Status()
+	assert.False(t, st.Leader)
+	assert.Equal(t, "connection reset", st.LastErr)
+	assert.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestElector_RunOnlyWhenLeader(t *testing.T) {
+	rdb, m := mocks.NewRedisMock()
+	e := New(rdb, "cron", 30*time.Second)
+	e.id = "pod-b"
+	m.ExpectSetNX("leader:cron", "pod-b", 30*time.Second).SetVal(false)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	started := false
+	e.Run(ctx, func(context.Context) { started = true })
+	assert.False(t, started, "a follower never runs the task")
+}
@@ -0,0 +1,111 @@
+// Package lockout throttles password guessing per login email: after MaxFailures failed
+// logins within Window, the email is locked for Cooldown.
+//
+// Failures count the same whether or not an account has the email, so neither the lockout
+// nor its status (GET /auth/lockout-status) reveals which emails are registered. Keys hold a
+// SHA-256 of the canonical email, never the address: "login_fail:<hash>" counts failures,
+// "login_lock:<hash>" exists while the email is locked.
+package lockout
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"HelmyTask/utils/redisscript"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Policy is when an email gets locked and for how long.
+type Policy struct {
+	MaxFailures int           // failed logins that trigger a lock (0 = lockout off)
+	Window      time.Duration // failures older than this (since the first) are forgotten
+	Cooldown    time.Duration // how long a lock lasts
+}
+
+// Tracker records failed logins in Redis.
+type Tracker struct {
+	rdb    *redis.Client
+	policy Policy
+}
+
+// New creates a tracker. A nil client or MaxFailures <= 0 yields a tracker that never locks.
+func New(rdb *redis.Client, p Policy) *Tracker {
+	return &Tracker{rdb: rdb, policy: p}
+}
+
+func (t *Tracker) off() bool {
+	return t == nil || t.rdb == nil || t.policy.MaxFailures <= 0
+}
+
+func hash(email string) string {
+	sum := sha256.Sum256([]byte(email))
+	return hex.EncodeToString(sum[:])
+}
+
+func failKey(email string) string { return "login_fail:" + hash(email) }
+func lockKey(email string) string { return "login_lock:" + hash(email) }
+
+// Locked returns how long the email stays locked (0 = not locked). Errors mean "not locked":
+// a Redis outage must not lock everyone out.
+func (t *Tracker) Locked(ctx context.Context, email string) (time.Duration, error) {
+	if t.off() {
+		return 0, nil
+	}
+	ttl, err := t.rdb.PTTL(ctx, lockKey(email)).Result()
+	if err != nil || ttl < 0 { // -2: no lock; -1 can't happen (locks are always set with a TTL)
+		return 0, err
+	}
+	return ttl, nil
+}
+
+// failScript counts a failure and locks the email once the count reaches the limit, atomically:
+// concurrent failures can't each see a count below the limit, and the window's expiry is set
+// with the first failure's INCR (a crash between the two would leave a counter that never expires).
+//
+// KEYS[1] failure counter; KEYS[2] lock; ARGV[1] window (ms); ARGV[2] max failures; ARGV[3] cooldown (ms).
+// Returns the lock's duration in ms if this failure triggered one, else 0.
+var failScript = redisscript.Register("lockout.fail", `
+local n = redis.call("INCR", KEYS[1])
+if n == 1 then -- the window starts at the first failure
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+if n < tonumber(ARGV[2]) then
+	return 0
+end
+redis.call("SET", KEYS[2], "1", "PX", ARGV[3])
+redis.call("DEL", KEYS[1]) -- a fresh count once the lock ends
+return tonumber(ARGV[3])`)
+
+// Fail records a failed login and returns the lock's duration if this failure triggered one
+// (0 otherwise).
+func (t *Tracker) Fail(ctx context.Context, email string) (time.Duration, error) {
+	if t.off() {
+		return 0, nil
+	}
+	ms, err := failScript.Run(ctx, t.rdb, []string{failKey(email), lockKey(email)},
+		t.policy.Window.Milliseconds(), t.policy.MaxFailures, t.policy.Cooldown.Milliseconds()).Int64()
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(ms) * time.Millisecond, nil
+}
+
+// Reset forgets the failures after a successful login. An active lock stays: a locked email
+// can't log in anyway, so this only runs for unlocked ones.
+func (t *Tracker) Reset(ctx context.Context, email string) error {
+	if t.off() {
+		return nil
+	}
+	return t.rdb.Del(ctx, failKey(email)).Err()
+}
+
+// Clear forgets the email's failures and lifts its lock (support flushing a user's state).
+func (t *Tracker) Clear(ctx context.Context, email string) error {
+	if t.off() {
+		return nil
+	}
+	return t.rdb.Del(ctx, failKey(email), lockKey(email)).Err()
+}
@@ -0,0 +1,59 @@
+package lockout
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFailLocksAfterMaxFailures(t *testing.T) {
+	rdb, m := redismock.NewClientMock()
+	tr := New(rdb, Policy{MaxFailures: 2, Window: 15 * time.Minute, Cooldown: 10 * time.Minute})
+	ctx := context.Background()
+	fk, lk := failKey("a@x.com"), lockKey("a@x.com")
+
+	args := []interface{}{int64(900000), 2, int64(600000)}
+	m.ExpectEvalSha(failScript.SHA(), []string{fk, lk}, args...).SetVal(int64(0))
+	d, err := tr.Fail(ctx, "a@x.com")
+	require.NoError(t, err)
+	assert.Zero(t, d)
+
+	m.ExpectEvalSha(failScript.SHA(), []string{fk, lk}, args...).SetVal(int64(600000))
+	d, err = tr.Fail(ctx, "a@x.com")
+	require.NoError(t, err)
+	assert.Equal(t, 10*time.Minute, d)
+
+	m.ExpectPTTL(lk).SetVal(9 * time.Minute)
+	d, err = tr.Locked(ctx, "a@x.com")
+	require.NoError(t, err)
+	assert.Equal(t, 9*time.Minute, d)
+
+	m.ExpectPTTL(lockKey("b@x.com")).SetVal(-2 * time.Nanosecond) // go-redis reports a missing key as -2
+	d, err = tr.Locked(ctx, "b@x.com")
+	require.NoError(t, err)
+	assert.Zero(t, d)
+
+	m.ExpectDel(fk, lk).SetVal(1)
+	assert.NoError(t, tr.Clear(ctx, "a@x.com"))
+
+	assert.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestOffNeverTouchesRedis(t *testing.T) {
+	rdb, m := redismock.NewClientMock()
+	tr := New(rdb, Policy{})
+	d, err := tr.Fail(context.Background(), "a@x.com")
+	assert.NoError(t, err)
+	assert.Zero(t, d)
+	assert.NoError(t, tr.Reset(context.Background(), "a@x.com"))
+	assert.NoError(t, m.ExpectationsWereMet())
+
+	var nilTracker *Tracker
+	d, err = nilTracker.Locked(context.Background(), "a@x.com")
+	assert.NoError(t, err)
+	assert.Zero(t, d)
+}
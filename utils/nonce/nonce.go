@@ -0,0 +1,31 @@
+// Package nonce remembers one-time values (e.g. request signatures) for a while, so a second
+// use inside that window can be refused as a replay.
+package nonce
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Store records values under a key prefix in Redis.
+type Store struct {
+	rdb    *redis.Client
+	prefix string        // key namespace, e.g. "nonce:sig:"
+	ttl    time.Duration // how long a used value is remembered
+}
+
+// New creates a Redis-backed store. A nil client yields a store that accepts every value.
+func New(rdb *redis.Client, prefix string, ttl time.Duration) *Store {
+	return &Store{rdb: rdb, prefix: prefix, ttl: ttl}
+}
+
+// Claim marks value used and reports whether this was its first use (SET NX, so of two
+// concurrent claims exactly one wins).
+func (s *Store) Claim(ctx context.Context, value string) (bool, error) {
+	if s == nil || s.rdb == nil {
+		return true, nil
+	}
+	return s.rdb.SetNX(ctx, s.prefix+value, 1, s.ttl).Result()
+}
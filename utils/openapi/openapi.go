@@ -0,0 +1,221 @@
+// Package openapi loads the API description (docs/swagger.yaml) and checks requests and
+// responses against it, so handler/spec drift shows up in staging instead of in a client.
+//
+// Only the subset of OpenAPI 3 the spec actually uses is understood: path/query/header
+// parameters, JSON request and response bodies, local $refs, and the type, format, enum,
+// required, properties, items, length and range keywords. Anything else is ignored rather
+// than rejected.
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Schema is the part of an OpenAPI schema object the validator understands.
+type Schema struct {
+	Ref        string             `yaml:"$ref"`
+	Type       string             `yaml:"type"`
+	Format     string             `yaml:"format"`
+	Enum       []interface{}      `yaml:"enum"`
+	Required   []string           `yaml:"required"`
+	Properties map[string]*Schema `yaml:"properties"`
+	Items      *Schema            `yaml:"items"`
+	Nullable   bool               `yaml:"nullable"`
+	MinLength  *int               `yaml:"minLength"`
+	MaxLength  *int               `yaml:"maxLength"`
+	MinItems   *int               `yaml:"minItems"`
+	MaxItems   *int               `yaml:"maxItems"`
+	Minimum    *float64           `yaml:"minimum"`
+	Maximum    *float64           `yaml:"maximum"`
+}
+
+// Parameter is a path, query or header parameter.
+type Parameter struct {
+	In       string  `yaml:"in"`
+	Name     string  `yaml:"name"`
+	Required bool    `yaml:"required"`
+	Schema   *Schema `yaml:"schema"`
+}
+
+// MediaType holds the schema of one content type.
+type MediaType struct {
+	Schema *Schema `yaml:"schema"`
+}
+
+// RequestBody describes an operation's body.
+type RequestBody struct {
+	Required bool                 `yaml:"required"`
+	Content  map[string]MediaType `yaml:"content"`
+}
+
+// Response describes one documented status code.
+type Response struct {
+	Content map[string]MediaType `yaml:"content"`
+}
+
+// Operation is one method on one path.
+type Operation struct {
+	Parameters  []Parameter         `yaml:"parameters"`
+	RequestBody *RequestBody        `yaml:"requestBody"`
+	Responses   map[string]Response `yaml:"responses"`
+}
+
+// pathItem lists the methods of one path template.
+type pathItem struct {
+	Parameters []Parameter `yaml:"parameters"` // shared by every method
+	Get        *Operation  `yaml:"get"`
+	Post       *Operation  `yaml:"post"`
+	Put        *Operation  `yaml:"put"`
+	Patch      *Operation  `yaml:"patch"`
+	Delete     *Operation  `yaml:"delete"`
+}
+
+// route is a path template split into segments ("{id}" segments match anything).
+type route struct {
+	segments []string
+	ops      map[string]*Operation // by HTTP method
+}
+
+// Spec is a loaded API description.
+type Spec struct {
+	schemas map[string]*Schema // components.schemas
+	routes  []route
+}
+
+// Load reads and parses the spec file.
+func Load(path string) (*Spec, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(b)
+}
+
+// Parse builds a Spec from YAML (or JSON) bytes.
+func Parse(b []byte) (*Spec, error) {
+	var doc struct {
+		Paths      map[string]pathItem `yaml:"paths"`
+		Components struct {
+			Schemas map[string]*Schema `yaml:"schemas"`
+		} `yaml:"components"`
+	}
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return nil, fmt.Errorf("openapi: %w", err)
+	}
+	s := &Spec{schemas: doc.Components.Schemas}
+	for tmpl, item := range doc.Paths {
+		r := route{segments: strings.Split(strings.Trim(tmpl, "/"), "/"), ops: map[string]*Operation{}}
+		for method, op := range map[string]*Operation{
+			http.MethodGet: item.Get, http.MethodPost: item.Post, http.MethodPut: item.Put,
+			http.MethodPatch: item.Patch, http.MethodDelete: item.Delete,
+		} {
+			if op == nil {
+				continue
+			}
+			op.Parameters = append(append([]Parameter{}, item.Parameters...), op.Parameters...)
+			r.ops[method] = op
+		}
+		s.routes = append(s.routes, r)
+	}
+	if err := s.checkRefs(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Find returns the operation for method and path plus the values of its path parameters.
+// Literal segments win over "{param}" ones, so /users/export beats /users/{id}.
+func (s *Spec) Find(method, path string) (*Operation, map[string]string) {
+	segs := strings.Split(strings.Trim(path, "/"), "/")
+	var (
+		best       *Operation
+		bestParams map[string]string
+		bestScore  = -1
+	)
+	for _, r := range s.routes {
+		op := r.ops[method]
+		if op == nil || len(r.segments) != len(segs) {
+			continue
+		}
+		params, score, ok := map[string]string{}, 0, true
+		for i, seg := range r.segments {
+			if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
+				params[seg[1:len(seg)-1]] = segs[i]
+				continue
+			}
+			if seg != segs[i] {
+				ok = false
+				break
+			}
+			score++
+		}
+		if ok && score > bestScore {
+			best, bestParams, bestScore = op, params, score
+		}
+	}
+	return best, bestParams
+}
+
+// resolve follows a local "#/components/schemas/X" reference.
+func (s *Spec) resolve(sc *Schema) *Schema {
+	for sc != nil && sc.Ref != "" {
+		sc = s.schemas[strings.TrimPrefix(sc.Ref, "#/components/schemas/")]
+	}
+	return sc
+}
+
+// checkRefs fails on references the validator couldn't follow, so a typo fails at boot.
+func (s *Spec) checkRefs() error {
+	var walk func(sc *Schema) error
+	walk = func(sc *Schema) error {
+		if sc == nil {
+			return nil
+		}
+		if sc.Ref != "" {
+			if !strings.HasPrefix(sc.Ref, "#/components/schemas/") || s.resolve(sc) == nil {
+				return fmt.Errorf("openapi: unresolvable $ref %q", sc.Ref)
+			}
+			return nil
+		}
+		for _, p := range sc.Properties {
+			if err := walk(p); err != nil {
+				return err
+			}
+		}
+		return walk(sc.Items)
+	}
+	for _, sc := range s.schemas {
+		if err := walk(sc); err != nil {
+			return err
+		}
+	}
+	for _, r := range s.routes {
+		for _, op := range r.ops {
+			for _, p := range op.Parameters {
+				if err := walk(p.Schema); err != nil {
+					return err
+				}
+			}
+			if op.RequestBody != nil {
+				for _, mt := range op.RequestBody.Content {
+					if err := walk(mt.Schema); err != nil {
+						return err
+					}
+				}
+			}
+			for _, resp := range op.Responses {
+				for _, mt := range resp.Content {
+					if err := walk(mt.Schema); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
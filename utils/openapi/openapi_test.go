@@ -0,0 +1,122 @@
+package openapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSpec = `
+paths:
+  /api/v1/users/{id}:
+    get:
+      parameters:
+        - { in: path, name: id, required: true, schema: { type: integer, minimum: 1 } }
+      responses:
+        '200':
+          content:
+            application/json:
+              schema: { $ref: '#/components/schemas/User' }
+  /api/v1/users/export:
+    get:
+      parameters:
+        - { in: query, name: format, schema: { type: string, enum: [csv] } }
+      responses:
+        '200': {}
+  /api/v1/users:
+    post:
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema: { $ref: '#/components/schemas/User' }
+      responses:
+        '201': {}
+components:
+  schemas:
+    User:
+      type: object
+      required: [name, email]
+      properties:
+        name: { type: string, minLength: 2 }
+        email: { type: string, format: email }
+        tags: { type: array, items: { type: string }, maxItems: 2 }
+`
+
+func TestFind_PrefersLiteralSegments(t *testing.T) {
+	s, err := Parse([]byte(testSpec))
+	require.NoError(t, err)
+
+	op, params := s.Find(http.MethodGet, "/api/v1/users/export")
+	require.NotNil(t, op)
+	assert.Empty(t, params)
+	assert.Equal(t, "format", op.Parameters[0].Name)
+
+	op, params = s.Find(http.MethodGet, "/api/v1/users/42")
+	require.NotNil(t, op)
+	assert.Equal(t, map[string]string{"id": "42"}, params)
+
+	op, _ = s.Find(http.MethodDelete, "/api/v1/users/42")
+	assert.Nil(t, op, "undocumented method")
+}
+
+func TestValidateRequest(t *testing.T) {
+	s, err := Parse([]byte(testSpec))
+	require.NoError(t, err)
+
+	op, params := s.Find(http.MethodGet, "/api/v1/users/0")
+	v := s.ValidateRequest(op, params, httptest.NewRequest(http.MethodGet, "/api/v1/users/0", nil), nil)
+	require.Len(t, v, 1)
+	assert.Equal(t, "path.id", v[0].Field)
+	assert.Equal(t, CodeRange, v[0].Code)
+
+	op, params = s.Find(http.MethodGet, "/api/v1/users/export")
+	v = s.ValidateRequest(op, params, httptest.NewRequest(http.MethodGet, "/api/v1/users/export?format=xlsx", nil), nil)
+	require.Len(t, v, 1)
+	assert.Equal(t, CodeEnum, v[0].Code)
+
+	op, params = s.Find(http.MethodPost, "/api/v1/users")
+	body := `{"name":"A","email":"nope","tags":["a","b",3]}`
+	v = s.ValidateRequest(op, params, httptest.NewRequest(http.MethodPost, "/api/v1/users", strings.NewReader(body)), []byte(body))
+	codes := map[string]string{}
+	for _, x := range v {
+		codes[x.Field] = x.Code
+	}
+	assert.Equal(t, map[string]string{
+		"body.email":   CodeFormat,
+		"body.name":    CodeLength,
+		"body.tags":    CodeLength,
+		"body.tags[2]": CodeType,
+	}, codes)
+
+	v = s.ValidateRequest(op, params, httptest.NewRequest(http.MethodPost, "/api/v1/users", nil), nil)
+	require.Len(t, v, 1)
+	assert.Equal(t, CodeRequired, v[0].Code)
+}
+
+func TestValidateResponse(t *testing.T) {
+	s, err := Parse([]byte(testSpec))
+	require.NoError(t, err)
+	op, _ := s.Find(http.MethodGet, "/api/v1/users/1")
+
+	assert.Empty(t, s.ValidateResponse(op, 200, "application/json; charset=utf-8", []byte(`{"name":"Ahmed","email":"a@b.c"}`)))
+	assert.Len(t, s.ValidateResponse(op, 200, "application/json", []byte(`{"name":"Ahmed"}`)), 1)
+	assert.Equal(t, CodeStatus, s.ValidateResponse(op, 202, "", nil)[0].Code)
+	assert.Empty(t, s.ValidateResponse(op, 404, "application/json", []byte(`{"error":"x"}`)), "undocumented errors are tolerated")
+}
+
+func TestParse_UnresolvableRef(t *testing.T) {
+	_, err := Parse([]byte("components:\n  schemas:\n    A: { $ref: '#/components/schemas/Missing' }\n"))
+	assert.Error(t, err)
+}
+
+func TestLoad_ShippedSpec(t *testing.T) {
+	s, err := Load("../../docs/swagger.yaml")
+	require.NoError(t, err)
+	op, _ := s.Find(http.MethodPost, "/api/v1/auth/login")
+	assert.NotNil(t, op)
+}
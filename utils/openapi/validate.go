@@ -0,0 +1,293 @@
+// Schema checks for request parameters and JSON bodies.
+
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"HelmyTask/core"
+)
+
+// Violation codes for spec mismatches (same shape as core's business-rule violations).
+const (
+	CodeRequired = "schema_required" // missing parameter, body or property
+	CodeType     = "schema_type"     // wrong JSON type / unparsable parameter
+	CodeEnum     = "schema_enum"     // value not in the documented enum
+	CodeFormat   = "schema_format"   // email, date-time, date, uri, ...
+	CodeLength   = "schema_length"   // string length or array size
+	CodeRange    = "schema_range"    // numeric minimum/maximum
+	CodeBody     = "schema_body"     // body isn't valid JSON
+	CodeStatus   = "schema_status"   // response status not documented
+)
+
+// ignoredHeaders are header parameters OpenAPI says to ignore (auth is checked by middleware).
+var ignoredHeaders = map[string]bool{"Accept": true, "Content-Type": true, "Authorization": true}
+
+// ValidateRequest checks r's parameters and, for JSON operations, body against op.
+// pathParams comes from Find. Values are never echoed back (they may be passwords).
+func (s *Spec) ValidateRequest(op *Operation, pathParams map[string]string, r *http.Request, body []byte) core.Violations {
+	var v core.Violations
+	for _, p := range op.Parameters {
+		var (
+			raw     string
+			present bool
+		)
+		switch p.In {
+		case "path":
+			raw, present = pathParams[p.Name]
+		case "query":
+			present = r.URL.Query().Has(p.Name)
+			raw = r.URL.Query().Get(p.Name)
+		case "header":
+			if ignoredHeaders[http.CanonicalHeaderKey(p.Name)] {
+				continue
+			}
+			raw = r.Header.Get(p.Name)
+			present = raw != ""
+		default:
+			continue // cookie parameters aren't used
+		}
+		field := p.In + "." + p.Name
+		if !present {
+			if p.Required {
+				v = append(v, core.Violation{Field: field, Code: CodeRequired, Message: "is required"})
+			}
+			continue
+		}
+		v = append(v, s.validateParam(field, raw, s.resolve(p.Schema))...)
+	}
+
+	if op.RequestBody == nil {
+		return v
+	}
+	mt, ok := op.RequestBody.Content["application/json"]
+	if !ok {
+		return v // multipart/other bodies aren't checked
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		if op.RequestBody.Required {
+			v = append(v, core.Violation{Field: "body", Code: CodeRequired, Message: "a JSON body is required"})
+		}
+		return v
+	}
+	return append(v, s.ValidateJSON("body", mt.Schema, body)...)
+}
+
+// ValidateResponse checks a response against op: the status must be documented (errors are
+// often left out of the spec, so only 2xx/3xx are insisted on) and a JSON body must match
+// its schema when one is given.
+func (s *Spec) ValidateResponse(op *Operation, status int, contentType string, body []byte) core.Violations {
+	resp, ok := op.Responses[strconv.Itoa(status)]
+	if !ok {
+		resp, ok = op.Responses[fmt.Sprintf("%dXX", status/100)]
+	}
+	if !ok {
+		resp, ok = op.Responses["default"]
+	}
+	if !ok {
+		if status < 400 {
+			return core.Violations{{Field: "status", Code: CodeStatus, Message: fmt.Sprintf("%d is not a documented response", status)}}
+		}
+		return nil
+	}
+	mt, ok := resp.Content["application/json"]
+	if !ok || mt.Schema == nil || !strings.HasPrefix(contentType, "application/json") || len(body) == 0 {
+		return nil
+	}
+	return s.ValidateJSON("response", mt.Schema, body)
+}
+
+// ValidateJSON decodes body and checks it against sc; field paths start with prefix.
+func (s *Spec) ValidateJSON(prefix string, sc *Schema, body []byte) core.Violations {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber() // keep integers distinguishable from floats
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return core.Violations{{Field: prefix, Code: CodeBody, Message: "is not valid JSON"}}
+	}
+	var v core.Violations
+	s.validate(prefix, sc, doc, &v)
+	return v
+}
+
+// validateParam converts a string parameter to the schema's type, then validates it.
+func (s *Spec) validateParam(field, raw string, sc *Schema) core.Violations {
+	if sc == nil {
+		return nil
+	}
+	var val interface{} = raw
+	switch sc.Type {
+	case "integer", "number":
+		if _, err := strconv.ParseFloat(raw, 64); err != nil {
+			return core.Violations{{Field: field, Code: CodeType, Message: "must be " + article(sc.Type)}}
+		}
+		val = json.Number(raw)
+	case "boolean":
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return core.Violations{{Field: field, Code: CodeType, Message: "must be true or false"}}
+		}
+		val = b
+	}
+	var v core.Violations
+	s.validate(field, sc, val, &v)
+	return v
+}
+
+// validate appends every mismatch between val (decoded with UseNumber) and sc to v.
+func (s *Spec) validate(field string, sc *Schema, val interface{}, v *core.Violations) {
+	sc = s.resolve(sc)
+	if sc == nil {
+		return
+	}
+	add := func(code, msg string) { *v = append(*v, core.Violation{Field: field, Code: code, Message: msg}) }
+	if val == nil {
+		if !sc.Nullable && sc.Type != "" {
+			add(CodeType, "must not be null")
+		}
+		return
+	}
+	if !matchesType(sc.Type, val) {
+		add(CodeType, "must be "+article(sc.Type))
+		return
+	}
+	if len(sc.Enum) > 0 && !inEnum(sc.Enum, val) {
+		add(CodeEnum, fmt.Sprintf("must be one of %v", sc.Enum))
+	}
+
+	switch x := val.(type) {
+	case string:
+		n := len([]rune(x))
+		if sc.MinLength != nil && n < *sc.MinLength {
+			add(CodeLength, fmt.Sprintf("must be at least %d characters", *sc.MinLength))
+		}
+		if sc.MaxLength != nil && n > *sc.MaxLength {
+			add(CodeLength, fmt.Sprintf("must be at most %d characters", *sc.MaxLength))
+		}
+		if msg := checkFormat(sc.Format, x); msg != "" {
+			add(CodeFormat, msg)
+		}
+	case json.Number:
+		f, _ := x.Float64()
+		if sc.Minimum != nil && f < *sc.Minimum {
+			add(CodeRange, fmt.Sprintf("must be >= %v", *sc.Minimum))
+		}
+		if sc.Maximum != nil && f > *sc.Maximum {
+			add(CodeRange, fmt.Sprintf("must be <= %v", *sc.Maximum))
+		}
+	case []interface{}:
+		if sc.MinItems != nil && len(x) < *sc.MinItems {
+			add(CodeLength, fmt.Sprintf("must have at least %d items", *sc.MinItems))
+		}
+		if sc.MaxItems != nil && len(x) > *sc.MaxItems {
+			add(CodeLength, fmt.Sprintf("must have at most %d items", *sc.MaxItems))
+		}
+		for i, item := range x {
+			s.validate(fmt.Sprintf("%s[%d]", field, i), sc.Items, item, v)
+		}
+	case map[string]interface{}:
+		for _, name := range sc.Required {
+			if _, ok := x[name]; !ok {
+				*v = append(*v, core.Violation{Field: field + "." + name, Code: CodeRequired, Message: "is required"})
+			}
+		}
+		names := make([]string, 0, len(sc.Properties)) // stable order for readable reports
+		for name := range sc.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if pv, ok := x[name]; ok {
+				s.validate(field+"."+name, sc.Properties[name], pv, v)
+			}
+		}
+	}
+}
+
+// matchesType reports whether a decoded JSON value has the schema type ("" = any).
+func matchesType(typ string, val interface{}) bool {
+	switch typ {
+	case "":
+		return true
+	case "string":
+		_, ok := val.(string)
+		return ok
+	case "integer":
+		n, ok := val.(json.Number)
+		if !ok {
+			return false
+		}
+		_, err := n.Int64()
+		return err == nil
+	case "number":
+		_, ok := val.(json.Number)
+		return ok
+	case "boolean":
+		_, ok := val.(bool)
+		return ok
+	case "array":
+		_, ok := val.([]interface{})
+		return ok
+	case "object":
+		_, ok := val.(map[string]interface{})
+		return ok
+	}
+	return true // unknown type keyword: don't guess
+}
+
+// inEnum compares val with the enum entries (YAML decodes numbers as int/float64).
+func inEnum(enum []interface{}, val interface{}) bool {
+	for _, e := range enum {
+		if n, ok := val.(json.Number); ok {
+			if fmt.Sprint(e) == n.String() {
+				return true
+			}
+			continue
+		}
+		if reflect.DeepEqual(e, val) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkFormat returns a message when s doesn't match a known format; unknown formats pass.
+func checkFormat(format, s string) string {
+	switch format {
+	case "email":
+		if _, err := mail.ParseAddress(s); err != nil || strings.ContainsAny(s, "<> ") {
+			return "must be an email address"
+		}
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339, s); err != nil {
+			return "must be an RFC 3339 timestamp"
+		}
+	case "date":
+		if _, err := time.Parse("2006-01-02", s); err != nil {
+			return "must be a date (YYYY-MM-DD)"
+		}
+	case "uri":
+		if u, err := url.Parse(s); err != nil || u.Scheme == "" || u.Host == "" {
+			return "must be an absolute URI"
+		}
+	}
+	return ""
+}
+
+// article renders "an integer", "a string", ...
+func article(typ string) string {
+	if typ != "" && strings.ContainsRune("aeiou", rune(typ[0])) {
+		return "an " + typ
+	}
+	return "a " + typ
+}
@@ -0,0 +1,163 @@
+// Package origins is the registry of trusted browser origins consulted by the CORS and CSRF
+// middlewares.
+//
+// Entries come from config (fixed per environment) and from the admin API (stored in the Redis
+// set "origins:trusted", so a new frontend domain works on every replica without a redeploy).
+// Each replica caches the Redis set for a few seconds to keep Redis off the request path.
+package origins
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisKey holds the runtime-added origins.
+const redisKey = "origins:trusted"
+
+// ErrInvalidOrigin is returned for entries that aren't scheme://host[:port].
+var ErrInvalidOrigin = errors.New(`origin must be "https://host[:port]" or "https://*.domain" (no path)`)
+
+// Registry answers "is this Origin trusted?".
+type Registry struct {
+	rdb    *redis.Client
+	static []string      // normalized config entries
+	ttl    time.Duration // local cache lifetime of the Redis set
+
+	mu      sync.Mutex
+	dynamic []string
+	fetched time.Time
+}
+
+// New builds a registry from config entries (invalid ones are an error, so typos fail at boot).
+// rdb may be nil: then only the config entries apply.
+func New(rdb *redis.Client, configured []string, ttl time.Duration) (*Registry, error) {
+	r := &Registry{rdb: rdb, ttl: ttl}
+	for _, o := range configured {
+		n, err := Normalize(o)
+		if err != nil {
+			return nil, err
+		}
+		r.static = append(r.static, n)
+	}
+	return r, nil
+}
+
+// Normalize validates a pattern and returns its canonical form (lower-case, no trailing slash).
+func Normalize(origin string) (string, error) {
+	u, err := url.Parse(strings.TrimSuffix(strings.TrimSpace(origin), "/"))
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" ||
+		u.Path != "" || u.RawQuery != "" || u.Fragment != "" || u.User != nil {
+		return "", ErrInvalidOrigin
+	}
+	host := strings.ToLower(u.Host)
+	if strings.Contains(strings.TrimPrefix(host, "*."), "*") {
+		return "", ErrInvalidOrigin // only a leading "*." label
+	}
+	return u.Scheme + "://" + host, nil
+}
+
+// Allowed reports whether the Origin header value is trusted. Redis trouble falls back to the
+// last good copy (and config entries), never to "allow everything".
+func (r *Registry) Allowed(ctx context.Context, origin string) bool {
+	o, err := Normalize(origin)
+	if err != nil || strings.Contains(o, "*") {
+		return false
+	}
+	for _, p := range r.static {
+		if match(p, o) {
+			return true
+		}
+	}
+	for _, p := range r.cached(ctx) {
+		if match(p, o) {
+			return true
+		}
+	}
+	return false
+}
+
+// match compares scheme and host[:port]; "*.example.com" covers subdomains only.
+func match(pattern, origin string) bool {
+	if pattern == origin {
+		return true
+	}
+	pScheme, pHost, _ := strings.Cut(pattern, "://")
+	oScheme, oHost, _ := strings.Cut(origin, "://")
+	suffix, ok := strings.CutPrefix(pHost, "*.")
+	return ok && pScheme == oScheme && strings.HasSuffix(oHost, "."+suffix)
+}
+
+func (r *Registry) cached(ctx context.Context) []string {
+	if r.rdb == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if time.Since(r.fetched) < r.ttl {
+		return r.dynamic
+	}
+	if members, err := r.rdb.SMembers(ctx, redisKey).Result(); err == nil {
+		r.dynamic = members
+	}
+	r.fetched = time.Now() // also on error: don't hammer a struggling Redis
+	return r.dynamic
+}
+
+// List returns config and runtime entries.
+func (r *Registry) List(ctx context.Context) (configured, runtime []string, err error) {
+	configured = append([]string{}, r.static...)
+	runtime = []string{}
+	if r.rdb != nil {
+		if runtime, err = r.rdb.SMembers(ctx, redisKey).Result(); err != nil {
+			return nil, nil, err
+		}
+		sort.Strings(runtime)
+	}
+	return configured, runtime, nil
+}
+
+// Add trusts origin at runtime on every replica (within the cache TTL elsewhere, at once here).
+func (r *Registry) Add(ctx context.Context, origin string) (string, error) {
+	n, err := Normalize(origin)
+	if err != nil {
+		return "", err
+	}
+	if r.rdb == nil {
+		return "", errors.New("runtime origins need Redis")
+	}
+	if err := r.rdb.SAdd(ctx, redisKey, n).Err(); err != nil {
+		return "", err
+	}
+	r.invalidate()
+	return n, nil
+}
+
+// Remove drops a runtime entry; config entries can only be removed by a config change.
+func (r *Registry) Remove(ctx context.Context, origin string) (bool, error) {
+	n, err := Normalize(origin)
+	if err != nil {
+		return false, err
+	}
+	if r.rdb == nil {
+		return false, nil
+	}
+	removed, err := r.rdb.SRem(ctx, redisKey, n).Result()
+	if err != nil {
+		return false, err
+	}
+	r.invalidate()
+	return removed > 0, nil
+}
+
+func (r *Registry) invalidate() {
+	r.mu.Lock()
+	r.fetched = time.Time{}
+	r.mu.Unlock()
+}
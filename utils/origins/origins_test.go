@@ -0,0 +1,53 @@
+package origins
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"HelmyTask/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalize(t *testing.T) {
+	n, err := Normalize(" HTTPS://App.Example.com/ ")
+	require.NoError(t, err)
+	assert.Equal(t, "https://app.example.com", n)
+
+	for _, bad := range []string{"app.example.com", "ftp://x.com", "https://x.com/path", "https://a.*.com", "https://u@x.com", "*"} {
+		_, err := Normalize(bad)
+		assert.ErrorIs(t, err, ErrInvalidOrigin, bad)
+	}
+}
+
+func TestAllowed_ConfigAndRuntime(t *testing.T) {
+	rdb, m := mocks.NewRedisMock()
+	r, err := New(rdb, []string{"https://*.example.com", "http://localhost:3000"}, time.Minute)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	m.ExpectSMembers(redisKey).SetVal([]string{"https://new.partner.io"})
+	assert.True(t, r.Allowed(ctx, "https://new.partner.io"))
+	assert.True(t, r.Allowed(ctx, "https://app.example.com"))
+	assert.True(t, r.Allowed(ctx, "http://localhost:3000"))
+	assert.False(t, r.Allowed(ctx, "https://example.com"), "wildcard covers subdomains only")
+	assert.False(t, r.Allowed(ctx, "http://app.example.com"), "scheme must match")
+	assert.False(t, r.Allowed(ctx, "https://evil-example.com"))
+	assert.False(t, r.Allowed(ctx, "null"))
+	assert.NoError(t, m.ExpectationsWereMet(), "Redis set read once per TTL")
+
+	// Add invalidates the local copy
+	m.ExpectSAdd(redisKey, "https://other.io").SetVal(1)
+	m.ExpectSMembers(redisKey).SetVal([]string{"https://new.partner.io", "https://other.io"})
+	_, err = r.Add(ctx, "https://Other.io/")
+	require.NoError(t, err)
+	assert.True(t, r.Allowed(ctx, "https://other.io"))
+	assert.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestNew_RejectsBadConfig(t *testing.T) {
+	_, err := New(nil, []string{"example.com"}, time.Second)
+	assert.ErrorIs(t, err, ErrInvalidOrigin)
+}
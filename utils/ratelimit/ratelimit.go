@@ -0,0 +1,101 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"HelmyTask/utils/redisscript"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Rule is a token bucket: refills RequestsPerMinute tokens per minute, holds at most Burst.
+type Rule struct {
+	RequestsPerMinute int
+	Burst             int // bucket size; 0 means "same as RequestsPerMinute"
+}
+
+// capacity is the bucket size used for a rule.
+func (r Rule) capacity() float64 {
+	if r.Burst > 0 {
+		return float64(r.Burst)
+	}
+	return math.Max(float64(r.RequestsPerMinute), 1)
+}
+
+// ratePerSecond converts the per-minute rule into a refill rate.
+func (r Rule) ratePerSecond() float64 {
+	return float64(r.RequestsPerMinute) / 60
+}
+
+// bucketScript refills and takes one token in a single atomic step, so any number of replicas
+// can share a bucket without read-modify-write races. The clock is Redis' own TIME, not the
+// callers', so skew between replicas can't mint extra tokens.
+//
+// KEYS[1] bucket hash {tokens, ts(µs)}; ARGV[1] refill rate per second; ARGV[2] capacity.
+// Returns {allowed (0|1), wait in ms until the next token}.
+var bucketScript = redisscript.Register("ratelimit.bucket", `
+if redis.replicate_commands then redis.replicate_commands() end -- TIME before writes (Redis < 5)
+local rate = tonumber(ARGV[1])
+local cap = tonumber(ARGV[2])
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000000 + tonumber(t[2])
+local b = redis.call("HMGET", KEYS[1], "tokens", "ts")
+local tokens = tonumber(b[1]) or cap -- empty key = full bucket
+local last = tonumber(b[2]) or now
+if now > last then
+	tokens = math.min(cap, tokens + (now - last) / 1000000 * rate)
+end
+local allowed, wait = 0, 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	wait = math.ceil((1 - tokens) / rate * 1000)
+end
+redis.call("HSET", KEYS[1], "tokens", string.format("%.4f", tokens), "ts", string.format("%d", now)) -- %d: µs overflow %.14g
+redis.call("PEXPIRE", KEYS[1], math.ceil(cap / rate * 1000) + 1000) -- full refill → key can go
+return {allowed, wait}`)
+
+// Limiter keeps one bucket per key in Redis, shared by every replica.
+type Limiter struct {
+	rdb    *redis.Client
+	prefix string // e.g. "rl:"
+}
+
+// New creates a Redis token-bucket limiter.
+func New(rdb *redis.Client, prefix string) *Limiter {
+	return &Limiter{rdb: rdb, prefix: prefix}
+}
+
+// ScriptSHA is the SHA1 the bucket script is cached under (preloaded by redisscript.LoadAll).
+func ScriptSHA() string { return bucketScript.SHA() }
+
+// Allow takes one token from key's bucket. When empty it reports how long until a token is available.
+func (l *Limiter) Allow(ctx context.Context, key string, rule Rule) (bool, time.Duration, error) {
+	if rule.RequestsPerMinute <= 0 {
+		return true, 0, nil // rule disabled
+	}
+	res, err := bucketScript.Run(ctx, l.rdb, []string{l.prefix + key}, rule.ratePerSecond(), rule.capacity()).Int64Slice()
+	if err != nil {
+		return false, 0, err
+	}
+	if len(res) != 2 {
+		return false, 0, fmt.Errorf("ratelimit: unexpected script reply %v", res)
+	}
+	return res[0] == 1, time.Duration(res[1]) * time.Millisecond, nil
+}
+
+// Reset refills the buckets of keys at once (by dropping them; a missing bucket is full).
+func (l *Limiter) Reset(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	full := make([]string, len(keys))
+	for i, k := range keys {
+		full[i] = l.prefix + k
+	}
+	return l.rdb.Del(ctx, full...).Err()
+}
@@ -0,0 +1,58 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"HelmyTask/mocks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRule_Defaults(t *testing.T) {
+	assert.Equal(t, 3.0, Rule{RequestsPerMinute: 60, Burst: 3}.capacity())
+	assert.Equal(t, 10.0, Rule{RequestsPerMinute: 10}.capacity(), "burst defaults to the per-minute rate")
+	assert.Equal(t, 1.0, Rule{RequestsPerMinute: 60}.ratePerSecond())
+}
+
+func TestAllow_SingleAtomicScriptCall(t *testing.T) {
+	rdb, m := mocks.NewRedisMock()
+	l := New(rdb, "rl:")
+	rule := Rule{RequestsPerMinute: 60, Burst: 3}
+	ctx := context.Background()
+
+	m.ExpectEvalSha(ScriptSHA(), []string{"rl:auth:1.2.3.4"}, 1.0, 3.0).SetVal([]interface{}{int64(1), int64(0)})
+	ok, wait, err := l.Allow(ctx, "auth:1.2.3.4", rule)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Zero(t, wait)
+
+	m.ExpectEvalSha(ScriptSHA(), []string{"rl:auth:1.2.3.4"}, 1.0, 3.0).SetVal([]interface{}{int64(0), int64(750)})
+	ok, wait, err = l.Allow(ctx, "auth:1.2.3.4", rule)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, 750*time.Millisecond, wait)
+
+	// Redis trouble is returned (the middleware fails open)
+	m.ExpectEvalSha(ScriptSHA(), []string{"rl:auth:1.2.3.4"}, 1.0, 3.0).SetErr(errors.New("connection refused"))
+	_, _, err = l.Allow(ctx, "auth:1.2.3.4", rule)
+	assert.Error(t, err)
+	assert.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestAllow_DisabledRuleSkipsRedis(t *testing.T) {
+	rdb, m := mocks.NewRedisMock()
+	ok, _, err := New(rdb, "rl:").Allow(context.Background(), "k", Rule{})
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestReset_DropsBuckets(t *testing.T) {
+	rdb, m := mocks.NewRedisMock()
+	m.ExpectDel("rl:auth:1.2.3.4", "rl:api:1.2.3.4").SetVal(1)
+	assert.NoError(t, New(rdb, "rl:").Reset(context.Background(), "auth:1.2.3.4", "api:1.2.3.4"))
+	assert.NoError(t, m.ExpectationsWereMet())
+}
@@ -0,0 +1,165 @@
+// Package readcache is a read-through Redis cache for entities loaded from the database: a
+// lookup tries "<entity>:<id>" first and falls back to the loader (a repository's finder),
+// storing what it found for the entity's TTL. Writers keep it honest with Set (warm/refresh)
+// and Invalidate, which also runs the invalidation hooks, e.g. to drop a dependent cache.
+//
+// Values are stored as JSON, so fields tagged json:"-" (password hashes, secrets) are never
+// cached and come back zero: cache only what the read path needs. Redis errors are logged and
+// treated as misses; a nil Cache (or a nil client) always goes to the loader.
+package readcache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"HelmyTask/utils/redislog"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Recorder counts hits and misses per entity; *metrics.Metrics satisfies it.
+type Recorder interface {
+	CacheHit(cache string)
+	CacheMiss(cache string)
+}
+
+// Options are the optional parts of a Cache.
+type Options struct {
+	Metrics Recorder         // nil = not counted
+	Log     *redislog.Logger // HIT/MISS/SET trace and Redis errors (nil = silent)
+}
+
+// Cache caches values of type V (usually a model pointer, e.g. *models.User) by ID.
+type Cache[K comparable, V any] struct {
+	rdb    *redis.Client
+	entity string               // key prefix and metrics label, e.g. "user"
+	ttl    func() time.Duration // read on every write, so runtime settings apply
+	opts   Options
+
+	mu    sync.RWMutex
+	hooks []func(K)
+}
+
+// New builds a cache for entity; ttl is called on every write.
+func New[K comparable, V any](rdb *redis.Client, entity string, ttl func() time.Duration, opts Options) *Cache[K, V] {
+	return &Cache[K, V]{rdb: rdb, entity: entity, ttl: ttl, opts: opts}
+}
+
+// Key is the Redis key for id, e.g. "user:42".
+func (c *Cache[K, V]) Key(id K) string { return fmt.Sprintf("%s:%v", c.entity, id) }
+
+// enabled reports whether Redis is there to use.
+func (c *Cache[K, V]) enabled() bool { return c != nil && c.rdb != nil }
+
+// Get returns the cached value for id, or calls load and caches its result. Loader errors
+// (not found included) are returned as they are and nothing is cached. Redis and the loader
+// run under ctx.
+func (c *Cache[K, V]) Get(ctx context.Context, id K, load func(context.Context, K) (V, error)) (V, error) {
+	if c.enabled() {
+		key := c.Key(id)
+		c.trace("info", "cache try GET", map[string]string{"key": key})
+		val, err := c.rdb.Get(ctx, key).Bytes()
+		switch {
+		case err == nil:
+			var v V
+			if json.Unmarshal(val, &v) == nil {
+				c.trace("info", "cache HIT", map[string]string{"key": key})
+				c.hit()
+				return v, nil
+			}
+			c.trace("warn", "cache unmarshal failed", map[string]string{"key": key}) // e.g. an older layout; reload
+		case errors.Is(err, redis.Nil):
+			c.trace("warn", "cache MISS", map[string]string{"key": key})
+		default:
+			c.trace("error", "cache GET error", map[string]string{"key": key, "err": err.Error()})
+		}
+		c.miss()
+	}
+	v, err := load(ctx, id)
+	if err != nil {
+		return v, err
+	}
+	c.Set(ctx, id, v)
+	return v, nil
+}
+
+// ReadThrough decorates a repository finder: the returned func answers from the cache and
+// loads through find on a miss.
+func (c *Cache[K, V]) ReadThrough(find func(context.Context, K) (V, error)) func(context.Context, K) (V, error) {
+	return func(ctx context.Context, id K) (V, error) { return c.Get(ctx, id, find) }
+}
+
+// Set stores v for the entity's TTL (best-effort).
+func (c *Cache[K, V]) Set(ctx context.Context, id K, v V) {
+	if !c.enabled() {
+		return
+	}
+	b, err := json.Marshal(v)
+	if err != nil || len(b) == 0 {
+		return
+	}
+	key, ttl := c.Key(id), c.ttl()
+	if err := c.rdb.Set(ctx, key, b, ttl).Err(); err != nil {
+		c.trace("error", "cache SET error", map[string]string{"key": key, "err": err.Error()})
+		return
+	}
+	c.trace("info", "cache SET", map[string]string{"key": key, "ttl": ttl.String()})
+}
+
+// Invalidate drops the cached values for ids in one DEL (best-effort: the next read reloads),
+// then runs the invalidation hooks for each id. It follows a committed write, so the DEL is not
+// cancelled with ctx: a client hanging up must not leave a stale entry behind.
+func (c *Cache[K, V]) Invalidate(ctx context.Context, ids ...K) {
+	if c == nil || len(ids) == 0 {
+		return
+	}
+	if c.rdb != nil {
+		keys := make([]string, len(ids))
+		for i, id := range ids {
+			keys[i] = c.Key(id)
+		}
+		_ = c.rdb.Del(context.WithoutCancel(ctx), keys...).Err()
+	}
+	c.mu.RLock()
+	hooks := c.hooks
+	c.mu.RUnlock()
+	for _, id := range ids {
+		for _, fn := range hooks {
+			fn(id)
+		}
+	}
+}
+
+// OnInvalidate registers fn to run after every Invalidate, once per ID, e.g. to drop a cache
+// derived from this entity. fn runs synchronously and must be cheap.
+func (c *Cache[K, V]) OnInvalidate(fn func(K)) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	c.hooks = append(c.hooks, fn)
+	c.mu.Unlock()
+}
+
+// trace writes to the Redis log, if there is one.
+func (c *Cache[K, V]) trace(level, msg string, meta map[string]string) {
+	if c.opts.Log != nil {
+		c.opts.Log.Log(level, msg, redislog.Fields{}, meta)
+	}
+}
+
+func (c *Cache[K, V]) hit() {
+	if c.opts.Metrics != nil {
+		c.opts.Metrics.CacheHit(c.entity)
+	}
+}
+
+func (c *Cache[K, V]) miss() {
+	if c.opts.Metrics != nil {
+		c.opts.Metrics.CacheMiss(c.entity)
+	}
+}
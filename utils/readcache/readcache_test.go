@@ -0,0 +1,99 @@
+package readcache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type widget struct {
+	ID     uint   `json:"id"`
+	Name   string `json:"name"`
+	Secret string `json:"-"`
+}
+
+type counter map[string]int
+
+func (c counter) CacheHit(cache string)  { c[cache+" hit"]++ }
+func (c counter) CacheMiss(cache string) { c[cache+" miss"]++ }
+
+func TestCache_ReadThrough(t *testing.T) {
+	rdb, m := redismock.NewClientMock()
+	counts := counter{}
+	c := New[uint, *widget](rdb, "widget", func() time.Duration { return time.Minute }, Options{Metrics: counts})
+	ctx := context.Background()
+	loads := 0
+	find := c.ReadThrough(func(_ context.Context, id uint) (*widget, error) {
+		loads++
+		if id == 404 {
+			return nil, errors.New("not found")
+		}
+		return &widget{ID: id, Name: "w", Secret: "s3cret"}, nil
+	})
+
+	// miss: loaded and stored; json:"-" fields never reach Redis
+	m.ExpectGet("widget:7").RedisNil()
+	m.ExpectSet("widget:7", []byte(`{"id":7,"name":"w"}`), time.Minute).SetVal("OK")
+	w, err := find(ctx, 7)
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", w.Secret, "the loaded value is returned as is")
+
+	// hit
+	m.ExpectGet("widget:7").SetVal(`{"id":7,"name":"w"}`)
+	w, err = find(ctx, 7)
+	require.NoError(t, err)
+	assert.Equal(t, &widget{ID: 7, Name: "w"}, w)
+	assert.Equal(t, 1, loads)
+
+	// loader errors are returned and not cached
+	m.ExpectGet("widget:404").RedisNil()
+	_, err = find(ctx, 404)
+	assert.EqualError(t, err, "not found")
+
+	// a broken entry is reloaded
+	m.ExpectGet("widget:8").SetVal(`not json`)
+	m.ExpectSet("widget:8", []byte(`{"id":8,"name":"w"}`), time.Minute).SetVal("OK")
+	_, err = find(ctx, 8)
+	require.NoError(t, err)
+
+	assert.NoError(t, m.ExpectationsWereMet())
+	assert.Equal(t, counter{"widget hit": 1, "widget miss": 3}, counts)
+}
+
+func TestCache_InvalidateRunsHooks(t *testing.T) {
+	rdb, m := redismock.NewClientMock()
+	c := New[uint, *widget](rdb, "widget", func() time.Duration { return time.Minute }, Options{})
+	var dropped []uint
+	c.OnInvalidate(func(id uint) { dropped = append(dropped, id) })
+
+	m.ExpectDel("widget:1", "widget:2").SetVal(2) // one DEL for all keys
+	c.Invalidate(context.Background(), 1, 2)
+	c.Invalidate(context.Background()) // nothing to do
+	assert.Equal(t, []uint{1, 2}, dropped)
+	assert.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestCache_WithoutRedis(t *testing.T) {
+	c := New[uint, *widget](nil, "widget", func() time.Duration { return time.Minute }, Options{})
+	var nilCache *Cache[uint, *widget]
+	for _, c := range []*Cache[uint, *widget]{c, nilCache} {
+		w, err := c.Get(context.Background(), 1, func(_ context.Context, id uint) (*widget, error) { return &widget{ID: id}, nil })
+		require.NoError(t, err)
+		assert.Equal(t, uint(1), w.ID)
+		c.Set(context.Background(), 1, w) // no-ops
+		c.Invalidate(context.Background(), 1)
+	}
+}
+
+func TestCache_LoaderGetsContext(t *testing.T) {
+	c := New[uint, *widget](nil, "widget", func() time.Duration { return time.Minute }, Options{})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel() // e.g. the client went away
+	_, err := c.Get(ctx, 1, func(ctx context.Context, id uint) (*widget, error) { return nil, ctx.Err() })
+	assert.ErrorIs(t, err, context.Canceled)
+}
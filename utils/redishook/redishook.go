@@ -0,0 +1,101 @@
+// Package redishook instruments the Redis client: per-command latency (for Prometheus) and a
+// log line for every command or pipeline slower than a threshold, so the cache path can be
+// diagnosed when Redis is under pressure. Timings include go-redis' own retries.
+//
+// Slow commands go to a stdout-only logger: copying them to the Redis log would add writes to
+// a Redis that is already slow, and those writes could be logged as slow in turn.
+package redishook
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Recorder receives one observation per command; *metrics.Metrics satisfies it.
+type Recorder interface {
+	RedisCommand(command, result string, elapsed time.Duration) // result: ok|error
+}
+
+// Options configure the hook; the zero value does nothing.
+type Options struct {
+	Metrics Recorder      // nil = not recorded
+	Slow    time.Duration // log commands at least this slow; 0 = off
+	Log     *slog.Logger  // where slow commands go (nil = slog.Default())
+}
+
+type hook struct {
+	opts Options
+	now  func() time.Time
+}
+
+// New returns a hook for rdb.AddHook.
+func New(opts Options) redis.Hook {
+	if opts.Log == nil {
+		opts.Log = slog.Default()
+	}
+	return &hook{opts: opts, now: time.Now}
+}
+
+func (h *hook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		start := h.now()
+		conn, err := next(ctx, network, addr)
+		if elapsed := h.now().Sub(start); h.opts.Slow > 0 && elapsed >= h.opts.Slow { // new pool connections; not a command
+			args := []any{"addr", addr, "duration_ms", ms(elapsed)}
+			if err != nil {
+				args = append(args, "err", err.Error())
+			}
+			h.opts.Log.Warn("redis: slow dial", args...)
+		}
+		return conn, err
+	}
+}
+
+func (h *hook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		start := h.now()
+		err := next(ctx, cmd)
+		h.observe(strings.ToLower(cmd.Name()), 1, h.now().Sub(start), err)
+		return err
+	}
+}
+
+func (h *hook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		start := h.now()
+		err := next(ctx, cmds)
+		h.observe("pipeline", len(cmds), h.now().Sub(start), err)
+		return err
+	}
+}
+
+// observe records one command (or pipeline of n). Only command names are logged: keys and
+// values may hold user data.
+func (h *hook) observe(command string, n int, elapsed time.Duration, err error) {
+	failed := err != nil && !errors.Is(err, redis.Nil) // absent key is a normal answer
+	if h.opts.Metrics != nil {
+		result := "ok"
+		if failed {
+			result = "error"
+		}
+		h.opts.Metrics.RedisCommand(command, result, elapsed)
+	}
+	if h.opts.Slow > 0 && elapsed >= h.opts.Slow {
+		args := []any{"command", command, "duration_ms", ms(elapsed), "threshold", h.opts.Slow.String()}
+		if n > 1 {
+			args = append(args, "commands", n)
+		}
+		if failed {
+			args = append(args, "err", err.Error())
+		}
+		h.opts.Log.Warn("redis: slow command", args...)
+	}
+}
+
+func ms(d time.Duration) float64 { return float64(d.Microseconds()) / 1000 }
@@ -0,0 +1,86 @@
+package redishook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type call struct {
+	command, result string
+	elapsed         time.Duration
+}
+
+type recorder []call
+
+func (r *recorder) RedisCommand(command, result string, elapsed time.Duration) {
+	*r = append(*r, call{command, result, elapsed})
+}
+
+// fixture returns a hook whose clock advances by the given durations, one per reading pair.
+func fixture(rec *recorder, slow time.Duration, out *bytes.Buffer, took ...time.Duration) *hook {
+	now := time.Unix(0, 0)
+	reads := 0
+	h := New(Options{Metrics: rec, Slow: slow, Log: slog.New(slog.NewJSONHandler(out, nil))}).(*hook)
+	h.now = func() time.Time {
+		if reads%2 == 1 { // the end of a command
+			now = now.Add(took[reads/2])
+		}
+		reads++
+		return now
+	}
+	return h
+}
+
+func TestHook_RecordsAndLogsSlowCommands(t *testing.T) {
+	var rec recorder
+	var out bytes.Buffer
+	h := fixture(&rec, 50*time.Millisecond, &out, time.Millisecond, 80*time.Millisecond, 2*time.Millisecond, 60*time.Millisecond)
+	ctx := context.Background()
+	ok := func(context.Context, redis.Cmder) error { return nil }
+	process := h.ProcessHook(ok)
+
+	require.NoError(t, process(ctx, redis.NewCmd(ctx, "GET", "user:1")))
+	require.NoError(t, process(ctx, redis.NewCmd(ctx, "evalsha", "abc", 1, "rl:1.2.3.4")))
+	assert.ErrorIs(t, h.ProcessHook(func(context.Context, redis.Cmder) error { return redis.Nil })(ctx, redis.NewCmd(ctx, "get", "user:2")), redis.Nil)
+	pipe := h.ProcessPipelineHook(func(context.Context, []redis.Cmder) error { return errors.New("i/o timeout") })
+	assert.Error(t, pipe(ctx, []redis.Cmder{redis.NewCmd(ctx, "lpush", "logs:app", "x"), redis.NewCmd(ctx, "ltrim", "logs:app", 0, 9)}))
+
+	assert.Equal(t, recorder{
+		{"get", "ok", time.Millisecond},
+		{"evalsha", "ok", 80 * time.Millisecond},
+		{"get", "ok", 2 * time.Millisecond}, // a missing key is an answer, not an error
+		{"pipeline", "error", 60 * time.Millisecond},
+	}, rec)
+
+	lines := bytes.Split(bytes.TrimSpace(out.Bytes()), []byte("\n"))
+	require.Len(t, lines, 2)
+	var first, second map[string]any
+	require.NoError(t, json.Unmarshal(lines[0], &first))
+	require.NoError(t, json.Unmarshal(lines[1], &second))
+	assert.Equal(t, "redis: slow command", first["msg"])
+	assert.Equal(t, "evalsha", first["command"])
+	assert.Equal(t, 80.0, first["duration_ms"])
+	assert.NotContains(t, string(lines[0]), "rl:1.2.3.4", "keys and args are never logged")
+	assert.Equal(t, "pipeline", second["command"])
+	assert.Equal(t, 2.0, second["commands"])
+	assert.Equal(t, "i/o timeout", second["err"])
+}
+
+func TestHook_SlowOff(t *testing.T) {
+	var rec recorder
+	var out bytes.Buffer
+	h := fixture(&rec, 0, &out, time.Second)
+	ctx := context.Background()
+	require.NoError(t, h.ProcessHook(func(context.Context, redis.Cmder) error { return nil })(ctx, redis.NewCmd(ctx, "get", "k")))
+	assert.Len(t, rec, 1)
+	assert.Zero(t, out.Len())
+}
@@ -0,0 +1,127 @@
+// Asynchronous writes: entries queue in memory and a background writer (Run) sends them to
+// Redis in pipelined batches, so logging doesn't cost the request path a round trip.
+
+package redislog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	asyncFlushInterval = 250 * time.Millisecond // a quiet logger still lands within this
+	asyncMaxBatch      = 500                    // entries per pipeline
+)
+
+// WithAsync queues up to size entries for the background writer started by Run. When the
+// queue is full, entries are dropped (and counted, see Run) rather than blocking the caller.
+// Before Run starts and after it returns, entries are written synchronously as without it.
+func WithAsync(size int) Option {
+	return func(l *Logger) {
+		if size > 0 {
+			l.queue = make(chan []byte, size)
+		}
+	}
+}
+
+// enqueue hands b to the background writer; false means the caller must write it itself.
+func (l *Logger) enqueue(b []byte) bool {
+	l.asyncMu.RLock()
+	defer l.asyncMu.RUnlock()
+	if !l.running {
+		return false
+	}
+	select {
+	case l.queue <- b:
+	default:
+		l.dropped.Add(1)
+	}
+	return true
+}
+
+// Run writes queued entries every asyncFlushInterval (or as soon as a batch fills) until ctx
+// is done, then writes whatever is still queued and returns; later entries are written
+// synchronously. Without WithAsync it returns at once. Run it once, and wait for it before
+// closing the Redis client.
+func (l *Logger) Run(ctx context.Context) {
+	if l == nil || l.rdb == nil || l.queue == nil {
+		return
+	}
+	l.asyncMu.Lock()
+	l.running = true
+	l.asyncMu.Unlock()
+
+	t := time.NewTicker(asyncFlushInterval)
+	defer t.Stop()
+	batch := make([][]byte, 0, asyncMaxBatch)
+	for {
+		select {
+		case b := <-l.queue:
+			if batch = append(batch, b); len(batch) >= asyncMaxBatch {
+				l.writeBatch(batch)
+				batch = batch[:0]
+			}
+		case <-t.C:
+			l.writeBatch(batch)
+			batch = batch[:0]
+		case <-ctx.Done():
+			l.asyncMu.Lock()
+			l.running = false // no enqueue can happen after this, so the drain below is complete
+			l.asyncMu.Unlock()
+			for {
+				select {
+				case b := <-l.queue:
+					if batch = append(batch, b); len(batch) >= asyncMaxBatch {
+						l.writeBatch(batch)
+						batch = batch[:0]
+					}
+				default:
+					l.writeBatch(batch)
+					return
+				}
+			}
+		}
+	}
+}
+
+// writeBatch sends entries (oldest first) in one pipeline: a single LPUSH, LTRIM and EXPIRE,
+// or one XADD each and a single XTRIM in stream mode. Entries dropped since the last batch
+// are reported by a warn entry at the end.
+func (l *Logger) writeBatch(batch [][]byte) {
+	if n := l.dropped.Swap(0); n > 0 {
+		en := newEntry("warn", "redislog: queue full, entries dropped", Fields{}, map[string]string{"dropped": strconv.FormatInt(n, 10)})
+		b, _ := json.Marshal(en)
+		batch = append(batch, b)
+	}
+	if len(batch) == 0 {
+		return
+	}
+	ctx := context.Background()
+	max, retention := l.Limits()
+	_, _ = l.rdb.Pipelined(ctx, func(p redis.Pipeliner) error {
+		if l.stream {
+			for _, b := range batch {
+				p.XAdd(ctx, &redis.XAddArgs{Stream: l.key, MaxLen: max, Approx: true, Values: []interface{}{streamField, b}})
+			}
+			if retention > 0 {
+				p.XTrimMinIDApprox(ctx, l.key, fmt.Sprintf("%d-0", l.now().Add(-retention).UnixMilli()), 0)
+			}
+			return nil
+		}
+		values := make([]interface{}, len(batch))
+		for i, b := range batch {
+			values[i] = b
+		}
+		p.LPush(ctx, l.key, values...) // pushed in order, so the newest ends up at the head as before
+		p.LTrim(ctx, l.key, 0, max-1)
+		if retention > 0 {
+			p.Expire(ctx, l.key, retention)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,66 @@
+package redislog
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAsync_BatchesUntilShutdownThenWritesSynchronously(t *testing.T) {
+	rdb, m := redismock.NewClientMock()
+	l := New(rdb, "logs:app", 100, time.Hour, WithAsync(10))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	l.asyncMu.Lock()
+	l.running = true // as Run does first; set here so the entries below are sure to queue
+	l.asyncMu.Unlock()
+	l.Info("one", nil)
+	l.Info("two", nil)
+	l.Info("three", nil)
+
+	var pushed []interface{}
+	m.CustomMatch(func(_, actual []interface{}) error { pushed = actual[2:]; return nil }).
+		ExpectLPush("logs:app", "", "", "").SetVal(3)
+	m.ExpectLTrim("logs:app", 0, 99).SetVal("OK")
+	m.ExpectExpire("logs:app", time.Hour).SetVal(true)
+	go func() { l.Run(ctx); close(done) }()
+	cancel()
+	<-done
+	require.Len(t, pushed, 3, "one LPUSH for the whole batch")
+	var first Entry
+	require.NoError(t, json.Unmarshal(pushed[0].([]byte), &first))
+	assert.Equal(t, "one", first.Msg, "oldest first, so the newest ends up at the head")
+
+	// After Run returns, entries go straight to Redis again.
+	m.CustomMatch(func(_, _ []interface{}) error { return nil }).ExpectLPush("logs:app", "").SetVal(1)
+	m.ExpectLTrim("logs:app", 0, 99).SetVal("OK")
+	m.ExpectExpire("logs:app", time.Hour).SetVal(true)
+	l.Info("late", nil)
+	assert.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestAsync_FullQueueDropsAndReports(t *testing.T) {
+	rdb, m := redismock.NewClientMock()
+	l := New(rdb, "logs:app", 100, 0, WithAsync(1))
+	l.running = true
+	l.Info("kept", nil)
+	l.Info("dropped", nil)
+	assert.EqualValues(t, 1, l.dropped.Load())
+
+	var pushed []interface{}
+	m.CustomMatch(func(_, actual []interface{}) error { pushed = actual[2:]; return nil }).
+		ExpectLPush("logs:app", "", "").SetVal(2)
+	m.ExpectLTrim("logs:app", 0, 99).SetVal("OK")
+	l.writeBatch([][]byte{<-l.queue})
+	assert.NoError(t, m.ExpectationsWereMet())
+	require.Len(t, pushed, 2)
+	var report Entry
+	require.NoError(t, json.Unmarshal(pushed[1].([]byte), &report))
+	assert.Equal(t, map[string]string{"dropped": "1"}, report.Meta)
+}
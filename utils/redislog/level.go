@@ -0,0 +1,39 @@
+// Minimum level: entries below it are dropped before sampling or any Redis call. Two limits
+// apply: the runtime one (SetLevel, follows log_level) and a fixed floor (WithMinLevel).
+
+package redislog
+
+// levelRank orders levels; unknown levels rank as "info".
+func levelRank(level string) int32 {
+	switch level {
+	case "debug":
+		return 0
+	case "warn":
+		return 2
+	case "error":
+		return 3
+	}
+	return 1
+}
+
+// WithMinLevel drops entries below level (debug|info|warn|error) for the Logger's lifetime,
+// whatever SetLevel says later: e.g. "warn" keeps info chatter out of Redis while stdout still
+// has it.
+func WithMinLevel(level string) Option {
+	return func(l *Logger) { l.floor = levelRank(level) }
+}
+
+// SetLevel drops entries below level (debug|info|warn|error) from now on. Safe to call while
+// logging, e.g. when an admin changes log_level at runtime.
+func (l *Logger) SetLevel(level string) {
+	if l == nil {
+		return
+	}
+	l.minLevel.Store(levelRank(level))
+}
+
+// enabled reports whether entries at level pass both minimum levels.
+func (l *Logger) enabled(level string) bool {
+	rank := levelRank(level)
+	return rank >= l.floor && rank >= l.minLevel.Load()
+}
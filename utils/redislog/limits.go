@@ -0,0 +1,98 @@
+// Size limits that admins can change at runtime, and what the log costs in Redis right now.
+
+package redislog
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// SetLimits changes the entry cap and retention from the next write on. Safe to call while
+// logging, e.g. when an admin lowers them under Redis memory pressure; entries already over
+// the new cap go with the next write.
+func (l *Logger) SetLimits(max int64, retention time.Duration) {
+	if l == nil {
+		return
+	}
+	l.max.Store(max)
+	l.retention.Store(int64(retention))
+}
+
+// Limits returns the entry cap and retention in effect.
+func (l *Logger) Limits() (max int64, retention time.Duration) {
+	return l.max.Load(), time.Duration(l.retention.Load())
+}
+
+// Key is the list (or stream) key entries go to.
+func (l *Logger) Key() string { return l.key }
+
+// Usage is the log key's footprint in Redis.
+type Usage struct {
+	Key         string     `json:"key"`
+	Mode        string     `json:"mode"` // list|stream
+	Entries     int64      `json:"entries"`
+	MemoryBytes int64      `json:"memory_bytes"` // MEMORY USAGE (sampled by Redis for big keys)
+	MaxLen      int64      `json:"max_len"`
+	Retention   string     `json:"retention"` // "0s" = no age limit
+	Oldest      *time.Time `json:"oldest,omitempty"`
+}
+
+// Usage reports the key's length, memory and oldest entry along with the limits in effect.
+func (l *Logger) Usage(ctx context.Context) (Usage, error) {
+	if l == nil || l.rdb == nil {
+		return Usage{}, errors.New("redislog: no Redis client")
+	}
+	max, retention := l.Limits()
+	u := Usage{Key: l.key, Mode: "list", MaxLen: max, Retention: retention.String()}
+	var err error
+	if l.stream {
+		u.Mode = "stream"
+		u.Entries, err = l.rdb.XLen(ctx, l.key).Result()
+	} else {
+		u.Entries, err = l.rdb.LLen(ctx, l.key).Result()
+	}
+	if err != nil {
+		return u, err
+	}
+	if u.Entries == 0 {
+		return u, nil
+	}
+	if u.MemoryBytes, err = l.rdb.MemoryUsage(ctx, l.key).Result(); err != nil && !errors.Is(err, redis.Nil) {
+		return u, err
+	}
+	u.Oldest, err = l.oldest(ctx)
+	return u, err
+}
+
+// oldest is the time of the oldest entry, nil if it can't be told.
+func (l *Logger) oldest(ctx context.Context) (*time.Time, error) {
+	if l.stream {
+		msgs, err := l.rdb.XRangeN(ctx, l.key, "-", "+", 1).Result()
+		if err != nil || len(msgs) == 0 {
+			return nil, err
+		}
+		ms, perr := strconv.ParseInt(strings.SplitN(msgs[0].ID, "-", 2)[0], 10, 64)
+		if perr != nil {
+			return nil, nil
+		}
+		t := time.UnixMilli(ms).UTC()
+		return &t, nil
+	}
+	raw, err := l.rdb.LIndex(ctx, l.key, -1).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	t, ok := entryTime(raw)
+	if !ok {
+		return nil, nil
+	}
+	return &t, nil
+}
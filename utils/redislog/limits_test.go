@@ -0,0 +1,37 @@
+package redislog
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetLimits_NextWriteUsesThem(t *testing.T) {
+	rdb, m := redismock.NewClientMock()
+	l := New(rdb, "logs:app", 1000, time.Hour)
+	l.SetLimits(10, 0)
+	m.CustomMatch(func(expected, actual []interface{}) error { return nil }).ExpectLPush("logs:app", "").SetVal(1)
+	m.ExpectLTrim("logs:app", 0, 9).SetVal("OK") // no EXPIRE: retention off
+
+	l.Info("hello", nil)
+	assert.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestUsage_List(t *testing.T) {
+	rdb, m := redismock.NewClientMock()
+	l := New(rdb, "logs:app", 1000, 168*time.Hour)
+	m.ExpectLLen("logs:app").SetVal(2)
+	m.ExpectMemoryUsage("logs:app").SetVal(2048)
+	m.ExpectLIndex("logs:app", -1).SetVal(`{"time":"2026-05-01T08:00:00Z","msg":"oldest"}`)
+
+	u, err := l.Usage(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, Usage{Key: "logs:app", Mode: "list", Entries: 2, MemoryBytes: 2048, MaxLen: 1000, Retention: "168h0m0s", Oldest: u.Oldest}, u)
+	require.NotNil(t, u.Oldest)
+	assert.Equal(t, time.Date(2026, 5, 1, 8, 0, 0, 0, time.UTC), *u.Oldest)
+	assert.NoError(t, m.ExpectationsWereMet())
+}
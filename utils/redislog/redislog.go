@@ -1,52 +1,190 @@
+// Package redislog writes structured JSON log entries to a capped Redis list, or with
+// WithStream to a capped stream that consumer groups read (see stream.go).
+//
+// Entry schema (the "schema" field; bump SchemaVersion on any incompatible change):
+//
+//	v1: level, msg, time, meta
+//	v2: adds typed request_id, uid, route, latency_ms, error, stack; meta keeps only
+//	    keys without a typed field
 package redislog
 
 import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math/rand/v2"
+	"runtime/debug"
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 )
 
+// SchemaVersion is stamped on every entry so consumers can tell layouts apart.
+const SchemaVersion = 2
+
+// Fields are the typed, schema-stable parts of an entry; zero values are omitted.
+type Fields struct {
+	RequestID string  `json:"request_id,omitempty"`
+	UID       uint    `json:"uid,omitempty"`        // acting (authenticated) user
+	Route     string  `json:"route,omitempty"`      // route pattern, e.g. "GET /users/:id"
+	LatencyMS float64 `json:"latency_ms,omitempty"`
+	Error     string  `json:"error,omitempty"`
+	Stack     string  `json:"stack,omitempty"` // goroutine stack (see Stack)
+}
+
 // Entry is a structured log object saved into Redis as JSON.
 type Entry struct {
-	Level string            `json:"level"`
-	Msg   string            `json:"msg"`
-	Time  string            `json:"time"`
-	Meta  map[string]string `json:"meta,omitempty"`
+	Schema int    `json:"schema"`
+	Level  string `json:"level"`
+	Msg    string `json:"msg"`
+	Time   string `json:"time"`
+	Fields
+	Meta map[string]string `json:"meta,omitempty"` // free-form extras
 }
 
+// Stack returns the calling goroutine's stack trace, for Fields.Stack.
+func Stack() string { return string(debug.Stack()) }
+
 // Logger pushes logs to a Redis LIST (e.g., "logs:app") and trims to a max length.
 type Logger struct {
 	rdb       *redis.Client
-	key       string        // list key, e.g. "logs:app"
-	max       int64         // keep last N entries
-	retention time.Duration // optional expire for the list key
+	key       string        // list key, e.g. "logs:app" (stream key in stream mode)
+	max       atomic.Int64  // keep last N entries; see SetLimits
+	retention atomic.Int64  // time.Duration: optional expire for the list key; age limit for stream entries
+	minLevel  atomic.Int32  // see SetLevel; zero value = everything
+	floor     int32         // see WithMinLevel; fixed after New
+	stream    bool          // XADD instead of LPUSH; see WithStream
+
+	queue   chan []byte  // encoded entries for Run; nil = always synchronous (see async.go)
+	asyncMu sync.RWMutex // guards running against enqueues while Run shuts down
+	running bool
+	dropped atomic.Int64 // entries lost to a full queue since the last batch
+
+	sampling map[string]Sampling // per level; see sampling.go
+	rand     func() float64      // for Sampling.Rate; rand.Float64 outside tests
+
+	report      func(Entry) // see WithReporter; nil = none
+	reportLevel int32
+	mu       sync.Mutex
+	seen     map[string]*repeatState // level+msg -> counters
+	now      func() time.Time
 }
 
+// Option customizes a Logger.
+type Option func(*Logger)
+
 // New creates a Redis logger using a LIST. You’ll see this key in your Redis Desktop Manager.
-func New(rdb *redis.Client, key string, max int64, retention time.Duration) *Logger {
-	return &Logger{rdb: rdb, key: key, max: max, retention: retention}
+func New(rdb *redis.Client, key string, max int64, retention time.Duration, opts ...Option) *Logger {
+	l := &Logger{rdb: rdb, key: key, seen: map[string]*repeatState{}, now: time.Now, rand: rand.Float64}
+	l.SetLimits(max, retention)
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// newEntry builds a v2 entry. Meta keys that have a typed field ("request_id", "uid", "route",
+// "latency_ms", "err"/"error", "stack") are moved into it unless f already sets that field, so
+// existing map-style call sites produce the typed layout too.
+func newEntry(level, msg string, f Fields, meta map[string]string) Entry {
+	rest := make(map[string]string, len(meta))
+	for k, v := range meta {
+		switch {
+		case k == "request_id" && f.RequestID == "":
+			f.RequestID = v
+		case k == "route" && f.Route == "":
+			f.Route = v
+		case (k == "err" || k == "error") && f.Error == "":
+			f.Error = v
+		case k == "stack" && f.Stack == "":
+			f.Stack = v
+		case k == "uid" && f.UID == 0:
+			n, err := strconv.ParseUint(v, 10, 0)
+			if err != nil {
+				rest[k] = v // keep what we can't type
+				continue
+			}
+			f.UID = uint(n)
+		case k == "latency_ms" && f.LatencyMS == 0:
+			n, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				rest[k] = v
+				continue
+			}
+			f.LatencyMS = n
+		default:
+			rest[k] = v
+		}
+	}
+	if len(rest) == 0 {
+		rest = nil
+	}
+	return Entry{
+		Schema: SchemaVersion,
+		Level:  level,
+		Msg:    msg,
+		Time:   time.Now().UTC().Format(time.RFC3339),
+		Fields: f,
+		Meta:   rest,
+	}
 }
 
 // log pushes a log entry as JSON -> LPUSH; then LTRIM; then EXPIRE.
 func (l *Logger) log(level, msg string, meta map[string]string) {
+	l.Log(level, msg, Fields{}, meta)
+}
+
+// Log writes an entry with typed fields plus optional free-form meta.
+func (l *Logger) Log(level, msg string, f Fields, meta map[string]string) {
+	l.emit(level, msg, f, meta, true)
+}
+
+// Forward is Log for entries copied from another log (the slog sink in package logger): they
+// are stored the same way but not passed to the WithReporter hook.
+func (l *Logger) Forward(level, msg string, meta map[string]string) {
+	l.emit(level, msg, Fields{}, meta, false)
+}
+
+// emit builds the entry, hands it to the reporter (regardless of level filters and sampling,
+// which only protect the Redis list) and writes it.
+func (l *Logger) emit(level, msg string, f Fields, meta map[string]string, report bool) {
 	if l == nil || l.rdb == nil {
 		return // no-op if logger not initialized
 	}
-	en := Entry{
-		Level: level,
-		Msg:   msg,
-		Time:  time.Now().UTC().Format(time.RFC3339),
-		Meta:  meta,
+	report = report && l.reports(level)
+	if !report && !l.enabled(level) {
+		return
 	}
+	en := newEntry(level, msg, f, meta)
+	if report {
+		l.report(en)
+	}
+	if !l.enabled(level) || !l.admit(&en) {
+		return // below the minimum level, sampled out or folded into a "repeated N×" count
+	}
+	l.write(en)
+}
+
+// write pushes one entry: LPUSH; then LTRIM; then EXPIRE (or XADD in stream mode). With
+// WithAsync and Run going, it only queues the entry.
+func (l *Logger) write(en Entry) {
 	b, _ := json.Marshal(en)
+	if l.queue != nil && l.enqueue(b) {
+		return
+	}
 	ctx := context.Background()
+	if l.stream {
+		l.writeStream(ctx, b)
+		return
+	}
+	max, retention := l.Limits()
 	_ = l.rdb.LPush(ctx, l.key, b).Err()
-	_ = l.rdb.LTrim(ctx, l.key, 0, l.max-1).Err()
-	if l.retention > 0 {
-		_ = l.rdb.Expire(ctx, l.key, l.retention).Err()
+	_ = l.rdb.LTrim(ctx, l.key, 0, max-1).Err()
+	if retention > 0 {
+		_ = l.rdb.Expire(ctx, l.key, retention).Err()
 	}
 }
 
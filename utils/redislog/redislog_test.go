@@ -0,0 +1,141 @@
+package redislog
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewEntry_PromotesKnownMetaKeys(t *testing.T) {
+	e := newEntry("error", "GetByID db error", Fields{Route: "GET /users/:id"}, map[string]string{
+		"err": "connection refused", "uid": "7", "latency_ms": "12.5", "route": "ignored", "user_id": "3",
+	})
+	assert.Equal(t, SchemaVersion, e.Schema)
+	assert.Equal(t, Fields{UID: 7, Route: "GET /users/:id", LatencyMS: 12.5, Error: "connection refused"}, e.Fields)
+	// untyped keys stay in meta; so does a key whose field the caller already set explicitly
+	assert.Equal(t, map[string]string{"user_id": "3", "route": "ignored"}, e.Meta)
+
+	b, err := json.Marshal(e)
+	assert.NoError(t, err)
+	var out map[string]interface{}
+	assert.NoError(t, json.Unmarshal(b, &out))
+	assert.Equal(t, 2.0, out["schema"])
+	assert.Equal(t, 7.0, out["uid"], "typed fields sit at the top level")
+	assert.NotContains(t, out, "request_id", "zero fields are omitted")
+
+	// a non-numeric uid isn't dropped, just left untyped
+	e = newEntry("info", "x", Fields{}, map[string]string{"uid": "abc"})
+	assert.Zero(t, e.UID)
+	assert.Equal(t, map[string]string{"uid": "abc"}, e.Meta)
+}
+
+func TestAdmit_SamplingAndDedup(t *testing.T) {
+	clock := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := New(nil, "logs:app", 100, 0,
+		WithSampling("warn", Sampling{Every: 3}),
+		WithSampling("info", Sampling{DedupWindow: 10 * time.Second}))
+	l.now = func() time.Time { return clock }
+
+	// 1-in-3: the 1st, 4th, 7th... identical warn is kept
+	var kept []int
+	for i := 1; i <= 7; i++ {
+		en := newEntry("warn", "cache MISS", Fields{}, map[string]string{"key": fmt.Sprintf("user:%d", i)})
+		if l.admit(&en) {
+			kept = append(kept, i)
+			assert.Equal(t, "1/3", en.Meta["sampled"])
+		}
+	}
+	assert.Equal(t, []int{1, 4, 7}, kept)
+
+	// dedup: the first is written, the burst is counted, the next one after the window carries the count
+	en := newEntry("info", "cache HIT", Fields{}, nil)
+	assert.True(t, l.admit(&en))
+	for i := 0; i < 500; i++ {
+		en = newEntry("info", "cache HIT", Fields{}, nil)
+		assert.False(t, l.admit(&en))
+	}
+	clock = clock.Add(11 * time.Second)
+	en = newEntry("info", "cache HIT", Fields{}, nil)
+	assert.True(t, l.admit(&en))
+	assert.Equal(t, "cache HIT (repeated 500×)", en.Msg)
+	assert.Equal(t, "500", en.Meta["repeated"])
+
+	// errors have no rule: never touched
+	for i := 0; i < 3; i++ {
+		en = newEntry("error", "cache GET error", Fields{}, nil)
+		assert.True(t, l.admit(&en))
+	}
+
+	// a trailing burst is reported by Flush
+	en = newEntry("info", "cache HIT", Fields{}, nil)
+	assert.False(t, l.admit(&en))
+	l.mu.Lock()
+	pending := l.drainLocked()
+	l.mu.Unlock()
+	if assert.Len(t, pending, 1) {
+		assert.Equal(t, "cache HIT (repeated 1×)", pending[0].Msg)
+	}
+}
+
+func TestSetLevel(t *testing.T) {
+	l := New(nil, "logs:app", 100, 0)
+	assert.True(t, l.enabled("info"), "everything passes by default")
+	l.SetLevel("warn")
+	assert.False(t, l.enabled("info"))
+	assert.True(t, l.enabled("warn"))
+	assert.True(t, l.enabled("error"))
+	l.SetLevel("debug")
+	assert.True(t, l.enabled("info"))
+	var nilLogger *Logger
+	nilLogger.SetLevel("error") // no panic
+}
+
+func TestAdmit_Rate(t *testing.T) {
+	l := New(nil, "logs:app", 100, 0, WithSampling("info", Sampling{Rate: 0.25}))
+	draws := []float64{0.1, 0.3, 0.9, 0.2}
+	l.rand = func() float64 { d := draws[0]; draws = draws[1:]; return d }
+
+	// a draw below the rate keeps the entry, whatever its message
+	var kept []int
+	for i := 0; i < 4; i++ {
+		en := newEntry("info", fmt.Sprintf("request %d", i), Fields{}, nil)
+		if l.admit(&en) {
+			kept = append(kept, i)
+			assert.Equal(t, "0.25", en.Meta["sampled_rate"])
+		}
+	}
+	assert.Equal(t, []int{0, 3}, kept)
+
+	// other levels draw nothing
+	en := newEntry("error", "db down", Fields{}, nil)
+	assert.True(t, l.admit(&en))
+	assert.Nil(t, en.Meta)
+}
+
+func TestWithMinLevel(t *testing.T) {
+	l := New(nil, "logs:app", 100, 0, WithMinLevel("warn"))
+	assert.False(t, l.enabled("info"))
+	assert.True(t, l.enabled("warn"))
+	l.SetLevel("debug") // the runtime level can't lower the floor...
+	assert.False(t, l.enabled("info"))
+	l.SetLevel("error") // ...but can raise it
+	assert.False(t, l.enabled("warn"))
+	assert.True(t, l.enabled("error"))
+}
+
+func TestWithReporter(t *testing.T) {
+	var reported []string
+	rdb, _ := redismock.NewClientMock() // writes fail; only the hook matters here
+	l := New(rdb, "logs:app", 100, 0, WithMinLevel("error"),
+		WithReporter("warn", func(en Entry) { reported = append(reported, en.Level+" "+en.Msg) }))
+
+	l.Info("cache HIT", nil)
+	l.Warn("cache MISS", nil) // below the Redis floor, still reported
+	l.Error("db down", nil)
+	l.Forward("error", "copied from slog", nil) // its source reports for itself
+	assert.Equal(t, []string{"warn cache MISS", "error db down"}, reported)
+}
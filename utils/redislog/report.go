@@ -0,0 +1,17 @@
+// Error reporting hook: serious entries are also handed to an error tracker (see errreport).
+
+package redislog
+
+// WithReporter passes every entry at or above level (warn|error) to fn, synchronously and
+// before the minimum level and sampling are applied, so a Redis-side filter never hides an
+// error from the tracker. Entries arriving through Forward are not passed on. fn must not block.
+func WithReporter(level string, fn func(Entry)) Option {
+	return func(l *Logger) {
+		l.report, l.reportLevel = fn, levelRank(level)
+	}
+}
+
+// reports tells whether entries at level go to the reporter.
+func (l *Logger) reports(level string) bool {
+	return l.report != nil && levelRank(level) >= l.reportLevel
+}
@@ -0,0 +1,157 @@
+package redislog
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Sampling thins out entries of one level so chatter (cache HIT/MISS, ...) doesn't push real
+// errors out of the capped list. Rate keeps a random share of all entries; Every and DedupWindow
+// work on identical ones: same level and msg, meta ignored, so "cache MISS" for user:1 and user:2
+// are the same message. Rate is applied first.
+type Sampling struct {
+	// Rate keeps this share of entries (0.1 = about 1 in 10), picked at random and tagged meta
+	// sampled_rate="0.1". 0 or 1 keeps all.
+	Rate float64
+	// Every keeps 1 in Every identical entries (the 1st, Every+1th, ...), tagged meta
+	// sampled="1/Every". 0 or 1 keeps all.
+	Every int
+	// DedupWindow: after an entry is written, identical ones within the window are only counted.
+	// The next one written (after the window, or by Flush) says how many were folded into it:
+	// msg gets " (repeated N×)" and meta repeated=N. 0 disables.
+	DedupWindow time.Duration
+}
+
+// maxTrackedMessages bounds the counters map (messages built with Sprintf can be unbounded).
+const maxTrackedMessages = 10000
+
+// repeatState counts one level+msg.
+type repeatState struct {
+	level, msg string
+	total      int64     // seen so far (for Every)
+	last       time.Time // last written (for DedupWindow)
+	suppressed int       // folded since last
+}
+
+// WithSampling applies s to every entry of level ("info", "warn", "error").
+func WithSampling(level string, s Sampling) Option {
+	return func(l *Logger) {
+		if l.sampling == nil {
+			l.sampling = map[string]Sampling{}
+		}
+		l.sampling[level] = s
+	}
+}
+
+// admit applies the level's sampling rule; false means drop en. May annotate en.
+func (l *Logger) admit(en *Entry) bool {
+	rule, ok := l.sampling[en.Level]
+	if !ok {
+		return true
+	}
+	if rule.Rate > 0 && rule.Rate < 1 {
+		if l.rand() >= rule.Rate {
+			return false
+		}
+		setMeta(en, "sampled_rate", strconv.FormatFloat(rule.Rate, 'g', -1, 64))
+	}
+	if rule.Every <= 1 && rule.DedupWindow <= 0 {
+		return true
+	}
+	var flushed []Entry
+	defer func() { // written outside the lock
+		for _, e := range flushed {
+			l.write(e)
+		}
+	}()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	k := en.Level + "\x00" + en.Msg
+	st, ok := l.seen[k]
+	if !ok {
+		if len(l.seen) >= maxTrackedMessages {
+			flushed = l.drainLocked()
+		}
+		st = &repeatState{level: en.Level, msg: en.Msg}
+		l.seen[k] = st
+	}
+	st.total++
+	if rule.Every > 1 {
+		if (st.total-1)%int64(rule.Every) != 0 {
+			return false
+		}
+		setMeta(en, "sampled", "1/"+strconv.Itoa(rule.Every))
+	}
+	if rule.DedupWindow > 0 {
+		now := l.now()
+		if !st.last.IsZero() && now.Sub(st.last) < rule.DedupWindow {
+			st.suppressed++
+			return false
+		}
+		if st.suppressed > 0 {
+			markRepeated(en, st.suppressed)
+			st.suppressed = 0
+		}
+		st.last = now
+	}
+	return true
+}
+
+// Flush writes a "repeated N×" entry for every message with folded repeats and resets the
+// counters. Call it on shutdown, or periodically via FlushEvery, so a burst that ends isn't
+// only reported at its next occurrence.
+func (l *Logger) Flush() {
+	if l == nil || l.rdb == nil {
+		return
+	}
+	l.mu.Lock()
+	pending := l.drainLocked()
+	l.mu.Unlock()
+	for _, en := range pending {
+		l.write(en)
+	}
+}
+
+// FlushEvery calls Flush every interval until ctx is done, then once more.
+func (l *Logger) FlushEvery(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			l.Flush()
+			return
+		case <-t.C:
+			l.Flush()
+		}
+	}
+}
+
+// drainLocked builds the summaries of pending repeats and clears the counters (l.mu held).
+func (l *Logger) drainLocked() []Entry {
+	var out []Entry
+	for _, st := range l.seen {
+		if st.suppressed > 0 {
+			en := newEntry(st.level, st.msg, Fields{}, nil)
+			markRepeated(&en, st.suppressed)
+			out = append(out, en)
+		}
+	}
+	l.seen = map[string]*repeatState{}
+	return out
+}
+
+func markRepeated(en *Entry, n int) {
+	en.Msg = fmt.Sprintf("%s (repeated %d×)", en.Msg, n)
+	setMeta(en, "repeated", strconv.Itoa(n))
+}
+
+func setMeta(en *Entry, k, v string) {
+	if en.Meta == nil {
+		en.Meta = map[string]string{}
+	}
+	en.Meta[k] = v
+}
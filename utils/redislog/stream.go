@@ -0,0 +1,140 @@
+// Stream mode: entries go to a capped Redis stream that consumer groups read reliably.
+
+package redislog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// streamField is the stream entry field holding the JSON-encoded Entry.
+const streamField = "entry"
+
+// WithStream writes entries with XADD to a stream at the logger's key instead of LPUSH to a
+// list. The stream is capped at max entries (approximately, so trimming stays cheap) and
+// entries older than retention are trimmed; the key never expires, so consumer groups and
+// their positions survive quiet periods. A key can't change type in place: give stream mode
+// its own key rather than the old list's.
+func WithStream() Option {
+	return func(l *Logger) { l.stream = true }
+}
+
+// writeStream appends one entry: XADD MAXLEN ~max; then XTRIM MINID ~(now-retention).
+func (l *Logger) writeStream(ctx context.Context, b []byte) {
+	max, retention := l.Limits()
+	_ = l.rdb.XAdd(ctx, &redis.XAddArgs{Stream: l.key, MaxLen: max, Approx: true, Values: []interface{}{streamField, b}}).Err()
+	if retention > 0 {
+		minID := fmt.Sprintf("%d-0", l.now().Add(-retention).UnixMilli())
+		_ = l.rdb.XTrimMinIDApprox(ctx, l.key, minID, 0).Err()
+	}
+}
+
+// CreateGroups makes sure each consumer group exists on the stream (creating the stream if
+// needed). New groups start with entries written from now on; existing ones keep their place.
+// A list-mode logger has no groups: it does nothing (MKSTREAM would claim the list's key).
+func (l *Logger) CreateGroups(ctx context.Context, groups ...string) error {
+	if l == nil || l.rdb == nil || !l.stream {
+		return nil
+	}
+	for _, g := range groups {
+		if err := createGroup(ctx, l.rdb, l.key, g); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func createGroup(ctx context.Context, rdb *redis.Client, key, group string) error {
+	err := rdb.XGroupCreateMkStream(ctx, key, group, "$").Err()
+	if err != nil && strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return nil // already there
+	}
+	return err
+}
+
+// Message is one entry read from the stream; Ack it by ID once processed.
+type Message struct {
+	ID    string
+	Entry Entry
+}
+
+// Consumer reads a log stream as one member of a consumer group: every entry goes to exactly
+// one consumer in the group, and stays pending until acked, so a crashed consumer's entries
+// can be reclaimed by another. Separate groups (a log shipper, an alerter) each see every entry.
+type Consumer struct {
+	rdb                  *redis.Client
+	key, group, consumer string
+}
+
+// NewConsumer returns a consumer named consumer (e.g. a hostname) in group on the stream at key.
+func NewConsumer(rdb *redis.Client, key, group, consumer string) *Consumer {
+	return &Consumer{rdb: rdb, key: key, group: group, consumer: consumer}
+}
+
+// Ensure creates the group if it doesn't exist yet.
+func (c *Consumer) Ensure(ctx context.Context) error {
+	return createGroup(ctx, c.rdb, c.key, c.group)
+}
+
+// Read returns up to count entries no one in the group has received yet, waiting up to block
+// for the first one (0 = don't wait). An empty result just means nothing new.
+func (c *Consumer) Read(ctx context.Context, count int64, block time.Duration) ([]Message, error) {
+	if block <= 0 {
+		block = -1 // go-redis: a negative Block omits BLOCK (0 would wait forever)
+	}
+	res, err := c.rdb.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group: c.group, Consumer: c.consumer, Streams: []string{c.key, ">"}, Count: count, Block: block,
+	}).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil // timed out waiting
+	}
+	if err != nil {
+		return nil, err
+	}
+	var msgs []Message
+	for _, s := range res {
+		msgs = append(msgs, decodeMessages(s.Messages)...)
+	}
+	return msgs, nil
+}
+
+// Reclaim takes over up to count entries another consumer received but hasn't acked for at
+// least minIdle (it probably died), so they are processed after all.
+func (c *Consumer) Reclaim(ctx context.Context, minIdle time.Duration, count int64) ([]Message, error) {
+	res, _, err := c.rdb.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+		Stream: c.key, Group: c.group, Consumer: c.consumer, MinIdle: minIdle, Start: "0-0", Count: count,
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+	return decodeMessages(res), nil
+}
+
+// Ack marks entries as processed by the group.
+func (c *Consumer) Ack(ctx context.Context, ids ...string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return c.rdb.XAck(ctx, c.key, c.group, ids...).Err()
+}
+
+// decodeMessages parses entries; one that doesn't decode keeps its ID (so it can be acked) and
+// carries the raw value as Msg.
+func decodeMessages(xs []redis.XMessage) []Message {
+	out := make([]Message, 0, len(xs))
+	for _, x := range xs {
+		m := Message{ID: x.ID}
+		raw, _ := x.Values[streamField].(string)
+		if err := json.Unmarshal([]byte(raw), &m.Entry); err != nil {
+			m.Entry = Entry{Msg: raw}
+		}
+		out = append(out, m)
+	}
+	return out
+}
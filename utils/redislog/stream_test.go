@@ -0,0 +1,70 @@
+package redislog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamMode_XAddAndTrim(t *testing.T) {
+	rdb, m := redismock.NewClientMock()
+	l := New(rdb, "logs:app:stream", 1000, time.Hour, WithStream())
+	clock := time.UnixMilli(1_700_000_000_000)
+	l.now = func() time.Time { return clock }
+
+	var added []byte
+	m.CustomMatch(func(expected, actual []interface{}) error {
+		added, _ = actual[len(actual)-1].([]byte)
+		return nil
+	}).ExpectXAdd(&redis.XAddArgs{Stream: "logs:app:stream", MaxLen: 1000, Approx: true, Values: []interface{}{"entry", ""}}).SetVal("1-0")
+	m.ExpectXTrimMinIDApprox("logs:app:stream", "1699996400000-0", 0).SetVal(0)
+	l.Warn("disk almost full", map[string]string{"err": "97%"})
+	assert.NoError(t, m.ExpectationsWereMet())
+
+	var en Entry
+	require.NoError(t, json.Unmarshal(added, &en))
+	assert.Equal(t, "warn", en.Level)
+	assert.Equal(t, "97%", en.Error)
+}
+
+func TestCreateGroups_ExistingGroupIsFine(t *testing.T) {
+	rdb, m := redismock.NewClientMock()
+	l := New(rdb, "logs:app:stream", 1000, 0, WithStream())
+	m.ExpectXGroupCreateMkStream("logs:app:stream", "shipper", "$").SetErr(errors.New("BUSYGROUP Consumer Group name already exists"))
+	m.ExpectXGroupCreateMkStream("logs:app:stream", "alerts", "$").SetVal("OK")
+	assert.NoError(t, l.CreateGroups(context.Background(), "shipper", "alerts"))
+	assert.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestConsumer_ReadAndAck(t *testing.T) {
+	rdb, m := redismock.NewClientMock()
+	c := NewConsumer(rdb, "logs:app:stream", "shipper", "host-1")
+	ctx := context.Background()
+
+	m.ExpectXReadGroup(&redis.XReadGroupArgs{Group: "shipper", Consumer: "host-1", Streams: []string{"logs:app:stream", ">"}, Count: 10, Block: -1}).
+		SetVal([]redis.XStream{{Stream: "logs:app:stream", Messages: []redis.XMessage{
+			{ID: "1-0", Values: map[string]interface{}{"entry": `{"schema":2,"level":"error","msg":"boom"}`}},
+			{ID: "2-0", Values: map[string]interface{}{"entry": "not json"}},
+		}}})
+	msgs, err := c.Read(ctx, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, msgs, 2)
+	assert.Equal(t, "boom", msgs[0].Entry.Msg)
+	assert.Equal(t, Message{ID: "2-0", Entry: Entry{Msg: "not json"}}, msgs[1])
+
+	m.ExpectXReadGroup(&redis.XReadGroupArgs{Group: "shipper", Consumer: "host-1", Streams: []string{"logs:app:stream", ">"}, Count: 10, Block: time.Second}).RedisNil()
+	msgs, err = c.Read(ctx, 10, time.Second)
+	assert.NoError(t, err)
+	assert.Empty(t, msgs, "nothing new within the block time")
+
+	m.ExpectXAck("logs:app:stream", "shipper", "1-0", "2-0").SetVal(2)
+	assert.NoError(t, c.Ack(ctx, "1-0", "2-0"))
+	assert.NoError(t, m.ExpectationsWereMet())
+}
@@ -0,0 +1,152 @@
+// Tailing: follow the log as it is written (the admin live log viewer).
+
+package redislog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// TailEntry is one entry seen by Tail. ID is the stream entry ID in stream mode ("" in list mode).
+type TailEntry struct {
+	ID    string
+	Entry Entry
+}
+
+// Tail calls each for the newest backlog entries (oldest first), then for every entry written
+// after that by any replica, until ctx is done or each returns an error (which Tail returns).
+// poll is how long one Redis read waits: stream mode blocks on XREAD; list mode re-reads the
+// head of the list that often. Level filtering is the caller's.
+//
+// List mode can't know how many entries arrived between two reads once more than the list's
+// cap was pushed; it then resumes from the entries still in the list.
+func (l *Logger) Tail(ctx context.Context, backlog int64, poll time.Duration, each func(TailEntry) error) error {
+	if l.stream {
+		return l.tailStream(ctx, backlog, poll, each)
+	}
+	return l.tailList(ctx, backlog, poll, each)
+}
+
+// tailStream: XREVRANGE for the backlog, then XREAD BLOCK from the last ID seen.
+func (l *Logger) tailStream(ctx context.Context, backlog int64, poll time.Duration, each func(TailEntry) error) error {
+	last := "$" // only entries added from now on
+	if backlog > 0 {
+		xs, err := l.rdb.XRevRangeN(ctx, l.key, "+", "-", backlog).Result()
+		if err != nil {
+			return err
+		}
+		for i := len(xs) - 1; i >= 0; i-- {
+			if err := each(streamTailEntry(xs[i])); err != nil {
+				return err
+			}
+			last = xs[i].ID
+		}
+	}
+	for ctx.Err() == nil {
+		res, err := l.rdb.XRead(ctx, &redis.XReadArgs{Streams: []string{l.key, last}, Block: poll, Count: 100}).Result()
+		if errors.Is(err, redis.Nil) {
+			continue // nothing new within poll
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			return err
+		}
+		for _, s := range res {
+			for _, x := range s.Messages {
+				if err := each(streamTailEntry(x)); err != nil {
+					return err
+				}
+				last = x.ID
+			}
+		}
+	}
+	return nil
+}
+
+// tailList: entries are LPUSHed, so new ones are those in front of the newest one seen last time.
+func (l *Logger) tailList(ctx context.Context, backlog int64, poll time.Duration, each func(TailEntry) error) error {
+	window, _ := l.Limits() // the whole list: anything pushed since the last read is in it
+	if window <= 0 || window > 1000 {
+		window = 1000
+	}
+	head, err := l.rdb.LRange(ctx, l.key, 0, window-1).Result()
+	if err != nil {
+		return err
+	}
+	n := int(backlog)
+	if n > len(head) {
+		n = len(head)
+	}
+	for i := n - 1; i >= 0; i-- {
+		if err := each(listTailEntry(head[i])); err != nil {
+			return err
+		}
+	}
+	newest := ""
+	if len(head) > 0 {
+		newest = head[0]
+	}
+
+	t := time.NewTicker(poll)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-t.C:
+		}
+		head, err := l.rdb.LRange(ctx, l.key, 0, window-1).Result()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		fresh := len(head) // newest not found (trimmed away, or first entries): all are new
+		for i, raw := range head {
+			if raw == newest {
+				fresh = i
+				break
+			}
+		}
+		for i := fresh - 1; i >= 0; i-- {
+			if err := each(listTailEntry(head[i])); err != nil {
+				return err
+			}
+		}
+		if len(head) > 0 {
+			newest = head[0]
+		}
+	}
+}
+
+func streamTailEntry(x redis.XMessage) TailEntry {
+	raw, _ := x.Values[streamField].(string)
+	return TailEntry{ID: x.ID, Entry: decodeEntry(raw)}
+}
+
+func listTailEntry(raw string) TailEntry {
+	return TailEntry{Entry: decodeEntry(raw)}
+}
+
+// decodeEntry parses one stored entry; one that doesn't decode is passed on as its Msg.
+func decodeEntry(raw string) Entry {
+	var en Entry
+	if err := json.Unmarshal([]byte(raw), &en); err != nil {
+		return Entry{Msg: strings.TrimSpace(raw)}
+	}
+	return en
+}
+
+// LevelAtLeast reports whether level ranks at or above min (debug < info < warn < error), for
+// filtering tailed entries.
+func LevelAtLeast(level, min string) bool {
+	return levelRank(level) >= levelRank(min)
+}
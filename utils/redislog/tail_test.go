@@ -0,0 +1,61 @@
+package redislog
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+var errStop = errors.New("stop")
+
+func TestTail_StreamBacklogThenNew(t *testing.T) {
+	rdb, m := redismock.NewClientMock()
+	l := New(rdb, "logs:app:stream", 1000, 0, WithStream())
+	m.ExpectXRevRangeN("logs:app:stream", "+", "-", 2).SetVal([]redis.XMessage{
+		{ID: "2-0", Values: map[string]interface{}{"entry": `{"level":"warn","msg":"second"}`}},
+		{ID: "1-0", Values: map[string]interface{}{"entry": `{"level":"info","msg":"first"}`}},
+	})
+	m.ExpectXRead(&redis.XReadArgs{Streams: []string{"logs:app:stream", "2-0"}, Block: time.Second, Count: 100}).SetVal([]redis.XStream{
+		{Stream: "logs:app:stream", Messages: []redis.XMessage{{ID: "3-0", Values: map[string]interface{}{"entry": `{"level":"error","msg":"third"}`}}}},
+	})
+
+	var got []string
+	err := l.Tail(context.Background(), 2, time.Second, func(e TailEntry) error {
+		got = append(got, e.ID+" "+e.Entry.Msg)
+		if len(got) == 3 {
+			return errStop
+		}
+		return nil
+	})
+	assert.ErrorIs(t, err, errStop)
+	assert.Equal(t, []string{"1-0 first", "2-0 second", "3-0 third"}, got)
+	assert.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestTail_ListPicksUpPushedEntries(t *testing.T) {
+	rdb, m := redismock.NewClientMock()
+	l := New(rdb, "logs:app", 100, 0)
+	m.ExpectLRange("logs:app", 0, 99).SetVal([]string{`{"msg":"old"}`})
+	m.ExpectLRange("logs:app", 0, 99).SetVal([]string{`{"msg":"new2"}`, `{"msg":"new1"}`, `{"msg":"old"}`})
+
+	var got []string
+	err := l.Tail(context.Background(), 0, time.Millisecond, func(e TailEntry) error {
+		got = append(got, e.Entry.Msg)
+		if len(got) == 2 {
+			return errStop
+		}
+		return nil
+	})
+	assert.ErrorIs(t, err, errStop)
+	assert.Equal(t, []string{"new1", "new2"}, got)
+}
+
+func TestLevelAtLeast(t *testing.T) {
+	assert.True(t, LevelAtLeast("error", "warn"))
+	assert.False(t, LevelAtLeast("info", "warn"))
+}
@@ -0,0 +1,105 @@
+// Age-based trimming, for the scheduler's trim_log job, and archival of what is trimmed.
+
+package redislog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// trimBatch is how many of the oldest entries TrimOlderThan and Archive read per round trip.
+const trimBatch = 500
+
+// TrimOlderThan removes entries older than maxAge and returns how many went. Writes only cap a
+// list by count and expire the whole key, so a busy list otherwise keeps old entries for as long
+// as it stays under the cap. In list mode the oldest entries sit at the tail: they are read in
+// batches and cut with LTRIM from the end, which new entries (pushed at the head) don't shift.
+// Entries that don't decode count as old. In stream mode it is an exact XTRIM MINID.
+func (l *Logger) TrimOlderThan(ctx context.Context, maxAge time.Duration) (int64, error) {
+	return l.removeOld(ctx, maxAge, nil)
+}
+
+// Archive is TrimOlderThan that first writes each removed entry to w as one JSON line, oldest
+// first. Nothing is removed that wasn't written: on a write error the entries stay in Redis.
+func (l *Logger) Archive(ctx context.Context, w io.Writer, maxAge time.Duration) (int64, error) {
+	return l.removeOld(ctx, maxAge, w)
+}
+
+// removeOld removes entries written before now-maxAge, copying them to w first unless it is nil.
+func (l *Logger) removeOld(ctx context.Context, maxAge time.Duration, w io.Writer) (int64, error) {
+	cutoff := l.now().Add(-maxAge)
+	if l.stream {
+		return l.removeOldStream(ctx, cutoff, w)
+	}
+	var removed int64
+	for ctx.Err() == nil {
+		batch, err := l.rdb.LRange(ctx, l.key, -trimBatch, -1).Result()
+		if err != nil {
+			return removed, err
+		}
+		n := int64(0)
+		for i := len(batch) - 1; i >= 0 && entryBefore(batch[i], cutoff); i-- {
+			if w != nil {
+				if _, err := io.WriteString(w, batch[i]+"\n"); err != nil {
+					return removed, err
+				}
+			}
+			n++
+		}
+		if n == 0 {
+			break
+		}
+		if err := l.rdb.LTrim(ctx, l.key, 0, -n-1).Err(); err != nil {
+			return removed, err
+		}
+		removed += n
+		if n < int64(len(batch)) { // reached entries to keep
+			break
+		}
+	}
+	return removed, ctx.Err()
+}
+
+// removeOldStream copies the entries below the cutoff's ID to w (if any), then trims them.
+func (l *Logger) removeOldStream(ctx context.Context, cutoff time.Time, w io.Writer) (int64, error) {
+	minID := fmt.Sprintf("%d-0", cutoff.UnixMilli())
+	if w != nil {
+		start, end := "-", fmt.Sprintf("%d", cutoff.UnixMilli()-1) // up to the last ID of the previous millisecond
+		for {
+			msgs, err := l.rdb.XRangeN(ctx, l.key, start, end, trimBatch).Result()
+			if err != nil {
+				return 0, err
+			}
+			for _, x := range msgs {
+				raw, _ := x.Values[streamField].(string)
+				if _, err := io.WriteString(w, raw+"\n"); err != nil {
+					return 0, err
+				}
+			}
+			if len(msgs) < trimBatch {
+				break
+			}
+			start = "(" + msgs[len(msgs)-1].ID
+		}
+	}
+	return l.rdb.XTrimMinID(ctx, l.key, minID).Result()
+}
+
+// entryBefore reports whether a stored entry was written before cutoff.
+func entryBefore(raw string, cutoff time.Time) bool {
+	t, ok := entryTime(raw)
+	return !ok || t.Before(cutoff)
+}
+
+// entryTime is when a stored entry was written; false if it doesn't decode.
+func entryTime(raw string) (time.Time, bool) {
+	var e Entry
+	if json.Unmarshal([]byte(raw), &e) != nil {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339, e.Time)
+	return t, err == nil
+}
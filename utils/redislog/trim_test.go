@@ -0,0 +1,110 @@
+package redislog
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrimOlderThan_ListCutsOldTail(t *testing.T) {
+	rdb, m := redismock.NewClientMock()
+	l := New(rdb, "logs:app", 1000, 0)
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	l.now = func() time.Time { return now }
+	m.ExpectLRange("logs:app", -trimBatch, -1).SetVal([]string{ // newest first, like LPUSH leaves them
+		`{"time":"2026-05-01T11:00:00Z","msg":"recent"}`,
+		`{"time":"2026-04-20T09:00:00Z","msg":"old"}`,
+		`not json`,
+	})
+	m.ExpectLTrim("logs:app", 0, -3).SetVal("OK")
+
+	n, err := l.TrimOlderThan(context.Background(), 7*24*time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+	assert.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestTrimOlderThan_ListNothingOld(t *testing.T) {
+	rdb, m := redismock.NewClientMock()
+	l := New(rdb, "logs:app", 1000, 0)
+	m.ExpectLRange("logs:app", -trimBatch, -1).SetVal([]string{`{"time":"` + time.Now().UTC().Format(time.RFC3339) + `"}`})
+
+	n, err := l.TrimOlderThan(context.Background(), time.Hour)
+	assert.NoError(t, err)
+	assert.Zero(t, n)
+	assert.NoError(t, m.ExpectationsWereMet(), "no LTRIM")
+}
+
+func TestTrimOlderThan_StreamByMinID(t *testing.T) {
+	rdb, m := redismock.NewClientMock()
+	l := New(rdb, "logs:app:stream", 1000, 0, WithStream())
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	l.now = func() time.Time { return now }
+	m.ExpectXTrimMinID("logs:app:stream", "1777633200000-0").SetVal(4)
+
+	n, err := l.TrimOlderThan(context.Background(), time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), n)
+	assert.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestArchive_ListWritesOldestFirstThenTrims(t *testing.T) {
+	rdb, m := redismock.NewClientMock()
+	l := New(rdb, "logs:app", 1000, 0)
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	l.now = func() time.Time { return now }
+	m.ExpectLRange("logs:app", -trimBatch, -1).SetVal([]string{
+		`{"time":"2026-05-01T11:59:00Z","msg":"keep"}`,
+		`{"time":"2026-05-01T09:00:00Z","msg":"b"}`,
+		`{"time":"2026-05-01T08:00:00Z","msg":"a"}`,
+	})
+	m.ExpectLTrim("logs:app", 0, -3).SetVal("OK")
+
+	var buf strings.Builder
+	n, err := l.Archive(context.Background(), &buf, time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+	assert.Equal(t, `{"time":"2026-05-01T08:00:00Z","msg":"a"}`+"\n"+`{"time":"2026-05-01T09:00:00Z","msg":"b"}`+"\n", buf.String())
+	assert.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestArchive_WriteErrorKeepsEntries(t *testing.T) {
+	rdb, m := redismock.NewClientMock()
+	l := New(rdb, "logs:app", 1000, 0)
+	m.ExpectLRange("logs:app", -trimBatch, -1).SetVal([]string{`{"time":"2020-01-01T00:00:00Z"}`})
+
+	n, err := l.Archive(context.Background(), failingWriter{}, time.Hour)
+	assert.ErrorIs(t, err, errDiskFull)
+	assert.Zero(t, n)
+	assert.NoError(t, m.ExpectationsWereMet(), "no LTRIM")
+}
+
+func TestArchive_StreamCopiesBelowCutoff(t *testing.T) {
+	rdb, m := redismock.NewClientMock()
+	l := New(rdb, "logs:app:stream", 1000, 0, WithStream())
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	l.now = func() time.Time { return now }
+	m.ExpectXRangeN("logs:app:stream", "-", "1777633199999", trimBatch).SetVal([]redis.XMessage{
+		{ID: "1-0", Values: map[string]interface{}{"entry": `{"msg":"a"}`}},
+	})
+	m.ExpectXTrimMinID("logs:app:stream", "1777633200000-0").SetVal(1)
+
+	var buf strings.Builder
+	n, err := l.Archive(context.Background(), &buf, time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+	assert.Equal(t, `{"msg":"a"}`+"\n", buf.String())
+	assert.NoError(t, m.ExpectationsWereMet())
+}
+
+var errDiskFull = errors.New("disk full")
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errDiskFull }
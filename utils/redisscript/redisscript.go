@@ -0,0 +1,111 @@
+// Package redisscript manages the Lua scripts the app runs in Redis (rate limiter buckets,
+// leader leases, ...).
+//
+// Scripts are registered once at package init, addressed by their SHA1 and executed with
+// EVALSHA, so the script body crosses the wire only when Redis doesn't have it cached. On
+// NOSCRIPT (Redis restarted, failed over, or SCRIPT FLUSH) the script is reloaded with
+// SCRIPT LOAD and the call retried once.
+package redisscript
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Script is a registered Lua script with its precomputed SHA1.
+type Script struct {
+	name string
+	src  string
+	sha  string
+}
+
+var (
+	mu       sync.RWMutex
+	registry = map[string]*Script{}
+)
+
+// Register adds a script under a unique name (e.g. "ratelimit.bucket") and returns it.
+// Meant for package-level vars; a duplicate name is a programming error and panics.
+func Register(name, src string) *Script {
+	s := newScript(name, src)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if _, dup := registry[name]; dup {
+		panic("redisscript: duplicate script " + name)
+	}
+	registry[name] = s
+	return s
+}
+
+func newScript(name, src string) *Script {
+	sum := sha1.Sum([]byte(src))
+	return &Script{name: name, src: src, sha: hex.EncodeToString(sum[:])}
+}
+
+// Registered returns every registered script, sorted by name.
+func Registered() []*Script {
+	mu.RLock()
+	out := make([]*Script, 0, len(registry))
+	for _, s := range registry {
+		out = append(out, s)
+	}
+	mu.RUnlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].name < out[j].name })
+	return out
+}
+
+// LoadAll preloads every registered script (SCRIPT LOAD) at boot, so the first requests go
+// straight to EVALSHA. Optional: Run reloads on demand anyway.
+func LoadAll(ctx context.Context, rdb redis.Scripter) error {
+	for _, s := range Registered() {
+		if err := s.Load(ctx, rdb); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Name is the registration name.
+func (s *Script) Name() string { return s.name }
+
+// SHA is the SHA1 the script is cached under in Redis (for SCRIPT EXISTS checks in ops tooling).
+func (s *Script) SHA() string { return s.sha }
+
+// Load puts the script into Redis' script cache.
+func (s *Script) Load(ctx context.Context, rdb redis.Scripter) error {
+	sha, err := rdb.ScriptLoad(ctx, s.src).Result()
+	if err != nil {
+		return fmt.Errorf("redisscript: load %s: %w", s.name, err)
+	}
+	if sha != s.sha { // would mean every EVALSHA misses
+		return fmt.Errorf("redisscript: load %s: redis returned sha %s, want %s", s.name, sha, s.sha)
+	}
+	return nil
+}
+
+// Run executes the script with EVALSHA; on NOSCRIPT it reloads the script and retries once.
+func (s *Script) Run(ctx context.Context, rdb redis.Scripter, keys []string, args ...interface{}) *redis.Cmd {
+	cmd := rdb.EvalSha(ctx, s.sha, keys, args...)
+	if !isNoScript(cmd.Err()) {
+		return cmd
+	}
+	if err := s.Load(ctx, rdb); err != nil {
+		failed := redis.NewCmd(ctx)
+		failed.SetErr(err)
+		return failed
+	}
+	return rdb.EvalSha(ctx, s.sha, keys, args...)
+}
+
+// isNoScript reports Redis' "NOSCRIPT No matching script" reply.
+func isNoScript(err error) bool {
+	return err != nil && strings.HasPrefix(err.Error(), "NOSCRIPT")
+}
@@ -0,0 +1,48 @@
+package redisscript
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"HelmyTask/mocks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun_ReloadsOnNoScript(t *testing.T) {
+	rdb, m := mocks.NewRedisMock()
+	s := newScript("test.echo", `return ARGV[1]`) // unregistered
+	ctx := context.Background()
+
+	// cached: one EVALSHA
+	m.ExpectEvalSha(s.SHA(), []string{"k"}, "hi").SetVal("hi")
+	v, err := s.Run(ctx, rdb, []string{"k"}, "hi").Text()
+	assert.NoError(t, err)
+	assert.Equal(t, "hi", v)
+
+	// Redis lost its script cache: SCRIPT LOAD, then retry once
+	m.ExpectEvalSha(s.SHA(), []string{"k"}, "hi").SetErr(errors.New("NOSCRIPT No matching script. Please use EVAL."))
+	m.ExpectScriptLoad(s.src).SetVal(s.SHA())
+	m.ExpectEvalSha(s.SHA(), []string{"k"}, "hi").SetVal("hi")
+	v, err = s.Run(ctx, rdb, []string{"k"}, "hi").Text()
+	assert.NoError(t, err)
+	assert.Equal(t, "hi", v)
+
+	// other errors are returned as-is, no reload
+	m.ExpectEvalSha(s.SHA(), []string{"k"}, "hi").SetErr(errors.New("connection refused"))
+	_, err = s.Run(ctx, rdb, []string{"k"}, "hi").Result()
+	assert.EqualError(t, err, "connection refused")
+	assert.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestRegister(t *testing.T) {
+	s := Register("test.register", `return 1`)
+	assert.Equal(t, "e0e1f9fabfc9d4800c877a703b823ac0578ff8db", s.SHA()) // sha1("return 1")
+	assert.Contains(t, Registered(), s)
+	assert.Panics(t, func() { Register("test.register", `return 2`) })
+
+	rdb, m := mocks.NewRedisMock()
+	m.ExpectScriptLoad(`return 1`).SetVal("deadbeef")
+	assert.Error(t, s.Load(context.Background(), rdb), "a SHA mismatch would make every EVALSHA miss")
+}
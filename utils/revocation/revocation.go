@@ -0,0 +1,57 @@
+package revocation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Store voids a user's stateless JWTs in bulk: after RevokeAll, any token whose "iat" is not
+// later than that moment is rejected. One key per user, "auth:revoked_before:<uid>" (unix
+// seconds with milliseconds, e.g. "1700000000.123"), kept only as long as a token can live —
+// older tokens have expired on their own anyway.
+type Store struct {
+	rdb *redis.Client
+	ttl time.Duration // max token lifetime
+}
+
+// New creates a revocation store. A nil client yields a no-op store.
+func New(rdb *redis.Client, ttl time.Duration) *Store {
+	return &Store{rdb: rdb, ttl: ttl}
+}
+
+func key(uid uint) string { return fmt.Sprintf("auth:revoked_before:%d", uid) }
+
+// RevokeAll invalidates every token issued to uid up to now (password change, account compromise).
+func (s *Store) RevokeAll(ctx context.Context, uid uint) error {
+	if s == nil || s.rdb == nil {
+		return nil
+	}
+	return s.rdb.Set(ctx, key(uid), strconv.FormatFloat(float64(time.Now().UnixMilli())/1000, 'f', 3, 64), s.ttl).Err()
+}
+
+// Revoked reports whether a token issued to uid at issuedAt has been revoked.
+func (s *Store) Revoked(ctx context.Context, uid uint, issuedAt time.Time) (bool, error) {
+	if s == nil || s.rdb == nil {
+		return false, nil
+	}
+	val, err := s.rdb.Get(ctx, key(uid)).Result()
+	if errors.Is(err, redis.Nil) {
+		return false, nil // never revoked (or the marker outlived every token)
+	}
+	if err != nil {
+		return false, err
+	}
+	secs, err := strconv.ParseFloat(val, 64) // markers from older builds are whole seconds
+	if err != nil {
+		return false, err
+	}
+	// Millisecond precision on both sides (login issues iat the same way): a token minted earlier
+	// in the revocation's second is dead, the fresh login right after it isn't.
+	return !issuedAt.After(time.UnixMilli(int64(math.Round(secs * 1000)))), nil
+}
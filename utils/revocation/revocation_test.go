@@ -0,0 +1,50 @@
+package revocation
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRevoked(t *testing.T) {
+	rdb, m := redismock.NewClientMock()
+	s := New(rdb, time.Hour)
+	ctx := context.Background()
+	cut := time.Unix(1_700_000_000, 0)
+
+	m.ExpectGet("auth:revoked_before:7").RedisNil()
+	ok, err := s.Revoked(ctx, 7, cut.Add(-time.Hour))
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	m.ExpectGet("auth:revoked_before:7").SetVal("1700000000.500")
+	ok, _ = s.Revoked(ctx, 7, cut.Add(-time.Second))
+	assert.True(t, ok, "older token is revoked")
+
+	m.ExpectGet("auth:revoked_before:7").SetVal("1700000000.500")
+	ok, _ = s.Revoked(ctx, 7, cut.Add(200*time.Millisecond))
+	assert.True(t, ok, "token from earlier in the same second is revoked")
+
+	m.ExpectGet("auth:revoked_before:7").SetVal("1700000000.500")
+	ok, _ = s.Revoked(ctx, 7, cut.Add(501*time.Millisecond))
+	assert.False(t, ok, "token issued after the revocation survives")
+
+	m.ExpectGet("auth:revoked_before:7").SetVal(strconv.FormatInt(cut.Unix(), 10)) // whole seconds, from an older build
+	ok, _ = s.Revoked(ctx, 7, cut)
+	assert.True(t, ok)
+
+	assert.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestNilStoreIsNoop(t *testing.T) {
+	var s *Store
+	assert.NoError(t, s.RevokeAll(context.Background(), 1))
+	ok, err := s.Revoked(context.Background(), 1, time.Now())
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
@@ -0,0 +1,110 @@
+package session
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrNotFound is returned for unknown or expired session IDs.
+var ErrNotFound = errors.New("session not found")
+
+// Data is what we keep server-side for an opaque session.
+type Data struct {
+	UserID    uint      `json:"uid"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Store keeps sessions in Redis with a sliding TTL: every successful Get pushes expiry forward.
+// Keys: "session:<id>" (JSON Data) and "sessions:user:<uid>" (SET of that user's session IDs,
+// so all of a user's sessions can be revoked at once). The set slides with each of its
+// sessions, so it outlives all of them.
+type Store struct {
+	rdb *redis.Client
+	ttl time.Duration
+}
+
+// New creates a session store.
+func New(rdb *redis.Client, ttl time.Duration) *Store {
+	return &Store{rdb: rdb, ttl: ttl}
+}
+
+// TTL is the idle timeout (useful for cookie Max-Age).
+func (s *Store) TTL() time.Duration { return s.ttl }
+
+func sessionKey(id string) string { return "session:" + id }
+func userSetKey(uid uint) string  { return fmt.Sprintf("sessions:user:%d", uid) }
+
+// Create starts a session and returns its random ID (256 bits, hex).
+func (s *Store) Create(ctx context.Context, uid uint, role string) (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	id := hex.EncodeToString(buf)
+	b, _ := json.Marshal(Data{UserID: uid, Role: role, CreatedAt: time.Now().UTC()})
+
+	pipe := s.rdb.TxPipeline()
+	pipe.Set(ctx, sessionKey(id), b, s.ttl)
+	pipe.SAdd(ctx, userSetKey(uid), id)
+	pipe.Expire(ctx, userSetKey(uid), s.ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// Get loads a session and slides its expiry.
+func (s *Store) Get(ctx context.Context, id string) (*Data, error) {
+	val, err := s.rdb.Get(ctx, sessionKey(id)).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var d Data
+	if err := json.Unmarshal([]byte(val), &d); err != nil {
+		return nil, ErrNotFound // Corrupt entry: treat as logged out.
+	}
+	// Sliding window (best-effort). The user's index slides too: were it to expire first, the
+	// sessions still alive would escape DeleteAllForUser.
+	pipe := s.rdb.Pipeline()
+	pipe.Expire(ctx, sessionKey(id), s.ttl)
+	pipe.Expire(ctx, userSetKey(d.UserID), s.ttl)
+	_, _ = pipe.Exec(ctx)
+	return &d, nil
+}
+
+// Delete ends one session (logout).
+func (s *Store) Delete(ctx context.Context, id string) error {
+	d, err := s.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	pipe := s.rdb.TxPipeline()
+	pipe.Del(ctx, sessionKey(id))
+	pipe.SRem(ctx, userSetKey(d.UserID), id)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// DeleteAllForUser ends every session of a user (password change, ban, admin flush).
+func (s *Store) DeleteAllForUser(ctx context.Context, uid uint) error {
+	ids, err := s.rdb.SMembers(ctx, userSetKey(uid)).Result()
+	if err != nil {
+		return err
+	}
+	keys := []string{userSetKey(uid)}
+	for _, id := range ids {
+		keys = append(keys, sessionKey(id))
+	}
+	return s.rdb.Del(ctx, keys...).Err()
+}
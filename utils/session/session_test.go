@@ -0,0 +1,37 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_GetSlidesTTL(t *testing.T) {
+	rdb, mock := redismock.NewClientMock()
+	s := New(rdb, time.Hour)
+
+	b, _ := json.Marshal(Data{UserID: 4, Role: "user"})
+	mock.ExpectGet("session:abc").SetVal(string(b))
+	mock.ExpectExpire("session:abc", time.Hour).SetVal(true)
+	mock.ExpectExpire("sessions:user:4", time.Hour).SetVal(true) // the index must outlive the session
+
+	d, err := s.Get(context.Background(), "abc")
+	require.NoError(t, err)
+	assert.Equal(t, uint(4), d.UserID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStore_GetMissing(t *testing.T) {
+	rdb, mock := redismock.NewClientMock()
+	s := New(rdb, time.Hour)
+
+	mock.ExpectGet("session:gone").RedisNil()
+
+	_, err := s.Get(context.Background(), "gone")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTOTP_RFC6238Vector(t *testing.T) {
+	// GIVEN: the RFC 6238 SHA1 seed "12345678901234567890" in base32
+	secret := "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+	// WHEN
+	code, err := TOTPCode(secret, time.Unix(59, 0))
+
+	// THEN: last 6 digits of the published 94287082
+	require.NoError(t, err)
+	assert.Equal(t, "287082", code)
+}
+
+func TestTOTP_ValidateWithSkew(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	require.NoError(t, err)
+	now := time.Now()
+
+	prev, _ := TOTPCode(secret, now.Add(-30*time.Second))
+	assert.True(t, ValidateTOTP(secret, prev, now))
+	assert.False(t, ValidateTOTP(secret, "000000x", now)) // malformed
+
+	step, ok := MatchTOTP(secret, prev, now)
+	assert.True(t, ok)
+	assert.Equal(t, now.Unix()/30-1, step, "the step the code was made for, not now's")
+
+	window := map[string]bool{}
+	for i := -1; i <= 1; i++ {
+		c, _ := TOTPCode(secret, now.Add(time.Duration(i)*30*time.Second))
+		window[c] = true
+	}
+	wrong := "000000"
+	for n := 1; window[wrong]; n++ {
+		wrong = fmt.Sprintf("%06d", n)
+	}
+	assert.False(t, ValidateTOTP(secret, wrong, now)) // well-formed, but no step in the window makes it
+}
+
+func TestEncryptString_RoundTrip(t *testing.T) {
+	ct, err := EncryptString("k", "JBSWY3DPEHPK3PXP")
+	require.NoError(t, err)
+
+	pt, err := DecryptString("k", ct)
+	require.NoError(t, err)
+	assert.Equal(t, "JBSWY3DPEHPK3PXP", pt)
+
+	_, err = DecryptString("other", ct)
+	assert.Error(t, err)
+}
@@ -0,0 +1,31 @@
+package webhookverify
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignVerify(t *testing.T) {
+	body := []byte(`{"type":"webhook.test"}`)
+	at := time.Unix(1_700_000_000, 0)
+	h := Sign("whsec_1", body, at)
+
+	assert.NoError(t, Verify("whsec_1", h, body, 0, at.Add(time.Minute)))
+	assert.ErrorIs(t, Verify("whsec_2", h, body, 0, at), ErrBadSignature)
+	assert.ErrorIs(t, Verify("whsec_1", h, []byte(`{"type":"tampered"}`), 0, at), ErrBadSignature)
+	assert.ErrorIs(t, Verify("whsec_1", h, body, 0, at.Add(10*time.Minute)), ErrStaleRequest, "replayed later")
+	assert.ErrorIs(t, Verify("whsec_1", "", body, 0, at), ErrNoSignature)
+}
+
+func TestVerify_AnyV1MatchesDuringRotation(t *testing.T) {
+	body := []byte(`{}`)
+	at := time.Unix(1_700_000_000, 0)
+	oldSig := Sign("old", body, at)
+	newSig := Sign("new", body, at)
+	h := newSig + "," + oldSig[len("t=1700000000,"):]
+
+	assert.NoError(t, Verify("old", h, body, 0, at))
+	assert.NoError(t, Verify("new", h, body, 0, at))
+}